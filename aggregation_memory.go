@@ -0,0 +1,24 @@
+package zetasqlite
+
+import (
+	"context"
+	"fmt"
+
+	internal "github.com/goccy/go-zetasqlite/internal"
+)
+
+// SetAggregationMemoryLimit specifies the soft limit of the memory in bytes used by the aggregations of the connection.
+// The memory is estimated from the values kept by ARRAY_AGG, STRING_AGG and similar functions and the keys of DISTINCT aggregations.
+// If the limit is exceeded, the query fails early with the estimated usage instead of exhausting the memory.
+// Spilling the aggregation states to disk is not supported. If limit is 0 ( default ), the memory is not limited.
+func (c *ZetaSQLiteConn) SetAggregationMemoryLimit(ctx context.Context, limit int64) error {
+	if limit < 0 {
+		return fmt.Errorf("zetasqlite: invalid aggregation memory limit %d", limit)
+	}
+	if _, err := internal.NewConn(c.conn, c.tx).ExecContext(
+		ctx, "SELECT zetasqlite_set_aggregation_memory_limit(?)", limit,
+	); err != nil {
+		return fmt.Errorf("zetasqlite: failed to set aggregation memory limit: %w", err)
+	}
+	return nil
+}
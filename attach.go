@@ -0,0 +1,75 @@
+package zetasqlite
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/mattn/go-sqlite3"
+
+	internal "github.com/goccy/go-zetasqlite/internal"
+)
+
+const (
+	// AttachDSNParam is the DSN parameter to store the dataset in the other SQLite database file.
+	// The value is `<dataset>=<file>` and the parameter can be specified multiple times
+	// ( e.g. `file:scratch.db?_zetasqlite_attach=logs=logs.db` ).
+	AttachDSNParam = "_zetasqlite_attach"
+	// AttachReadOnlyDSNParam is the DSN parameter to mount the dataset stored in the other SQLite database file as read-only.
+	// The value is the same as AttachDSNParam ( e.g. `file:scratch.db?_zetasqlite_attach_ro=ref=ref.db` ).
+	AttachReadOnlyDSNParam = "_zetasqlite_attach_ro"
+)
+
+type AttachedDataset = internal.AttachedDataset
+
+// parseAttachedDatasets extracts the attached datasets from the DSN parameters.
+func parseAttachedDatasets(params url.Values) ([]*AttachedDataset, error) {
+	var datasets []*AttachedDataset
+	for _, param := range []string{AttachDSNParam, AttachReadOnlyDSNParam} {
+		for _, value := range params[param] {
+			name, path, found := strings.Cut(value, "=")
+			if !found {
+				return nil, fmt.Errorf("invalid %s parameter %q. the value must be <dataset>=<file>", param, value)
+			}
+			datasets = append(datasets, &AttachedDataset{
+				Name:     name,
+				Path:     path,
+				ReadOnly: param == AttachReadOnlyDSNParam,
+			})
+		}
+	}
+	return datasets, nil
+}
+
+// newSQLiteDriver creates the SQLite driver that attaches the datasets to every connection.
+func newSQLiteDriver(datasets []*AttachedDataset) *sqlite3.SQLiteDriver {
+	return &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			if err := setupSQLiteConn(conn); err != nil {
+				return err
+			}
+			for _, dataset := range datasets {
+				if _, err := conn.Exec(dataset.AttachQuery(), []driver.Value{dataset.Filename()}); err != nil {
+					return fmt.Errorf("failed to attach dataset %s from %s: %w", dataset.Name, dataset.Path, err)
+				}
+			}
+			return nil
+		},
+	}
+}
+
+// sqliteConnector opens the connections of the DSN by the SQLite driver that has the attached datasets.
+type sqliteConnector struct {
+	dsn    string
+	driver *sqlite3.SQLiteDriver
+}
+
+func (c *sqliteConnector) Connect(_ context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+func (c *sqliteConnector) Driver() driver.Driver {
+	return c.driver
+}
@@ -0,0 +1,266 @@
+package benchmarks_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/goccy/go-zetasql"
+	"github.com/goccy/go-zetasql/types"
+
+	_ "github.com/goccy/go-zetasqlite"
+)
+
+const (
+	// benchmarkTableRows is the number of rows of the table used by the scan benchmarks.
+	benchmarkTableRows = 10000
+
+	// insertBatchSize is the number of rows inserted by one iteration of BenchmarkBulkInsert.
+	insertBatchSize = 1000
+)
+
+const complexQuery = `
+WITH orders AS (
+  SELECT id, MOD(id, 10) AS customer_id, CAST(id AS NUMERIC) * 1.5 AS amount, DATE_ADD(DATE '2022-01-01', INTERVAL id DAY) AS ordered_at
+  FROM UNNEST(GENERATE_ARRAY(1, 100)) AS id
+)
+SELECT
+  customer_id,
+  COUNT(*) AS cnt,
+  SUM(amount) AS total,
+  ARRAY_AGG(STRUCT(id, ordered_at) ORDER BY ordered_at DESC LIMIT 3) AS recent_orders,
+  FORMAT_DATE('%Y-%m', MAX(ordered_at)) AS last_month
+FROM orders
+WHERE amount > 10 AND customer_id IN (1, 2, 3, 4, 5)
+GROUP BY customer_id
+HAVING COUNT(*) > 1
+ORDER BY total DESC`
+
+func openDB(b *testing.B) *sql.DB {
+	b.Helper()
+	db, err := sql.Open("zetasqlite", ":memory:")
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() { db.Close() })
+	return db
+}
+
+// openDBWithTable opens the database that has the table containing benchmarkTableRows rows of various types.
+func openDBWithTable(b *testing.B) *sql.DB {
+	b.Helper()
+	db := openDB(b)
+	if _, err := db.ExecContext(context.Background(), fmt.Sprintf(`
+CREATE TABLE bench_table (
+  id INT64, grp INT64, name STRING, amount NUMERIC, score FLOAT64,
+  created_at TIMESTAMP, tags ARRAY<STRING>, attr STRUCT<key STRING, value INT64>, payload JSON
+);
+INSERT INTO bench_table
+SELECT
+  id,
+  MOD(id, 100),
+  CONCAT('name', CAST(id AS STRING)),
+  CAST(id AS NUMERIC) / 100,
+  id * 0.5,
+  TIMESTAMP_ADD(TIMESTAMP '2022-01-01 00:00:00+00', INTERVAL id SECOND),
+  ['a', 'b', CAST(id AS STRING)],
+  STRUCT(CAST(id AS STRING) AS key, id AS value),
+  TO_JSON(STRUCT(id, [id, id + 1] AS items))
+FROM UNNEST(GENERATE_ARRAY(1, %d)) AS id`, benchmarkTableRows)); err != nil {
+		b.Fatal(err)
+	}
+	return db
+}
+
+func runQuery(b *testing.B, db *sql.DB, query string) {
+	b.Helper()
+	ctx := context.Background()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		rows, err := db.QueryContext(ctx, query)
+		if err != nil {
+			b.Fatal(err)
+		}
+		columns, err := rows.Columns()
+		if err != nil {
+			b.Fatal(err)
+		}
+		values := make([]interface{}, len(columns))
+		scanArgs := make([]interface{}, len(columns))
+		for i := range values {
+			scanArgs[i] = &values[i]
+		}
+		for rows.Next() {
+			if err := rows.Scan(scanArgs...); err != nil {
+				b.Fatal(err)
+			}
+		}
+		if err := rows.Err(); err != nil {
+			b.Fatal(err)
+		}
+		rows.Close()
+	}
+}
+
+// BenchmarkAnalyze measures the analysis by ZetaSQL only.
+func BenchmarkAnalyze(b *testing.B) {
+	catalog := types.NewSimpleCatalog("bench")
+	catalog.AddZetaSQLBuiltinFunctions(nil)
+	langOpt := zetasql.NewLanguageOptions()
+	langOpt.SetProductMode(types.ProductInternal)
+	langOpt.SetEnabledLanguageFeatures([]zetasql.LanguageFeature{
+		zetasql.FeatureAnalyticFunctions,
+		zetasql.FeatureNumericType,
+		zetasql.FeatureV11OrderByInAggregate,
+		zetasql.FeatureV11LimitInAggregate,
+	})
+	opt := zetasql.NewAnalyzerOptions()
+	opt.SetLanguage(langOpt)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := zetasql.AnalyzeStatement(complexQuery, catalog, opt); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkPrepare measures the analysis and the formatting to the SQLite query.
+// The difference from BenchmarkAnalyze is the cost of formatting.
+func BenchmarkPrepare(b *testing.B) {
+	db := openDB(b)
+	ctx := context.Background()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		stmt, err := db.PrepareContext(ctx, complexQuery)
+		if err != nil {
+			b.Fatal(err)
+		}
+		stmt.Close()
+	}
+}
+
+// BenchmarkParallelPrepare measures the analysis and the formatting run by the parallel goroutines.
+// Each connection has its own analyzer, but the connections opened by the same DSN share the catalog synchronized under the lock,
+// so the goroutines using the separate DSNs ( like the parallel tests opening their own databases ) scale better than the goroutines sharing the DSN.
+func BenchmarkParallelPrepare(b *testing.B) {
+	ctx := context.Background()
+	prepare := func(db *sql.DB) error {
+		stmt, err := db.PrepareContext(ctx, complexQuery)
+		if err != nil {
+			return err
+		}
+		return stmt.Close()
+	}
+	b.Run("shared dsn", func(b *testing.B) {
+		db, err := sql.Open("zetasqlite", "file:BenchmarkParallelPrepare?mode=memory&cache=shared")
+		if err != nil {
+			b.Fatal(err)
+		}
+		defer db.Close()
+		b.ResetTimer()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				if err := prepare(db); err != nil {
+					b.Error(err)
+					return
+				}
+			}
+		})
+	})
+	b.Run("separate dsns", func(b *testing.B) {
+		var dbID int64
+		b.ResetTimer()
+		b.RunParallel(func(pb *testing.PB) {
+			dsn := fmt.Sprintf("file:BenchmarkParallelPrepare%d?mode=memory&cache=shared", atomic.AddInt64(&dbID, 1))
+			db, err := sql.Open("zetasqlite", dsn)
+			if err != nil {
+				b.Error(err)
+				return
+			}
+			defer db.Close()
+			for pb.Next() {
+				if err := prepare(db); err != nil {
+					b.Error(err)
+					return
+				}
+			}
+		})
+	})
+}
+
+func BenchmarkBulkInsert(b *testing.B) {
+	db := openDB(b)
+	ctx := context.Background()
+	if _, err := db.ExecContext(ctx, `
+CREATE TABLE bench_insert (id INT64, name STRING, amount NUMERIC, created_at TIMESTAMP, tags ARRAY<STRING>)`,
+	); err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			b.Fatal(err)
+		}
+		stmt, err := tx.PrepareContext(ctx, `INSERT INTO bench_insert (id, name, amount, created_at, tags) VALUES (?, ?, ?, ?, ?)`)
+		if err != nil {
+			b.Fatal(err)
+		}
+		for j := 0; j < insertBatchSize; j++ {
+			if _, err := stmt.ExecContext(ctx, j, fmt.Sprintf("name%d", j), "1.5", "2022-01-01 00:00:00", []string{"a", "b"}); err != nil {
+				b.Fatal(err)
+			}
+		}
+		stmt.Close()
+		if err := tx.Commit(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkScan(b *testing.B) {
+	db := openDBWithTable(b)
+	b.Run("primitive", func(b *testing.B) {
+		runQuery(b, db, `SELECT id, grp, score FROM bench_table`)
+	})
+	b.Run("encoded", func(b *testing.B) {
+		runQuery(b, db, `SELECT name, amount, created_at, tags, attr FROM bench_table`)
+	})
+	b.Run("filter", func(b *testing.B) {
+		runQuery(b, db, `SELECT id FROM bench_table WHERE name LIKE 'name1%' AND amount > 10`)
+	})
+	b.Run("order by", func(b *testing.B) {
+		runQuery(b, db, `SELECT id FROM bench_table ORDER BY created_at DESC`)
+	})
+	b.Run("group by", func(b *testing.B) {
+		runQuery(b, db, `SELECT grp, COUNT(*), SUM(amount), ARRAY_AGG(name) FROM bench_table GROUP BY grp`)
+	})
+}
+
+func BenchmarkWindowFunction(b *testing.B) {
+	db := openDBWithTable(b)
+	b.Run("sum", func(b *testing.B) {
+		runQuery(b, db, `SELECT id, SUM(score) OVER (PARTITION BY grp ORDER BY id) FROM bench_table`)
+	})
+	b.Run("row_number", func(b *testing.B) {
+		runQuery(b, db, `SELECT id, ROW_NUMBER() OVER (PARTITION BY grp ORDER BY created_at DESC) FROM bench_table`)
+	})
+	b.Run("lag", func(b *testing.B) {
+		runQuery(b, db, `SELECT id, LAG(name) OVER (PARTITION BY grp ORDER BY id) FROM bench_table`)
+	})
+}
+
+func BenchmarkJSONFunction(b *testing.B) {
+	db := openDBWithTable(b)
+	b.Run("json_value", func(b *testing.B) {
+		runQuery(b, db, `SELECT JSON_VALUE(payload, '$.id') FROM bench_table`)
+	})
+	b.Run("json_query_array", func(b *testing.B) {
+		runQuery(b, db, `SELECT JSON_QUERY_ARRAY(payload, '$.items') FROM bench_table`)
+	})
+	b.Run("to_json_string", func(b *testing.B) {
+		runQuery(b, db, `SELECT TO_JSON_STRING(attr) FROM bench_table`)
+	})
+}
@@ -0,0 +1,25 @@
+package zetasqlite
+
+import (
+	"database/sql"
+
+	"cloud.google.com/go/bigquery"
+
+	internal "github.com/goccy/go-zetasqlite/internal"
+)
+
+// BigQuerySchema converts column specifications ( e.g. TableSpec.Columns ) to the schema of cloud.google.com/go/bigquery.
+// This is useful to compare the schema produced by zetasqlite with the schema of the production table.
+func BigQuerySchema(columns []*ColumnSpec) (bigquery.Schema, error) {
+	return internal.BigQuerySchema(columns)
+}
+
+// BigQuerySchemaFromRows returns the schema of the result set as the schema of cloud.google.com/go/bigquery.
+// NOTE: This API relies on the internal structure of sql.Rows, so not will work for all Go versions.
+func BigQuerySchemaFromRows(rows *sql.Rows) (bigquery.Schema, error) {
+	zetasqliteRows, err := zetasqliteRowsFromRows(rows)
+	if err != nil {
+		return nil, err
+	}
+	return internal.BigQuerySchema(zetasqliteRows.OutputColumns())
+}
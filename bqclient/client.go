@@ -0,0 +1,182 @@
+// Package bqclient provides a subset of the cloud.google.com/go/bigquery Client API backed by zetasqlite.
+// Method names and types follow the official client, so the code written against it
+// can be run in unit tests by switching the client behind a small interface.
+package bqclient
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"cloud.google.com/go/bigquery"
+
+	zetasqlite "github.com/goccy/go-zetasqlite"
+)
+
+// Client is a client that executes queries by zetasqlite.
+type Client struct {
+	// Location is not used by zetasqlite. It is kept for compatibility with bigquery.Client.
+	Location string
+
+	projectID string
+	db        *sql.DB
+}
+
+// NewClient creates a client for projectID backed by db.
+// db must be opened with the zetasqlite driver ( e.g. sql.Open("zetasqlite", ":memory:") ).
+func NewClient(ctx context.Context, projectID string, db *sql.DB) (*Client, error) {
+	if db == nil {
+		return nil, fmt.Errorf("bqclient: sql.DB instance required not nil")
+	}
+	if err := db.PingContext(ctx); err != nil {
+		return nil, fmt.Errorf("bqclient: failed to connect database: %w", err)
+	}
+	return &Client{projectID: projectID, db: db}, nil
+}
+
+// Project returns the project ID of the client.
+func (c *Client) Project() string {
+	return c.projectID
+}
+
+// Close does nothing because the database is owned by the caller of NewClient.
+func (c *Client) Close() error {
+	return nil
+}
+
+// Query creates a query with string q.
+func (c *Client) Query(q string) *Query {
+	return &Query{
+		client:      c,
+		QueryConfig: bigquery.QueryConfig{Q: q},
+	}
+}
+
+// Dataset creates a handle to a dataset in the client's project.
+func (c *Client) Dataset(id string) *Dataset {
+	return c.DatasetInProject(c.projectID, id)
+}
+
+// DatasetInProject creates a handle to a dataset in the specified project.
+func (c *Client) DatasetInProject(projectID, datasetID string) *Dataset {
+	return &Dataset{ProjectID: projectID, DatasetID: datasetID, client: c}
+}
+
+// conn returns the connection whose name path is set to the project and dataset.
+func (c *Client) conn(ctx context.Context, projectID, datasetID string) (*sql.Conn, error) {
+	conn, err := c.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("bqclient: failed to get connection: %w", err)
+	}
+	if projectID == "" {
+		projectID = c.projectID
+	}
+	namePath := []string{}
+	if projectID != "" {
+		namePath = append(namePath, projectID)
+	}
+	if datasetID != "" {
+		namePath = append(namePath, datasetID)
+	}
+	if err := conn.Raw(func(c interface{}) error {
+		zetasqliteConn, ok := c.(*zetasqlite.ZetaSQLiteConn)
+		if !ok {
+			return fmt.Errorf("unexpected connection type %T", c)
+		}
+		return zetasqliteConn.SetNamePath(namePath)
+	}); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("bqclient: failed to set name path: %w", err)
+	}
+	return conn, nil
+}
+
+func (c *Client) read(ctx context.Context, projectID, datasetID, query string, args ...interface{}) (*RowIterator, error) {
+	conn, err := c.conn(ctx, projectID, datasetID)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	schema, err := zetasqlite.BigQuerySchemaFromRows(rows)
+	if err != nil {
+		rows.Close()
+		conn.Close()
+		return nil, err
+	}
+	return &RowIterator{Schema: schema, conn: conn, rows: rows}, nil
+}
+
+// Query represents a query to be executed.
+// Only Q, Parameters, DefaultProjectID and DefaultDatasetID of QueryConfig are used.
+type Query struct {
+	bigquery.QueryConfig
+
+	client *Client
+}
+
+// Read submits a query for execution and returns the results via a RowIterator.
+func (q *Query) Read(ctx context.Context) (*RowIterator, error) {
+	args := make([]interface{}, 0, len(q.Parameters))
+	for _, param := range q.Parameters {
+		if param.Name == "" {
+			args = append(args, param.Value)
+		} else {
+			args = append(args, sql.Named(param.Name, param.Value))
+		}
+	}
+	return q.client.read(ctx, q.DefaultProjectID, q.DefaultDatasetID, q.Q, args...)
+}
+
+// Dataset is a reference to a dataset.
+type Dataset struct {
+	ProjectID string
+	DatasetID string
+
+	client *Client
+}
+
+// Table creates a handle to a table in the dataset.
+func (d *Dataset) Table(tableID string) *Table {
+	return &Table{ProjectID: d.ProjectID, DatasetID: d.DatasetID, TableID: tableID, client: d.client}
+}
+
+// Table is a reference to a table.
+type Table struct {
+	ProjectID string
+	DatasetID string
+	TableID   string
+
+	client *Client
+}
+
+// FullyQualifiedName returns the ID of the table in projectID:datasetID.tableID format.
+func (t *Table) FullyQualifiedName() string {
+	return fmt.Sprintf("%s:%s.%s", t.ProjectID, t.DatasetID, t.TableID)
+}
+
+// Metadata returns the metadata of the table. Only Name, Schema and Type are filled.
+func (t *Table) Metadata(ctx context.Context) (*bigquery.TableMetadata, error) {
+	it, err := t.client.read(ctx, t.ProjectID, t.DatasetID, fmt.Sprintf("SELECT * FROM `%s` LIMIT 0", t.TableID))
+	if err != nil {
+		return nil, fmt.Errorf("bqclient: failed to get metadata of %s: %w", t.FullyQualifiedName(), err)
+	}
+	defer it.close()
+	return &bigquery.TableMetadata{
+		Name:   t.TableID,
+		Schema: it.Schema,
+		Type:   bigquery.RegularTable,
+	}, nil
+}
+
+// Read fetches the contents of the table.
+func (t *Table) Read(ctx context.Context) *RowIterator {
+	it, err := t.client.read(ctx, t.ProjectID, t.DatasetID, fmt.Sprintf("SELECT * FROM `%s`", t.TableID))
+	if err != nil {
+		return &RowIterator{err: fmt.Errorf("bqclient: failed to read %s: %w", t.FullyQualifiedName(), err)}
+	}
+	return it
+}
@@ -0,0 +1,99 @@
+package bqclient_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"cloud.google.com/go/bigquery"
+	"cloud.google.com/go/civil"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/api/iterator"
+
+	_ "github.com/goccy/go-zetasqlite"
+	"github.com/goccy/go-zetasqlite/bqclient"
+)
+
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("zetasqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.ExecContext(ctx, `
+CREATE TABLE project.dataset.users (id INT64 NOT NULL, name STRING, birthday DATE);
+INSERT INTO project.dataset.users (id, name, birthday) VALUES (1, 'alice', '2000-01-01'), (2, 'bob', NULL);
+`); err != nil {
+		t.Fatal(err)
+	}
+	client, err := bqclient.NewClient(ctx, "project", db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	t.Run("query", func(t *testing.T) {
+		q := client.Query("SELECT id, name, birthday FROM users WHERE id = @id")
+		q.DefaultDatasetID = "dataset"
+		q.Parameters = []bigquery.QueryParameter{{Name: "id", Value: 1}}
+		it, err := q.Read(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var rows [][]bigquery.Value
+		for {
+			var row []bigquery.Value
+			err := it.Next(&row)
+			if err == iterator.Done {
+				break
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			rows = append(rows, row)
+		}
+		expected := [][]bigquery.Value{
+			{int64(1), "alice", civil.Date{Year: 2000, Month: 1, Day: 1}},
+		}
+		if diff := cmp.Diff(expected, rows); diff != "" {
+			t.Errorf("(-want +got):\n%s", diff)
+		}
+	})
+	t.Run("read table into struct", func(t *testing.T) {
+		type user struct {
+			ID   int64  `bigquery:"id"`
+			Name string `bigquery:"name"`
+		}
+		it := client.Dataset("dataset").Table("users").Read(ctx)
+		var users []user
+		for {
+			var u user
+			err := it.Next(&u)
+			if err == iterator.Done {
+				break
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			users = append(users, u)
+		}
+		if diff := cmp.Diff([]user{{ID: 1, Name: "alice"}, {ID: 2, Name: "bob"}}, users); diff != "" {
+			t.Errorf("(-want +got):\n%s", diff)
+		}
+	})
+	t.Run("metadata", func(t *testing.T) {
+		md, err := client.Dataset("dataset").Table("users").Metadata(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := bigquery.Schema{
+			{Name: "id", Type: bigquery.IntegerFieldType},
+			{Name: "name", Type: bigquery.StringFieldType},
+			{Name: "birthday", Type: bigquery.DateFieldType},
+		}
+		if diff := cmp.Diff(expected, md.Schema); diff != "" {
+			t.Errorf("(-want +got):\n%s", diff)
+		}
+	})
+}
@@ -0,0 +1,241 @@
+package bqclient
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+	"cloud.google.com/go/civil"
+	"google.golang.org/api/iterator"
+)
+
+// RowIterator provides access to the result of a query or the contents of a table.
+type RowIterator struct {
+	// Schema is the schema of the rows.
+	Schema bigquery.Schema
+	// TotalRows is the number of rows read so far.
+	TotalRows uint64
+
+	conn *sql.Conn
+	rows *sql.Rows
+	err  error
+}
+
+// Next loads the next row into dst. Its return value is iterator.Done if there are no more results.
+// dst must be one of *[]bigquery.Value, *map[string]bigquery.Value, bigquery.ValueLoader or a pointer to struct.
+func (it *RowIterator) Next(dst interface{}) error {
+	if it.err != nil {
+		return it.err
+	}
+	if it.rows == nil {
+		return iterator.Done
+	}
+	if !it.rows.Next() {
+		err := it.rows.Err()
+		it.close()
+		if err != nil {
+			it.err = err
+			return err
+		}
+		return iterator.Done
+	}
+	values := make([]interface{}, len(it.Schema))
+	scanArgs := make([]interface{}, len(it.Schema))
+	for i := range values {
+		scanArgs[i] = &values[i]
+	}
+	if err := it.rows.Scan(scanArgs...); err != nil {
+		return fmt.Errorf("bqclient: failed to scan row: %w", err)
+	}
+	row := make([]bigquery.Value, 0, len(values))
+	for i, v := range values {
+		converted, err := convertValue(it.Schema[i], v)
+		if err != nil {
+			return fmt.Errorf("bqclient: failed to convert value of %s: %w", it.Schema[i].Name, err)
+		}
+		row = append(row, converted)
+	}
+	it.TotalRows++
+	return loadRow(dst, row, it.Schema)
+}
+
+func (it *RowIterator) close() {
+	if it.rows != nil {
+		it.rows.Close()
+		it.rows = nil
+	}
+	if it.conn != nil {
+		it.conn.Close()
+		it.conn = nil
+	}
+}
+
+func loadRow(dst interface{}, row []bigquery.Value, schema bigquery.Schema) error {
+	switch d := dst.(type) {
+	case *[]bigquery.Value:
+		*d = row
+		return nil
+	case *map[string]bigquery.Value:
+		m := make(map[string]bigquery.Value, len(row))
+		for i, v := range row {
+			m[schema[i].Name] = v
+		}
+		*d = m
+		return nil
+	case bigquery.ValueLoader:
+		return d.Load(row, schema)
+	}
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("bqclient: unsupported destination type %T", dst)
+	}
+	return loadStruct(rv.Elem(), row, schema)
+}
+
+func loadStruct(dst reflect.Value, row []bigquery.Value, schema bigquery.Schema) error {
+	typ := dst.Type()
+	fieldMap := map[string]int{}
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		name := field.Name
+		if tag := strings.Split(field.Tag.Get("bigquery"), ",")[0]; tag != "" {
+			if tag == "-" {
+				continue
+			}
+			name = tag
+		}
+		fieldMap[strings.ToLower(name)] = i
+	}
+	for i, v := range row {
+		idx, exists := fieldMap[strings.ToLower(schema[i].Name)]
+		if !exists {
+			continue
+		}
+		if err := setValue(dst.Field(idx), v, schema[i]); err != nil {
+			return fmt.Errorf("bqclient: failed to set %s: %w", schema[i].Name, err)
+		}
+	}
+	return nil
+}
+
+func setValue(dst reflect.Value, v bigquery.Value, field *bigquery.FieldSchema) error {
+	if v == nil {
+		dst.Set(reflect.Zero(dst.Type()))
+		return nil
+	}
+	if values, ok := v.([]bigquery.Value); ok {
+		if field.Repeated && dst.Kind() == reflect.Slice {
+			elemField := *field
+			elemField.Repeated = false
+			slice := reflect.MakeSlice(dst.Type(), len(values), len(values))
+			for i, elem := range values {
+				if err := setValue(slice.Index(i), elem, &elemField); err != nil {
+					return err
+				}
+			}
+			dst.Set(slice)
+			return nil
+		}
+		if field.Type == bigquery.RecordFieldType && dst.Kind() == reflect.Struct {
+			return loadStruct(dst, values, field.Schema)
+		}
+	}
+	rv := reflect.ValueOf(v)
+	switch {
+	case rv.Type().AssignableTo(dst.Type()):
+		dst.Set(rv)
+	case rv.Type().ConvertibleTo(dst.Type()):
+		dst.Set(rv.Convert(dst.Type()))
+	default:
+		return fmt.Errorf("cannot assign %T to %s", v, dst.Type())
+	}
+	return nil
+}
+
+// convertValue converts the value scanned by database/sql to the value type used by cloud.google.com/go/bigquery.
+func convertValue(field *bigquery.FieldSchema, v interface{}) (bigquery.Value, error) {
+	if v == nil {
+		return nil, nil
+	}
+	if field.Repeated {
+		array, ok := v.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unexpected array value %T", v)
+		}
+		elemField := *field
+		elemField.Repeated = false
+		values := make([]bigquery.Value, 0, len(array))
+		for _, elem := range array {
+			converted, err := convertValue(&elemField, elem)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, converted)
+		}
+		return values, nil
+	}
+	switch field.Type {
+	case bigquery.RecordFieldType:
+		fields, ok := v.([]map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unexpected struct value %T", v)
+		}
+		if len(fields) != len(field.Schema) {
+			return nil, fmt.Errorf("unexpected number of struct fields %d", len(fields))
+		}
+		values := make([]bigquery.Value, 0, len(fields))
+		for i, f := range fields {
+			converted, err := convertValue(field.Schema[i], f[field.Schema[i].Name])
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, converted)
+		}
+		return values, nil
+	case bigquery.BytesFieldType:
+		return base64.StdEncoding.DecodeString(fmt.Sprint(v))
+	case bigquery.DateFieldType:
+		return civil.ParseDate(fmt.Sprint(v))
+	case bigquery.TimeFieldType:
+		return civil.ParseTime(fmt.Sprint(v))
+	case bigquery.DateTimeFieldType:
+		return civil.ParseDateTime(fmt.Sprint(v))
+	case bigquery.TimestampFieldType:
+		return parseTimestamp(fmt.Sprint(v))
+	case bigquery.NumericFieldType, bigquery.BigNumericFieldType:
+		r, ok := new(big.Rat).SetString(fmt.Sprint(v))
+		if !ok {
+			return nil, fmt.Errorf("failed to parse numeric value %v", v)
+		}
+		return r, nil
+	case bigquery.IntervalFieldType:
+		return bigquery.ParseInterval(fmt.Sprint(v))
+	}
+	return v, nil
+}
+
+// parseTimestamp parses TIMESTAMP value formatted as `seconds.microseconds` by zetasqlite.
+func parseTimestamp(s string) (time.Time, error) {
+	secText, microText, _ := strings.Cut(s, ".")
+	sec, err := strconv.ParseInt(secText, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse timestamp value %s: %w", s, err)
+	}
+	var micro int64
+	if microText != "" {
+		micro, err = strconv.ParseInt(microText, 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("failed to parse timestamp value %s: %w", s, err)
+		}
+	}
+	return time.Unix(sec, micro*int64(time.Microsecond)).UTC(), nil
+}
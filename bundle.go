@@ -0,0 +1,39 @@
+package zetasqlite
+
+import (
+	"context"
+
+	internal "github.com/goccy/go-zetasqlite/internal"
+)
+
+type (
+	BundleFile       = internal.BundleFile
+	BundleFileResult = internal.BundleFileResult
+)
+
+// ReadBundleFiles reads all .sql files under the directory recursively in the lexical order of their paths.
+func ReadBundleFiles(dir string) ([]*BundleFile, error) {
+	return internal.ReadBundleFiles(dir)
+}
+
+// RunBundle runs all .sql files under the directory like deploying the dataset to BigQuery.
+// The files are run in the order of their dependencies, so the file creating a table, view, function or procedure
+// is run before the files referencing it regardless of the file names.
+// The results are returned in the executed order with the error of each file.
+// If a file fails, the files depending on it are skipped, but the other files are still run.
+// The returned error is reported only if the files can't be read.
+func (c *ZetaSQLiteConn) RunBundle(ctx context.Context, dir string) ([]*BundleFileResult, error) {
+	files, err := ReadBundleFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	return c.RunBundleFiles(ctx, files), nil
+}
+
+// RunBundleFiles runs the files in the order of their dependencies like RunBundle.
+func (c *ZetaSQLiteConn) RunBundleFiles(ctx context.Context, files []*BundleFile) []*BundleFileResult {
+	return c.analyzer.RunBundle(ctx, files, func(ctx context.Context, query string) error {
+		_, err := c.ExecContext(ctx, query, nil)
+		return err
+	})
+}
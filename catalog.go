@@ -1,10 +1,13 @@
 package zetasqlite
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"reflect"
 
+	"github.com/goccy/go-zetasql"
+
 	internal "github.com/goccy/go-zetasqlite/internal"
 )
 
@@ -17,11 +20,43 @@ type (
 	NameWithType    = internal.NameWithType
 	ColumnSpec      = internal.ColumnSpec
 	Type            = internal.Type
+	ForeignKeySpec  = internal.ForeignKeySpec
+	ConstraintSpec  = internal.ConstraintSpec
+	ConstraintKind  = internal.ConstraintKind
+	// LanguageFeature is the ZetaSQL language feature ( e.g. zetasql.FeatureV13Qualify ).
+	LanguageFeature = zetasql.LanguageFeature
+)
+
+const (
+	ConstraintKindPrimaryKey = internal.ConstraintKindPrimaryKey
+	ConstraintKindForeignKey = internal.ConstraintKindForeignKey
+	ConstraintKindNotNull    = internal.ConstraintKindNotNull
 )
 
+// Tables returns the specs of tables and views registered to the catalog sorted by name.
+// The specs are shared with the catalog, so they must not be modified.
+func (c *ZetaSQLiteConn) Tables(ctx context.Context) ([]*TableSpec, error) {
+	return c.analyzer.Tables(ctx, internal.NewConn(c.conn, c.tx))
+}
+
+// UserDefinedFunctions returns the specs of user defined functions that can be called from the connection sorted by name.
+// The result includes the temporary functions created by the connection.
+// The specs are shared with the catalog, so they must not be modified.
+func (c *ZetaSQLiteConn) UserDefinedFunctions(ctx context.Context) ([]*FunctionSpec, error) {
+	return c.analyzer.UserDefinedFunctions(ctx, internal.NewConn(c.conn, c.tx))
+}
+
 // ChangedCatalogFromRows retrieve modified catalog information from sql.Rows.
 // NOTE: This API relies on the internal structure of sql.Rows, so not will work for all Go versions.
 func ChangedCatalogFromRows(rows *sql.Rows) (*ChangedCatalog, error) {
+	zetasqliteRows, err := zetasqliteRowsFromRows(rows)
+	if err != nil {
+		return nil, err
+	}
+	return zetasqliteRows.ChangedCatalog(), nil
+}
+
+func zetasqliteRowsFromRows(rows *sql.Rows) (*internal.Rows, error) {
 	if rows == nil {
 		return nil, fmt.Errorf("zetasqlite: sql.Rows instance required not nil")
 	}
@@ -34,13 +69,20 @@ func ChangedCatalogFromRows(rows *sql.Rows) (*ChangedCatalog, error) {
 	if driverValue.Type() != reflect.TypeOf(new(internal.Rows)) {
 		return nil, fmt.Errorf("zetasqlite: sql.Rows must be an instance created using the zetasqlite database driver")
 	}
-	zetasqliteRows := (*internal.Rows)(driverValue.UnsafePointer())
-	return zetasqliteRows.ChangedCatalog(), nil
+	return (*internal.Rows)(driverValue.UnsafePointer()), nil
 }
 
 // ChangedCatalogFromResult retrieve modified catalog information from sql.Result.
 // NOTE: This API relies on the internal structure of sql.Result, so not will work for all Go versions.
 func ChangedCatalogFromResult(result sql.Result) (*ChangedCatalog, error) {
+	zetasqliteResult, err := zetasqliteResultFromResult(result)
+	if err != nil {
+		return nil, err
+	}
+	return zetasqliteResult.ChangedCatalog(), nil
+}
+
+func zetasqliteResultFromResult(result sql.Result) (*internal.Result, error) {
 	rv := reflect.ValueOf(result)
 	if rv.Kind() != reflect.Struct {
 		return nil, fmt.Errorf("zetasqlite: unexpected sql.Result layout. expected sql.Result type is struct but got %T", result)
@@ -53,6 +95,5 @@ func ChangedCatalogFromResult(result sql.Result) (*ChangedCatalog, error) {
 	if driverValue.Type() != reflect.TypeOf(new(internal.Result)) {
 		return nil, fmt.Errorf("zetasqlite: sql.Result must be an instance created using the zetasqlite database driver")
 	}
-	zetasqliteResult := (*internal.Result)(driverValue.UnsafePointer())
-	return zetasqliteResult.ChangedCatalog(), nil
+	return (*internal.Result)(driverValue.UnsafePointer()), nil
 }
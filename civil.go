@@ -0,0 +1,120 @@
+package zetasqlite
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/civil"
+)
+
+// NullDate represents a DATE value that may be NULL.
+// It can be used as a scan target and a query parameter to interoperate with the types of cloud.google.com/go/bigquery.
+type NullDate struct {
+	Date  civil.Date
+	Valid bool // Valid is true if Date is not NULL.
+}
+
+// Scan implements the sql.Scanner interface.
+func (n *NullDate) Scan(src interface{}) error {
+	if src == nil {
+		n.Date, n.Valid = civil.Date{}, false
+		return nil
+	}
+	switch v := src.(type) {
+	case string:
+		date, err := civil.ParseDate(v)
+		if err != nil {
+			return fmt.Errorf("failed to scan DATE value %q: %w", v, err)
+		}
+		n.Date = date
+	case time.Time:
+		n.Date = civil.DateOf(v)
+	default:
+		return fmt.Errorf("unexpected DATE value type %T", src)
+	}
+	n.Valid = true
+	return nil
+}
+
+// Value implements the driver.Valuer interface.
+func (n NullDate) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return n.Date, nil
+}
+
+// NullDateTime represents a DATETIME value that may be NULL.
+// It can be used as a scan target and a query parameter to interoperate with the types of cloud.google.com/go/bigquery.
+type NullDateTime struct {
+	DateTime civil.DateTime
+	Valid    bool // Valid is true if DateTime is not NULL.
+}
+
+// Scan implements the sql.Scanner interface.
+func (n *NullDateTime) Scan(src interface{}) error {
+	if src == nil {
+		n.DateTime, n.Valid = civil.DateTime{}, false
+		return nil
+	}
+	switch v := src.(type) {
+	case string:
+		datetime, err := civil.ParseDateTime(v)
+		if err != nil {
+			return fmt.Errorf("failed to scan DATETIME value %q: %w", v, err)
+		}
+		n.DateTime = datetime
+	case time.Time:
+		n.DateTime = civil.DateTimeOf(v)
+	default:
+		return fmt.Errorf("unexpected DATETIME value type %T", src)
+	}
+	n.Valid = true
+	return nil
+}
+
+// Value implements the driver.Valuer interface.
+func (n NullDateTime) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return n.DateTime, nil
+}
+
+// NullTime represents a TIME value that may be NULL.
+// It can be used as a scan target and a query parameter to interoperate with the types of cloud.google.com/go/bigquery.
+type NullTime struct {
+	Time  civil.Time
+	Valid bool // Valid is true if Time is not NULL.
+}
+
+// Scan implements the sql.Scanner interface.
+func (n *NullTime) Scan(src interface{}) error {
+	if src == nil {
+		n.Time, n.Valid = civil.Time{}, false
+		return nil
+	}
+	switch v := src.(type) {
+	case string:
+		t, err := civil.ParseTime(v)
+		if err != nil {
+			return fmt.Errorf("failed to scan TIME value %q: %w", v, err)
+		}
+		n.Time = t
+	case time.Time:
+		n.Time = civil.TimeOf(v)
+	default:
+		return fmt.Errorf("unexpected TIME value type %T", src)
+	}
+	n.Valid = true
+	return nil
+}
+
+// Value implements the driver.Valuer interface.
+func (n NullTime) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return n.Time, nil
+}
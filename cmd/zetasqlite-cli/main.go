@@ -3,7 +3,6 @@ package main
 import (
 	"context"
 	"database/sql"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -15,7 +14,6 @@ import (
 	"github.com/fatih/color"
 	"github.com/goccy/go-zetasql/types"
 	"github.com/goccy/go-zetasqlite"
-	"github.com/goccy/go-zetasqlite/internal"
 	"github.com/jessevdk/go-flags"
 	"github.com/olekukonko/tablewriter"
 	"golang.org/x/crypto/ssh/terminal"
@@ -37,7 +35,7 @@ const (
 )
 
 const (
-	zetasqliteRawDriver = "zetasqlite_sqlite3"
+	zetasqliteRawDriver = zetasqlite.SQLiteDriverName
 	zetasqliteDriver    = "zetasqlite"
 )
 
@@ -171,68 +169,140 @@ func (cli *CLI) runCommand(ctx context.Context, query string) error {
 	case ".tables":
 		return cli.showTablesCommand(ctx)
 	case ".functions":
-		return cli.showFunctionsCommand(ctx)
+		return cli.showFunctionsCommand(ctx, subCommands)
 	case ".explain":
 		return cli.explainModeCommand(ctx, subCommands)
 	case ".autoindex":
 		return cli.autoIndexModeCommand(ctx, subCommands)
+	case ".bundle":
+		return cli.runBundleCommand(ctx, subCommands)
 	}
 	return cli.defaultCommand(ctx, query)
 }
 
 func (cli *CLI) showTablesCommand(ctx context.Context) error {
-	db, err := sql.Open(zetasqliteRawDriver, cli.getDSN())
+	db, err := sql.Open(zetasqliteDriver, cli.getDSN())
 	if err != nil {
 		return fmt.Errorf("failed to open zetasqlite driver: %w", err)
 	}
 	defer db.Close()
 
-	rows, err := db.QueryContext(ctx, `SELECT name, spec FROM zetasqlite_catalog WHERE kind = "table"`)
+	conn, err := db.Conn(ctx)
 	if err != nil {
-		return nil
+		return fmt.Errorf("failed to get connection: %w", err)
 	}
-	for rows.Next() {
-		var (
-			name string
-			spec string
-		)
-		if err := rows.Scan(&name, &spec); err != nil {
-			return err
+	defer conn.Close()
+
+	var tables []*zetasqlite.TableSpec
+	if err := conn.Raw(func(c interface{}) error {
+		zetasqliteConn, ok := c.(*zetasqlite.ZetaSQLiteConn)
+		if !ok {
+			return fmt.Errorf("failed to get ZetaSQLiteConn from %T", c)
 		}
-		var table internal.TableSpec
-		if err := json.Unmarshal([]byte(spec), &table); err != nil {
+		specs, err := zetasqliteConn.Tables(ctx)
+		if err != nil {
 			return err
 		}
+		tables = specs
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to get tables: %w", err)
+	}
+	for _, table := range tables {
 		fmt.Fprintf(cli.out, "%s\n", strings.Join(table.NamePath, "."))
 	}
 	return nil
 }
 
-func (cli *CLI) showFunctionsCommand(ctx context.Context) error {
-	db, err := sql.Open(zetasqliteRawDriver, cli.getDSN())
+func (cli *CLI) showFunctionsCommand(ctx context.Context, subCommands []string) error {
+	db, err := sql.Open(zetasqliteDriver, cli.getDSN())
 	if err != nil {
 		return fmt.Errorf("failed to open zetasqlite driver: %w", err)
 	}
 	defer db.Close()
 
-	rows, err := db.QueryContext(ctx, `SELECT name, spec FROM zetasqlite_catalog WHERE kind = "function"`)
+	conn, err := db.Conn(ctx)
 	if err != nil {
-		return nil
+		return fmt.Errorf("failed to get connection: %w", err)
 	}
-	for rows.Next() {
-		var (
-			name string
-			spec string
-		)
-		if err := rows.Scan(&name, &spec); err != nil {
+	defer conn.Close()
+
+	var functions []*zetasqlite.FunctionInfo
+	if err := conn.Raw(func(c interface{}) error {
+		zetasqliteConn, ok := c.(*zetasqlite.ZetaSQLiteConn)
+		if !ok {
+			return fmt.Errorf("failed to get ZetaSQLiteConn from %T", c)
+		}
+		fns, err := zetasqliteConn.Functions(ctx)
+		if err != nil {
 			return err
 		}
-		var fn internal.FunctionSpec
-		if err := json.Unmarshal([]byte(spec), &fn); err != nil {
+		functions = fns
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to get functions: %w", err)
+	}
+	var prefix string
+	if len(subCommands) != 0 {
+		prefix = strings.ToUpper(subCommands[0])
+	}
+	for _, fn := range functions {
+		if prefix != "" && !strings.HasPrefix(strings.ToUpper(fn.Name), prefix) {
+			continue
+		}
+		if fn.Kind == zetasqlite.FunctionKindBuiltin && prefix == "" {
+			continue
+		}
+		for _, sig := range fn.Signatures {
+			fmt.Fprintf(cli.out, "%s%s [%s]\n", fn.Name, sig, fn.Kind)
+		}
+	}
+	return nil
+}
+
+func (cli *CLI) runBundleCommand(ctx context.Context, subCommands []string) error {
+	if len(subCommands) == 0 {
+		fmt.Fprintf(cli.out, ".bundle requires directory argument\n")
+		return nil
+	}
+	db, err := sql.Open(zetasqliteDriver, cli.getDSN())
+	if err != nil {
+		return fmt.Errorf("failed to open zetasqlite driver: %w", err)
+	}
+	defer db.Close()
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+
+	var results []*zetasqlite.BundleFileResult
+	if err := conn.Raw(func(c interface{}) error {
+		zetasqliteConn, ok := c.(*zetasqlite.ZetaSQLiteConn)
+		if !ok {
+			return fmt.Errorf("failed to get ZetaSQLiteConn from %T", c)
+		}
+		res, err := zetasqliteConn.RunBundle(ctx, subCommands[0])
+		if err != nil {
 			return err
 		}
-		fmt.Fprintf(cli.out, "%s\n", strings.Join(fn.NamePath, "."))
+		results = res
+		return nil
+	}); err != nil {
+		fmt.Fprintf(cli.out, "ERROR: %v\n", err)
+		return nil
+	}
+	var failedNum int
+	for _, result := range results {
+		if result.Err != nil {
+			failedNum++
+			fmt.Fprintf(cli.out, "FAIL %s: %v\n", result.Path, result.Err)
+			continue
+		}
+		fmt.Fprintf(cli.out, "OK   %s\n", result.Path)
 	}
+	fmt.Fprintf(cli.out, "%d files, %d failed\n", len(results), failedNum)
 	return nil
 }
 
@@ -0,0 +1,109 @@
+package zetasqlite
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	internal "github.com/goccy/go-zetasqlite/internal"
+)
+
+// ColumnarResult holds query results laid out by column instead of by row.
+type ColumnarResult struct {
+	Columns []*ColumnarColumn
+	NumRows int
+}
+
+// ColumnarColumn holds all values of a single result column.
+// NULL values are represented by nil.
+type ColumnarColumn struct {
+	Name   string
+	Type   *ColumnType
+	Values []interface{}
+}
+
+// Column returns the column that matches the specified name.
+func (r *ColumnarResult) Column(name string) *ColumnarColumn {
+	for _, col := range r.Columns {
+		if col.Name == name {
+			return col
+		}
+	}
+	return nil
+}
+
+// QueryColumnar executes a query and returns the whole result set in columnar layout.
+// Values are converted the same way as scanning into interface{} destinations via database/sql,
+// but without the per-row overhead of sql.Rows.
+func (c *ZetaSQLiteConn) QueryColumnar(ctx context.Context, query string, args ...interface{}) (*ColumnarResult, error) {
+	driverRows, err := c.QueryContext(ctx, query, namedValuesFromArgs(args))
+	if err != nil {
+		return nil, err
+	}
+	rows, _ := driverRows.(*internal.Rows)
+	if rows == nil {
+		return &ColumnarResult{}, nil
+	}
+	defer rows.Close()
+
+	outputColumns := rows.OutputColumns()
+	columns := make([]*ColumnarColumn, 0, len(outputColumns))
+	for _, col := range outputColumns {
+		columns = append(columns, &ColumnarColumn{
+			Name: col.Name,
+			Type: col.Type,
+		})
+	}
+	dest := make([]driver.Value, len(columns))
+	var numRows int
+	for {
+		if err := rows.Next(dest); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("zetasqlite: failed to read row: %w", err)
+		}
+		for idx, v := range dest {
+			columns[idx].Values = append(columns[idx].Values, v)
+		}
+		numRows++
+	}
+	return &ColumnarResult{
+		Columns: columns,
+		NumRows: numRows,
+	}, nil
+}
+
+func namedValuesFromArgs(args []interface{}) []driver.NamedValue {
+	values := make([]driver.NamedValue, 0, len(args))
+	for idx, arg := range args {
+		value := driver.NamedValue{Ordinal: idx + 1, Value: arg}
+		if named, ok := arg.(sql.NamedArg); ok {
+			value.Name = named.Name
+			value.Value = named.Value
+		}
+		value.Value = convertArg(value.Value)
+		values = append(values, value)
+	}
+	return values
+}
+
+// convertArg converts the argument by driver.DefaultParameterConverter like database/sql ( e.g. the named types of int64 or driver.Valuer ).
+// The values that the converter doesn't support ( e.g. *big.Rat, civil.Date, slices and structs ) are returned as they are,
+// because ZetaSQLiteConn accepts them as the parameters of ARRAY, STRUCT and the other types by CheckNamedValue.
+// json.RawMessage is also returned as it is, because the converter turns it into []byte ( BYTES ) instead of JSON.
+func convertArg(arg interface{}) interface{} {
+	switch arg.(type) {
+	case json.RawMessage, *json.RawMessage:
+		return arg
+	}
+	converted, err := driver.DefaultParameterConverter.ConvertValue(arg)
+	if err != nil {
+		return arg
+	}
+	return converted
+}
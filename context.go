@@ -18,3 +18,15 @@ func WithCurrentTime(ctx context.Context, now time.Time) context.Context {
 func CurrentTime(ctx context.Context) *time.Time {
 	return internal.CurrentTime(ctx)
 }
+
+// WithQueryLabels attaches the labels to the queries executed with the returned context.
+// The labels are merged with the labels specified by SET @@query_label and exposed by QueryStatistics.Labels.
+// The labels override the labels of the same keys specified by SET @@query_label.
+func WithQueryLabels(ctx context.Context, labels map[string]string) context.Context {
+	return internal.WithQueryLabels(ctx, labels)
+}
+
+// QueryLabels gets the labels specified by WithQueryLabels.
+func QueryLabels(ctx context.Context) map[string]string {
+	return internal.QueryLabels(ctx)
+}
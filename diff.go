@@ -0,0 +1,64 @@
+package zetasqlite
+
+import (
+	"context"
+	"fmt"
+
+	internal "github.com/goccy/go-zetasqlite/internal"
+)
+
+type (
+	RowsDiff   = internal.RowsDiff
+	RowDiff    = internal.RowDiff
+	DiffReason = internal.DiffReason
+)
+
+const (
+	DiffReasonMissing    = internal.DiffReasonMissing
+	DiffReasonUnexpected = internal.DiffReasonUnexpected
+)
+
+// DiffQueries executes the expected and actual queries and returns the mismatched rows with the reason.
+// The rows are compared regardless of the order, and the columns are compared by the position.
+// The args are passed to both queries, so use named parameters if the queries have different parameters.
+// This is useful to assert the result of the query against the fixture ( e.g. the result of BigQuery saved as the table ).
+func (c *ZetaSQLiteConn) DiffQueries(ctx context.Context, expectedQuery, actualQuery string, args ...interface{}) (*RowsDiff, error) {
+	expected, err := c.queryRowsForDiff(ctx, expectedQuery, args)
+	if err != nil {
+		return nil, err
+	}
+	defer expected.Close()
+	actual, err := c.queryRowsForDiff(ctx, actualQuery, args)
+	if err != nil {
+		return nil, err
+	}
+	defer actual.Close()
+	diff, err := internal.DiffRows(expected, actual)
+	if err != nil {
+		return nil, fmt.Errorf("zetasqlite: %w", err)
+	}
+	return diff, nil
+}
+
+// DiffTables returns the mismatched rows of the expected and actual tables with the reason.
+// The table name can be specified with dots ( e.g. `project.dataset.table` ), and the name path of the connection is applied.
+// See DiffQueries for how the rows are compared.
+func (c *ZetaSQLiteConn) DiffTables(ctx context.Context, expectedTable, actualTable string) (*RowsDiff, error) {
+	return c.DiffQueries(
+		ctx,
+		fmt.Sprintf("SELECT * FROM `%s`", expectedTable),
+		fmt.Sprintf("SELECT * FROM `%s`", actualTable),
+	)
+}
+
+func (c *ZetaSQLiteConn) queryRowsForDiff(ctx context.Context, query string, args []interface{}) (*internal.Rows, error) {
+	driverRows, err := c.QueryContext(ctx, query, namedValuesFromArgs(args))
+	if err != nil {
+		return nil, err
+	}
+	rows, _ := driverRows.(*internal.Rows)
+	if rows == nil {
+		return nil, fmt.Errorf("zetasqlite: query doesn't return rows: %s", query)
+	}
+	return rows, nil
+}
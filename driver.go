@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"database/sql/driver"
 	"fmt"
+	"math"
 	"sync"
 
 	"github.com/mattn/go-sqlite3"
@@ -18,48 +19,97 @@ var (
 	_ driver.Tx     = &ZetaSQLiteTx{}
 )
 
+// SQLiteDriverName is the name of the SQLite driver used by zetasqlite internally.
+// All zetasqlite_* functions are registered to the connections opened by this driver,
+// so the queries formatted by zetasqlite ( e.g. the queries shown by explain mode ) can be executed as they are.
+const SQLiteDriverName = "zetasqlite_sqlite3"
+
 var (
 	nameToCatalogMap = map[string]*internal.Catalog{}
 	nameToDBMap      = map[string]*sql.DB{}
 	nameToValueMapMu sync.Mutex
 )
 
+// sqliteLimitIDs are the limits raised to allow the large queries generated from BigQuery SQL,
+// such as INSERT statements with many parameters or long UNION ALL chains.
+var sqliteLimitIDs = []int{
+	sqlite3.SQLITE_LIMIT_VARIABLE_NUMBER,
+	sqlite3.SQLITE_LIMIT_COMPOUND_SELECT,
+	sqlite3.SQLITE_LIMIT_FUNCTION_ARG,
+	sqlite3.SQLITE_LIMIT_EXPR_DEPTH,
+}
+
 func init() {
 	sql.Register("zetasqlite", &ZetaSQLiteDriver{})
-	sql.Register("zetasqlite_sqlite3", &sqlite3.SQLiteDriver{
-		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
-			if err := internal.RegisterFunctions(conn); err != nil {
-				return err
-			}
-			conn.SetLimit(sqlite3.SQLITE_LIMIT_VARIABLE_NUMBER, -1)
-			return nil
-		},
-	})
+	sql.Register(SQLiteDriverName, &sqlite3.SQLiteDriver{ConnectHook: setupSQLiteConn})
+}
+
+func setupSQLiteConn(conn *sqlite3.SQLiteConn) error {
+	if err := internal.RegisterFunctions(conn); err != nil {
+		return err
+	}
+	for _, id := range sqliteLimitIDs {
+		// SQLite caps the value at the upper bound of the build, so this raises the limit to the maximum.
+		conn.SetLimit(id, math.MaxInt32)
+	}
+	return nil
 }
 
-func newDBAndCatalog(name string) (*sql.DB, *internal.Catalog, error) {
+func newDBAndCatalog(name string, opts *dsnOptions) (*sql.DB, *internal.Catalog, error) {
 	nameToValueMapMu.Lock()
 	defer nameToValueMapMu.Unlock()
 	db, exists := nameToDBMap[name]
 	if exists {
 		return db, nameToCatalogMap[name], nil
 	}
-	db, err := sql.Open("zetasqlite_sqlite3", name)
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to open database by %s: %w", name, err)
+	if len(opts.attachedDatasets) == 0 {
+		var err error
+		db, err = sql.Open(SQLiteDriverName, opts.dsn)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open database by %s: %w", name, err)
+		}
+	} else {
+		db = sql.OpenDB(&sqliteConnector{dsn: opts.dsn, driver: newSQLiteDriver(opts.attachedDatasets)})
 	}
 	catalog := internal.NewCatalog(db)
+	if err := catalog.SetAttachedDatasets(opts.attachedDatasets); err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+	if err := migrateValueEncodingOnOpen(db, catalog, opts.readOnly); err != nil {
+		db.Close()
+		return nil, nil, err
+	}
 	nameToDBMap[name] = db
 	nameToCatalogMap[name] = catalog
 	return db, catalog, nil
 }
 
+// migrateValueEncodingOnOpen migrates the values stored by the legacy encoding of older versions before the database is used,
+// so they are not mixed with the values stored by the current version.
+func migrateValueEncodingOnOpen(db *sql.DB, catalog *internal.Catalog, readOnly bool) error {
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get sqlite3 connection: %w", err)
+	}
+	defer conn.Close()
+	if err := internal.MigrateValueEncodingOnOpen(ctx, catalog, internal.NewConn(conn, nil), readOnly); err != nil {
+		return fmt.Errorf("failed to migrate value encoding: %w", err)
+	}
+	return nil
+}
+
 type ZetaSQLiteDriver struct {
 	ConnectHook func(*ZetaSQLiteConn) error
 }
 
 func (d *ZetaSQLiteDriver) Open(name string) (driver.Conn, error) {
-	db, catalog, err := newDBAndCatalog(name)
+	opts, err := parseDSN(name)
+	if err != nil {
+		return nil, err
+	}
+	db, catalog, err := newDBAndCatalog(name, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -67,6 +117,8 @@ func (d *ZetaSQLiteDriver) Open(name string) (driver.Conn, error) {
 	if err != nil {
 		return nil, err
 	}
+	conn.SetReadOnlyMode(opts.readOnly)
+	conn.SetResultCacheMode(opts.resultCache)
 	if d.ConnectHook != nil {
 		if err := d.ConnectHook(conn); err != nil {
 			return nil, err
@@ -76,9 +128,11 @@ func (d *ZetaSQLiteDriver) Open(name string) (driver.Conn, error) {
 }
 
 type ZetaSQLiteConn struct {
-	conn     *sql.Conn
-	tx       *sql.Tx
-	analyzer *internal.Analyzer
+	conn               *sql.Conn
+	tx                 *sql.Tx
+	analyzer           *internal.Analyzer
+	catalog            *internal.Catalog
+	insertIDWindowSize int
 }
 
 func newZetaSQLiteConn(db *sql.DB, catalog *internal.Catalog) (*ZetaSQLiteConn, error) {
@@ -91,8 +145,10 @@ func newZetaSQLiteConn(db *sql.DB, catalog *internal.Catalog) (*ZetaSQLiteConn,
 		return nil, fmt.Errorf("failed to create analyzer: %w", err)
 	}
 	return &ZetaSQLiteConn{
-		conn:     conn,
-		analyzer: analyzer,
+		conn:               conn,
+		analyzer:           analyzer,
+		catalog:            catalog,
+		insertIDWindowSize: internal.DefaultInsertIDWindowSize,
 	}, nil
 }
 
@@ -104,6 +160,105 @@ func (c *ZetaSQLiteConn) SetExplainMode(enabled bool) {
 	c.analyzer.SetExplainMode(enabled)
 }
 
+// SetStrictMode enables the enforcement of parameterized types ( e.g. STRING(10) or NUMERIC(10, 2) ).
+// If enabled, INSERT and UPDATE statements return an error when the value exceeds the maximum length or precision,
+// and NUMERIC values are rounded to the scale of the column.
+func (c *ZetaSQLiteConn) SetStrictMode(enabled bool) {
+	c.analyzer.SetStrictMode(enabled)
+}
+
+// SetReadOnlyMode rejects the statements that modify the database at analysis time.
+// In read-only mode, DDL and DML statements ( CREATE, DROP, INSERT, UPDATE, DELETE, MERGE and TRUNCATE TABLE ) return an error,
+// so the shared fixture database can be opened by the parallel tests without accidental mutation.
+// CREATE TEMP FUNCTION is still allowed, because the function is visible only to the connection.
+// Read-only mode can also be enabled by `_zetasqlite_read_only=true` DSN parameter.
+func (c *ZetaSQLiteConn) SetReadOnlyMode(enabled bool) {
+	c.analyzer.SetReadOnlyMode(enabled)
+}
+
+// SetResultCacheMode enables the cache of the query results like the cached query results of BigQuery.
+// The results are shared by the connections opened by the same DSN, and the same query with the same arguments
+// returns the cached result until the statement that may modify the database ( DDL, DML or the commit of the transaction ) is executed.
+// The cache is not used for the queries calling non-deterministic functions ( e.g. CURRENT_TIMESTAMP or RAND ),
+// the queries in the transaction and the prepared statements.
+// Whether the result is returned from the cache is reported by CacheHit of QueryStatistics.
+// The changes made by the other processes or through RawSQLiteConn are not detected, so the cache is invalidated after RawSQLiteConn is called.
+// Result cache mode can also be enabled by `_zetasqlite_result_cache=true` DSN parameter.
+func (c *ZetaSQLiteConn) SetResultCacheMode(enabled bool) {
+	c.analyzer.SetResultCacheMode(enabled)
+}
+
+// SetSubqueryDecorrelation enables the rewriting of correlated EXISTS and IN subqueries to uncorrelated IN subqueries ( enabled by default ).
+// SQLite evaluates a correlated subquery for each row of the outer query, so the rewriting makes the queries on large tables much faster.
+// The subquery is rewritten only if the correlated conditions are equalities with the columns of the outer query.
+// Disable it if the formatted query should keep the structure of the original query ( e.g. in explain mode ).
+func (c *ZetaSQLiteConn) SetSubqueryDecorrelation(enabled bool) {
+	c.analyzer.SetSubqueryDecorrelation(enabled)
+}
+
+// SetRowNumberDeduplication enables the rewriting of the deduplication pattern QUALIFY ROW_NUMBER() OVER (PARTITION BY k ORDER BY v DESC) = 1
+// to the group-wise MAX or MIN of the order column ( enabled by default ).
+// The window function is evaluated by scanning the whole input for each row, so the rewriting makes the deduplication of large tables much faster.
+// The pattern is rewritten only if ROW_NUMBER is the only window function and it's ordered by a single column whose NULL values are sorted last.
+// If multiple rows have the same value of the order column, one of them is returned like ROW_NUMBER.
+func (c *ZetaSQLiteConn) SetRowNumberDeduplication(enabled bool) {
+	c.analyzer.SetRowNumberDeduplication(enabled)
+}
+
+// SetMaxRecursiveIterations specifies the maximum number of iterations of the recursive WITH entries ( default 500 like BigQuery ).
+// If the recursive term of WITH RECURSIVE ... UNION ALL still produces rows after the iterations, the query returns an error
+// instead of running forever. UNION DISTINCT stops when no new rows are produced, so the limit isn't applied to it.
+// If num is 0, the number of iterations is not limited.
+func (c *ZetaSQLiteConn) SetMaxRecursiveIterations(num int) {
+	c.analyzer.SetMaxRecursiveIterations(num)
+}
+
+// RawSQLiteConn calls f with the underlying SQLite connection where all zetasqlite_* functions are registered.
+// The connection is shared with the queries executed by zetasqlite ( including the transaction started by BeginTx ),
+// so the changes made by f are visible to them.
+// Note that the tables created through the SQLite connection are not registered to the catalog of zetasqlite.
+func (c *ZetaSQLiteConn) RawSQLiteConn(f func(*sqlite3.SQLiteConn) error) error {
+	defer c.catalog.InvalidateResultCache()
+	return c.conn.Raw(func(driverConn interface{}) error {
+		conn, ok := driverConn.(*sqlite3.SQLiteConn)
+		if !ok {
+			return fmt.Errorf("unexpected sqlite3 connection type %T", driverConn)
+		}
+		return f(conn)
+	})
+}
+
+// SetDefaultParameters predefines the named parameters for all queries executed by the connection.
+// The parameters are declared with the type of the value, so they can be referenced in any expression ( e.g. SELECT @run_date ).
+// If the arguments of the query contain the parameter of the same name, the argument is used instead.
+// Default parameters are not applied to prepared statements.
+func (c *ZetaSQLiteConn) SetDefaultParameters(params map[string]interface{}) error {
+	return c.analyzer.SetDefaultParameters(params)
+}
+
+// LanguageFeatures returns the ZetaSQL language features enabled for this connection.
+func (c *ZetaSQLiteConn) LanguageFeatures() []LanguageFeature {
+	return c.analyzer.LanguageFeatures()
+}
+
+// SetLanguageFeatures replaces the ZetaSQL language features enabled for this connection.
+// This is useful to match the feature set of the production BigQuery.
+// Disabling features that zetasqlite relies on makes queries using them fail to analyze.
+func (c *ZetaSQLiteConn) SetLanguageFeatures(features []LanguageFeature) {
+	c.analyzer.SetLanguageFeatures(features)
+}
+
+// EnableLanguageFeatures enables the ZetaSQL language features for this connection ( e.g. zetasql.FeatureV14... ).
+// Features that are enabled but not implemented by zetasqlite return an error when executing the query.
+func (c *ZetaSQLiteConn) EnableLanguageFeatures(features ...LanguageFeature) {
+	c.analyzer.EnableLanguageFeatures(features...)
+}
+
+// DisableLanguageFeatures disables the ZetaSQL language features for this connection.
+func (c *ZetaSQLiteConn) DisableLanguageFeatures(features ...LanguageFeature) {
+	c.analyzer.DisableLanguageFeatures(features...)
+}
+
 // SetMaxNamePath specifies the maximum value of name path.
 // If the name path in the query is the maximum value, the name path set as prefix is not used.
 // Effective only when a value greater than zero is specified ( default zero ).
@@ -133,6 +288,14 @@ func (c *ZetaSQLiteConn) AddNamePath(path string) error {
 	return c.analyzer.AddNamePath(path)
 }
 
+// MigrateValueEncoding re-encodes the values stored by the older versions of zetasqlite with the current compact binary encoding.
+// The database is migrated automatically when it's opened for the first time by the current version,
+// so this is needed only for the tables copied from the older databases after that.
+func (c *ZetaSQLiteConn) MigrateValueEncoding(ctx context.Context) error {
+	defer c.catalog.InvalidateResultCache()
+	return c.analyzer.MigrateValueEncoding(ctx, internal.NewConn(c.conn, c.tx))
+}
+
 func (s *ZetaSQLiteConn) CheckNamedValue(value *driver.NamedValue) error {
 	return nil
 }
@@ -144,7 +307,7 @@ func (c *ZetaSQLiteConn) Prepare(query string) (driver.Stmt, error) {
 
 func (c *ZetaSQLiteConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
 	conn := internal.NewConn(c.conn, c.tx)
-	actionFuncs, err := c.analyzer.Analyze(ctx, conn, query, nil)
+	actionFuncs, err := c.analyzer.Analyze(internal.WithPreparedStatement(ctx), conn, query, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -158,11 +321,64 @@ func (c *ZetaSQLiteConn) PrepareContext(ctx context.Context, query string) (driv
 		if err != nil {
 			return nil, err
 		}
+		if _, isQuery := action.(*internal.QueryStmtAction); !isQuery && s != nil {
+			s = &resultCacheInvalidationStmt{Stmt: s, catalog: c.catalog, tables: modifiedTables(action)}
+		}
 		stmt = s
 	}
 	return stmt, nil
 }
 
+// invalidateResultCache discards the cached query results after the statement that may modify the database is executed.
+// If the tables modified by the statement are known, only the results reading them are discarded.
+func (c *ZetaSQLiteConn) invalidateResultCache(action internal.StmtAction) {
+	if _, isQuery := action.(*internal.QueryStmtAction); isQuery {
+		return
+	}
+	invalidateResultCache(c.catalog, modifiedTables(action))
+}
+
+func invalidateResultCache(catalog *internal.Catalog, tables []string) {
+	if tables == nil {
+		catalog.InvalidateResultCache()
+		return
+	}
+	catalog.InvalidateTableResultCache(tables...)
+}
+
+// modifiedTables returns the tables modified by the action, or nil if they are unknown.
+func modifiedTables(action internal.StmtAction) []string {
+	if modifier, ok := action.(internal.TableModifyingStmtAction); ok {
+		return modifier.ModifiedTables()
+	}
+	return nil
+}
+
+// resultCacheInvalidationStmt is the prepared statement that may modify the database.
+// It discards the cached query results after executing the statement.
+type resultCacheInvalidationStmt struct {
+	driver.Stmt
+	catalog *internal.Catalog
+	tables  []string
+}
+
+func (s *resultCacheInvalidationStmt) CheckNamedValue(value *driver.NamedValue) error {
+	if checker, ok := s.Stmt.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+	return nil
+}
+
+func (s *resultCacheInvalidationStmt) Exec(args []driver.Value) (driver.Result, error) {
+	defer invalidateResultCache(s.catalog, s.tables)
+	return s.Stmt.Exec(args)
+}
+
+func (s *resultCacheInvalidationStmt) Query(args []driver.Value) (driver.Rows, error) {
+	defer invalidateResultCache(s.catalog, s.tables)
+	return s.Stmt.Query(args)
+}
+
 func (c *ZetaSQLiteConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (r driver.Result, e error) {
 	conn := internal.NewConn(c.conn, c.tx)
 	actionFuncs, err := c.analyzer.Analyze(ctx, conn, query, args)
@@ -189,6 +405,7 @@ func (c *ZetaSQLiteConn) ExecContext(ctx context.Context, query string, args []d
 		}
 		actions = append(actions, action)
 		r, err := action.ExecContext(ctx, conn)
+		c.invalidateResultCache(action)
 		if err != nil {
 			return nil, err
 		}
@@ -223,6 +440,7 @@ func (c *ZetaSQLiteConn) QueryContext(ctx context.Context, query string, args []
 		}
 		actions = append(actions, action)
 		queryRows, err := action.QueryContext(ctx, conn)
+		c.invalidateResultCache(action)
 		if err != nil {
 			return nil, err
 		}
@@ -232,9 +450,16 @@ func (c *ZetaSQLiteConn) QueryContext(ctx context.Context, query string, args []
 }
 
 func (c *ZetaSQLiteConn) Close() error {
+	c.analyzer.Close()
 	return c.conn.Close()
 }
 
+// BeginTx starts the transaction on the SQLite connection of c.
+// The database file is opened in WAL journal mode by default, so the transaction has snapshot isolation:
+// the statements in the transaction read the snapshot taken by the first read, and the changes committed
+// by the other connections after that are visible only after the transaction ends.
+// The writes of the transactions are serialized by SQLite, and the writer waits for the busy timeout
+// ( `_busy_timeout` DSN parameter ) while the other connection is writing.
 func (c *ZetaSQLiteConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
 	tx, err := c.conn.BeginTx(ctx, &sql.TxOptions{
 		Isolation: sql.IsolationLevel(opts.Isolation),
@@ -270,6 +495,7 @@ type ZetaSQLiteTx struct {
 func (tx *ZetaSQLiteTx) Commit() error {
 	defer func() {
 		tx.conn.tx = nil
+		tx.conn.catalog.InvalidateResultCache()
 	}()
 	return tx.tx.Commit()
 }
@@ -1,11 +1,25 @@
 package zetasqlite_test
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"cloud.google.com/go/bigquery"
+	"cloud.google.com/go/civil"
+	"github.com/goccy/go-zetasql"
 	"github.com/google/go-cmp/cmp"
+	"github.com/mattn/go-sqlite3"
 
 	zetasqlite "github.com/goccy/go-zetasqlite"
 )
@@ -91,6 +105,50 @@ func TestRegisterCustomDriver(t *testing.T) {
 	}
 }
 
+func TestRegisterMacro(t *testing.T) {
+	sql.Register("zetasqlite-macro", &zetasqlite.ZetaSQLiteDriver{
+		ConnectHook: func(conn *zetasqlite.ZetaSQLiteConn) error {
+			conn.RegisterMacro("fixture_users", func(args []string) (string, error) {
+				return "(SELECT * FROM UNNEST([STRUCT(1 AS id, 'JP' AS country), (2, 'US'), (3, 'JP')]))", nil
+			})
+			conn.RegisterMacro("users_of", func(args []string) (string, error) {
+				if len(args) != 1 {
+					return "", fmt.Errorf("users_of requires 1 argument")
+				}
+				return fmt.Sprintf("(SELECT * FROM {{ fixture_users }} WHERE country = %s)", args[0]), nil
+			})
+			return nil
+		},
+	})
+	db, err := sql.Open("zetasqlite-macro", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	rows, err := db.Query("SELECT id FROM {{ users_of(@country) }} ORDER BY id", sql.Named("country", "JP"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]int64{1, 3}, ids); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+	if _, err := db.Query("SELECT * FROM {{ unknown }}"); err == nil || !strings.Contains(err.Error(), "undefined macro: unknown") {
+		t.Fatalf("expected undefined macro error but got %v", err)
+	}
+}
+
 func TestChangedCatalog(t *testing.T) {
 	t.Run("table", func(t *testing.T) {
 		db, err := sql.Open("zetasqlite", ":memory:")
@@ -262,3 +320,2993 @@ CREATE TABLE IF NOT EXISTS Singers (
 		}
 	})
 }
+
+func TestQueryColumnar(t *testing.T) {
+	db, err := sql.Open("zetasqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	// the named types are converted by driver.DefaultParameterConverter like database/sql.
+	type userName string
+	var result *zetasqlite.ColumnarResult
+	if err := conn.Raw(func(c interface{}) error {
+		zetasqliteConn, ok := c.(*zetasqlite.ZetaSQLiteConn)
+		if !ok {
+			t.Fatalf("unexpected connection type %T", c)
+		}
+		r, err := zetasqliteConn.QueryColumnar(
+			context.Background(),
+			`SELECT id, name FROM UNNEST([STRUCT(1 AS id, 'a' AS name), (2, NULL), (@id, @name)])`,
+			sql.Named("id", 3),
+			sql.Named("name", userName("c")),
+		)
+		if err != nil {
+			return err
+		}
+		result = r
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if result.NumRows != 3 {
+		t.Fatalf("failed to get rows: expected 3 but got %d", result.NumRows)
+	}
+	if diff := cmp.Diff(result.Column("id").Values, []interface{}{int64(1), int64(2), int64(3)}); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(result.Column("name").Values, []interface{}{"a", nil, "c"}); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+}
+
+func TestQueryPages(t *testing.T) {
+	db, err := sql.Open("zetasqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if err := conn.Raw(func(c interface{}) error {
+		zetasqliteConn, ok := c.(*zetasqlite.ZetaSQLiteConn)
+		if !ok {
+			t.Fatalf("unexpected connection type %T", c)
+		}
+		pages, err := zetasqliteConn.QueryPages(
+			ctx,
+			`SELECT x, FORMAT('%d', x) AS s FROM UNNEST(GENERATE_ARRAY(1, @n)) AS x ORDER BY x DESC`,
+			sql.Named("n", 5),
+		)
+		if err != nil {
+			return err
+		}
+		defer pages.Close(ctx)
+
+		if pages.TotalRows() != 5 {
+			t.Fatalf("unexpected total rows %d", pages.TotalRows())
+		}
+		var (
+			rows      [][]interface{}
+			pageToken string
+			pageNum   int
+		)
+		for {
+			page, err := pages.Page(ctx, pageToken, 2)
+			if err != nil {
+				return err
+			}
+			rows = append(rows, page.Rows...)
+			pageNum++
+			if page.NextPageToken == "" {
+				break
+			}
+			pageToken = page.NextPageToken
+		}
+		if pageNum != 3 {
+			t.Fatalf("unexpected number of pages %d", pageNum)
+		}
+		if diff := cmp.Diff(rows, [][]interface{}{
+			{int64(5), "5"}, {int64(4), "4"}, {int64(3), "3"}, {int64(2), "2"}, {int64(1), "1"},
+		}); diff != "" {
+			t.Errorf("(-want +got):\n%s", diff)
+		}
+		if _, err := pages.Page(ctx, "invalid", 2); err == nil {
+			t.Fatal("expected error for invalid page token")
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestQueryToWriter(t *testing.T) {
+	db, err := sql.Open("zetasqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	for _, test := range []struct {
+		name     string
+		query    string
+		format   zetasqlite.ExportFormat
+		expected string
+	}{
+		{
+			name:     "csv",
+			query:    `SELECT 1 AS id, 'a,b' AS name, TIMESTAMP '2022-01-02 03:04:05.123+00' AS ts, CAST(NULL AS STRING) AS empty`,
+			format:   zetasqlite.ExportFormatCSV,
+			expected: "id,name,ts,empty\n1,\"a,b\",2022-01-02 03:04:05.123 UTC,\n",
+		},
+		{
+			name:     "ndjson",
+			query:    `SELECT 1 AS id, [1.5, 2] AS arr, STRUCT(true AS b, b'a' AS c) AS s, CAST(NULL AS STRING) AS empty`,
+			format:   zetasqlite.ExportFormatNDJSON,
+			expected: `{"id":"1","arr":[1.5,2],"s":{"b":true,"c":"YQ=="},"empty":null}` + "\n",
+		},
+	} {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := conn.Raw(func(c interface{}) error {
+				return c.(*zetasqlite.ZetaSQLiteConn).QueryToWriter(context.Background(), test.query, test.format, &buf)
+			}); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(test.expected, buf.String()); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDiffQueries(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("zetasqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, `
+CREATE TABLE expected_items (id INT64, name STRING);
+CREATE TABLE actual_items (id INT64, name STRING);
+INSERT expected_items (id, name) VALUES (1, 'a'), (2, 'b'), (2, 'b'), (3, NULL);
+INSERT actual_items (id, name) VALUES (3, NULL), (2, 'b'), (1, 'x'), (4, 'd');
+`); err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		name        string
+		diff        func(*zetasqlite.ZetaSQLiteConn) (*zetasqlite.RowsDiff, error)
+		expected    []*zetasqlite.RowDiff
+		expectedStr string
+		expectedErr string
+	}{
+		{
+			name: "tables",
+			diff: func(c *zetasqlite.ZetaSQLiteConn) (*zetasqlite.RowsDiff, error) {
+				return c.DiffTables(ctx, "expected_items", "actual_items")
+			},
+			expected: []*zetasqlite.RowDiff{
+				{Reason: zetasqlite.DiffReasonMissing, Values: []interface{}{int64(1), "a"}},
+				{Reason: zetasqlite.DiffReasonMissing, Values: []interface{}{int64(2), "b"}},
+				{Reason: zetasqlite.DiffReasonUnexpected, Values: []interface{}{int64(1), "x"}},
+				{Reason: zetasqlite.DiffReasonUnexpected, Values: []interface{}{int64(4), "d"}},
+			},
+			expectedStr: "reason | id | name\nMISSING | 1 | a\nMISSING | 2 | b\nUNEXPECTED | 1 | x\nUNEXPECTED | 4 | d",
+		},
+		{
+			name: "queries with parameter",
+			diff: func(c *zetasqlite.ZetaSQLiteConn) (*zetasqlite.RowsDiff, error) {
+				return c.DiffQueries(
+					ctx,
+					"SELECT id FROM expected_items WHERE id > @min_id",
+					"SELECT DISTINCT id FROM actual_items WHERE id > @min_id AND id < 4 ORDER BY id DESC",
+					sql.Named("min_id", 2),
+				)
+			},
+		},
+		{
+			name: "column type mismatch",
+			diff: func(c *zetasqlite.ZetaSQLiteConn) (*zetasqlite.RowsDiff, error) {
+				return c.DiffQueries(ctx, "SELECT id FROM expected_items", "SELECT name FROM actual_items")
+			},
+			expectedErr: "zetasqlite: type of column id mismatch: expected INT64 but got STRING",
+		},
+	} {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			if err := conn.Raw(func(c interface{}) error {
+				diff, err := test.diff(c.(*zetasqlite.ZetaSQLiteConn))
+				if test.expectedErr != "" {
+					if err == nil || err.Error() != test.expectedErr {
+						t.Fatalf("expected error %q but got %v", test.expectedErr, err)
+					}
+					return nil
+				}
+				if err != nil {
+					return err
+				}
+				if diff.Empty() != (len(test.expected) == 0) {
+					t.Errorf("unexpected empty diff %t", diff.Empty())
+				}
+				if d := cmp.Diff(test.expected, diff.Rows); d != "" {
+					t.Errorf("(-want +got):\n%s", d)
+				}
+				if d := cmp.Diff(test.expectedStr, diff.String()); d != "" {
+					t.Errorf("(-want +got):\n%s", d)
+				}
+				return nil
+			}); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestQueryStatistics(t *testing.T) {
+	db, err := sql.Open("zetasqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	for _, test := range []struct {
+		name     string
+		query    string
+		expected *zetasqlite.QueryStatistics
+	}{
+		{
+			name:  "create table",
+			query: `CREATE TABLE stats_table (id INT64, name STRING)`,
+			expected: &zetasqlite.QueryStatistics{
+				StatementType:         zetasqlite.StatementTypeCreateTable,
+				DDLOperationPerformed: "CREATE",
+				DDLTargetTable:        []string{"stats_table"},
+			},
+		},
+		{
+			name:  "create table if not exists",
+			query: `CREATE TABLE IF NOT EXISTS stats_table (id INT64, name STRING)`,
+			expected: &zetasqlite.QueryStatistics{
+				StatementType:         zetasqlite.StatementTypeCreateTable,
+				DDLOperationPerformed: "SKIP",
+				DDLTargetTable:        []string{"stats_table"},
+			},
+		},
+		{
+			name:  "insert",
+			query: `INSERT INTO stats_table (id, name) VALUES (1, 'a'), (2, 'b'), (3, 'c')`,
+			expected: &zetasqlite.QueryStatistics{
+				StatementType: zetasqlite.StatementTypeInsert,
+				DMLStats:      &zetasqlite.DMLStats{InsertedRowCount: 3},
+			},
+		},
+		{
+			name: "script",
+			query: `
+UPDATE stats_table SET name = 'x' WHERE id > 1;
+DELETE FROM stats_table WHERE id = 1;
+`,
+			expected: &zetasqlite.QueryStatistics{
+				StatementType: zetasqlite.StatementTypeScript,
+				DMLStats:      &zetasqlite.DMLStats{UpdatedRowCount: 2, DeletedRowCount: 1},
+				Children: []*zetasqlite.QueryStatistics{
+					{
+						StatementType: zetasqlite.StatementTypeUpdate,
+						DMLStats:      &zetasqlite.DMLStats{UpdatedRowCount: 2},
+					},
+					{
+						StatementType: zetasqlite.StatementTypeDelete,
+						DMLStats:      &zetasqlite.DMLStats{DeletedRowCount: 1},
+					},
+				},
+			},
+		},
+		{
+			name:  "drop table",
+			query: `DROP TABLE stats_table`,
+			expected: &zetasqlite.QueryStatistics{
+				StatementType:         zetasqlite.StatementTypeDropTable,
+				DDLOperationPerformed: "DROP",
+				DDLTargetTable:        []string{"stats_table"},
+			},
+		},
+	} {
+		result, err := db.Exec(test.query)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		stats, err := zetasqlite.QueryStatisticsFromResult(result)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if diff := cmp.Diff(test.expected, stats); diff != "" {
+			t.Errorf("%s: (-want +got):\n%s", test.name, diff)
+		}
+	}
+	rows, err := db.Query(`SELECT 1`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	stats, err := zetasqlite.QueryStatisticsFromRows(rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.StatementType != zetasqlite.StatementTypeSelect {
+		t.Fatalf("unexpected statement type %s", stats.StatementType)
+	}
+}
+
+func TestQueryLabels(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("zetasqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	queryLabels := func(ctx context.Context, query string) map[string]string {
+		t.Helper()
+		result, err := conn.ExecContext(ctx, query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		stats, err := zetasqlite.QueryStatisticsFromResult(result)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return stats.Labels
+	}
+	if labels := queryLabels(ctx, "CREATE TABLE labels_table (id INT64)"); labels != nil {
+		t.Fatalf("unexpected labels %v", labels)
+	}
+	if _, err := conn.ExecContext(ctx, `SET @@query_label = "team:data,env:test"`); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(
+		map[string]string{"team": "data", "env": "test"},
+		queryLabels(ctx, "INSERT INTO labels_table (id) VALUES (1)"),
+	); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+	// the labels of the context are merged with the labels of SET @@query_label and override the same keys.
+	if diff := cmp.Diff(
+		map[string]string{"team": "data", "env": "prod", "tenant": "a"},
+		queryLabels(
+			zetasqlite.WithQueryLabels(ctx, map[string]string{"env": "prod", "tenant": "a"}),
+			"DELETE FROM labels_table WHERE id = 1",
+		),
+	); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+	if _, err := conn.ExecContext(ctx, `SET @@query_label = "invalid"`); err == nil {
+		t.Fatal("expected error for invalid query label")
+	}
+}
+
+func TestCivilTypes(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("zetasqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.ExecContext(ctx, "CREATE TABLE civil_table (d DATE, dt DATETIME, t TIME)"); err != nil {
+		t.Fatal(err)
+	}
+	var (
+		date     = civil.Date{Year: 2023, Month: 4, Day: 1}
+		datetime = civil.DateTime{Date: date, Time: civil.Time{Hour: 10, Minute: 20, Second: 30, Nanosecond: 123456000}}
+		tm       = civil.Time{Hour: 23, Minute: 59, Second: 59}
+	)
+	if _, err := db.ExecContext(
+		ctx,
+		"INSERT INTO civil_table (d, dt, t) VALUES (?, ?, ?), (?, ?, ?)",
+		date, datetime, tm,
+		zetasqlite.NullDate{}, zetasqlite.NullDateTime{}, &zetasqlite.NullTime{Time: tm, Valid: true},
+	); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := db.QueryContext(ctx, "SELECT d, dt, t FROM civil_table WHERE t = @t ORDER BY d NULLS LAST", sql.Named("t", tm))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	type row struct {
+		D  zetasqlite.NullDate
+		DT zetasqlite.NullDateTime
+		T  zetasqlite.NullTime
+	}
+	var results []row
+	for rows.Next() {
+		var r row
+		if err := rows.Scan(&r.D, &r.DT, &r.T); err != nil {
+			t.Fatal(err)
+		}
+		results = append(results, r)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	expected := []row{
+		{
+			D:  zetasqlite.NullDate{Date: date, Valid: true},
+			DT: zetasqlite.NullDateTime{DateTime: datetime, Valid: true},
+			T:  zetasqlite.NullTime{Time: tm, Valid: true},
+		},
+		{
+			T: zetasqlite.NullTime{Time: tm, Valid: true},
+		},
+	}
+	if diff := cmp.Diff(expected, results); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+}
+
+func TestNumericTypes(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("zetasqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.ExecContext(ctx, "CREATE TABLE numeric_table (id INT64, n NUMERIC, bn BIGNUMERIC)"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.ExecContext(
+		ctx,
+		"INSERT INTO numeric_table (id, n, bn) VALUES (?, ?, ?), (?, ?, ?)",
+		1, big.NewRat(12345, 100), "-1.000000000000000000000000000000000001",
+		2, zetasqlite.NullNumeric{}, &zetasqlite.NullNumeric{Numeric: big.NewRat(1, 3), Valid: true},
+	); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := db.QueryContext(ctx, "SELECT n, bn FROM numeric_table ORDER BY id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var results [][]string
+	for rows.Next() {
+		var n, bn zetasqlite.NullNumeric
+		if err := rows.Scan(&n, &bn); err != nil {
+			t.Fatal(err)
+		}
+		var result []string
+		for _, v := range []zetasqlite.NullNumeric{n, bn} {
+			if !v.Valid {
+				result = append(result, "NULL")
+				continue
+			}
+			result = append(result, v.Numeric.RatString())
+		}
+		results = append(results, result)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	expected := [][]string{
+		{"2469/20", "-1000000000000000000000000000000000001/1000000000000000000000000000000000000"},
+		{"NULL", "33333333333333333333333333333333333333/100000000000000000000000000000000000000"},
+	}
+	if diff := cmp.Diff(expected, results); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+
+	var param zetasqlite.NullNumeric
+	if err := db.QueryRowContext(ctx, "SELECT @v", sql.Named("v", big.NewRat(3, 2))).Scan(&param); err != nil {
+		t.Fatal(err)
+	}
+	if !param.Valid || param.Numeric.Cmp(big.NewRat(3, 2)) != 0 {
+		t.Fatalf("failed to pass *big.Rat parameter: got %v", param.Numeric)
+	}
+	outOfRange := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(29), nil))
+	if _, err := db.ExecContext(ctx, "INSERT INTO numeric_table (id, n) VALUES (3, @v)", sql.Named("v", outOfRange)); err == nil {
+		t.Fatal("expected error for out of range NUMERIC value")
+	} else if !strings.Contains(err.Error(), "Invalid NUMERIC value") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestJSONParameter(t *testing.T) {
+	db, err := sql.Open("zetasqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+
+	var name string
+	if err := db.QueryRowContext(
+		ctx,
+		"SELECT JSON_VALUE(@j, '$.name')",
+		sql.Named("j", json.RawMessage(`{"name":"alice"}`)),
+	).Scan(&name); err != nil {
+		t.Fatal(err)
+	}
+	if name != "alice" {
+		t.Fatalf("failed to pass json.RawMessage parameter: got %q", name)
+	}
+
+	if _, err := db.ExecContext(ctx, "CREATE TABLE json_table (id INT64, v JSON)"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.ExecContext(
+		ctx,
+		"INSERT INTO json_table (id, v) VALUES (1, @v)",
+		sql.Named("v", `{"a":[1,2]}`),
+	); err != nil {
+		t.Fatal(err)
+	}
+	var inserted string
+	if err := db.QueryRowContext(ctx, "SELECT JSON_TYPE(JSON_QUERY(v, '$.a')) FROM json_table WHERE id = 1").Scan(&inserted); err != nil {
+		t.Fatal(err)
+	}
+	if inserted != "array" {
+		t.Fatalf("failed to pass string parameter as JSON: got %q", inserted)
+	}
+
+	if _, err := db.ExecContext(
+		ctx,
+		"CREATE FUNCTION first_item(j JSON) RETURNS JSON AS (JSON_QUERY(j, '$.items[0]'))",
+	); err != nil {
+		t.Fatal(err)
+	}
+	var (
+		typ  string
+		item string
+	)
+	if err := db.QueryRowContext(
+		ctx,
+		"SELECT JSON_TYPE(first_item(@j)), TO_JSON_STRING(first_item(@j))",
+		sql.Named("j", json.RawMessage(`{"items":[{"id":1}]}`)),
+	).Scan(&typ, &item); err != nil {
+		t.Fatal(err)
+	}
+	if typ != "object" || item != `{"id":1}` {
+		t.Fatalf("unexpected result of function returning JSON: type = %q, value = %q", typ, item)
+	}
+
+	if _, err := db.ExecContext(
+		ctx,
+		"INSERT INTO json_table (id, v) VALUES (2, @v)",
+		sql.Named("v", json.RawMessage(`{"a":`)),
+	); err == nil {
+		t.Fatal("expected error for invalid JSON parameter")
+	} else if !strings.Contains(err.Error(), "invalid JSON parameter") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestStructArrayParameter(t *testing.T) {
+	db, err := sql.Open("zetasqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+
+	type item struct {
+		ID        int64
+		Name      string
+		Tags      []string
+		CreatedAt civil.Date
+	}
+	items := []*item{
+		{ID: 2, Name: "b", CreatedAt: civil.Date{Year: 2023, Month: 1, Day: 2}},
+		{ID: 1, Name: "a", Tags: []string{"x", "y"}, CreatedAt: civil.Date{Year: 2023, Month: 1, Day: 1}},
+	}
+	rows, err := db.QueryContext(
+		ctx,
+		"SELECT id, name, ARRAY_LENGTH(tags), CAST(createdAt AS STRING) FROM UNNEST(@items) ORDER BY id",
+		sql.Named("items", items),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	type result struct {
+		ID        int64
+		Name      string
+		TagNum    int64
+		CreatedAt string
+	}
+	var results []result
+	for rows.Next() {
+		var r result
+		if err := rows.Scan(&r.ID, &r.Name, &r.TagNum, &r.CreatedAt); err != nil {
+			t.Fatal(err)
+		}
+		results = append(results, r)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	expected := []result{
+		{ID: 1, Name: "a", TagNum: 2, CreatedAt: "2023-01-01"},
+		{ID: 2, Name: "b", TagNum: 0, CreatedAt: "2023-01-02"},
+	}
+	if diff := cmp.Diff(expected, results); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+
+	var name string
+	if err := db.QueryRowContext(ctx, "SELECT @item.name", sql.Named("item", items[0])).Scan(&name); err != nil {
+		t.Fatal(err)
+	}
+	if name != "b" {
+		t.Fatalf("failed to pass struct parameter: got %q", name)
+	}
+
+	if _, err := db.QueryContext(
+		ctx,
+		"SELECT * FROM UNNEST(@values)",
+		sql.Named("values", []struct{ V interface{} }{{V: 1}}),
+	); err == nil {
+		t.Fatal("expected error for the field of unsupported type")
+	} else if !strings.Contains(err.Error(), "unsupported parameter type") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestStrictMode(t *testing.T) {
+	db, err := sql.Open("zetasqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, `CREATE TABLE params (name STRING(5), price NUMERIC(5, 2))`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.ExecContext(ctx, `INSERT INTO params (name, price) VALUES ('too long name', 1234.5)`); err != nil {
+		t.Fatalf("type parameters must not be enforced without strict mode: %v", err)
+	}
+	if err := conn.Raw(func(c interface{}) error {
+		zetasqliteConn, ok := c.(*zetasqlite.ZetaSQLiteConn)
+		if !ok {
+			t.Fatalf("unexpected connection type %T", c)
+		}
+		zetasqliteConn.SetStrictMode(true)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.ExecContext(ctx, `INSERT INTO params (name, price) VALUES ('alice', 1.235)`); err != nil {
+		t.Fatal(err)
+	}
+	var count int64
+	if err := conn.QueryRowContext(ctx, `SELECT COUNT(*) FROM params WHERE price = NUMERIC '1.24'`).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Fatalf("failed to round NUMERIC value by scale: expected 1 row but got %d", count)
+	}
+	for _, test := range []struct {
+		name        string
+		query       string
+		expectedErr string
+	}{
+		{
+			name:        "insert too long string",
+			query:       `INSERT INTO params (name, price) VALUES ('alice_', 1)`,
+			expectedErr: "Value of type STRING has length 6, which exceeds the maximum length 5 of column name with type STRING(5)",
+		},
+		{
+			name:        "insert too large numeric",
+			query:       `INSERT INTO params (name, price) SELECT 'bob', 1000`,
+			expectedErr: "Value 1000 exceeds the precision of column price with type NUMERIC(5, 2)",
+		},
+		{
+			name:        "update too long string",
+			query:       `UPDATE params SET name = 'alice_' WHERE name = 'alice'`,
+			expectedErr: "Value of type STRING has length 6, which exceeds the maximum length 5 of column name with type STRING(5)",
+		},
+	} {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			_, err := conn.ExecContext(ctx, test.query)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if !strings.Contains(err.Error(), test.expectedErr) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestDefaultParameters(t *testing.T) {
+	db, err := sql.Open("zetasqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	runTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := conn.Raw(func(c interface{}) error {
+		zetasqliteConn, ok := c.(*zetasqlite.ZetaSQLiteConn)
+		if !ok {
+			t.Fatalf("unexpected connection type %T", c)
+		}
+		return zetasqliteConn.SetDefaultParameters(zetasqlite.ScheduledQueryParameters(runTime))
+	}); err != nil {
+		t.Fatal(err)
+	}
+	var (
+		runDate     string
+		nextDate    string
+		runTimeText string
+	)
+	if err := conn.QueryRowContext(
+		ctx,
+		`SELECT @run_date, DATE_ADD(@run_date, INTERVAL 1 DAY), FORMAT_TIMESTAMP('%F %T', @run_time)`,
+	).Scan(&runDate, &nextDate, &runTimeText); err != nil {
+		t.Fatal(err)
+	}
+	if runDate != "2024-01-02" || nextDate != "2024-01-03" || runTimeText != "2024-01-02 03:04:05" {
+		t.Fatalf("unexpected default parameters: run_date = %s, next date = %s, run_time = %s", runDate, nextDate, runTimeText)
+	}
+	if err := conn.QueryRowContext(
+		ctx,
+		`SELECT @run_date`,
+		sql.Named("run_date", civil.Date{Year: 2023, Month: 12, Day: 31}),
+	).Scan(&runDate); err != nil {
+		t.Fatal(err)
+	}
+	if runDate != "2023-12-31" {
+		t.Fatalf("failed to override default parameter: run_date = %s", runDate)
+	}
+	var v int64
+	if err := conn.QueryRowContext(ctx, `SELECT ? + 1`, int64(1)).Scan(&v); err != nil {
+		t.Fatal(err)
+	}
+	if v != 2 {
+		t.Fatalf("unexpected positional parameter result %d", v)
+	}
+}
+
+func TestRawSQLiteConn(t *testing.T) {
+	db, err := sql.Open("zetasqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, `
+CREATE TABLE raw_items (id INT64);
+INSERT INTO raw_items (id) VALUES (1), (2);
+`); err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.Raw(func(c interface{}) error {
+		zetasqliteConn, ok := c.(*zetasqlite.ZetaSQLiteConn)
+		if !ok {
+			t.Fatalf("unexpected connection type %T", c)
+		}
+		return zetasqliteConn.RawSQLiteConn(func(sqliteConn *sqlite3.SQLiteConn) error {
+			_, err := sqliteConn.Exec("UPDATE raw_items SET id = zetasqlite_add(id, 10)", nil)
+			return err
+		})
+	}); err != nil {
+		t.Fatal(err)
+	}
+	var sum int64
+	if err := conn.QueryRowContext(ctx, `SELECT SUM(id) FROM raw_items`).Scan(&sum); err != nil {
+		t.Fatal(err)
+	}
+	if sum != 23 {
+		t.Fatalf("unexpected sum %d", sum)
+	}
+}
+
+func TestColumnDescription(t *testing.T) {
+	db, err := sql.Open("zetasqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	result, err := db.Exec(`
+CREATE TABLE described_items (
+  id INT64 NOT NULL OPTIONS(description="the identifier of the item"),
+  name STRING
+)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resultCatalog, err := zetasqlite.ChangedCatalogFromResult(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resultCatalog.Table.Added) != 1 {
+		t.Fatal("failed to get created table spec")
+	}
+	spec := resultCatalog.Table.Added[0]
+	if desc := spec.ColumnDescription("id"); desc != "the identifier of the item" {
+		t.Fatalf("unexpected description of id column: %q", desc)
+	}
+	if desc := spec.ColumnDescription("name"); desc != "" {
+		t.Fatalf("unexpected description of name column: %q", desc)
+	}
+
+	rows, err := db.Query(`DESCRIBE described_items`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"column_name", "data_type", "is_nullable", "description"}, columns); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+	var got [][]interface{}
+	for rows.Next() {
+		var (
+			name, typ, isNullable string
+			description           sql.NullString
+		)
+		if err := rows.Scan(&name, &typ, &isNullable, &description); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, []interface{}{name, typ, isNullable, description.String})
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([][]interface{}{
+		{"id", "INT64", "NO", "the identifier of the item"},
+		{"name", "STRING", "YES", ""},
+	}, got); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+}
+
+func TestSubqueryDecorrelation(t *testing.T) {
+	db, err := sql.Open("zetasqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, `
+CREATE TABLE orders (id INT64, customer_id INT64);
+CREATE TABLE customers (id INT64, name STRING);
+INSERT INTO orders (id, customer_id) VALUES (1, 1), (2, 1), (3, 2), (4, NULL), (5, 3);
+INSERT INTO customers (id, name) VALUES (1, 'alice'), (2, 'bob'), (3, NULL), (NULL, 'carol');
+`); err != nil {
+		t.Fatal(err)
+	}
+	query := `
+SELECT
+  (SELECT COUNT(*) FROM orders WHERE EXISTS (SELECT 1 FROM customers c WHERE c.id = orders.customer_id AND c.name IS NOT NULL)),
+  (SELECT COUNT(*) FROM orders WHERE NOT EXISTS (SELECT 1 FROM customers c WHERE c.id = orders.customer_id)),
+  (SELECT COUNT(*) FROM orders WHERE 'bob' NOT IN (SELECT name FROM customers c WHERE c.id = orders.customer_id))`
+	for _, enabled := range []bool{true, false} {
+		if err := conn.Raw(func(c interface{}) error {
+			zetasqliteConn, ok := c.(*zetasqlite.ZetaSQLiteConn)
+			if !ok {
+				t.Fatalf("unexpected connection type %T", c)
+			}
+			zetasqliteConn.SetSubqueryDecorrelation(enabled)
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+		var exists, notExists, notIn int64
+		if err := conn.QueryRowContext(ctx, query).Scan(&exists, &notExists, &notIn); err != nil {
+			t.Fatal(err)
+		}
+		if exists != 3 || notExists != 1 || notIn != 3 {
+			t.Fatalf(
+				"unexpected counts with decorrelation %t: exists %d, not exists %d, not in %d",
+				enabled, exists, notExists, notIn,
+			)
+		}
+	}
+}
+
+func TestRowNumberDeduplication(t *testing.T) {
+	db, err := sql.Open("zetasqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, `
+CREATE TABLE events (id INT64, user_id INT64, name STRING, updated_at TIMESTAMP);
+INSERT INTO events (id, user_id, name, updated_at) VALUES
+  (1, 1, 'a', TIMESTAMP '2023-01-01 00:00:00+00'),
+  (2, 1, 'b', TIMESTAMP '2023-01-03 00:00:00+00'),
+  (3, 1, 'c', NULL),
+  (4, 2, 'd', TIMESTAMP '2023-01-02 00:00:00+00'),
+  (5, NULL, 'e', TIMESTAMP '2023-01-04 00:00:00+00');
+`); err != nil {
+		t.Fatal(err)
+	}
+	query := `
+SELECT STRING_AGG(name, ',' ORDER BY id) FROM (
+  SELECT * FROM events QUALIFY ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY updated_at DESC) = 1
+)`
+	for _, enabled := range []bool{true, false} {
+		if err := conn.Raw(func(c interface{}) error {
+			zetasqliteConn, ok := c.(*zetasqlite.ZetaSQLiteConn)
+			if !ok {
+				t.Fatalf("unexpected connection type %T", c)
+			}
+			zetasqliteConn.SetRowNumberDeduplication(enabled)
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+		var names string
+		if err := conn.QueryRowContext(ctx, query).Scan(&names); err != nil {
+			t.Fatal(err)
+		}
+		if names != "b,d,e" {
+			t.Fatalf("unexpected names with deduplication %t: %s", enabled, names)
+		}
+	}
+}
+
+func TestRequirePartitionFilter(t *testing.T) {
+	db, err := sql.Open("zetasqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+	if _, err := db.ExecContext(ctx, `
+CREATE TABLE events (id INT64, dt DATE) PARTITION BY dt OPTIONS(require_partition_filter = true);
+INSERT INTO events (id, dt) VALUES (1, '2022-01-01'), (2, '2022-01-02');
+`); err != nil {
+		t.Fatal(err)
+	}
+	var count int64
+	if err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM events WHERE dt = '2022-01-01'`).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Fatalf("expected 1 row but got %d", count)
+	}
+	expectedErr := "Cannot query over table 'events' without a filter over column(s) 'dt' that can be used for partition elimination"
+	for _, query := range []string{
+		`SELECT COUNT(*) FROM events`,
+		`SELECT COUNT(*) FROM events WHERE id = 1`,
+	} {
+		err := db.QueryRowContext(ctx, query).Scan(&count)
+		if err == nil {
+			t.Fatalf("expected error for %s", query)
+		}
+		if !strings.Contains(err.Error(), expectedErr) {
+			t.Fatalf("unexpected error message: expected [%s] but got [%s]", expectedErr, err.Error())
+		}
+	}
+	for _, query := range []string{
+		`UPDATE events SET id = 3 WHERE id = 1`,
+		`DELETE FROM events WHERE id = 1`,
+	} {
+		_, err := db.ExecContext(ctx, query)
+		if err == nil {
+			t.Fatalf("expected error for %s", query)
+		}
+		if !strings.Contains(err.Error(), expectedErr) {
+			t.Fatalf("unexpected error message: expected [%s] but got [%s]", expectedErr, err.Error())
+		}
+	}
+	if _, err := db.ExecContext(ctx, `UPDATE events SET id = 3 WHERE dt = '2022-01-01'`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.ExecContext(ctx, `DELETE FROM events WHERE dt = '2022-01-02'`); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM events WHERE dt >= '2022-01-01' AND id = 3`).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Fatalf("expected 1 row but got %d", count)
+	}
+}
+
+func TestRequirePartitionFilterWithSameTableNameInDatasets(t *testing.T) {
+	db, err := sql.Open("zetasqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+	if _, err := db.ExecContext(ctx, `
+CREATE TABLE logs.events (id INT64, dt DATE);
+CREATE TABLE archive.events (id INT64, dt DATE) PARTITION BY dt OPTIONS(require_partition_filter = true);
+INSERT INTO logs.events (id, dt) VALUES (1, '2022-01-01');
+`); err != nil {
+		t.Fatal(err)
+	}
+	// the table of the other dataset with the same name must not require the partition filter.
+	var count int64
+	if err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM logs.events`).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Fatalf("expected 1 row but got %d", count)
+	}
+	if _, err := db.ExecContext(ctx, `DELETE FROM logs.events WHERE id = 1`); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCreateTableAsSelectWithPartitionAndCluster(t *testing.T) {
+	db, err := sql.Open("zetasqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, `
+CREATE TABLE raw_events (id INT64, created TIMESTAMP, author STRING);
+INSERT INTO raw_events (id, created, author) VALUES (1, '2022-01-01 00:00:00', 'alice'), (2, '2022-01-02 00:00:00', 'bob');
+CREATE TABLE events
+PARTITION BY DATE(ts)
+CLUSTER BY user_name, id
+OPTIONS(require_partition_filter = true)
+AS SELECT id, created AS ts, author AS user_name FROM raw_events;
+`); err != nil {
+		t.Fatal(err)
+	}
+	var tables []*zetasqlite.TableSpec
+	if err := conn.Raw(func(c interface{}) error {
+		specs, err := c.(*zetasqlite.ZetaSQLiteConn).Tables(ctx)
+		if err != nil {
+			return err
+		}
+		tables = specs
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	var spec *zetasqlite.TableSpec
+	for _, table := range tables {
+		if strings.Join(table.NamePath, ".") == "events" {
+			spec = table
+		}
+	}
+	if spec == nil {
+		t.Fatalf("failed to find events table: %v", tables)
+	}
+	if diff := cmp.Diff([]string{"ts"}, spec.PartitionColumns); diff != "" {
+		t.Errorf("unexpected partition columns (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"user_name", "id"}, spec.ClusterColumns); diff != "" {
+		t.Errorf("unexpected cluster columns (-want +got):\n%s", diff)
+	}
+	var count int64
+	if err := conn.QueryRowContext(ctx, `SELECT COUNT(*) FROM events WHERE DATE(ts) = '2022-01-01'`).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Fatalf("expected 1 row but got %d", count)
+	}
+	if err := conn.QueryRowContext(ctx, `SELECT COUNT(*) FROM events`).Scan(&count); err == nil {
+		t.Fatal("expected error for the query without partition filter")
+	}
+}
+
+func TestLanguageFeatures(t *testing.T) {
+	db, err := sql.Open("zetasqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	setFeature := func(enabled bool) {
+		if err := conn.Raw(func(c interface{}) error {
+			zetasqliteConn, ok := c.(*zetasqlite.ZetaSQLiteConn)
+			if !ok {
+				t.Fatalf("unexpected connection type %T", c)
+			}
+			if enabled {
+				zetasqliteConn.EnableLanguageFeatures(zetasql.FeatureV13Qualify)
+			} else {
+				zetasqliteConn.DisableLanguageFeatures(zetasql.FeatureV13Qualify)
+			}
+			for _, feature := range zetasqliteConn.LanguageFeatures() {
+				if feature == zetasql.FeatureV13Qualify && !enabled {
+					t.Fatal("failed to disable language feature")
+				}
+			}
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	query := `SELECT x FROM UNNEST([1, 2, 3]) AS x WHERE TRUE QUALIFY ROW_NUMBER() OVER (ORDER BY x) = 1`
+	setFeature(false)
+	if _, err := conn.QueryContext(ctx, query); err == nil {
+		t.Fatal("expected error for disabled QUALIFY feature")
+	}
+	setFeature(true)
+	var x int64
+	if err := conn.QueryRowContext(ctx, query).Scan(&x); err != nil {
+		t.Fatal(err)
+	}
+	if x != 1 {
+		t.Fatalf("expected 1 but got %d", x)
+	}
+}
+
+func TestSupportsFeature(t *testing.T) {
+	db, err := sql.Open("zetasqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	// the support reported by SupportsFeature must be consistent with the result of the query using the feature.
+	for _, test := range []struct {
+		feature zetasqlite.Feature
+		query   string
+	}{
+		{feature: zetasqlite.FeaturePivot, query: `SELECT * FROM (SELECT 1 AS k, 10 AS v) PIVOT (SUM(v) FOR k IN (1))`},
+		{feature: zetasqlite.FeatureRecursiveCTE, query: `WITH RECURSIVE r AS (SELECT 1 AS n UNION ALL SELECT n + 1 FROM r WHERE n < 3) SELECT n FROM r`},
+		{feature: zetasqlite.FeatureInlineLambda, query: `SELECT ARRAY_FILTER([1, 2, 3], e -> e > 1)`},
+		{feature: zetasqlite.FeatureCreateMaterializedView, query: `CREATE MATERIALIZED VIEW mv AS SELECT 1 AS x`},
+		{feature: zetasqlite.FeatureCreateSchema, query: `CREATE SCHEMA dataset`},
+		{feature: zetasqlite.FeatureNumeric, query: `SELECT NUMERIC '1.5' + 1`},
+		{feature: zetasqlite.FeatureJSON, query: `SELECT JSON_VALUE(JSON '{"a": 1}', '$.a')`},
+		{feature: zetasqlite.FeatureInterval, query: `SELECT INTERVAL 1 DAY`},
+	} {
+		t.Run(string(test.feature), func(t *testing.T) {
+			_, err := conn.ExecContext(ctx, test.query)
+			if supported := zetasqlite.SupportsFeature(test.feature); supported != (err == nil) {
+				t.Fatalf("SupportsFeature returns %t but the query returns error %v", supported, err)
+			}
+		})
+	}
+	// GEOGRAPHY is accepted by the analyzer, but there is no runtime for its values.
+	if zetasqlite.SupportsFeature(zetasqlite.FeatureGeography) {
+		t.Fatal("expected GEOGRAPHY to be unsupported")
+	}
+	if zetasqlite.SupportsFeature(zetasqlite.Feature("UNKNOWN")) {
+		t.Fatal("expected unknown feature to be unsupported")
+	}
+	supported := map[zetasqlite.Feature]bool{}
+	for _, feature := range zetasqlite.SupportedFeatures() {
+		supported[feature] = true
+	}
+	if !supported[zetasqlite.FeatureQualify] || supported[zetasqlite.FeatureExportData] {
+		t.Fatalf("unexpected supported features: %v", zetasqlite.SupportedFeatures())
+	}
+
+	// the connection reports the support with the language features enabled for it.
+	if err := conn.Raw(func(c interface{}) error {
+		zetasqliteConn := c.(*zetasqlite.ZetaSQLiteConn)
+		zetasqliteConn.DisableLanguageFeatures(zetasql.FeatureV13Qualify)
+		if zetasqliteConn.SupportsFeature(zetasqlite.FeatureQualify) {
+			t.Fatal("expected QUALIFY to be unsupported after disabling the language feature")
+		}
+		if !zetasqlite.SupportsFeature(zetasqlite.FeatureQualify) {
+			t.Fatal("expected the default support not to be changed by the connection")
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCurrentTimeInStatement(t *testing.T) {
+	db, err := sql.Open("zetasqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	var (
+		tsCount   int64
+		sameTime  bool
+		sameDates bool
+	)
+	if err := db.QueryRowContext(context.Background(), `
+SELECT
+  COUNT(DISTINCT CURRENT_TIMESTAMP()),
+  LOGICAL_AND(CURRENT_TIMESTAMP() = CURRENT_TIMESTAMP()),
+  LOGICAL_AND(CURRENT_DATE('UTC') = DATE(CURRENT_TIMESTAMP(), 'UTC'))
+FROM UNNEST(GENERATE_ARRAY(1, 1000))`,
+	).Scan(&tsCount, &sameTime, &sameDates); err != nil {
+		t.Fatal(err)
+	}
+	if tsCount != 1 || !sameTime || !sameDates {
+		t.Fatalf("CURRENT_* functions must return the same time in the statement: %d %v %v", tsCount, sameTime, sameDates)
+	}
+
+	stmt, err := db.Prepare(`SELECT CURRENT_TIMESTAMP()`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stmt.Close()
+	var firstValue, secondValue string
+	if err := stmt.QueryRow().Scan(&firstValue); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Millisecond)
+	if err := stmt.QueryRow().Scan(&secondValue); err != nil {
+		t.Fatal(err)
+	}
+	first, err := zetasqlite.TimeFromTimestampValue(firstValue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := zetasqlite.TimeFromTimestampValue(secondValue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !second.After(first) {
+		t.Fatalf("prepared statement must be evaluated with the time of the execution: %s %s", first, second)
+	}
+}
+
+func TestFunctions(t *testing.T) {
+	db, err := sql.Open("zetasqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, `CREATE TEMP FUNCTION add_one(x INT64) AS (x + 1)`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.ExecContext(ctx, `
+CREATE TEMP FUNCTION random_js() RETURNS FLOAT64 NOT DETERMINISTIC LANGUAGE js
+OPTIONS (description = "returns a random number")
+AS r"""
+  return Math.random();
+"""`); err != nil {
+		t.Fatal(err)
+	}
+	var functions []*zetasqlite.FunctionInfo
+	if err := conn.Raw(func(c interface{}) error {
+		zetasqliteConn, ok := c.(*zetasqlite.ZetaSQLiteConn)
+		if !ok {
+			t.Fatalf("unexpected connection type %T", c)
+		}
+		fns, err := zetasqliteConn.Functions(ctx)
+		if err != nil {
+			return err
+		}
+		functions = fns
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	functionMap := map[string]*zetasqlite.FunctionInfo{}
+	for _, fn := range functions {
+		functionMap[fn.Name] = fn
+	}
+	udf, exists := functionMap["add_one"]
+	if !exists {
+		t.Fatal("failed to find user defined function")
+	}
+	if diff := cmp.Diff(udf, &zetasqlite.FunctionInfo{
+		Name: "add_one",
+		Kind: zetasqlite.FunctionKindTempUDF,
+		Signatures: []*zetasqlite.FunctionSignature{
+			{
+				Arguments:  []*zetasqlite.FunctionArgument{{Name: "x", Type: "INT64"}},
+				ReturnType: "INT64",
+			},
+		},
+	}); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+	randomJS, exists := functionMap["random_js"]
+	if !exists {
+		t.Fatal("failed to find javascript user defined function")
+	}
+	if randomJS.Description != "returns a random number" {
+		t.Fatalf("unexpected description %q", randomJS.Description)
+	}
+	if randomJS.Determinism != zetasqlite.DeterminismNotDeterministic {
+		t.Fatalf("unexpected determinism %q", randomJS.Determinism)
+	}
+	var distinctCount int64
+	if err := conn.QueryRowContext(
+		ctx,
+		`SELECT COUNT(DISTINCT random_js()) FROM UNNEST(GENERATE_ARRAY(1, 10))`,
+	).Scan(&distinctCount); err != nil {
+		t.Fatal(err)
+	}
+	if distinctCount == 1 {
+		t.Fatal("NOT DETERMINISTIC function must be evaluated for each row")
+	}
+	concat, exists := functionMap["CONCAT"]
+	if !exists {
+		t.Fatal("failed to find builtin function")
+	}
+	if concat.Kind != zetasqlite.FunctionKindBuiltin {
+		t.Fatalf("unexpected function kind %s", concat.Kind)
+	}
+	if len(concat.Signatures) == 0 {
+		t.Fatal("failed to get signatures of builtin function")
+	}
+}
+
+func TestUserDefinedFunctionReanalysis(t *testing.T) {
+	db, err := sql.Open("zetasqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	for _, query := range []string{
+		`CREATE FUNCTION add_one(x INT64) AS (x + 1)`,
+		`CREATE FUNCTION double_add_one(x ANY TYPE) AS (add_one(x) * 2)`,
+	} {
+		if _, err := conn.ExecContext(ctx, query); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var a, b int64
+	if err := conn.QueryRowContext(ctx, `SELECT double_add_one(1), double_add_one(2)`).Scan(&a, &b); err != nil {
+		t.Fatal(err)
+	}
+	if a != 4 || b != 6 {
+		t.Fatalf("unexpected results %d, %d", a, b)
+	}
+	if _, err := conn.ExecContext(ctx, `CREATE OR REPLACE FUNCTION add_one(x INT64) AS (x + 10)`); err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.QueryRowContext(ctx, `SELECT double_add_one(1)`).Scan(&a); err != nil {
+		t.Fatal(err)
+	}
+	if a != 22 {
+		t.Fatalf("failed to reanalyze the function after the called function is replaced: got %d", a)
+	}
+
+	t.Run("recursive function", func(t *testing.T) {
+		if _, err := conn.ExecContext(ctx, `CREATE FUNCTION recursive_fn(x INT64) AS (x)`); err != nil {
+			t.Fatal(err)
+		}
+		_, err := conn.ExecContext(ctx, `CREATE OR REPLACE FUNCTION recursive_fn(x INT64) AS (recursive_fn(x) + 1)`)
+		if err == nil {
+			t.Fatal("expected error for recursive function")
+		}
+		if !strings.Contains(err.Error(), "recursive function call is not supported: recursive_fn -> recursive_fn") {
+			t.Fatalf("unexpected error %v", err)
+		}
+	})
+	t.Run("recursive templated function", func(t *testing.T) {
+		for _, query := range []string{
+			`CREATE FUNCTION countdown(x ANY TYPE) AS (x)`,
+			`CREATE FUNCTION call_countdown(x ANY TYPE) AS (countdown(x))`,
+			`CREATE OR REPLACE FUNCTION countdown(x ANY TYPE) AS (IF(x > 0, call_countdown(x - 1), 0))`,
+		} {
+			if _, err := conn.ExecContext(ctx, query); err != nil {
+				t.Fatal(err)
+			}
+		}
+		var v int64
+		err := conn.QueryRowContext(ctx, `SELECT countdown(3)`).Scan(&v)
+		if err == nil {
+			t.Fatal("expected error for recursive function")
+		}
+		if !strings.Contains(err.Error(), "recursive function call is not supported: countdown -> call_countdown -> countdown") {
+			t.Fatalf("unexpected error %v", err)
+		}
+	})
+}
+
+func TestCatalogSpecs(t *testing.T) {
+	db, err := sql.Open("zetasqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, `
+CREATE TABLE project.dataset.users (id INT64 NOT NULL, name STRING);
+CREATE VIEW project.dataset.user_names AS SELECT name FROM project.dataset.users;
+CREATE FUNCTION add_one(x INT64) AS (x + 1);
+CREATE TEMP FUNCTION add_two(x INT64) AS (x + 2);
+`); err != nil {
+		t.Fatal(err)
+	}
+	var (
+		tables    []*zetasqlite.TableSpec
+		functions []*zetasqlite.FunctionSpec
+	)
+	if err := conn.Raw(func(c interface{}) error {
+		zetasqliteConn, ok := c.(*zetasqlite.ZetaSQLiteConn)
+		if !ok {
+			t.Fatalf("unexpected connection type %T", c)
+		}
+		specs, err := zetasqliteConn.Tables(ctx)
+		if err != nil {
+			return err
+		}
+		tables = specs
+		fns, err := zetasqliteConn.UserDefinedFunctions(ctx)
+		if err != nil {
+			return err
+		}
+		functions = fns
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	var tableNames []string
+	for _, table := range tables {
+		tableNames = append(tableNames, strings.Join(table.NamePath, "."))
+	}
+	if diff := cmp.Diff(tableNames, []string{"project.dataset.user_names", "project.dataset.users"}); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+	if len(tables) == 2 && (len(tables[1].Columns) != 2 || !tables[1].Columns[0].IsNotNull) {
+		t.Errorf("unexpected columns of users table")
+	}
+	var funcNames []string
+	for _, fn := range functions {
+		funcNames = append(funcNames, fn.FuncName())
+	}
+	if diff := cmp.Diff(funcNames, []string{"add_one", "add_two"}); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+}
+
+func TestTableConstraints(t *testing.T) {
+	db, err := sql.Open("zetasqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, `
+CREATE TABLE dataset.users (id INT64 NOT NULL, name STRING, PRIMARY KEY (id) NOT ENFORCED);
+CREATE TABLE dataset.orders (
+  id INT64 NOT NULL,
+  user_id INT64,
+  PRIMARY KEY (id),
+  CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES dataset.users(id) NOT ENFORCED
+);
+INSERT INTO dataset.users (id, name) VALUES (1, 'alice'), (1, 'bob');
+INSERT INTO dataset.orders (id, user_id) VALUES (1, 100);
+`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.ExecContext(ctx, `INSERT INTO dataset.orders (id, user_id) VALUES (1, 1)`); err == nil {
+		t.Fatal("expected error for the duplicated primary key")
+	}
+	if _, err := conn.ExecContext(ctx, `CREATE TABLE dataset.items (user_id INT64, FOREIGN KEY (user_id) REFERENCES dataset.users(id))`); err == nil {
+		t.Fatal("expected error for the enforced foreign key")
+	}
+	var tables []*zetasqlite.TableSpec
+	if err := conn.Raw(func(c interface{}) error {
+		specs, err := c.(*zetasqlite.ZetaSQLiteConn).Tables(ctx)
+		if err != nil {
+			return err
+		}
+		tables = specs
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	constraints := map[string][]*zetasqlite.ConstraintSpec{}
+	for _, table := range tables {
+		constraints[strings.Join(table.NamePath, ".")] = table.Constraints()
+	}
+	if diff := cmp.Diff(map[string][]*zetasqlite.ConstraintSpec{
+		"dataset.users": {
+			{Kind: zetasqlite.ConstraintKindPrimaryKey, Columns: []string{"id"}},
+			{Kind: zetasqlite.ConstraintKindNotNull, Columns: []string{"id"}, Enforced: true},
+		},
+		"dataset.orders": {
+			{Kind: zetasqlite.ConstraintKindPrimaryKey, Columns: []string{"id"}, Enforced: true},
+			{
+				Kind:              zetasqlite.ConstraintKindForeignKey,
+				Name:              "fk_user",
+				Columns:           []string{"user_id"},
+				ReferencedTable:   []string{"dataset", "users"},
+				ReferencedColumns: []string{"id"},
+			},
+			{Kind: zetasqlite.ConstraintKindNotNull, Columns: []string{"id"}, Enforced: true},
+		},
+	}, constraints); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+}
+
+func TestBigQuerySchema(t *testing.T) {
+	db, err := sql.Open("zetasqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	rows, err := db.Query(`SELECT 1 AS id, 'alice' AS name, [1.5, 2.5] AS scores, STRUCT(DATE '2022-01-01' AS d, NUMERIC '1.2' AS n) AS s`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	schema, err := zetasqlite.BigQuerySchemaFromRows(rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := bigquery.Schema{
+		{Name: "id", Type: bigquery.IntegerFieldType},
+		{Name: "name", Type: bigquery.StringFieldType},
+		{Name: "scores", Type: bigquery.FloatFieldType, Repeated: true},
+		{
+			Name: "s",
+			Type: bigquery.RecordFieldType,
+			Schema: bigquery.Schema{
+				{Name: "d", Type: bigquery.DateFieldType},
+				{Name: "n", Type: bigquery.NumericFieldType},
+			},
+		},
+	}
+	if diff := cmp.Diff(expected, schema); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+}
+
+func TestInsertAll(t *testing.T) {
+	db, err := sql.Open("zetasqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, "CREATE TABLE events (id INT64 NOT NULL, name STRING, created DATE)"); err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.Raw(func(c interface{}) error {
+		zetasqliteConn, ok := c.(*zetasqlite.ZetaSQLiteConn)
+		if !ok {
+			t.Fatalf("unexpected connection type %T", c)
+		}
+		zetasqliteConn.SetInsertIDWindowSize(2)
+		for _, test := range []struct {
+			rows     []*zetasqlite.InsertAllRow
+			expected zetasqlite.InsertAllResult
+		}{
+			{
+				rows: []*zetasqlite.InsertAllRow{
+					{InsertID: "a", Values: map[string]interface{}{"id": 1, "name": "alice", "created": "2022-01-01"}},
+					{InsertID: "b", Values: map[string]interface{}{"id": 2}},
+					{Values: map[string]interface{}{"id": 3, "name": "carol"}},
+				},
+				expected: zetasqlite.InsertAllResult{InsertedRows: 3},
+			},
+			{
+				// retry of the previous rows and the duplicated insertId in the same request.
+				rows: []*zetasqlite.InsertAllRow{
+					{InsertID: "a", Values: map[string]interface{}{"id": 1, "name": "alice", "created": "2022-01-01"}},
+					{InsertID: "c", Values: map[string]interface{}{"id": 4}},
+					{InsertID: "c", Values: map[string]interface{}{"id": 4}},
+				},
+				expected: zetasqlite.InsertAllResult{InsertedRows: 1, DeduplicatedRows: 2},
+			},
+			{
+				// "a" is out of the window of the last two insertIds ( "b" and "c" ).
+				rows: []*zetasqlite.InsertAllRow{
+					{InsertID: "a", Values: map[string]interface{}{"id": 1, "name": "alice", "created": "2022-01-01"}},
+				},
+				expected: zetasqlite.InsertAllResult{InsertedRows: 1},
+			},
+		} {
+			result, err := zetasqliteConn.InsertAll(ctx, "events", test.rows)
+			if err != nil {
+				return err
+			}
+			if diff := cmp.Diff(test.expected, *result); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		}
+		if _, err := zetasqliteConn.InsertAll(ctx, "events", []*zetasqlite.InsertAllRow{
+			{Values: map[string]interface{}{"name": "dave"}},
+		}); err == nil {
+			t.Fatal("expected error for missing required field")
+		}
+		if _, err := zetasqliteConn.InsertAll(ctx, "events", []*zetasqlite.InsertAllRow{
+			{Values: map[string]interface{}{"id": 5, "unknown": 1}},
+		}); err == nil {
+			t.Fatal("expected error for unknown field")
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := conn.QueryContext(ctx, "SELECT id, name, created FROM events ORDER BY id, name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var got [][]interface{}
+	for rows.Next() {
+		var (
+			id      int64
+			name    sql.NullString
+			created sql.NullString
+		)
+		if err := rows.Scan(&id, &name, &created); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, []interface{}{id, name.String, created.String})
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([][]interface{}{
+		{int64(1), "alice", "2022-01-01"},
+		{int64(1), "alice", "2022-01-01"},
+		{int64(2), "", ""},
+		{int64(3), "carol", ""},
+		{int64(4), "", ""},
+	}, got); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+}
+
+func TestInsertAllIsAtomic(t *testing.T) {
+	db, err := sql.Open("zetasqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, "CREATE TABLE items (id INT64, PRIMARY KEY (id))"); err != nil {
+		t.Fatal(err)
+	}
+	insertAll := func(rows ...*zetasqlite.InsertAllRow) (*zetasqlite.InsertAllResult, error) {
+		var result *zetasqlite.InsertAllResult
+		err := conn.Raw(func(c interface{}) error {
+			r, err := c.(*zetasqlite.ZetaSQLiteConn).InsertAll(ctx, "items", rows)
+			result = r
+			return err
+		})
+		return result, err
+	}
+	countItems := func() int64 {
+		var count int64
+		if err := conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM items").Scan(&count); err != nil {
+			t.Fatal(err)
+		}
+		return count
+	}
+	// the second row violates the primary key, so the first row and its insertId must not be saved.
+	if _, err := insertAll(
+		&zetasqlite.InsertAllRow{InsertID: "a", Values: map[string]interface{}{"id": 1}},
+		&zetasqlite.InsertAllRow{Values: map[string]interface{}{"id": 1}},
+	); err == nil {
+		t.Fatal("expected error for the duplicated primary key")
+	}
+	if count := countItems(); count != 0 {
+		t.Fatalf("expected no rows but got %d", count)
+	}
+	result, err := insertAll(&zetasqlite.InsertAllRow{InsertID: "a", Values: map[string]interface{}{"id": 2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.InsertedRows != 1 {
+		t.Fatalf("expected the row of the rolled back insertId to be inserted, but got %+v", result)
+	}
+
+	// the insertIds of the dropped table must not deduplicate the rows of the table created again.
+	if _, err := conn.ExecContext(ctx, "DROP TABLE items; CREATE TABLE items (id INT64)"); err != nil {
+		t.Fatal(err)
+	}
+	result, err = insertAll(&zetasqlite.InsertAllRow{InsertID: "a", Values: map[string]interface{}{"id": 3}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.InsertedRows != 1 {
+		t.Fatalf("expected the row to be inserted after the table is dropped, but got %+v", result)
+	}
+
+	if err := conn.Raw(func(c interface{}) error {
+		zetasqliteConn := c.(*zetasqlite.ZetaSQLiteConn)
+		zetasqliteConn.SetInsertIDWindowSize(-1)
+		if _, err := zetasqliteConn.InsertAll(ctx, "items", []*zetasqlite.InsertAllRow{
+			{Values: map[string]interface{}{"id": 4}},
+		}); err == nil {
+			t.Fatal("expected error for the negative window size")
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if count := countItems(); count != 1 {
+		t.Fatalf("expected 1 row but got %d", count)
+	}
+}
+
+func TestDetectSchema(t *testing.T) {
+	t.Run("json", func(t *testing.T) {
+		columns, err := zetasqlite.DetectJSONSchema(strings.NewReader(`{"id": 1, "score": 1, "tags": ["a"], "user": {"name": "alice", "joined": "2022-01-01"}, "note": null}
+{"id": 2, "score": 2.5, "tags": [], "user": {"name": "bob", "joined": "2022-01-02 10:00:00"}, "items": [{"sku": "x", "qty": 1}]}
+`), 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		schema, err := zetasqlite.BigQuerySchema(columns)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := bigquery.Schema{
+			{Name: "id", Type: bigquery.IntegerFieldType},
+			{Name: "note", Type: bigquery.StringFieldType},
+			{Name: "score", Type: bigquery.FloatFieldType},
+			{Name: "tags", Type: bigquery.StringFieldType, Repeated: true},
+			{
+				Name: "user",
+				Type: bigquery.RecordFieldType,
+				Schema: bigquery.Schema{
+					{Name: "joined", Type: bigquery.TimestampFieldType},
+					{Name: "name", Type: bigquery.StringFieldType},
+				},
+			},
+			{
+				Name:     "items",
+				Type:     bigquery.RecordFieldType,
+				Repeated: true,
+				Schema: bigquery.Schema{
+					{Name: "qty", Type: bigquery.IntegerFieldType},
+					{Name: "sku", Type: bigquery.StringFieldType},
+				},
+			},
+		}
+		if diff := cmp.Diff(expected, schema); diff != "" {
+			t.Errorf("(-want +got):\n%s", diff)
+		}
+	})
+	t.Run("json with conflicting repeated field", func(t *testing.T) {
+		if _, err := zetasqlite.DetectJSONSchema(strings.NewReader(`{"a": [1]}
+{"a": 1}
+`), 0); err == nil {
+			t.Fatal("expected error for REPEATED and non-REPEATED values")
+		}
+	})
+	t.Run("csv with header", func(t *testing.T) {
+		columns, err := zetasqlite.DetectCSVSchema(strings.NewReader("id,full name,active,created\n1,alice,true,2022-01-01\n2,,false,2022-01-02\n"), 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		schema, err := zetasqlite.BigQuerySchema(columns)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := bigquery.Schema{
+			{Name: "id", Type: bigquery.IntegerFieldType},
+			{Name: "full_name", Type: bigquery.StringFieldType},
+			{Name: "active", Type: bigquery.BooleanFieldType},
+			{Name: "created", Type: bigquery.DateFieldType},
+		}
+		if diff := cmp.Diff(expected, schema); diff != "" {
+			t.Errorf("(-want +got):\n%s", diff)
+		}
+	})
+	t.Run("csv without header", func(t *testing.T) {
+		columns, err := zetasqlite.DetectCSVSchema(strings.NewReader("alice,1\nbob,2.5\n"), 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		names := make([]string, 0, len(columns))
+		for _, col := range columns {
+			names = append(names, col.Name)
+		}
+		if diff := cmp.Diff([]string{"string_field_0", "double_field_1"}, names); diff != "" {
+			t.Errorf("(-want +got):\n%s", diff)
+		}
+	})
+}
+
+func TestAggregationMemoryLimit(t *testing.T) {
+	db, err := sql.Open("zetasqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	setLimit := func(limit int64) {
+		if err := conn.Raw(func(c interface{}) error {
+			zetasqliteConn, ok := c.(*zetasqlite.ZetaSQLiteConn)
+			if !ok {
+				t.Fatalf("unexpected connection type %T", c)
+			}
+			return zetasqliteConn.SetAggregationMemoryLimit(ctx, limit)
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	query := "SELECT ARRAY_LENGTH(ARRAY_AGG(REPEAT('x', 100))) FROM UNNEST(GENERATE_ARRAY(1, 100))"
+	setLimit(1024)
+	rows, err := conn.QueryContext(ctx, query)
+	if err == nil {
+		for rows.Next() {
+		}
+		err = rows.Err()
+		rows.Close()
+	}
+	if err == nil || !strings.Contains(err.Error(), "aggregation memory limit exceeded") {
+		t.Fatalf("expected aggregation memory limit error but got %v", err)
+	}
+
+	setLimit(0)
+	var got int64
+	if err := conn.QueryRowContext(ctx, query).Scan(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got != 100 {
+		t.Fatalf("expected 100 values but got %d", got)
+	}
+
+	windowQuery := "SELECT MAX(v) OVER () FROM UNNEST(ARRAY(SELECT REPEAT('x', 100) FROM UNNEST(GENERATE_ARRAY(1, 100)))) AS v"
+	setLimit(1024)
+	rows, err = conn.QueryContext(ctx, windowQuery)
+	if err == nil {
+		for rows.Next() {
+		}
+		err = rows.Err()
+		rows.Close()
+	}
+	if err == nil || !strings.Contains(err.Error(), "aggregation memory limit exceeded") {
+		t.Fatalf("expected aggregation memory limit error for window function but got %v", err)
+	}
+}
+
+func TestAttachedDataset(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	refPath := filepath.Join(dir, "ref.db")
+	logsPath := filepath.Join(dir, "logs.db")
+
+	refDB, err := sql.Open("zetasqlite", refPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := refDB.ExecContext(ctx, "CREATE TABLE ref.users (id INT64, name STRING)"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := refDB.ExecContext(ctx, "INSERT ref.users (id, name) VALUES (1, 'alice'), (2, 'bob')"); err != nil {
+		t.Fatal(err)
+	}
+	refDB.Close()
+
+	db, err := sql.Open(
+		"zetasqlite",
+		filepath.Join(dir, "scratch.db")+"?_zetasqlite_attach_ro=ref="+refPath+"&_zetasqlite_attach=logs="+logsPath,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.ExecContext(ctx, "CREATE TABLE logs.visits (user_id INT64)"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.ExecContext(ctx, "INSERT logs.visits (user_id) VALUES (1), (1), (2)"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.ExecContext(ctx, "CREATE TABLE ref.scratch (id INT64)"); err == nil {
+		t.Fatal("expected error for creating table in read-only dataset")
+	}
+	if _, err := db.ExecContext(ctx, "INSERT ref.users (id, name) VALUES (3, 'carol')"); err == nil {
+		t.Fatal("expected error for inserting rows to read-only dataset")
+	}
+	rows, err := db.QueryContext(
+		ctx,
+		"SELECT name, COUNT(*) FROM ref.users JOIN logs.visits ON users.id = visits.user_id GROUP BY name ORDER BY name",
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var got [][]interface{}
+	for rows.Next() {
+		var (
+			name  string
+			count int64
+		)
+		if err := rows.Scan(&name, &count); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, []interface{}{name, count})
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([][]interface{}{{"alice", int64(2)}, {"bob", int64(1)}}, got); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+
+	// the table created in the attached dataset is stored in its database file.
+	logsDB, err := sql.Open("zetasqlite", logsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logsDB.Close()
+	var count int64
+	if err := logsDB.QueryRowContext(ctx, "SELECT COUNT(*) FROM logs.visits").Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 3 {
+		t.Fatalf("expected 3 rows in attached database but got %d", count)
+	}
+}
+
+func TestReadOnlyMode(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "fixture.db")
+	fixtureDB, err := sql.Open("zetasqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fixtureDB.ExecContext(ctx, "CREATE TABLE users (id INT64, name STRING); INSERT users (id, name) VALUES (1, 'alice')"); err != nil {
+		t.Fatal(err)
+	}
+	fixtureDB.Close()
+
+	db, err := sql.Open("zetasqlite", path+"?_zetasqlite_read_only=true")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	for _, test := range []struct {
+		query       string
+		expectedErr string
+	}{
+		{query: "INSERT users (id, name) VALUES (2, 'bob')", expectedErr: "INSERT statement is not allowed in read-only mode"},
+		{query: "UPDATE users SET name = 'bob' WHERE id = 1", expectedErr: "UPDATE statement is not allowed in read-only mode"},
+		{query: "DELETE FROM users WHERE TRUE", expectedErr: "DELETE statement is not allowed in read-only mode"},
+		{query: "CREATE TABLE logs (id INT64)", expectedErr: "CREATE TABLE statement is not allowed in read-only mode"},
+		{query: "DROP TABLE users", expectedErr: "DROP statement is not allowed in read-only mode"},
+		{query: "TRUNCATE TABLE users", expectedErr: "TRUNCATE TABLE statement is not allowed in read-only mode"},
+		{query: "CREATE TEMP FUNCTION double_id(id INT64) AS (id * 2)"},
+	} {
+		_, err := db.ExecContext(ctx, test.query)
+		if test.expectedErr == "" {
+			if err != nil {
+				t.Fatalf("%s: %v", test.query, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), test.expectedErr) {
+			t.Fatalf("%s: expected error %q but got %v", test.query, test.expectedErr, err)
+		}
+	}
+	var name string
+	if err := db.QueryRowContext(ctx, "SELECT name FROM users WHERE id = 1").Scan(&name); err != nil {
+		t.Fatal(err)
+	}
+	if name != "alice" {
+		t.Fatalf("expected alice but got %s", name)
+	}
+}
+
+func TestResultCache(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("zetasqlite", "file:TestResultCache?mode=memory&cache=shared&_zetasqlite_result_cache=true")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.ExecContext(ctx, `
+CREATE TABLE items (id INT64, name STRING);
+INSERT items (id, name) VALUES (1, 'a'), (2, 'b');
+CREATE TABLE others (id INT64, name STRING);
+CREATE TABLE logs.entries (id INT64, name STRING);
+INSERT logs.entries (id, name) VALUES (1, 'l');
+CREATE FUNCTION js_name(name STRING) RETURNS STRING NOT DETERMINISTIC LANGUAGE js AS "return name;";
+`); err != nil {
+		t.Fatal(err)
+	}
+	query := func(t *testing.T, query string, args ...interface{}) ([]string, bool) {
+		t.Helper()
+		rows, err := db.QueryContext(ctx, query, args...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rows.Close()
+		stats, err := zetasqlite.QueryStatisticsFromRows(rows)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for rows.Next() {
+			var name string
+			if err := rows.Scan(&name); err != nil {
+				t.Fatal(err)
+			}
+			names = append(names, name)
+		}
+		if err := rows.Err(); err != nil {
+			t.Fatal(err)
+		}
+		return names, stats.CacheHit
+	}
+	for _, test := range []struct {
+		name     string
+		exec     string
+		query    string
+		args     []interface{}
+		expected []string
+		cacheHit bool
+	}{
+		{name: "first query", query: "SELECT name FROM items ORDER BY id", expected: []string{"a", "b"}},
+		{name: "same query", query: "SELECT name FROM items ORDER BY id", expected: []string{"a", "b"}, cacheHit: true},
+		{name: "query with arg", query: "SELECT name FROM items WHERE id = @id", args: []interface{}{sql.Named("id", 1)}, expected: []string{"a"}},
+		{name: "query with same arg", query: "SELECT name FROM items WHERE id = @id", args: []interface{}{sql.Named("id", 1)}, expected: []string{"a"}, cacheHit: true},
+		{name: "query with different arg", query: "SELECT name FROM items WHERE id = @id", args: []interface{}{sql.Named("id", 2)}, expected: []string{"b"}},
+		{name: "after insert", exec: "INSERT items (id, name) VALUES (3, 'c')", query: "SELECT name FROM items ORDER BY id", expected: []string{"a", "b", "c"}},
+		{name: "cached after insert", query: "SELECT name FROM items ORDER BY id", expected: []string{"a", "b", "c"}, cacheHit: true},
+		{name: "after update", exec: "UPDATE items SET name = 'x' WHERE id = 1", query: "SELECT name FROM items ORDER BY id", expected: []string{"x", "b", "c"}},
+		{name: "non deterministic function", query: "SELECT name FROM items WHERE RAND() < 2 ORDER BY id", expected: []string{"x", "b", "c"}},
+		{name: "non deterministic function again", query: "SELECT name FROM items WHERE RAND() < 2 ORDER BY id", expected: []string{"x", "b", "c"}},
+		{name: "non deterministic javascript function", query: "SELECT js_name(name) FROM items ORDER BY id", expected: []string{"x", "b", "c"}},
+		{name: "non deterministic javascript function again", query: "SELECT js_name(name) FROM items ORDER BY id", expected: []string{"x", "b", "c"}},
+		{name: "cached before modifying other table", query: "SELECT name FROM items ORDER BY id", expected: []string{"x", "b", "c"}, cacheHit: true},
+		{name: "after modifying other table", exec: "INSERT others (id, name) VALUES (1, 'z')", query: "SELECT name FROM items ORDER BY id", expected: []string{"x", "b", "c"}, cacheHit: true},
+		{name: "join with modified table", query: "SELECT o.name FROM items AS i JOIN others AS o USING (id)", expected: []string{"z"}},
+		{name: "join after modifying joined table", exec: "UPDATE others SET name = 'y' WHERE id = 1", query: "SELECT o.name FROM items AS i JOIN others AS o USING (id)", expected: []string{"y"}},
+		{name: "dataset written in different case", query: "SELECT name FROM LOGS.entries ORDER BY id", expected: []string{"l"}},
+		{name: "cached dataset written in different case", query: "SELECT name FROM LOGS.entries ORDER BY id", expected: []string{"l"}, cacheHit: true},
+		{name: "after insert to dataset written in different case", exec: "INSERT logs.entries (id, name) VALUES (2, 'm')", query: "SELECT name FROM LOGS.entries ORDER BY id", expected: []string{"l", "m"}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if test.exec != "" {
+				if _, err := db.ExecContext(ctx, test.exec); err != nil {
+					t.Fatal(err)
+				}
+			}
+			names, cacheHit := query(t, test.query, test.args...)
+			if diff := cmp.Diff(test.expected, names); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+			if cacheHit != test.cacheHit {
+				t.Errorf("expected cache hit %t but got %t", test.cacheHit, cacheHit)
+			}
+		})
+	}
+	t.Run("transaction", func(t *testing.T) {
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tx.ExecContext(ctx, "DELETE FROM items WHERE id = 3"); err != nil {
+			t.Fatal(err)
+		}
+		if err := tx.Commit(); err != nil {
+			t.Fatal(err)
+		}
+		names, cacheHit := query(t, "SELECT name FROM items ORDER BY id")
+		if diff := cmp.Diff([]string{"x", "b"}, names); diff != "" {
+			t.Errorf("(-want +got):\n%s", diff)
+		}
+		if cacheHit {
+			t.Error("expected cache miss after commit")
+		}
+	})
+	t.Run("insert all", func(t *testing.T) {
+		if _, cacheHit := query(t, "SELECT name FROM items ORDER BY id"); cacheHit {
+			t.Fatal("expected cache miss for the first query")
+		}
+		if _, cacheHit := query(t, "SELECT name FROM items ORDER BY id"); !cacheHit {
+			t.Fatal("expected cache hit for the same query")
+		}
+		conn, err := db.Conn(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+		if err := conn.Raw(func(c interface{}) error {
+			_, err := c.(*zetasqlite.ZetaSQLiteConn).InsertAll(ctx, "items", []*zetasqlite.InsertAllRow{
+				{Values: map[string]interface{}{"id": 4, "name": "d"}},
+			})
+			return err
+		}); err != nil {
+			t.Fatal(err)
+		}
+		names, cacheHit := query(t, "SELECT name FROM items ORDER BY id")
+		if diff := cmp.Diff([]string{"x", "b", "d"}, names); diff != "" {
+			t.Errorf("(-want +got):\n%s", diff)
+		}
+		if cacheHit {
+			t.Error("expected cache miss after InsertAll")
+		}
+	})
+}
+
+func TestJournalMode(t *testing.T) {
+	ctx := context.Background()
+	for _, test := range []struct {
+		name     string
+		params   string
+		expected string
+	}{
+		{
+			name:     "default",
+			expected: "wal",
+		},
+		{
+			name:     "specified by dsn",
+			params:   "?_journal_mode=DELETE&_zetasqlite_result_cache=true",
+			expected: "delete",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			db, err := sql.Open("zetasqlite", "file:"+filepath.Join(t.TempDir(), "journal.db")+test.params)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+			conn, err := db.Conn(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+			var mode string
+			if err := conn.Raw(func(c interface{}) error {
+				return c.(*zetasqlite.ZetaSQLiteConn).RawSQLiteConn(func(sqliteConn *sqlite3.SQLiteConn) error {
+					rows, err := sqliteConn.Query("PRAGMA journal_mode", nil)
+					if err != nil {
+						return err
+					}
+					defer rows.Close()
+					values := make([]driver.Value, 1)
+					if err := rows.Next(values); err != nil {
+						return err
+					}
+					mode = fmt.Sprint(values[0])
+					return nil
+				})
+			}); err != nil {
+				t.Fatal(err)
+			}
+			if mode != test.expected {
+				t.Fatalf("expected journal mode %s but got %s", test.expected, mode)
+			}
+		})
+	}
+}
+
+func TestSnapshotIsolation(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("zetasqlite", filepath.Join(t.TempDir(), "snapshot.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.ExecContext(ctx, "CREATE TABLE items (id INT64); INSERT items (id) VALUES (1)"); err != nil {
+		t.Fatal(err)
+	}
+	reader, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	writer, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer writer.Close()
+
+	tx, err := reader.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSnapshot, ReadOnly: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	countInTx := func() int64 {
+		t.Helper()
+		var count int64
+		if err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM items").Scan(&count); err != nil {
+			t.Fatal(err)
+		}
+		return count
+	}
+	if count := countInTx(); count != 1 {
+		t.Fatalf("expected 1 row but got %d", count)
+	}
+	// the writer isn't blocked by the transaction of the reader.
+	if _, err := writer.ExecContext(ctx, "INSERT items (id) VALUES (2), (3)"); err != nil {
+		t.Fatal(err)
+	}
+	if count := countInTx(); count != 1 {
+		t.Fatalf("expected the snapshot of 1 row in the transaction but got %d", count)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	var count int64
+	if err := reader.QueryRowContext(ctx, "SELECT COUNT(*) FROM items").Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 3 {
+		t.Fatalf("expected 3 rows after the transaction but got %d", count)
+	}
+}
+
+func TestColumnDefaultValue(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("zetasqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.ExecContext(ctx, `
+CREATE TABLE events (
+  id INT64,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP(),
+  created_by STRING DEFAULT SESSION_USER(),
+  priority INT64 DEFAULT 10
+)`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.ExecContext(ctx, "INSERT events (id) VALUES (1)"); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if _, err := db.ExecContext(ctx, "INSERT events (id, created_at, created_by, priority) VALUES (2, DEFAULT, DEFAULT, DEFAULT)"); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := db.QueryContext(ctx, "SELECT created_at, created_by, priority FROM events ORDER BY id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var createdAts []time.Time
+	for rows.Next() {
+		var (
+			createdAt time.Time
+			createdBy string
+			priority  int64
+		)
+		if err := rows.Scan(&createdAt, &createdBy, &priority); err != nil {
+			t.Fatal(err)
+		}
+		if createdBy != "dummy" {
+			t.Fatalf("unexpected created_by %q", createdBy)
+		}
+		if priority != 10 {
+			t.Fatalf("unexpected priority %d", priority)
+		}
+		createdAts = append(createdAts, createdAt)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(createdAts) != 2 {
+		t.Fatalf("failed to get rows: %v", createdAts)
+	}
+	if !createdAts[1].After(createdAts[0]) {
+		t.Fatalf("expected DEFAULT to be evaluated at insert time but got %v and %v", createdAts[0], createdAts[1])
+	}
+
+	// CURRENT_TIMESTAMP() of DEFAULT returns the same time for all rows of the statement.
+	if _, err := db.ExecContext(ctx, "INSERT events (id) VALUES (3), (4), (5)"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.ExecContext(ctx, "INSERT events (id) SELECT id FROM UNNEST(GENERATE_ARRAY(6, 1000)) AS id"); err != nil {
+		t.Fatal(err)
+	}
+	var count int64
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(DISTINCT created_at) FROM events WHERE id >= 3").Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Fatalf("expected the time of each statement but got %d distinct times", count)
+	}
+}
+
+func TestAlterTableSetOptions(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "options.db")
+	db, err := sql.Open("zetasqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.ExecContext(ctx, `CREATE TABLE items (id INT64, name STRING) OPTIONS(description="items")`); err != nil {
+		t.Fatal(err)
+	}
+	result, err := db.ExecContext(ctx, `
+ALTER TABLE items SET OPTIONS (
+  description = "the list of items",
+  labels = [("env", "dev"), ("team", "data")],
+  expiration_timestamp = TIMESTAMP "2030-01-01 00:00:00 UTC"
+)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	changed, err := zetasqlite.ChangedCatalogFromResult(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changed.Table.Updated) != 1 || changed.Table.Updated[0].Description != "the list of items" {
+		t.Fatalf("failed to get updated table spec: %+v", changed.Table.Updated)
+	}
+	if _, err := db.ExecContext(ctx, `ALTER TABLE items ALTER COLUMN name SET OPTIONS (description = "the name of the item")`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.ExecContext(ctx, `ALTER TABLE items SET OPTIONS (description = NULL)`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.ExecContext(ctx, `ALTER TABLE missing_items SET OPTIONS (description = "missing")`); err == nil {
+		t.Fatal("expected error for missing table")
+	}
+	if _, err := db.ExecContext(ctx, `ALTER TABLE IF EXISTS missing_items SET OPTIONS (description = "missing")`); err != nil {
+		t.Fatal(err)
+	}
+
+	// reopen the database to confirm that the options are persisted in the catalog.
+	db.Close()
+	reopened, err := sql.Open("zetasqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+	conn, err := reopened.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	var tables []*zetasqlite.TableSpec
+	if err := conn.Raw(func(c interface{}) error {
+		specs, err := c.(*zetasqlite.ZetaSQLiteConn).Tables(ctx)
+		if err != nil {
+			return err
+		}
+		tables = specs
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(tables) != 1 {
+		t.Fatalf("unexpected tables: %v", tables)
+	}
+	spec := tables[0]
+	if spec.Description != "" {
+		t.Errorf("expected description to be cleared but got %q", spec.Description)
+	}
+	if diff := cmp.Diff(spec.Labels, map[string]string{"env": "dev", "team": "data"}); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+	if spec.ExpirationTime == nil || !spec.ExpirationTime.Equal(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected expiration time %v", spec.ExpirationTime)
+	}
+	if desc := spec.ColumnDescription("name"); desc != "the name of the item" {
+		t.Errorf("unexpected description of name column: %q", desc)
+	}
+}
+
+func TestCreateTableWithOptionExpressions(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("zetasqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, `
+CREATE TABLE items (id INT64) OPTIONS (
+  description = CONCAT("the list of ", "items"),
+  expiration_timestamp = TIMESTAMP_ADD(TIMESTAMP "2030-01-01 00:00:00 UTC", INTERVAL 1 DAY)
+);
+CREATE TABLE copied_items OPTIONS (
+  expiration_timestamp = TIMESTAMP_ADD(TIMESTAMP "2030-01-01 00:00:00 UTC", INTERVAL 2 DAY)
+) AS SELECT id FROM items;
+`); err != nil {
+		t.Fatal(err)
+	}
+	var tables []*zetasqlite.TableSpec
+	if err := conn.Raw(func(c interface{}) error {
+		specs, err := c.(*zetasqlite.ZetaSQLiteConn).Tables(ctx)
+		if err != nil {
+			return err
+		}
+		tables = specs
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	expirations := map[string]time.Time{
+		"items":        time.Date(2030, 1, 2, 0, 0, 0, 0, time.UTC),
+		"copied_items": time.Date(2030, 1, 3, 0, 0, 0, 0, time.UTC),
+	}
+	for _, spec := range tables {
+		name := strings.Join(spec.NamePath, ".")
+		expected, exists := expirations[name]
+		if !exists {
+			continue
+		}
+		delete(expirations, name)
+		if spec.ExpirationTime == nil || !spec.ExpirationTime.Equal(expected) {
+			t.Errorf("unexpected expiration time of %s: %v", name, spec.ExpirationTime)
+		}
+		if name == "items" && spec.Description != "the list of items" {
+			t.Errorf("unexpected description of %s: %q", name, spec.Description)
+		}
+	}
+	if len(expirations) != 0 {
+		t.Fatalf("failed to find tables: %v", expirations)
+	}
+}
+
+func TestTableSampleIsReproducible(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("zetasqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.ExecContext(ctx, `
+CREATE TABLE sampled_items (id INT64, name STRING, PRIMARY KEY (id) NOT ENFORCED);
+INSERT INTO sampled_items (id, name) SELECT id, 'item' FROM UNNEST(GENERATE_ARRAY(1, 500)) AS id;
+`); err != nil {
+		t.Fatal(err)
+	}
+	sample := func(query string) []int64 {
+		rows, err := db.QueryContext(ctx, query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rows.Close()
+		var ids []int64
+		for rows.Next() {
+			var id int64
+			if err := rows.Scan(&id); err != nil {
+				t.Fatal(err)
+			}
+			ids = append(ids, id)
+		}
+		if err := rows.Err(); err != nil {
+			t.Fatal(err)
+		}
+		return ids
+	}
+	for _, query := range []string{
+		"SELECT id FROM sampled_items TABLESAMPLE SYSTEM (20 PERCENT) ORDER BY id",
+		"SELECT id FROM sampled_items TABLESAMPLE SYSTEM (20 PERCENT) REPEATABLE (1) ORDER BY id",
+		"SELECT id FROM sampled_items TABLESAMPLE RESERVOIR (10 ROWS) ORDER BY id",
+	} {
+		first := sample(query)
+		if len(first) == 0 {
+			t.Fatalf("no rows are sampled by %s", query)
+		}
+		for i := 0; i < 3; i++ {
+			if diff := cmp.Diff(first, sample(query)); diff != "" {
+				t.Fatalf("the sample of %s is changed (-first +got):\n%s", query, diff)
+			}
+		}
+	}
+	if diff := cmp.Diff(
+		sample("SELECT id FROM sampled_items TABLESAMPLE SYSTEM (20 PERCENT) REPEATABLE (1) ORDER BY id"),
+		sample("SELECT id FROM sampled_items TABLESAMPLE SYSTEM (20 PERCENT) REPEATABLE (2) ORDER BY id"),
+	); diff == "" {
+		t.Fatal("expected the different samples for the different seeds")
+	}
+}
+
+func TestRenameTable(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("zetasqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.ExecContext(ctx, `
+CREATE TABLE items (id INT64, name STRING);
+INSERT items (id, name) VALUES (1, 'apple'), (2, 'banana');
+CREATE TABLE others (id INT64);
+CREATE VIEW item_names AS SELECT name FROM items;
+CREATE FUNCTION count_items() AS ((SELECT COUNT(*) FROM items));
+`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.ExecContext(ctx, "ALTER TABLE items RENAME TO others"); err == nil {
+		t.Fatal("expected error for renaming to the existing table")
+	}
+	if _, err := db.ExecContext(ctx, "ALTER TABLE items RENAME TO products"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.ExecContext(ctx, "RENAME TABLE products TO goods"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.QueryContext(ctx, "SELECT * FROM items"); err == nil {
+		t.Fatal("expected error for the old table name")
+	}
+	var count int64
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM goods").Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Fatalf("unexpected count %d", count)
+	}
+	var names []string
+	rows, err := db.QueryContext(ctx, "SELECT name FROM item_names ORDER BY name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, name)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(names, []string{"apple", "banana"}); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+	if err := db.QueryRowContext(ctx, "SELECT count_items()").Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Fatalf("unexpected count %d", count)
+	}
+}
+
+func TestRenameTableFailure(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("zetasqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	// the column of b_view has the same name as the table, so the reference to the table can't be distinguished from it.
+	if _, err := conn.ExecContext(ctx, `
+CREATE TABLE items (id INT64, items STRING);
+INSERT items (id, items) VALUES (1, 'apple');
+CREATE VIEW a_view AS SELECT id FROM items;
+CREATE VIEW b_view AS SELECT items FROM items;
+`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.ExecContext(ctx, "ALTER TABLE items RENAME TO products"); err == nil {
+		t.Fatal("expected error for the view that can't be rewritten")
+	}
+	if err := conn.Raw(func(c interface{}) error {
+		specs, err := c.(*zetasqlite.ZetaSQLiteConn).Tables(ctx)
+		if err != nil {
+			return err
+		}
+		for _, spec := range specs {
+			if spec.TableName() == "products" {
+				t.Fatal("the renamed table spec must be removed by the failed rename")
+			}
+			if spec.TableName() == "a_view" && !strings.Contains(spec.Query, "`items`") {
+				t.Fatalf("the view spec must be restored by the failed rename: %s", spec.Query)
+			}
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	var id int64
+	if err := conn.QueryRowContext(ctx, "SELECT id FROM a_view").Scan(&id); err != nil {
+		t.Fatal(err)
+	}
+	if id != 1 {
+		t.Fatalf("unexpected id %d", id)
+	}
+}
+
+func TestRenameTableWithAutoIndex(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("zetasqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	var indexes []string
+	if err := conn.Raw(func(c interface{}) error {
+		zetasqliteConn := c.(*zetasqlite.ZetaSQLiteConn)
+		zetasqliteConn.SetAutoIndexMode(true)
+		if _, err := zetasqliteConn.ExecContext(ctx, `
+CREATE TABLE items (id INT64);
+ALTER TABLE items RENAME TO products;
+CREATE TABLE items (id INT64);
+`, nil); err != nil {
+			return err
+		}
+		return zetasqliteConn.RawSQLiteConn(func(sqliteConn *sqlite3.SQLiteConn) error {
+			rows, err := sqliteConn.Query("SELECT tbl_name || ':' || name FROM sqlite_master WHERE type = 'index' AND name LIKE 'zetasqlite_autoindex_%' ORDER BY name", nil)
+			if err != nil {
+				return err
+			}
+			defer rows.Close()
+			values := make([]driver.Value, 1)
+			for rows.Next(values) == nil {
+				indexes = append(indexes, fmt.Sprint(values[0]))
+			}
+			return nil
+		})
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(indexes, []string{
+		"items:zetasqlite_autoindex_id_items",
+		"products:zetasqlite_autoindex_id_products",
+	}); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+}
+
+func TestAlterColumnSetDataType(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("zetasqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.ExecContext(ctx, `
+CREATE TABLE items (id INT64, price INT64, weight INT64, name STRING(10));
+INSERT items (id, price, weight, name) VALUES (1, 100, 3, 'apple'), (2, NULL, 5, 'banana');
+`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.ExecContext(ctx, `
+ALTER TABLE items
+  ALTER COLUMN price SET DATA TYPE NUMERIC,
+  ALTER COLUMN weight SET DATA TYPE FLOAT64,
+  ALTER COLUMN name SET DATA TYPE STRING(20)`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.ExecContext(ctx, "INSERT items (id, price, weight, name) VALUES (3, 1.25, 0.5, 'a long name of item')"); err != nil {
+		t.Fatal(err)
+	}
+	type item struct {
+		Price  *string
+		Weight float64
+		Name   string
+	}
+	var items []*item
+	rows, err := db.QueryContext(ctx, "SELECT CAST(price AS STRING), weight / 2, name FROM items ORDER BY id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var it item
+		if err := rows.Scan(&it.Price, &it.Weight, &it.Name); err != nil {
+			t.Fatal(err)
+		}
+		items = append(items, &it)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	price := func(v string) *string { return &v }
+	if diff := cmp.Diff(items, []*item{
+		{Price: price("100"), Weight: 1.5, Name: "apple"},
+		{Price: nil, Weight: 2.5, Name: "banana"},
+		{Price: price("1.25"), Weight: 0.25, Name: "a long name of item"},
+	}); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+	for _, query := range []string{
+		"ALTER TABLE items ALTER COLUMN name SET DATA TYPE INT64",
+		"ALTER TABLE items ALTER COLUMN name SET DATA TYPE STRING(5)",
+		"ALTER TABLE items ALTER COLUMN weight SET DATA TYPE INT64",
+		"ALTER TABLE items ALTER COLUMN missing SET DATA TYPE INT64",
+	} {
+		if _, err := db.ExecContext(ctx, query); err == nil {
+			t.Errorf("expected error for %q", query)
+		}
+	}
+	if _, err := db.ExecContext(ctx, "ALTER TABLE items ALTER COLUMN IF EXISTS missing SET DATA TYPE INT64"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRunBundle(t *testing.T) {
+	dir := t.TempDir()
+	for path, query := range map[string]string{
+		"00_view.sql":        `CREATE VIEW active_user_names AS SELECT name FROM users WHERE is_active(id)`,
+		"01_function.sql":    `CREATE FUNCTION is_active(id INT64) AS (id > 1)`,
+		"broken.sql":         `CREATE TABLE broken (id UNKNOWN_TYPE)`,
+		"cycle_a.sql":        `CREATE VIEW cycle_a AS SELECT * FROM cycle_b`,
+		"cycle_b.sql":        `CREATE VIEW cycle_b AS SELECT * FROM cycle_a`,
+		"depends_broken.sql": `CREATE VIEW broken_view AS SELECT * FROM broken`,
+		"tables/users.sql": `
+CREATE TABLE users (id INT64, name STRING);
+INSERT INTO users (id, name) VALUES (1, 'alice'), (2, 'bob');
+`,
+		"README.md": `not a sql file`,
+	} {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, path)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, path), []byte(query), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db, err := sql.Open("zetasqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	var results []*zetasqlite.BundleFileResult
+	if err := conn.Raw(func(c interface{}) error {
+		zetasqliteConn, ok := c.(*zetasqlite.ZetaSQLiteConn)
+		if !ok {
+			t.Fatalf("unexpected connection type %T", c)
+		}
+		res, err := zetasqliteConn.RunBundle(ctx, dir)
+		if err != nil {
+			return err
+		}
+		results = res
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	type fileResult struct {
+		Path         string
+		Dependencies []string
+		Err          string
+	}
+	var got []*fileResult
+	for _, result := range results {
+		r := &fileResult{Path: result.Path, Dependencies: result.Dependencies}
+		if result.Err != nil {
+			r.Err = result.Err.Error()
+		}
+		got = append(got, r)
+	}
+	if len(got) != 7 {
+		t.Fatalf("unexpected number of results %d", len(got))
+	}
+	if got[1].Path != "broken.sql" || got[1].Err == "" {
+		t.Fatalf("expected error for broken.sql but got %+v", got[1])
+	}
+	// the error of the invalid statement depends on ZetaSQL, so it's not compared.
+	got[1].Err = "error"
+	circularErr := "circular dependency found among the files: cycle_a.sql, cycle_b.sql"
+	if diff := cmp.Diff([]*fileResult{
+		{Path: "01_function.sql"},
+		{Path: "broken.sql", Err: "error"},
+		{Path: "depends_broken.sql", Dependencies: []string{"broken.sql"}, Err: "skipped because the dependency broken.sql failed"},
+		{Path: "tables/users.sql"},
+		{Path: "00_view.sql", Dependencies: []string{"01_function.sql", "tables/users.sql"}},
+		{Path: "cycle_a.sql", Dependencies: []string{"cycle_b.sql"}, Err: circularErr},
+		{Path: "cycle_b.sql", Dependencies: []string{"cycle_a.sql"}, Err: circularErr},
+	}, got); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+	var name string
+	if err := conn.QueryRowContext(ctx, `SELECT name FROM active_user_names`).Scan(&name); err != nil {
+		t.Fatal(err)
+	}
+	if name != "bob" {
+		t.Fatalf("unexpected name %q", name)
+	}
+}
+
+func TestOutputColumnNames(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("zetasqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.ExecContext(ctx, `
+CREATE TABLE orders (id INT64, item STRING, price INT64);
+CREATE TABLE items (id INT64, item STRING, ID_1 INT64);
+INSERT orders (id, item, price) VALUES (1, 'apple', 100);
+INSERT items (id, item, ID_1) VALUES (1, 'apple', 10);
+`); err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		query   string
+		columns []string
+	}{
+		{
+			query:   "SELECT * FROM orders JOIN items ON orders.id = items.id",
+			columns: []string{"id", "item", "price", "id_2", "item_1", "ID_1"},
+		},
+		{
+			query:   "SELECT * FROM orders JOIN items USING (id)",
+			columns: []string{"id", "item", "price", "item_1", "ID_1"},
+		},
+		{
+			query:   "SELECT * EXCEPT (price) FROM orders JOIN items USING (id, item)",
+			columns: []string{"id", "item", "ID_1"},
+		},
+		{
+			query:   "SELECT orders.item, items.item FROM orders JOIN items USING (id)",
+			columns: []string{"item", "item_1"},
+		},
+		{
+			query:   "SELECT 1, id, UPPER(item), id + 1 FROM orders",
+			columns: []string{"f0_", "id", "f1_", "f2_"},
+		},
+	} {
+		rows, err := db.QueryContext(ctx, test.query)
+		if err != nil {
+			t.Fatalf("%s: %v", test.query, err)
+		}
+		columns, err := rows.Columns()
+		if err != nil {
+			t.Fatal(err)
+		}
+		rows.Close()
+		if diff := cmp.Diff(test.columns, columns); diff != "" {
+			t.Errorf("%s: (-want +got):\n%s", test.query, diff)
+		}
+	}
+}
+
+func TestMigrateValueEncodingOnOpen(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "legacy.db")
+	db, err := sql.Open("zetasqlite", "file:"+path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.ExecContext(ctx, "CREATE TABLE legacy_table (s STRING); INSERT legacy_table (s) VALUES ('a')"); err != nil {
+		t.Fatal(err)
+	}
+
+	// store the value by the legacy encoding as the older versions did.
+	sqliteDB, err := sql.Open(zetasqlite.SQLiteDriverName, "file:"+path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sqliteDB.Close()
+	legacy := base64.StdEncoding.EncodeToString([]byte(`{"header":"string","body":"a"}`))
+	if _, err := sqliteDB.ExecContext(ctx, "INSERT INTO legacy_table (s) VALUES (?)", legacy); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sqliteDB.ExecContext(ctx, "PRAGMA user_version = 0"); err != nil {
+		t.Fatal(err)
+	}
+
+	readOnlyDB, err := sql.Open("zetasqlite", "file:"+path+"?_zetasqlite_read_only=true")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer readOnlyDB.Close()
+	if _, err := readOnlyDB.QueryContext(ctx, "SELECT s FROM legacy_table"); err == nil || !strings.Contains(err.Error(), "legacy value encoding") {
+		t.Fatalf("expected legacy value encoding error but got %v", err)
+	}
+
+	migratedDB, err := sql.Open("zetasqlite", "file:"+path+"?cache=private")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer migratedDB.Close()
+	var count int64
+	if err := migratedDB.QueryRowContext(ctx, "SELECT COUNT(DISTINCT s) FROM legacy_table").Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Fatalf("expected the legacy value to be migrated but got %d distinct values", count)
+	}
+	var version int64
+	if err := sqliteDB.QueryRowContext(ctx, "PRAGMA user_version").Scan(&version); err != nil {
+		t.Fatal(err)
+	}
+	if version == 0 {
+		t.Fatal("expected user_version to be set after the migration")
+	}
+}
@@ -0,0 +1,129 @@
+package zetasqlite
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ReadOnlyDSNParam is the DSN parameter to open the connections in read-only mode ( e.g. `file:fixture.db?_zetasqlite_read_only=true` ).
+// See (*ZetaSQLiteConn).SetReadOnlyMode for the statements rejected in read-only mode.
+const ReadOnlyDSNParam = "_zetasqlite_read_only"
+
+// ResultCacheDSNParam is the DSN parameter to enable the query result cache ( e.g. `file:fixture.db?_zetasqlite_result_cache=true` ).
+// See (*ZetaSQLiteConn).SetResultCacheMode for the queries that use the cache.
+const ResultCacheDSNParam = "_zetasqlite_result_cache"
+
+// dsnOptions is the options of zetasqlite specified by the DSN parameters.
+type dsnOptions struct {
+	// dsn is the DSN without the parameters of zetasqlite, which is passed to the SQLite driver.
+	dsn              string
+	attachedDatasets []*AttachedDataset
+	readOnly         bool
+	resultCache      bool
+}
+
+// parseDSN extracts the parameters of zetasqlite from the DSN.
+// The other parameters are passed to the SQLite driver as they are written, without re-encoding them.
+// The database file is opened in WAL journal mode only if the journal mode isn't specified by the DSN,
+// so that the readers see the consistent snapshot while another connection writes ( see (*ZetaSQLiteConn).BeginTx ).
+func parseDSN(dsn string) (*dsnOptions, error) {
+	base, query, _ := strings.Cut(dsn, "?")
+	params, err := url.ParseQuery(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse DSN parameters: %w", err)
+	}
+	useWAL := needsWALJournalMode(base, params)
+	if !hasZetaSQLiteDSNParam(params) && !useWAL {
+		return &dsnOptions{dsn: dsn}, nil
+	}
+	datasets, err := parseAttachedDatasets(params)
+	if err != nil {
+		return nil, err
+	}
+	opts := &dsnOptions{attachedDatasets: datasets}
+	if value := params.Get(ReadOnlyDSNParam); value != "" {
+		readOnly, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s parameter %q: %w", ReadOnlyDSNParam, value, err)
+		}
+		opts.readOnly = readOnly
+	}
+	if value := params.Get(ResultCacheDSNParam); value != "" {
+		resultCache, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s parameter %q: %w", ResultCacheDSNParam, value, err)
+		}
+		opts.resultCache = resultCache
+	}
+	query = removeZetaSQLiteDSNParams(query)
+	if useWAL {
+		if query != "" {
+			query += "&"
+		}
+		query += "_journal_mode=WAL"
+	}
+	if query == "" {
+		opts.dsn = base
+	} else {
+		opts.dsn = fmt.Sprintf("%s?%s", base, query)
+	}
+	return opts, nil
+}
+
+// removeZetaSQLiteDSNParams removes the parameters of zetasqlite from the query of the DSN.
+// The other parameters are kept in the written order and encoding.
+func removeZetaSQLiteDSNParams(query string) string {
+	var params []string
+	for _, param := range strings.Split(query, "&") {
+		if param == "" {
+			continue
+		}
+		key, _, _ := strings.Cut(param, "=")
+		if unescaped, err := url.QueryUnescape(key); err == nil {
+			key = unescaped
+		}
+		if isZetaSQLiteDSNParam(key) {
+			continue
+		}
+		params = append(params, param)
+	}
+	return strings.Join(params, "&")
+}
+
+// needsWALJournalMode reports whether the database should be opened in WAL journal mode.
+// In-memory and read-only databases can't change the journal mode.
+func needsWALJournalMode(base string, params url.Values) bool {
+	if _, exists := params["_journal_mode"]; exists {
+		return false
+	}
+	if _, exists := params["_journal"]; exists {
+		return false
+	}
+	if base == "" || strings.Contains(base, ":memory:") {
+		return false
+	}
+	switch params.Get("mode") {
+	case "memory", "ro":
+		return false
+	}
+	return true
+}
+
+func hasZetaSQLiteDSNParam(params url.Values) bool {
+	for param := range params {
+		if isZetaSQLiteDSNParam(param) {
+			return true
+		}
+	}
+	return false
+}
+
+func isZetaSQLiteDSNParam(param string) bool {
+	switch param {
+	case AttachDSNParam, AttachReadOnlyDSNParam, ReadOnlyDSNParam, ResultCacheDSNParam:
+		return true
+	}
+	return false
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -64,6 +65,18 @@ CREATE OR REPLACE TABLE recreate_table ( a string );
 DROP TABLE recreate_table;
 CREATE TABLE recreate_table ( b string );
 INSERT recreate_table (b) VALUES ('hello');
+`,
+		},
+		{
+			name: "replace table with different columns",
+			query: `
+CREATE TABLE replaced_table ( a STRING );
+CREATE TABLE replaced_dataset.replaced_table ( x INT64 );
+CREATE OR REPLACE TABLE replaced_dataset.replaced_table ( y STRING, z INT64 );
+INSERT replaced_dataset.replaced_table (y, z) VALUES ('hello', 1);
+INSERT replaced_table (a) VALUES ('world');
+CREATE OR REPLACE TABLE replaced_table ( b INT64 );
+INSERT replaced_table (b) VALUES (1);
 `,
 		},
 		{
@@ -132,6 +145,23 @@ WHEN MATCHED THEN
 TRUNCATE TABLE tmp;
 
 COMMIT TRANSACTION;
+`,
+		},
+		{
+			name: "create temp table as select with order by and limit",
+			query: `
+CREATE TEMP TABLE _tmp_ordered AS SELECT product, quantity FROM Inventory ORDER BY quantity DESC LIMIT 3;
+CREATE TEMP TABLE _tmp_filtered AS SELECT product FROM _tmp_ordered WHERE quantity > 10;
+SELECT * FROM _tmp_filtered;
+`,
+		},
+		{
+			name: "create or replace table as select from the same table",
+			query: `
+CREATE TABLE _table_ctas AS SELECT 1 AS id;
+CREATE OR REPLACE TABLE _table_ctas AS SELECT id, 'a' AS name FROM _table_ctas;
+CREATE TABLE IF NOT EXISTS _table_ctas AS SELECT 2 AS id;
+SELECT id, name FROM _table_ctas;
 `,
 		},
 	} {
@@ -144,6 +174,233 @@ COMMIT TRANSACTION;
 	}
 }
 
+func TestCreateTableAsSelect(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("zetasqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.ExecContext(ctx, `
+CREATE TABLE _ctas_source AS SELECT * FROM UNNEST([3, 1, 2]) AS id;
+CREATE OR REPLACE TABLE _ctas_source AS SELECT id, id * 10 AS value FROM _ctas_source ORDER BY id LIMIT 2;
+`); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := db.QueryContext(ctx, "SELECT id, value FROM _ctas_source ORDER BY id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var results [][]int64
+	for rows.Next() {
+		var id, value int64
+		if err := rows.Scan(&id, &value); err != nil {
+			t.Fatal(err)
+		}
+		results = append(results, []int64{id, value})
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(results, [][]int64{{1, 10}, {2, 20}}) {
+		t.Fatalf("unexpected results %v", results)
+	}
+}
+
+func TestParametersInDDLAndDML(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("zetasqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.ExecContext(
+		ctx,
+		"CREATE TABLE _param_ctas AS SELECT id FROM UNNEST([1, 2, 3]) AS id WHERE id >= @min",
+		sql.Named("min", 2),
+	); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.ExecContext(ctx, `
+INSERT INTO _param_ctas (id) SELECT id FROM UNNEST([10, 20]) AS id WHERE id > ?;
+INSERT INTO _param_ctas (id) VALUES (?);
+`, 10, 30); err != nil {
+		t.Fatal(err)
+	}
+	stmt, err := db.PrepareContext(ctx, "CREATE TABLE _param_prepared AS SELECT id FROM UNNEST([1, 2, 3]) AS id WHERE id > ?")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stmt.Close()
+	if _, err := stmt.ExecContext(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		table    string
+		expected []int64
+	}{
+		{table: "_param_ctas", expected: []int64{2, 3, 20, 30}},
+		{table: "_param_prepared", expected: []int64{2, 3}},
+	} {
+		rows, err := db.QueryContext(ctx, fmt.Sprintf("SELECT id FROM %s ORDER BY id", test.table))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ids []int64
+		for rows.Next() {
+			var id int64
+			if err := rows.Scan(&id); err != nil {
+				t.Fatal(err)
+			}
+			ids = append(ids, id)
+		}
+		if err := rows.Err(); err != nil {
+			t.Fatal(err)
+		}
+		rows.Close()
+		if !reflect.DeepEqual(ids, test.expected) {
+			t.Fatalf("unexpected ids of %s: %v", test.table, ids)
+		}
+	}
+}
+
+func TestLiteralRowsUnionAll(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("zetasqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// more rows than the limit of terms in compound SELECT statement of SQLite.
+	const rowNum = 600
+	rows := make([]string, 0, rowNum)
+	for i := 0; i < rowNum; i++ {
+		if i == 0 {
+			rows = append(rows, "SELECT 0 AS id, 'name0' AS name")
+		} else {
+			rows = append(rows, fmt.Sprintf("SELECT %d, 'name%d'", i, i))
+		}
+	}
+	query := fmt.Sprintf(
+		"WITH fixture AS (%s) SELECT COUNT(*), SUM(id), MAX(name) FROM fixture",
+		strings.Join(rows, " UNION ALL "),
+	)
+	var (
+		count int64
+		sum   int64
+		name  string
+	)
+	if err := db.QueryRowContext(ctx, query).Scan(&count, &sum, &name); err != nil {
+		t.Fatal(err)
+	}
+	if count != rowNum || sum != rowNum*(rowNum-1)/2 || name != "name99" {
+		t.Fatalf("unexpected result: count = %d, sum = %d, name = %s", count, sum, name)
+	}
+}
+
+func TestExceedSQLiteLimits(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("zetasqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if _, err := db.ExecContext(ctx, "CREATE TABLE t (id INT64); INSERT t (id) VALUES (1)"); err != nil {
+		t.Fatal(err)
+	}
+	t.Run("compound select", func(t *testing.T) {
+		// more terms than the limit of compound SELECT statement of SQLite.
+		const termNum = 1200
+		terms := make([]string, 0, termNum)
+		for i := 0; i < termNum; i++ {
+			terms = append(terms, fmt.Sprintf("SELECT id + %d AS id FROM t", i))
+		}
+		query := fmt.Sprintf(
+			"SELECT COUNT(*), SUM(id) FROM (%s)",
+			strings.Join(terms, " UNION ALL "),
+		)
+		var count, sum int64
+		if err := db.QueryRowContext(ctx, query).Scan(&count, &sum); err != nil {
+			t.Fatal(err)
+		}
+		if count != termNum || sum != termNum*(termNum+1)/2 {
+			t.Fatalf("unexpected result: count = %d, sum = %d", count, sum)
+		}
+	})
+	t.Run("in list", func(t *testing.T) {
+		// more items than the limit of function arguments of SQLite.
+		const itemNum = 300
+		items := make([]string, 0, itemNum)
+		for i := 0; i < itemNum; i++ {
+			items = append(items, fmt.Sprint(i*2))
+		}
+		list := strings.Join(items, ",")
+		query := fmt.Sprintf(
+			"SELECT id * 500 IN (%[1]s), id * 501 IN (%[1]s), id * 501 IN (%[1]s, NULL) FROM t",
+			list,
+		)
+		var (
+			found    bool
+			notFound bool
+			null     sql.NullBool
+		)
+		if err := db.QueryRowContext(ctx, query).Scan(&found, &notFound, &null); err != nil {
+			t.Fatal(err)
+		}
+		if !found || notFound || null.Valid {
+			t.Fatalf("unexpected result: found = %v, notFound = %v, null = %v", found, notFound, null)
+		}
+	})
+}
+
+func TestNameCaseSensitivity(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("zetasqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.ExecContext(ctx, `
+CREATE TABLE Sales.Users (Id INT64, Name STRING);
+INSERT INTO sales.Users (id, name) VALUES (1, 'alice');
+CREATE FUNCTION AddOne(x INT64) AS (x + 1);
+`); err != nil {
+		t.Fatal(err)
+	}
+	var (
+		id   int64
+		name string
+	)
+	if err := db.QueryRowContext(ctx, "SELECT addone(ID), NAME FROM SALES.Users").Scan(&id, &name); err != nil {
+		t.Fatal(err)
+	}
+	if id != 2 || name != "alice" {
+		t.Fatalf("unexpected result: id = %d, name = %s", id, name)
+	}
+	for _, query := range []string{
+		"SELECT * FROM Sales.users",
+		"INSERT INTO Sales.USERS (id, name) VALUES (2, 'bob')",
+		"DROP TABLE Sales.users",
+	} {
+		if _, err := db.ExecContext(ctx, query); err == nil || !strings.Contains(err.Error(), "Table not found") {
+			t.Fatalf("expected table not found error for %q but got %v", query, err)
+		}
+	}
+	if _, err := db.ExecContext(ctx, `
+CREATE OR REPLACE TABLE SALES.Users (id INT64);
+DROP FUNCTION addone;
+DROP TABLE sales.Users;
+`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.ExecContext(ctx, "SELECT * FROM Sales.Users"); err == nil {
+		t.Fatal("expected error for dropped table")
+	}
+}
+
 func TestNestedStructFieldAccess(t *testing.T) {
 	now := time.Now()
 	ctx := context.Background()
@@ -232,6 +489,176 @@ func TestCreateTempTable(t *testing.T) {
 	}
 }
 
+func TestDMLOverView(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("zetasqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.ExecContext(ctx, `
+CREATE TABLE view_source (id INT64, name STRING);
+INSERT INTO view_source (id, name) VALUES (1, 'alice');
+CREATE VIEW source_view AS SELECT id, name FROM view_source;
+`); err != nil {
+		t.Fatal(err)
+	}
+	for _, query := range []string{
+		`INSERT INTO source_view (id, name) VALUES (2, 'bob')`,
+		`UPDATE source_view SET name = 'bob' WHERE id = 1`,
+		`DELETE FROM source_view WHERE id = 1`,
+		`TRUNCATE TABLE source_view`,
+		`MERGE source_view T USING view_source S ON T.id = S.id WHEN MATCHED THEN DELETE`,
+	} {
+		_, err := db.ExecContext(ctx, query)
+		if err == nil {
+			t.Fatalf("expected error for %s", query)
+		}
+		if !strings.Contains(err.Error(), "Cannot modify view source_view") {
+			t.Fatalf("unexpected error for %s: %v", query, err)
+		}
+	}
+	var count int64
+	if err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM source_view`).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Fatalf("the view must not be modified: expected 1 row but got %d", count)
+	}
+}
+
+func TestTempFunctionVisibility(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("zetasqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	conn1, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn1.Close()
+	conn2, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn2.Close()
+
+	stmt, err := conn1.PrepareContext(ctx, `CREATE TEMP FUNCTION session_func(x INT64) AS (x * 2)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := stmt.Close(); err != nil {
+		t.Fatal(err)
+	}
+	var v int64
+	if err := conn1.QueryRowContext(ctx, `SELECT session_func(2)`).Scan(&v); err != nil {
+		t.Fatal(err)
+	}
+	if v != 4 {
+		t.Fatalf("unexpected result %d", v)
+	}
+	if err := conn2.QueryRowContext(ctx, `SELECT session_func(2)`).Scan(&v); err == nil {
+		t.Fatal("TEMP function must not be visible from the other connection")
+	}
+	if _, err := conn2.ExecContext(ctx, `
+CREATE TEMP FUNCTION session_func(x INT64) AS (x * 3);
+SELECT session_func(2);
+`); err != nil {
+		t.Fatal(err)
+	}
+	if err := conn1.QueryRowContext(ctx, `SELECT session_func(2)`).Scan(&v); err != nil {
+		t.Fatal(err)
+	}
+	if v != 4 {
+		t.Fatalf("TEMP function of the other connection must not replace the function: got %d", v)
+	}
+	if err := conn2.QueryRowContext(ctx, `SELECT session_func(2)`).Scan(&v); err == nil {
+		t.Fatal("TEMP function created in the script must be removed at the end of the script")
+	}
+}
+
+func TestUnsupportedStatement(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("zetasqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.ExecContext(ctx, `CREATE TABLE lock_target (id INT64)`); err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		query       string
+		expectedErr string
+	}{
+		{
+			query:       `SELECT id FROM lock_target FOR UPDATE`,
+			expectedErr: "locking clause ( FOR UPDATE / FOR SHARE ) is not supported",
+		},
+		{
+			query:       `SELECT id FROM lock_target WHERE id = 1 FOR SHARE`,
+			expectedErr: "locking clause ( FOR UPDATE / FOR SHARE ) is not supported",
+		},
+	} {
+		_, err := db.ExecContext(ctx, test.query)
+		if err == nil {
+			t.Fatalf("expected error for %s", test.query)
+		}
+		if !strings.Contains(err.Error(), test.expectedErr) {
+			t.Fatalf("unexpected error for %s: %v", test.query, err)
+		}
+	}
+}
+
+func TestErrorHintOfOtherStatement(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("zetasqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	for _, test := range []struct {
+		name  string
+		query string
+		hint  string
+	}{
+		{
+			name: "locking clause in the other statement",
+			query: `
+SELECT 'SELECT 1 FOR UPDATE';
+SELECT 1 FOR`,
+			hint: "locking clause ( FOR UPDATE / FOR SHARE ) is not supported",
+		},
+		{
+			name: "aggregate filter clause in the other statement",
+			query: `
+SELECT 'COUNT(*) FILTER (WHERE x > 1)';
+SELECT 1 +`,
+			hint: "aggregate FILTER (WHERE ...) clause is not supported",
+		},
+		{
+			name:  "json type in the message of the other error",
+			query: `SELECT CAST('contains expression of type JSON' AS INT64)`,
+			hint:  "UNNEST of JSON value is not supported",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := db.ExecContext(ctx, test.query)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if strings.Contains(err.Error(), test.hint) {
+				t.Fatalf("unexpected hint for the query that doesn't use the syntax: %v", err)
+			}
+		})
+	}
+}
+
 func TestWildcardTable(t *testing.T) {
 	ctx := context.Background()
 	db, err := sql.Open("zetasqlite", ":memory:")
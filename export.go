@@ -0,0 +1,36 @@
+package zetasqlite
+
+import (
+	"context"
+	"io"
+
+	internal "github.com/goccy/go-zetasqlite/internal"
+)
+
+type ExportFormat = internal.ExportFormat
+
+const (
+	// ExportFormatCSV writes a header line followed by one CSV record per row.
+	// Columns of ARRAY or STRUCT type cannot be exported in this format.
+	ExportFormatCSV = internal.ExportFormatCSV
+
+	// ExportFormatNDJSON writes one JSON object per line.
+	// Like BigQuery, INT64 values are written as JSON strings to preserve their precision.
+	ExportFormatNDJSON = internal.ExportFormatNDJSON
+)
+
+// QueryToWriter executes a query and streams the results to w in the specified format.
+// Values are formatted the same way BigQuery formats exported data
+// ( e.g. TIMESTAMP values are written as `2006-01-02 15:04:05.999999 UTC` and BYTES values are base64 encoded ).
+func (c *ZetaSQLiteConn) QueryToWriter(ctx context.Context, query string, format ExportFormat, w io.Writer, args ...interface{}) error {
+	driverRows, err := c.QueryContext(ctx, query, namedValuesFromArgs(args))
+	if err != nil {
+		return err
+	}
+	rows, _ := driverRows.(*internal.Rows)
+	if rows == nil {
+		return nil
+	}
+	defer rows.Close()
+	return rows.Export(w, format)
+}
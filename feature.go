@@ -0,0 +1,62 @@
+package zetasqlite
+
+import (
+	internal "github.com/goccy/go-zetasqlite/internal"
+)
+
+// Feature is the SQL feature of BigQuery whose support can be queried by SupportsFeature ( e.g. FeaturePivot ).
+type Feature = internal.Feature
+
+const (
+	FeatureAnalyticFunctions         = internal.FeatureAnalyticFunctions
+	FeatureQualify                   = internal.FeatureQualify
+	FeaturePivot                     = internal.FeaturePivot
+	FeatureUnpivot                   = internal.FeatureUnpivot
+	FeatureRecursiveCTE              = internal.FeatureRecursiveCTE
+	FeatureTablesample               = internal.FeatureTablesample
+	FeatureGroupByRollup             = internal.FeatureGroupByRollup
+	FeatureInlineLambda              = internal.FeatureInlineLambda
+	FeatureNumeric                   = internal.FeatureNumeric
+	FeatureBigNumeric                = internal.FeatureBigNumeric
+	FeatureJSON                      = internal.FeatureJSON
+	FeatureInterval                  = internal.FeatureInterval
+	FeatureGeography                 = internal.FeatureGeography
+	FeatureCollation                 = internal.FeatureCollation
+	FeatureParameterizedTypes        = internal.FeatureParameterizedTypes
+	FeatureColumnDefaultValue        = internal.FeatureColumnDefaultValue
+	FeaturePrimaryKey                = internal.FeaturePrimaryKey
+	FeatureForeignKey                = internal.FeatureForeignKey
+	FeaturePartitionBy               = internal.FeaturePartitionBy
+	FeatureClusterBy                 = internal.FeatureClusterBy
+	FeatureCreateTableAsSelect       = internal.FeatureCreateTableAsSelect
+	FeatureCreateView                = internal.FeatureCreateView
+	FeatureCreateMaterializedView    = internal.FeatureCreateMaterializedView
+	FeatureCreateFunction            = internal.FeatureCreateFunction
+	FeatureCreateTableFunction       = internal.FeatureCreateTableFunction
+	FeatureCreateProcedure           = internal.FeatureCreateProcedure
+	FeatureCreateSchema              = internal.FeatureCreateSchema
+	FeatureAlterTableSetOptions      = internal.FeatureAlterTableSetOptions
+	FeatureAlterColumnSetDataType    = internal.FeatureAlterColumnSetDataType
+	FeatureRenameTable               = internal.FeatureRenameTable
+	FeatureMerge                     = internal.FeatureMerge
+	FeatureTruncateTable             = internal.FeatureTruncateTable
+	FeatureExportData                = internal.FeatureExportData
+	FeatureMultiStatementTransaction = internal.FeatureMultiStatementTransaction
+)
+
+// SupportsFeature reports whether the feature is supported by zetasqlite with the default language features.
+// This is useful for the test frameworks to skip the queries using unsupported features instead of matching the error messages.
+// The unknown feature is always unsupported.
+func SupportsFeature(feature Feature) bool {
+	return internal.SupportsFeature(feature)
+}
+
+// SupportedFeatures returns all features supported by zetasqlite with the default language features.
+func SupportedFeatures() []Feature {
+	return internal.SupportedFeatures()
+}
+
+// SupportsFeature reports whether the feature is supported with the language features enabled for this connection.
+func (c *ZetaSQLiteConn) SupportsFeature(feature Feature) bool {
+	return c.analyzer.SupportsFeature(feature)
+}
@@ -0,0 +1,31 @@
+package zetasqlite
+
+import (
+	"context"
+
+	internal "github.com/goccy/go-zetasqlite/internal"
+)
+
+type (
+	FunctionKind      = internal.FunctionKind
+	FunctionInfo      = internal.FunctionInfo
+	FunctionSignature = internal.FunctionSignature
+	FunctionArgument  = internal.FunctionArgument
+	Determinism       = internal.Determinism
+)
+
+const (
+	FunctionKindBuiltin = internal.FunctionKindBuiltin
+	FunctionKindUDF     = internal.FunctionKindUDF
+	FunctionKindTempUDF = internal.FunctionKindTempUDF
+
+	DeterminismDeterministic    = internal.DeterminismDeterministic
+	DeterminismNotDeterministic = internal.DeterminismNotDeterministic
+)
+
+// Functions returns all functions that can be called from the connection.
+// The result includes ZetaSQL builtin functions, user defined functions and temporary functions,
+// and each function has the list of its signatures.
+func (c *ZetaSQLiteConn) Functions(ctx context.Context) ([]*FunctionInfo, error) {
+	return c.analyzer.Functions(ctx, internal.NewConn(c.conn, c.tx))
+}
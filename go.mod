@@ -18,16 +18,17 @@ require github.com/google/uuid v1.3.0
 require gonum.org/v1/gonum v0.11.0
 
 require (
+	cloud.google.com/go v0.110.0
 	cloud.google.com/go/bigquery v1.51.0
 	github.com/DataDog/go-hll v1.0.2
 	github.com/dop251/goja v0.0.0-20221118162653-d4bf6fde1b86
 	github.com/spaolacci/murmur3 v0.0.0-20180118202830-f09979ecbc72
 	golang.org/x/net v0.8.0
 	golang.org/x/text v0.8.0
+	google.golang.org/api v0.114.0
 )
 
 require (
-	cloud.google.com/go v0.110.0 // indirect
 	cloud.google.com/go/compute v1.19.0 // indirect
 	cloud.google.com/go/compute/metadata v0.2.3 // indirect
 	cloud.google.com/go/iam v0.13.0 // indirect
@@ -57,7 +58,6 @@ require (
 	golang.org/x/sys v0.6.0 // indirect
 	golang.org/x/tools v0.6.0 // indirect
 	golang.org/x/xerrors v0.0.0-20220907171357-04be3eba64a2 // indirect
-	google.golang.org/api v0.114.0 // indirect
 	google.golang.org/appengine v1.6.7 // indirect
 	google.golang.org/genproto v0.0.0-20230330154414-c0448cd141ea // indirect
 	google.golang.org/grpc v1.54.0 // indirect
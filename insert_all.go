@@ -0,0 +1,33 @@
+package zetasqlite
+
+import (
+	"context"
+	"fmt"
+
+	internal "github.com/goccy/go-zetasqlite/internal"
+)
+
+type (
+	InsertAllRow    = internal.InsertAllRow
+	InsertAllResult = internal.InsertAllResult
+)
+
+// InsertAll inserts the rows to the table like tabledata.insertAll ( the streaming insert ) of BigQuery.
+// The table name can be specified with dots ( e.g. `project.dataset.table` ), and the name path of the connection is applied.
+// The rows are deduplicated by InsertID against the recent insertIds of the table,
+// so the retries of the streaming insert can be tested deterministically.
+func (c *ZetaSQLiteConn) InsertAll(ctx context.Context, table string, rows []*InsertAllRow) (*InsertAllResult, error) {
+	result, err := c.analyzer.InsertAll(ctx, internal.NewConn(c.conn, c.tx), table, rows, c.insertIDWindowSize)
+	if err != nil {
+		return nil, fmt.Errorf("zetasqlite: %w", err)
+	}
+	return result, nil
+}
+
+// SetInsertIDWindowSize specifies the number of recent insertIds kept for each table to deduplicate the rows of InsertAll.
+// The rows with the insertId older than the window are inserted again ( default 1000 ).
+// If size is zero, no insertIds are kept after InsertAll returns, so only the rows of the same call are deduplicated.
+// The negative size makes InsertAll fail.
+func (c *ZetaSQLiteConn) SetInsertIDWindowSize(size int) {
+	c.insertIDWindowSize = size
+}
@@ -0,0 +1,84 @@
+package internal
+
+import (
+	"fmt"
+	"sync"
+)
+
+// valueRetainingAggregateFuncMap is a set of aggregate functions that keep all input values until the aggregation is done.
+// The memory used by the other functions doesn't grow with the number of rows, so only their DISTINCT keys are tracked.
+var valueRetainingAggregateFuncMap = map[string]struct{}{
+	"array":            {},
+	"array_agg":        {},
+	"array_concat_agg": {},
+	"string_agg":       {},
+	"approx_quantiles": {},
+	"approx_top_count": {},
+	"approx_top_sum":   {},
+}
+
+// aggregationMemory tracks the estimated memory used by the aggregation states of a SQLite connection.
+// If the limit is exceeded, the aggregation fails early with the current usage instead of exhausting the memory.
+type aggregationMemory struct {
+	mu    sync.Mutex
+	limit int64
+	used  int64
+}
+
+func (m *aggregationMemory) setLimit(limit int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.limit = limit
+}
+
+func (m *aggregationMemory) reserve(size int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.limit > 0 && m.used+size > m.limit {
+		return fmt.Errorf(
+			"aggregation memory limit exceeded: aggregations need %d bytes but the limit is %d bytes. reduce the number of groups or the values aggregated by ARRAY_AGG, STRING_AGG or window functions",
+			m.used+size, m.limit,
+		)
+	}
+	m.used += size
+	return nil
+}
+
+func (m *aggregationMemory) release(size int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.used -= size
+}
+
+// estimateValueSize returns the approximate number of bytes used by the value.
+func estimateValueSize(v Value) int64 {
+	const headerSize = 16
+	switch vv := v.(type) {
+	case nil:
+		return headerSize
+	case StringValue:
+		return headerSize + int64(len(vv))
+	case BytesValue:
+		return headerSize + int64(len(vv))
+	case JsonValue:
+		return headerSize + int64(len(vv))
+	case *ArrayValue:
+		size := int64(headerSize)
+		for _, elem := range vv.values {
+			size += estimateValueSize(elem)
+		}
+		return size
+	case *StructValue:
+		size := int64(headerSize)
+		for i, field := range vv.values {
+			size += estimateValueSize(field)
+			if i < len(vv.keys) {
+				size += int64(len(vv.keys[i]))
+			}
+		}
+		return size
+	case *NumericValue:
+		return headerSize + 48
+	}
+	return headerSize
+}
@@ -0,0 +1,260 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	ast "github.com/goccy/go-zetasql/resolved_ast"
+	"github.com/goccy/go-zetasql/types"
+)
+
+// columnTypeChange is the new type of the column specified by ALTER COLUMN SET DATA TYPE.
+type columnTypeChange struct {
+	column     string
+	isIfExists bool
+	typ        types.Type
+	typeParams *TypeParameters
+}
+
+// assignableColumnTypeKindMap is the set of the types that the values of the column can be converted to by ALTER COLUMN SET DATA TYPE.
+var assignableColumnTypeKindMap = map[types.TypeKind]map[types.TypeKind]struct{}{
+	types.INT64: {
+		types.NUMERIC:     {},
+		types.BIG_NUMERIC: {},
+		types.DOUBLE:      {},
+	},
+	types.NUMERIC: {
+		types.BIG_NUMERIC: {},
+		types.DOUBLE:      {},
+	},
+}
+
+// isAssignableColumnType reports whether the column can be changed to the new type without losing the stored values.
+// Like BigQuery, INT64 can be changed to NUMERIC, BIGNUMERIC or FLOAT64, and NUMERIC can be changed to BIGNUMERIC or FLOAT64.
+// The type parameters can be changed only to the less restrictive ones ( e.g. STRING(10) to STRING(20) or STRING ).
+func isAssignableColumnType(col *ColumnSpec, change *columnTypeChange) (bool, error) {
+	current, err := col.Type.ToZetaSQLType()
+	if err != nil {
+		return false, err
+	}
+	if !current.Equals(change.typ) {
+		if _, exists := assignableColumnTypeKindMap[current.Kind()][change.typ.Kind()]; !exists {
+			return false, nil
+		}
+		return change.typeParams == nil, nil
+	}
+	if change.typeParams == nil {
+		return true, nil
+	}
+	if col.TypeParams == nil {
+		return false, nil
+	}
+	switch current.Kind() {
+	case types.STRING, types.BYTES:
+		return change.typeParams.MaxLength >= col.TypeParams.MaxLength, nil
+	case types.NUMERIC, types.BIG_NUMERIC:
+		currentIntegerDigits := col.TypeParams.Precision - col.TypeParams.Scale
+		newIntegerDigits := change.typeParams.Precision - change.typeParams.Scale
+		return change.typeParams.Scale >= col.TypeParams.Scale && newIntegerDigits >= currentIntegerDigits, nil
+	}
+	return false, nil
+}
+
+// applyColumnTypeChanges changes the types of the columns of the spec, and returns the new types of the changed columns by name.
+func applyColumnTypeChanges(spec *TableSpec, changes []*columnTypeChange) (map[string]types.Type, error) {
+	changedTypeMap := map[string]types.Type{}
+	for _, change := range changes {
+		col := spec.Column(change.column)
+		if col == nil {
+			if change.isIfExists {
+				continue
+			}
+			return nil, fmt.Errorf("Column not found: %s", change.column)
+		}
+		assignable, err := isAssignableColumnType(col, change)
+		if err != nil {
+			return nil, err
+		}
+		if !assignable {
+			currentType, err := col.FormatType()
+			if err != nil {
+				return nil, err
+			}
+			updatedType, err := formatColumnType(change.typ, change.typeParams)
+			if err != nil {
+				return nil, err
+			}
+			return nil, fmt.Errorf(
+				"ALTER TABLE ALTER COLUMN SET DATA TYPE requires that the existing column type (%s) is assignable to the new type (%s)",
+				currentType, updatedType,
+			)
+		}
+		if col.Type.Kind != int(change.typ.Kind()) {
+			changedTypeMap[col.Name] = change.typ
+		}
+		col.Type = newType(change.typ)
+		col.TypeParams = change.typeParams
+	}
+	return changedTypeMap, nil
+}
+
+// FormatType returns the type name of the column including the type parameters ( e.g. STRING(10) ).
+func (s *ColumnSpec) FormatType() (string, error) {
+	typ, err := s.Type.ToZetaSQLType()
+	if err != nil {
+		return "", err
+	}
+	return formatColumnType(typ, s.TypeParams)
+}
+
+func formatColumnType(typ types.Type, params *TypeParameters) (string, error) {
+	name := typ.TypeName(types.ProductExternal)
+	if params == nil {
+		return name, nil
+	}
+	switch typ.Kind() {
+	case types.STRING, types.BYTES:
+		return fmt.Sprintf("%s(%d)", name, params.MaxLength), nil
+	}
+	return fmt.Sprintf("%s(%d, %d)", name, params.Precision, params.Scale), nil
+}
+
+// convertColumnTypes rebuilds the SQLite table of the spec with the updated spec, and converts the values of the changed columns to the new types.
+// SQLite can't change the type of the column, and the values stored by the type affinity of the old column type are converted implicitly
+// ( e.g. FLOAT64 1.0 is stored as INTEGER 1 in the INT64 column ), so the values are copied to the staging table created with the new types.
+// All changes are made in the savepoint, so the table is never left converted partially.
+func convertColumnTypes(ctx context.Context, conn *Conn, spec, updated *TableSpec, changedTypeMap map[string]types.Type) error {
+	if _, err := conn.ExecContext(ctx, "SAVEPOINT zetasqlite_convert_column_types"); err != nil {
+		return fmt.Errorf("failed to begin converting column types: %w", err)
+	}
+	if err := convertColumnTypesInSavepoint(ctx, conn, spec, updated, changedTypeMap); err != nil {
+		_, _ = conn.ExecContext(ctx, "ROLLBACK TO zetasqlite_convert_column_types")
+		_, _ = conn.ExecContext(ctx, "RELEASE zetasqlite_convert_column_types")
+		return err
+	}
+	if _, err := conn.ExecContext(ctx, "RELEASE zetasqlite_convert_column_types"); err != nil {
+		return fmt.Errorf("failed to finish converting column types: %w", err)
+	}
+	return nil
+}
+
+func convertColumnTypesInSavepoint(ctx context.Context, conn *Conn, spec, updated *TableSpec, changedTypeMap map[string]types.Type) error {
+	tableName := spec.TableName()
+	staging := updated.clone()
+	staging.NamePath = []string{fmt.Sprintf("zetasqlite_staging_%s", tableName)}
+	staging.CreateMode = ast.CreateDefaultMode
+	if _, err := conn.ExecContext(ctx, fmt.Sprintf("DROP TABLE IF EXISTS %s", staging.QualifiedTableName())); err != nil {
+		return err
+	}
+	if _, err := conn.ExecContext(ctx, staging.SQLiteSchema()); err != nil {
+		return fmt.Errorf("failed to create staging table: %w", err)
+	}
+	indexes, err := tableIndexSchemas(ctx, conn, spec)
+	if err != nil {
+		return err
+	}
+	columnNames := make([]string, 0, len(spec.Columns))
+	placeholders := make([]string, 0, len(spec.Columns))
+	for _, col := range spec.Columns {
+		columnNames = append(columnNames, fmt.Sprintf("`%s`", col.Name))
+		placeholders = append(placeholders, "?")
+	}
+	rows, err := conn.QueryContext(
+		ctx,
+		fmt.Sprintf("SELECT %s FROM %s", strings.Join(columnNames, ","), spec.QualifiedTableName()),
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	// collect all rows before inserting them, because the connection is used by the rows until they are closed.
+	var insertArgs [][]interface{}
+	for rows.Next() {
+		values := make([]interface{}, len(spec.Columns))
+		scanArgs := make([]interface{}, 0, len(values))
+		for i := range values {
+			scanArgs = append(scanArgs, &values[i])
+		}
+		if err := rows.Scan(scanArgs...); err != nil {
+			return err
+		}
+		for i, col := range spec.Columns {
+			typ, exists := changedTypeMap[col.Name]
+			if !exists {
+				continue
+			}
+			decoded, err := DecodeValue(values[i])
+			if err != nil {
+				return err
+			}
+			casted, err := CastValue(typ, decoded)
+			if err != nil {
+				return fmt.Errorf("failed to convert the value of column %s: %w", col.Name, err)
+			}
+			encoded, err := EncodeValue(casted)
+			if err != nil {
+				return err
+			}
+			values[i] = encoded
+		}
+		insertArgs = append(insertArgs, values)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if err := rows.Close(); err != nil {
+		return err
+	}
+	insertQuery := fmt.Sprintf(
+		"INSERT INTO %s (%s) VALUES (%s)",
+		staging.QualifiedTableName(), strings.Join(columnNames, ","), strings.Join(placeholders, ","),
+	)
+	for _, args := range insertArgs {
+		if _, err := conn.ExecContext(ctx, insertQuery, args...); err != nil {
+			return err
+		}
+	}
+	if _, err := conn.ExecContext(ctx, fmt.Sprintf("DROP TABLE %s", spec.QualifiedTableName())); err != nil {
+		return err
+	}
+	if _, err := conn.ExecContext(
+		ctx,
+		fmt.Sprintf("ALTER TABLE %s RENAME TO `%s`", staging.QualifiedTableName(), tableName),
+	); err != nil {
+		return err
+	}
+	for _, index := range indexes {
+		if _, err := conn.ExecContext(ctx, index); err != nil {
+			return fmt.Errorf("failed to recreate index: %w", err)
+		}
+	}
+	return nil
+}
+
+// tableIndexSchemas returns the CREATE INDEX statements of the indexes on the table.
+// The indexes are dropped with the table, so they are recreated after the table is rebuilt.
+func tableIndexSchemas(ctx context.Context, conn *Conn, spec *TableSpec) ([]string, error) {
+	rows, err := conn.QueryContext(
+		ctx,
+		fmt.Sprintf("SELECT sql FROM %s WHERE type = 'index' AND tbl_name = ? AND sql IS NOT NULL", qualifiedName(spec.Schema, "sqlite_master")),
+		spec.TableName(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var indexes []string
+	for rows.Next() {
+		var index string
+		if err := rows.Scan(&index); err != nil {
+			return nil, err
+		}
+		indexes = append(indexes, index)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return indexes, nil
+}
@@ -4,7 +4,10 @@ import (
 	"context"
 	"database/sql/driver"
 	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/goccy/go-zetasql"
 	parsed_ast "github.com/goccy/go-zetasql/ast"
@@ -12,12 +15,26 @@ import (
 	"github.com/goccy/go-zetasql/types"
 )
 
+// DefaultMaxRecursiveIterations is the default maximum number of iterations of the recursive WITH entries like BigQuery.
+const DefaultMaxRecursiveIterations = 500
+
 type Analyzer struct {
-	namePath        *NamePath
-	isAutoIndexMode bool
-	isExplainMode   bool
-	catalog         *Catalog
-	opt             *zetasql.AnalyzerOptions
+	namePath                         *NamePath
+	isAutoIndexMode                  bool
+	isExplainMode                    bool
+	isStrictMode                     bool
+	isReadOnlyMode                   bool
+	isResultCacheMode                bool
+	isSubqueryDecorrelationDisabled  bool
+	isRowNumberDeduplicationDisabled bool
+	maxRecursiveIterations           int
+	queryLabels                      map[string]string
+	defaultParams                    []*defaultParameter
+	macros                           map[string]MacroFunc
+	templatedFunctionBodyCache       map[string]*templatedFunctionBody
+	catalog                          *Catalog
+	session                          *sessionCatalog
+	opt                              *zetasql.AnalyzerOptions
 }
 
 func NewAnalyzer(catalog *Catalog) (*Analyzer, error) {
@@ -26,75 +43,80 @@ func NewAnalyzer(catalog *Catalog) (*Analyzer, error) {
 		return nil, err
 	}
 	return &Analyzer{
-		catalog:  catalog,
-		opt:      opt,
-		namePath: &NamePath{},
+		catalog:                catalog,
+		session:                newSessionCatalog(catalog),
+		opt:                    opt,
+		namePath:               &NamePath{},
+		maxRecursiveIterations: DefaultMaxRecursiveIterations,
 	}, nil
 }
 
+// Close removes the TEMP functions created by the connection.
+func (a *Analyzer) Close() {
+	a.session.close()
+}
+
+// defaultLanguageFeatures is the language features enabled for the analyzer by default.
+// SupportsFeature reports the supported features from this list, so the features implemented newly must be added here.
+var defaultLanguageFeatures = []zetasql.LanguageFeature{
+	zetasql.FeatureAnalyticFunctions,
+	zetasql.FeatureNamedArguments,
+	zetasql.FeatureNumericType,
+	zetasql.FeatureBignumericType,
+	zetasql.FeatureV13DecimalAlias,
+	zetasql.FeatureCreateTableNotNull,
+	zetasql.FeatureCreateTablePartitionBy,
+	zetasql.FeatureCreateTableClusterBy,
+	zetasql.FeatureUnenforcedPrimaryKeys,
+	zetasql.FeatureForeignKeys,
+	zetasql.FeatureAlterColumnSetDataType,
+	zetasql.FeatureParameterizedTypes,
+	zetasql.FeatureTablesample,
+	zetasql.FeatureTimestampNanos,
+	zetasql.FeatureV11HavingInAggregate,
+	zetasql.FeatureV11NullHandlingModifierInAggregate,
+	zetasql.FeatureV11NullHandlingModifierInAnalytic,
+	zetasql.FeatureV11OrderByCollate,
+	zetasql.FeatureV11SelectStarExceptReplace,
+	zetasql.FeatureV12SafeFunctionCall,
+	zetasql.FeatureJsonType,
+	zetasql.FeatureJsonArrayFunctions,
+	zetasql.FeatureJsonStrictNumberParsing,
+	zetasql.FeatureV13IsDistinct,
+	zetasql.FeatureV13FormatInCast,
+	zetasql.FeatureV13DateArithmetics,
+	zetasql.FeatureV11OrderByInAggregate,
+	zetasql.FeatureV11LimitInAggregate,
+	zetasql.FeatureV13DateTimeConstructors,
+	zetasql.FeatureV13ExtendedDateTimeSignatures,
+	zetasql.FeatureV12CivilTime,
+	zetasql.FeatureV12GroupByStruct,
+	zetasql.FeatureV12GroupByArray,
+	zetasql.FeatureV12WeekWithWeekday,
+	zetasql.FeatureIntervalType,
+	zetasql.FeatureGroupByRollup,
+	zetasql.FeatureV13NullsFirstLastInOrderBy,
+	zetasql.FeatureV13Qualify,
+	zetasql.FeatureV13AllowDashesInTableName,
+	zetasql.FeatureGeography,
+	zetasql.FeatureV13ExtendedGeographyParsers,
+	zetasql.FeatureTemplateFunctions,
+	zetasql.FeatureV11WithOnSubquery,
+	zetasql.FeatureV13WithRecursive,
+	zetasql.FeatureV13Pivot,
+	zetasql.FeatureV13Unpivot,
+	zetasql.FeatureCreateTableAsSelectColumnList,
+	zetasql.FeatureV13ColumnDefaultValue,
+	zetasql.FeatureV13AnnotationFramework,
+	zetasql.FeatureV13CollationSupport,
+}
+
 func newAnalyzerOptions() (*zetasql.AnalyzerOptions, error) {
 	langOpt := zetasql.NewLanguageOptions()
 	langOpt.SetNameResolutionMode(zetasql.NameResolutionDefault)
 	langOpt.SetProductMode(types.ProductInternal)
-	langOpt.SetEnabledLanguageFeatures([]zetasql.LanguageFeature{
-		zetasql.FeatureAnalyticFunctions,
-		zetasql.FeatureNamedArguments,
-		zetasql.FeatureNumericType,
-		zetasql.FeatureBignumericType,
-		zetasql.FeatureV13DecimalAlias,
-		zetasql.FeatureCreateTableNotNull,
-		zetasql.FeatureParameterizedTypes,
-		zetasql.FeatureTablesample,
-		zetasql.FeatureTimestampNanos,
-		zetasql.FeatureV11HavingInAggregate,
-		zetasql.FeatureV11NullHandlingModifierInAggregate,
-		zetasql.FeatureV11NullHandlingModifierInAnalytic,
-		zetasql.FeatureV11OrderByCollate,
-		zetasql.FeatureV11SelectStarExceptReplace,
-		zetasql.FeatureV12SafeFunctionCall,
-		zetasql.FeatureJsonType,
-		zetasql.FeatureJsonArrayFunctions,
-		zetasql.FeatureJsonStrictNumberParsing,
-		zetasql.FeatureV13IsDistinct,
-		zetasql.FeatureV13FormatInCast,
-		zetasql.FeatureV13DateArithmetics,
-		zetasql.FeatureV11OrderByInAggregate,
-		zetasql.FeatureV11LimitInAggregate,
-		zetasql.FeatureV13DateTimeConstructors,
-		zetasql.FeatureV13ExtendedDateTimeSignatures,
-		zetasql.FeatureV12CivilTime,
-		zetasql.FeatureV12WeekWithWeekday,
-		zetasql.FeatureIntervalType,
-		zetasql.FeatureGroupByRollup,
-		zetasql.FeatureV13NullsFirstLastInOrderBy,
-		zetasql.FeatureV13Qualify,
-		zetasql.FeatureV13AllowDashesInTableName,
-		zetasql.FeatureGeography,
-		zetasql.FeatureV13ExtendedGeographyParsers,
-		zetasql.FeatureTemplateFunctions,
-		zetasql.FeatureV11WithOnSubquery,
-		zetasql.FeatureV13Pivot,
-		zetasql.FeatureV13Unpivot,
-		zetasql.FeatureCreateTableAsSelectColumnList,
-	})
-	langOpt.SetSupportedStatementKinds([]ast.Kind{
-		ast.BeginStmt,
-		ast.CommitStmt,
-		ast.MergeStmt,
-		ast.QueryStmt,
-		ast.InsertStmt,
-		ast.UpdateStmt,
-		ast.DeleteStmt,
-		ast.DropStmt,
-		ast.TruncateStmt,
-		ast.CreateTableStmt,
-		ast.CreateTableAsSelectStmt,
-		ast.CreateProcedureStmt,
-		ast.CreateFunctionStmt,
-		ast.CreateTableFunctionStmt,
-		ast.CreateViewStmt,
-		ast.DropFunctionStmt,
-	})
+	langOpt.SetEnabledLanguageFeatures(defaultLanguageFeatures)
+	langOpt.SetSupportedStatementKinds(supportedStatementKinds)
 	// Enable QUALIFY without WHERE
 	// https://github.com/google/zetasql/issues/124
 	if err := langOpt.EnableReservableKeyword("QUALIFY", true); err != nil {
@@ -107,6 +129,31 @@ func newAnalyzerOptions() (*zetasql.AnalyzerOptions, error) {
 	return opt, nil
 }
 
+// supportedStatementKinds is the allowlist of statements that can be executed.
+// The analyzer rejects the other statements with "Statement not supported" error,
+// so they never reach SQLite. Every kind in this list must be handled by newStmtAction.
+var supportedStatementKinds = []ast.Kind{
+	ast.BeginStmt,
+	ast.CommitStmt,
+	ast.MergeStmt,
+	ast.QueryStmt,
+	ast.InsertStmt,
+	ast.UpdateStmt,
+	ast.DeleteStmt,
+	ast.DropStmt,
+	ast.TruncateStmt,
+	ast.CreateTableStmt,
+	ast.CreateTableAsSelectStmt,
+	ast.CreateFunctionStmt,
+	ast.CreateViewStmt,
+	ast.CreateSchemaStmt,
+	ast.DropFunctionStmt,
+	ast.DescribeStmt,
+	ast.AlterTableStmt,
+	ast.AlterTableSetOptionsStmt,
+	ast.RenameStmt,
+}
+
 func (a *Analyzer) SetAutoIndexMode(enabled bool) {
 	a.isAutoIndexMode = enabled
 }
@@ -115,6 +162,73 @@ func (a *Analyzer) SetExplainMode(enabled bool) {
 	a.isExplainMode = enabled
 }
 
+func (a *Analyzer) SetStrictMode(enabled bool) {
+	a.isStrictMode = enabled
+}
+
+// SetReadOnlyMode rejects the statements that modify the database at analysis time.
+func (a *Analyzer) SetReadOnlyMode(enabled bool) {
+	a.isReadOnlyMode = enabled
+}
+
+// SetResultCacheMode enables the cache of the query results shared by the connections of the same database.
+func (a *Analyzer) SetResultCacheMode(enabled bool) {
+	a.isResultCacheMode = enabled
+}
+
+// SetSubqueryDecorrelation enables the rewriting of correlated EXISTS and IN subqueries to uncorrelated subqueries ( enabled by default ).
+func (a *Analyzer) SetSubqueryDecorrelation(enabled bool) {
+	a.isSubqueryDecorrelationDisabled = !enabled
+}
+
+// SetRowNumberDeduplication enables the rewriting of QUALIFY ROW_NUMBER() OVER (PARTITION BY ... ORDER BY ...) = 1 to the group-wise MAX or MIN ( enabled by default ).
+func (a *Analyzer) SetRowNumberDeduplication(enabled bool) {
+	a.isRowNumberDeduplicationDisabled = !enabled
+}
+
+// SetMaxRecursiveIterations specifies the maximum number of iterations of the recursive WITH entries ( default 500 ).
+// If it's 0, the number of iterations is not limited.
+func (a *Analyzer) SetMaxRecursiveIterations(num int) {
+	a.maxRecursiveIterations = num
+}
+
+// LanguageFeatures returns the enabled language features.
+func (a *Analyzer) LanguageFeatures() []zetasql.LanguageFeature {
+	return a.opt.Language().EnabledLanguageFeatures()
+}
+
+// SetLanguageFeatures replaces the enabled language features.
+func (a *Analyzer) SetLanguageFeatures(features []zetasql.LanguageFeature) {
+	lang := a.opt.Language()
+	lang.SetEnabledLanguageFeatures(features)
+	a.opt.SetLanguage(lang)
+}
+
+// EnableLanguageFeatures enables the specified language features in addition to the enabled features.
+func (a *Analyzer) EnableLanguageFeatures(features ...zetasql.LanguageFeature) {
+	lang := a.opt.Language()
+	for _, feature := range features {
+		lang.EnableLanguageFeature(feature)
+	}
+	a.opt.SetLanguage(lang)
+}
+
+// DisableLanguageFeatures disables the specified language features.
+func (a *Analyzer) DisableLanguageFeatures(features ...zetasql.LanguageFeature) {
+	disabledFeatureMap := map[zetasql.LanguageFeature]struct{}{}
+	for _, feature := range features {
+		disabledFeatureMap[feature] = struct{}{}
+	}
+	enabledFeatures := []zetasql.LanguageFeature{}
+	for _, feature := range a.LanguageFeatures() {
+		if _, exists := disabledFeatureMap[feature]; exists {
+			continue
+		}
+		enabledFeatures = append(enabledFeatures, feature)
+	}
+	a.SetLanguageFeatures(enabledFeatures)
+}
+
 func (a *Analyzer) NamePath() []string {
 	return a.namePath.path
 }
@@ -135,14 +249,47 @@ func (a *Analyzer) AddNamePath(path string) error {
 	return a.namePath.addPath(path)
 }
 
+// Tables returns the specs of tables and views registered to the catalog sorted by name.
+func (a *Analyzer) Tables(ctx context.Context, conn *Conn) ([]*TableSpec, error) {
+	if err := a.catalog.Sync(ctx, conn); err != nil {
+		return nil, fmt.Errorf("failed to sync catalog: %w", err)
+	}
+	return a.catalog.tableSpecs(), nil
+}
+
+// UserDefinedFunctions returns the specs of user defined functions that can be called from the session sorted by name.
+func (a *Analyzer) UserDefinedFunctions(ctx context.Context, conn *Conn) ([]*FunctionSpec, error) {
+	if err := a.catalog.Sync(ctx, conn); err != nil {
+		return nil, fmt.Errorf("failed to sync catalog: %w", err)
+	}
+	return a.session.functionSpecs(), nil
+}
+
 func (a *Analyzer) parseScript(query string) ([]parsed_ast.StatementNode, error) {
 	loc := zetasql.NewParseResumeLocation(query)
-	var stmts []parsed_ast.StatementNode
+	var (
+		stmts []parsed_ast.StatementNode
+		// stmtStart is the byte offset of the statement to be parsed next.
+		stmtStart int
+	)
 	for {
 		stmt, isEnd, err := zetasql.ParseNextScriptStatement(loc, a.opt.ParserOptions())
 		if err != nil {
+			if isWindowFrameExclusionError(err) {
+				return nil, fmt.Errorf("window frame exclusion ( EXCLUDE CURRENT ROW / GROUP / TIES / NO OTHERS ) is not supported: %w", err)
+			}
+			failedStmt := failedStatementText(query, stmtStart, err)
+			if isLockingClauseError(err) && lockingClausePattern.MatchString(failedStmt) {
+				return nil, fmt.Errorf("locking clause ( FOR UPDATE / FOR SHARE ) is not supported. BigQuery doesn't lock the rows read by queries: %w", err)
+			}
+			if aggregateFilterClausePattern.MatchString(failedStmt) {
+				return nil, fmt.Errorf("aggregate FILTER (WHERE ...) clause is not supported. use COUNTIF or IF expression in the aggregate arguments instead: %w", err)
+			}
 			return nil, fmt.Errorf("failed to parse statement: %w", err)
 		}
+		if end := stmt.ParseLocationRange().End().ByteOffset(); end > stmtStart && end <= len(query) {
+			stmtStart = end
+		}
 		switch s := stmt.(type) {
 		case *parsed_ast.BeginEndBlockNode:
 			stmts = append(stmts, s.StatementList()...)
@@ -156,6 +303,58 @@ func (a *Analyzer) parseScript(query string) ([]parsed_ast.StatementNode, error)
 	return stmts, nil
 }
 
+// parseErrorLinePattern matches the line number of the location reported by the parser like [at 2:10].
+var parseErrorLinePattern = regexp.MustCompile(`\[at (\d+):\d+\]`)
+
+// failedStatementText returns the text of the statement that the parser failed to parse.
+// The text starts at the end of the last parsed statement and ends at the end of the line where the error is reported,
+// so the statements after the failed one are not included.
+func failedStatementText(query string, stmtStart int, err error) string {
+	text := query[stmtStart:]
+	matched := parseErrorLinePattern.FindStringSubmatch(err.Error())
+	if len(matched) != 2 {
+		return text
+	}
+	line, convErr := strconv.Atoi(matched[1])
+	if convErr != nil {
+		return text
+	}
+	// the line number is counted from the beginning of the query.
+	end := 0
+	for i := 0; i < line; i++ {
+		idx := strings.IndexByte(query[end:], '\n')
+		if idx < 0 {
+			end = len(query)
+			break
+		}
+		end += idx + 1
+	}
+	if end <= stmtStart {
+		return text
+	}
+	return query[stmtStart:end]
+}
+
+// isWindowFrameExclusionError reports whether the parser rejected the EXCLUDE clause of a window frame.
+// ZetaSQL has no grammar for frame exclusion, so this is the only place it can be detected.
+func isWindowFrameExclusionError(err error) bool {
+	return strings.Contains(err.Error(), "got keyword EXCLUDE")
+}
+
+// isLockingClauseError reports whether the parser rejected the FOR keyword at the end of a query.
+func isLockingClauseError(err error) bool {
+	return strings.Contains(err.Error(), "got keyword FOR")
+}
+
+// lockingClausePattern matches the row locking clauses like SELECT ... FOR UPDATE.
+// FOR SYSTEM_TIME AS OF is valid BigQuery syntax, so only the locking modes are matched.
+var lockingClausePattern = regexp.MustCompile(`(?i)\bFOR\s+(UPDATE|NO\s+KEY\s+UPDATE|SHARE|KEY\s+SHARE)\b`)
+
+// aggregateFilterClausePattern matches the FILTER (WHERE ...) clause of ANSI SQL aggregates like COUNT(*) FILTER (WHERE cond).
+// The parser reads FILTER as a column alias and fails on the following parenthesis,
+// so the query text is inspected only to give a better error message.
+var aggregateFilterClausePattern = regexp.MustCompile(`(?i)\)\s*FILTER\s*\(\s*WHERE\b`)
+
 func (a *Analyzer) getParameterMode(stmt parsed_ast.StatementNode) (zetasql.ParameterMode, error) {
 	var (
 		enabledNamedParameter      bool
@@ -182,41 +381,90 @@ func (a *Analyzer) getParameterMode(stmt parsed_ast.StatementNode) (zetasql.Para
 	return zetasql.ParameterNamed, nil
 }
 
+// canPruneUnusedColumns reports whether the columns not referenced by the statement can be removed
+// from the column list of the table scans, so that they are not read and encoded by the formatted query.
+// DML statements and MERGE refer to the whole rows of the target table, so pruning is applied to queries only.
+// TABLESAMPLE samples the rows by the fingerprint of all columns, so the pruned columns would change the result.
+func canPruneUnusedColumns(stmt parsed_ast.StatementNode) bool {
+	if _, ok := stmt.(*parsed_ast.QueryStatementNode); !ok {
+		return false
+	}
+	var existsSampleClause bool
+	_ = parsed_ast.Walk(stmt, func(node parsed_ast.Node) error {
+		if _, ok := node.(*parsed_ast.SampleClauseNode); ok {
+			existsSampleClause = true
+		}
+		return nil
+	})
+	return !existsSampleClause
+}
+
 type StmtActionFunc func() (StmtAction, error)
 
 func (a *Analyzer) Analyze(ctx context.Context, conn *Conn, query string, args []driver.NamedValue) ([]StmtActionFunc, error) {
 	if err := a.catalog.Sync(ctx, conn); err != nil {
 		return nil, fmt.Errorf("failed to sync catalog: %w", err)
 	}
+	query, err := a.expandMacros(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to expand macros: %w", err)
+	}
+	query, err = rewriteArrayZip(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rewrite ARRAY_ZIP: %w", err)
+	}
 	stmts, err := a.parseScript(query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse statements: %w", err)
 	}
+	if !isPreparedStatement(ctx) {
+		args = a.argsWithDefaultParameters(args)
+	}
 	funcMap := map[string]*FunctionSpec{}
-	for _, spec := range a.catalog.getFunctions(a.namePath) {
-		funcMap[spec.FuncName()] = spec
+	for _, spec := range a.session.getFunctions(a.namePath) {
+		funcMap[nameKey(spec.FuncName())] = spec
 	}
 	actionFuncs := make([]StmtActionFunc, 0, len(stmts))
 	for _, stmt := range stmts {
 		stmt := stmt
 		actionFuncs = append(actionFuncs, func() (StmtAction, error) {
+			conn.setQueryLabels(a.mergedQueryLabels(ctx))
+			if s, ok := stmt.(*parsed_ast.SystemVariableAssignmentNode); ok {
+				return a.newSystemVariableAssignmentStmtAction(s)
+			}
 			mode, err := a.getParameterMode(stmt)
 			if err != nil {
 				return nil, err
 			}
 			a.opt.SetParameterMode(mode)
+			a.opt.SetPruneUnusedColumns(canPruneUnusedColumns(stmt))
+			if err := a.declareDefaultParameters(mode); err != nil {
+				return nil, err
+			}
+			if err := a.declareParameterTypes(mode, args); err != nil {
+				return nil, err
+			}
 			out, err := zetasql.AnalyzeStatementFromParserAST(
 				query,
 				stmt,
-				a.catalog,
+				a.session,
 				a.opt,
 			)
 			if err != nil {
-				return nil, fmt.Errorf("failed to analyze: %w", err)
+				return nil, analyzeError(err)
 			}
 			stmtNode := out.Statement()
-			ctx = a.context(ctx, funcMap, stmtNode, stmt)
-			action, err := a.newStmtAction(ctx, query, args, stmtNode)
+			if err := a.checkReadOnly(stmtNode); err != nil {
+				return nil, err
+			}
+			stmtCtx := ctx
+			if CurrentTime(stmtCtx) == nil && !isPreparedStatement(stmtCtx) {
+				// CURRENT_* functions return the time at the start of the statement
+				// no matter how many times they are called in the statement.
+				stmtCtx = WithCurrentTime(stmtCtx, time.Now())
+			}
+			stmtCtx = a.context(stmtCtx, funcMap, stmtNode, stmt)
+			action, err := a.newStmtAction(stmtCtx, query, args, stmtNode)
 			if err != nil {
 				return nil, err
 			}
@@ -229,6 +477,58 @@ func (a *Analyzer) Analyze(ctx context.Context, conn *Conn, query string, args [
 	return actionFuncs, nil
 }
 
+// modifyingStatementNameMap is the names of the statements that modify the database.
+var modifyingStatementNameMap = map[ast.Kind]string{
+	ast.CreateTableStmt:          "CREATE TABLE",
+	ast.CreateTableAsSelectStmt:  "CREATE TABLE AS SELECT",
+	ast.CreateViewStmt:           "CREATE VIEW",
+	ast.CreateFunctionStmt:       "CREATE FUNCTION",
+	ast.CreateSchemaStmt:         "CREATE SCHEMA",
+	ast.DropStmt:                 "DROP",
+	ast.DropFunctionStmt:         "DROP FUNCTION",
+	ast.InsertStmt:               "INSERT",
+	ast.UpdateStmt:               "UPDATE",
+	ast.DeleteStmt:               "DELETE",
+	ast.MergeStmt:                "MERGE",
+	ast.TruncateStmt:             "TRUNCATE TABLE",
+	ast.AlterTableStmt:           "ALTER TABLE",
+	ast.AlterTableSetOptionsStmt: "ALTER TABLE",
+	ast.RenameStmt:               "RENAME",
+}
+
+// checkReadOnly returns an error if the statement modifies the database in read-only mode.
+// TEMP functions are allowed, because they are created only in the session of the connection.
+func (a *Analyzer) checkReadOnly(node ast.StatementNode) error {
+	if !a.isReadOnlyMode {
+		return nil
+	}
+	name, modifying := modifyingStatementNameMap[node.Kind()]
+	if !modifying {
+		return nil
+	}
+	if stmt, ok := node.(*ast.CreateFunctionStmtNode); ok && stmt.CreateScope() == ast.CreateScopeTemp {
+		return nil
+	}
+	return fmt.Errorf("%s statement is not allowed in read-only mode", name)
+}
+
+// unnestJSONErrorPattern matches the error of the analyzer for UNNEST with the argument of JSON type.
+var unnestJSONErrorPattern = regexp.MustCompile(`Values referenced in UNNEST must be arrays\. UNNEST contains expression of type JSON\b`)
+
+// analyzeError adds the hint to rewrite the query to the error of the queries that ZetaSQL analyzer rejects.
+func analyzeError(err error) error {
+	msg := err.Error()
+	switch {
+	case unnestJSONErrorPattern.MatchString(msg):
+		// ZetaSQL analyzer accepts only ARRAY type as the argument of UNNEST.
+		return fmt.Errorf("UNNEST of JSON value is not supported. use UNNEST(JSON_QUERY_ARRAY(json_expr)) instead: %w", err)
+	case strings.Contains(msg, "Subquery of type IN must have only one output column"):
+		// STRUCT values are compared by position of fields, so the columns can be compared as a STRUCT.
+		return fmt.Errorf("multi-column IN subquery is not supported. use (a, b) IN (SELECT AS STRUCT x, y FROM ...) instead: %w", err)
+	}
+	return fmt.Errorf("failed to analyze: %w", err)
+}
+
 func (a *Analyzer) context(
 	ctx context.Context,
 	funcMap map[string]*FunctionSpec,
@@ -239,13 +539,12 @@ func (a *Analyzer) context(
 	ctx = withColumnRefMap(ctx, map[string]string{})
 	ctx = withTableNameToColumnListMap(ctx, map[string][]*ast.Column{})
 	ctx = withFuncMap(ctx, funcMap)
-	ctx = withAnalyticOrderColumnNames(ctx, &analyticOrderColumnNames{})
 	ctx = withNodeMap(ctx, zetasql.NewNodeMap(stmtNode, stmt))
 	return ctx
 }
 
 func (a *Analyzer) analyzeTemplatedFunctionWithRuntimeArgument(ctx context.Context, query string) (*FunctionSpec, error) {
-	out, err := zetasql.AnalyzeStatement(query, a.catalog, a.opt)
+	out, err := zetasql.AnalyzeStatement(query, a.session, a.opt)
 	if err != nil {
 		return nil, fmt.Errorf("failed to analyze: %w", err)
 	}
@@ -273,6 +572,8 @@ func (a *Analyzer) newStmtAction(ctx context.Context, query string, args []drive
 	case ast.CreateViewStmt:
 		ctx = withUseColumnID(ctx)
 		return a.newCreateViewStmtAction(ctx, query, args, node.(*ast.CreateViewStmtNode))
+	case ast.CreateSchemaStmt:
+		return a.newCreateSchemaStmtAction(ctx, query, args, node.(*ast.CreateSchemaStmtNode))
 	case ast.DropStmt:
 		return a.newDropStmtAction(ctx, query, args, node.(*ast.DropStmtNode))
 	case ast.DropFunctionStmt:
@@ -281,6 +582,14 @@ func (a *Analyzer) newStmtAction(ctx context.Context, query string, args []drive
 		return a.newDMLStmtAction(ctx, query, args, node)
 	case ast.TruncateStmt:
 		return a.newTruncateStmtAction(ctx, query, args, node.(*ast.TruncateStmtNode))
+	case ast.AlterTableStmt:
+		return a.newAlterTableStmtAction(ctx, query, args, node.(*ast.AlterTableStmtNode))
+	case ast.AlterTableSetOptionsStmt:
+		return a.newAlterTableSetOptionsStmtAction(ctx, query, args, node.(*ast.AlterTableSetOptionsStmtNode))
+	case ast.RenameStmt:
+		return a.newRenameStmtAction(ctx, query, args, node.(*ast.RenameStmtNode))
+	case ast.DescribeStmt:
+		return a.newDescribeStmtAction(ctx, query, args, node.(*ast.DescribeStmtNode))
 	case ast.MergeStmt:
 		ctx = withUseColumnID(ctx)
 		return a.newMergeStmtAction(ctx, query, args, node.(*ast.MergeStmtNode))
@@ -292,11 +601,21 @@ func (a *Analyzer) newStmtAction(ctx context.Context, query string, args []drive
 	case ast.CommitStmt:
 		return a.newCommitStmtAction(ctx, query, args, node)
 	}
-	return nil, fmt.Errorf("unsupported stmt %s", node.DebugString())
+	return nil, fmt.Errorf("Statement not supported: %s", node.DebugString())
 }
 
-func (a *Analyzer) newCreateTableStmtAction(_ context.Context, query string, args []driver.NamedValue, node *ast.CreateTableStmtNode) (*CreateTableStmtAction, error) {
-	spec := newTableSpec(a.namePath, node)
+func (a *Analyzer) newCreateTableStmtAction(ctx context.Context, query string, args []driver.NamedValue, node *ast.CreateTableStmtNode) (*CreateTableStmtAction, error) {
+	spec, err := newTableSpec(ctx, a.catalog, a.namePath, node)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.catalog.setTableSchema(spec); err != nil {
+		return nil, err
+	}
+	options, err := newExprTableOptions(ctx, node.OptionList())
+	if err != nil {
+		return nil, err
+	}
 	params := getParamsFromNode(node)
 	queryArgs, err := getArgsFromParams(args, params)
 	if err != nil {
@@ -305,18 +624,38 @@ func (a *Analyzer) newCreateTableStmtAction(_ context.Context, query string, arg
 	return &CreateTableStmtAction{
 		query:           query,
 		spec:            spec,
+		options:         options,
+		params:          params,
 		args:            queryArgs,
 		catalog:         a.catalog,
 		isAutoIndexMode: a.isAutoIndexMode,
 	}, nil
 }
 
+func (a *Analyzer) newCreateSchemaStmtAction(_ context.Context, _ string, _ []driver.NamedValue, node *ast.CreateSchemaStmtNode) (*CreateSchemaStmtAction, error) {
+	return &CreateSchemaStmtAction{
+		spec:       newDatasetSpec(a.namePath, node),
+		createMode: node.CreateMode(),
+		catalog:    a.catalog,
+	}, nil
+}
+
 func (a *Analyzer) newCreateTableAsSelectStmtAction(ctx context.Context, _ string, args []driver.NamedValue, node *ast.CreateTableAsSelectStmtNode) (*CreateTableStmtAction, error) {
 	query, err := newNode(node.Query()).FormatSQL(ctx)
 	if err != nil {
 		return nil, err
 	}
-	spec := newTableAsSelectSpec(a.namePath, query, node)
+	spec, err := newTableAsSelectSpec(ctx, a.catalog, a.namePath, query, node)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.catalog.setTableSchema(spec); err != nil {
+		return nil, err
+	}
+	options, err := newExprTableOptions(ctx, node.OptionList())
+	if err != nil {
+		return nil, err
+	}
 	params := getParamsFromNode(node)
 	queryArgs, err := getArgsFromParams(args, params)
 	if err != nil {
@@ -325,6 +664,8 @@ func (a *Analyzer) newCreateTableAsSelectStmtAction(ctx context.Context, _ strin
 	return &CreateTableStmtAction{
 		query:           query,
 		spec:            spec,
+		options:         options,
+		params:          params,
 		args:            queryArgs,
 		catalog:         a.catalog,
 		isAutoIndexMode: a.isAutoIndexMode,
@@ -332,6 +673,13 @@ func (a *Analyzer) newCreateTableAsSelectStmtAction(ctx context.Context, _ strin
 }
 
 func (a *Analyzer) newCreateFunctionStmtAction(ctx context.Context, query string, _ []driver.NamedValue, node *ast.CreateFunctionStmtNode) (*CreateFunctionStmtAction, error) {
+	// the function being created is the caller of the functions in the body, so that the body calling itself is rejected
+	// ( e.g. CREATE OR REPLACE FUNCTION f(x INT64) AS (f(x)) refers to the function f that already exists ).
+	call, err := newFunctionCall(ctx, formatPath(a.namePath.mergePath(node.NamePath())))
+	if err != nil {
+		return nil, err
+	}
+	ctx = withFunctionCall(ctx, call)
 	var spec *FunctionSpec
 	if a.resultTypeIsTemplatedType(node.Signature()) {
 		realStmts, err := a.inferTemplatedTypeByRealType(query, node)
@@ -351,9 +699,10 @@ func (a *Analyzer) newCreateFunctionStmtAction(ctx context.Context, query string
 		spec = funcSpec
 	}
 	return &CreateFunctionStmtAction{
-		spec:    spec,
-		catalog: a.catalog,
-		funcMap: funcMapFromContext(ctx),
+		spec:       spec,
+		createMode: node.CreateMode(),
+		catalog:    a.session,
+		funcMap:    funcMapFromContext(ctx),
 	}, nil
 }
 
@@ -363,6 +712,9 @@ func (a *Analyzer) newCreateViewStmtAction(ctx context.Context, _ string, _ []dr
 		return nil, err
 	}
 	spec := newTableAsViewSpec(a.namePath, query, node)
+	if err := a.catalog.setTableSchema(spec); err != nil {
+		return nil, err
+	}
 	return &CreateViewStmtAction{
 		query:   query,
 		spec:    spec,
@@ -387,7 +739,7 @@ var inferTypes = []string{
 func (a *Analyzer) inferTemplatedTypeByRealType(query string, node *ast.CreateFunctionStmtNode) ([]*ast.CreateFunctionStmtNode, error) {
 	var stmts []*ast.CreateFunctionStmtNode
 	for _, typ := range inferTypes {
-		if out, err := zetasql.AnalyzeStatement(a.buildScalarTypeFuncFromTemplatedFunc(node, typ), a.catalog, a.opt); err == nil {
+		if out, err := zetasql.AnalyzeStatement(a.buildScalarTypeFuncFromTemplatedFunc(node, typ), a.session, a.opt); err == nil {
 			stmts = append(stmts, out.Statement().(*ast.CreateFunctionStmtNode))
 		}
 	}
@@ -395,7 +747,7 @@ func (a *Analyzer) inferTemplatedTypeByRealType(query string, node *ast.CreateFu
 		return stmts, nil
 	}
 	for _, typ := range inferTypes {
-		if out, err := zetasql.AnalyzeStatement(a.buildArrayTypeFuncFromTemplatedFunc(node, typ), a.catalog, a.opt); err == nil {
+		if out, err := zetasql.AnalyzeStatement(a.buildArrayTypeFuncFromTemplatedFunc(node, typ), a.session, a.opt); err == nil {
 			stmts = append(stmts, out.Statement().(*ast.CreateFunctionStmtNode))
 		}
 	}
@@ -454,11 +806,17 @@ func (a *Analyzer) newDropStmtAction(ctx context.Context, query string, args []d
 	}
 	objectType := node.ObjectType()
 	name := a.namePath.format(node.NamePath())
+	if objectType == "TABLE" || objectType == "VIEW" {
+		// SQLite drops the table whose name differs only in case, so it must be checked before dropping.
+		if err := a.catalog.checkTableName(name); err != nil {
+			return nil, err
+		}
+	}
 	return &DropStmtAction{
 		name:           name,
 		objectType:     objectType,
 		funcMap:        funcMapFromContext(ctx),
-		catalog:        a.catalog,
+		catalog:        a.session,
 		query:          query,
 		formattedQuery: formattedQuery,
 		args:           queryArgs,
@@ -476,13 +834,54 @@ func (a *Analyzer) newDropFunctionStmtAction(ctx context.Context, query string,
 		name:       name,
 		objectType: "FUNCTION",
 		funcMap:    funcMapFromContext(ctx),
-		catalog:    a.catalog,
+		catalog:    a.session,
 		query:      query,
 		args:       queryArgs,
 	}, nil
 }
 
+// checkModifiableTable returns an error if the target table of the DML statement is a view or is referenced by the name in the different case.
+// BigQuery doesn't support DML statements over views.
+func (a *Analyzer) checkModifiableTable(ctx context.Context, scan *ast.TableScanNode) error {
+	if scan == nil {
+		return nil
+	}
+	name, err := getTableName(ctx, scan)
+	if err != nil {
+		name = scan.Table().Name()
+	}
+	if err := a.catalog.checkTableName(name); err != nil {
+		return err
+	}
+	if spec := a.catalog.tableSpec(name); spec != nil && spec.IsView {
+		return fmt.Errorf("Cannot modify view %s: DML statements over views are not supported", strings.Join(spec.NamePath, "."))
+	}
+	return nil
+}
+
 func (a *Analyzer) newDMLStmtAction(ctx context.Context, query string, args []driver.NamedValue, node ast.Node) (*DMLStmtAction, error) {
+	var targetScan *ast.TableScanNode
+	switch n := node.(type) {
+	case *ast.InsertStmtNode:
+		targetScan = n.TableScan()
+	case *ast.UpdateStmtNode:
+		targetScan = n.TableScan()
+	case *ast.DeleteStmtNode:
+		targetScan = n.TableScan()
+	}
+	if err := a.checkModifiableTable(ctx, targetScan); err != nil {
+		return nil, err
+	}
+	if err := a.checkPartitionFilter(node); err != nil {
+		return nil, err
+	}
+	// if the target table is unknown, all cached query results are discarded after the statement is executed.
+	var table string
+	if name, err := getTableName(ctx, targetScan); err == nil {
+		if spec := a.catalog.tableSpec(name); spec != nil {
+			table = spec.TableName()
+		}
+	}
 	formattedQuery, err := newNode(node).FormatSQL(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to format query %s: %w", query, err)
@@ -496,19 +895,25 @@ func (a *Analyzer) newDMLStmtAction(ctx context.Context, query string, args []dr
 		return nil, err
 	}
 	return &DMLStmtAction{
+		kind:           node.Kind(),
 		query:          query,
 		params:         params,
 		args:           queryArgs,
 		formattedQuery: formattedQuery,
+		table:          table,
 	}, nil
 }
 
 func (a *Analyzer) newQueryStmtAction(ctx context.Context, query string, args []driver.NamedValue, node *ast.QueryStmtNode) (*QueryStmtAction, error) {
+	if err := a.checkPartitionFilter(node); err != nil {
+		return nil, err
+	}
 	outputColumns := []*ColumnSpec{}
-	for _, col := range node.OutputColumnList() {
+	outputColumnNodes := node.OutputColumnList()
+	for idx, name := range outputColumnNames(outputColumnNodes) {
 		outputColumns = append(outputColumns, &ColumnSpec{
-			Name: col.Name(),
-			Type: newType(col.Column().Type()),
+			Name: name,
+			Type: newType(outputColumnNodes[idx].Column().Type()),
 		})
 	}
 	formattedQuery, err := newNode(node).FormatSQL(ctx)
@@ -523,13 +928,25 @@ func (a *Analyzer) newQueryStmtAction(ctx context.Context, query string, args []
 	if err != nil {
 		return nil, err
 	}
+	var (
+		cache       *resultCache
+		cacheTables []string
+	)
+	if a.isResultCacheMode && !a.isExplainMode {
+		if tables, cacheable := resultCacheTables(ctx, a.catalog, node, formattedQuery); cacheable {
+			cache = a.catalog.resultCache
+			cacheTables = tables
+		}
+	}
 	return &QueryStmtAction{
-		query:          query,
-		params:         params,
-		args:           queryArgs,
-		formattedQuery: formattedQuery,
-		outputColumns:  outputColumns,
-		isExplainMode:  a.isExplainMode,
+		query:             query,
+		params:            params,
+		args:              queryArgs,
+		formattedQuery:    formattedQuery,
+		outputColumns:     outputColumns,
+		isExplainMode:     a.isExplainMode,
+		resultCache:       cache,
+		resultCacheTables: cacheTables,
 	}, nil
 }
 
@@ -542,12 +959,89 @@ func (a *Analyzer) newCommitStmtAction(ctx context.Context, query string, args [
 }
 
 //nolint:unparam
-func (a *Analyzer) newTruncateStmtAction(_ context.Context, _ string, _ []driver.NamedValue, node *ast.TruncateStmtNode) (*TruncateStmtAction, error) {
+func (a *Analyzer) newTruncateStmtAction(ctx context.Context, _ string, _ []driver.NamedValue, node *ast.TruncateStmtNode) (*TruncateStmtAction, error) {
+	if err := a.checkModifiableTable(ctx, node.TableScan()); err != nil {
+		return nil, err
+	}
 	table := node.TableScan().Table().Name()
 	return &TruncateStmtAction{query: fmt.Sprintf("DELETE FROM `%s`", table)}, nil
 }
 
+func (a *Analyzer) newAlterTableStmtAction(ctx context.Context, query string, _ []driver.NamedValue, node *ast.AlterTableStmtNode) (*AlterTableStmtAction, error) {
+	action := &AlterTableStmtAction{
+		query:      query,
+		name:       a.namePath.format(node.NamePath()),
+		isIfExists: node.IsIfExists(),
+		catalog:    a.session,
+	}
+	for _, alterAction := range node.AlterActionList() {
+		switch act := alterAction.(type) {
+		case *ast.SetOptionsActionNode:
+			options, err := newTableOptions(ctx, act.OptionList())
+			if err != nil {
+				return nil, err
+			}
+			action.options = append(action.options, options...)
+		case *ast.AlterColumnOptionsActionNode:
+			options, err := newTableOptions(ctx, act.OptionList())
+			if err != nil {
+				return nil, err
+			}
+			action.columnOptions = append(action.columnOptions, &columnOptions{
+				column:     act.Column(),
+				isIfExists: act.IsIfExists(),
+				options:    options,
+			})
+		case *ast.AlterColumnSetDataTypeActionNode:
+			typeParams, err := newTypeParameters(act.UpdatedType(), act.UpdatedTypeParameters())
+			if err != nil {
+				return nil, err
+			}
+			action.columnTypes = append(action.columnTypes, &columnTypeChange{
+				column:     act.Column(),
+				isIfExists: act.IsIfExists(),
+				typ:        act.UpdatedType(),
+				typeParams: typeParams,
+			})
+		case *ast.RenameToActionNode:
+			action.renameTo = a.renamedNamePath(node.NamePath(), act.NewPath())
+		default:
+			return nil, fmt.Errorf("currently unsupported ALTER TABLE action: %s", alterAction.Kind())
+		}
+	}
+	return action, nil
+}
+
+func (a *Analyzer) newAlterTableSetOptionsStmtAction(ctx context.Context, query string, _ []driver.NamedValue, node *ast.AlterTableSetOptionsStmtNode) (*AlterTableStmtAction, error) {
+	options, err := newTableOptions(ctx, node.OptionList())
+	if err != nil {
+		return nil, err
+	}
+	return &AlterTableStmtAction{
+		query:      query,
+		name:       a.namePath.format(node.NamePath()),
+		isIfExists: node.IsIfExists(),
+		options:    options,
+		catalog:    a.session,
+	}, nil
+}
+
+func (a *Analyzer) newRenameStmtAction(_ context.Context, query string, _ []driver.NamedValue, node *ast.RenameStmtNode) (*AlterTableStmtAction, error) {
+	if !strings.EqualFold(node.ObjectType(), "TABLE") {
+		return nil, fmt.Errorf("currently unsupported RENAME %s statement", node.ObjectType())
+	}
+	return &AlterTableStmtAction{
+		query:    query,
+		name:     a.namePath.format(node.OldNamePath()),
+		renameTo: a.renamedNamePath(node.OldNamePath(), node.NewNamePath()),
+		catalog:  a.session,
+	}, nil
+}
+
 func (a *Analyzer) newMergeStmtAction(ctx context.Context, _ string, args []driver.NamedValue, node *ast.MergeStmtNode) (*MergeStmtAction, error) {
+	if err := a.checkModifiableTable(ctx, node.TableScan()); err != nil {
+		return nil, err
+	}
 	targetTable, err := newNode(node.TableScan()).FormatSQL(ctx)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,210 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/goccy/go-json"
+	ast "github.com/goccy/go-zetasql/resolved_ast"
+)
+
+// arrayFilterFuncMap is a set of comparison functions that can be evaluated while decoding the array.
+var arrayFilterFuncMap = map[string]func(...Value) (Value, error){
+	"equal":            bindEqual,
+	"not_equal":        bindNotEqual,
+	"greater":          bindGreater,
+	"greater_or_equal": bindGreaterOrEqual,
+	"less":             bindLess,
+	"less_or_equal":    bindLessOrEqual,
+}
+
+// flippedArrayFilterFuncNameMap is used when the element is specified as the right operand of the comparison.
+var flippedArrayFilterFuncNameMap = map[string]string{
+	"equal":            "equal",
+	"not_equal":        "not_equal",
+	"greater":          "less",
+	"greater_or_equal": "less_or_equal",
+	"less":             "greater",
+	"less_or_equal":    "greater_or_equal",
+}
+
+// arrayElementPredicate is the comparison between the element of the array and the value that doesn't depend on any columns.
+type arrayElementPredicate struct {
+	funcName string
+	value    ast.ExprNode
+}
+
+// splitArrayElementPredicates splits the filter expression of the array scan into the predicates that can be evaluated
+// while decoding the array and the others.
+// The predicates are pushed down only if removing the elements doesn't change the rows other than the removed elements.
+// So the outer array scans ( LEFT JOIN UNNEST ) and the array scans with offset column are not applicable.
+func splitArrayElementPredicates(ctx context.Context, scan *ast.ArrayScanNode, filter ast.ExprNode) ([]*arrayElementPredicate, []ast.ExprNode) {
+	if scan.IsOuter() || scan.ArrayOffsetColumn() != nil {
+		return nil, []ast.ExprNode{filter}
+	}
+	var (
+		predicates []*arrayElementPredicate
+		remaining  []ast.ExprNode
+	)
+	for _, cond := range conjunctionExprs(filter) {
+		predicate := arrayElementPredicateFromExpr(ctx, scan.ElementColumn(), cond)
+		if predicate == nil {
+			remaining = append(remaining, cond)
+			continue
+		}
+		predicates = append(predicates, predicate)
+	}
+	return predicates, remaining
+}
+
+func arrayElementPredicateFromExpr(ctx context.Context, element *ast.Column, expr ast.ExprNode) *arrayElementPredicate {
+	fn, ok := expr.(*ast.FunctionCallNode)
+	if !ok || fn.ErrorMode() == ast.SafeErrorMode {
+		return nil
+	}
+	name := fn.Function().FullName(false)
+	if len(name) == 0 || name[0] != '$' {
+		return nil
+	}
+	funcName := name[1:]
+	if _, exists := arrayFilterFuncMap[funcName]; !exists {
+		return nil
+	}
+	if functionCollation(ctx, fn.BaseFunctionCallNode) != "" {
+		return nil
+	}
+	args := fn.ArgumentList()
+	if len(args) != 2 {
+		return nil
+	}
+	for i, arg := range args {
+		ref, ok := arg.(*ast.ColumnRefNode)
+		if !ok || ref.Column().ColumnID() != element.ColumnID() {
+			continue
+		}
+		value := args[1-i]
+		if !isColumnIndependentExpr(value) {
+			return nil
+		}
+		if i == 1 {
+			funcName = flippedArrayFilterFuncNameMap[funcName]
+		}
+		return &arrayElementPredicate{funcName: funcName, value: value}
+	}
+	return nil
+}
+
+// isColumnIndependentExpr reports whether the value of expr is the same for all rows ( e.g. literal or query parameter ).
+// The calls of non-deterministic functions ( e.g. RAND ) and user defined functions may return a different value for each element,
+// so they are not column independent.
+func isColumnIndependentExpr(expr ast.ExprNode) bool {
+	independent := true
+	_ = ast.Walk(expr, func(n ast.Node) error {
+		switch n := n.(type) {
+		case *ast.ColumnRefNode, *ast.SubqueryExprNode:
+			independent = false
+		case *ast.FunctionCallNode:
+			fn := n.Function()
+			if !fn.IsZetaSQLBuiltin() {
+				independent = false
+				return nil
+			}
+			if _, exists := nonDeterministicFuncMap[strings.ToLower(fn.Name())]; exists {
+				independent = false
+			}
+		}
+		return nil
+	})
+	return independent
+}
+
+// formatArrayElementPredicates formats the arguments of zetasqlite_decode_array_filter.
+// Each predicate is passed as a pair of the function name and the value to compare.
+func formatArrayElementPredicates(ctx context.Context, predicates []*arrayElementPredicate) (string, error) {
+	var args string
+	for _, predicate := range predicates {
+		value, err := newNode(predicate.value).FormatSQL(ctx)
+		if err != nil {
+			return "", err
+		}
+		args += fmt.Sprintf(", '%s', %s", predicate.funcName, value)
+	}
+	return args, nil
+}
+
+// filterArrayElements returns the elements that satisfy all predicates.
+// predicates is the list of the pairs of the function name and the encoded value.
+func filterArrayElements(elements []Value, predicates []interface{}) ([]Value, error) {
+	if len(predicates)%2 != 0 {
+		return nil, fmt.Errorf("decode_array_filter: unexpected number of predicate arguments %d", len(predicates))
+	}
+	type filter struct {
+		name  string
+		fn    func(...Value) (Value, error)
+		value Value
+	}
+	filters := make([]*filter, 0, len(predicates)/2)
+	for i := 0; i < len(predicates); i += 2 {
+		name, ok := predicates[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("decode_array_filter: unexpected function name %v", predicates[i])
+		}
+		fn, exists := arrayFilterFuncMap[name]
+		if !exists {
+			return nil, fmt.Errorf("decode_array_filter: unsupported function %s", name)
+		}
+		value, err := DecodeValue(predicates[i+1])
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, &filter{name: name, fn: fn, value: value})
+	}
+	filtered := make([]Value, 0, len(elements))
+	for _, elem := range elements {
+		matched := true
+		for _, f := range filters {
+			args, err := coerceArgs(f.name, []Value{elem, f.value})
+			if err != nil {
+				return nil, err
+			}
+			ret, err := f.fn(args...)
+			if err != nil {
+				return nil, err
+			}
+			if ret == nil {
+				matched = false
+				break
+			}
+			cond, err := ret.ToBool()
+			if err != nil {
+				return nil, err
+			}
+			if !cond {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			filtered = append(filtered, elem)
+		}
+	}
+	return filtered, nil
+}
+
+// encodeArrayElements encodes the elements to the JSON array that is expanded by json_each.
+func encodeArrayElements(elements []Value) (string, error) {
+	encodedValues := make([]interface{}, 0, len(elements))
+	for _, value := range elements {
+		v, err := EncodeValue(value)
+		if err != nil {
+			return "", err
+		}
+		encodedValues = append(encodedValues, v)
+	}
+	b, err := json.Marshal(encodedValues)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
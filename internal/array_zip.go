@@ -0,0 +1,179 @@
+package internal
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const arrayZipFuncName = "array_zip"
+
+var (
+	arrayZipModeArgPattern  = regexp.MustCompile(`(?is)^mode\s*=>\s*(.+)$`)
+	arrayZipNamedArgPattern = regexp.MustCompile(`(?is)^[a-z_][a-z0-9_]*\s*=>`)
+	arrayZipAliasPattern    = regexp.MustCompile("(?is)^(.+?)\\s+AS\\s+(`[^`]+`|[a-z_][a-z0-9_]*)$")
+	arrayZipPathPattern     = regexp.MustCompile(`(?i)^[a-z_][a-z0-9_]*(\.[a-z_][a-z0-9_]*)*$`)
+)
+
+// rewriteArrayZip rewrites the ARRAY_ZIP calls in the query into the equivalent ARRAY subqueries before the query is parsed.
+// The ZetaSQL catalog bundled with go-zetasql doesn't define ARRAY_ZIP,
+// and its result type ( ARRAY<STRUCT> whose fields are named by the aliases of the arguments ) can't be declared by the function signatures of the catalog.
+// The calls in string literals, quoted identifiers and comments are not rewritten.
+func rewriteArrayZip(query string) (string, error) {
+	if !strings.Contains(strings.ToLower(query), arrayZipFuncName) {
+		return query, nil
+	}
+	var b strings.Builder
+	for i := 0; i < len(query); {
+		if end := skipQuotedOrComment(query, i); end > i {
+			b.WriteString(query[i:end])
+			i = end
+			continue
+		}
+		open := arrayZipCallStart(query, i)
+		if open < 0 {
+			b.WriteByte(query[i])
+			i++
+			continue
+		}
+		end := findCallEnd(query, open)
+		if end < 0 {
+			return "", fmt.Errorf("ARRAY_ZIP: unterminated function call: %s", query[i:])
+		}
+		args := splitCallArgs(query[open+1 : end])
+		for idx, arg := range args {
+			rewritten, err := rewriteArrayZip(arg)
+			if err != nil {
+				return "", err
+			}
+			args[idx] = rewritten
+		}
+		expr, err := formatArrayZip(args)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(expr)
+		i = end + 1
+	}
+	return b.String(), nil
+}
+
+// arrayZipCallStart returns the position of the open parenthesis if ARRAY_ZIP is called at pos, otherwise returns -1.
+func arrayZipCallStart(query string, pos int) int {
+	nameEnd := pos + len(arrayZipFuncName)
+	if nameEnd > len(query) || !strings.EqualFold(query[pos:nameEnd], arrayZipFuncName) {
+		return -1
+	}
+	if pos > 0 {
+		switch c := query[pos-1]; {
+		case c == '.', c == '@', isIdentChar(c):
+			return -1
+		}
+	}
+	for i := nameEnd; i < len(query); i++ {
+		switch query[i] {
+		case ' ', '\t', '\r', '\n':
+			continue
+		case '(':
+			return i
+		}
+		return -1
+	}
+	return -1
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9'
+}
+
+// findCallEnd returns the position of the parenthesis that closes the function call opened at pos, or -1 if the call is not closed.
+func findCallEnd(query string, pos int) int {
+	var depth int
+	for i := pos; i < len(query); {
+		if end := skipQuotedOrComment(query, i); end > i {
+			i = end
+			continue
+		}
+		switch query[i] {
+		case '(', '[':
+			depth++
+		case ')', ']':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+		i++
+	}
+	return -1
+}
+
+// formatArrayZip formats ARRAY_ZIP(array [AS alias], ... [, mode => mode]) as the ARRAY subquery.
+// The fields of STRUCT are named by the aliases, or by the last name of the path expression if the alias isn't specified.
+// mode specifies how to handle the arrays of the different length.
+//   - STRICT: returns an error ( default )
+//   - TRUNCATE: drops the elements that exceed the length of the shortest array
+//   - PAD: fills the missing elements by NULL up to the length of the longest array
+//
+// If any of the arrays is NULL, NULL is returned.
+func formatArrayZip(args []string) (string, error) {
+	var (
+		arrays []string
+		fields []string
+		mode   = "'STRICT'"
+	)
+	for _, arg := range args {
+		if matched := arrayZipModeArgPattern.FindStringSubmatch(arg); matched != nil {
+			mode = matched[1]
+			continue
+		}
+		if arrayZipNamedArgPattern.MatchString(arg) {
+			return "", fmt.Errorf("ARRAY_ZIP: unsupported named argument %s", arg)
+		}
+		expr, alias := arg, ""
+		if matched := arrayZipAliasPattern.FindStringSubmatch(arg); matched != nil {
+			expr, alias = matched[1], matched[2]
+		} else if arrayZipPathPattern.MatchString(arg) {
+			path := strings.Split(arg, ".")
+			alias = path[len(path)-1]
+		}
+		array := fmt.Sprintf("(%s)", expr)
+		field := fmt.Sprintf("%s[SAFE_OFFSET(zetasqlite_zip_offset)]", array)
+		if alias != "" {
+			field += " AS " + alias
+		}
+		arrays = append(arrays, array)
+		fields = append(fields, field)
+	}
+	if len(arrays) < 2 || len(arrays) > 5 {
+		return "", fmt.Errorf("ARRAY_ZIP: requires 2 to 5 arrays but got %d", len(arrays))
+	}
+	var (
+		nullConds    []string
+		lengths      []string
+		equalLengths []string
+	)
+	for _, array := range arrays {
+		length := fmt.Sprintf("ARRAY_LENGTH(%s)", array)
+		nullConds = append(nullConds, fmt.Sprintf("%s IS NULL", array))
+		lengths = append(lengths, length)
+		if len(lengths) > 1 {
+			equalLengths = append(equalLengths, fmt.Sprintf("%s = %s", lengths[0], length))
+		}
+	}
+	length := fmt.Sprintf(
+		"CASE UPPER(%[1]s) WHEN 'STRICT' THEN IF(%[2]s, %[3]s, ERROR('Unequal array length in ARRAY_ZIP using STRICT mode')) "+
+			"WHEN 'TRUNCATE' THEN LEAST(%[4]s) WHEN 'PAD' THEN GREATEST(%[4]s) "+
+			"ELSE ERROR(CONCAT('Invalid mode for ARRAY_ZIP: ', IFNULL(%[1]s, 'NULL'))) END",
+		mode,
+		strings.Join(equalLengths, " AND "),
+		lengths[0],
+		strings.Join(lengths, ", "),
+	)
+	return fmt.Sprintf(
+		"IF(%s, NULL, ARRAY(SELECT AS STRUCT %s FROM UNNEST(GENERATE_ARRAY(0, %s - 1)) AS zetasqlite_zip_offset ORDER BY zetasqlite_zip_offset))",
+		strings.Join(nullConds, " OR "),
+		strings.Join(fields, ", "),
+		length,
+	), nil
+}
@@ -0,0 +1,132 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AttachedDataset is the dataset stored in the SQLite database file attached to the main database.
+// The tables of the dataset are created in the attached database and the catalog saved in it is loaded together,
+// so the large shared datasets can be mounted read-only while the other tables are written to the main database.
+type AttachedDataset struct {
+	// Name is the name of the dataset, which is also used as the schema name of the attached database.
+	Name string
+	// Path is the file name of the SQLite database ( URI filenames like file:ref.db?cache=shared are also accepted ).
+	Path string
+	// ReadOnly opens the attached database as read-only.
+	ReadOnly bool
+}
+
+// AttachQuery returns the ATTACH DATABASE statement that takes the filename of the database as the parameter.
+func (d *AttachedDataset) AttachQuery() string {
+	return fmt.Sprintf("ATTACH DATABASE ? AS `%s`", d.Name)
+}
+
+// Filename returns the filename passed to ATTACH DATABASE.
+// The read-only database is opened by the URI filename with mode=ro parameter.
+func (d *AttachedDataset) Filename() string {
+	if !d.ReadOnly {
+		return d.Path
+	}
+	path := d.Path
+	if !strings.HasPrefix(path, "file:") {
+		path = "file:" + path
+	}
+	if strings.Contains(path, "?") {
+		return path + "&mode=ro"
+	}
+	return path + "?mode=ro"
+}
+
+func (d *AttachedDataset) validate() error {
+	if d.Name == "" {
+		return fmt.Errorf("dataset name of the attached database must be specified")
+	}
+	if d.Path == "" {
+		return fmt.Errorf("database file of the attached dataset %s must be specified", d.Name)
+	}
+	switch strings.ToLower(d.Name) {
+	case "main", "temp":
+		return fmt.Errorf("%s cannot be used as the name of the attached dataset", d.Name)
+	}
+	if strings.Contains(d.Name, "`") {
+		return fmt.Errorf("invalid name of the attached dataset %s", d.Name)
+	}
+	return nil
+}
+
+// SetAttachedDatasets specifies the datasets stored in the attached databases.
+// The databases must be attached to every SQLite connection of the catalog with the same names.
+func (c *Catalog) SetAttachedDatasets(datasets []*AttachedDataset) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	datasetMap := map[string]*AttachedDataset{}
+	for _, dataset := range datasets {
+		if err := dataset.validate(); err != nil {
+			return err
+		}
+		if _, exists := datasetMap[nameKey(dataset.Name)]; exists {
+			return fmt.Errorf("dataset %s is attached more than once", dataset.Name)
+		}
+		datasetMap[nameKey(dataset.Name)] = dataset
+	}
+	c.attachedDatasets = datasets
+	c.attachedDatasetMap = datasetMap
+	return nil
+}
+
+// attachedDataset returns the attached dataset that contains the table of the name path.
+// The dataset is the element before the table name ( e.g. `dataset.table` or `project.dataset.table` ).
+func (c *Catalog) attachedDataset(namePath []string) *AttachedDataset {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(namePath) < 2 {
+		return nil
+	}
+	return c.attachedDatasetMap[nameKey(namePath[len(namePath)-2])]
+}
+
+// isReadOnlySchema reports whether the schema is the database attached as read-only.
+func (c *Catalog) isReadOnlySchema(schema string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, dataset := range c.attachedDatasets {
+		if dataset.Name == schema {
+			return dataset.ReadOnly
+		}
+	}
+	return false
+}
+
+// setTableSchema sets the schema of the attached database where the table is created.
+func (c *Catalog) setTableSchema(spec *TableSpec) error {
+	if spec.IsTemp {
+		return nil
+	}
+	dataset := c.attachedDataset(spec.NamePath)
+	if dataset == nil {
+		return nil
+	}
+	if dataset.ReadOnly {
+		return fmt.Errorf("cannot create %s in dataset %s attached as read-only", strings.Join(spec.NamePath, "."), dataset.Name)
+	}
+	spec.Schema = dataset.Name
+	return nil
+}
+
+// qualifiedName returns the name qualified by the schema of the attached database if it's specified.
+// SQLite searches the tables from the main database and the attached databases in order,
+// so only the statements creating the objects need the qualified name.
+func qualifiedName(schema, name string) string {
+	if schema == "" {
+		return fmt.Sprintf("`%s`", name)
+	}
+	return fmt.Sprintf("`%s`.`%s`", schema, name)
+}
+
+func catalogTableName(schema string) string {
+	return qualifiedName(schema, "zetasqlite_catalog")
+}
@@ -0,0 +1,100 @@
+package internal
+
+import (
+	"fmt"
+
+	"cloud.google.com/go/bigquery"
+	"github.com/goccy/go-zetasql/types"
+)
+
+// BigQuerySchema converts column specifications to the schema of cloud.google.com/go/bigquery.
+func BigQuerySchema(columns []*ColumnSpec) (bigquery.Schema, error) {
+	schema := make(bigquery.Schema, 0, len(columns))
+	for _, col := range columns {
+		field, err := col.Type.BigQueryFieldSchema(col.Name)
+		if err != nil {
+			return nil, err
+		}
+		if !field.Repeated {
+			field.Required = col.IsNotNull
+		}
+		field.Collation = col.Collation
+		if col.TypeParams != nil {
+			field.MaxLength = col.TypeParams.MaxLength
+			field.Precision = col.TypeParams.Precision
+			field.Scale = col.TypeParams.Scale
+		}
+		schema = append(schema, field)
+	}
+	return schema, nil
+}
+
+// BigQueryFieldSchema converts the type to the field schema of cloud.google.com/go/bigquery.
+// ARRAY type is represented as the repeated field of the element type.
+func (t *Type) BigQueryFieldSchema(name string) (*bigquery.FieldSchema, error) {
+	if t.IsArray() {
+		if t.ElementType.IsArray() {
+			return nil, fmt.Errorf("unsupported ARRAY of ARRAY type for BigQuery schema: %s", t.FormatType())
+		}
+		field, err := t.ElementType.BigQueryFieldSchema(name)
+		if err != nil {
+			return nil, err
+		}
+		field.Repeated = true
+		return field, nil
+	}
+	if t.IsStruct() {
+		schema := make(bigquery.Schema, 0, len(t.FieldTypes))
+		for _, fieldType := range t.FieldTypes {
+			field, err := fieldType.Type.BigQueryFieldSchema(fieldType.Name)
+			if err != nil {
+				return nil, err
+			}
+			schema = append(schema, field)
+		}
+		return &bigquery.FieldSchema{
+			Name:   name,
+			Type:   bigquery.RecordFieldType,
+			Schema: schema,
+		}, nil
+	}
+	typ, err := bigQueryFieldType(types.TypeKind(t.Kind))
+	if err != nil {
+		return nil, err
+	}
+	return &bigquery.FieldSchema{Name: name, Type: typ}, nil
+}
+
+func bigQueryFieldType(kind types.TypeKind) (bigquery.FieldType, error) {
+	switch kind {
+	case types.INT32, types.INT64, types.UINT32, types.UINT64, types.ENUM:
+		return bigquery.IntegerFieldType, nil
+	case types.BOOL:
+		return bigquery.BooleanFieldType, nil
+	case types.FLOAT, types.DOUBLE:
+		return bigquery.FloatFieldType, nil
+	case types.STRING:
+		return bigquery.StringFieldType, nil
+	case types.BYTES:
+		return bigquery.BytesFieldType, nil
+	case types.DATE:
+		return bigquery.DateFieldType, nil
+	case types.DATETIME:
+		return bigquery.DateTimeFieldType, nil
+	case types.TIME:
+		return bigquery.TimeFieldType, nil
+	case types.TIMESTAMP:
+		return bigquery.TimestampFieldType, nil
+	case types.NUMERIC:
+		return bigquery.NumericFieldType, nil
+	case types.BIG_NUMERIC:
+		return bigquery.BigNumericFieldType, nil
+	case types.GEOGRAPHY:
+		return bigquery.GeographyFieldType, nil
+	case types.JSON:
+		return bigquery.JSONFieldType, nil
+	case types.INTERVAL:
+		return bigquery.IntervalFieldType, nil
+	}
+	return "", fmt.Errorf("unsupported type kind for BigQuery schema: %v", kind)
+}
@@ -0,0 +1,279 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	parsed_ast "github.com/goccy/go-zetasql/ast"
+)
+
+// BundleFile is the .sql file of the deployment bundle.
+type BundleFile struct {
+	// Path is the slash separated path of the file relative to the bundle directory.
+	Path  string
+	Query string
+}
+
+// BundleFileResult is the result of running the file of the bundle.
+type BundleFileResult struct {
+	Path string
+	// Dependencies is the paths of the files that define the tables, views, functions or procedures referenced by the file.
+	Dependencies []string
+	// Err is the error of the file. It's nil if all statements of the file are executed successfully.
+	Err error
+}
+
+// ReadBundleFiles reads all .sql files under the directory recursively in the lexical order of their paths.
+func ReadBundleFiles(dir string) ([]*BundleFile, error) {
+	fsys := os.DirFS(dir)
+	var files []*BundleFile
+	if err := fs.WalkDir(fsys, ".", func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || !strings.EqualFold(path.Ext(p), ".sql") {
+			return nil
+		}
+		query, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+		files = append(files, &BundleFile{Path: p, Query: string(query)})
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("failed to read bundle files from %s: %w", dir, err)
+	}
+	return files, nil
+}
+
+// bundleFileNode is the file of the bundle with the names defined and referenced by its statements.
+type bundleFileNode struct {
+	file        *BundleFile
+	definitions [][]string
+	references  [][]string
+	// dependencies is the indexes of the files that must be run before the file.
+	dependencies    []int
+	dependencyPaths []string
+	err             error
+}
+
+// RunBundle runs the files of the bundle in the order of their dependencies, and returns the results in the executed order.
+// The file that creates a table, view, function or procedure is run before the files referencing it,
+// and the files that don't depend on each other are run in the order of their paths.
+// If a file fails, the files depending on it are skipped, but the other files are still run.
+func (a *Analyzer) RunBundle(ctx context.Context, files []*BundleFile, exec func(context.Context, string) error) []*BundleFileResult {
+	nodes := make([]*bundleFileNode, 0, len(files))
+	for _, file := range files {
+		nodes = append(nodes, a.newBundleFileNode(file))
+	}
+	resolveBundleDependencies(nodes)
+
+	results := make([]*BundleFileResult, 0, len(nodes))
+	done := make([]bool, len(nodes))
+	failed := make([]bool, len(nodes))
+	for len(results) < len(nodes) {
+		idx := nextBundleFileNode(nodes, done)
+		if idx < 0 {
+			// the remaining files depend on each other.
+			var paths []string
+			for i, node := range nodes {
+				if !done[i] {
+					paths = append(paths, node.file.Path)
+				}
+			}
+			for i, node := range nodes {
+				if done[i] {
+					continue
+				}
+				results = append(results, node.result(
+					fmt.Errorf("circular dependency found among the files: %s", strings.Join(paths, ", ")),
+				))
+				done[i] = true
+				failed[i] = true
+			}
+			break
+		}
+		node := nodes[idx]
+		err := node.err
+		if err == nil {
+			for _, dep := range node.dependencies {
+				if failed[dep] {
+					err = fmt.Errorf("skipped because the dependency %s failed", nodes[dep].file.Path)
+					break
+				}
+			}
+		}
+		if err == nil {
+			err = exec(ctx, node.file.Query)
+		}
+		results = append(results, node.result(err))
+		done[idx] = true
+		failed[idx] = err != nil
+	}
+	return results
+}
+
+func (a *Analyzer) newBundleFileNode(file *BundleFile) *bundleFileNode {
+	node := &bundleFileNode{file: file}
+	stmts, err := a.parseScript(file.Query)
+	if err != nil {
+		node.err = err
+		return node
+	}
+	for _, stmt := range stmts {
+		if name := bundleDefinitionName(stmt); name != nil {
+			node.definitions = append(node.definitions, a.namePath.mergePath(name))
+		}
+		_ = parsed_ast.Walk(stmt, func(n parsed_ast.Node) error {
+			if name := bundleReferenceName(n); name != nil {
+				node.references = append(node.references, a.namePath.mergePath(name))
+			}
+			return nil
+		})
+	}
+	return node
+}
+
+// bundleDefinitionName returns the name of the object created by the statement, or nil if the statement doesn't create any objects.
+func bundleDefinitionName(stmt parsed_ast.StatementNode) []string {
+	var name *parsed_ast.PathExpressionNode
+	switch s := stmt.(type) {
+	case *parsed_ast.CreateTableStatementNode:
+		name = s.Name()
+	case *parsed_ast.CreateViewStatementNode:
+		name = s.Name()
+	case *parsed_ast.CreateMaterializedViewStatementNode:
+		name = s.Name()
+	case *parsed_ast.CreateFunctionStatementNode:
+		name = s.FunctionDeclaration().Name()
+	case *parsed_ast.CreateTableFunctionStatementNode:
+		name = s.FunctionDeclaration().Name()
+	case *parsed_ast.CreateProcedureStatementNode:
+		name = s.Name()
+	}
+	if name == nil {
+		return nil
+	}
+	path, _ := getPathFromNode(name)
+	return path
+}
+
+// bundleReferenceName returns the name of the table, function or procedure referenced by the node.
+// The names of the builtin functions and the common table expressions are also returned,
+// but they are ignored because no files define them.
+func bundleReferenceName(n parsed_ast.Node) []string {
+	var name parsed_ast.Node
+	switch node := n.(type) {
+	case *parsed_ast.InsertStatementNode:
+		name = node.TargetPath()
+	case *parsed_ast.UpdateStatementNode:
+		name = node.TargetPath()
+	case *parsed_ast.DeleteStatementNode:
+		name = node.TargetPath()
+	case *parsed_ast.MergeStatementNode:
+		name = node.TargetPath()
+	case *parsed_ast.AlterTableStatementNode:
+		name = node.Path()
+	case *parsed_ast.AlterViewStatementNode:
+		name = node.Path()
+	case *parsed_ast.TablePathExpressionNode:
+		if node.PathExpr() == nil {
+			return nil
+		}
+		name = node.PathExpr()
+	case *parsed_ast.FunctionCallNode:
+		name = node.Function()
+	case *parsed_ast.TVFNode:
+		name = node.Name()
+	case *parsed_ast.CallStatementNode:
+		name = node.ProcedureName()
+	default:
+		return nil
+	}
+	path, err := getPathFromNode(name)
+	if err != nil {
+		return nil
+	}
+	return path
+}
+
+// resolveBundleDependencies resolves the files defining the names referenced by each file.
+// The name is matched by the whole path first, and then by the last name if only one object has the name,
+// because the objects can be referenced by the name without the project or the dataset.
+func resolveBundleDependencies(nodes []*bundleFileNode) {
+	definedPathMap := map[string][]int{}
+	definedNameMap := map[string][]int{}
+	for idx, node := range nodes {
+		for _, def := range node.definitions {
+			path := nameKey(formatPath(def))
+			name := nameKey(def[len(def)-1])
+			definedPathMap[path] = append(definedPathMap[path], idx)
+			definedNameMap[name] = append(definedNameMap[name], idx)
+		}
+	}
+	for idx, node := range nodes {
+		depMap := map[int]struct{}{}
+		for _, ref := range node.references {
+			definers, exists := definedPathMap[nameKey(formatPath(ref))]
+			if !exists {
+				definers = definedNameMap[nameKey(ref[len(ref)-1])]
+				if len(uniqueBundleFileIndexes(definers)) != 1 {
+					continue
+				}
+			}
+			for _, definer := range definers {
+				if definer != idx {
+					depMap[definer] = struct{}{}
+				}
+			}
+		}
+		for dep := range depMap {
+			node.dependencies = append(node.dependencies, dep)
+		}
+		sort.Ints(node.dependencies)
+		for _, dep := range node.dependencies {
+			node.dependencyPaths = append(node.dependencyPaths, nodes[dep].file.Path)
+		}
+	}
+}
+
+func uniqueBundleFileIndexes(indexes []int) map[int]struct{} {
+	indexMap := make(map[int]struct{}, len(indexes))
+	for _, idx := range indexes {
+		indexMap[idx] = struct{}{}
+	}
+	return indexMap
+}
+
+// nextBundleFileNode returns the index of the first file whose dependencies are all done, or -1 if no such file exists.
+func nextBundleFileNode(nodes []*bundleFileNode, done []bool) int {
+	for idx, node := range nodes {
+		if done[idx] {
+			continue
+		}
+		ready := true
+		for _, dep := range node.dependencies {
+			if !done[dep] {
+				ready = false
+				break
+			}
+		}
+		if ready {
+			return idx
+		}
+	}
+	return -1
+}
+
+func (n *bundleFileNode) result(err error) *BundleFileResult {
+	return &BundleFileResult{
+		Path:         n.file.Path,
+		Dependencies: n.dependencyPaths,
+		Err:          err,
+	}
+}
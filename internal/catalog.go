@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -15,7 +16,7 @@ import (
 
 var (
 	createCatalogTableQuery = `
-CREATE TABLE IF NOT EXISTS zetasqlite_catalog(
+CREATE TABLE IF NOT EXISTS %s(
   name STRING NOT NULL PRIMARY KEY,
   kind STRING NOT NULL,
   spec STRING NOT NULL,
@@ -24,7 +25,7 @@ CREATE TABLE IF NOT EXISTS zetasqlite_catalog(
 )
 `
 	upsertCatalogQuery = `
-INSERT INTO zetasqlite_catalog (
+INSERT INTO %s (
   name,
   kind,
   spec,
@@ -41,7 +42,7 @@ INSERT INTO zetasqlite_catalog (
   updatedAt = @updatedAt
 `
 	deleteCatalogQuery = `
-DELETE FROM zetasqlite_catalog WHERE name = @name
+DELETE FROM %s WHERE name = @name
 `
 )
 
@@ -51,6 +52,7 @@ const (
 	TableSpecKind    CatalogSpecKind = "table"
 	ViewSpecKind     CatalogSpecKind = "view"
 	FunctionSpecKind CatalogSpecKind = "function"
+	DatasetSpecKind  CatalogSpecKind = "dataset"
 	catalogName                      = "zetasqlite"
 )
 
@@ -63,23 +65,103 @@ type Catalog struct {
 	catalog      *types.SimpleCatalog
 	tableMap     map[string]*TableSpec
 	funcMap      map[string]*FunctionSpec
+
+	// tableNodeMap holds the tables registered to the ZetaSQL catalog for each table spec.
+	// The spec is registered by the name path and its suffixes, so a spec has multiple tables.
+	tableNodeMap map[string][]*catalogTable
+
+	// specVersionMap holds the updatedAt value of the specs saved or loaded by this catalog.
+	// It is used as the version of the spec to skip reloading the specs already added to the catalog.
+	specVersionMap map[string]time.Time
+
+	attachedDatasets   []*AttachedDataset
+	attachedDatasetMap map[string]*AttachedDataset
+
+	// datasetMap holds the datasets created by CREATE SCHEMA.
+	// They aren't registered to the ZetaSQL catalog, because the datasets are resolved by the name path of the tables.
+	datasetMap map[string]*DatasetSpec
+
+	resultCache *resultCache
 }
 
 func newSimpleCatalog(name string) *types.SimpleCatalog {
 	catalog := types.NewSimpleCatalog(name)
 	catalog.AddZetaSQLBuiltinFunctions(nil)
+	for _, fn := range newZetaSQLiteFunctions() {
+		catalog.AddFunction(fn)
+	}
 	return catalog
 }
 
+// newZetaSQLiteFunctions returns the functions of BigQuery that are not defined by the ZetaSQL builtin functions of go-zetasql.
+// They are called by the same name as the functions registered to SQLite ( e.g. edit_distance is called by zetasqlite_edit_distance ),
+// so each function must be also registered in normalFuncs.
+func newZetaSQLiteFunctions() []*types.Function {
+	arg := func(typ types.Type) *types.FunctionArgumentType {
+		return types.NewFunctionArgumentType(typ, types.NewFunctionArgumentTypeOptions(types.RequiredArgumentCardinality))
+	}
+	optionalArg := func(typ types.Type, name string) *types.FunctionArgumentType {
+		opt := types.NewFunctionArgumentTypeOptions(types.OptionalArgumentCardinality)
+		if name != "" {
+			opt.SetArgumentName(name)
+		}
+		return types.NewFunctionArgumentType(typ, opt)
+	}
+	newFunction := func(name string, sigs ...*types.FunctionSignature) *types.Function {
+		return types.NewFunction([]string{name}, "", types.ScalarMode, sigs)
+	}
+	return []*types.Function{
+		// EDIT_DISTANCE(value1, value2 [, max_distance => max_distance])
+		newFunction(
+			"edit_distance",
+			types.NewFunctionSignature(
+				arg(types.Int64Type()),
+				[]*types.FunctionArgumentType{arg(types.StringType()), arg(types.StringType()), optionalArg(types.Int64Type(), "max_distance")},
+			),
+			types.NewFunctionSignature(
+				arg(types.Int64Type()),
+				[]*types.FunctionArgumentType{arg(types.BytesType()), arg(types.BytesType()), optionalArg(types.Int64Type(), "max_distance")},
+			),
+		),
+		// SPLIT_SUBSTR(value, delimiter, start_split [, count])
+		newFunction(
+			"split_substr",
+			types.NewFunctionSignature(
+				arg(types.StringType()),
+				[]*types.FunctionArgumentType{
+					arg(types.StringType()), arg(types.StringType()), arg(types.Int64Type()), optionalArg(types.Int64Type(), ""),
+				},
+			),
+		),
+	}
+}
+
 func NewCatalog(db *sql.DB) *Catalog {
 	return &Catalog{
-		db:       db,
-		catalog:  newSimpleCatalog(catalogName),
-		tableMap: map[string]*TableSpec{},
-		funcMap:  map[string]*FunctionSpec{},
+		db:                 db,
+		catalog:            newSimpleCatalog(catalogName),
+		tableMap:           map[string]*TableSpec{},
+		funcMap:            map[string]*FunctionSpec{},
+		tableNodeMap:       map[string][]*catalogTable{},
+		specVersionMap:     map[string]time.Time{},
+		attachedDatasetMap: map[string]*AttachedDataset{},
+		datasetMap:         map[string]*DatasetSpec{},
+		resultCache:        newResultCache(),
 	}
 }
 
+// InvalidateResultCache discards the cached query results.
+// It must be called after the statement that may modify the database is executed.
+func (c *Catalog) InvalidateResultCache() {
+	c.resultCache.invalidate()
+}
+
+// InvalidateTableResultCache discards the cached query results reading the tables.
+// It must be called after the rows of the tables are modified, and the tables are the names returned by TableSpec.TableName.
+func (c *Catalog) InvalidateTableResultCache(tables ...string) {
+	c.resultCache.invalidateTables(tables)
+}
+
 func (c *Catalog) FullName() string {
 	return c.catalog.FullName()
 }
@@ -155,7 +237,7 @@ func (c *Catalog) getFunctions(namePath *NamePath) []*FunctionSpec {
 	if namePath.empty() {
 		return c.functions
 	}
-	key := c.formatNamePath(namePath.path)
+	key := nameKey(c.formatNamePath(namePath.path))
 	specs := make([]*FunctionSpec, 0, len(c.functions))
 	for _, fn := range c.functions {
 		if len(fn.NamePath) == 1 {
@@ -163,7 +245,7 @@ func (c *Catalog) getFunctions(namePath *NamePath) []*FunctionSpec {
 			specs = append(specs, fn)
 			continue
 		}
-		pathPrefixKey := c.formatNamePath(c.trimmedLastPath(fn.NamePath))
+		pathPrefixKey := nameKey(c.formatNamePath(c.trimmedLastPath(fn.NamePath)))
 		if strings.Contains(pathPrefixKey, key) {
 			specs = append(specs, fn)
 		}
@@ -179,9 +261,33 @@ func (c *Catalog) Sync(ctx context.Context, conn *Conn) error {
 		return fmt.Errorf("failed to create catalog tables: %w", err)
 	}
 	now := time.Now()
+	if err := c.syncSchema(ctx, conn, ""); err != nil {
+		return err
+	}
+	for _, dataset := range c.attachedDatasets {
+		exists, err := c.existsCatalogTable(ctx, conn, dataset.Name)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			continue
+		}
+		if err := c.syncSchema(ctx, conn, dataset.Name); err != nil {
+			return fmt.Errorf("failed to sync catalog of attached dataset %s: %w", dataset.Name, err)
+		}
+	}
+	c.lastSyncedAt = now
+	return nil
+}
+
+// syncSchema loads the specs updated after the last synchronization from the catalog table of the schema.
+func (c *Catalog) syncSchema(ctx context.Context, conn *Conn, schema string) error {
 	rows, err := conn.QueryContext(
 		ctx,
-		`SELECT name, kind, spec FROM zetasqlite_catalog WHERE updatedAt >= @lastUpdatedAt`,
+		fmt.Sprintf(
+			`SELECT name, kind, spec, updatedAt FROM %s WHERE updatedAt >= @lastUpdatedAt`,
+			catalogTableName(schema),
+		),
 		c.lastSyncedAt,
 	)
 	if err != nil {
@@ -193,34 +299,68 @@ func (c *Catalog) Sync(ctx context.Context, conn *Conn) error {
 	defer rows.Close()
 	for rows.Next() {
 		var (
-			name string
-			kind CatalogSpecKind
-			spec string
+			name      string
+			kind      CatalogSpecKind
+			spec      string
+			updatedAt time.Time
 		)
-		if err := rows.Scan(&name, &kind, &spec); err != nil {
+		if err := rows.Scan(&name, &kind, &spec, &updatedAt); err != nil {
 			return fmt.Errorf("failed to scan catalog values: %w", err)
 		}
+		if version, exists := c.specVersionMap[name]; exists && version.Equal(updatedAt) {
+			// already added to the catalog.
+			continue
+		}
 		switch kind {
 		case TableSpecKind, ViewSpecKind:
-			if err := c.loadTableSpec(spec); err != nil {
+			if err := c.loadTableSpec(spec, schema); err != nil {
 				return fmt.Errorf("failed to load table spec: %w", err)
 			}
 		case FunctionSpecKind:
 			if err := c.loadFunctionSpec(spec); err != nil {
 				return fmt.Errorf("failed to load function spec: %w", err)
 			}
+		case DatasetSpecKind:
+			if err := c.loadDatasetSpec(spec); err != nil {
+				return fmt.Errorf("failed to load dataset spec: %w", err)
+			}
 		default:
 			return fmt.Errorf("unknown catalog spec kind %s", kind)
 		}
+		c.specVersionMap[name] = updatedAt
 	}
-	c.lastSyncedAt = now
-	return nil
+	return rows.Err()
+}
+
+// existsCatalogTable reports whether the catalog table exists in the attached database.
+// The attached database that isn't created by zetasqlite doesn't have the catalog table.
+func (c *Catalog) existsCatalogTable(ctx context.Context, conn *Conn, schema string) (bool, error) {
+	rows, err := conn.QueryContext(
+		ctx,
+		fmt.Sprintf("SELECT 1 FROM %s WHERE type = 'table' AND name = 'zetasqlite_catalog'", qualifiedName(schema, "sqlite_master")),
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to find catalog table of attached dataset %s: %w", schema, err)
+	}
+	defer rows.Close()
+	exists := rows.Next()
+	if err := rows.Err(); err != nil {
+		return false, err
+	}
+	return exists, nil
 }
 
 func (c *Catalog) AddNewTableSpec(ctx context.Context, conn *Conn, spec *TableSpec) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	if current, exists := c.tableMap[nameKey(spec.TableName())]; exists && current.TableName() != spec.TableName() {
+		// remove the spec saved by the name written in the different case.
+		if _, err := conn.ExecContext(ctx, fmt.Sprintf(deleteCatalogQuery, catalogTableName(current.Schema)), sql.Named("name", current.TableName())); err != nil {
+			return err
+		}
+		delete(c.specVersionMap, current.TableName())
+	}
 	if err := c.addTableSpec(spec); err != nil {
 		return err
 	}
@@ -236,6 +376,13 @@ func (c *Catalog) AddNewFunctionSpec(ctx context.Context, conn *Conn, spec *Func
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	if current, exists := c.funcMap[nameKey(spec.FuncName())]; exists && current.FuncName() != spec.FuncName() {
+		// remove the spec saved by the name written in the different case.
+		if _, err := conn.ExecContext(ctx, fmt.Sprintf(deleteCatalogQuery, catalogTableName("")), sql.Named("name", current.FuncName())); err != nil {
+			return err
+		}
+		delete(c.specVersionMap, current.FuncName())
+	}
 	if err := c.addFunctionSpec(spec); err != nil {
 		return err
 	}
@@ -247,16 +394,29 @@ func (c *Catalog) AddNewFunctionSpec(ctx context.Context, conn *Conn, spec *Func
 	return nil
 }
 
+func (c *Catalog) AddNewDatasetSpec(ctx context.Context, conn *Conn, spec *DatasetSpec) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.datasetMap[nameKey(spec.DatasetName())] = spec
+	return c.saveDatasetSpec(ctx, conn, spec)
+}
+
 func (c *Catalog) DeleteTableSpec(ctx context.Context, conn *Conn, name string) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	spec := c.lookupTableSpec(name)
+	if spec == nil {
+		return fmt.Errorf("failed to find table spec from map by %s", name)
+	}
 	if err := c.deleteTableSpecByName(name); err != nil {
 		return err
 	}
-	if _, err := conn.ExecContext(ctx, deleteCatalogQuery, sql.Named("name", name)); err != nil {
+	if _, err := conn.ExecContext(ctx, fmt.Sprintf(deleteCatalogQuery, catalogTableName(spec.Schema)), sql.Named("name", spec.TableName())); err != nil {
 		return err
 	}
+	delete(c.specVersionMap, spec.TableName())
 	return nil
 }
 
@@ -264,24 +424,161 @@ func (c *Catalog) DeleteFunctionSpec(ctx context.Context, conn *Conn, name strin
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	spec, exists := c.funcMap[nameKey(name)]
+	if !exists {
+		return fmt.Errorf("failed to find function spec from map by %s", name)
+	}
 	if err := c.deleteFunctionSpecByName(name); err != nil {
 		return err
 	}
-	if _, err := conn.ExecContext(ctx, deleteCatalogQuery, sql.Named("name", name)); err != nil {
+	if _, err := conn.ExecContext(ctx, fmt.Sprintf(deleteCatalogQuery, catalogTableName("")), sql.Named("name", spec.FuncName())); err != nil {
 		return err
 	}
+	delete(c.specVersionMap, spec.FuncName())
 	return nil
 }
 
-func (c *Catalog) deleteTableSpecByName(name string) error {
-	spec, exists := c.tableMap[name]
+func (c *Catalog) existsTableSpec(name string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.lookupTableSpec(name) != nil
+}
+
+func (c *Catalog) existsFunctionSpec(name string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, exists := c.funcMap[nameKey(name)]
+	return exists
+}
+
+func (c *Catalog) existsDatasetSpec(name string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, exists := c.datasetMap[nameKey(name)]
+	return exists
+}
+
+// datasetDefaultCollation returns the default collation of the dataset that the table of the name path belongs to.
+// If the dataset isn't created by CREATE SCHEMA, returns empty string.
+func (c *Catalog) datasetDefaultCollation(tableNamePath []string) string {
+	if len(tableNamePath) < 2 {
+		return ""
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	spec, exists := c.datasetMap[nameKey(formatPath(tableNamePath[:len(tableNamePath)-1]))]
 	if !exists {
+		return ""
+	}
+	return spec.DefaultCollation
+}
+
+func (c *Catalog) tableSpec(name string) *TableSpec {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.lookupTableSpec(name)
+}
+
+// checkTableName returns the error if the table is referenced by the name written in the different case.
+// ZetaSQL catalog resolves the table names case-insensitively, but they are case-sensitive in BigQuery.
+func (c *Catalog) checkTableName(name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if spec, exists := c.tableMap[nameKey(name)]; exists && !spec.hasTableName(name) {
+		return fmt.Errorf("Table not found: %s", name)
+	}
+	return nil
+}
+
+// lookupTableSpec returns the spec of the table referenced by name.
+// It must be called with the lock held.
+func (c *Catalog) lookupTableSpec(name string) *TableSpec {
+	spec, exists := c.tableMap[nameKey(name)]
+	if !exists || !spec.hasTableName(name) {
+		return nil
+	}
+	return spec
+}
+
+// tableSpecs returns the table and view specs sorted by name.
+// The specs are shared with the catalog, so they must not be modified.
+func (c *Catalog) tableSpecs() []*TableSpec {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	specs := append([]*TableSpec{}, c.tables...)
+	sort.SliceStable(specs, func(i, j int) bool {
+		return specs[i].TableName() < specs[j].TableName()
+	})
+	return specs
+}
+
+// functionSpecs returns the user defined function specs sorted by name.
+// The specs are shared with the catalog, so they must not be modified.
+func (c *Catalog) functionSpecs() []*FunctionSpec {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	specs := append([]*FunctionSpec{}, c.functions...)
+	sort.SliceStable(specs, func(i, j int) bool {
+		return specs[i].FuncName() < specs[j].FuncName()
+	})
+	return specs
+}
+
+// columnCollation returns the collation specification of the column.
+// tableName is the name of the table registered to the ZetaSQL catalog.
+func (c *Catalog) columnCollation(tableName, columnName string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	spec := c.tableSpecByCatalogName(tableName)
+	if spec == nil {
+		return ""
+	}
+	if col := spec.Column(columnName); col != nil {
+		return col.Collation
+	}
+	return ""
+}
+
+// tableSpecByCatalogName returns the spec of the table registered to the ZetaSQL catalog by the name ( e.g. types.Table.Name() ).
+// The table is registered by the name path and its suffixes joined by "." ( see addTableSpecRecursive ),
+// so the suffix can be shared by the tables of the different datasets.
+// nil is returned if no table or more than one table matches the name.
+// It must be called with the lock held.
+func (c *Catalog) tableSpecByCatalogName(name string) *TableSpec {
+	var found *TableSpec
+	for _, spec := range c.tables {
+		for i := range spec.NamePath {
+			if strings.Join(spec.NamePath[i:], ".") != name {
+				continue
+			}
+			if found != nil && found != spec {
+				return nil
+			}
+			found = spec
+			break
+		}
+	}
+	return found
+}
+
+func (c *Catalog) deleteTableSpecByName(name string) error {
+	spec := c.lookupTableSpec(name)
+	if spec == nil {
 		return fmt.Errorf("failed to find table spec from map by %s", name)
 	}
 	tables := make([]*TableSpec, 0, len(c.tables))
 	specName := c.formatNamePath(spec.NamePath)
 	for _, table := range c.tables {
-		if specName == c.formatNamePath(table.NamePath) {
+		if strings.EqualFold(specName, c.formatNamePath(table.NamePath)) {
 			continue
 		}
 		tables = append(tables, table)
@@ -293,14 +590,14 @@ func (c *Catalog) deleteTableSpecByName(name string) error {
 }
 
 func (c *Catalog) deleteFunctionSpecByName(name string) error {
-	spec, exists := c.funcMap[name]
+	spec, exists := c.funcMap[nameKey(name)]
 	if !exists {
 		return fmt.Errorf("failed to find function spec from map by %s", name)
 	}
 	functions := make([]*FunctionSpec, 0, len(c.functions))
 	specName := c.formatNamePath(spec.NamePath)
 	for _, function := range c.functions {
-		if specName == c.formatNamePath(function.NamePath) {
+		if strings.EqualFold(specName, c.formatNamePath(function.NamePath)) {
 			continue
 		}
 		functions = append(functions, function)
@@ -317,6 +614,7 @@ func (c *Catalog) resetCatalog(tables []*TableSpec, functions []*FunctionSpec) e
 	c.functions = []*FunctionSpec{}
 	c.tableMap = map[string]*TableSpec{}
 	c.funcMap = map[string]*FunctionSpec{}
+	c.tableNodeMap = map[string][]*catalogTable{}
 	for _, spec := range tables {
 		if err := c.addTableSpec(spec); err != nil {
 			return err
@@ -330,6 +628,46 @@ func (c *Catalog) resetCatalog(tables []*TableSpec, functions []*FunctionSpec) e
 	return nil
 }
 
+// catalogSnapshot is the specs of the catalog kept to restore them when the statement modifying them fails.
+type catalogSnapshot struct {
+	tables         []*TableSpec
+	functions      []*FunctionSpec
+	datasetMap     map[string]*DatasetSpec
+	specVersionMap map[string]time.Time
+	tempFunctions  []*FunctionSpec
+}
+
+func (c *Catalog) snapshot() *catalogSnapshot {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	versionMap := make(map[string]time.Time, len(c.specVersionMap))
+	for name, version := range c.specVersionMap {
+		versionMap[name] = version
+	}
+	datasetMap := make(map[string]*DatasetSpec, len(c.datasetMap))
+	for name, spec := range c.datasetMap {
+		datasetMap[name] = spec
+	}
+	return &catalogSnapshot{
+		tables:         append([]*TableSpec{}, c.tables...),
+		functions:      append([]*FunctionSpec{}, c.functions...),
+		datasetMap:     datasetMap,
+		specVersionMap: versionMap,
+	}
+}
+
+// restore replaces the specs with the snapshot without saving them,
+// because the changes of the catalog table are rolled back with the statement.
+func (c *Catalog) restore(snapshot *catalogSnapshot) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.specVersionMap = snapshot.specVersionMap
+	c.datasetMap = snapshot.datasetMap
+	return c.resetCatalog(snapshot.tables, snapshot.functions)
+}
+
 func (c *Catalog) saveTableSpec(ctx context.Context, conn *Conn, spec *TableSpec) error {
 	encoded, err := json.Marshal(spec)
 	if err != nil {
@@ -340,9 +678,14 @@ func (c *Catalog) saveTableSpec(ctx context.Context, conn *Conn, spec *TableSpec
 	if spec.IsView {
 		kind = string(ViewSpecKind)
 	}
+	if spec.Schema != "" {
+		if _, err := conn.ExecContext(ctx, fmt.Sprintf(createCatalogTableQuery, catalogTableName(spec.Schema))); err != nil {
+			return fmt.Errorf("failed to create catalog table of attached dataset %s: %w", spec.Schema, err)
+		}
+	}
 	if _, err := conn.ExecContext(
 		ctx,
-		upsertCatalogQuery,
+		fmt.Sprintf(upsertCatalogQuery, catalogTableName(spec.Schema)),
 		sql.Named("name", spec.TableName()),
 		sql.Named("kind", kind),
 		sql.Named("spec", string(encoded)),
@@ -351,6 +694,7 @@ func (c *Catalog) saveTableSpec(ctx context.Context, conn *Conn, spec *TableSpec
 	); err != nil {
 		return fmt.Errorf("failed to save a new table spec: %w", err)
 	}
+	c.specVersionMap[spec.TableName()] = now
 	return nil
 }
 
@@ -362,7 +706,7 @@ func (c *Catalog) saveFunctionSpec(ctx context.Context, conn *Conn, spec *Functi
 	now := time.Now()
 	if _, err := conn.ExecContext(
 		ctx,
-		upsertCatalogQuery,
+		fmt.Sprintf(upsertCatalogQuery, catalogTableName("")),
 		sql.Named("name", spec.FuncName()),
 		sql.Named("kind", string(FunctionSpecKind)),
 		sql.Named("spec", string(encoded)),
@@ -371,21 +715,44 @@ func (c *Catalog) saveFunctionSpec(ctx context.Context, conn *Conn, spec *Functi
 	); err != nil {
 		return fmt.Errorf("failed to save a new function spec: %w", err)
 	}
+	c.specVersionMap[spec.FuncName()] = now
+	return nil
+}
+
+func (c *Catalog) saveDatasetSpec(ctx context.Context, conn *Conn, spec *DatasetSpec) error {
+	encoded, err := json.Marshal(spec)
+	if err != nil {
+		return fmt.Errorf("failed to encode dataset spec: %w", err)
+	}
+	now := time.Now()
+	if _, err := conn.ExecContext(
+		ctx,
+		fmt.Sprintf(upsertCatalogQuery, catalogTableName("")),
+		sql.Named("name", spec.DatasetName()),
+		sql.Named("kind", string(DatasetSpecKind)),
+		sql.Named("spec", string(encoded)),
+		sql.Named("updatedAt", now),
+		sql.Named("createdAt", now),
+	); err != nil {
+		return fmt.Errorf("failed to save a new dataset spec: %w", err)
+	}
+	c.specVersionMap[spec.DatasetName()] = now
 	return nil
 }
 
 func (c *Catalog) createCatalogTablesIfNotExists(ctx context.Context, conn *Conn) error {
-	if _, err := conn.ExecContext(ctx, createCatalogTableQuery); err != nil {
+	if _, err := conn.ExecContext(ctx, fmt.Sprintf(createCatalogTableQuery, catalogTableName(""))); err != nil {
 		return fmt.Errorf("failed to create catalog table: %w", err)
 	}
 	return nil
 }
 
-func (c *Catalog) loadTableSpec(spec string) error {
+func (c *Catalog) loadTableSpec(spec, schema string) error {
 	var v TableSpec
 	if err := json.Unmarshal([]byte(spec), &v); err != nil {
 		return fmt.Errorf("failed to decode table spec: %w", err)
 	}
+	v.Schema = schema
 	if err := c.addTableSpec(&v); err != nil {
 		return fmt.Errorf("failed to add table spec to catalog: %w", err)
 	}
@@ -403,6 +770,15 @@ func (c *Catalog) loadFunctionSpec(spec string) error {
 	return nil
 }
 
+func (c *Catalog) loadDatasetSpec(spec string) error {
+	var v DatasetSpec
+	if err := json.Unmarshal([]byte(spec), &v); err != nil {
+		return fmt.Errorf("failed to decode dataset spec: %w", err)
+	}
+	c.datasetMap[nameKey(v.DatasetName())] = &v
+	return nil
+}
+
 func (c *Catalog) trimmedLastPath(path []string) []string {
 	if len(path) == 0 {
 		return path
@@ -411,9 +787,15 @@ func (c *Catalog) trimmedLastPath(path []string) []string {
 }
 
 func (c *Catalog) addFunctionSpec(spec *FunctionSpec) error {
-	funcName := spec.FuncName()
+	funcName := nameKey(spec.FuncName())
 	if _, exists := c.funcMap[funcName]; exists {
-		c.funcMap[funcName] = spec // update current spec
+		// update current spec
+		for idx, fn := range c.functions {
+			if nameKey(fn.FuncName()) == funcName {
+				c.functions[idx] = spec
+			}
+		}
+		c.funcMap[funcName] = spec
 		return nil
 	}
 	c.functions = append(c.functions, spec)
@@ -425,20 +807,34 @@ func (c *Catalog) addFunctionSpec(spec *FunctionSpec) error {
 }
 
 func (c *Catalog) addTableSpec(spec *TableSpec) error {
-	tableName := spec.TableName()
+	tableName := nameKey(spec.TableName())
 	if _, exists := c.tableMap[tableName]; exists {
-		c.tableMap[tableName] = spec // update current spec
+		// the columns may be changed ( e.g. CREATE OR REPLACE TABLE ),
+		// so replace the columns of the tables registered for the current spec.
+		for _, table := range c.tableNodeMap[tableName] {
+			if err := table.setColumns(spec); err != nil {
+				return err
+			}
+		}
+		for idx, table := range c.tables {
+			if nameKey(table.TableName()) == tableName {
+				c.tables[idx] = spec
+			}
+		}
+		c.tableMap[tableName] = spec
 		return nil
 	}
 	c.tables = append(c.tables, spec)
 	c.tableMap[tableName] = spec
-	if err := c.addTableSpecRecursive(c.catalog, spec); err != nil {
+	if err := c.addTableSpecRecursive(c.catalog, tableName, spec); err != nil {
 		return err
 	}
 	return nil
 }
 
-func (c *Catalog) addTableSpecRecursive(cat *types.SimpleCatalog, spec *TableSpec) error {
+// addTableSpecRecursive registers the table of the spec to the catalog and its sub catalogs.
+// specName is the key of the tableNodeMap to replace the registered tables later.
+func (c *Catalog) addTableSpecRecursive(cat *types.SimpleCatalog, specName string, spec *TableSpec) error {
 	if len(spec.NamePath) > 1 {
 		subCatalogName := spec.NamePath[0]
 		subCatalog, _ := cat.Catalog(subCatalogName)
@@ -448,19 +844,17 @@ func (c *Catalog) addTableSpecRecursive(cat *types.SimpleCatalog, spec *TableSpe
 		}
 		fullTableName := strings.Join(spec.NamePath, ".")
 		if !c.existsTable(cat, fullTableName) {
-			table, err := c.createSimpleTable(fullTableName, spec)
-			if err != nil {
+			if err := c.addCatalogTable(cat, specName, fullTableName, spec); err != nil {
 				return err
 			}
-			cat.AddTable(table)
 		}
 		newNamePath := spec.NamePath[1:]
 		// add sub catalog to root catalog
-		if err := c.addTableSpecRecursive(cat, c.copyTableSpec(spec, newNamePath)); err != nil {
+		if err := c.addTableSpecRecursive(cat, specName, c.copyTableSpec(spec, newNamePath)); err != nil {
 			return fmt.Errorf("failed to add table spec to root catalog: %w", err)
 		}
 		// add sub catalog to parent catalog
-		if err := c.addTableSpecRecursive(subCatalog, c.copyTableSpec(spec, newNamePath)); err != nil {
+		if err := c.addTableSpecRecursive(subCatalog, specName, c.copyTableSpec(spec, newNamePath)); err != nil {
 			return fmt.Errorf("failed to add table spec to parent catalog: %w", err)
 		}
 		return nil
@@ -473,28 +867,19 @@ func (c *Catalog) addTableSpecRecursive(cat *types.SimpleCatalog, spec *TableSpe
 	if c.existsTable(cat, tableName) {
 		return nil
 	}
-	table, err := c.createSimpleTable(tableName, spec)
+	return c.addCatalogTable(cat, specName, tableName, spec)
+}
+
+func (c *Catalog) addCatalogTable(cat *types.SimpleCatalog, specName, tableName string, spec *TableSpec) error {
+	table, err := newCatalogTable(tableName, spec)
 	if err != nil {
 		return err
 	}
 	cat.AddTable(table)
+	c.tableNodeMap[specName] = append(c.tableNodeMap[specName], table)
 	return nil
 }
 
-func (c *Catalog) createSimpleTable(tableName string, spec *TableSpec) (*types.SimpleTable, error) {
-	columns := []types.Column{}
-	for _, column := range spec.Columns {
-		typ, err := column.Type.ToZetaSQLType()
-		if err != nil {
-			return nil, err
-		}
-		columns = append(columns, types.NewSimpleColumn(
-			tableName, column.Name, typ,
-		))
-	}
-	return types.NewSimpleTable(tableName, columns), nil
-}
-
 func (c *Catalog) addFunctionSpecRecursive(cat *types.SimpleCatalog, spec *FunctionSpec) error {
 	if len(spec.NamePath) > 1 {
 		subCatalogName := spec.NamePath[0]
@@ -0,0 +1,106 @@
+package internal
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/goccy/go-zetasql/types"
+)
+
+// catalogTable is the table registered to the ZetaSQL catalog.
+// The table can't be removed from the ZetaSQL catalog, so the columns are replaced in place
+// when the table is replaced ( e.g. CREATE OR REPLACE TABLE ) instead of rebuilding the entire catalog.
+type catalogTable struct {
+	mu      sync.RWMutex
+	name    string
+	columns []types.Column
+}
+
+func newCatalogTable(name string, spec *TableSpec) (*catalogTable, error) {
+	table := &catalogTable{name: name}
+	if err := table.setColumns(spec); err != nil {
+		return nil, err
+	}
+	return table, nil
+}
+
+func (t *catalogTable) setColumns(spec *TableSpec) error {
+	columns := make([]types.Column, 0, len(spec.Columns))
+	for _, column := range spec.Columns {
+		typ, err := column.Type.ToZetaSQLType()
+		if err != nil {
+			return err
+		}
+		columns = append(columns, types.NewSimpleColumn(t.name, column.Name, typ))
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.columns = columns
+	return nil
+}
+
+func (t *catalogTable) Name() string {
+	return t.name
+}
+
+func (t *catalogTable) FullName() string {
+	return t.name
+}
+
+func (t *catalogTable) NumColumns() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return len(t.columns)
+}
+
+func (t *catalogTable) Column(idx int) types.Column {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if idx < 0 || idx >= len(t.columns) {
+		return nil
+	}
+	return t.columns[idx]
+}
+
+func (t *catalogTable) PrimaryKey() []int {
+	return nil
+}
+
+func (t *catalogTable) FindColumnByName(name string) types.Column {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	for _, column := range t.columns {
+		if strings.EqualFold(column.Name(), name) {
+			return column
+		}
+	}
+	return nil
+}
+
+func (t *catalogTable) IsValueTable() bool {
+	return false
+}
+
+func (t *catalogTable) SerializationID() int64 {
+	return 0
+}
+
+func (t *catalogTable) CreateEvaluatorTableIterator(columnIdxs []int) (*types.EvaluatorTableIterator, error) {
+	return nil, nil
+}
+
+func (t *catalogTable) AnonymizationInfo() *types.AnonymizationInfo {
+	return nil
+}
+
+func (t *catalogTable) SupportsAnonymization() bool {
+	return false
+}
+
+func (t *catalogTable) TableTypeName(mode types.ProductMode) string {
+	return ""
+}
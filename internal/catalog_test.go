@@ -0,0 +1,98 @@
+package internal
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/goccy/go-zetasql/types"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestCatalogSpecVersion(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	sqlConn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sqlConn.Close()
+	conn := NewConn(sqlConn, nil)
+
+	newTableSpec := func(columns ...string) *TableSpec {
+		spec := &TableSpec{NamePath: []string{"table1"}}
+		for _, column := range columns {
+			spec.Columns = append(spec.Columns, &ColumnSpec{Name: column, Type: &Type{Kind: int(types.INT64)}})
+		}
+		return spec
+	}
+	savedVersion := func() time.Time {
+		var updatedAt time.Time
+		if err := sqlConn.QueryRowContext(ctx, "SELECT updatedAt FROM zetasqlite_catalog WHERE name = 'table1'").Scan(&updatedAt); err != nil {
+			t.Fatal(err)
+		}
+		return updatedAt
+	}
+
+	catalog := NewCatalog(db)
+	if err := catalog.Sync(ctx, conn); err != nil {
+		t.Fatal(err)
+	}
+	if err := catalog.AddNewTableSpec(ctx, conn, newTableSpec("id")); err != nil {
+		t.Fatal(err)
+	}
+	version := catalog.specVersionMap["table1"]
+	if !version.Equal(savedVersion()) {
+		t.Fatalf("expected version %v but got %v", savedVersion(), version)
+	}
+
+	// the other catalog loads the saved spec and skips it while the version isn't changed.
+	other := NewCatalog(db)
+	if err := other.Sync(ctx, conn); err != nil {
+		t.Fatal(err)
+	}
+	loaded := other.tableSpec("table1")
+	if loaded == nil || len(loaded.Columns) != 1 {
+		t.Fatalf("failed to load table spec: %+v", loaded)
+	}
+	if err := other.Sync(ctx, conn); err != nil {
+		t.Fatal(err)
+	}
+	if other.tableSpec("table1") != loaded {
+		t.Fatal("expected the loaded spec to be reused")
+	}
+
+	// replacing the spec bumps the version, so the other catalog reloads it.
+	if err := catalog.AddNewTableSpec(ctx, conn, newTableSpec("id", "name")); err != nil {
+		t.Fatal(err)
+	}
+	replaced := catalog.specVersionMap["table1"]
+	if !replaced.Equal(savedVersion()) {
+		t.Fatalf("expected version %v but got %v", savedVersion(), replaced)
+	}
+	if !replaced.After(version) {
+		t.Fatalf("expected version to be bumped from %v but got %v", version, replaced)
+	}
+	if err := other.Sync(ctx, conn); err != nil {
+		t.Fatal(err)
+	}
+	if spec := other.tableSpec("table1"); spec == nil || len(spec.Columns) != 2 {
+		t.Fatalf("failed to reload replaced table spec: %+v", spec)
+	}
+	if !other.specVersionMap["table1"].Equal(replaced) {
+		t.Fatalf("expected version %v but got %v", replaced, other.specVersionMap["table1"])
+	}
+
+	// dropping the spec removes the version.
+	if err := catalog.DeleteTableSpec(ctx, conn, "table1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, exists := catalog.specVersionMap["table1"]; exists {
+		t.Fatal("expected version of dropped table to be removed")
+	}
+}
@@ -29,3 +29,32 @@ type StructValueLayout struct {
 	Keys   []string      `json:"keys"`
 	Values []interface{} `json:"values"`
 }
+
+// binaryValuePrefix is the prefix of the value encoded by the binary value encoding.
+// Since it is not contained in the base64 alphabet, encoded values can be distinguished from the legacy JSON based encoding.
+const binaryValuePrefix = "!"
+
+// binaryValueEncodingVersion is the version of the binary value encoding.
+// It is written to the first byte of the encoded value and must be incremented when the layout is changed.
+const binaryValueEncodingVersion byte = 1
+
+type binaryValueTag byte
+
+const (
+	binaryNullValueTag binaryValueTag = iota
+	binaryIntValueTag
+	binaryFloatValueTag
+	binaryBoolValueTag
+	binaryStringValueTag
+	binaryBytesValueTag
+	binaryNumericValueTag
+	binaryBigNumericValueTag
+	binaryDateValueTag
+	binaryDatetimeValueTag
+	binaryTimeValueTag
+	binaryTimestampValueTag
+	binaryIntervalValueTag
+	binaryJsonValueTag
+	binaryArrayValueTag
+	binaryStructValueTag
+)
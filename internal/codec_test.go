@@ -0,0 +1,264 @@
+package internal
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testValues(t testing.TB) []Value {
+	interval, err := parseInterval("1-2 3 4:5:6.789")
+	if err != nil {
+		t.Fatal(err)
+	}
+	date := time.Date(2022, 1, 2, 0, 0, 0, 0, time.UTC)
+	return []Value{
+		StringValue("hello"),
+		BytesValue("world"),
+		&NumericValue{Rat: big.NewRat(12345, 100)},
+		&NumericValue{Rat: big.NewRat(-1, 3), isBigNumeric: true},
+		DateValue(date),
+		DatetimeValue(time.Date(2022, 1, 2, 3, 4, 5, 678901000, time.UTC)),
+		TimeValue(time.Date(0, 1, 1, 3, 4, 5, 678901000, time.UTC)),
+		TimestampValue(time.Date(2022, 1, 2, 3, 4, 5, 678901000, time.UTC)),
+		interval,
+		JsonValue(`{"a":[1,2,3]}`),
+		&ArrayValue{values: []Value{IntValue(1), nil, IntValue(-3)}},
+		&StructValue{
+			keys:   []string{"a", "b"},
+			values: []Value{FloatValue(1.5), &ArrayValue{values: []Value{StringValue("x"), DateValue(date)}}},
+			m: map[string]Value{
+				"a": FloatValue(1.5),
+				"b": &ArrayValue{values: []Value{StringValue("x"), DateValue(date)}},
+			},
+		},
+	}
+}
+
+func TestBinaryValueEncoding(t *testing.T) {
+	for _, value := range testValues(t) {
+		encoded, err := EncodeValue(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		text, ok := encoded.(string)
+		if !ok || !strings.HasPrefix(text, binaryValuePrefix) {
+			t.Fatalf("unexpected encoded value %v", encoded)
+		}
+		legacy, err := encodeValueLayout(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(text) >= len(legacy.(string)) {
+			t.Errorf("encoded value of %T is not smaller than legacy encoding: %d >= %d", value, len(text), len(legacy.(string)))
+		}
+		for _, src := range []interface{}{encoded, legacy} {
+			decoded, err := DecodeValue(src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := encodeValueLayout(decoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != legacy {
+				t.Errorf("failed to decode %T value: got %v", value, decoded)
+			}
+		}
+	}
+}
+
+func TestBinaryValueEncodingVersion(t *testing.T) {
+	unknown := binaryValuePrefix + base64.RawStdEncoding.EncodeToString(
+		[]byte{binaryValueEncodingVersion + 1, byte(binaryNullValueTag)},
+	)
+	if _, err := DecodeValue(unknown); err == nil {
+		t.Fatal("expected error for unsupported encoding version")
+	}
+}
+
+func BenchmarkEncodeValue(b *testing.B) {
+	values := testValues(b)
+	for _, bench := range []struct {
+		name   string
+		encode func(Value) (interface{}, error)
+	}{
+		{name: "legacy", encode: encodeValueLayout},
+		{name: "binary", encode: EncodeValue},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			var size int
+			for i := 0; i < b.N; i++ {
+				size = 0
+				for _, value := range values {
+					encoded, err := bench.encode(value)
+					if err != nil {
+						b.Fatal(err)
+					}
+					size += len(encoded.(string))
+				}
+			}
+			b.ReportMetric(float64(size), "bytes/values")
+		})
+	}
+}
+
+func BenchmarkDecodeValue(b *testing.B) {
+	values := testValues(b)
+	for _, bench := range []struct {
+		name   string
+		encode func(Value) (interface{}, error)
+	}{
+		{name: "legacy", encode: encodeValueLayout},
+		{name: "binary", encode: EncodeValue},
+	} {
+		encodedValues := make([]interface{}, 0, len(values))
+		for _, value := range values {
+			encoded, err := bench.encode(value)
+			if err != nil {
+				b.Fatal(err)
+			}
+			encodedValues = append(encodedValues, encoded)
+		}
+		b.Run(bench.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				for _, encoded := range encodedValues {
+					if _, err := DecodeValue(encoded); err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+		})
+	}
+}
+
+// assertStableEncoding checks that the decoded value is encoded to the same value after the round trip.
+// The values are compared by the formatted text because the encoded float value may be NaN.
+func assertStableEncoding(t *testing.T, value Value) {
+	t.Helper()
+	if value != nil {
+		_ = value.Interface()
+		_ = value.Format('t')
+	}
+	encoded, err := EncodeValue(value)
+	if err != nil {
+		t.Fatalf("failed to encode decoded value %T: %v", value, err)
+	}
+	decoded, err := DecodeValue(encoded)
+	if err != nil {
+		t.Fatalf("failed to decode encoded value %v: %v", encoded, err)
+	}
+	reencoded, err := EncodeValue(decoded)
+	if err != nil {
+		t.Fatalf("failed to encode decoded value %T: %v", decoded, err)
+	}
+	if fmt.Sprint(encoded) != fmt.Sprint(reencoded) {
+		t.Fatalf("unstable encoding of %T value: %v != %v", value, encoded, reencoded)
+	}
+}
+
+func FuzzDecodeValue(f *testing.F) {
+	for _, value := range testValues(f) {
+		encoded, err := EncodeValue(value)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(encoded.(string))
+		legacy, err := encodeValueLayout(value)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(legacy.(string))
+	}
+	f.Add(binaryValuePrefix)
+	f.Fuzz(func(t *testing.T, src string) {
+		value, err := DecodeValue(src)
+		if err != nil {
+			return
+		}
+		assertStableEncoding(t, value)
+	})
+}
+
+func FuzzBinaryValueDecoder(f *testing.F) {
+	for _, value := range testValues(f) {
+		encoded, err := EncodeValue(value)
+		if err != nil {
+			f.Fatal(err)
+		}
+		b, err := base64.RawStdEncoding.DecodeString(encoded.(string)[len(binaryValuePrefix):])
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(b[1:])
+	}
+	f.Add([]byte{byte(binaryNullValueTag)})
+	f.Fuzz(func(t *testing.T, b []byte) {
+		src := binaryValuePrefix + base64.RawStdEncoding.EncodeToString(
+			append([]byte{binaryValueEncodingVersion}, b...),
+		)
+		value, err := DecodeValue(src)
+		if err != nil {
+			return
+		}
+		assertStableEncoding(t, value)
+	})
+}
+
+func FuzzNumericValueEncoding(f *testing.F) {
+	for _, seed := range []string{"0", "123.45", "-1/3", "99999999999999999999999999999.999999999", "1e-38"} {
+		f.Add(seed, false)
+		f.Add(seed, true)
+	}
+	f.Fuzz(func(t *testing.T, text string, isBigNumeric bool) {
+		r, ok := new(big.Rat).SetString(text)
+		if !ok {
+			return
+		}
+		value := &NumericValue{Rat: r, isBigNumeric: isBigNumeric}
+		assertStableEncoding(t, value)
+		encoded, err := EncodeValue(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded, err := DecodeValue(encoded)
+		if err != nil {
+			t.Fatal(err)
+		}
+		eq, err := value.EQ(decoded)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !eq {
+			t.Fatalf("failed to decode numeric value %s: got %v", text, decoded)
+		}
+	})
+}
+
+func FuzzIntervalValueEncoding(f *testing.F) {
+	for _, seed := range []string{"0-0 0 0:0:0", "1-2 3 4:5:6.789", "-1-2 -3 -4:5:6.789", "10000-0 3660000 87840000:0:0", "0-0 0 -0:0:1.5", "P1Y2M3DT4H5M6.5S"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, text string) {
+		value, err := parseInterval(text)
+		if err != nil {
+			return
+		}
+		assertStableEncoding(t, value)
+	})
+}
+
+func FuzzJsonValueEncoding(f *testing.F) {
+	for _, seed := range []string{`null`, `{"a":[1,2,3]}`, `"text"`, `1.5`, `[null,{"b":null}]`} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, text string) {
+		value := JsonValue(text)
+		assertStableEncoding(t, value)
+		arr := &ArrayValue{values: []Value{value, nil}}
+		assertStableEncoding(t, arr)
+	})
+}
@@ -0,0 +1,91 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+
+	ast "github.com/goccy/go-zetasql/resolved_ast"
+	"github.com/goccy/go-zetasql/types"
+)
+
+// collationSensitiveFuncMap is a set of functions whose result depends on the collation of STRING arguments.
+var collationSensitiveFuncMap = map[string]struct{}{
+	"$equal":            {},
+	"$not_equal":        {},
+	"$less":             {},
+	"$less_or_equal":    {},
+	"$greater":          {},
+	"$greater_or_equal": {},
+	"$between":          {},
+	"$in":               {},
+}
+
+// functionCollation returns the collation specification used by the function call.
+// ZetaSQL sets the collation only for the expression that uses COLLATE function,
+// so the collation is also looked up from the column definitions of the referenced tables.
+func functionCollation(ctx context.Context, node *ast.BaseFunctionCallNode) string {
+	if _, exists := collationSensitiveFuncMap[node.Function().FullName(false)]; !exists {
+		return ""
+	}
+	for _, collation := range node.CollationList() {
+		if collation.HasCollation() {
+			return collation.CollationName()
+		}
+	}
+	for _, arg := range node.ArgumentList() {
+		if collation := columnRefCollation(ctx, arg); collation != "" {
+			return collation
+		}
+	}
+	return ""
+}
+
+// columnRefCollation returns the collation specification of the table column referenced by the expression.
+// If the expression isn't the reference to the table column, returns empty string.
+func columnRefCollation(ctx context.Context, expr ast.ExprNode) string {
+	ref, ok := expr.(*ast.ColumnRefNode)
+	if !ok {
+		return ""
+	}
+	analyzer := analyzerFromContext(ctx)
+	if analyzer == nil {
+		return ""
+	}
+	col := ref.Column()
+	return analyzer.catalog.columnCollation(col.TableName(), col.Name())
+}
+
+// groupByCollationKey returns the expression to group the column by.
+// The column of STRING type with the collation is grouped by the collation key,
+// so the values equal by the collation are in the same group ( this also applies to SELECT DISTINCT ).
+func groupByCollationKey(ctx context.Context, col *ast.ComputedColumnNode, column string) (string, error) {
+	if col.Expr().Type().Kind() != types.STRING {
+		return column, nil
+	}
+	collation := columnRefCollation(ctx, col.Expr())
+	if collation == "" {
+		return column, nil
+	}
+	spec, err := LiteralFromValue(StringValue(collation))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("zetasqlite_collation_key(%s, %s)", column, spec), nil
+}
+
+// applyCollation converts STRING arguments into the collation keys to compare them by the collation.
+func applyCollation(argNodes []ast.ExprNode, args []string, collation string) ([]string, error) {
+	spec, err := LiteralFromValue(StringValue(collation))
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]string, 0, len(args))
+	for idx, arg := range args {
+		if argNodes[idx].Type().Kind() != types.STRING {
+			ret = append(ret, arg)
+			continue
+		}
+		ret = append(ret, fmt.Sprintf("zetasqlite_collation_key(%s, %s)", arg, spec))
+	}
+	return ret, nil
+}
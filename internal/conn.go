@@ -41,9 +41,11 @@ func (f *ChangedFunction) Changed() bool {
 }
 
 type Conn struct {
-	conn *sql.Conn
-	tx   *sql.Tx
-	cc   *ChangedCatalog
+	conn   *sql.Conn
+	tx     *sql.Tx
+	cc     *ChangedCatalog
+	stats  []*QueryStatistics
+	labels map[string]string
 }
 
 func NewConn(conn *sql.Conn, tx *sql.Tx) *Conn {
@@ -75,12 +77,20 @@ func (c *Conn) QueryContext(ctx context.Context, query string, args ...interface
 	return c.conn.QueryContext(ctx, query, args...)
 }
 
+func (c *Conn) addStatistics(stats *QueryStatistics) {
+	stats.Labels = c.labels
+	c.stats = append(c.stats, stats)
+}
+
+func (c *Conn) setQueryLabels(labels map[string]string) {
+	c.labels = labels
+}
+
 func (c *Conn) addTable(spec *TableSpec) {
 	c.removeFromDeletedTablesIfExists(spec)
 	c.cc.Table.Added = append(c.cc.Table.Added, spec)
 }
 
-//nolint:unused
 func (c *Conn) updateTable(spec *TableSpec) {
 	c.cc.Table.Updated = append(c.cc.Table.Updated, spec)
 }
@@ -103,7 +113,7 @@ func (c *Conn) deleteFunction(spec *FunctionSpec) {
 func (c *Conn) removeFromDeletedTablesIfExists(spec *TableSpec) {
 	tables := make([]*TableSpec, 0, len(c.cc.Table.Deleted))
 	for _, table := range c.cc.Table.Deleted {
-		if table.TableName() == spec.TableName() {
+		if table.hasTableName(spec.TableName()) {
 			continue
 		}
 		tables = append(tables, table)
@@ -114,7 +124,7 @@ func (c *Conn) removeFromDeletedTablesIfExists(spec *TableSpec) {
 func (c *Conn) removeFromAddedTablesIfExists(spec *TableSpec) {
 	tables := make([]*TableSpec, 0, len(c.cc.Table.Added))
 	for _, table := range c.cc.Table.Added {
-		if table.TableName() == spec.TableName() {
+		if table.hasTableName(spec.TableName()) {
 			continue
 		}
 		tables = append(tables, table)
@@ -125,7 +135,7 @@ func (c *Conn) removeFromAddedTablesIfExists(spec *TableSpec) {
 func (c *Conn) removeFromDeletedFunctionsIfExists(spec *FunctionSpec) {
 	funcs := make([]*FunctionSpec, 0, len(c.cc.Function.Deleted))
 	for _, fun := range c.cc.Function.Deleted {
-		if fun.FuncName() == spec.FuncName() {
+		if nameKey(fun.FuncName()) == nameKey(spec.FuncName()) {
 			continue
 		}
 		funcs = append(funcs, fun)
@@ -136,7 +146,7 @@ func (c *Conn) removeFromDeletedFunctionsIfExists(spec *FunctionSpec) {
 func (c *Conn) removeFromAddedFunctionsIfExists(spec *FunctionSpec) {
 	funcs := make([]*FunctionSpec, 0, len(c.cc.Function.Added))
 	for _, fun := range c.cc.Function.Added {
-		if fun.FuncName() == spec.FuncName() {
+		if nameKey(fun.FuncName()) == nameKey(spec.FuncName()) {
 			continue
 		}
 		funcs = append(funcs, fun)
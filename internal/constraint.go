@@ -0,0 +1,129 @@
+package internal
+
+import (
+	"fmt"
+
+	ast "github.com/goccy/go-zetasql/resolved_ast"
+)
+
+// ConstraintKind is the kind of the table constraint.
+type ConstraintKind string
+
+const (
+	ConstraintKindPrimaryKey ConstraintKind = "PRIMARY KEY"
+	ConstraintKindForeignKey ConstraintKind = "FOREIGN KEY"
+	ConstraintKindNotNull    ConstraintKind = "NOT NULL"
+)
+
+// ForeignKeySpec is the foreign key declared by CREATE TABLE.
+// Like BigQuery, the foreign key must be declared with NOT ENFORCED, so the referenced rows are not checked.
+type ForeignKeySpec struct {
+	// Name is the name specified by CONSTRAINT name FOREIGN KEY. It's empty if the constraint isn't named.
+	Name    string   `json:"name"`
+	Columns []string `json:"columns"`
+	// ReferencedTable is the name path of the referenced table.
+	ReferencedTable   []string `json:"referencedTable"`
+	ReferencedColumns []string `json:"referencedColumns"`
+}
+
+// ConstraintSpec is the constraint of the table listed by TableSpec.Constraints.
+type ConstraintSpec struct {
+	Kind ConstraintKind
+	// Name is the name of the constraint. It's empty if the constraint isn't named.
+	Name    string
+	Columns []string
+	// ReferencedTable and ReferencedColumns are specified only for FOREIGN KEY.
+	ReferencedTable   []string
+	ReferencedColumns []string
+	// Enforced reports whether the constraint is checked when the rows are inserted or updated.
+	Enforced bool
+}
+
+// Constraints lists the constraints of the table in the order of PRIMARY KEY, FOREIGN KEY and NOT NULL of the columns.
+// The constraints of the table created by CREATE TABLE AS SELECT are not enforced,
+// because the SQLite table is created from the result of the query without the constraints.
+func (s *TableSpec) Constraints() []*ConstraintSpec {
+	var constraints []*ConstraintSpec
+	isCreatedFromQuery := s.Query != ""
+	if len(s.PrimaryKey) != 0 {
+		constraints = append(constraints, &ConstraintSpec{
+			Kind:     ConstraintKindPrimaryKey,
+			Columns:  s.PrimaryKey,
+			Enforced: !s.IsPrimaryKeyUnenforced && !isCreatedFromQuery,
+		})
+	}
+	for _, key := range s.ForeignKeys {
+		constraints = append(constraints, &ConstraintSpec{
+			Kind:              ConstraintKindForeignKey,
+			Name:              key.Name,
+			Columns:           key.Columns,
+			ReferencedTable:   key.ReferencedTable,
+			ReferencedColumns: key.ReferencedColumns,
+		})
+	}
+	for _, col := range s.Columns {
+		if !col.IsNotNull {
+			continue
+		}
+		constraints = append(constraints, &ConstraintSpec{
+			Kind:     ConstraintKindNotNull,
+			Columns:  []string{col.Name},
+			Enforced: !isCreatedFromQuery,
+		})
+	}
+	return constraints
+}
+
+func isUnenforcedPrimaryKey(key *ast.PrimaryKeyNode) bool {
+	return key != nil && key.Unenforced()
+}
+
+func (c *Catalog) newForeignKeys(namePath *NamePath, keys []*ast.ForeignKeyNode) ([]*ForeignKeySpec, error) {
+	var ret []*ForeignKeySpec
+	for _, key := range keys {
+		if key.Enforced() {
+			return nil, fmt.Errorf("FOREIGN KEY must be declared with NOT ENFORCED")
+		}
+		table := key.ReferencedTable()
+		var referencedColumns []string
+		for _, offset := range key.ReferencedColumnOffsetList() {
+			referencedColumns = append(referencedColumns, table.Column(offset).Name())
+		}
+		ret = append(ret, &ForeignKeySpec{
+			Name:              key.ConstraintName(),
+			Columns:           key.ReferencingColumnList(),
+			ReferencedTable:   c.referencedTableNamePath(namePath, table.Name()),
+			ReferencedColumns: referencedColumns,
+		})
+	}
+	return ret, nil
+}
+
+// referencedTableNamePath returns the name path of the table referenced by the foreign key.
+// The tables are registered to the ZetaSQL catalog by the last name of the path too,
+// so the name of the referenced table may not contain the whole path.
+func (c *Catalog) referencedTableNamePath(namePath *NamePath, tableName string) []string {
+	path := namePath.mergePath([]string{tableName})
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.lookupTableSpec(formatPath(path)) != nil {
+		return path
+	}
+	var found *TableSpec
+	for _, spec := range c.tables {
+		if spec.NamePath[len(spec.NamePath)-1] != tableName {
+			continue
+		}
+		if found != nil {
+			// ambiguous name.
+			return path
+		}
+		found = spec
+	}
+	if found == nil {
+		return path
+	}
+	return found.NamePath
+}
@@ -16,12 +16,22 @@ type (
 	funcMapKey                      struct{}
 	analyticOrderColumnNamesKey     struct{}
 	analyticPartitionColumnNamesKey struct{}
+	analyticFunctionGroupKey        struct{}
 	analyticInputScanKey            struct{}
 	arraySubqueryColumnNameKey      struct{}
 	currentTimeKey                  struct{}
+	preparedStatementKey            struct{}
+	queryLabelsKey                  struct{}
+	letExprColumnMapKey             struct{}
 	tableNameToColumnListMapKey     struct{}
+	hoistedWithEntriesKey           struct{}
+	recursiveQueryNameKey           struct{}
 	useColumnIDKey                  struct{}
 	useTableNameForColumnKey        struct{}
+	typeParametersColumnMapKey      struct{}
+	columnDefaultValueMapKey        struct{}
+	columnDefaultCurrentTimeKey     struct{}
+	functionCallKey                 struct{}
 )
 
 func analyzerFromContext(ctx context.Context) *Analyzer {
@@ -84,6 +94,18 @@ func funcMapFromContext(ctx context.Context) map[string]*FunctionSpec {
 	return value.(map[string]*FunctionSpec)
 }
 
+func withLetExprColumnMap(ctx context.Context, m map[string]string) context.Context {
+	return context.WithValue(ctx, letExprColumnMapKey{}, m)
+}
+
+func letExprColumnMapFromContext(ctx context.Context) map[string]string {
+	value := ctx.Value(letExprColumnMapKey{})
+	if value == nil {
+		return nil
+	}
+	return value.(map[string]string)
+}
+
 type analyticOrderBy struct {
 	column string
 	isAsc  bool
@@ -117,6 +139,18 @@ func analyticPartitionColumnNamesFromContext(ctx context.Context) []string {
 	return value.([]string)
 }
 
+func withAnalyticFunctionGroup(ctx context.Context, group *ast.AnalyticFunctionGroupNode) context.Context {
+	return context.WithValue(ctx, analyticFunctionGroupKey{}, group)
+}
+
+func analyticFunctionGroupFromContext(ctx context.Context) *ast.AnalyticFunctionGroupNode {
+	value := ctx.Value(analyticFunctionGroupKey{})
+	if value == nil {
+		return nil
+	}
+	return value.(*ast.AnalyticFunctionGroupNode)
+}
+
 func withAnalyticInputScan(ctx context.Context, input string) context.Context {
 	return context.WithValue(ctx, analyticInputScanKey{}, input)
 }
@@ -185,10 +219,64 @@ func tableNameToColumnListMap(ctx context.Context) map[string][]*ast.Column {
 	return value.(map[string][]*ast.Column)
 }
 
+// hoistedWithEntries is the list of WITH entries collected by the outermost WITH clause.
+type hoistedWithEntries struct {
+	values    []string
+	names     map[string]struct{}
+	recursive bool
+}
+
+func withHoistedWithEntries(ctx context.Context, v *hoistedWithEntries) context.Context {
+	return context.WithValue(ctx, hoistedWithEntriesKey{}, v)
+}
+
+func hoistedWithEntriesFromContext(ctx context.Context) *hoistedWithEntries {
+	value := ctx.Value(hoistedWithEntriesKey{})
+	if value == nil {
+		return nil
+	}
+	return value.(*hoistedWithEntries)
+}
+
+// withRecursiveQueryName sets the name of the recursive WITH entry being formatted.
+// RecursiveRefScanNode doesn't have the name of the referenced entry, so it's passed through the context.
+func withRecursiveQueryName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, recursiveQueryNameKey{}, name)
+}
+
+func recursiveQueryNameFromContext(ctx context.Context) string {
+	value := ctx.Value(recursiveQueryNameKey{})
+	if value == nil {
+		return ""
+	}
+	return value.(string)
+}
+
 func WithCurrentTime(ctx context.Context, now time.Time) context.Context {
 	return context.WithValue(ctx, currentTimeKey{}, &now)
 }
 
+// withoutCurrentTime removes the current time fixed for the statement.
+// The current time functions formatted with the returned context are evaluated every time they are called.
+func withoutCurrentTime(ctx context.Context) context.Context {
+	return context.WithValue(ctx, currentTimeKey{}, (*time.Time)(nil))
+}
+
+// withColumnDefaultCurrentTime removes the current time fixed for the statement like withoutCurrentTime,
+// and passes the time returned by zetasqlite_current_time_unix_nano() to the current time functions instead.
+// The DML statement replaces the function call with the current time of the statement ( see bindColumnDefaultValue ).
+func withColumnDefaultCurrentTime(ctx context.Context) context.Context {
+	return context.WithValue(withoutCurrentTime(ctx), columnDefaultCurrentTimeKey{}, true)
+}
+
+func isColumnDefaultCurrentTime(ctx context.Context) bool {
+	value := ctx.Value(columnDefaultCurrentTimeKey{})
+	if value == nil {
+		return false
+	}
+	return value.(bool)
+}
+
 func CurrentTime(ctx context.Context) *time.Time {
 	value := ctx.Value(currentTimeKey{})
 	if value == nil {
@@ -196,3 +284,68 @@ func CurrentTime(ctx context.Context) *time.Time {
 	}
 	return value.(*time.Time)
 }
+
+// WithQueryLabels attaches the labels to the queries executed with the returned context.
+// The labels are merged with the labels specified by SET @@query_label and override the labels of the same keys.
+func WithQueryLabels(ctx context.Context, labels map[string]string) context.Context {
+	return context.WithValue(ctx, queryLabelsKey{}, labels)
+}
+
+// QueryLabels gets the labels specified by WithQueryLabels.
+func QueryLabels(ctx context.Context) map[string]string {
+	value := ctx.Value(queryLabelsKey{})
+	if value == nil {
+		return nil
+	}
+	return value.(map[string]string)
+}
+
+// WithPreparedStatement marks that the statement is analyzed to be prepared and executed repeatedly.
+// For prepared statements, the current time cannot be fixed at the analysis.
+func WithPreparedStatement(ctx context.Context) context.Context {
+	return context.WithValue(ctx, preparedStatementKey{}, true)
+}
+
+func isPreparedStatement(ctx context.Context) bool {
+	value := ctx.Value(preparedStatementKey{})
+	if value == nil {
+		return false
+	}
+	return value.(bool)
+}
+
+func withTypeParametersColumnMap(ctx context.Context, m map[string]*ColumnSpec) context.Context {
+	return context.WithValue(ctx, typeParametersColumnMapKey{}, m)
+}
+
+func typeParametersColumnMapFromContext(ctx context.Context) map[string]*ColumnSpec {
+	value := ctx.Value(typeParametersColumnMapKey{})
+	if value == nil {
+		return nil
+	}
+	return value.(map[string]*ColumnSpec)
+}
+
+func withColumnDefaultValueMap(ctx context.Context, m map[string]string) context.Context {
+	return context.WithValue(ctx, columnDefaultValueMapKey{}, m)
+}
+
+func columnDefaultValueMapFromContext(ctx context.Context) map[string]string {
+	value := ctx.Value(columnDefaultValueMapKey{})
+	if value == nil {
+		return nil
+	}
+	return value.(map[string]string)
+}
+
+func withFunctionCall(ctx context.Context, call *functionCall) context.Context {
+	return context.WithValue(ctx, functionCallKey{}, call)
+}
+
+func functionCallFromContext(ctx context.Context) *functionCall {
+	value := ctx.Value(functionCallKey{})
+	if value == nil {
+		return nil
+	}
+	return value.(*functionCall)
+}
@@ -2,9 +2,13 @@ package internal
 
 import (
 	"encoding/base64"
+	"encoding/binary"
 	"fmt"
+	"io"
+	"math"
 	"math/big"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/goccy/go-json"
@@ -26,6 +30,9 @@ func DecodeValue(v interface{}) (Value, error) {
 	if !ok {
 		return nil, fmt.Errorf("unexpected value type: %T", v)
 	}
+	if strings.HasPrefix(s, binaryValuePrefix) {
+		return decodeBinaryValue(s)
+	}
 	decoded, err := base64.StdEncoding.DecodeString(s)
 	if err != nil {
 		return nil, fmt.Errorf("failed to decode value: %w", err)
@@ -75,13 +82,10 @@ func decodeFromValueLayout(layout *ValueLayout) (Value, error) {
 		return TimeValue(t), nil
 	case TimestampValueType:
 		microsec, err := strconv.ParseInt(layout.Body, 10, 64)
-		microSecondsInSecond := int64(time.Second) / int64(time.Microsecond)
-		sec := microsec / microSecondsInSecond
-		remainder := microsec - (sec * microSecondsInSecond)
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse unixmicro for timestamp value %s: %w", layout.Body, err)
 		}
-		return TimestampValue(time.Unix(sec, remainder*int64(time.Microsecond))), nil
+		return timestampValueFromUnixMicro(microsec), nil
 	case IntervalValueType:
 		return parseInterval(layout.Body)
 	case JsonValueType:
@@ -125,3 +129,195 @@ func decodeFromValueLayout(layout *ValueLayout) (Value, error) {
 	}
 	return nil, fmt.Errorf("unexpected value header: %s", layout.Header)
 }
+
+func timestampValueFromUnixMicro(microsec int64) TimestampValue {
+	microSecondsInSecond := int64(time.Second) / int64(time.Microsecond)
+	sec := microsec / microSecondsInSecond
+	remainder := microsec - (sec * microSecondsInSecond)
+	return TimestampValue(time.Unix(sec, remainder*int64(time.Microsecond)))
+}
+
+func decodeBinaryValue(s string) (Value, error) {
+	b, err := base64.RawStdEncoding.DecodeString(s[len(binaryValuePrefix):])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode value: %w", err)
+	}
+	if len(b) == 0 {
+		return nil, fmt.Errorf("failed to decode value: empty binary value")
+	}
+	if b[0] != binaryValueEncodingVersion {
+		return nil, fmt.Errorf("failed to decode value: unsupported binary value encoding version %d", b[0])
+	}
+	d := &binaryValueDecoder{buf: b[1:]}
+	value, err := d.decode()
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode value: %w", err)
+	}
+	if len(d.buf) != 0 {
+		return nil, fmt.Errorf("failed to decode value: found %d bytes after the value", len(d.buf))
+	}
+	return value, nil
+}
+
+type binaryValueDecoder struct {
+	buf []byte
+}
+
+func (d *binaryValueDecoder) readByte() (byte, error) {
+	if len(d.buf) == 0 {
+		return 0, io.ErrUnexpectedEOF
+	}
+	c := d.buf[0]
+	d.buf = d.buf[1:]
+	return c, nil
+}
+
+func (d *binaryValueDecoder) readVarint() (int64, error) {
+	v, n := binary.Varint(d.buf)
+	if n <= 0 {
+		return 0, fmt.Errorf("invalid varint")
+	}
+	d.buf = d.buf[n:]
+	return v, nil
+}
+
+func (d *binaryValueDecoder) readUvarint() (uint64, error) {
+	v, n := binary.Uvarint(d.buf)
+	if n <= 0 {
+		return 0, fmt.Errorf("invalid uvarint")
+	}
+	d.buf = d.buf[n:]
+	return v, nil
+}
+
+func (d *binaryValueDecoder) readString() (string, error) {
+	length, err := d.readUvarint()
+	if err != nil {
+		return "", err
+	}
+	if uint64(len(d.buf)) < length {
+		return "", io.ErrUnexpectedEOF
+	}
+	s := string(d.buf[:length])
+	d.buf = d.buf[length:]
+	return s, nil
+}
+
+func (d *binaryValueDecoder) decode() (Value, error) {
+	tag, err := d.readByte()
+	if err != nil {
+		return nil, err
+	}
+	switch binaryValueTag(tag) {
+	case binaryNullValueTag:
+		return nil, nil
+	case binaryIntValueTag:
+		v, err := d.readVarint()
+		if err != nil {
+			return nil, err
+		}
+		return IntValue(v), nil
+	case binaryFloatValueTag:
+		if len(d.buf) < 8 {
+			return nil, io.ErrUnexpectedEOF
+		}
+		v := math.Float64frombits(binary.BigEndian.Uint64(d.buf))
+		d.buf = d.buf[8:]
+		return FloatValue(v), nil
+	case binaryBoolValueTag:
+		v, err := d.readByte()
+		if err != nil {
+			return nil, err
+		}
+		return BoolValue(v != 0), nil
+	case binaryTimestampValueTag:
+		v, err := d.readVarint()
+		if err != nil {
+			return nil, err
+		}
+		return timestampValueFromUnixMicro(v), nil
+	case binaryArrayValueTag:
+		length, err := d.readUvarint()
+		if err != nil {
+			return nil, err
+		}
+		if uint64(len(d.buf)) < length {
+			return nil, io.ErrUnexpectedEOF
+		}
+		ret := &ArrayValue{values: make([]Value, 0, length)}
+		for i := uint64(0); i < length; i++ {
+			value, err := d.decode()
+			if err != nil {
+				return nil, err
+			}
+			ret.values = append(ret.values, value)
+		}
+		return ret, nil
+	case binaryStructValueTag:
+		length, err := d.readUvarint()
+		if err != nil {
+			return nil, err
+		}
+		if uint64(len(d.buf)) < length {
+			return nil, io.ErrUnexpectedEOF
+		}
+		ret := &StructValue{
+			keys:   make([]string, 0, length),
+			values: make([]Value, 0, length),
+			m:      make(map[string]Value, length),
+		}
+		for i := uint64(0); i < length; i++ {
+			key, err := d.readString()
+			if err != nil {
+				return nil, err
+			}
+			value, err := d.decode()
+			if err != nil {
+				return nil, err
+			}
+			ret.keys = append(ret.keys, key)
+			ret.values = append(ret.values, value)
+			ret.m[key] = value
+		}
+		return ret, nil
+	}
+	text, err := d.readString()
+	if err != nil {
+		return nil, err
+	}
+	switch binaryValueTag(tag) {
+	case binaryStringValueTag:
+		return StringValue(text), nil
+	case binaryBytesValueTag:
+		return BytesValue(text), nil
+	case binaryNumericValueTag, binaryBigNumericValueTag:
+		r, ok := new(big.Rat).SetString(text)
+		if !ok {
+			return nil, fmt.Errorf("invalid numeric value %s", text)
+		}
+		return &NumericValue{Rat: r, isBigNumeric: binaryValueTag(tag) == binaryBigNumericValueTag}, nil
+	case binaryDateValueTag:
+		t, err := parseDate(text)
+		if err != nil {
+			return nil, err
+		}
+		return DateValue(t), nil
+	case binaryDatetimeValueTag:
+		t, err := parseDatetime(text)
+		if err != nil {
+			return nil, err
+		}
+		return DatetimeValue(t), nil
+	case binaryTimeValueTag:
+		t, err := parseTime(text)
+		if err != nil {
+			return nil, err
+		}
+		return TimeValue(t), nil
+	case binaryIntervalValueTag:
+		return parseInterval(text)
+	case binaryJsonValueTag:
+		return JsonValue(text), nil
+	}
+	return nil, fmt.Errorf("unexpected binary value tag %d", tag)
+}
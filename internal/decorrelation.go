@@ -0,0 +1,227 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	ast "github.com/goccy/go-zetasql/resolved_ast"
+	"github.com/goccy/go-zetasql/types"
+)
+
+// decorrelationKeyTypeKindMap is a set of types whose encoded values are equal only if the values are equal.
+// Only the equality of these types can be replaced by the comparison of encoded values.
+var decorrelationKeyTypeKindMap = map[types.TypeKind]struct{}{
+	types.INT64:  {},
+	types.BOOL:   {},
+	types.STRING: {},
+	types.BYTES:  {},
+	types.DATE:   {},
+}
+
+// correlatedEqualKey is the equality condition between the column of the outer query and the expression of the subquery.
+type correlatedEqualKey struct {
+	outer *ast.ColumnRefNode
+	inner ast.ExprNode
+}
+
+// decorrelateSubquery rewrites the correlated EXISTS or IN subquery to the uncorrelated IN subquery.
+// SQLite evaluates the correlated subquery for each row of the outer query,
+// but the uncorrelated IN subquery is evaluated only once and looked up by the index built for the result.
+// The subquery is rewritten only if it is a simple filter whose correlated conditions are equalities with the outer columns.
+// For example, EXISTS (SELECT 1 FROM t2 WHERE t2.id = t1.id AND t2.v > 0) is formatted to
+// CASE WHEN t1.id IS NULL THEN 0 ELSE t1.id IN (SELECT t2.id FROM t2 WHERE t2.v > 0 AND t2.id IS NOT NULL) END.
+// If the subquery cannot be rewritten, returns false.
+func decorrelateSubquery(ctx context.Context, node *ast.SubqueryExprNode) (string, bool, error) {
+	analyzer := analyzerFromContext(ctx)
+	if analyzer == nil || analyzer.isSubqueryDecorrelationDisabled {
+		return "", false, nil
+	}
+	switch node.SubqueryType() {
+	case ast.SubqueryTypeExists:
+	case ast.SubqueryTypeIn:
+		// STRUCT values are compared by zetasqlite_equal instead of the encoded values.
+		if node.InExpr().Type().Kind() == types.STRUCT {
+			return "", false, nil
+		}
+	default:
+		return "", false, nil
+	}
+	if len(node.ParameterList()) == 0 {
+		return "", false, nil
+	}
+	project, ok := node.Subquery().(*ast.ProjectScanNode)
+	if !ok {
+		return "", false, nil
+	}
+	filter, ok := project.InputScan().(*ast.FilterScanNode)
+	if !ok {
+		return "", false, nil
+	}
+	if hasCorrelatedColumnRef(filter.InputScan()) {
+		return "", false, nil
+	}
+	isIn := node.SubqueryType() == ast.SubqueryTypeIn
+	if isIn {
+		if len(project.ColumnList()) != 1 {
+			return "", false, nil
+		}
+		for _, expr := range project.ExprList() {
+			if hasCorrelatedColumnRef(expr) {
+				return "", false, nil
+			}
+		}
+	}
+	var (
+		keys       []*correlatedEqualKey
+		conditions []ast.ExprNode
+	)
+	for _, cond := range conjunctionExprs(filter.FilterExpr()) {
+		if !hasCorrelatedColumnRef(cond) {
+			conditions = append(conditions, cond)
+			continue
+		}
+		key := correlatedEqualKeyFromExpr(ctx, cond)
+		if key == nil {
+			return "", false, nil
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return "", false, nil
+	}
+
+	var (
+		inValue string
+		err     error
+	)
+	if isIn {
+		inValue, err = newNode(node.InExpr()).FormatSQL(ctx)
+		if err != nil {
+			return "", false, err
+		}
+		for _, col := range project.ExprList() {
+			// assign expr to columnRefMap
+			if _, err := newNode(col).FormatSQL(ctx); err != nil {
+				return "", false, err
+			}
+		}
+	}
+	input, err := newNode(filter.InputScan()).FormatSQL(ctx)
+	if err != nil {
+		return "", false, err
+	}
+	var (
+		outerKeys   []string
+		innerKeys   []string
+		nullChecks  []string
+		whereClause []string
+	)
+	for _, cond := range conditions {
+		expr, err := newNode(cond).FormatSQL(ctx)
+		if err != nil {
+			return "", false, err
+		}
+		whereClause = append(whereClause, expr)
+	}
+	for _, key := range keys {
+		outer, err := newNode(key.outer).FormatSQL(ctx)
+		if err != nil {
+			return "", false, err
+		}
+		inner, err := newNode(key.inner).FormatSQL(ctx)
+		if err != nil {
+			return "", false, err
+		}
+		outerKeys = append(outerKeys, outer)
+		innerKeys = append(innerKeys, inner)
+		nullChecks = append(nullChecks, fmt.Sprintf("%s IS NULL", outer))
+		// the rows that have NULL keys never match the outer row.
+		whereClause = append(whereClause, fmt.Sprintf("%s IS NOT NULL", inner))
+	}
+	if isIn {
+		colName := uniqueColumnName(ctx, project.ColumnList()[0])
+		inColumn := fmt.Sprintf("`%s`", colName)
+		if ref, exists := columnRefMap(ctx)[colName]; exists {
+			inColumn = ref
+		}
+		outerKeys = append([]string{inValue}, outerKeys...)
+		innerKeys = append([]string{inColumn}, innerKeys...)
+	}
+	subquery, err := formatInput(formatFilterScan(input, strings.Join(whereClause, " AND ")))
+	if err != nil {
+		return "", false, err
+	}
+	outerExpr := outerKeys[0]
+	if len(outerKeys) > 1 {
+		outerExpr = fmt.Sprintf("(%s)", strings.Join(outerKeys, ","))
+	}
+	// the correlated subquery matches no rows if the key of the outer row is NULL,
+	// so EXISTS returns FALSE and IN returns FALSE even if the left operand is NULL.
+	return fmt.Sprintf(
+		"CASE WHEN %s THEN 0 ELSE %s IN (SELECT %s %s) END",
+		strings.Join(nullChecks, " OR "),
+		outerExpr,
+		strings.Join(innerKeys, ","),
+		subquery,
+	), true, nil
+}
+
+// conjunctionExprs splits the expression combined by AND into the list of expressions.
+func conjunctionExprs(expr ast.ExprNode) []ast.ExprNode {
+	fn, ok := expr.(*ast.FunctionCallNode)
+	if !ok || fn.Function().FullName(false) != "$and" {
+		return []ast.ExprNode{expr}
+	}
+	var exprs []ast.ExprNode
+	for _, arg := range fn.ArgumentList() {
+		exprs = append(exprs, conjunctionExprs(arg)...)
+	}
+	return exprs
+}
+
+// correlatedEqualKeyFromExpr returns the key if expr is the equality between the correlated column and the uncorrelated expression.
+func correlatedEqualKeyFromExpr(ctx context.Context, expr ast.ExprNode) *correlatedEqualKey {
+	fn, ok := expr.(*ast.FunctionCallNode)
+	if !ok || fn.Function().FullName(false) != "$equal" {
+		return nil
+	}
+	if functionCollation(ctx, fn.BaseFunctionCallNode) != "" {
+		return nil
+	}
+	args := fn.ArgumentList()
+	if len(args) != 2 {
+		return nil
+	}
+	for i, arg := range args {
+		ref, ok := arg.(*ast.ColumnRefNode)
+		if !ok || !ref.IsCorrelated() {
+			continue
+		}
+		inner := args[1-i]
+		if hasCorrelatedColumnRef(inner) {
+			return nil
+		}
+		if _, exists := decorrelationKeyTypeKindMap[ref.Type().Kind()]; !exists {
+			return nil
+		}
+		if ref.Type().Kind() != inner.Type().Kind() {
+			return nil
+		}
+		return &correlatedEqualKey{outer: ref, inner: inner}
+	}
+	return nil
+}
+
+// hasCorrelatedColumnRef reports whether the node refers to the columns of the outer query.
+// The columns referred by the nested subqueries are also considered.
+func hasCorrelatedColumnRef(node ast.Node) bool {
+	var found bool
+	_ = ast.Walk(node, func(n ast.Node) error {
+		if ref, ok := n.(*ast.ColumnRefNode); ok && ref.IsCorrelated() {
+			found = true
+		}
+		return nil
+	})
+	return found
+}
@@ -0,0 +1,187 @@
+package internal
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/goccy/go-json"
+	"github.com/goccy/go-zetasql"
+	"github.com/goccy/go-zetasql/types"
+)
+
+// SetDefaultParameters predefines the named parameters ( e.g. @run_date of scheduled queries ) for all queries.
+// The parameters are declared with the type of the value,
+// and the values are used when the arguments of the query don't contain the parameter of the same name.
+func (a *Analyzer) SetDefaultParameters(params map[string]interface{}) error {
+	defaultParams := make([]*defaultParameter, 0, len(params))
+	for name, v := range params {
+		typ, err := parameterValueType(v)
+		if err != nil {
+			return fmt.Errorf("failed to get type of default parameter @%s: %w", name, err)
+		}
+		if typ == nil {
+			value, err := ValueFromGoValue(v)
+			if err != nil {
+				return fmt.Errorf("failed to convert default parameter @%s: %w", name, err)
+			}
+			typ, err = defaultParameterType(value)
+			if err != nil {
+				return fmt.Errorf("failed to get type of default parameter @%s: %w", name, err)
+			}
+		}
+		defaultParams = append(defaultParams, &defaultParameter{
+			name:  strings.ToLower(name),
+			value: v,
+			typ:   typ,
+		})
+	}
+	sort.Slice(defaultParams, func(i, j int) bool {
+		return defaultParams[i].name < defaultParams[j].name
+	})
+	a.defaultParams = defaultParams
+	return nil
+}
+
+type defaultParameter struct {
+	name  string
+	value interface{}
+	typ   types.Type
+}
+
+func defaultParameterType(v Value) (types.Type, error) {
+	switch v.(type) {
+	case IntValue:
+		return types.Int64Type(), nil
+	case FloatValue:
+		return types.DoubleType(), nil
+	case BoolValue:
+		return types.BoolType(), nil
+	case StringValue:
+		return types.StringType(), nil
+	case BytesValue:
+		return types.BytesType(), nil
+	case DateValue:
+		return types.DateType(), nil
+	case DatetimeValue:
+		return types.DatetimeType(), nil
+	case TimeValue:
+		return types.TimeType(), nil
+	case TimestampValue:
+		return types.TimestampType(), nil
+	case *NumericValue:
+		return types.NumericType(), nil
+	}
+	return nil, fmt.Errorf("unsupported default parameter value %T", v)
+}
+
+// declareDefaultParameters declares the types of default parameters to the analyzer.
+// Named parameters cannot be declared in positional parameter mode, so they are declared only in named parameter mode.
+func (a *Analyzer) declareDefaultParameters(mode zetasql.ParameterMode) error {
+	a.opt.ClearQueryParameters()
+	if mode != zetasql.ParameterNamed {
+		return nil
+	}
+	for _, param := range a.defaultParams {
+		if err := a.opt.AddQueryParameter(param.name, param.typ); err != nil {
+			return fmt.Errorf("failed to declare default parameter @%s: %w", param.name, err)
+		}
+	}
+	return nil
+}
+
+// declareParameterTypes declares the named parameters whose types cannot be determined by the context.
+// The values of *big.Rat are declared as NUMERIC and the values of json.RawMessage are declared as JSON.
+// The structs and the slices of structs are declared as STRUCT and ARRAY<STRUCT> by the types of the fields,
+// so that they can be used without the context like UNNEST(@rows).
+// The type of an undeclared parameter is INT64 if the context doesn't determine it ( e.g. SELECT @p ),
+// so the value would be truncated to an integer or fail to be converted without the declaration.
+// These are the same types as the parameters of cloud.google.com/go/bigquery.
+func (a *Analyzer) declareParameterTypes(mode zetasql.ParameterMode, args []driver.NamedValue) error {
+	if mode != zetasql.ParameterNamed {
+		return nil
+	}
+	declared := map[string]struct{}{}
+	for _, param := range a.defaultParams {
+		declared[param.name] = struct{}{}
+	}
+	for _, arg := range args {
+		name := strings.ToLower(arg.Name)
+		if name == "" {
+			continue
+		}
+		if _, exists := declared[name]; exists {
+			continue
+		}
+		typ, err := parameterValueType(arg.Value)
+		if err != nil {
+			return fmt.Errorf("failed to get type of parameter @%s: %w", name, err)
+		}
+		if typ == nil {
+			continue
+		}
+		if err := a.opt.AddQueryParameter(name, typ); err != nil {
+			return fmt.Errorf("failed to declare parameter @%s: %w", name, err)
+		}
+		declared[name] = struct{}{}
+	}
+	return nil
+}
+
+// parameterValueType returns the type of the parameter value that must be declared.
+// It returns nil if the type of the value can be determined by the context.
+func parameterValueType(v interface{}) (types.Type, error) {
+	switch vv := v.(type) {
+	case *big.Rat:
+		if vv != nil {
+			return types.NumericType(), nil
+		}
+		return nil, nil
+	case big.Rat:
+		return types.NumericType(), nil
+	case json.RawMessage:
+		if vv != nil {
+			return types.JsonType(), nil
+		}
+		return nil, nil
+	case driver.Valuer:
+		// e.g. zetasqlite.NullNumeric
+		value, err := vv.Value()
+		if err != nil {
+			return nil, nil
+		}
+		return parameterValueType(value)
+	}
+	if isStructParameterValue(v) {
+		return goValueType(reflect.TypeOf(v))
+	}
+	return nil, nil
+}
+
+// argsWithDefaultParameters appends the values of default parameters not contained in args.
+func (a *Analyzer) argsWithDefaultParameters(args []driver.NamedValue) []driver.NamedValue {
+	if len(a.defaultParams) == 0 {
+		return args
+	}
+	nameMap := map[string]struct{}{}
+	for _, arg := range args {
+		if arg.Name != "" {
+			nameMap[strings.ToLower(arg.Name)] = struct{}{}
+		}
+	}
+	ret := append([]driver.NamedValue{}, args...)
+	for _, param := range a.defaultParams {
+		if _, exists := nameMap[param.name]; exists {
+			continue
+		}
+		ret = append(ret, driver.NamedValue{
+			Name:    param.name,
+			Ordinal: len(ret) + 1,
+			Value:   param.value,
+		})
+	}
+	return ret
+}
@@ -0,0 +1,124 @@
+package internal
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+
+	ast "github.com/goccy/go-zetasql/resolved_ast"
+	"github.com/goccy/go-zetasql/types"
+)
+
+// describeOutputColumns is the result set of DESCRIBE statement.
+// The names are the same as the columns of INFORMATION_SCHEMA.COLUMN_FIELD_PATHS in BigQuery.
+var describeOutputColumns = []*ColumnSpec{
+	{Name: "column_name", Type: newType(types.StringType())},
+	{Name: "data_type", Type: newType(types.StringType())},
+	{Name: "is_nullable", Type: newType(types.StringType())},
+	{Name: "description", Type: newType(types.StringType())},
+}
+
+func (a *Analyzer) newDescribeStmtAction(_ context.Context, _ string, _ []driver.NamedValue, node *ast.DescribeStmtNode) (*DescribeStmtAction, error) {
+	if objectType := node.ObjectType(); objectType != "" && !strings.EqualFold(objectType, "TABLE") {
+		return nil, fmt.Errorf("DESCRIBE %s is not supported", objectType)
+	}
+	if len(node.FromNamePath()) != 0 {
+		return nil, fmt.Errorf("DESCRIBE with FROM clause is not supported")
+	}
+	return &DescribeStmtAction{
+		name:    a.namePath.format(node.NamePath()),
+		catalog: a.catalog,
+	}, nil
+}
+
+// DescribeStmtAction returns the columns of the table with their types and descriptions.
+type DescribeStmtAction struct {
+	name    string
+	catalog *Catalog
+}
+
+func (a *DescribeStmtAction) Prepare(ctx context.Context, conn *Conn) (driver.Stmt, error) {
+	return nil, nil
+}
+
+func (a *DescribeStmtAction) ExecContext(ctx context.Context, conn *Conn) (driver.Result, error) {
+	if _, err := a.tableSpec(); err != nil {
+		return nil, err
+	}
+	return &Result{conn: conn}, nil
+}
+
+func (a *DescribeStmtAction) QueryContext(ctx context.Context, conn *Conn) (*Rows, error) {
+	spec, err := a.tableSpec()
+	if err != nil {
+		return nil, err
+	}
+	query, err := a.query(spec)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := conn.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe table %s: %w", a.name, err)
+	}
+	return &Rows{conn: conn, rows: rows, columns: describeOutputColumns}, nil
+}
+
+func (a *DescribeStmtAction) tableSpec() (*TableSpec, error) {
+	spec := a.catalog.tableSpec(a.name)
+	if spec == nil {
+		return nil, fmt.Errorf("Table not found: %s", a.name)
+	}
+	return spec, nil
+}
+
+// query builds the query that returns a row for each column of the table.
+func (a *DescribeStmtAction) query(spec *TableSpec) (string, error) {
+	rows := make([]string, 0, len(spec.Columns))
+	for _, col := range spec.Columns {
+		isNullable := "YES"
+		if col.IsNotNull {
+			isNullable = "NO"
+		}
+		var description Value
+		if col.Description != "" {
+			description = StringValue(col.Description)
+		}
+		typ, err := col.Type.ToZetaSQLType()
+		if err != nil {
+			return "", err
+		}
+		values := make([]string, 0, len(describeOutputColumns))
+		for _, v := range []Value{
+			StringValue(col.Name),
+			StringValue(typ.TypeName(types.ProductExternal)),
+			StringValue(isNullable),
+			description,
+		} {
+			literal, err := LiteralFromValue(v)
+			if err != nil {
+				return "", err
+			}
+			values = append(values, literal)
+		}
+		rows = append(rows, fmt.Sprintf("SELECT %s", strings.Join(values, ",")))
+	}
+	columns := make([]string, 0, len(describeOutputColumns))
+	for _, col := range describeOutputColumns {
+		columns = append(columns, fmt.Sprintf("`%s`", col.Name))
+	}
+	return fmt.Sprintf(
+		"WITH describe_table(%s) AS (%s) SELECT * FROM describe_table",
+		strings.Join(columns, ","),
+		strings.Join(rows, " UNION ALL "),
+	), nil
+}
+
+func (a *DescribeStmtAction) Args() []interface{} {
+	return nil
+}
+
+func (a *DescribeStmtAction) Cleanup(ctx context.Context, conn *Conn) error {
+	return nil
+}
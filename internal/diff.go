@@ -0,0 +1,147 @@
+package internal
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// DiffReason represents why the row is reported by DiffRows.
+type DiffReason string
+
+const (
+	// DiffReasonMissing is the reason of the row that exists in the expected rows but not in the actual rows.
+	DiffReasonMissing DiffReason = "MISSING"
+	// DiffReasonUnexpected is the reason of the row that exists in the actual rows but not in the expected rows.
+	DiffReasonUnexpected DiffReason = "UNEXPECTED"
+)
+
+// RowDiff is the row that is not matched.
+// Values are converted the same way as scanning into interface{} destinations via database/sql.
+type RowDiff struct {
+	Reason DiffReason
+	Values []interface{}
+}
+
+// RowsDiff is the result of DiffRows.
+type RowsDiff struct {
+	Columns []*ColumnSpec
+	Rows    []*RowDiff
+}
+
+// Empty reports whether the expected rows and the actual rows are matched.
+func (d *RowsDiff) Empty() bool {
+	return len(d.Rows) == 0
+}
+
+// String formats the mismatched rows as the table with the reason column for the messages of the test assertions.
+func (d *RowsDiff) String() string {
+	if d.Empty() {
+		return ""
+	}
+	header := []string{"reason"}
+	for _, col := range d.Columns {
+		header = append(header, col.Name)
+	}
+	lines := []string{strings.Join(header, " | ")}
+	for _, row := range d.Rows {
+		fields := []string{string(row.Reason)}
+		for _, v := range row.Values {
+			if v == nil {
+				fields = append(fields, "NULL")
+				continue
+			}
+			fields = append(fields, fmt.Sprint(v))
+		}
+		lines = append(lines, strings.Join(fields, " | "))
+	}
+	return strings.Join(lines, "\n")
+}
+
+type diffRow struct {
+	key    string
+	values []interface{}
+}
+
+// DiffRows compares the expected rows with the actual rows regardless of the order of the rows.
+// The rows are compared as multisets, so the duplicated rows must appear the same number of times.
+// The columns are compared by the position, and an error is returned if the types of the columns are different.
+func DiffRows(expected, actual *Rows) (*RowsDiff, error) {
+	if len(expected.columns) != len(actual.columns) {
+		return nil, fmt.Errorf(
+			"number of columns mismatch: expected %d columns but got %d columns",
+			len(expected.columns), len(actual.columns),
+		)
+	}
+	for idx, col := range expected.columns {
+		expectedType := col.Type.FormatType()
+		actualType := actual.columns[idx].Type.FormatType()
+		if expectedType != actualType {
+			return nil, fmt.Errorf(
+				"type of column %s mismatch: expected %s but got %s",
+				col.Name, expectedType, actualType,
+			)
+		}
+	}
+	expectedRows, err := expected.readDiffRows()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read expected rows: %w", err)
+	}
+	actualRows, err := actual.readDiffRows()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read actual rows: %w", err)
+	}
+	expectedCount := map[string]int{}
+	for _, row := range expectedRows {
+		expectedCount[row.key]++
+	}
+	var unexpectedRows []*RowDiff
+	for _, row := range actualRows {
+		if expectedCount[row.key] > 0 {
+			expectedCount[row.key]--
+			continue
+		}
+		unexpectedRows = append(unexpectedRows, &RowDiff{Reason: DiffReasonUnexpected, Values: row.values})
+	}
+	diff := &RowsDiff{Columns: expected.columns}
+	for _, row := range expectedRows {
+		if expectedCount[row.key] == 0 {
+			continue
+		}
+		expectedCount[row.key]--
+		diff.Rows = append(diff.Rows, &RowDiff{Reason: DiffReasonMissing, Values: row.values})
+	}
+	diff.Rows = append(diff.Rows, unexpectedRows...)
+	return diff, nil
+}
+
+// readDiffRows reads all rows with the key made from the encoded values.
+// The values of the same type are encoded into the same form, so the key can be used to match the rows.
+func (r *Rows) readDiffRows() ([]*diffRow, error) {
+	var ret []*diffRow
+	colTypes := r.columnTypes()
+	for {
+		srcs, err := r.nextSources()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+		dest := make([]driver.Value, len(srcs))
+		destV := reflect.ValueOf(dest)
+		for idx, colType := range colTypes {
+			if err := r.assignValue(srcs[idx], destV.Index(idx), colType); err != nil {
+				return nil, err
+			}
+		}
+		values := make([]interface{}, 0, len(dest))
+		for _, v := range dest {
+			values = append(values, v)
+		}
+		ret = append(ret, &diffRow{key: fmt.Sprintf("%#v", srcs), values: values})
+	}
+	return ret, nil
+}
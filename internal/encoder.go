@@ -4,14 +4,18 @@ import (
 	"database/sql"
 	"database/sql/driver"
 	"encoding/base64"
+	"encoding/binary"
 	"fmt"
+	"math"
 	"math/big"
 	"reflect"
 	"regexp"
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
+	"cloud.google.com/go/civil"
 	"github.com/goccy/go-json"
 	ast "github.com/goccy/go-zetasql/resolved_ast"
 	"github.com/goccy/go-zetasql/types"
@@ -54,6 +58,14 @@ func EncodeGoValues(v []interface{}, params []*ast.ParameterNode) ([]interface{}
 }
 
 func EncodeGoValue(t types.Type, v interface{}) (interface{}, error) {
+	if t.Kind() == types.JSON {
+		if text, ok := jsonTextFromGoValue(v); ok {
+			if !json.Valid([]byte(text)) {
+				return nil, fmt.Errorf("invalid JSON parameter: %s", text)
+			}
+			return EncodeValue(JsonValue(text))
+		}
+	}
 	value, err := ValueFromGoValue(v)
 	if err != nil {
 		return nil, err
@@ -62,9 +74,79 @@ func EncodeGoValue(t types.Type, v interface{}) (interface{}, error) {
 	if err != nil {
 		return nil, err
 	}
+	if numeric, ok := casted.(*NumericValue); ok {
+		casted, err = checkNumericParameter(numeric)
+		if err != nil {
+			return nil, err
+		}
+	}
 	return EncodeValue(casted)
 }
 
+// jsonTextFromGoValue returns the JSON text of the parameter value of JSON type.
+// The string or json.RawMessage value of JSON parameter is the JSON text like the parameters of BigQuery,
+// so it's not converted to the JSON string value.
+func jsonTextFromGoValue(v interface{}) (string, bool) {
+	switch vv := v.(type) {
+	case json.RawMessage:
+		if vv == nil {
+			return "", false
+		}
+		return string(vv), true
+	case *json.RawMessage:
+		if vv == nil || *vv == nil {
+			return "", false
+		}
+		return string(*vv), true
+	case string:
+		return vv, true
+	case *string:
+		if vv == nil {
+			return "", false
+		}
+		return *vv, true
+	}
+	return "", false
+}
+
+var (
+	// maxNumericValue is the upper bound ( exclusive ) of the absolute value of NUMERIC type.
+	maxNumericValue = new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(29), nil))
+
+	// maxBigNumericValue and minBigNumericValue are the range of BIGNUMERIC type.
+	// BIGNUMERIC is the 256 bit integer scaled by 10^38.
+	maxBigNumericValue = new(big.Rat).SetFrac(
+		new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(1)),
+		new(big.Int).Exp(big.NewInt(10), big.NewInt(38), nil),
+	)
+	minBigNumericValue = new(big.Rat).SetFrac(
+		new(big.Int).Neg(new(big.Int).Lsh(big.NewInt(1), 255)),
+		new(big.Int).Exp(big.NewInt(10), big.NewInt(38), nil),
+	)
+)
+
+// checkNumericParameter rounds the parameter value to the scale of NUMERIC ( 9 ) or BIGNUMERIC ( 38 )
+// and returns an error if the rounded value is out of range of the type.
+func checkNumericParameter(v *NumericValue) (*NumericValue, error) {
+	typeName := "NUMERIC"
+	scale := int64(9)
+	if v.isBigNumeric {
+		typeName = "BIGNUMERIC"
+		scale = 38
+	}
+	rounded := roundRat(v.Rat, scale)
+	var inRange bool
+	if v.isBigNumeric {
+		inRange = rounded.Cmp(minBigNumericValue) >= 0 && rounded.Cmp(maxBigNumericValue) <= 0
+	} else {
+		inRange = new(big.Rat).Abs(rounded).Cmp(maxNumericValue) < 0
+	}
+	if !inRange {
+		return nil, fmt.Errorf("Invalid %s value: %s", typeName, v.Rat.FloatString(int(scale)))
+	}
+	return &NumericValue{Rat: rounded, isBigNumeric: v.isBigNumeric}, nil
+}
+
 func EncodeValue(v Value) (interface{}, error) {
 	if v == nil {
 		return nil, nil
@@ -79,15 +161,7 @@ func EncodeValue(v Value) (interface{}, error) {
 	case *SafeValue:
 		return EncodeValue(vv.value)
 	}
-	layout, err := valueLayoutFromValue(v)
-	if err != nil {
-		return nil, err
-	}
-	b, err := json.Marshal(layout)
-	if err != nil {
-		return nil, fmt.Errorf("failed to encode value: %w", err)
-	}
-	return base64.StdEncoding.EncodeToString(b), nil
+	return encodeBinaryValue(v)
 }
 
 func LiteralFromValue(v Value) (string, error) {
@@ -121,15 +195,11 @@ func LiteralFromValue(v Value) (string, error) {
 	case *SafeValue:
 		return LiteralFromValue(vv.value)
 	}
-	layout, err := valueLayoutFromValue(v)
+	encoded, err := encodeBinaryValue(v)
 	if err != nil {
 		return "", err
 	}
-	b, err := json.Marshal(layout)
-	if err != nil {
-		return "", fmt.Errorf("failed to encode value: %w", err)
-	}
-	return fmt.Sprintf("%q", base64.StdEncoding.EncodeToString(b)), nil
+	return fmt.Sprintf("%q", encoded), nil
 }
 
 func LiteralFromZetaSQLValue(v types.Value) (string, error) {
@@ -156,7 +226,7 @@ func ValueFromZetaSQLValue(v types.Value) (Value, error) {
 	case types.ENUM:
 		return stringValueFromLiteral(v.SQLLiteral(0))
 	case types.BYTES:
-		return bytesValueFromLiteral(v.SQLLiteral(0)), nil
+		return bytesValueFromLiteral(v.SQLLiteral(0))
 	case types.DATE:
 		return dateValueFromLiteral(v.ToInt64()), nil
 	case types.DATETIME:
@@ -215,18 +285,80 @@ func stringValueFromLiteral(lit string) (StringValue, error) {
 	return StringValue(v), nil
 }
 
-func bytesValueFromLiteral(lit string) BytesValue {
-	// use a workaround because ToBytes doesn't work with certain values.
-	unquoted, err := strconv.Unquote(lit[1:])
-	if err != nil {
-		return BytesValue(lit)
+// bytesValueFromLiteral decodes the bytes literal formatted by ZetaSQL ( e.g. b"\x00", b'"' ).
+// use a workaround because ToBytes doesn't work with certain values.
+// The escape sequences of ZetaSQL are different from Go ( e.g. \' in double quotes ), so they are decoded here.
+func bytesValueFromLiteral(lit string) (BytesValue, error) {
+	body := strings.TrimPrefix(strings.TrimPrefix(lit, "b"), "B")
+	for _, quote := range []string{`"""`, `'''`, `"`, `'`} {
+		if len(body) >= 2*len(quote) && strings.HasPrefix(body, quote) && strings.HasSuffix(body, quote) {
+			unescaped, err := unescapeBytesLiteral(body[len(quote) : len(body)-len(quote)])
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode bytes literal %s: %w", lit, err)
+			}
+			return BytesValue(unescaped), nil
+		}
 	}
-	return BytesValue(unquoted)
+	return nil, fmt.Errorf("unexpected bytes literal %s", lit)
+}
+
+func unescapeBytesLiteral(s string) ([]byte, error) {
+	ret := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			ret = append(ret, s[i])
+			continue
+		}
+		i++
+		if i >= len(s) {
+			return nil, fmt.Errorf("illegal escape sequence at the end")
+		}
+		switch c := s[i]; c {
+		case 'a':
+			ret = append(ret, '\a')
+		case 'b':
+			ret = append(ret, '\b')
+		case 'f':
+			ret = append(ret, '\f')
+		case 'n':
+			ret = append(ret, '\n')
+		case 'r':
+			ret = append(ret, '\r')
+		case 't':
+			ret = append(ret, '\t')
+		case 'v':
+			ret = append(ret, '\v')
+		case '\\', '?', '"', '\'', '`':
+			ret = append(ret, c)
+		case 'x', 'X':
+			if i+2 >= len(s) {
+				return nil, fmt.Errorf("illegal hex escape sequence")
+			}
+			b, err := strconv.ParseUint(s[i+1:i+3], 16, 8)
+			if err != nil {
+				return nil, fmt.Errorf("illegal hex escape sequence: %w", err)
+			}
+			ret = append(ret, byte(b))
+			i += 2
+		case '0', '1', '2', '3':
+			if i+2 >= len(s) {
+				return nil, fmt.Errorf("illegal octal escape sequence")
+			}
+			b, err := strconv.ParseUint(s[i:i+3], 8, 8)
+			if err != nil {
+				return nil, fmt.Errorf("illegal octal escape sequence: %w", err)
+			}
+			ret = append(ret, byte(b))
+			i += 2
+		default:
+			return nil, fmt.Errorf("illegal escape sequence \\%c", c)
+		}
+	}
+	return ret, nil
 }
 
 func dateValueFromLiteral(days int64) DateValue {
-	t := time.Unix(int64(time.Duration(days)*24*(time.Hour/time.Second)), 0)
-	return DateValue(t)
+	return DateValue(dateFromUnixDays(days))
 }
 
 const (
@@ -454,6 +586,11 @@ func CastValue(t types.Type, v Value) (Value, error) {
 			return nil, err
 		}
 		typ := t.AsStruct()
+		if typ.NumFields() == len(s.values) && !hasAllStructFields(typ, s) {
+			// Fields that can't be looked up by name ( e.g. anonymous fields or
+			// fields of the second operand of UNION ALL ) are converted by position.
+			return castValueByPosition(t, s)
+		}
 		anonymousStruct := true
 		for _, key := range s.keys {
 			if key != "" {
@@ -505,6 +642,78 @@ func CastValue(t types.Type, v Value) (Value, error) {
 	return nil, fmt.Errorf("unsupported cast %s value", t.Kind())
 }
 
+func hasAllStructFields(typ *types.StructType, s *StructValue) bool {
+	for i := 0; i < typ.NumFields(); i++ {
+		name := typ.Field(i).Name()
+		if name == "" {
+			return false
+		}
+		if _, exists := s.m[name]; !exists {
+			return false
+		}
+	}
+	return true
+}
+
+// castValueByPosition casts the value like CastValue, but STRUCT fields are cast by position instead of name.
+// This is the semantics of CAST between STRUCT types ( field names of the source type are ignored ).
+// ARRAY values are cast element-wise, so the elements of STRUCT type are also cast by position.
+func castValueByPosition(t types.Type, v Value) (Value, error) {
+	if v == nil {
+		return nil, nil
+	}
+	switch t.Kind() {
+	case types.ARRAY:
+		array, err := v.ToArray()
+		if err != nil {
+			return nil, err
+		}
+		elemType := t.AsArray().ElementType()
+		ret := &ArrayValue{}
+		for _, value := range array.values {
+			casted, err := castValueByPosition(elemType, value)
+			if err != nil {
+				return nil, err
+			}
+			ret.values = append(ret.values, casted)
+		}
+		return ret, nil
+	case types.STRUCT:
+		st, ok := v.(*StructValue)
+		if !ok {
+			return CastValue(t, v)
+		}
+		typ := t.AsStruct()
+		if typ.NumFields() != len(st.values) {
+			return nil, fmt.Errorf(
+				"failed to cast STRUCT with %d fields to %s",
+				len(st.values), newType(t).FormatType(),
+			)
+		}
+		ret := &StructValue{m: map[string]Value{}}
+		for i := 0; i < typ.NumFields(); i++ {
+			field := typ.Field(i)
+			casted, err := castValueByPosition(field.Type(), st.values[i])
+			if err != nil {
+				return nil, err
+			}
+			ret.keys = append(ret.keys, field.Name())
+			ret.values = append(ret.values, casted)
+			ret.m[field.Name()] = casted
+		}
+		return ret, nil
+	case types.STRING:
+		// BYTES value is cast to STRING as UTF-8 encoded text, not the base64 representation used for display.
+		if b, ok := v.(BytesValue); ok {
+			if !utf8.Valid(b) {
+				return nil, fmt.Errorf("failed to cast BYTES to STRING: invalid UTF-8 sequence %q", string(b))
+			}
+			return StringValue(string(b)), nil
+		}
+	}
+	return CastValue(t, v)
+}
+
 func ValueFromGoValue(v interface{}) (Value, error) {
 	if isNullValue(v) {
 		return nil, nil
@@ -560,9 +769,25 @@ func valueFromGoReflectValue(v reflect.Value) (Value, error) {
 		}
 		return ret, nil
 	case reflect.Struct:
-		t, ok := v.Interface().(time.Time)
-		if ok {
-			return TimestampValue(t), nil
+		switch vv := v.Interface().(type) {
+		case time.Time:
+			return TimestampValue(vv), nil
+		case civil.Date:
+			return DateValue(vv.In(time.UTC)), nil
+		case civil.DateTime:
+			return DatetimeValue(vv.In(time.UTC)), nil
+		case civil.Time:
+			return TimeValue(time.Date(0, 1, 1, vv.Hour, vv.Minute, vv.Second, vv.Nanosecond, time.UTC)), nil
+		case big.Rat:
+			// The value is copied so that the parameter is not modified by the arithmetic operations.
+			return &NumericValue{Rat: new(big.Rat).Set(&vv)}, nil
+		case driver.Valuer:
+			// e.g. sql.NullString or zetasqlite.NullDate
+			value, err := vv.Value()
+			if err != nil {
+				return nil, err
+			}
+			return ValueFromGoValue(value)
 		}
 		ret := &StructValue{m: map[string]Value{}}
 		typ := v.Type()
@@ -600,6 +825,129 @@ func encodeNamedValue(v driver.NamedValue, param *ast.ParameterNode) (sql.NamedA
 	}, nil
 }
 
+// encodeBinaryValue encodes the value by the binary value encoding.
+// The encoded value is base64 text, so it is stored as TEXT and can be compared by zetasqlite_collate.
+func encodeBinaryValue(v Value) (string, error) {
+	b, err := appendBinaryValue([]byte{binaryValueEncodingVersion}, v)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode value: %w", err)
+	}
+	return binaryValuePrefix + base64.RawStdEncoding.EncodeToString(b), nil
+}
+
+func appendBinaryValue(b []byte, v Value) ([]byte, error) {
+	if v == nil {
+		return append(b, byte(binaryNullValueTag)), nil
+	}
+	switch vv := v.(type) {
+	case IntValue:
+		return binary.AppendVarint(append(b, byte(binaryIntValueTag)), int64(vv)), nil
+	case FloatValue:
+		return binary.BigEndian.AppendUint64(append(b, byte(binaryFloatValueTag)), math.Float64bits(float64(vv))), nil
+	case BoolValue:
+		if vv {
+			return append(b, byte(binaryBoolValueTag), 1), nil
+		}
+		return append(b, byte(binaryBoolValueTag), 0), nil
+	case StringValue:
+		return appendBinaryString(append(b, byte(binaryStringValueTag)), string(vv)), nil
+	case BytesValue:
+		return appendBinaryString(append(b, byte(binaryBytesValueTag)), string(vv)), nil
+	case *NumericValue:
+		text, err := vv.Rat.MarshalText()
+		if err != nil {
+			return nil, err
+		}
+		if vv.isBigNumeric {
+			return appendBinaryString(append(b, byte(binaryBigNumericValueTag)), string(text)), nil
+		}
+		return appendBinaryString(append(b, byte(binaryNumericValueTag)), string(text)), nil
+	case DateValue:
+		text, err := vv.ToString()
+		if err != nil {
+			return nil, err
+		}
+		return appendBinaryString(append(b, byte(binaryDateValueTag)), text), nil
+	case DatetimeValue:
+		text, err := vv.ToString()
+		if err != nil {
+			return nil, err
+		}
+		return appendBinaryString(append(b, byte(binaryDatetimeValueTag)), text), nil
+	case TimeValue:
+		text, err := vv.ToString()
+		if err != nil {
+			return nil, err
+		}
+		return appendBinaryString(append(b, byte(binaryTimeValueTag)), text), nil
+	case TimestampValue:
+		return binary.AppendVarint(append(b, byte(binaryTimestampValueTag)), time.Time(vv).UnixMicro()), nil
+	case *IntervalValue:
+		text, err := vv.ToString()
+		if err != nil {
+			return nil, err
+		}
+		return appendBinaryString(append(b, byte(binaryIntervalValueTag)), text), nil
+	case JsonValue:
+		return appendBinaryString(append(b, byte(binaryJsonValueTag)), string(vv)), nil
+	case *ArrayValue:
+		b = binary.AppendUvarint(append(b, byte(binaryArrayValueTag)), uint64(len(vv.values)))
+		for _, value := range vv.values {
+			var err error
+			b, err = appendBinaryValue(b, value)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	case *StructValue:
+		b = binary.AppendUvarint(append(b, byte(binaryStructValueTag)), uint64(len(vv.values)))
+		for i, value := range vv.values {
+			b = appendBinaryString(b, vv.keys[i])
+			var err error
+			b, err = appendBinaryValue(b, value)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	case *SafeValue:
+		return appendBinaryValue(b, vv.value)
+	}
+	return nil, fmt.Errorf("unexpected value type to encode: %T", v)
+}
+
+func appendBinaryString(b []byte, s string) []byte {
+	return append(binary.AppendUvarint(b, uint64(len(s))), s...)
+}
+
+// encodeValueLayout encodes the value by the legacy JSON based encoding.
+// Values are no longer encoded by this, but it is kept to verify that the values stored by the older versions can be decoded.
+func encodeValueLayout(v Value) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	switch vv := v.(type) {
+	case IntValue:
+		return v.ToInt64()
+	case FloatValue:
+		return v.ToFloat64()
+	case BoolValue:
+		return v.ToBool()
+	case *SafeValue:
+		return encodeValueLayout(vv.value)
+	}
+	layout, err := valueLayoutFromValue(v)
+	if err != nil {
+		return nil, err
+	}
+	b, err := json.Marshal(layout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode value: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(b), nil
+}
+
 func valueLayoutFromValue(v Value) (*ValueLayout, error) {
 	switch vv := v.(type) {
 	case StringValue:
@@ -680,7 +1028,7 @@ func valueLayoutFromValue(v Value) (*ValueLayout, error) {
 				values = append(values, nil)
 				continue
 			}
-			value, err := EncodeValue(v)
+			value, err := encodeValueLayout(v)
 			if err != nil {
 				return nil, err
 			}
@@ -697,7 +1045,7 @@ func valueLayoutFromValue(v Value) (*ValueLayout, error) {
 	case *StructValue:
 		values := make([]interface{}, 0, len(vv.values))
 		for _, v := range vv.values {
-			value, err := EncodeValue(v)
+			value, err := encodeValueLayout(v)
 			if err != nil {
 				return nil, err
 			}
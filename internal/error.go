@@ -1,6 +1,10 @@
 package internal
 
-import "strings"
+import (
+	"fmt"
+	"runtime/debug"
+	"strings"
+)
 
 type ErrorGroup struct {
 	errs []error
@@ -27,3 +31,35 @@ func (eg *ErrorGroup) Error() string {
 	}
 	return ""
 }
+
+// FunctionPanicError is the error converted from the panic that occurred in the function called by SQLite.
+// The panic is recovered so that a bug of a single function doesn't take down the whole process.
+// SQLite passes only the message to the caller, so the message contains the stack of the panic.
+type FunctionPanicError struct {
+	FuncName string
+	Value    interface{}
+	Stack    []byte
+}
+
+func (e *FunctionPanicError) Error() string {
+	return fmt.Sprintf("%s: unexpected panic: %v\n%s", e.FuncName, e.Value, e.Stack)
+}
+
+func (e *FunctionPanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+	return nil
+}
+
+// recoverFunctionPanic converts the panic to FunctionPanicError and sets it to err.
+// It must be called by defer statement directly.
+func recoverFunctionPanic(funcName string, err *error) {
+	if r := recover(); r != nil {
+		*err = &FunctionPanicError{
+			FuncName: funcName,
+			Value:    r,
+			Stack:    debug.Stack(),
+		}
+	}
+}
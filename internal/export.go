@@ -0,0 +1,236 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+
+	"github.com/goccy/go-json"
+	"github.com/goccy/go-zetasql/types"
+)
+
+// ExportFormat represents the format used to export query results.
+// The names follow the destination formats of BigQuery extract jobs.
+type ExportFormat string
+
+const (
+	ExportFormatCSV    ExportFormat = "CSV"
+	ExportFormatNDJSON ExportFormat = "NEWLINE_DELIMITED_JSON"
+)
+
+const exportTimestampFormat = "2006-01-02 15:04:05.999999"
+
+// Export writes all remaining rows to w in the specified format.
+func (r *Rows) Export(w io.Writer, format ExportFormat) error {
+	switch format {
+	case ExportFormatCSV:
+		return r.exportCSV(w)
+	case ExportFormatNDJSON:
+		return r.exportNDJSON(w)
+	}
+	return fmt.Errorf("unsupported export format %s", format)
+}
+
+func (r *Rows) exportCSV(w io.Writer) error {
+	for _, col := range r.columns {
+		if col.Type.IsArray() || col.Type.IsStruct() {
+			return fmt.Errorf(
+				"CSV export does not support nested or repeated column: %s %s",
+				col.Name, col.Type.FormatType(),
+			)
+		}
+	}
+	writer := csv.NewWriter(w)
+	if err := writer.Write(r.Columns()); err != nil {
+		return err
+	}
+	for {
+		values, err := r.nextValues()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return err
+		}
+		record := make([]string, 0, len(values))
+		for idx, value := range values {
+			field, err := formatCSVExportValue(value, r.columns[idx].Type)
+			if err != nil {
+				return err
+			}
+			record = append(record, field)
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func (r *Rows) exportNDJSON(w io.Writer) error {
+	var buf bytes.Buffer
+	for {
+		values, err := r.nextValues()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return err
+		}
+		buf.Reset()
+		buf.WriteByte('{')
+		for idx, value := range values {
+			if idx != 0 {
+				buf.WriteByte(',')
+			}
+			name, err := json.Marshal(r.columns[idx].Name)
+			if err != nil {
+				return err
+			}
+			buf.Write(name)
+			buf.WriteByte(':')
+			if err := writeJSONExportValue(&buf, value, r.columns[idx].Type); err != nil {
+				return err
+			}
+		}
+		buf.WriteString("}\n")
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nextValues reads the next row as decoded values.
+// NULL is represented by nil and io.EOF is returned after the last row.
+func (r *Rows) nextValues() ([]Value, error) {
+	srcs, err := r.nextSources()
+	if err != nil {
+		return nil, err
+	}
+	values := make([]Value, 0, len(r.columns))
+	for idx, col := range r.columns {
+		src := srcs[idx]
+		if src == nil {
+			values = append(values, nil)
+			continue
+		}
+		value, err := r.decodeValue(src, col.Type)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+func formatCSVExportValue(v Value, typ *Type) (string, error) {
+	if v == nil {
+		return "", nil
+	}
+	if types.TypeKind(typ.Kind) == types.TIMESTAMP {
+		t, err := v.ToTime()
+		if err != nil {
+			return "", err
+		}
+		return t.UTC().Format(exportTimestampFormat) + " UTC", nil
+	}
+	return v.ToString()
+}
+
+func writeJSONExportValue(buf *bytes.Buffer, v Value, typ *Type) error {
+	if v == nil {
+		buf.WriteString("null")
+		return nil
+	}
+	switch types.TypeKind(typ.Kind) {
+	case types.BOOL:
+		b, err := v.ToBool()
+		if err != nil {
+			return err
+		}
+		buf.WriteString(strconv.FormatBool(b))
+		return nil
+	case types.FLOAT, types.DOUBLE:
+		f, err := v.ToFloat64()
+		if err != nil {
+			return err
+		}
+		switch {
+		case math.IsNaN(f):
+			buf.WriteString(`"NaN"`)
+		case math.IsInf(f, 1):
+			buf.WriteString(`"Infinity"`)
+		case math.IsInf(f, -1):
+			buf.WriteString(`"-Infinity"`)
+		default:
+			buf.WriteString(strconv.FormatFloat(f, 'g', -1, 64))
+		}
+		return nil
+	case types.JSON:
+		s, err := v.ToJSON()
+		if err != nil {
+			return err
+		}
+		buf.WriteString(s)
+		return nil
+	case types.ARRAY:
+		array, err := v.ToArray()
+		if err != nil {
+			return err
+		}
+		buf.WriteByte('[')
+		for idx, elem := range array.values {
+			if idx != 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeJSONExportValue(buf, elem, typ.ElementType); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+		return nil
+	case types.STRUCT:
+		s, err := v.ToStruct()
+		if err != nil {
+			return err
+		}
+		buf.WriteByte('{')
+		for idx, field := range typ.FieldTypes {
+			if idx != 0 {
+				buf.WriteByte(',')
+			}
+			name, err := json.Marshal(field.Name)
+			if err != nil {
+				return err
+			}
+			buf.Write(name)
+			buf.WriteByte(':')
+			var fieldValue Value
+			if idx < len(s.values) {
+				fieldValue = s.values[idx]
+			}
+			if err := writeJSONExportValue(buf, fieldValue, field.Type); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+		return nil
+	}
+	// INT64 values are encoded as JSON strings to preserve 64-bit precision,
+	// the same as BigQuery does for exported JSON.
+	s, err := formatCSVExportValue(v, typ)
+	if err != nil {
+		return err
+	}
+	encoded, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	buf.Write(encoded)
+	return nil
+}
@@ -0,0 +1,210 @@
+package internal
+
+import (
+	"github.com/goccy/go-zetasql"
+	ast "github.com/goccy/go-zetasql/resolved_ast"
+	"github.com/goccy/go-zetasql/types"
+)
+
+// Feature is the SQL feature of BigQuery whose support can be queried by SupportsFeature.
+type Feature string
+
+const (
+	FeatureAnalyticFunctions         Feature = "ANALYTIC FUNCTIONS"
+	FeatureQualify                   Feature = "QUALIFY"
+	FeaturePivot                     Feature = "PIVOT"
+	FeatureUnpivot                   Feature = "UNPIVOT"
+	FeatureRecursiveCTE              Feature = "WITH RECURSIVE"
+	FeatureTablesample               Feature = "TABLESAMPLE"
+	FeatureGroupByRollup             Feature = "GROUP BY ROLLUP"
+	FeatureInlineLambda              Feature = "INLINE LAMBDA"
+	FeatureNumeric                   Feature = "NUMERIC"
+	FeatureBigNumeric                Feature = "BIGNUMERIC"
+	FeatureJSON                      Feature = "JSON"
+	FeatureInterval                  Feature = "INTERVAL"
+	FeatureGeography                 Feature = "GEOGRAPHY"
+	FeatureCollation                 Feature = "COLLATE"
+	FeatureParameterizedTypes        Feature = "PARAMETERIZED TYPES"
+	FeatureColumnDefaultValue        Feature = "COLUMN DEFAULT VALUE"
+	FeaturePrimaryKey                Feature = "PRIMARY KEY"
+	FeatureForeignKey                Feature = "FOREIGN KEY"
+	FeaturePartitionBy               Feature = "PARTITION BY"
+	FeatureClusterBy                 Feature = "CLUSTER BY"
+	FeatureCreateTableAsSelect       Feature = "CREATE TABLE AS SELECT"
+	FeatureCreateView                Feature = "CREATE VIEW"
+	FeatureCreateMaterializedView    Feature = "CREATE MATERIALIZED VIEW"
+	FeatureCreateFunction            Feature = "CREATE FUNCTION"
+	FeatureCreateTableFunction       Feature = "CREATE TABLE FUNCTION"
+	FeatureCreateProcedure           Feature = "CREATE PROCEDURE"
+	FeatureCreateSchema              Feature = "CREATE SCHEMA"
+	FeatureAlterTableSetOptions      Feature = "ALTER TABLE SET OPTIONS"
+	FeatureAlterColumnSetDataType    Feature = "ALTER COLUMN SET DATA TYPE"
+	FeatureRenameTable               Feature = "RENAME TABLE"
+	FeatureMerge                     Feature = "MERGE"
+	FeatureTruncateTable             Feature = "TRUNCATE TABLE"
+	FeatureExportData                Feature = "EXPORT DATA"
+	FeatureMultiStatementTransaction Feature = "MULTI-STATEMENT TRANSACTION"
+)
+
+// featureRequirement is the language features, the statements and the types that the feature requires.
+type featureRequirement struct {
+	feature          Feature
+	languageFeatures []zetasql.LanguageFeature
+	statements       []ast.Kind
+	typeKinds        []types.TypeKind
+}
+
+// featureRequirements is the list of the features queried by SupportsFeature.
+// The support of each feature is derived from defaultLanguageFeatures, supportedStatementKinds
+// and the types whose values can be handled at runtime,
+// so that the result is always consistent with the queries accepted by the analyzer and executed by zetasqlite.
+var featureRequirements = []*featureRequirement{
+	{feature: FeatureAnalyticFunctions, languageFeatures: []zetasql.LanguageFeature{zetasql.FeatureAnalyticFunctions}},
+	{feature: FeatureQualify, languageFeatures: []zetasql.LanguageFeature{zetasql.FeatureV13Qualify}},
+	{feature: FeaturePivot, languageFeatures: []zetasql.LanguageFeature{zetasql.FeatureV13Pivot}},
+	{feature: FeatureUnpivot, languageFeatures: []zetasql.LanguageFeature{zetasql.FeatureV13Unpivot}},
+	{feature: FeatureRecursiveCTE, languageFeatures: []zetasql.LanguageFeature{zetasql.FeatureV13WithRecursive}},
+	{feature: FeatureTablesample, languageFeatures: []zetasql.LanguageFeature{zetasql.FeatureTablesample}},
+	{feature: FeatureGroupByRollup, languageFeatures: []zetasql.LanguageFeature{zetasql.FeatureGroupByRollup}},
+	{feature: FeatureInlineLambda, languageFeatures: []zetasql.LanguageFeature{zetasql.FeatureV13InlineLambdaArgument}},
+	{
+		feature:          FeatureNumeric,
+		languageFeatures: []zetasql.LanguageFeature{zetasql.FeatureNumericType},
+		typeKinds:        []types.TypeKind{types.NUMERIC},
+	},
+	{
+		feature:          FeatureBigNumeric,
+		languageFeatures: []zetasql.LanguageFeature{zetasql.FeatureBignumericType},
+		typeKinds:        []types.TypeKind{types.BIG_NUMERIC},
+	},
+	{
+		feature:          FeatureJSON,
+		languageFeatures: []zetasql.LanguageFeature{zetasql.FeatureJsonType},
+		typeKinds:        []types.TypeKind{types.JSON},
+	},
+	{
+		feature:          FeatureInterval,
+		languageFeatures: []zetasql.LanguageFeature{zetasql.FeatureIntervalType},
+		typeKinds:        []types.TypeKind{types.INTERVAL},
+	},
+	{
+		feature:          FeatureGeography,
+		languageFeatures: []zetasql.LanguageFeature{zetasql.FeatureGeography},
+		typeKinds:        []types.TypeKind{types.GEOGRAPHY},
+	},
+	{
+		feature:          FeatureCollation,
+		languageFeatures: []zetasql.LanguageFeature{zetasql.FeatureV13AnnotationFramework, zetasql.FeatureV13CollationSupport},
+	},
+	{feature: FeatureParameterizedTypes, languageFeatures: []zetasql.LanguageFeature{zetasql.FeatureParameterizedTypes}},
+	{
+		feature:          FeatureColumnDefaultValue,
+		languageFeatures: []zetasql.LanguageFeature{zetasql.FeatureV13ColumnDefaultValue},
+		statements:       []ast.Kind{ast.CreateTableStmt},
+	},
+	{
+		feature:          FeaturePrimaryKey,
+		languageFeatures: []zetasql.LanguageFeature{zetasql.FeatureUnenforcedPrimaryKeys},
+		statements:       []ast.Kind{ast.CreateTableStmt},
+	},
+	{
+		feature:          FeatureForeignKey,
+		languageFeatures: []zetasql.LanguageFeature{zetasql.FeatureForeignKeys},
+		statements:       []ast.Kind{ast.CreateTableStmt},
+	},
+	{
+		feature:          FeaturePartitionBy,
+		languageFeatures: []zetasql.LanguageFeature{zetasql.FeatureCreateTablePartitionBy},
+		statements:       []ast.Kind{ast.CreateTableStmt},
+	},
+	{
+		feature:          FeatureClusterBy,
+		languageFeatures: []zetasql.LanguageFeature{zetasql.FeatureCreateTableClusterBy},
+		statements:       []ast.Kind{ast.CreateTableStmt},
+	},
+	{feature: FeatureCreateTableAsSelect, statements: []ast.Kind{ast.CreateTableAsSelectStmt}},
+	{feature: FeatureCreateView, statements: []ast.Kind{ast.CreateViewStmt}},
+	{feature: FeatureCreateMaterializedView, statements: []ast.Kind{ast.CreateMaterializedViewStmt}},
+	{feature: FeatureCreateFunction, statements: []ast.Kind{ast.CreateFunctionStmt, ast.DropFunctionStmt}},
+	{feature: FeatureCreateTableFunction, statements: []ast.Kind{ast.CreateTableFunctionStmt}},
+	{feature: FeatureCreateProcedure, statements: []ast.Kind{ast.CreateProcedureStmt}},
+	{feature: FeatureCreateSchema, statements: []ast.Kind{ast.CreateSchemaStmt}},
+	{feature: FeatureAlterTableSetOptions, statements: []ast.Kind{ast.AlterTableStmt, ast.AlterTableSetOptionsStmt}},
+	{
+		feature:          FeatureAlterColumnSetDataType,
+		languageFeatures: []zetasql.LanguageFeature{zetasql.FeatureAlterColumnSetDataType},
+		statements:       []ast.Kind{ast.AlterTableStmt},
+	},
+	{feature: FeatureRenameTable, statements: []ast.Kind{ast.AlterTableStmt, ast.RenameStmt}},
+	{feature: FeatureMerge, statements: []ast.Kind{ast.MergeStmt}},
+	{feature: FeatureTruncateTable, statements: []ast.Kind{ast.TruncateStmt}},
+	{feature: FeatureExportData, statements: []ast.Kind{ast.ExportDataStmt}},
+	{feature: FeatureMultiStatementTransaction, statements: []ast.Kind{ast.BeginStmt, ast.CommitStmt, ast.RollbackStmt}},
+}
+
+// SupportsFeature reports whether the feature is supported with the default language features.
+// The unknown feature is always unsupported.
+func SupportsFeature(feature Feature) bool {
+	return supportsFeature(feature, defaultLanguageFeatures)
+}
+
+// SupportedFeatures returns the features supported with the default language features in the order of declaration.
+func SupportedFeatures() []Feature {
+	var features []Feature
+	for _, req := range featureRequirements {
+		if req.isSatisfied(defaultLanguageFeatures) {
+			features = append(features, req.feature)
+		}
+	}
+	return features
+}
+
+// SupportsFeature reports whether the feature is supported with the language features enabled for the analyzer.
+// If the language features required by the feature are disabled by DisableLanguageFeatures, the feature isn't supported.
+func (a *Analyzer) SupportsFeature(feature Feature) bool {
+	return supportsFeature(feature, a.LanguageFeatures())
+}
+
+func supportsFeature(feature Feature, languageFeatures []zetasql.LanguageFeature) bool {
+	for _, req := range featureRequirements {
+		if req.feature == feature {
+			return req.isSatisfied(languageFeatures)
+		}
+	}
+	return false
+}
+
+func (r *featureRequirement) isSatisfied(languageFeatures []zetasql.LanguageFeature) bool {
+	enabledFeatureMap := make(map[zetasql.LanguageFeature]struct{}, len(languageFeatures))
+	for _, feature := range languageFeatures {
+		enabledFeatureMap[feature] = struct{}{}
+	}
+	for _, feature := range r.languageFeatures {
+		if _, exists := enabledFeatureMap[feature]; !exists {
+			return false
+		}
+	}
+	supportedStatementMap := make(map[ast.Kind]struct{}, len(supportedStatementKinds))
+	for _, kind := range supportedStatementKinds {
+		supportedStatementMap[kind] = struct{}{}
+	}
+	for _, kind := range r.statements {
+		if _, exists := supportedStatementMap[kind]; !exists {
+			return false
+		}
+	}
+	for _, kind := range r.typeKinds {
+		if !isRuntimeSupportedTypeKind(kind) {
+			return false
+		}
+	}
+	return true
+}
+
+// isRuntimeSupportedTypeKind reports whether the values of the type can be handled at runtime.
+// The analyzer accepts some types that have no value representation in zetasqlite ( e.g. GEOGRAPHY ),
+// so the support is derived from the Go type that the values of the type are converted to.
+func isRuntimeSupportedTypeKind(kind types.TypeKind) bool {
+	_, err := (&Type{Kind: int(kind)}).GoReflectType()
+	return err == nil
+}
@@ -94,6 +94,15 @@ func uniqueColumnName(ctx context.Context, col *ast.Column) string {
 	return colName
 }
 
+const (
+	// maxFunctionArgs is the default maximum number of arguments of SQLite function ( SQLITE_MAX_FUNCTION_ARG ).
+	maxFunctionArgs = 127
+	// maxCompoundSelectTerms is the default maximum number of terms in compound SELECT statement ( SQLITE_MAX_COMPOUND_SELECT ).
+	maxCompoundSelectTerms = 500
+	// recursionDepthColumnName is the hidden column of the recursive WITH entry that keeps the iteration that produced the row.
+	recursionDepthColumnName = "zetasqlite_recursion_depth"
+)
+
 type InputPattern int
 
 const (
@@ -131,6 +140,10 @@ func formatInput(input string) (string, error) {
 	return "", fmt.Errorf("unexpected input pattern: %s", input)
 }
 
+// getFuncNameAndArgs returns the name of the function registered to SQLite and the formatted arguments.
+// The named arguments ( e.g. `MAKE_INTERVAL(hour => 10)` ) are already mapped to the positions of the signature by ZetaSQL,
+// so the arguments are passed positionally for every signature that declares argument names,
+// including the user defined functions and the functions added by newZetaSQLiteFunctions.
 func getFuncNameAndArgs(ctx context.Context, node *ast.BaseFunctionCallNode, isWindowFunc bool) (string, []string, error) {
 	args := []string{}
 	for _, a := range node.ArgumentList() {
@@ -165,10 +178,13 @@ func getFuncNameAndArgs(ctx context.Context, node *ast.BaseFunctionCallNode, isW
 		}
 	} else if existsCurrentTimeFunc {
 		if currentTime != nil {
+			// the time must be passed as the first argument because the time zone is passed as the second argument.
 			args = append(
-				args,
-				fmt.Sprint(currentTime.UnixNano()),
+				[]string{fmt.Sprint(currentTime.UnixNano())},
+				args...,
 			)
+		} else if isColumnDefaultCurrentTime(ctx) {
+			args = append([]string{columnDefaultCurrentTimeFunc}, args...)
 		}
 		funcName = fmt.Sprintf("%s_%s", funcPrefix, funcName)
 	} else if existsNormalFunc {
@@ -215,6 +231,14 @@ func (n *ColumnRefNode) FormatSQL(ctx context.Context) (string, error) {
 	columnMap := columnRefMap(ctx)
 	col := n.node.Column()
 	colName := uniqueColumnName(ctx, col)
+	if expr, exists := letExprColumnMapFromContext(ctx)[colName]; exists {
+		return expr, nil
+	}
+	if n.node.IsCorrelated() {
+		// the correlated column is already selected by the outer query,
+		// so it must be referenced by name.
+		return fmt.Sprintf("`%s`", colName), nil
+	}
 	if ref, exists := columnMap[colName]; exists {
 		delete(columnMap, colName)
 		return ref, nil
@@ -250,6 +274,20 @@ func (n *FunctionCallNode) FormatSQL(ctx context.Context) (string, error) {
 	if err != nil {
 		return "", err
 	}
+	if collation := functionCollation(ctx, n.node.BaseFunctionCallNode); collation != "" {
+		collatedArgs, err := applyCollation(n.node.ArgumentList(), args, collation)
+		if err != nil {
+			return "", err
+		}
+		args = collatedArgs
+	}
+	if indexes := conditionalResultArgIndexes(funcName, len(args)); len(indexes) != 0 {
+		castedArgs, err := castResultArgs(n.node.BaseFunctionCallNode, args, indexes)
+		if err != nil {
+			return "", err
+		}
+		args = castedArgs
+	}
 	switch funcName {
 	case "zetasqlite_ifnull":
 		return fmt.Sprintf(
@@ -294,9 +332,13 @@ func (n *FunctionCallNode) FormatSQL(ctx context.Context) (string, error) {
 		}
 		stmt += " END"
 		return stmt, nil
+	case "zetasqlite_in":
+		if len(args) > maxFunctionArgs {
+			return formatChunkedIn(funcName, args[0], args[1:]), nil
+		}
 	}
 	funcMap := funcMapFromContext(ctx)
-	if spec, exists := funcMap[funcName]; exists {
+	if spec, exists := funcMap[nameKey(funcName)]; exists {
 		return spec.CallSQL(ctx, n.node.BaseFunctionCallNode, args)
 	}
 	return fmt.Sprintf(
@@ -306,6 +348,29 @@ func (n *FunctionCallNode) FormatSQL(ctx context.Context) (string, error) {
 	), nil
 }
 
+// formatChunkedIn splits the IN list that exceeds the maximum number of function arguments into multiple IN calls combined by OR.
+// OR returns TRUE if any call returns TRUE and NULL if no call returns TRUE and any call returns NULL, so the result is the same as the single IN call.
+// The value is evaluated once by the subquery because it can be non-deterministic.
+func formatChunkedIn(funcName, value string, items []string) string {
+	chunkSize := maxFunctionArgs - 1
+	var calls []string
+	for i := 0; i < len(items); i += chunkSize {
+		end := i + chunkSize
+		if end > len(items) {
+			end = len(items)
+		}
+		calls = append(
+			calls,
+			fmt.Sprintf("%s(`zetasqlite_in_value`,%s)", funcName, strings.Join(items[i:end], ",")),
+		)
+	}
+	return fmt.Sprintf(
+		"(SELECT %s FROM (SELECT %s AS `zetasqlite_in_value`))",
+		strings.Join(calls, " OR "),
+		value,
+	)
+}
+
 func (n *AggregateFunctionCallNode) FormatSQL(ctx context.Context) (string, error) {
 	if n.node == nil {
 		return "", nil
@@ -315,7 +380,7 @@ func (n *AggregateFunctionCallNode) FormatSQL(ctx context.Context) (string, erro
 		return "", err
 	}
 	funcMap := funcMapFromContext(ctx)
-	if spec, exists := funcMap[funcName]; exists {
+	if spec, exists := funcMap[nameKey(funcName)]; exists {
 		return spec.CallSQL(ctx, n.node.BaseFunctionCallNode, args)
 	}
 	var opts []string
@@ -351,16 +416,116 @@ func (n *AggregateFunctionCallNode) FormatSQL(ctx context.Context) (string, erro
 	), nil
 }
 
+// respectNullsByDefaultWindowFuncNameMap is a set of window functions that respect NULL values
+// if the null handling modifier is not specified.
+var respectNullsByDefaultWindowFuncNameMap = map[string]struct{}{
+	"zetasqlite_window_array_agg": {},
+}
+
+// analyticFunctionGroupColumns returns the partition columns and the order columns of the OVER clause of the group.
+// The partition columns are also contained in the order columns to sort the rows of the partition.
+func analyticFunctionGroupColumns(ctx context.Context, group *ast.AnalyticFunctionGroupNode) ([]string, []*analyticOrderBy) {
+	var (
+		partitionColumns []string
+		orderColumns     []*analyticOrderBy
+	)
+	if group.PartitionBy() != nil {
+		for _, columnRef := range group.PartitionBy().PartitionByList() {
+			colName := fmt.Sprintf("`%s`", uniqueColumnName(ctx, columnRef.Column()))
+			partitionColumns = append(partitionColumns, colName)
+			orderColumns = append(orderColumns, &analyticOrderBy{
+				column: colName,
+				isAsc:  true,
+			})
+		}
+	}
+	if group.OrderBy() != nil {
+		for _, item := range group.OrderBy().OrderByItemList() {
+			orderColumns = append(orderColumns, &analyticOrderBy{
+				column: fmt.Sprintf("`%s`", uniqueColumnName(ctx, item.ColumnRef().Column())),
+				isAsc:  !item.IsDescending(),
+			})
+		}
+	}
+	return partitionColumns, orderColumns
+}
+
+// validateAnalyticWindowArgs asserts that the partition and order columns passed to the window function
+// are derived only from the OVER clause of its own analytic function group.
+// They are passed through the context separately from the group, so the expected columns are built from
+// the PARTITION BY and ORDER BY of the group again to detect the columns of the other group leaked into the context.
+func validateAnalyticWindowArgs(ctx context.Context, funcName string, partitionColumns []string, orderColumns []*analyticOrderBy) error {
+	group := analyticFunctionGroupFromContext(ctx)
+	if group == nil {
+		return fmt.Errorf("failed to find the analytic function group of %s", funcName)
+	}
+	var (
+		expectedPartitionColumns []string
+		expectedOrderColumns     []string
+	)
+	if partitionBy := group.PartitionBy(); partitionBy != nil {
+		for _, columnRef := range partitionBy.PartitionByList() {
+			name := fmt.Sprintf("`%s`", uniqueColumnName(ctx, columnRef.Column()))
+			expectedPartitionColumns = append(expectedPartitionColumns, name)
+			// the rows are sorted by the partition columns first.
+			expectedOrderColumns = append(expectedOrderColumns, name+" ASC")
+		}
+	}
+	if orderBy := group.OrderBy(); orderBy != nil {
+		for _, item := range orderBy.OrderByItemList() {
+			direction := "ASC"
+			if item.IsDescending() {
+				direction = "DESC"
+			}
+			expectedOrderColumns = append(
+				expectedOrderColumns,
+				fmt.Sprintf("`%s` %s", uniqueColumnName(ctx, item.ColumnRef().Column()), direction),
+			)
+		}
+	}
+	actualOrderColumns := make([]string, 0, len(orderColumns))
+	for _, col := range orderColumns {
+		direction := "ASC"
+		if !col.isAsc {
+			direction = "DESC"
+		}
+		actualOrderColumns = append(actualOrderColumns, fmt.Sprintf("%s %s", col.column, direction))
+	}
+	if strings.Join(partitionColumns, ",") != strings.Join(expectedPartitionColumns, ",") {
+		return fmt.Errorf(
+			"unexpected partition columns of %s: expected [%s] but got [%s]",
+			funcName,
+			strings.Join(expectedPartitionColumns, ","),
+			strings.Join(partitionColumns, ","),
+		)
+	}
+	if strings.Join(actualOrderColumns, ",") != strings.Join(expectedOrderColumns, ",") {
+		return fmt.Errorf(
+			"unexpected order columns of %s: expected [%s] but got [%s]",
+			funcName,
+			strings.Join(expectedOrderColumns, ","),
+			strings.Join(actualOrderColumns, ","),
+		)
+	}
+	return nil
+}
+
 func (n *AnalyticFunctionCallNode) FormatSQL(ctx context.Context) (string, error) {
 	if n.node == nil {
 		return "", nil
 	}
-	orderColumnNames := analyticOrderColumnNamesFromContext(ctx)
-	orderColumns := orderColumnNames.values
+	partitionColumns := analyticPartitionColumnNamesFromContext(ctx)
+	var orderColumns []*analyticOrderBy
+	if orderColumnNames := analyticOrderColumnNamesFromContext(ctx); orderColumnNames != nil {
+		orderColumns = orderColumnNames.values
+	}
 	funcName, args, err := getFuncNameAndArgs(ctx, n.node.BaseFunctionCallNode, true)
 	if err != nil {
 		return "", err
 	}
+	if err := validateAnalyticWindowArgs(ctx, funcName, partitionColumns, orderColumns); err != nil {
+		return "", err
+	}
 	var opts []string
 	if n.node.Distinct() {
 		opts = append(opts, "zetasqlite_distinct()")
@@ -368,11 +533,15 @@ func (n *AnalyticFunctionCallNode) FormatSQL(ctx context.Context) (string, error
 	switch n.node.NullHandlingModifier() {
 	case ast.RespectNulls:
 		// do nothing
-	default:
+	case ast.IgnoreNulls:
 		opts = append(opts, "zetasqlite_ignore_nulls()")
+	default:
+		if _, exists := respectNullsByDefaultWindowFuncNameMap[funcName]; !exists {
+			opts = append(opts, "zetasqlite_ignore_nulls()")
+		}
 	}
 	args = append(args, opts...)
-	for _, column := range analyticPartitionColumnNamesFromContext(ctx) {
+	for _, column := range partitionColumns {
 		args = append(args, getWindowPartitionOptionFuncSQL(column))
 	}
 	for _, col := range orderColumns {
@@ -394,7 +563,7 @@ func (n *AnalyticFunctionCallNode) FormatSQL(ctx context.Context) (string, error
 	args = append(args, getWindowRowIDOptionFuncSQL())
 	input := analyticInputScanFromContext(ctx)
 	funcMap := funcMapFromContext(ctx)
-	if spec, exists := funcMap[funcName]; exists {
+	if spec, exists := funcMap[nameKey(funcName)]; exists {
 		return spec.CallSQL(ctx, n.node.BaseFunctionCallNode, args)
 	}
 	return fmt.Sprintf(
@@ -438,34 +607,92 @@ func (n *CastNode) FormatSQL(ctx context.Context) (string, error) {
 	if n.node == nil {
 		return "", nil
 	}
-	fromType := newType(n.node.Expr().Type())
-	jsonEncodedFromType, err := json.Marshal(fromType)
+	expr, err := newNode(n.node.Expr()).FormatSQL(ctx)
 	if err != nil {
 		return "", err
 	}
-	toType := newType(n.node.Type())
-	jsonEncodedToType, err := json.Marshal(toType)
+	return formatCast(expr, n.node.Expr().Type(), n.node.Type(), n.node.ReturnNullOnError())
+}
+
+func formatCast(expr string, from, to types.Type, returnNullOnError bool) (string, error) {
+	jsonEncodedFromType, err := json.Marshal(newType(from))
 	if err != nil {
 		return "", err
 	}
-	encodedFromType, err := EncodeGoValue(types.StringType(), string(jsonEncodedFromType))
+	jsonEncodedToType, err := json.Marshal(newType(to))
 	if err != nil {
 		return "", err
 	}
-	encodedToType, err := EncodeGoValue(types.StringType(), string(jsonEncodedToType))
+	encodedFromType, err := EncodeGoValue(types.StringType(), string(jsonEncodedFromType))
 	if err != nil {
 		return "", err
 	}
-	expr, err := newNode(n.node.Expr()).FormatSQL(ctx)
+	encodedToType, err := EncodeGoValue(types.StringType(), string(jsonEncodedToType))
 	if err != nil {
 		return "", err
 	}
 	return fmt.Sprintf(
 		"zetasqlite_cast(%s, '%s', '%s', %t)",
-		expr, encodedFromType, encodedToType, n.node.ReturnNullOnError(),
+		expr, encodedFromType, encodedToType, returnNullOnError,
 	), nil
 }
 
+// castResultArgs casts the arguments at the specified indexes to the result type of the conditional expression
+// if the types are different from it ( e.g. the branch returns the STRUCT that has the different field names ).
+// This keeps the type of the result stable regardless of the branch taken.
+func castResultArgs(node *ast.BaseFunctionCallNode, args []string, indexes []int) ([]string, error) {
+	argNodes := node.ArgumentList()
+	if len(argNodes) != len(args) {
+		return args, nil
+	}
+	resultType := node.Type()
+	ret := append([]string{}, args...)
+	for _, idx := range indexes {
+		argType := argNodes[idx].Type()
+		if argType.Equals(resultType) {
+			continue
+		}
+		casted, err := formatCast(args[idx], argType, resultType, false)
+		if err != nil {
+			return nil, err
+		}
+		ret[idx] = casted
+	}
+	return ret, nil
+}
+
+// conditionalResultArgIndexes returns the indexes of the arguments that are returned as the result of the conditional expression.
+func conditionalResultArgIndexes(funcName string, argNum int) []int {
+	var indexes []int
+	switch funcName {
+	case "zetasqlite_coalesce":
+		for i := 0; i < argNum; i++ {
+			indexes = append(indexes, i)
+		}
+	case "zetasqlite_nullif":
+		indexes = []int{0}
+	case "zetasqlite_ifnull":
+		indexes = []int{0, 1}
+	case "zetasqlite_if":
+		indexes = []int{1, 2}
+	case "zetasqlite_case_no_value":
+		for i := 1; i < argNum; i += 2 {
+			indexes = append(indexes, i)
+		}
+		if argNum%2 == 1 {
+			indexes = append(indexes, argNum-1)
+		}
+	case "zetasqlite_case_with_value":
+		for i := 2; i < argNum; i += 2 {
+			indexes = append(indexes, i)
+		}
+		if argNum%2 == 0 {
+			indexes = append(indexes, argNum-1)
+		}
+	}
+	return indexes
+}
+
 func (n *MakeStructNode) FormatSQL(ctx context.Context) (string, error) {
 	if n.node == nil {
 		return "", nil
@@ -552,6 +779,14 @@ func (n *SubqueryExprNode) FormatSQL(ctx context.Context) (string, error) {
 	}
 	columnNames := &arraySubqueryColumnNames{}
 	ctx = withArraySubqueryColumnName(ctx, columnNames)
+	// the subquery has its own scope of computed columns.
+	// It must not consume the columns of the outer query referenced by the correlated columns.
+	ctx = withColumnRefMap(ctx, map[string]string{})
+	if sql, ok, err := decorrelateSubquery(ctx, n.node); err != nil {
+		return "", err
+	} else if ok {
+		return sql, nil
+	}
 	sql, err := newNode(n.node.Subquery()).FormatSQL(ctx)
 	if err != nil {
 		return "", err
@@ -571,6 +806,23 @@ func (n *SubqueryExprNode) FormatSQL(ctx context.Context) (string, error) {
 		if err != nil {
 			return "", err
 		}
+		if n.node.InExpr().Type().Kind() == types.STRUCT {
+			// STRUCT values are compared by position of fields,
+			// so they cannot be compared by the encoded values that include field names.
+			if len(n.node.Subquery().ColumnList()) == 0 {
+				return "", fmt.Errorf("failed to find computed column names for in subquery")
+			}
+			// IN returns NULL instead of FALSE if no value matches and any comparison returns NULL,
+			// so the comparison with the NULL value or by the NULL value is handled separately.
+			// The value is evaluated once by the subquery because it can be non-deterministic.
+			colName := uniqueColumnName(ctx, n.node.Subquery().ColumnList()[0])
+			return fmt.Sprintf(
+				"(SELECT CASE WHEN EXISTS (SELECT 1 FROM (%[1]s) WHERE zetasqlite_equal(`zetasqlite_in_value`, `%[2]s`)) THEN TRUE "+
+					"WHEN EXISTS (SELECT 1 FROM (%[1]s) WHERE zetasqlite_equal(`zetasqlite_in_value`, `%[2]s`) IS NULL) THEN NULL "+
+					"ELSE FALSE END FROM (SELECT %[3]s AS `zetasqlite_in_value`))",
+				sql, colName, expr,
+			), nil
+		}
 		return fmt.Sprintf("%s IN (%s)", expr, sql), nil
 	case ast.SubqueryTypeLikeAny:
 	case ast.SubqueryTypeLikeAll:
@@ -579,7 +831,32 @@ func (n *SubqueryExprNode) FormatSQL(ctx context.Context) (string, error) {
 }
 
 func (n *LetExprNode) FormatSQL(ctx context.Context) (string, error) {
-	return "", nil
+	if n.node == nil {
+		return "", nil
+	}
+	// The assignments are evaluated once by the subquery in FROM clause, because they can be non-deterministic.
+	// Each assignment is selected with the preceding ones, so it can refer to the columns assigned before it.
+	var assignments string
+	for _, assignment := range n.node.AssignmentList() {
+		expr, err := newNode(assignment.Expr()).FormatSQL(ctx)
+		if err != nil {
+			return "", err
+		}
+		column := fmt.Sprintf("%s AS `%s`", expr, uniqueColumnName(ctx, assignment.Column()))
+		if assignments == "" {
+			assignments = fmt.Sprintf("SELECT %s", column)
+		} else {
+			assignments = fmt.Sprintf("SELECT *, %s FROM (%s)", column, assignments)
+		}
+	}
+	expr, err := newNode(n.node.Expr()).FormatSQL(ctx)
+	if err != nil {
+		return "", err
+	}
+	if assignments == "" {
+		return expr, nil
+	}
+	return fmt.Sprintf("(SELECT %s FROM (%s))", expr, assignments), nil
 }
 
 func (n *ModelNode) FormatSQL(ctx context.Context) (string, error) {
@@ -590,8 +867,12 @@ func (n *ConnectionNode) FormatSQL(ctx context.Context) (string, error) {
 	return "", nil
 }
 
+// DescriptorNode appears only in the arguments of table-valued functions, which aren't supported yet.
 func (n *DescriptorNode) FormatSQL(ctx context.Context) (string, error) {
-	return "", nil
+	return "", fmt.Errorf(
+		"DESCRIPTOR(%s) argument of table-valued function is not supported",
+		strings.Join(n.node.DescriptorColumnNameList(), ", "),
+	)
 }
 
 func (n *SingleRowScanNode) FormatSQL(ctx context.Context) (string, error) {
@@ -602,14 +883,7 @@ func (n *TableScanNode) FormatSQL(ctx context.Context) (string, error) {
 	if n.node == nil {
 		return "", nil
 	}
-	var columns []string
-	for _, col := range n.node.ColumnList() {
-		columns = append(
-			columns,
-			fmt.Sprintf("`%s` AS `%s`", col.Name(), uniqueColumnName(ctx, col)),
-		)
-	}
-
+	columns := tableScanColumns(ctx, n.node)
 	table := n.node.Table()
 	wildcardTable, ok := table.(*WildcardTable)
 	if ok {
@@ -623,9 +897,30 @@ func (n *TableScanNode) FormatSQL(ctx context.Context) (string, error) {
 	if err != nil {
 		return "", err
 	}
+	if analyzer := analyzerFromContext(ctx); analyzer != nil {
+		if err := analyzer.catalog.checkTableName(tableName); err != nil {
+			return "", err
+		}
+	}
 	return fmt.Sprintf("(SELECT %s FROM `%s`)", strings.Join(columns, ","), tableName), nil
 }
 
+func tableScanColumns(ctx context.Context, node *ast.TableScanNode) []string {
+	var columns []string
+	for _, col := range node.ColumnList() {
+		columns = append(
+			columns,
+			fmt.Sprintf("`%s` AS `%s`", col.Name(), uniqueColumnName(ctx, col)),
+		)
+	}
+	if len(columns) == 0 {
+		// the unused columns are pruned ( e.g. SELECT COUNT(*) FROM table ),
+		// but the scan still has to produce the rows.
+		columns = append(columns, "1")
+	}
+	return columns
+}
+
 func (n *JoinScanNode) FormatSQL(ctx context.Context) (string, error) {
 	if n.node == nil {
 		return "", nil
@@ -641,7 +936,12 @@ func (n *JoinScanNode) FormatSQL(ctx context.Context) (string, error) {
 	if getInputPattern(left) == InputNeedsWrap {
 		left = fmt.Sprintf("(%s)", left)
 	}
-	if getInputPattern(right) == InputNeedsWrap {
+	switch getInputPattern(right) {
+	case InputNeedsWrap:
+		right = fmt.Sprintf("(%s)", right)
+	case InputNeedsFrom:
+		// the right side is a join ( e.g. a JOIN (b JOIN c USING (k)) USING (k) ),
+		// so it must be grouped to keep its join condition.
 		right = fmt.Sprintf("(%s)", right)
 	}
 	if n.node.JoinExpr() == nil {
@@ -668,19 +968,34 @@ func (n *ArrayScanNode) FormatSQL(ctx context.Context) (string, error) {
 	if n.node == nil {
 		return "", nil
 	}
-	arrayExpr, err := newNode(n.node.ArrayExpr()).FormatSQL(ctx)
+	return formatArrayScan(ctx, n.node, nil)
+}
+
+// formatArrayScan formats the array scan.
+// If predicates are specified, the elements that don't satisfy them are removed while decoding the array.
+func formatArrayScan(ctx context.Context, node *ast.ArrayScanNode, predicates []*arrayElementPredicate) (string, error) {
+	arrayExpr, err := newNode(node.ArrayExpr()).FormatSQL(ctx)
 	if err != nil {
 		return "", err
 	}
-	colName := uniqueColumnName(ctx, n.node.ElementColumn())
+	decodedArray := fmt.Sprintf("zetasqlite_decode_array(%s)", arrayExpr)
+	if len(predicates) != 0 {
+		args, err := formatArrayElementPredicates(ctx, predicates)
+		if err != nil {
+			return "", err
+		}
+		decodedArray = fmt.Sprintf("zetasqlite_decode_array_filter(%s%s)", arrayExpr, args)
+	}
+	colName := uniqueColumnName(ctx, node.ElementColumn())
 	columns := []string{fmt.Sprintf("json_each.value AS `%s`", colName)}
 
-	if offsetColumn := n.node.ArrayOffsetColumn(); offsetColumn != nil {
+	if offsetColumn := node.ArrayOffsetColumn(); offsetColumn != nil {
 		offsetColName := uniqueColumnName(ctx, offsetColumn.Column())
 		columns = append(columns, fmt.Sprintf("json_each.key AS `%s`", offsetColName))
 	}
-	if n.node.InputScan() != nil {
-		input, err := newNode(n.node.InputScan()).FormatSQL(ctx)
+	columns = append(columns, fmt.Sprintf("json_each.key AS `%s`", arrayScanOrdinalColumnName(ctx, node)))
+	if node.InputScan() != nil {
+		input, err := newNode(node.InputScan()).FormatSQL(ctx)
 		if err != nil {
 			return "", err
 		}
@@ -689,16 +1004,26 @@ func (n *ArrayScanNode) FormatSQL(ctx context.Context) (string, error) {
 			return "", err
 		}
 
-		array := fmt.Sprintf("json_each(zetasqlite_decode_array(%s))", arrayExpr)
+		array := fmt.Sprintf("json_each(%s)", decodedArray)
 		var arrayJoinExpr string
-		if n.node.JoinExpr() != nil {
-			arrayJoinExpr, err = newNode(n.node.JoinExpr()).FormatSQL(ctx)
+		if node.JoinExpr() != nil {
+			// the aliases of the element and offset columns cannot be referred reliably from ON clause,
+			// so replace them with the columns of json_each.
+			joinColumnMap := map[string]string{}
+			for name, expr := range letExprColumnMapFromContext(ctx) {
+				joinColumnMap[name] = expr
+			}
+			joinColumnMap[colName] = "json_each.value"
+			if offsetColumn := node.ArrayOffsetColumn(); offsetColumn != nil {
+				joinColumnMap[uniqueColumnName(ctx, offsetColumn.Column())] = "json_each.key"
+			}
+			arrayJoinExpr, err = newNode(node.JoinExpr()).FormatSQL(withLetExprColumnMap(ctx, joinColumnMap))
 			if err != nil {
 				return "", err
 			}
 			// RIGHT JOINs on array expressions are not supported by BigQuery
 			var joinMode string
-			if n.node.IsOuter() {
+			if node.IsOuter() {
 				joinMode = "LEFT OUTER JOIN"
 			} else {
 				joinMode = "INNER JOIN"
@@ -708,6 +1033,9 @@ func (n *ArrayScanNode) FormatSQL(ctx context.Context) (string, error) {
 				array,
 				arrayJoinExpr,
 			)
+		} else if node.IsOuter() {
+			// LEFT JOIN UNNEST without join expression keeps the rows of input scan even if the array is empty.
+			arrayJoinExpr = fmt.Sprintf("LEFT OUTER JOIN %s ON 1", array)
 		} else {
 			// If there is no join expression, use a CROSS JOIN
 			arrayJoinExpr = fmt.Sprintf(", %s", array)
@@ -721,12 +1049,40 @@ func (n *ArrayScanNode) FormatSQL(ctx context.Context) (string, error) {
 		), nil
 	}
 	return fmt.Sprintf(
-		"SELECT %s FROM json_each(zetasqlite_decode_array(%s))",
+		"SELECT %s FROM json_each(%s)",
 		strings.Join(columns, ","),
-		arrayExpr,
+		decodedArray,
 	), nil
 }
 
+// arrayScanOrdinalColumnName returns the name of the column that has the position of the element in the array.
+// The column is added to the array scan even if WITH OFFSET isn't specified, so that the rows can be ordered by the array order.
+func arrayScanOrdinalColumnName(ctx context.Context, node *ast.ArrayScanNode) string {
+	return fmt.Sprintf("zetasqlite_ordinal_%s", uniqueColumnName(ctx, node.ElementColumn()))
+}
+
+// scanOrdinalColumns returns the ordinal columns that identify the rows of the scan in the order of the decoded arrays.
+// The rows are identified only if the scan is composed of the array scans and the filters over them,
+// because the order of the rows read from the tables or the other scans isn't stable.
+func scanOrdinalColumns(ctx context.Context, scan ast.ScanNode) []string {
+	switch s := scan.(type) {
+	case *ast.ArrayScanNode:
+		var ordinals []string
+		if s.InputScan() != nil {
+			if _, ok := s.InputScan().(*ast.SingleRowScanNode); !ok {
+				ordinals = scanOrdinalColumns(ctx, s.InputScan())
+				if len(ordinals) == 0 {
+					return nil
+				}
+			}
+		}
+		return append(ordinals, fmt.Sprintf("`%s`", arrayScanOrdinalColumnName(ctx, s)))
+	case *ast.FilterScanNode:
+		return scanOrdinalColumns(ctx, s.InputScan())
+	}
+	return nil
+}
+
 func (n *ColumnHolderNode) FormatSQL(ctx context.Context) (string, error) {
 	return "", nil
 }
@@ -738,6 +1094,16 @@ func (n *FilterScanNode) FormatSQL(ctx context.Context) (string, error) {
 	if n.node == nil {
 		return "", nil
 	}
+	if scan, ok := n.node.InputScan().(*ast.AnalyticScanNode); ok {
+		if dedup := newRowNumberDeduplication(ctx, scan, n.node.FilterExpr()); dedup != nil {
+			return formatRowNumberDeduplication(ctx, scan, dedup)
+		}
+	}
+	if scan, ok := n.node.InputScan().(*ast.ArrayScanNode); ok {
+		if predicates, conditions := splitArrayElementPredicates(ctx, scan, n.node.FilterExpr()); len(predicates) != 0 {
+			return formatArrayScanWithFilter(ctx, scan, predicates, conditions)
+		}
+	}
 	input, err := newNode(n.node.InputScan()).FormatSQL(ctx)
 	if err != nil {
 		return "", err
@@ -746,6 +1112,32 @@ func (n *FilterScanNode) FormatSQL(ctx context.Context) (string, error) {
 	if err != nil {
 		return "", err
 	}
+	return formatFilterScan(input, filter), nil
+}
+
+// formatArrayScanWithFilter formats the filter over the array scan.
+// The element predicates are evaluated while decoding the array,
+// so the elements filtered out are not expanded by json_each.
+func formatArrayScanWithFilter(ctx context.Context, scan *ast.ArrayScanNode, predicates []*arrayElementPredicate, conditions []ast.ExprNode) (string, error) {
+	input, err := formatArrayScan(ctx, scan, predicates)
+	if err != nil {
+		return "", err
+	}
+	if len(conditions) == 0 {
+		return input, nil
+	}
+	filters := make([]string, 0, len(conditions))
+	for _, cond := range conditions {
+		filter, err := newNode(cond).FormatSQL(ctx)
+		if err != nil {
+			return "", err
+		}
+		filters = append(filters, filter)
+	}
+	return formatFilterScan(input, strings.Join(filters, " AND ")), nil
+}
+
+func formatFilterScan(input, filter string) string {
 	currentQuery := removeExpressions.ReplaceAllString(input, "")
 
 	// Qualify the statement if the input is not wrapped in parens
@@ -757,9 +1149,9 @@ func (n *FilterScanNode) FormatSQL(ctx context.Context) (string, error) {
 	}
 
 	if !queryWrappedInParens && containsTokens {
-		return fmt.Sprintf("( %s ) WHERE %s", input, filter), nil
+		return fmt.Sprintf("( %s ) WHERE %s", input, filter)
 	}
-	return fmt.Sprintf("%s WHERE %s", input, filter), nil
+	return fmt.Sprintf("%s WHERE %s", input, filter)
 }
 
 func (n *GroupingSetNode) FormatSQL(ctx context.Context) (string, error) {
@@ -781,14 +1173,18 @@ func (n *AggregateScanNode) FormatSQL(ctx context.Context) (string, error) {
 		return "", err
 	}
 	groupByColumns := []string{}
-	groupByColumnMap := map[string]struct{}{}
+	groupByColumnMap := map[string]string{}
 	for _, col := range n.node.GroupByList() {
 		if _, err := newNode(col).FormatSQL(ctx); err != nil {
 			return "", err
 		}
 		colName := uniqueColumnName(ctx, col.Column())
-		groupByColumns = append(groupByColumns, fmt.Sprintf("`%s`", colName))
-		groupByColumnMap[colName] = struct{}{}
+		groupByColumn, err := groupByCollationKey(ctx, col, fmt.Sprintf("`%s`", colName))
+		if err != nil {
+			return "", err
+		}
+		groupByColumns = append(groupByColumns, groupByColumn)
+		groupByColumnMap[colName] = groupByColumn
 	}
 	columns := []string{}
 	columnMap := columnRefMap(ctx)
@@ -811,7 +1207,11 @@ func (n *AggregateScanNode) FormatSQL(ctx context.Context) (string, error) {
 			groupBySetColumnMap := map[string]struct{}{}
 			for _, col := range set.GroupByColumnList() {
 				colName := uniqueColumnName(ctx, col.Column())
-				groupBySetColumns = append(groupBySetColumns, fmt.Sprintf("`%s`", colName))
+				groupBySetColumn, exists := groupByColumnMap[colName]
+				if !exists {
+					groupBySetColumn = fmt.Sprintf("`%s`", colName)
+				}
+				groupBySetColumns = append(groupBySetColumns, groupBySetColumn)
 				groupBySetColumnMap[colName] = struct{}{}
 			}
 			nullColumnNameMap := map[string]struct{}{}
@@ -922,6 +1322,15 @@ func (n *SetOperationScanNode) FormatSQL(ctx context.Context) (string, error) {
 	default:
 		opType = "UNKNOWN"
 	}
+	if n.node.OpType() == ast.SetOperationTypeUnionAll {
+		values, err := n.formatLiteralRowsAsValues(ctx)
+		if err != nil {
+			return "", err
+		}
+		if values != "" {
+			return values, nil
+		}
+	}
 	var queries []string
 	for _, item := range n.node.InputItemList() {
 		var outputColumns []string
@@ -962,7 +1371,79 @@ func (n *SetOperationScanNode) FormatSQL(ctx context.Context) (string, error) {
 	return fmt.Sprintf(
 		"SELECT %s FROM (%s)",
 		strings.Join(columnMaps, ","),
-		strings.Join(queries, fmt.Sprintf(" %s ", opType)),
+		joinCompoundSelectTerms(queries, opType),
+	), nil
+}
+
+// joinCompoundSelectTerms joins the queries by the set operator.
+// If the number of queries exceeds the maximum number of terms in compound SELECT statement,
+// the preceding terms are wrapped by the subquery and combined with the following terms.
+// The set operators of SQLite are evaluated from left to right, so the wrapping doesn't change the result.
+func joinCompoundSelectTerms(queries []string, opType string) string {
+	sep := fmt.Sprintf(" %s ", opType)
+	if len(queries) <= maxCompoundSelectTerms {
+		return strings.Join(queries, sep)
+	}
+	joined := strings.Join(queries[:maxCompoundSelectTerms], sep)
+	for i := maxCompoundSelectTerms; i < len(queries); i += maxCompoundSelectTerms - 1 {
+		end := i + maxCompoundSelectTerms - 1
+		if end > len(queries) {
+			end = len(queries)
+		}
+		joined = fmt.Sprintf("SELECT * FROM (%s)%s%s", joined, sep, strings.Join(queries[i:end], sep))
+	}
+	return joined
+}
+
+// formatLiteralRowsAsValues formats UNION ALL of the rows consisting of literals only
+// ( e.g. SELECT 1 AS id, 'a' AS name UNION ALL SELECT 2, 'b' ) as VALUES clause of SQLite.
+// Literal tables used as test fixtures often have hundreds of rows,
+// but SQLite limits the number of terms in compound SELECT statement to 500.
+// If the input items contain other than literal rows, returns empty string.
+func (n *SetOperationScanNode) formatLiteralRowsAsValues(ctx context.Context) (string, error) {
+	items := n.node.InputItemList()
+	if len(items) == 0 {
+		return "", nil
+	}
+	rows := make([]string, 0, len(items))
+	for _, item := range items {
+		project, ok := item.Scan().(*ast.ProjectScanNode)
+		if !ok {
+			return "", nil
+		}
+		if _, ok := project.InputScan().(*ast.SingleRowScanNode); !ok {
+			return "", nil
+		}
+		exprMap := map[int]ast.ExprNode{}
+		for _, computed := range project.ExprList() {
+			if _, ok := computed.Expr().(*ast.LiteralNode); !ok {
+				return "", nil
+			}
+			exprMap[computed.Column().ColumnID()] = computed.Expr()
+		}
+		values := make([]string, 0, len(item.OutputColumnList()))
+		for _, col := range item.OutputColumnList() {
+			expr, exists := exprMap[col.ColumnID()]
+			if !exists {
+				return "", nil
+			}
+			value, err := newNode(expr).FormatSQL(ctx)
+			if err != nil {
+				return "", err
+			}
+			values = append(values, value)
+		}
+		rows = append(rows, fmt.Sprintf("(%s)", strings.Join(values, ",")))
+	}
+	columns := make([]string, 0, len(n.node.ColumnList()))
+	for idx, col := range n.node.ColumnList() {
+		// SQLite names the columns of VALUES clause column1, column2, ...
+		columns = append(columns, fmt.Sprintf("`column%d` AS `%s`", idx+1, uniqueColumnName(ctx, col)))
+	}
+	return fmt.Sprintf(
+		"SELECT %s FROM (VALUES %s)",
+		strings.Join(columns, ","),
+		strings.Join(rows, ","),
 	), nil
 }
 
@@ -1063,6 +1544,12 @@ func (n *LimitOffsetScanNode) FormatSQL(ctx context.Context) (string, error) {
 		}
 		offsetExpr = fmt.Sprintf("OFFSET %s", expr)
 	}
+	if _, ok := n.node.InputScan().(*ast.OrderByScanNode); ok {
+		// SQLite doesn't guarantee the order of the rows of the subquery,
+		// so LIMIT is added to the query that has ORDER BY to select the rows in the specified order.
+		// Otherwise the outer query like the window functions may see the different rows.
+		return fmt.Sprintf("%s %s %s", input, limitExpr, offsetExpr), nil
+	}
 	return fmt.Sprintf(
 		"SELECT %s %s %s %s",
 		strings.Join(columns, ","),
@@ -1108,48 +1595,29 @@ func (n *AnalyticScanNode) FormatSQL(ctx context.Context) (string, error) {
 	if err != nil {
 		return "", err
 	}
+	// The analytic functions refer to the current row by `row_id`, and they read the input in the same order.
+	// If the input is the decoded arrays, both are ordered by the positions of the elements,
+	// so the rows are numbered deterministically even if the analytic functions don't specify ORDER BY.
+	rowNumber := "ROW_NUMBER() OVER()"
+	if ordinals := scanOrdinalColumns(ctx, n.node.InputScan()); len(ordinals) != 0 {
+		orderBy := strings.Join(ordinals, ",")
+		formattedInput = fmt.Sprintf("FROM (SELECT * %s ORDER BY %s)", formattedInput, orderBy)
+		rowNumber = fmt.Sprintf("ROW_NUMBER() OVER(ORDER BY %s)", orderBy)
+	}
 	ctx = withAnalyticInputScan(ctx, formattedInput)
-	orderColumnNames := analyticOrderColumnNamesFromContext(ctx)
 	var scanOrderBy []*analyticOrderBy
 	for _, group := range n.node.FunctionGroupList() {
-		scanOrderBy = []*analyticOrderBy{}
-
-		if group.PartitionBy() != nil {
-			var partitionColumns []string
-			for _, columnRef := range group.PartitionBy().PartitionByList() {
-				colName := fmt.Sprintf("`%s`", uniqueColumnName(ctx, columnRef.Column()))
-				partitionColumns = append(
-					partitionColumns,
-					colName,
-				)
-				order := &analyticOrderBy{
-					column: colName,
-					isAsc:  true,
-				}
-				orderColumnNames.values = append(orderColumnNames.values, order)
-				scanOrderBy = append(scanOrderBy, order)
-			}
-			ctx = withAnalyticPartitionColumnNames(ctx, partitionColumns)
-		}
-		if group.OrderBy() != nil {
-			for _, item := range group.OrderBy().OrderByItemList() {
-				colName := uniqueColumnName(ctx, item.ColumnRef().Column())
-				formattedColName := fmt.Sprintf("`%s`", colName)
-				order := &analyticOrderBy{
-					column: formattedColName,
-					isAsc:  !item.IsDescending(),
-				}
-				orderColumnNames.values = append(orderColumnNames.values, order)
-				scanOrderBy = append(scanOrderBy, order)
-			}
-		}
-		if _, err := newNode(group).FormatSQL(ctx); err != nil {
+		partitionColumns, orderColumns := analyticFunctionGroupColumns(ctx, group)
+		scanOrderBy = orderColumns
+
+		// The columns of each group are scoped to the context of the group,
+		// so that they are not shared with the other groups or the analytic functions in the subqueries.
+		groupCtx := withAnalyticFunctionGroup(ctx, group)
+		groupCtx = withAnalyticPartitionColumnNames(groupCtx, partitionColumns)
+		groupCtx = withAnalyticOrderColumnNames(groupCtx, &analyticOrderColumnNames{values: orderColumns})
+		if _, err := newNode(group).FormatSQL(groupCtx); err != nil {
 			return "", err
 		}
-
-		// Reset context after each analytic function group
-		orderColumnNames.values = []*analyticOrderBy{}
-		ctx = withAnalyticPartitionColumnNames(ctx, nil)
 	}
 	columns := []string{}
 	columnMap := columnRefMap(ctx)
@@ -1183,17 +1651,104 @@ func (n *AnalyticScanNode) FormatSQL(ctx context.Context) (string, error) {
 	if len(orderColumnFormattedNames) != 0 {
 		orderBy = fmt.Sprintf("ORDER BY %s", strings.Join(orderColumnFormattedNames, ","))
 	}
-	orderColumnNames.values = []*analyticOrderBy{}
 	return fmt.Sprintf(
-		"SELECT %s FROM (SELECT *, ROW_NUMBER() OVER() AS `row_id` %s) %s",
+		"SELECT %s FROM (SELECT *, %s AS `row_id` %s) %s",
 		strings.Join(columns, ","),
+		rowNumber,
 		formattedInput,
 		orderBy,
 	), nil
 }
 
 func (n *SampleScanNode) FormatSQL(ctx context.Context) (string, error) {
-	return "", nil
+	if n.node == nil {
+		return "", nil
+	}
+	if n.node.WeightColumn() != nil || len(n.node.PartitionByList()) != 0 {
+		return "", fmt.Errorf("TABLESAMPLE with WITH WEIGHT or PARTITION BY is not supported")
+	}
+	size, err := newNode(n.node.Size()).FormatSQL(ctx)
+	if err != nil {
+		return "", err
+	}
+	seed := "NULL"
+	if n.node.RepeatableArgument() != nil {
+		seed, err = newNode(n.node.RepeatableArgument()).FormatSQL(ctx)
+		if err != nil {
+			return "", err
+		}
+	}
+	// Rows are sampled by MOD(ABS(FARM_FINGERPRINT(key)), 100) instead of a random number,
+	// so the result of TABLESAMPLE is reproducible in tests.
+	// The filter is applied to the table directly to sample by the key columns that may not be referenced by the query.
+	var (
+		from string
+		keys []string
+	)
+	if scan, ok := n.node.InputScan().(*ast.TableScanNode); ok {
+		from, keys, err = tableSampleSource(ctx, scan)
+		if err != nil {
+			return "", err
+		}
+	}
+	if from == "" {
+		input, err := newNode(n.node.InputScan()).FormatSQL(ctx)
+		if err != nil {
+			return "", err
+		}
+		formattedInput, err := formatInput(input)
+		if err != nil {
+			return "", err
+		}
+		from = fmt.Sprintf("SELECT * %s", formattedInput)
+		for _, col := range n.node.ColumnList() {
+			keys = append(keys, fmt.Sprintf("`%s`", uniqueColumnName(ctx, col)))
+		}
+	}
+	bucket := fmt.Sprintf("zetasqlite_table_sample_bucket(%s)", strings.Join(append([]string{seed}, keys...), ","))
+	switch n.node.Unit() {
+	case ast.SampleUnitPercent:
+		return fmt.Sprintf("%s WHERE zetasqlite_less(%s, %s)", from, bucket, size), nil
+	case ast.SampleUnitRows:
+		return fmt.Sprintf("%s ORDER BY %s LIMIT %s", from, strings.Join(append([]string{bucket}, keys...), ","), size), nil
+	}
+	return "", fmt.Errorf("unexpected TABLESAMPLE unit %d", n.node.Unit())
+}
+
+// tableSampleSource returns the query selecting the columns of the table scan and the key columns to sample the rows of the table.
+// The key is the primary key of the table, or all columns if the primary key isn't declared.
+// The empty query is returned if the table isn't found in the catalog ( e.g. wildcard tables ).
+func tableSampleSource(ctx context.Context, node *ast.TableScanNode) (string, []string, error) {
+	analyzer := analyzerFromContext(ctx)
+	if analyzer == nil {
+		return "", nil, nil
+	}
+	if _, ok := node.Table().(*WildcardTable); ok {
+		return "", nil, nil
+	}
+	tableName, err := getTableName(ctx, node)
+	if err != nil {
+		return "", nil, err
+	}
+	spec := analyzer.catalog.tableSpec(tableName)
+	if spec == nil {
+		return "", nil, nil
+	}
+	keyColumns := spec.PrimaryKey
+	if len(keyColumns) == 0 {
+		for _, col := range spec.Columns {
+			keyColumns = append(keyColumns, col.Name)
+		}
+	}
+	keys := make([]string, 0, len(keyColumns))
+	for _, col := range keyColumns {
+		keys = append(keys, fmt.Sprintf("`%s`", col))
+	}
+	return fmt.Sprintf(
+		"SELECT %s FROM `%s`",
+		strings.Join(tableScanColumns(ctx, node), ","),
+		tableName,
+	), keys, nil
 }
 
 func (n *ComputedColumnNode) FormatSQL(ctx context.Context) (string, error) {
@@ -1297,8 +1852,11 @@ func (n *ProjectScanNode) FormatSQL(ctx context.Context) (string, error) {
 	return fmt.Sprintf("SELECT %s %s", formattedColumns, formattedInput), nil
 }
 
+// TVFScanNode is never resolved because table-valued functions cannot be registered to the catalog
+// ( CREATE TABLE FUNCTION is rejected by the analyzer ).
+// Returns an error instead of the empty query so that the unsupported call doesn't produce a broken SQL.
 func (n *TVFScanNode) FormatSQL(ctx context.Context) (string, error) {
-	return "", nil
+	return "", fmt.Errorf("table-valued function %s is not supported", n.node.TVF().FullName())
 }
 
 func (n *GroupRowsScanNode) FormatSQL(ctx context.Context) (string, error) {
@@ -1306,13 +1864,59 @@ func (n *GroupRowsScanNode) FormatSQL(ctx context.Context) (string, error) {
 }
 
 func (n *FunctionArgumentNode) FormatSQL(ctx context.Context) (string, error) {
-	return "", nil
+	return "", fmt.Errorf("arguments of table-valued function are not supported")
 }
 
 func (n *ExplainStmtNode) FormatSQL(ctx context.Context) (string, error) {
 	return "", nil
 }
 
+// outputColumnNames returns the column names of the query result.
+// The columns without alias are named by ZetaSQL like `$col1`, so they are renamed to f0_, f1_, ... like BigQuery.
+// The result of SELECT * over JOIN may have the same column names, so the duplicate names are renamed
+// by adding the suffix like BigQuery ( e.g. id, id_1 ). The names are compared case-insensitively.
+func outputColumnNames(columns []*ast.OutputColumnNode) []string {
+	baseNames := make([]string, 0, len(columns))
+	var anonymousColumnNum int
+	for _, col := range columns {
+		name := col.Name()
+		if isInternalColumnName(name) {
+			name = fmt.Sprintf("f%d_", anonymousColumnNum)
+			anonymousColumnNum++
+		}
+		baseNames = append(baseNames, name)
+	}
+	used := make(map[string]struct{}, len(baseNames))
+	for _, name := range baseNames {
+		used[strings.ToLower(name)] = struct{}{}
+	}
+	names := make([]string, 0, len(baseNames))
+	assigned := make(map[string]struct{}, len(baseNames))
+	for _, baseName := range baseNames {
+		name := baseName
+		if _, exists := assigned[strings.ToLower(name)]; exists {
+			for suffix := 1; ; suffix++ {
+				candidate := fmt.Sprintf("%s_%d", baseName, suffix)
+				key := strings.ToLower(candidate)
+				_, usedByOther := used[key]
+				_, alreadyAssigned := assigned[key]
+				if !usedByOther && !alreadyAssigned {
+					name = candidate
+					break
+				}
+			}
+		}
+		assigned[strings.ToLower(name)] = struct{}{}
+		names = append(names, name)
+	}
+	return names
+}
+
+// isInternalColumnName reports whether the name is generated by ZetaSQL for the column without alias.
+func isInternalColumnName(name string) bool {
+	return strings.HasPrefix(name, "$")
+}
+
 // FormatSQL Formats the outermost query statement that runs and produces rows of output, like a SELECT
 // The node's `OutputColumnList()` gives user-visible column names that should be returned. There may be duplicate names,
 // and multiple output columns may reference the same column from `Query()`
@@ -1327,12 +1931,13 @@ func (n *QueryStmtNode) FormatSQL(ctx context.Context) (string, error) {
 	}
 
 	var columns []string
-	for _, outputColumnNode := range n.node.OutputColumnList() {
+	outputColumnNodes := n.node.OutputColumnList()
+	for idx, name := range outputColumnNames(outputColumnNodes) {
 		columns = append(
 			columns,
 			fmt.Sprintf("`%s` AS `%s`",
-				uniqueColumnName(ctx, outputColumnNode.Column()),
-				outputColumnNode.Name(),
+				uniqueColumnName(ctx, outputColumnNodes[idx].Column()),
+				name,
 			),
 		)
 	}
@@ -1461,33 +2066,166 @@ func (n *DropSnapshotTableStmtNode) FormatSQL(ctx context.Context) (string, erro
 	return "", nil
 }
 
+// FormatSQL formats the reference to the recursive WITH entry in the recursive term.
+// SQLite doesn't allow the recursive table to be referenced in the subquery,
+// so the reference is formatted to the single row subquery correlated with the row of the recursive table
+// that is scanned at the top level of the recursive term ( see RecursiveScanNode ).
 func (n *RecursiveRefScanNode) FormatSQL(ctx context.Context) (string, error) {
-	return "", nil
+	if n.node == nil {
+		return "", nil
+	}
+	queryName := recursiveQueryNameFromContext(ctx)
+	if queryName == "" {
+		return "", fmt.Errorf("failed to find the recursive WITH entry referenced by the recursive term")
+	}
+	columnDefs := tableNameToColumnListMap(ctx)[queryName]
+	columns := n.node.ColumnList()
+	if len(columnDefs) != len(columns) {
+		return "", fmt.Errorf(
+			"column num mismatch. defined column num is %d but used %d column",
+			len(columnDefs), len(columns),
+		)
+	}
+	formattedColumns := make([]string, 0, len(columns))
+	for i := 0; i < len(columns); i++ {
+		formattedColumns = append(
+			formattedColumns,
+			fmt.Sprintf("`%s`.`%s` AS `%s`", queryName, uniqueColumnName(ctx, columnDefs[i]), uniqueColumnName(ctx, columns[i])),
+		)
+	}
+	return fmt.Sprintf("(SELECT %s)", strings.Join(formattedColumns, ",")), nil
 }
 
+// FormatSQL formats the recursive WITH entry to the compound SELECT statement of the recursive CTE of SQLite.
+// SQLite evaluates the recursive term for each row of the recursive table, so the rows produced by the recursive term
+// for the row are collected as JSON array and expanded by json_each at the top level of the recursive term.
+// BigQuery doesn't allow aggregations and window functions over the recursive reference, so the result is the same as
+// the evaluation for all rows produced by the previous iteration.
+// For UNION ALL, the iteration that produced the row is kept in the hidden column to stop the runaway recursion.
 func (n *RecursiveScanNode) FormatSQL(ctx context.Context) (string, error) {
-	return "", nil
+	if n.node == nil {
+		return "", nil
+	}
+	queryName := recursiveQueryNameFromContext(ctx)
+	if queryName == "" {
+		return "", fmt.Errorf("recursive query must be defined in the WITH entry")
+	}
+	tableToColumnList := tableNameToColumnListMap(ctx)
+	tableToColumnList[queryName] = n.node.ColumnList()
+
+	nonRecursiveTerm := n.node.NonRecursiveTerm()
+	nonRecursiveQuery, err := newNode(nonRecursiveTerm).FormatSQL(ctx)
+	if err != nil {
+		return "", err
+	}
+	formattedNonRecursiveInput, err := formatInput(nonRecursiveQuery)
+	if err != nil {
+		return "", err
+	}
+	recursiveTerm := n.node.RecursiveTerm()
+	recursiveQuery, err := newNode(recursiveTerm).FormatSQL(ctx)
+	if err != nil {
+		return "", err
+	}
+	formattedRecursiveInput, err := formatInput(recursiveQuery)
+	if err != nil {
+		return "", err
+	}
+
+	var (
+		nonRecursiveColumns []string
+		recursiveColumns    []string
+		recursiveValues     []string
+	)
+	for idx, col := range n.node.ColumnList() {
+		colName := uniqueColumnName(ctx, col)
+		nonRecursiveColumns = append(
+			nonRecursiveColumns,
+			fmt.Sprintf("`%s` AS `%s`", uniqueColumnName(ctx, nonRecursiveTerm.OutputColumnList()[idx]), colName),
+		)
+		recursiveColumns = append(
+			recursiveColumns,
+			fmt.Sprintf("json_extract(zetasqlite_recursive_rows.value, '$[%d]') AS `%s`", idx, colName),
+		)
+		recursiveValues = append(
+			recursiveValues,
+			fmt.Sprintf("`%s`", uniqueColumnName(ctx, recursiveTerm.OutputColumnList()[idx])),
+		)
+	}
+	opType := "UNION"
+	if n.node.OpType() == ast.RecursiveSetOperationTypeUnionAll {
+		opType = "UNION ALL"
+		var maxIterations int
+		if analyzer := analyzerFromContext(ctx); analyzer != nil {
+			maxIterations = analyzer.maxRecursiveIterations
+		}
+		nonRecursiveColumns = append(nonRecursiveColumns, fmt.Sprintf("0 AS `%s`", recursionDepthColumnName))
+		recursiveColumns = append(
+			recursiveColumns,
+			fmt.Sprintf(
+				"zetasqlite_check_recursion_depth(`%s`.`%s` + 1, %d) AS `%s`",
+				queryName, recursionDepthColumnName, maxIterations, recursionDepthColumnName,
+			),
+		)
+	}
+	return fmt.Sprintf(
+		"SELECT %s %s %s SELECT %s FROM `%s`, json_each((SELECT json_group_array(json_array(%s)) %s)) AS zetasqlite_recursive_rows",
+		strings.Join(nonRecursiveColumns, ","),
+		formattedNonRecursiveInput,
+		opType,
+		strings.Join(recursiveColumns, ","),
+		queryName,
+		strings.Join(recursiveValues, ","),
+		formattedRecursiveInput,
+	), nil
 }
 
 func (n *WithScanNode) FormatSQL(ctx context.Context) (string, error) {
 	if n.node == nil {
 		return "", nil
 	}
-	queries := []string{}
+	// The WITH entries of the nested WithScanNode are hoisted to the outermost WITH clause.
+	// The aliases of WITH entries are unique in the resolved AST and the entries are never correlated,
+	// so all entries can be treated as a single WITH clause.
+	// This keeps the nesting depth of the generated query low enough for the SQLite parser.
+	entries := hoistedWithEntriesFromContext(ctx)
+	isOutermost := entries == nil
+	if isOutermost {
+		entries = &hoistedWithEntries{names: map[string]struct{}{}}
+		ctx = withHoistedWithEntries(ctx, entries)
+	}
+	if n.node.Recursive() {
+		entries.recursive = true
+	}
 	for _, entry := range n.node.WithEntryList() {
+		// the nested entries referenced by this entry are appended while formatting it,
+		// so the entries are always defined before they are referenced.
 		sql, err := newNode(entry).FormatSQL(ctx)
 		if err != nil {
 			return "", err
 		}
-		queries = append(queries, sql)
+		if _, exists := entries.names[entry.WithQueryName()]; exists {
+			continue
+		}
+		entries.names[entry.WithQueryName()] = struct{}{}
+		entries.values = append(entries.values, sql)
 	}
 	query, err := newNode(n.node.Query()).FormatSQL(ctx)
 	if err != nil {
 		return "", err
 	}
+	if !isOutermost {
+		return query, nil
+	}
+	with := "WITH"
+	if entries.recursive {
+		// the non-recursive entries can be defined in the WITH RECURSIVE clause of SQLite too.
+		with = "WITH RECURSIVE"
+	}
 	return fmt.Sprintf(
-		"WITH %s %s",
-		strings.Join(queries, ", "),
+		"%s %s %s",
+		with,
+		strings.Join(entries.values, ", "),
 		query,
 	), nil
 }
@@ -1497,13 +2235,16 @@ func (n *WithEntryNode) FormatSQL(ctx context.Context) (string, error) {
 		return "", nil
 	}
 	queryName := n.node.WithQueryName()
+	if _, isRecursive := n.node.WithSubquery().(*ast.RecursiveScanNode); isRecursive {
+		ctx = withRecursiveQueryName(ctx, queryName)
+	}
 	subquery, err := newNode(n.node.WithSubquery()).FormatSQL(ctx)
 	if err != nil {
 		return "", err
 	}
 	tableToColumnList := tableNameToColumnListMap(ctx)
 	tableToColumnList[queryName] = n.node.WithSubquery().ColumnList()
-	return fmt.Sprintf("%s AS ( %s )", queryName, subquery), nil
+	return fmt.Sprintf("`%s` AS ( %s )", queryName, subquery), nil
 }
 
 func (n *OptionNode) FormatSQL(ctx context.Context) (string, error) {
@@ -1550,7 +2291,82 @@ func (n *DMLValueNode) FormatSQL(ctx context.Context) (string, error) {
 }
 
 func (n *DMLDefaultNode) FormatSQL(ctx context.Context) (string, error) {
-	return "", nil
+	return "NULL", nil
+}
+
+// columnDefaultCurrentTimeFunc is passed to the current time functions of the DEFAULT expression as the current time.
+// It returns the time when the row is inserted by SQLite, and it's replaced with the current time of the DML statement
+// when the DEFAULT expression is embedded in the statement.
+const columnDefaultCurrentTimeFunc = "zetasqlite_current_time_unix_nano()"
+
+// columnDefaultValueMap returns the DEFAULT expressions of the table columns to replace DEFAULT keyword of DML.
+// SQLite doesn't support DEFAULT keyword in VALUES and SET clause, so the expression is embedded instead.
+func columnDefaultValueMap(ctx context.Context, table string) map[string]string {
+	columnMap := map[string]string{}
+	for _, col := range columnDefaultValues(ctx, table) {
+		columnMap[col.Name] = bindColumnDefaultValue(ctx, col.DefaultValue)
+	}
+	return columnMap
+}
+
+// columnDefaultValues returns the columns that have DEFAULT in the order of the table columns.
+func columnDefaultValues(ctx context.Context, table string) []*ColumnSpec {
+	analyzer := analyzerFromContext(ctx)
+	if analyzer == nil {
+		return nil
+	}
+	spec := analyzer.catalog.tableSpec(table)
+	if spec == nil {
+		return nil
+	}
+	var columns []*ColumnSpec
+	for _, col := range spec.Columns {
+		if col.DefaultValue != "" {
+			columns = append(columns, col)
+		}
+	}
+	return columns
+}
+
+// bindColumnDefaultValue replaces the current time of the DEFAULT expression with the current time of the statement,
+// so that the current time functions return the same time for all rows of the statement like BigQuery.
+func bindColumnDefaultValue(ctx context.Context, defaultValue string) string {
+	currentTime := CurrentTime(ctx)
+	if currentTime == nil {
+		return defaultValue
+	}
+	return strings.ReplaceAll(defaultValue, columnDefaultCurrentTimeFunc, fmt.Sprint(currentTime.UnixNano()))
+}
+
+// omittedColumnDefaultValues returns the columns omitted by INSERT statement that have DEFAULT, and their DEFAULT expressions.
+// They are inserted explicitly instead of DEFAULT of SQLite, so that the current time of the statement is used.
+func omittedColumnDefaultValues(ctx context.Context, table string, insertColumns []*ast.Column) ([]string, []string) {
+	insertColumnMap := map[string]struct{}{}
+	for _, col := range insertColumns {
+		insertColumnMap[strings.ToLower(col.Name())] = struct{}{}
+	}
+	var columns, values []string
+	for _, col := range columnDefaultValues(ctx, table) {
+		if _, exists := insertColumnMap[strings.ToLower(col.Name)]; exists {
+			continue
+		}
+		columns = append(columns, fmt.Sprintf("`%s`", col.Name))
+		values = append(values, bindColumnDefaultValue(ctx, col.DefaultValue))
+	}
+	return columns, values
+}
+
+func formatDMLValue(ctx context.Context, value *ast.DMLValueNode, column string) (string, error) {
+	if value == nil {
+		return "", nil
+	}
+	if _, ok := value.Value().(*ast.DMLDefaultNode); ok {
+		if defaultValue, exists := columnDefaultValueMapFromContext(ctx)[column]; exists {
+			return defaultValue, nil
+		}
+		return "NULL", nil
+	}
+	return newNode(value).FormatSQL(ctx)
 }
 
 func (n *AssertStmtNode) FormatSQL(ctx context.Context) (string, error) {
@@ -1584,16 +2400,37 @@ func (n *InsertStmtNode) FormatSQL(ctx context.Context) (string, error) {
 	if err != nil {
 		return "", err
 	}
+	columnMap := typeParametersColumnMap(ctx, table)
 	columns := []string{}
 	for _, col := range n.node.InsertColumnList() {
 		columns = append(columns, fmt.Sprintf("`%s`", col.Name()))
 	}
+	defaultColumns, defaultValues := omittedColumnDefaultValues(ctx, table, n.node.InsertColumnList())
+	columns = append(columns, defaultColumns...)
 	query := n.node.Query()
 	if query != nil {
 		stmt, err := newNode(query).FormatSQL(withUseColumnID(ctx))
 		if err != nil {
 			return "", err
 		}
+		if len(columnMap) != 0 {
+			outputColumns := []string{}
+			for idx, col := range n.node.QueryOutputColumnList() {
+				value := fmt.Sprintf("`%s#%d`", col.Name(), col.ColumnID())
+				if spec, exists := columnMap[n.node.InsertColumnList()[idx].Name()]; exists {
+					applied, err := applyTypeParameters(spec, value)
+					if err != nil {
+						return "", err
+					}
+					value = applied
+				}
+				outputColumns = append(outputColumns, value)
+			}
+			stmt = fmt.Sprintf("SELECT %s FROM (%s)", strings.Join(outputColumns, ","), stmt)
+		}
+		if len(defaultValues) != 0 {
+			stmt = fmt.Sprintf("SELECT *, %s FROM (%s)", strings.Join(defaultValues, ","), stmt)
+		}
 		return fmt.Sprintf("INSERT INTO `%s` (%s) %s",
 			table,
 			strings.Join(columns, ","),
@@ -1601,12 +2438,25 @@ func (n *InsertStmtNode) FormatSQL(ctx context.Context) (string, error) {
 		), nil
 	}
 	rows := []string{}
+	valueCtx := withColumnDefaultValueMap(ctx, columnDefaultValueMap(ctx, table))
 	for _, row := range n.node.RowList() {
-		sql, err := newNode(row).FormatSQL(ctx)
-		if err != nil {
-			return "", err
+		values := []string{}
+		for idx, value := range row.ValueList() {
+			sql, err := formatDMLValue(valueCtx, value, n.node.InsertColumnList()[idx].Name())
+			if err != nil {
+				return "", err
+			}
+			if spec, exists := columnMap[n.node.InsertColumnList()[idx].Name()]; exists {
+				applied, err := applyTypeParameters(spec, sql)
+				if err != nil {
+					return "", err
+				}
+				sql = applied
+			}
+			values = append(values, sql)
 		}
-		rows = append(rows, fmt.Sprintf("(%s)", sql))
+		values = append(values, defaultValues...)
+		rows = append(rows, fmt.Sprintf("(%s)", strings.Join(values, ",")))
 	}
 	return fmt.Sprintf("INSERT INTO `%s` (%s) VALUES %s",
 		table,
@@ -1642,10 +2492,23 @@ func (n *UpdateItemNode) FormatSQL(ctx context.Context) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	setValue, err := newNode(n.node.SetValue()).FormatSQL(ctx)
+	var column string
+	if ref, ok := n.node.Target().(*ast.ColumnRefNode); ok {
+		column = ref.Column().Name()
+	}
+	setValue, err := formatDMLValue(ctx, n.node.SetValue(), column)
 	if err != nil {
 		return "", err
 	}
+	if ref, ok := n.node.Target().(*ast.ColumnRefNode); ok {
+		if spec, exists := typeParametersColumnMapFromContext(ctx)[ref.Column().Name()]; exists {
+			applied, err := applyTypeParameters(spec, setValue)
+			if err != nil {
+				return "", err
+			}
+			setValue = applied
+		}
+	}
 	return fmt.Sprintf("%s=%s", target, setValue), nil
 }
 
@@ -1662,8 +2525,10 @@ func (n *UpdateStmtNode) FormatSQL(ctx context.Context) (string, error) {
 		return "", err
 	}
 	updateItems := []string{}
+	itemCtx := withTypeParametersColumnMap(ctx, typeParametersColumnMap(ctx, table))
+	itemCtx = withColumnDefaultValueMap(itemCtx, columnDefaultValueMap(ctx, table))
 	for _, item := range n.node.UpdateItemList() {
-		sql, err := newNode(item).FormatSQL(ctx)
+		sql, err := newNode(item).FormatSQL(itemCtx)
 		if err != nil {
 			return "", err
 		}
@@ -1877,7 +2742,7 @@ func (n *CreateTableFunctionStmtNode) FormatSQL(ctx context.Context) (string, er
 }
 
 func (n *RelationArgumentScanNode) FormatSQL(ctx context.Context) (string, error) {
-	return "", nil
+	return "", fmt.Errorf("TABLE argument %s of table-valued function is not supported", n.node.Name())
 }
 
 func (n *ArgumentListNode) FormatSQL(ctx context.Context) (string, error) {
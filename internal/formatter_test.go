@@ -0,0 +1,95 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-zetasql"
+	ast "github.com/goccy/go-zetasql/resolved_ast"
+)
+
+func TestValidateAnalyticWindowArgs(t *testing.T) {
+	opt, err := newAnalyzerOptions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := zetasql.AnalyzeStatement(`
+SELECT
+  SUM(x) OVER (PARTITION BY y) AS a,
+  SUM(x) OVER (ORDER BY x DESC) AS b
+FROM (SELECT 1 AS x, 2 AS y)`,
+		newSimpleCatalog("test"),
+		opt,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var groups []*ast.AnalyticFunctionGroupNode
+	_ = ast.Walk(out.Statement(), func(n ast.Node) error {
+		if scan, ok := n.(*ast.AnalyticScanNode); ok {
+			groups = append(groups, scan.FunctionGroupList()...)
+		}
+		return nil
+	})
+	if len(groups) != 2 {
+		t.Fatalf("expected 2 analytic function groups but got %d", len(groups))
+	}
+	ctx := context.Background()
+	for idx, group := range groups {
+		partitionColumns, orderColumns := analyticFunctionGroupColumns(ctx, group)
+		if err := validateAnalyticWindowArgs(withAnalyticFunctionGroup(ctx, group), "sum", partitionColumns, orderColumns); err != nil {
+			t.Fatalf("failed to validate the columns of group %d: %v", idx, err)
+		}
+		// the columns of the other group leaked into the context must be rejected.
+		other := groups[1-idx]
+		if err := validateAnalyticWindowArgs(withAnalyticFunctionGroup(ctx, other), "sum", partitionColumns, orderColumns); err == nil {
+			t.Fatalf("expected error for the columns of group %d passed to the other group", idx)
+		}
+	}
+}
+
+func TestLetExprEvaluatesAssignmentsOnce(t *testing.T) {
+	opt, err := newAnalyzerOptions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// LetExpr is created by the rewriter of FLATTEN, which refers to the flattened value twice.
+	opt.Language().EnableLanguageFeature(zetasql.FeatureV13UnnestAndFlattenArrays)
+	out, err := zetasql.AnalyzeStatement(
+		`SELECT FLATTEN((SELECT [STRUCT(RAND() AS r), STRUCT(RAND() AS r)]).r)`,
+		newSimpleCatalog("test"),
+		opt,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var letExpr *ast.LetExprNode
+	_ = ast.Walk(out.Statement(), func(n ast.Node) error {
+		if expr, ok := n.(*ast.LetExprNode); ok && letExpr == nil {
+			letExpr = expr
+		}
+		return nil
+	})
+	if letExpr == nil {
+		t.Fatal("failed to find LetExpr node")
+	}
+	ctx := context.Background()
+	ctx = withColumnRefMap(ctx, map[string]string{})
+	ctx = withTableNameToColumnListMap(ctx, map[string][]*ast.Column{})
+	ctx = withFuncMap(ctx, map[string]*FunctionSpec{})
+	sql, err := newNode(letExpr).FormatSQL(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count := strings.Count(sql, "zetasqlite_rand("); count != 2 {
+		t.Fatalf("expected the assignment to be formatted once, but RAND() is called %d times: %s", count, sql)
+	}
+	for _, assignment := range letExpr.AssignmentList() {
+		column := fmt.Sprintf("AS `%s`", uniqueColumnName(ctx, assignment.Column()))
+		if count := strings.Count(sql, column); count != 1 {
+			t.Fatalf("expected the column %s to be assigned once, but got %d: %s", column, count, sql)
+		}
+	}
+}
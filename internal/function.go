@@ -2,6 +2,8 @@ package internal
 
 import (
 	"fmt"
+	"math"
+	"math/big"
 	"regexp"
 	"strings"
 	"time"
@@ -25,16 +27,39 @@ func OP_DIV(a, b Value) (Value, error) {
 	return a.Div(b)
 }
 
-func EQ(a, b Value) (Value, error) {
-	cond, err := a.EQ(b)
-	if err != nil {
-		return nil, err
+func UNARY_MINUS(a Value) (Value, error) {
+	switch v := a.(type) {
+	case IntValue:
+		if v == math.MinInt64 {
+			return nil, fmt.Errorf("int64 overflow: -%d", v)
+		}
+		return -v, nil
+	case FloatValue:
+		return -v, nil
+	case *NumericValue:
+		return &NumericValue{Rat: new(big.Rat).Neg(v.Rat), isBigNumeric: v.isBigNumeric}, nil
+	case *IntervalValue:
+		iv := *v.IntervalValue
+		iv.Years, iv.Months, iv.Days = -iv.Years, -iv.Months, -iv.Days
+		iv.Hours, iv.Minutes, iv.Seconds, iv.SubSecondNanos = -iv.Hours, -iv.Minutes, -iv.Seconds, -iv.SubSecondNanos
+		return &IntervalValue{IntervalValue: &iv}, nil
 	}
-	return BoolValue(cond), nil
+	return nil, fmt.Errorf("unsupported unary minus for %T", a)
+}
+
+func EQ(a, b Value) (Value, error) {
+	return equalValue(a, b)
 }
 
 func NOT_EQ(a, b Value) (Value, error) {
-	cond, err := a.EQ(b)
+	eq, err := equalValue(a, b)
+	if err != nil {
+		return nil, err
+	}
+	if eq == nil {
+		return nil, nil
+	}
+	cond, err := eq.ToBool()
 	if err != nil {
 		return nil, err
 	}
@@ -240,11 +265,17 @@ func IN(a Value, values ...Value) (Value, error) {
 	if a == nil {
 		return nil, nil
 	}
+	var unknown bool
 	for _, v := range values {
-		if v == nil {
+		eq, err := equalValue(a, v)
+		if err != nil {
+			return nil, err
+		}
+		if eq == nil {
+			unknown = true
 			continue
 		}
-		cond, err := a.EQ(v)
+		cond, err := eq.ToBool()
 		if err != nil {
 			return nil, err
 		}
@@ -252,6 +283,9 @@ func IN(a Value, values ...Value) (Value, error) {
 			return BoolValue(true), nil
 		}
 	}
+	if unknown {
+		return nil, nil
+	}
 	return BoolValue(false), nil
 }
 
@@ -381,7 +415,14 @@ func NULLIF(expr, exprToMatch Value) (Value, error) {
 	if expr == nil {
 		return nil, nil
 	}
-	cond, err := expr.EQ(exprToMatch)
+	eq, err := equalValue(expr, exprToMatch)
+	if err != nil {
+		return nil, err
+	}
+	if eq == nil {
+		return expr, nil
+	}
+	cond, err := eq.ToBool()
 	if err != nil {
 		return nil, err
 	}
@@ -451,6 +492,9 @@ func EXTRACT(v Value, part, zone string) (Value, error) {
 			}
 			t = t.In(loc)
 		}
+		if start, exists := WeekPartToOffset[part]; exists {
+			return IntValue(weekNumber(t, time.Weekday(start))), nil
+		}
 		switch part {
 		case "ISOYEAR":
 			year, _ := t.ISOWeek()
@@ -462,9 +506,6 @@ func EXTRACT(v Value, part, zone string) (Value, error) {
 		case "ISOWEEK":
 			_, week := t.ISOWeek()
 			return IntValue(week), nil
-		case "WEEK":
-			_, week := t.AddDate(0, 0, -int(t.Weekday())).ISOWeek()
-			return IntValue(week), nil
 		case "DAY":
 			return IntValue(t.Day()), nil
 		case "DAYOFYEAR":
@@ -472,17 +513,7 @@ func EXTRACT(v Value, part, zone string) (Value, error) {
 		case "DAYOFWEEK":
 			return IntValue(int(t.Weekday()) + 1), nil
 		case "QUARTER":
-			day := t.YearDay()
-			const quarterDays = 91
-			switch {
-			case day <= quarterDays:
-				return IntValue(1), nil
-			case day <= quarterDays*2:
-				return IntValue(2), nil
-			case day <= quarterDays*3:
-				return IntValue(3), nil
-			}
-			return IntValue(4), nil
+			return IntValue((int(t.Month())-1)/3 + 1), nil
 		case "HOUR":
 			return IntValue(t.Hour()), nil
 		case "MINUTE":
@@ -505,6 +536,13 @@ func EXTRACT(v Value, part, zone string) (Value, error) {
 	return nil, fmt.Errorf("EXTRACT: value type must be INTERVAL or DATE or DATETIME or TIME or TIMESTAMP")
 }
 
+// weekNumber returns the week number of t in the range [0, 53] where the weeks begin on start.
+// The days before the first start day of the year are in week 0.
+func weekNumber(t time.Time, start time.Weekday) int {
+	daysSinceStart := (int(t.Weekday()) - int(start) + 7) % 7
+	return (t.YearDay() - 1 - daysSinceStart + 7) / 7
+}
+
 func GENERATE_UUID() (Value, error) {
 	id := uuid.NewString()
 	return StringValue(id), nil
@@ -526,7 +564,7 @@ func CAST(expr Value, fromType, toType *Type, isSafeCast bool) (Value, error) {
 		}
 		return nil, err
 	}
-	casted, err := CastValue(to, fromValue)
+	casted, err := castValueByPosition(to, fromValue)
 	if err != nil {
 		if isSafeCast {
 			return nil, nil
@@ -1,7 +1,9 @@
 package internal
 
 import (
+	"bytes"
 	"fmt"
+	"math/big"
 	"sort"
 	"strings"
 	"sync"
@@ -140,8 +142,58 @@ func (f *ARRAY_CONCAT_AGG) Done() (Value, error) {
 	}, nil
 }
 
+// sumAccumulator adds up the values of SUM and AVG.
+// INT64 values are added with arbitrary precision like ZetaSQL,
+// so the overflow is reported only if the final result doesn't fit in INT64.
+type sumAccumulator struct {
+	sum    Value
+	intSum *big.Int
+}
+
+func (a *sumAccumulator) add(v Value) error {
+	if iv, ok := v.(IntValue); ok {
+		if a.intSum == nil {
+			a.intSum = new(big.Int)
+		}
+		a.intSum.Add(a.intSum, big.NewInt(int64(iv)))
+		return nil
+	}
+	if a.sum == nil {
+		a.sum = v
+		return nil
+	}
+	added, err := a.sum.Add(v)
+	if err != nil {
+		return err
+	}
+	a.sum = added
+	return nil
+}
+
+func (a *sumAccumulator) empty() bool {
+	return a.sum == nil && a.intSum == nil
+}
+
+func (a *sumAccumulator) result() (Value, error) {
+	if a.intSum == nil {
+		return a.sum, nil
+	}
+	if !a.intSum.IsInt64() {
+		return nil, fmt.Errorf("int64 overflow")
+	}
+	return IntValue(a.intSum.Int64()), nil
+}
+
+func (a *sumAccumulator) float64() (float64, error) {
+	if a.intSum == nil {
+		return a.sum.ToFloat64()
+	}
+	f, _ := new(big.Float).SetInt(a.intSum).Float64()
+	return f, nil
+}
+
 type AVG struct {
-	sum Value
+	sum sumAccumulator
 	num int64
 }
 
@@ -149,24 +201,18 @@ func (f *AVG) Step(v Value, opt *AggregatorOption) error {
 	if v == nil {
 		return nil
 	}
-	if f.sum == nil {
-		f.sum = v
-	} else {
-		added, err := f.sum.Add(v)
-		if err != nil {
-			return err
-		}
-		f.sum = added
+	if err := f.sum.add(v); err != nil {
+		return err
 	}
 	f.num++
 	return nil
 }
 
 func (f *AVG) Done() (Value, error) {
-	if f.sum == nil {
+	if f.sum.empty() {
 		return nil, nil
 	}
-	base, err := f.sum.ToFloat64()
+	base, err := f.sum.float64()
 	if err != nil {
 		return nil, err
 	}
@@ -424,22 +470,23 @@ func (f *MIN) Done() (Value, error) {
 
 type STRING_AGG struct {
 	values []*OrderedValue
-	delim  string
+	delim  Value
 	opt    *AggregatorOption
 	once   sync.Once
 }
 
-func (f *STRING_AGG) Step(v Value, delim string, opt *AggregatorOption) error {
+func (f *STRING_AGG) Step(v Value, delim Value, opt *AggregatorOption) error {
 	if v == nil {
 		return nil
 	}
+	var err error
 	f.once.Do(func() {
-		if delim == "" {
-			delim = ","
-		}
-		f.delim = delim
+		f.delim, err = stringAggDelimiter(v, delim)
 		f.opt = opt
 	})
+	if err != nil {
+		return err
+	}
 	f.values = append(f.values, &OrderedValue{
 		OrderBy: opt.OrderBy,
 		Value:   v,
@@ -447,6 +494,64 @@ func (f *STRING_AGG) Step(v Value, delim string, opt *AggregatorOption) error {
 	return nil
 }
 
+// stringAggDelimiter validates the delimiter of STRING_AGG.
+// The delimiter must have the same type as the aggregated value, and the default delimiter is a comma.
+func stringAggDelimiter(v, delim Value) (Value, error) {
+	switch v.(type) {
+	case BytesValue:
+		if delim == nil {
+			return BytesValue(","), nil
+		}
+		if _, ok := delim.(BytesValue); !ok {
+			return nil, fmt.Errorf("STRING_AGG: delimiter must be BYTES for BYTES input")
+		}
+	default:
+		if delim == nil {
+			return StringValue(","), nil
+		}
+		if _, ok := delim.(BytesValue); ok {
+			return nil, fmt.Errorf("STRING_AGG: delimiter must be STRING for STRING input")
+		}
+	}
+	return delim, nil
+}
+
+// joinStringAggValues concatenates values by delimiter.
+// If the values are BYTES, the result is also BYTES.
+func joinStringAggValues(values []Value, delim Value) (Value, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	if _, ok := values[0].(BytesValue); ok {
+		sep, err := delim.ToBytes()
+		if err != nil {
+			return nil, err
+		}
+		b := make([][]byte, 0, len(values))
+		for _, v := range values {
+			bv, err := v.ToBytes()
+			if err != nil {
+				return nil, err
+			}
+			b = append(b, bv)
+		}
+		return BytesValue(bytes.Join(b, sep)), nil
+	}
+	sep, err := delim.ToString()
+	if err != nil {
+		return nil, err
+	}
+	texts := make([]string, 0, len(values))
+	for _, v := range values {
+		text, err := v.ToString()
+		if err != nil {
+			return nil, err
+		}
+		texts = append(texts, text)
+	}
+	return StringValue(strings.Join(texts, sep)), nil
+}
+
 func sortAggregatedValues(values []*OrderedValue, opt *AggregatorOption) []*OrderedValue {
 	if opt != nil && len(opt.OrderBy) == 0 {
 		return values
@@ -491,45 +596,26 @@ func (f *STRING_AGG) Done() (Value, error) {
 		}
 		f.values = f.values[:minLen]
 	}
-	values := make([]string, 0, len(f.values))
-
-	foundNotNilValue := false
+	values := make([]Value, 0, len(f.values))
 	for _, v := range f.values {
-		text, err := v.Value.ToString()
-		if err != nil {
-			return nil, err
-		}
-		foundNotNilValue = true
-		values = append(values, text)
-	}
-	if !foundNotNilValue {
-		return nil, nil
+		values = append(values, v.Value)
 	}
-	return StringValue(strings.Join(values, f.delim)), nil
+	return joinStringAggValues(values, f.delim)
 }
 
 type SUM struct {
-	sum Value
+	sum sumAccumulator
 }
 
 func (f *SUM) Step(v Value, opt *AggregatorOption) error {
 	if v == nil {
 		return nil
 	}
-	if f.sum == nil {
-		f.sum = v
-	} else {
-		added, err := f.sum.Add(v)
-		if err != nil {
-			return err
-		}
-		f.sum = added
-	}
-	return nil
+	return f.sum.add(v)
 }
 
 func (f *SUM) Done() (Value, error) {
-	return f.sum, nil
+	return f.sum.result()
 }
 
 type CORR struct {
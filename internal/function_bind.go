@@ -50,13 +50,19 @@ func convertArgs(args ...interface{}) ([]Value, error) {
 }
 
 type Aggregator struct {
+	name        string
 	distinctMap map[string]struct{}
 	distinctNil bool
 	step        func([]Value, *AggregatorOption) error
 	done        func() (Value, error)
+	// memory is used to track the size of DISTINCT keys and values retained by the aggregator if it's not nil.
+	memory        *aggregationMemory
+	retainsValues bool
+	reserved      int64
 }
 
-func (a *Aggregator) Step(stepArgs ...interface{}) error {
+func (a *Aggregator) Step(stepArgs ...interface{}) (e error) {
+	defer recoverFunctionPanic(a.name, &e)
 	values, err := convertArgs(stepArgs...)
 	if err != nil {
 		return err
@@ -75,6 +81,7 @@ func (a *Aggregator) Step(stepArgs ...interface{}) error {
 			return nil
 		}
 	}
+	var size int64
 	if opt.Distinct {
 		if len(values) < 1 {
 			return fmt.Errorf("DISTINCT option required at least one argument")
@@ -85,7 +92,7 @@ func (a *Aggregator) Step(stepArgs ...interface{}) error {
 			}
 			a.distinctNil = true
 		} else {
-			key, err := values[0].ToString()
+			key, err := canonicalKey(values[0])
 			if err != nil {
 				return err
 			}
@@ -93,12 +100,29 @@ func (a *Aggregator) Step(stepArgs ...interface{}) error {
 				return nil
 			}
 			a.distinctMap[key] = struct{}{}
+			size += int64(len(key))
 		}
 	}
+	if a.memory != nil {
+		if a.retainsValues {
+			for _, v := range values {
+				size += estimateValueSize(v)
+			}
+		}
+		if err := a.memory.reserve(size); err != nil {
+			return err
+		}
+		a.reserved += size
+	}
 	return a.step(values, opt)
 }
 
-func (a *Aggregator) Done() (interface{}, error) {
+func (a *Aggregator) Done() (_ interface{}, e error) {
+	defer recoverFunctionPanic(a.name, &e)
+	if a.memory != nil {
+		a.memory.release(a.reserved)
+		a.reserved = 0
+	}
 	ret, err := a.done()
 	if err != nil {
 		return nil, err
@@ -117,18 +141,34 @@ func newAggregator(
 }
 
 type WindowAggregator struct {
+	name        string
 	distinctMap map[string]struct{}
 	agg         *WindowFuncAggregatedStatus
 	step        func([]Value, *WindowFuncStatus, *WindowFuncAggregatedStatus) error
 	done        func(*WindowFuncAggregatedStatus) (Value, error)
 	once        sync.Once
+	// memory is used to track the size of the values retained by the aggregator if it's not nil.
+	// The window aggregator keeps all rows of the partition with their partition and order values until it's done.
+	memory   *aggregationMemory
+	reserved int64
 }
 
-func (a *WindowAggregator) Step(stepArgs ...interface{}) error {
+func (a *WindowAggregator) Step(stepArgs ...interface{}) (e error) {
+	defer recoverFunctionPanic(a.name, &e)
 	values, err := convertArgs(stepArgs...)
 	if err != nil {
 		return err
 	}
+	if a.memory != nil {
+		var size int64
+		for _, v := range values {
+			size += estimateValueSize(v)
+		}
+		if err := a.memory.reserve(size); err != nil {
+			return err
+		}
+		a.reserved += size
+	}
 	values, opt := parseAggregateOptions(values...)
 	values, windowOpt := parseWindowOptions(values...)
 	a.once.Do(func() {
@@ -137,7 +177,12 @@ func (a *WindowAggregator) Step(stepArgs ...interface{}) error {
 	return a.step(values, windowOpt, a.agg)
 }
 
-func (a *WindowAggregator) Done() (interface{}, error) {
+func (a *WindowAggregator) Done() (_ interface{}, e error) {
+	defer recoverFunctionPanic(a.name, &e)
+	if a.memory != nil {
+		a.memory.release(a.reserved)
+		a.reserved = 0
+	}
 	ret, err := a.done(a.agg)
 	if err != nil {
 		return nil, err
@@ -156,6 +201,113 @@ func newWindowAggregator(
 	}
 }
 
+// numericCoercionFuncNameMap is a set of functions whose numeric arguments are compared or computed with each other.
+// The arguments of these functions are implicitly coerced to the common supertype before calling them.
+var numericCoercionFuncNameMap = map[string]struct{}{
+	"add":                  {},
+	"subtract":             {},
+	"multiply":             {},
+	"divide":               {},
+	"equal":                {},
+	"not_equal":            {},
+	"greater":              {},
+	"greater_or_equal":     {},
+	"less":                 {},
+	"less_or_equal":        {},
+	"between":              {},
+	"in":                   {},
+	"is_distinct_from":     {},
+	"is_not_distinct_from": {},
+	"coalesce":             {},
+	"if":                   {},
+	"ifnull":               {},
+	"nullif":               {},
+	"ieee_divide":          {},
+	"pow":                  {},
+	"power":                {},
+	"greatest":             {},
+	"least":                {},
+	"div":                  {},
+	"mod":                  {},
+	"safe_divide":          {},
+	"safe_multiply":        {},
+	"safe_add":             {},
+	"safe_subtract":        {},
+}
+
+// numericSupertypeRank returns the rank of numeric types in the order of coercion ( INT64 -> NUMERIC -> BIGNUMERIC -> FLOAT64 ).
+// Zero is returned for non numeric values.
+func numericSupertypeRank(v Value) int {
+	switch vv := v.(type) {
+	case IntValue:
+		return 1
+	case *NumericValue:
+		if vv.isBigNumeric {
+			return 3
+		}
+		return 2
+	case FloatValue:
+		return 4
+	}
+	return 0
+}
+
+// coerceArgs converts the arguments by implicit coercion rules.
+// NULL and non numeric arguments are returned as is.
+func coerceArgs(funcName string, args []Value) ([]Value, error) {
+	if _, exists := numericCoercionFuncNameMap[funcName]; !exists {
+		return args, nil
+	}
+	var (
+		minRank int
+		maxRank int
+	)
+	for _, arg := range args {
+		rank := numericSupertypeRank(arg)
+		if rank == 0 {
+			continue
+		}
+		if minRank == 0 || rank < minRank {
+			minRank = rank
+		}
+		if rank > maxRank {
+			maxRank = rank
+		}
+	}
+	if minRank == maxRank {
+		return args, nil
+	}
+	ret := make([]Value, 0, len(args))
+	for _, arg := range args {
+		rank := numericSupertypeRank(arg)
+		if rank == 0 || rank == maxRank {
+			ret = append(ret, arg)
+			continue
+		}
+		coerced, err := coerceNumericValue(arg, maxRank)
+		if err != nil {
+			return nil, fmt.Errorf("failed to coerce argument of %s: %w", funcName, err)
+		}
+		ret = append(ret, coerced)
+	}
+	return ret, nil
+}
+
+func coerceNumericValue(v Value, rank int) (Value, error) {
+	if rank == 4 {
+		f, err := v.ToFloat64()
+		if err != nil {
+			return nil, err
+		}
+		return FloatValue(f), nil
+	}
+	r, err := v.ToRat()
+	if err != nil {
+		return nil, err
+	}
+	return &NumericValue{Rat: r, isBigNumeric: rank == 3}, nil
+}
+
 func bindAdd(args ...Value) (Value, error) {
 	if existsNull(args) {
 		return nil, nil
@@ -184,6 +336,13 @@ func bindOpDiv(args ...Value) (Value, error) {
 	return OP_DIV(args[0], args[1])
 }
 
+func bindUnaryMinus(args ...Value) (Value, error) {
+	if existsNull(args) {
+		return nil, nil
+	}
+	return UNARY_MINUS(args[0])
+}
+
 func bindEqual(args ...Value) (Value, error) {
 	if existsNull(args) {
 		return nil, nil
@@ -595,6 +754,9 @@ func bindJustifyInterval(args ...Value) (Value, error) {
 }
 
 func bindParseNumeric(args ...Value) (Value, error) {
+	if existsNull(args) {
+		return nil, nil
+	}
 	numeric, err := args[0].ToString()
 	if err != nil {
 		return nil, err
@@ -603,6 +765,9 @@ func bindParseNumeric(args ...Value) (Value, error) {
 }
 
 func bindParseBigNumeric(args ...Value) (Value, error) {
+	if existsNull(args) {
+		return nil, nil
+	}
 	numeric, err := args[0].ToString()
 	if err != nil {
 		return nil, err
@@ -624,6 +789,21 @@ func bindFarmFingerprint(args ...Value) (Value, error) {
 	return FARM_FINGERPRINT(v)
 }
 
+func bindTableSampleBucket(args ...Value) (Value, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("TABLE_SAMPLE_BUCKET: invalid argument num %d", len(args))
+	}
+	var seed *int64
+	if args[0] != nil {
+		v, err := args[0].ToInt64()
+		if err != nil {
+			return nil, err
+		}
+		seed = &v
+	}
+	return TABLE_SAMPLE_BUCKET(seed, args[1:])
+}
+
 func bindMD5(args ...Value) (Value, error) {
 	if len(args) != 1 {
 		return nil, fmt.Errorf("MD5: invalid argument num %d", len(args))
@@ -788,6 +968,46 @@ func bindCollate(args ...Value) (Value, error) {
 	return COLLATE(value, spec)
 }
 
+func bindCollationKey(args ...Value) (Value, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("COLLATION_KEY: invalid argument num %d", len(args))
+	}
+	if existsNull(args) {
+		return nil, nil
+	}
+	value, err := args[0].ToString()
+	if err != nil {
+		return nil, fmt.Errorf("COLLATION_KEY: value must be string: %w", err)
+	}
+	spec, err := args[1].ToString()
+	if err != nil {
+		return nil, fmt.Errorf("COLLATION_KEY: collation_specification must be string: %w", err)
+	}
+	return COLLATION_KEY(value, spec)
+}
+
+func bindApplyTypeParameters(args ...Value) (Value, error) {
+	if len(args) != 5 {
+		return nil, fmt.Errorf("APPLY_TYPE_PARAMETERS: invalid argument num %d", len(args))
+	}
+	if args[0] == nil {
+		return nil, nil
+	}
+	column, err := args[1].ToString()
+	if err != nil {
+		return nil, err
+	}
+	params := make([]int64, 0, 3)
+	for _, arg := range args[2:] {
+		i64, err := arg.ToInt64()
+		if err != nil {
+			return nil, err
+		}
+		params = append(params, i64)
+	}
+	return APPLY_TYPE_PARAMETERS(args[0], column, params[0], params[1], params[2])
+}
+
 func bindConcat(args ...Value) (Value, error) {
 	if len(args) < 1 {
 		return nil, fmt.Errorf("CONCAT: invalid argument num %d", len(args))
@@ -822,6 +1042,24 @@ func bindEndsWith(args ...Value) (Value, error) {
 	return ENDS_WITH(args[0], args[1])
 }
 
+func bindEditDistance(args ...Value) (Value, error) {
+	if len(args) != 2 && len(args) != 3 {
+		return nil, fmt.Errorf("EDIT_DISTANCE: invalid argument num %d", len(args))
+	}
+	if existsNull(args) {
+		return nil, nil
+	}
+	var maxDistance *int64
+	if len(args) == 3 {
+		v, err := args[2].ToInt64()
+		if err != nil {
+			return nil, err
+		}
+		maxDistance = &v
+	}
+	return EDIT_DISTANCE(args[0], args[1], maxDistance)
+}
+
 func bindFormat(args ...Value) (Value, error) {
 	if len(args) == 0 {
 		return nil, fmt.Errorf("FORMAT: invalid argument num %d", len(args))
@@ -914,7 +1152,7 @@ func bindInstr(args ...Value) (Value, error) {
 		return nil, nil
 	}
 	var (
-		position   int64 = 0
+		position   int64 = 1
 		occurrence int64 = 1
 	)
 	if len(args) >= 3 {
@@ -1240,6 +1478,36 @@ func bindSplit(args ...Value) (Value, error) {
 	return SPLIT(args[0], delim)
 }
 
+func bindSplitSubstr(args ...Value) (Value, error) {
+	if len(args) != 3 && len(args) != 4 {
+		return nil, fmt.Errorf("SPLIT_SUBSTR: invalid argument num %d", len(args))
+	}
+	if existsNull(args) {
+		return nil, nil
+	}
+	value, err := args[0].ToString()
+	if err != nil {
+		return nil, err
+	}
+	delimiter, err := args[1].ToString()
+	if err != nil {
+		return nil, err
+	}
+	startSplit, err := args[2].ToInt64()
+	if err != nil {
+		return nil, err
+	}
+	var count *int64
+	if len(args) == 4 {
+		v, err := args[3].ToInt64()
+		if err != nil {
+			return nil, err
+		}
+		count = &v
+	}
+	return SPLIT_SUBSTR(value, delimiter, startSplit, count)
+}
+
 func bindStartsWith(args ...Value) (Value, error) {
 	if len(args) != 2 {
 		return nil, fmt.Errorf("STARTS_WITH: invalid argument num %d", len(args))
@@ -1520,11 +1788,8 @@ func bindToJson(args ...Value) (Value, error) {
 	if len(args) != 1 && len(args) != 2 {
 		return nil, fmt.Errorf("TO_JSON: invalid argument num %d", len(args))
 	}
-	if existsNull(args) {
-		return nil, nil
-	}
 	var stringifyWideNumbers bool
-	if len(args) == 2 {
+	if len(args) == 2 && args[1] != nil {
 		b, err := args[1].ToBool()
 		if err != nil {
 			return nil, err
@@ -1539,7 +1804,7 @@ func bindToJsonString(args ...Value) (Value, error) {
 		return nil, fmt.Errorf("TO_JSON_STRING: invalid argument num %d", len(args))
 	}
 	var prettyPrint bool
-	if len(args) == 2 {
+	if len(args) == 2 && args[1] != nil {
 		b, err := args[1].ToBool()
 		if err != nil {
 			return nil, err
@@ -1553,37 +1818,55 @@ func bindBool(args ...Value) (Value, error) {
 	if len(args) != 1 {
 		return nil, fmt.Errorf("BOOL: invalid argument num %d", len(args))
 	}
-	return args[0], nil
+	if existsNull(args) {
+		return nil, nil
+	}
+	jsonValue, ok := args[0].(JsonValue)
+	if !ok {
+		return nil, fmt.Errorf("BOOL: failed to convert %T to JSON value", args[0])
+	}
+	return JSON_BOOL(jsonValue)
 }
 
 func bindInt64(args ...Value) (Value, error) {
 	if len(args) != 1 {
 		return nil, fmt.Errorf("INT64: invalid argument num %d", len(args))
 	}
-	return args[0], nil
+	if existsNull(args) {
+		return nil, nil
+	}
+	jsonValue, ok := args[0].(JsonValue)
+	if !ok {
+		return nil, fmt.Errorf("INT64: failed to convert %T to JSON value", args[0])
+	}
+	return JSON_INT64(jsonValue)
 }
 
 func bindDouble(args ...Value) (Value, error) {
 	if len(args) != 2 {
 		return nil, fmt.Errorf("FLOAT64: invalid argument num %d", len(args))
 	}
+	if existsNull(args) {
+		return nil, nil
+	}
+	jsonValue, ok := args[0].(JsonValue)
+	if !ok {
+		return nil, fmt.Errorf("FLOAT64: failed to convert %T to JSON value", args[0])
+	}
 	mode, err := args[1].ToString()
 	if err != nil {
 		return nil, err
 	}
-	switch mode {
-	case "exact":
-		return args[0], nil
-	case "round":
-		return args[0], nil
-	}
-	return nil, fmt.Errorf("unexpected wide_number_mode: %s", mode)
+	return JSON_FLOAT64(jsonValue, mode)
 }
 
 func bindJsonType(args ...Value) (Value, error) {
 	if len(args) != 1 {
 		return nil, fmt.Errorf("JSON_TYPE: invalid argument num %d", len(args))
 	}
+	if existsNull(args) {
+		return nil, nil
+	}
 	value, ok := args[0].(JsonValue)
 	if !ok {
 		return nil, fmt.Errorf("JSON_TYPE: failed to convert %T to JSON value", args[0])
@@ -2468,7 +2751,7 @@ func bindString(args ...Value) (Value, error) {
 	}
 	jsonValue, ok := args[0].(JsonValue)
 	if ok {
-		return StringValue(fmt.Sprint(jsonValue.Interface())), nil
+		return JSON_STRING(jsonValue)
 	}
 	t, err := args[0].ToTime()
 	if err != nil {
@@ -3313,12 +3596,9 @@ func bindStringAgg() func() *Aggregator {
 		fn := &STRING_AGG{}
 		return newAggregator(
 			func(args []Value, opt *AggregatorOption) error {
-				if len(args) == 1 {
-					return fn.Step(args[0], "", opt)
-				}
-				delim, err := args[1].ToString()
-				if err != nil {
-					return err
+				var delim Value
+				if len(args) > 1 {
+					delim = args[1]
 				}
 				return fn.Step(args[0], delim, opt)
 			},
@@ -3741,13 +4021,9 @@ func bindWindowStringAgg() func() *WindowAggregator {
 		fn := &WINDOW_STRING_AGG{}
 		return newWindowAggregator(
 			func(args []Value, windowOpt *WindowFuncStatus, agg *WindowFuncAggregatedStatus) error {
-				var delim string
+				var delim Value
 				if len(args) > 1 {
-					d, err := args[1].ToString()
-					if err != nil {
-						return err
-					}
-					delim = d
+					delim = args[1]
 				}
 				return fn.Step(args[0], delim, windowOpt, agg)
 			},
@@ -0,0 +1,133 @@
+package internal
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestCoerceArgs(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		funcName string
+		args     []Value
+		expected Value
+	}{
+		{
+			name:     "int64 and float64",
+			funcName: "add",
+			args:     []Value{IntValue(1), FloatValue(1.5)},
+			expected: FloatValue(2.5),
+		},
+		{
+			name:     "float64 and int64",
+			funcName: "equal",
+			args:     []Value{FloatValue(1.5), IntValue(1)},
+			expected: BoolValue(false),
+		},
+		{
+			name:     "int64 and numeric",
+			funcName: "less",
+			args:     []Value{IntValue(1), &NumericValue{Rat: big.NewRat(3, 2)}},
+			expected: BoolValue(true),
+		},
+		{
+			name:     "greatest",
+			funcName: "greatest",
+			args:     []Value{FloatValue(2.5), IntValue(3), FloatValue(1.5)},
+			expected: FloatValue(3),
+		},
+	} {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			args, err := coerceArgs(test.funcName, test.args)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var bindFunc BindFunction
+			for _, info := range normalFuncs {
+				if info.Name == test.funcName {
+					bindFunc = info.BindFunc
+					break
+				}
+			}
+			if bindFunc == nil {
+				t.Fatalf("failed to find function %s", test.funcName)
+			}
+			ret, err := bindFunc(args...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cond, err := ret.EQ(test.expected)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !cond {
+				t.Fatalf("expected %v but got %v", test.expected, ret)
+			}
+		})
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	maxDistance := int64(2)
+	for _, test := range []struct {
+		name        string
+		args        []Value
+		expected    Value
+		expectedErr string
+	}{
+		{name: "string", args: []Value{StringValue("kitten"), StringValue("sitting")}, expected: IntValue(3)},
+		{name: "multibyte characters", args: []Value{StringValue("αβγ"), StringValue("αγ")}, expected: IntValue(1)},
+		{name: "bytes", args: []Value{BytesValue("abc"), BytesValue("acb")}, expected: IntValue(2)},
+		{name: "max distance", args: []Value{StringValue("kitten"), StringValue("sitting"), IntValue(maxDistance)}, expected: IntValue(2)},
+		{name: "null", args: []Value{StringValue("a"), nil}},
+		{
+			name:        "different types",
+			args:        []Value{StringValue("a"), BytesValue("a")},
+			expectedErr: "EDIT_DISTANCE: arguments are must be same type",
+		},
+	} {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			ret, err := bindEditDistance(test.args...)
+			if test.expectedErr != "" {
+				if err == nil || err.Error() != test.expectedErr {
+					t.Fatalf("expected error %q but got %v", test.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ret != test.expected {
+				t.Fatalf("expected %v but got %v", test.expected, ret)
+			}
+		})
+	}
+}
+
+func TestSplitSubstr(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		args     []Value
+		expected Value
+	}{
+		{name: "from start", args: []Value{StringValue("www.abc.xyz.com"), StringValue("."), IntValue(1), IntValue(2)}, expected: StringValue("www.abc")},
+		{name: "without count", args: []Value{StringValue("www.abc.xyz.com"), StringValue("."), IntValue(2)}, expected: StringValue("abc.xyz.com")},
+		{name: "negative start", args: []Value{StringValue("www.abc.xyz.com"), StringValue("."), IntValue(-2)}, expected: StringValue("xyz.com")},
+		{name: "zero start", args: []Value{StringValue("www.abc.xyz.com"), StringValue("."), IntValue(0), IntValue(1)}, expected: StringValue("www")},
+		{name: "start exceeds splits", args: []Value{StringValue("www.abc.xyz.com"), StringValue("."), IntValue(5)}, expected: StringValue("")},
+		{name: "null", args: []Value{nil, StringValue("."), IntValue(1)}},
+	} {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			ret, err := bindSplitSubstr(test.args...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ret != test.expected {
+				t.Fatalf("expected %v but got %v", test.expected, ret)
+			}
+		})
+	}
+}
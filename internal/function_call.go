@@ -0,0 +1,116 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// functionCall is the user defined function being expanded into the query.
+// The calls are chained by the context while the body of the function is formatted,
+// so the recursive call is detected before it expands the body infinitely.
+type functionCall struct {
+	parent *functionCall
+	name   string
+	// dependencies is the user defined functions called from the body of the function by name key.
+	dependencies map[string]*FunctionSpec
+}
+
+// newFunctionCall starts the call of the function in the body of the function called by the context.
+// If the function is already being called, returns an error because BigQuery doesn't support recursive functions.
+func newFunctionCall(ctx context.Context, name string) (*functionCall, error) {
+	parent := functionCallFromContext(ctx)
+	var path []string
+	for call := parent; call != nil; call = call.parent {
+		path = append([]string{call.name}, path...)
+		if nameKey(call.name) == nameKey(name) {
+			return nil, fmt.Errorf(
+				"recursive function call is not supported: %s -> %s",
+				strings.Join(path, " -> "), name,
+			)
+		}
+	}
+	return &functionCall{
+		parent:       parent,
+		name:         name,
+		dependencies: map[string]*FunctionSpec{},
+	}, nil
+}
+
+// finish records the function and the functions called from it as the dependencies of the caller.
+func (c *functionCall) finish(spec *FunctionSpec) {
+	if c.parent == nil {
+		return
+	}
+	c.parent.dependencies[nameKey(spec.FuncName())] = spec
+	c.parent.addDependencies(c.dependencies)
+}
+
+func (c *functionCall) addDependencies(dependencies map[string]*FunctionSpec) {
+	for name, spec := range dependencies {
+		c.dependencies[name] = spec
+	}
+}
+
+// templatedFunctionBody is the body of the templated function analyzed with the types of the arguments at the call site.
+type templatedFunctionBody struct {
+	spec         *FunctionSpec
+	body         string
+	dependencies map[string]*FunctionSpec
+}
+
+// isValid reports whether the body can be reused.
+// The specs are replaced when the functions are created again, so the body is stale
+// if the function or the functions called from the body are not the same specs.
+func (b *templatedFunctionBody) isValid(spec *FunctionSpec, funcMap map[string]*FunctionSpec) bool {
+	if b.spec != spec {
+		return false
+	}
+	for name, dep := range b.dependencies {
+		if funcMap[name] != dep {
+			return false
+		}
+	}
+	return true
+}
+
+// templatedFunctionBody returns the body of the templated function analyzed with the arguments defined by the types at the call site.
+// The analyzed body is cached by the signature, so the function called from many call sites is analyzed once for each signature.
+// The name path and the column naming change the formatted body, so they are also the part of the cache key.
+func (a *Analyzer) templatedFunctionBody(ctx context.Context, spec *FunctionSpec, definedArgs []string) (string, error) {
+	call := functionCallFromContext(ctx)
+	key := fmt.Sprintf(
+		"%s(%s):%s:%t",
+		nameKey(spec.FuncName()),
+		strings.Join(definedArgs, ","),
+		strings.Join(a.namePath.path, "."),
+		useColumnID(ctx),
+	)
+	if cached, exists := a.templatedFunctionBodyCache[key]; exists && cached.isValid(spec, funcMapFromContext(ctx)) {
+		call.addDependencies(cached.dependencies)
+		return cached.body, nil
+	}
+	runtimeDefinedFunc := fmt.Sprintf(
+		"CREATE FUNCTION `%s`(%s) as (%s)",
+		strings.Join(spec.NamePath, "."),
+		strings.Join(definedArgs, ","),
+		spec.Code,
+	)
+	runtimeSpec, err := a.analyzeTemplatedFunctionWithRuntimeArgument(ctx, runtimeDefinedFunc)
+	if err != nil {
+		return "", err
+	}
+	dependencies := make(map[string]*FunctionSpec, len(call.dependencies))
+	for name, dep := range call.dependencies {
+		dependencies[name] = dep
+	}
+	if a.templatedFunctionBodyCache == nil {
+		a.templatedFunctionBodyCache = map[string]*templatedFunctionBody{}
+	}
+	a.templatedFunctionBodyCache[key] = &templatedFunctionBody{
+		spec:         spec,
+		body:         runtimeSpec.Body,
+		dependencies: dependencies,
+	}
+	return runtimeSpec.Body, nil
+}
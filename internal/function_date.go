@@ -32,7 +32,11 @@ func DATE(args ...Value) (Value, error) {
 		if err != nil {
 			return nil, err
 		}
-		return DateValue(time.Time{}.AddDate(int(year)-1, int(month)-1, int(day)-1)), nil
+		t := time.Date(int(year), time.Month(month), int(day), 0, 0, 0, 0, time.UTC)
+		if !isValidCivilTime(t) || int64(t.Year()) != year || int64(t.Month()) != month || int64(t.Day()) != day {
+			return nil, fmt.Errorf("Input calculates to invalid date: %04d-%02d-%02d", year, month, day)
+		}
+		return DateValue(t), nil
 	} else if len(args) == 2 {
 		t, err := args[0].ToTime()
 		if err != nil {
@@ -57,35 +61,82 @@ func DATE(args ...Value) (Value, error) {
 }
 
 func DATE_ADD(t time.Time, v int64, part string) (Value, error) {
-	switch part {
-	case "DAY":
-		return DateValue(t.AddDate(0, 0, int(v))), nil
-	case "WEEK":
-		return DateValue(t.AddDate(0, 0, int(v*7))), nil
-	case "MONTH":
-		return DateValue(addMonth(t, int(v))), nil
-	case "YEAR":
-		return DateValue(addYear(t, int(v))), nil
-	case "QUARTER":
-		return DateValue(addMonth(t, 3)), nil
+	ret, ok, err := addDate(t, v, part)
+	if err != nil {
+		return nil, err
 	}
-	return nil, fmt.Errorf("unexpected part value %s", part)
+	if !ok {
+		return nil, fmt.Errorf("Adding %d %s to date %s causes overflow", v, part, t.Format("2006-01-02"))
+	}
+	return DateValue(ret), nil
 }
 
 func DATE_SUB(t time.Time, v int64, part string) (Value, error) {
+	ret, ok, err := addDate(t, -v, part)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("Subtracting %d %s from date %s causes overflow", v, part, t.Format("2006-01-02"))
+	}
+	return DateValue(ret), nil
+}
+
+// addDate adds v parts to t. false is returned if the result is out of the range of DATE.
+func addDate(t time.Time, v int64, part string) (time.Time, bool, error) {
+	if v > maxCivilDays || v < -maxCivilDays {
+		// time.AddDate may overflow with the large value, but the result is always out of range.
+		switch part {
+		case "DAY", "WEEK", "MONTH", "QUARTER", "YEAR":
+			return time.Time{}, false, nil
+		}
+		return time.Time{}, false, fmt.Errorf("unexpected part value %s", part)
+	}
+	var ret time.Time
 	switch part {
 	case "DAY":
-		return DateValue(t.AddDate(0, 0, int(-v))), nil
+		ret = t.AddDate(0, 0, int(v))
 	case "WEEK":
-		return DateValue(t.AddDate(0, 0, int(-v*7))), nil
+		ret = t.AddDate(0, 0, int(v*7))
 	case "MONTH":
-		return DateValue(addMonth(t, int(-v))), nil
+		ret = addMonth(t, int(v))
+	case "QUARTER":
+		ret = addMonth(t, int(v*3))
 	case "YEAR":
-		return DateValue(addYear(t, int(-v))), nil
+		ret = addYear(t, int(v))
+	default:
+		return time.Time{}, false, fmt.Errorf("unexpected part value %s", part)
 	}
-	return nil, fmt.Errorf("unexpected part value %s", part)
+	return ret, isValidCivilTime(ret), nil
 }
 
+// addTimeUnit adds v units to t.
+// The value is added in seconds because time.Duration only covers about 292 years.
+// false is returned if the result obviously overflows, so the caller must validate the range of the result.
+func addTimeUnit(t time.Time, v int64, unit time.Duration) (time.Time, bool) {
+	var sec, nsec int64
+	if unit < time.Second {
+		perSec := int64(time.Second / unit)
+		sec, nsec = v/perSec, (v%perSec)*int64(unit)
+	} else {
+		perUnit := int64(unit / time.Second)
+		if v > maxCivilSeconds/perUnit || v < -maxCivilSeconds/perUnit {
+			return time.Time{}, false
+		}
+		sec = v * perUnit
+	}
+	if sec > maxCivilSeconds || sec < -maxCivilSeconds {
+		return time.Time{}, false
+	}
+	return time.Unix(t.Unix()+sec, int64(t.Nanosecond())+nsec).In(t.Location()), true
+}
+
+const (
+	// minUnixDate and maxUnixDate are the number of days since 1970-01-01 of 0001-01-01 and 9999-12-31.
+	minUnixDate = -719162
+	maxUnixDate = 2932896
+)
+
 var WeekPartToOffset = map[string]int{
 	"WEEK":           0,
 	"WEEK_MONDAY":    1,
@@ -234,8 +285,10 @@ func DATE_TRUNC(t time.Time, part string) (Value, error) {
 }
 
 func DATE_FROM_UNIX_DATE(unixdate int64) (Value, error) {
-	t := time.Unix(int64(time.Duration(unixdate)*24*time.Hour/time.Second), 0)
-	return DateValue(t), nil
+	if unixdate < minUnixDate || unixdate > maxUnixDate {
+		return nil, fmt.Errorf("DATE_FROM_UNIX_DATE range is %d to %d but saw %d", minUnixDate, maxUnixDate, unixdate)
+	}
+	return DateValue(dateFromUnixDays(unixdate)), nil
 }
 
 func FORMAT_DATE(format string, t time.Time) (Value, error) {
@@ -106,75 +106,82 @@ func DATETIME(args ...Value) (Value, error) {
 }
 
 func DATETIME_ADD(t time.Time, v int64, part string) (Value, error) {
-	switch part {
-	case "MICROSECOND":
-		return DatetimeValue(t.Add(time.Duration(v) * time.Microsecond)), nil
-	case "MILLISECOND":
-		return DatetimeValue(t.Add(time.Duration(v) * time.Millisecond)), nil
-	case "SECOND":
-		return DatetimeValue(t.Add(time.Duration(v) * time.Second)), nil
-	case "MINUTE":
-		return DatetimeValue(t.Add(time.Duration(v) * time.Minute)), nil
-	case "HOUR":
-		return DatetimeValue(t.Add(time.Duration(v) * time.Hour)), nil
-	default:
-		date, err := DATE_ADD(t, v, part)
-		if err != nil {
-			return nil, fmt.Errorf("DATETIME_ADD: %w", err)
-		}
-		datetime, err := date.ToTime()
-		if err != nil {
-			return nil, fmt.Errorf("DATETIME_ADD: %w", err)
-		}
-		return DatetimeValue(
-			time.Date(
-				datetime.Year(),
-				datetime.Month(),
-				datetime.Day(),
-				t.Hour(),
-				t.Minute(),
-				t.Second(),
-				t.Nanosecond(),
-				t.Location(),
-			),
-		), nil
+	ret, ok, err := addDatetime(t, v, part)
+	if err != nil {
+		return nil, fmt.Errorf("DATETIME_ADD: %w", err)
 	}
+	if !ok {
+		return nil, fmt.Errorf("Adding %d %s to datetime %s causes overflow", v, part, formatDatetimeForError(t))
+	}
+	return DatetimeValue(ret), nil
 }
 
 func DATETIME_SUB(t time.Time, v int64, part string) (Value, error) {
-	switch part {
-	case "MICROSECOND":
-		return DatetimeValue(t.Add(-time.Duration(v) * time.Microsecond)), nil
-	case "MILLISECOND":
-		return DatetimeValue(t.Add(-time.Duration(v) * time.Millisecond)), nil
-	case "SECOND":
-		return DatetimeValue(t.Add(-time.Duration(v) * time.Second)), nil
-	case "MINUTE":
-		return DatetimeValue(t.Add(-time.Duration(v) * time.Minute)), nil
-	case "HOUR":
-		return DatetimeValue(t.Add(-time.Duration(v) * time.Hour)), nil
-	default:
-		date, err := DATE_SUB(t, v, part)
-		if err != nil {
-			return nil, fmt.Errorf("DATETIME_SUB: %w", err)
+	ret, ok, err := addDatetime(t, -v, part)
+	if err != nil {
+		return nil, fmt.Errorf("DATETIME_SUB: %w", err)
+	}
+	if !ok {
+		return nil, fmt.Errorf("Subtracting %d %s from datetime %s causes overflow", v, part, formatDatetimeForError(t))
+	}
+	return DatetimeValue(ret), nil
+}
+
+var datetimeUnitMap = map[string]time.Duration{
+	"MICROSECOND": time.Microsecond,
+	"MILLISECOND": time.Millisecond,
+	"SECOND":      time.Second,
+	"MINUTE":      time.Minute,
+	"HOUR":        time.Hour,
+}
+
+// addDatetime adds v parts to t. false is returned if the result is out of the range of DATETIME.
+func addDatetime(t time.Time, v int64, part string) (time.Time, bool, error) {
+	if unit, exists := datetimeUnitMap[part]; exists {
+		ret, ok := addTimeUnit(t, v, unit)
+		return ret, ok && isValidCivilTime(ret), nil
+	}
+	date, ok, err := addDate(t, v, part)
+	if err != nil || !ok {
+		return time.Time{}, ok, err
+	}
+	return time.Date(
+		date.Year(),
+		date.Month(),
+		date.Day(),
+		t.Hour(),
+		t.Minute(),
+		t.Second(),
+		t.Nanosecond(),
+		t.Location(),
+	), true, nil
+}
+
+// addInterval adds the interval multiplied by sign to t in the same way as DATETIME_ADD.
+// The years and months are added first with the day clamped to the end of the month ( e.g. 2024-01-31 + INTERVAL 1 MONTH is 2024-02-29 ),
+// then the days and the time parts are added.
+// false is returned if the result obviously overflows, so the caller must validate the range of the result.
+func addInterval(t time.Time, iv *IntervalValue, sign int64) (time.Time, bool) {
+	ret := t
+	if months := sign * (int64(iv.Years)*12 + int64(iv.Months)); months != 0 {
+		if months > maxCivilDays || months < -maxCivilDays {
+			return time.Time{}, false
 		}
-		datetime, err := date.ToTime()
-		if err != nil {
-			return nil, fmt.Errorf("DATETIME_SUB: %w", err)
+		date := addMonth(ret, int(months))
+		ret = time.Date(date.Year(), date.Month(), date.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	}
+	if days := sign * int64(iv.Days); days != 0 {
+		if days > maxCivilDays || days < -maxCivilDays {
+			return time.Time{}, false
 		}
-		return DatetimeValue(
-			time.Date(
-				datetime.Year(),
-				datetime.Month(),
-				datetime.Day(),
-				t.Hour(),
-				t.Minute(),
-				t.Second(),
-				t.Nanosecond(),
-				t.Location(),
-			),
-		), nil
+		ret = ret.AddDate(0, 0, int(days))
+	}
+	seconds := sign * (int64(iv.Hours)*60*60 + int64(iv.Minutes)*60 + int64(iv.Seconds))
+	ret, ok := addTimeUnit(ret, seconds, time.Second)
+	if !ok {
+		return time.Time{}, false
 	}
+	return ret.Add(time.Duration(sign * int64(iv.SubSecondNanos))), true
 }
 
 func DATETIME_DIFF(a, b time.Time, part string) (Value, error) {
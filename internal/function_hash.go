@@ -6,6 +6,8 @@ import (
 	"crypto/sha1"
 	"crypto/sha256"
 	"crypto/sha512"
+	"fmt"
+	"strings"
 
 	"github.com/dgryski/go-farm"
 )
@@ -33,3 +35,29 @@ func SHA512(v []byte) (Value, error) {
 	sum := sha512.Sum512(v)
 	return BytesValue(sum[:]), nil
 }
+
+// TABLE_SAMPLE_BUCKET returns the bucket in the range [0, 100) of the row used by TABLESAMPLE.
+// The bucket is MOD(ABS(FARM_FINGERPRINT(key)), 100), and the key is the STRING values of the key columns joined by ",".
+// If the seed of REPEATABLE is specified, it's prepended to the key. NULL values are treated as empty strings.
+func TABLE_SAMPLE_BUCKET(seed *int64, values []Value) (Value, error) {
+	key := make([]string, 0, len(values)+1)
+	if seed != nil {
+		key = append(key, fmt.Sprint(*seed))
+	}
+	for _, v := range values {
+		if v == nil {
+			key = append(key, "")
+			continue
+		}
+		s, err := v.ToString()
+		if err != nil {
+			return nil, err
+		}
+		key = append(key, s)
+	}
+	bucket := int64(farm.Fingerprint64([]byte(strings.Join(key, ",")))) % 100
+	if bucket < 0 {
+		bucket = -bucket
+	}
+	return IntValue(bucket), nil
+}
@@ -0,0 +1,167 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/goccy/go-zetasql/types"
+)
+
+// FunctionKind represents the kind of function listed by catalog.
+type FunctionKind string
+
+const (
+	FunctionKindBuiltin FunctionKind = "BUILTIN"
+	FunctionKindUDF     FunctionKind = "UDF"
+	FunctionKindTempUDF FunctionKind = "TEMP_UDF"
+)
+
+// FunctionInfo represents a function that can be called from the query.
+type FunctionInfo struct {
+	Name       string               `json:"name"`
+	Kind       FunctionKind         `json:"kind"`
+	Signatures []*FunctionSignature `json:"signatures"`
+	// Description and Determinism are the metadata of user defined functions
+	// specified by OPTIONS(description = ...) and DETERMINISTIC / NOT DETERMINISTIC.
+	Description string      `json:"description,omitempty"`
+	Determinism Determinism `json:"determinism,omitempty"`
+}
+
+// FunctionSignature represents a signature of the function.
+// Templated types are represented by the name used in ZetaSQL ( e.g. `<T1>` or `ANY TYPE` ).
+type FunctionSignature struct {
+	Arguments  []*FunctionArgument `json:"arguments"`
+	ReturnType string              `json:"returnType"`
+}
+
+// FunctionArgument represents an argument of the function signature.
+type FunctionArgument struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Optional bool   `json:"optional"`
+	Repeated bool   `json:"repeated"`
+}
+
+// String returns the signature text like `CONCAT(STRING, [STRING, ...]) -> STRING`.
+func (s *FunctionSignature) String() string {
+	args := make([]string, 0, len(s.Arguments))
+	for _, arg := range s.Arguments {
+		text := arg.Type
+		if arg.Name != "" {
+			text = fmt.Sprintf("%s %s", arg.Name, arg.Type)
+		}
+		switch {
+		case arg.Repeated:
+			text = fmt.Sprintf("[%s, ...]", text)
+		case arg.Optional:
+			text = fmt.Sprintf("[%s]", text)
+		}
+		args = append(args, text)
+	}
+	return fmt.Sprintf("(%s) -> %s", strings.Join(args, ", "), s.ReturnType)
+}
+
+// Functions returns builtin functions and user defined functions sorted by name.
+func (a *Analyzer) Functions(ctx context.Context, conn *Conn) ([]*FunctionInfo, error) {
+	if err := a.catalog.Sync(ctx, conn); err != nil {
+		return nil, fmt.Errorf("failed to sync catalog: %w", err)
+	}
+	return a.session.functionInfos()
+}
+
+func (c *Catalog) functionInfos() ([]*FunctionInfo, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	builtinFuncs, err := c.catalog.Functions()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get builtin functions: %w", err)
+	}
+	infos := make([]*FunctionInfo, 0, len(builtinFuncs)+len(c.functions))
+	for _, fn := range builtinFuncs {
+		if !fn.IsZetaSQLBuiltin() {
+			continue
+		}
+		// Functions that start with `$` are operators ( e.g. `$add` ).
+		if strings.HasPrefix(fn.Name(), "$") {
+			continue
+		}
+		infos = append(infos, newBuiltinFunctionInfo(fn))
+	}
+	for _, spec := range c.functions {
+		infos = append(infos, newUserDefinedFunctionInfo(spec))
+	}
+	sort.SliceStable(infos, func(i, j int) bool {
+		return infos[i].Name < infos[j].Name
+	})
+	return infos, nil
+}
+
+func newBuiltinFunctionInfo(fn *types.Function) *FunctionInfo {
+	sigs := make([]*FunctionSignature, 0, fn.NumSignatures())
+	for _, sig := range fn.Signatures() {
+		if sig.IsDeprecated() {
+			continue
+		}
+		args := make([]*FunctionArgument, 0, len(sig.Arguments()))
+		for _, arg := range sig.Arguments() {
+			var name string
+			if arg.HasArgumentName() {
+				name = arg.ArgumentName()
+			}
+			args = append(args, &FunctionArgument{
+				Name:     name,
+				Type:     arg.UserFacingName(types.ProductExternal),
+				Optional: arg.Optional(),
+				Repeated: arg.Repeated(),
+			})
+		}
+		sigs = append(sigs, &FunctionSignature{
+			Arguments:  args,
+			ReturnType: sig.ResultType().UserFacingName(types.ProductExternal),
+		})
+	}
+	return &FunctionInfo{
+		Name:       fn.SQLName(),
+		Kind:       FunctionKindBuiltin,
+		Signatures: sigs,
+	}
+}
+
+func newUserDefinedFunctionInfo(spec *FunctionSpec) *FunctionInfo {
+	args := make([]*FunctionArgument, 0, len(spec.Args))
+	for _, arg := range spec.Args {
+		args = append(args, &FunctionArgument{
+			Name: arg.Name,
+			Type: userFacingTypeName(arg.Type),
+		})
+	}
+	kind := FunctionKindUDF
+	if spec.IsTemp {
+		kind = FunctionKindTempUDF
+	}
+	return &FunctionInfo{
+		Name: strings.Join(spec.NamePath, "."),
+		Kind: kind,
+		Signatures: []*FunctionSignature{
+			{
+				Arguments:  args,
+				ReturnType: userFacingTypeName(spec.Return),
+			},
+		},
+		Description: spec.Description,
+		Determinism: spec.Determinism,
+	}
+}
+
+func userFacingTypeName(t *Type) string {
+	if t == nil {
+		return ""
+	}
+	if t.SignatureKind != types.ArgTypeFixed {
+		return "ANY TYPE"
+	}
+	return t.FormatType()
+}
@@ -2,6 +2,7 @@ package internal
 
 import (
 	"fmt"
+	"time"
 
 	"cloud.google.com/go/bigquery"
 )
@@ -9,23 +10,72 @@ import (
 func INTERVAL(value int64, part string) (Value, error) {
 	switch part {
 	case "YEAR":
-		return &IntervalValue{IntervalValue: &bigquery.IntervalValue{Years: int32(value)}}, nil
+		return newMonthsInterval(value, 12)
+	case "QUARTER":
+		return newMonthsInterval(value, 3)
 	case "MONTH":
-		return &IntervalValue{IntervalValue: &bigquery.IntervalValue{Months: int32(value)}}, nil
+		return newMonthsInterval(value, 1)
+	case "WEEK":
+		return newDaysInterval(value, 7)
 	case "DAY":
-		return &IntervalValue{IntervalValue: &bigquery.IntervalValue{Days: int32(value)}}, nil
+		return newDaysInterval(value, 1)
 	case "HOUR":
-		return &IntervalValue{IntervalValue: &bigquery.IntervalValue{Hours: int32(value)}}, nil
+		return newSecondsInterval(value, 3600, 0)
 	case "MINUTE":
-		return &IntervalValue{IntervalValue: &bigquery.IntervalValue{Minutes: int32(value)}}, nil
+		return newSecondsInterval(value, 60, 0)
 	case "SECOND":
-		return &IntervalValue{IntervalValue: &bigquery.IntervalValue{Seconds: int32(value)}}, nil
+		return newSecondsInterval(value, 1, 0)
+	case "MILLISECOND":
+		return newSecondsInterval(value/1000, 1, (value%1000)*int64(time.Millisecond))
+	case "MICROSECOND":
+		return newSecondsInterval(value/1000000, 1, (value%1000000)*int64(time.Microsecond))
 	case "NANOSECOND":
-		return &IntervalValue{IntervalValue: &bigquery.IntervalValue{SubSecondNanos: int32(value)}}, nil
+		return newSecondsInterval(value/int64(time.Second), 1, value%int64(time.Second))
 	}
 	return nil, fmt.Errorf("unexpected interval part: %s", part)
 }
 
+// checkIntervalRange returns an error if value * unit exceeds max without overflowing int64.
+func checkIntervalRange(value, unit, max int64) error {
+	if value > max/unit || value < -max/unit {
+		return fmt.Errorf("interval value is out of range")
+	}
+	return nil
+}
+
+func newMonthsInterval(value, unit int64) (Value, error) {
+	if err := checkIntervalRange(value, unit, maxIntervalMonths); err != nil {
+		return nil, err
+	}
+	months := value * unit
+	return &IntervalValue{IntervalValue: &bigquery.IntervalValue{
+		Years:  int32(months / 12),
+		Months: int32(months % 12),
+	}}, nil
+}
+
+func newDaysInterval(value, unit int64) (Value, error) {
+	if err := checkIntervalRange(value, unit, maxIntervalDays); err != nil {
+		return nil, err
+	}
+	return &IntervalValue{IntervalValue: &bigquery.IntervalValue{Days: int32(value * unit)}}, nil
+}
+
+// newSecondsInterval creates the interval of the time part.
+// The seconds are split into hours, minutes and seconds, because the range of the interval exceeds int32 in seconds.
+func newSecondsInterval(value, unit, nanos int64) (Value, error) {
+	if err := checkIntervalRange(value, unit, maxIntervalSeconds); err != nil {
+		return nil, err
+	}
+	seconds := value * unit
+	return &IntervalValue{IntervalValue: &bigquery.IntervalValue{
+		Hours:          int32(seconds / 3600),
+		Minutes:        int32(seconds % 3600 / 60),
+		Seconds:        int32(seconds % 60),
+		SubSecondNanos: int32(nanos),
+	}}, nil
+}
+
 func MAKE_INTERVAL(year, month, day, hour, minute, second int64) (Value, error) {
 	return &IntervalValue{
 		IntervalValue: &bigquery.IntervalValue{
@@ -3,7 +3,11 @@ package internal
 import (
 	"bytes"
 	"fmt"
+	"math"
+	"math/big"
 	"reflect"
+	"strconv"
+	"strings"
 
 	"github.com/goccy/go-json"
 )
@@ -15,7 +19,8 @@ func JSON_FIELD(v, fieldName string) (Value, error) {
 	}
 	extracted, err := p.Extract([]byte(v))
 	if err != nil {
-		return nil, err
+		// the value is always valid JSON, so the error means the value isn't an object ( e.g. JSON 'null' ).
+		return nil, nil
 	}
 	if len(extracted) == 0 {
 		return nil, nil
@@ -46,10 +51,13 @@ func JSON_SUBSCRIPT(v string, field Value) (Value, error) {
 			return nil, err
 		}
 		path = p
+	default:
+		return nil, fmt.Errorf("JSON subscript: unexpected field type %T", field)
 	}
 	extracted, err := path.Extract([]byte(v))
 	if err != nil {
-		return nil, err
+		// the value is always valid JSON, so the error means the value isn't an object or array ( e.g. JSON 'null' ).
+		return nil, nil
 	}
 	if len(extracted) == 0 {
 		return nil, nil
@@ -58,7 +66,7 @@ func JSON_SUBSCRIPT(v string, field Value) (Value, error) {
 }
 
 func JSON_EXTRACT(v, path string) (Value, error) {
-	p, err := json.CreatePath(path)
+	p, err := createJSONPath("JSON_EXTRACT", path)
 	if err != nil {
 		return nil, err
 	}
@@ -84,7 +92,7 @@ func JSON_EXTRACT(v, path string) (Value, error) {
 }
 
 func JSON_EXTRACT_SCALAR(v, path string) (Value, error) {
-	p, err := json.CreatePath(path)
+	p, err := createJSONPath("JSON_EXTRACT_SCALAR", path)
 	if err != nil {
 		return nil, err
 	}
@@ -98,16 +106,11 @@ func JSON_EXTRACT_SCALAR(v, path string) (Value, error) {
 	if len(values) == 0 {
 		return nil, nil
 	}
-	value := values[0]
-	switch reflect.ValueOf(value).Type().Kind() {
-	case reflect.Map, reflect.Slice:
-		return nil, nil
-	}
-	return StringValue(fmt.Sprint(value)), nil
+	return jsonScalarValue(values[0]), nil
 }
 
 func JSON_EXTRACT_ARRAY(v, path string) (Value, error) {
-	p, err := json.CreatePath(path)
+	p, err := createJSONPath("JSON_EXTRACT_ARRAY", path)
 	if err != nil {
 		return nil, err
 	}
@@ -143,7 +146,7 @@ func JSON_EXTRACT_ARRAY(v, path string) (Value, error) {
 }
 
 func JSON_EXTRACT_STRING_ARRAY(v, path string) (Value, error) {
-	p, err := json.CreatePath(path)
+	p, err := createJSONPath("JSON_EXTRACT_STRING_ARRAY", path)
 	if err != nil {
 		return nil, err
 	}
@@ -163,26 +166,23 @@ func JSON_EXTRACT_STRING_ARRAY(v, path string) (Value, error) {
 	if !rv.IsValid() || rv.Type().Kind() != reflect.Slice {
 		return nil, nil
 	}
-	ret := &ArrayValue{}
-	for i := 0; i < rv.Len(); i++ {
-		elem := rv.Index(i).Interface()
-		elemV := reflect.ValueOf(elem)
-		elemKind := elemV.Type().Kind()
-		if elemKind == reflect.Map || elemKind == reflect.Slice {
-			return nil, nil
-		}
-		jsonValue := fmt.Sprint(elem)
-		if jsonValue == "null" {
-			ret.values = append(ret.values, nil)
-		} else {
-			ret.values = append(ret.values, StringValue(jsonValue))
-		}
-	}
-	return ret, nil
+	return jsonScalarArrayValue(rv), nil
 }
 
 func JSON_QUERY(v, path string) (Value, error) {
-	p, err := json.CreatePath(path)
+	mode, modePath, err := splitJSONPathMode(path)
+	if err != nil {
+		return nil, err
+	}
+	if mode != jsonPathModeStrict {
+		// lax mode always returns JSON array even if no value matches.
+		extracted, err := extractJSONByLaxPath(v, modePath, mode)
+		if err != nil {
+			return nil, err
+		}
+		return JsonValue(extracted), nil
+	}
+	p, err := createJSONPath("JSON_QUERY", path)
 	if err != nil {
 		return nil, err
 	}
@@ -208,7 +208,7 @@ func JSON_QUERY(v, path string) (Value, error) {
 }
 
 func JSON_VALUE(v, path string) (Value, error) {
-	p, err := json.CreatePath(path)
+	p, err := createJSONPath("JSON_VALUE", path)
 	if err != nil {
 		return nil, err
 	}
@@ -222,19 +222,11 @@ func JSON_VALUE(v, path string) (Value, error) {
 	if len(values) == 0 {
 		return nil, nil
 	}
-	value := values[0]
-	if !reflect.ValueOf(value).IsValid() {
-		return nil, nil
-	}
-	switch reflect.ValueOf(value).Type().Kind() {
-	case reflect.Map, reflect.Slice:
-		return nil, nil
-	}
-	return StringValue(fmt.Sprint(value)), nil
+	return jsonScalarValue(values[0]), nil
 }
 
 func JSON_QUERY_ARRAY(v, path string) (Value, error) {
-	p, err := json.CreatePath(path)
+	p, err := createJSONPath("JSON_QUERY_ARRAY", path)
 	if err != nil {
 		return nil, err
 	}
@@ -270,7 +262,7 @@ func JSON_QUERY_ARRAY(v, path string) (Value, error) {
 }
 
 func JSON_VALUE_ARRAY(v, path string) (Value, error) {
-	p, err := json.CreatePath(path)
+	p, err := createJSONPath("JSON_VALUE_ARRAY", path)
 	if err != nil {
 		return nil, err
 	}
@@ -290,22 +282,34 @@ func JSON_VALUE_ARRAY(v, path string) (Value, error) {
 	if !rv.IsValid() || rv.Type().Kind() != reflect.Slice {
 		return nil, nil
 	}
+	return jsonScalarArrayValue(rv), nil
+}
+
+// jsonScalarValue converts the unmarshaled JSON value to STRING value.
+// JSON null, object and array are converted to NULL.
+func jsonScalarValue(v interface{}) Value {
+	switch v.(type) {
+	case nil, map[string]interface{}, []interface{}:
+		return nil
+	}
+	return StringValue(fmt.Sprint(v))
+}
+
+// jsonScalarArrayValue converts the unmarshaled JSON array to ARRAY<STRING> value.
+// JSON null elements are converted to NULL, and if the array contains an object or array, returns NULL.
+func jsonScalarArrayValue(rv reflect.Value) Value {
 	ret := &ArrayValue{}
 	for i := 0; i < rv.Len(); i++ {
-		elem := rv.Index(i).Interface()
-		elemV := reflect.ValueOf(elem)
-		elemKind := elemV.Type().Kind()
-		if elemKind == reflect.Map || elemKind == reflect.Slice {
-			return nil, nil
-		}
-		jsonValue := fmt.Sprint(elem)
-		if jsonValue == "null" {
+		switch elem := rv.Index(i).Interface().(type) {
+		case nil:
 			ret.values = append(ret.values, nil)
-		} else {
-			ret.values = append(ret.values, StringValue(jsonValue))
+		case map[string]interface{}, []interface{}:
+			return nil
+		default:
+			ret.values = append(ret.values, StringValue(fmt.Sprint(elem)))
 		}
 	}
-	return ret, nil
+	return ret
 }
 
 func PARSE_JSON(expr, mode string) (Value, error) {
@@ -317,6 +321,9 @@ func PARSE_JSON(expr, mode string) (Value, error) {
 }
 
 func TO_JSON(v Value, stringifyWideNumbers bool) (Value, error) {
+	if v == nil {
+		return JsonValue("null"), nil
+	}
 	s, err := v.ToJSON()
 	if err != nil {
 		return nil, err
@@ -325,6 +332,9 @@ func TO_JSON(v Value, stringifyWideNumbers bool) (Value, error) {
 }
 
 func TO_JSON_STRING(v Value, prettyPrint bool) (Value, error) {
+	if v == nil {
+		return StringValue("null"), nil
+	}
 	s, err := v.ToJSON()
 	if err != nil {
 		return nil, err
@@ -335,3 +345,63 @@ func TO_JSON_STRING(v Value, prettyPrint bool) (Value, error) {
 func JSON_TYPE(v JsonValue) (Value, error) {
 	return StringValue(v.Type()), nil
 }
+
+// JSON_BOOL converts JSON boolean to BOOL value. The other JSON values including JSON null produce an error.
+func JSON_BOOL(v JsonValue) (Value, error) {
+	b, ok := v.Interface().(bool)
+	if !ok {
+		return nil, fmt.Errorf("BOOL: the JSON value is not a boolean: %s", v)
+	}
+	return BoolValue(b), nil
+}
+
+// JSON_INT64 converts JSON number to INT64 value.
+// The number must be an integer in the range of INT64. The other JSON values including JSON null produce an error.
+func JSON_INT64(v JsonValue) (Value, error) {
+	if v.Type() != "number" {
+		return nil, fmt.Errorf("INT64: the JSON value is not a number: %s", v)
+	}
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(string(v)))
+	if !ok || !r.IsInt() || !r.Num().IsInt64() {
+		return nil, fmt.Errorf("INT64: the JSON number cannot be converted to INT64 without loss of precision: %s", v)
+	}
+	return IntValue(r.Num().Int64()), nil
+}
+
+// JSON_FLOAT64 converts JSON number to FLOAT64 value.
+// If wideNumberMode is `exact`, the number that cannot be converted to FLOAT64 without loss of precision produces an error.
+// The other JSON values including JSON null produce an error.
+func JSON_FLOAT64(v JsonValue, wideNumberMode string) (Value, error) {
+	if v.Type() != "number" {
+		return nil, fmt.Errorf("FLOAT64: the JSON value is not a number: %s", v)
+	}
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(string(v)))
+	if !ok {
+		return nil, fmt.Errorf("FLOAT64: failed to parse JSON number: %s", v)
+	}
+	f, _ := r.Float64()
+	if math.IsInf(f, 0) {
+		return nil, fmt.Errorf("FLOAT64: the JSON number is out of range of FLOAT64: %s", v)
+	}
+	switch wideNumberMode {
+	case "exact":
+		// the number is exact if the shortest representation of the FLOAT64 value is the same number ( e.g. 9.8 ).
+		shortest, _ := new(big.Rat).SetString(strconv.FormatFloat(f, 'g', -1, 64))
+		if shortest == nil || shortest.Cmp(r) != 0 {
+			return nil, fmt.Errorf("FLOAT64: the JSON number cannot be converted to FLOAT64 without loss of precision: %s", v)
+		}
+	case "round":
+	default:
+		return nil, fmt.Errorf("FLOAT64: unexpected wide_number_mode: %s", wideNumberMode)
+	}
+	return FloatValue(f), nil
+}
+
+// JSON_STRING converts JSON string to STRING value. The other JSON values including JSON null produce an error.
+func JSON_STRING(v JsonValue) (Value, error) {
+	str, ok := v.Interface().(string)
+	if !ok {
+		return nil, fmt.Errorf("STRING: the JSON value is not a string: %s", v)
+	}
+	return StringValue(str), nil
+}
@@ -198,6 +198,9 @@ func DIV(x, y Value) (Value, error) {
 	if yv == 0 {
 		return nil, fmt.Errorf("DIV: zero divided")
 	}
+	if xv == math.MinInt64 && yv == -1 {
+		return nil, fmt.Errorf("int64 overflow: %d / %d", xv, yv)
+	}
 	return IntValue(xv / yv), nil
 }
 
@@ -217,6 +220,9 @@ func SAFE_DIVIDE(x, y Value) (Value, error) {
 }
 
 func SAFE_MULTIPLY(x, y Value) (Value, error) {
+	if isInt64Values(x, y) {
+		return safeInt64Result(x.Mul(y))
+	}
 	xv, err := x.ToFloat64()
 	if err != nil {
 		return nil, err
@@ -229,6 +235,9 @@ func SAFE_MULTIPLY(x, y Value) (Value, error) {
 }
 
 func SAFE_NEGATE(x Value) (Value, error) {
+	if isInt64Values(x) {
+		return safeInt64Result(UNARY_MINUS(x))
+	}
 	xv, err := x.ToFloat64()
 	if err != nil {
 		return nil, err
@@ -237,6 +246,9 @@ func SAFE_NEGATE(x Value) (Value, error) {
 }
 
 func SAFE_ADD(x, y Value) (Value, error) {
+	if isInt64Values(x, y) {
+		return safeInt64Result(x.Add(y))
+	}
 	xv, err := x.ToFloat64()
 	if err != nil {
 		return nil, err
@@ -249,6 +261,9 @@ func SAFE_ADD(x, y Value) (Value, error) {
 }
 
 func SAFE_SUBTRACT(x, y Value) (Value, error) {
+	if isInt64Values(x, y) {
+		return safeInt64Result(x.Sub(y))
+	}
 	xv, err := x.ToFloat64()
 	if err != nil {
 		return nil, err
@@ -260,6 +275,23 @@ func SAFE_SUBTRACT(x, y Value) (Value, error) {
 	return FloatValue(xv - yv), nil
 }
 
+func isInt64Values(values ...Value) bool {
+	for _, v := range values {
+		if _, ok := v.(IntValue); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// safeInt64Result returns NULL instead of the error of the INT64 arithmetic ( e.g. overflow ).
+func safeInt64Result(v Value, err error) (Value, error) {
+	if err != nil {
+		return nil, nil
+	}
+	return v, nil
+}
+
 func MOD(x, y Value) (Value, error) {
 	xv, err := x.ToFloat64()
 	if err != nil {
@@ -3,20 +3,68 @@ package internal
 import (
 	"fmt"
 	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	parseNumericPattern = regexp.MustCompile(`^([0-9]+\.?[0-9]*|\.[0-9]+)(?:[eE]([+-]?[0-9]+))?$`)
 )
 
 func PARSE_NUMERIC(numeric string) (Value, error) {
-	r := new(big.Rat)
-	if _, ok := r.SetString(numeric); !ok {
-		return nil, fmt.Errorf("unexpected numeric literal: %s", numeric)
+	r, err := parseNumericString(numeric, 9)
+	if err != nil || new(big.Rat).Abs(r).Cmp(maxNumericValue) >= 0 {
+		return nil, fmt.Errorf("Invalid NUMERIC value: %s", numeric)
 	}
 	return &NumericValue{Rat: r}, nil
 }
 
 func PARSE_BIGNUMERIC(numeric string) (Value, error) {
-	r := new(big.Rat)
-	if _, ok := r.SetString(numeric); !ok {
-		return nil, fmt.Errorf("unexpected numeric literal: %s", numeric)
+	r, err := parseNumericString(numeric, 38)
+	if err != nil || r.Cmp(minBigNumericValue) < 0 || r.Cmp(maxBigNumericValue) > 0 {
+		return nil, fmt.Errorf("Invalid BIGNUMERIC value: %s", numeric)
 	}
 	return &NumericValue{Rat: r, isBigNumeric: true}, nil
 }
+
+// parseNumericString parses the string by the rules of PARSE_NUMERIC and PARSE_BIGNUMERIC.
+// The sign can be placed before or after the number, and whitespaces are allowed around the sign and the number.
+// The fractional part that exceeds scale is rounded half away from zero.
+func parseNumericString(numeric string, scale int64) (*big.Rat, error) {
+	v := strings.TrimSpace(numeric)
+	var negative bool
+	switch {
+	case strings.HasPrefix(v, "+") || strings.HasPrefix(v, "-"):
+		negative = v[0] == '-'
+		v = strings.TrimSpace(v[1:])
+	case strings.HasSuffix(v, "+") || strings.HasSuffix(v, "-"):
+		negative = v[len(v)-1] == '-'
+		v = strings.TrimSpace(v[:len(v)-1])
+	}
+	matched := parseNumericPattern.FindStringSubmatch(v)
+	if matched == nil {
+		return nil, fmt.Errorf("unexpected numeric literal: %s", numeric)
+	}
+	if strings.Trim(matched[1], "0.") == "" {
+		return new(big.Rat), nil
+	}
+	if matched[2] != "" {
+		// reject the exponent that obviously overflows before allocating the huge number.
+		exp, err := strconv.ParseInt(matched[2], 10, 64)
+		if err != nil || exp > 1000 {
+			return nil, fmt.Errorf("numeric literal is out of range: %s", numeric)
+		}
+		if exp < -1000 {
+			return new(big.Rat), nil
+		}
+	}
+	r, ok := new(big.Rat).SetString(v)
+	if !ok {
+		return nil, fmt.Errorf("unexpected numeric literal: %s", numeric)
+	}
+	if negative {
+		r.Neg(r)
+	}
+	return roundRat(r, scale), nil
+}
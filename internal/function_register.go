@@ -2,9 +2,10 @@ package internal
 
 import (
 	"fmt"
+	"strings"
 	"sync"
+	"time"
 
-	"github.com/goccy/go-json"
 	"github.com/mattn/go-sqlite3"
 )
 
@@ -13,6 +14,7 @@ var normalFuncs = []*FuncInfo{
 	{Name: "subtract", BindFunc: bindSub},
 	{Name: "multiply", BindFunc: bindMul},
 	{Name: "divide", BindFunc: bindOpDiv},
+	{Name: "unary_minus", BindFunc: bindUnaryMinus},
 	{Name: "equal", BindFunc: bindEqual},
 	{Name: "not_equal", BindFunc: bindNotEqual},
 	{Name: "greater", BindFunc: bindGreater},
@@ -129,8 +131,10 @@ var normalFuncs = []*FuncInfo{
 	{Name: "sha1", BindFunc: bindSha1},
 	{Name: "sha256", BindFunc: bindSha256},
 	{Name: "sha512", BindFunc: bindSha512},
+	{Name: "table_sample_bucket", BindFunc: bindTableSampleBucket},
 
 	// string functions
+	{Name: "apply_type_parameters", BindFunc: bindApplyTypeParameters},
 	{Name: "ascii", BindFunc: bindAscii},
 	{Name: "byte_length", BindFunc: bindByteLength},
 	{Name: "char_length", BindFunc: bindCharLength},
@@ -138,8 +142,10 @@ var normalFuncs = []*FuncInfo{
 	{Name: "code_points_to_bytes", BindFunc: bindCodePointsToBytes},
 	{Name: "code_points_to_string", BindFunc: bindCodePointsToString},
 	{Name: "collate", BindFunc: bindCollate},
+	{Name: "collation_key", BindFunc: bindCollationKey},
 	{Name: "concat", BindFunc: bindConcat},
 	{Name: "contains_substr", BindFunc: bindContainsSubstr},
+	{Name: "edit_distance", BindFunc: bindEditDistance},
 	{Name: "ends_with", BindFunc: bindEndsWith},
 	{Name: "format", BindFunc: bindFormat},
 	{Name: "from_base32", BindFunc: bindFromBase32},
@@ -168,6 +174,7 @@ var normalFuncs = []*FuncInfo{
 	{Name: "safe_convert_bytes_to_string", BindFunc: bindSafeConvertBytesToString},
 	{Name: "soundex", BindFunc: bindSoundex},
 	{Name: "split", BindFunc: bindSplit},
+	{Name: "split_substr", BindFunc: bindSplitSubstr},
 	{Name: "starts_with", BindFunc: bindStartsWith},
 	{Name: "strpos", BindFunc: bindStrpos},
 	{Name: "substr", BindFunc: bindSubstr},
@@ -274,6 +281,7 @@ var normalFuncs = []*FuncInfo{
 
 	// javascript funcs
 	{Name: "eval_javascript", BindFunc: bindEvalJavaScript},
+	{Name: "eval_javascript_not_deterministic", BindFunc: bindEvalJavaScript},
 
 	// net funcs
 	{Name: "net_host", BindFunc: bindNetHost},
@@ -391,8 +399,50 @@ var (
 		"current_time":      {},
 		"current_timestamp": {},
 	}
+
+	// nonDeterministicFuncMap is a set of functions that return a different value for each call.
+	// They must not be registered as deterministic functions,
+	// otherwise SQLite evaluates them only once and uses the result for every row.
+	nonDeterministicFuncMap = map[string]struct{}{
+		"rand":                              {},
+		"generate_uuid":                     {},
+		"current_date":                      {},
+		"current_datetime":                  {},
+		"current_time":                      {},
+		"current_timestamp":                 {},
+		"eval_javascript_not_deterministic": {},
+	}
 )
 
+// collateValues compares the encoded values for zetasqlite_collate.
+func collateValues(a, b string) (ret int, e error) {
+	defer recoverFunctionPanic("zetasqlite_collate", &e)
+
+	va, err := DecodeValue(a)
+	if err != nil {
+		return 0, err
+	}
+	vb, err := DecodeValue(b)
+	if err != nil {
+		return 0, err
+	}
+	eq, err := va.EQ(vb)
+	if err != nil {
+		return 0, err
+	}
+	if eq {
+		return 0, nil
+	}
+	gt, err := va.GT(vb)
+	if err != nil {
+		return 0, err
+	}
+	if gt {
+		return 1, nil
+	}
+	return -1, nil
+}
+
 func RegisterFunctions(conn *sqlite3.SQLiteConn) error {
 	funcMapMu.RLock()
 	defer funcMapMu.RUnlock()
@@ -413,7 +463,8 @@ func RegisterFunctions(conn *sqlite3.SQLiteConn) error {
 		return onceErr
 	}
 
-	if err := conn.RegisterFunc("zetasqlite_decode_array", func(v interface{}) (string, error) {
+	if err := conn.RegisterFunc("zetasqlite_decode_array", func(v interface{}) (_ string, e error) {
+		defer recoverFunctionPanic("zetasqlite_decode_array", &e)
 		decoded, err := DecodeValue(v)
 		if err != nil {
 			return "", err
@@ -425,69 +476,125 @@ func RegisterFunctions(conn *sqlite3.SQLiteConn) error {
 		if err != nil {
 			return "", err
 		}
-		encodedValues := make([]interface{}, 0, len(array.values))
-		for _, value := range array.values {
-			v, err := EncodeValue(value)
-			if err != nil {
-				return "", err
-			}
-			encodedValues = append(encodedValues, v)
+		return encodeArrayElements(array.values)
+	}, true); err != nil {
+		return fmt.Errorf("failed to register decode_array function: %w", err)
+	}
+
+	if err := conn.RegisterFunc("zetasqlite_check_recursion_depth", func(depth, maxIterations int64) (int64, error) {
+		if maxIterations > 0 && depth > maxIterations {
+			return 0, fmt.Errorf(
+				"recursive query exceeded the maximum number of iterations (%d). add the termination condition to the recursive term or increase the limit",
+				maxIterations,
+			)
+		}
+		return depth, nil
+	}, true); err != nil {
+		return fmt.Errorf("failed to register check_recursion_depth function: %w", err)
+	}
+
+	if err := conn.RegisterFunc("zetasqlite_decode_array_filter", func(v interface{}, predicates ...interface{}) (_ string, e error) {
+		defer recoverFunctionPanic("zetasqlite_decode_array_filter", &e)
+		decoded, err := DecodeValue(v)
+		if err != nil {
+			return "", err
+		}
+		if decoded == nil {
+			return "[]", nil
 		}
-		b, err := json.Marshal(encodedValues)
+		array, err := decoded.ToArray()
 		if err != nil {
 			return "", err
 		}
-		return string(b), err
+		values, err := filterArrayElements(array.values, predicates)
+		if err != nil {
+			return "", err
+		}
+		return encodeArrayElements(values)
 	}, true); err != nil {
-		return fmt.Errorf("failed to register decode_array function: %w", err)
+		return fmt.Errorf("failed to register decode_array_filter function: %w", err)
 	}
 
-	if err := conn.RegisterFunc("zetasqlite_group_by", func(v interface{}) (interface{}, error) {
+	if err := conn.RegisterFunc("zetasqlite_group_by", func(v interface{}) (_ interface{}, e error) {
+		defer recoverFunctionPanic("zetasqlite_group_by", &e)
 		decoded, err := DecodeValue(v)
 		if err != nil {
 			return "", err
 		}
-		if decoded == nil {
+		switch decoded.(type) {
+		case nil:
 			return nil, nil
+		case *ArrayValue, *StructValue:
+			// ARRAY and STRUCT values are grouped by the key of the elements
+			// because the encoded values can be different for the equal values ( e.g. 0.0 and -0.0 ).
+			return canonicalKey(decoded)
 		}
 		return decoded.Interface(), nil
 	}, true); err != nil {
 		return fmt.Errorf("failed to register group_by function: %w", err)
 	}
 
+	if err := conn.RegisterFunc("zetasqlite_current_time_unix_nano", func() int64 {
+		return time.Now().UnixNano()
+	}, false); err != nil {
+		return fmt.Errorf("failed to register current_time_unix_nano function: %w", err)
+	}
+
 	if err := conn.RegisterCollation("zetasqlite_collate", func(a, b string) int {
-		va, _ := DecodeValue(a)
-		vb, _ := DecodeValue(b)
-		eq, _ := va.EQ(vb)
-		if eq {
-			return 0
-		}
-		cond, _ := va.GT(vb)
-		if cond {
-			return 1
+		ret, err := collateValues(a, b)
+		if err != nil {
+			// SQLite can't receive the error from the collation,
+			// so the values that can't be compared are ordered by the encoded text to keep the order consistent.
+			return strings.Compare(a, b)
 		}
-		return -1
+		return ret
 	}); err != nil {
 		return fmt.Errorf("failed to register collate function: %w", err)
 	}
 
-	for _, values := range normalFuncMap {
+	for name, values := range normalFuncMap {
+		_, nonDeterministic := nonDeterministicFuncMap[name]
 		for _, v := range values {
-			if err := conn.RegisterFunc(v.Name, v.Func, true); err != nil {
+			if err := conn.RegisterFunc(v.Name, v.Func, !nonDeterministic); err != nil {
 				return fmt.Errorf("failed to register function %s: %w", v.Name, err)
 			}
 		}
 	}
-	for _, values := range aggregateFuncMap {
+	memory := &aggregationMemory{}
+	if err := conn.RegisterFunc("zetasqlite_set_aggregation_memory_limit", func(limit int64) int64 {
+		memory.setLimit(limit)
+		return limit
+	}, false); err != nil {
+		return fmt.Errorf("failed to register set_aggregation_memory_limit function: %w", err)
+	}
+	for name, values := range aggregateFuncMap {
+		_, retainsValues := valueRetainingAggregateFuncMap[name]
 		for _, v := range values {
-			if err := conn.RegisterAggregator(v.Name, v.Func, true); err != nil {
+			f := v.Func
+			if newAggregator, ok := v.Func.(func() *Aggregator); ok {
+				f = func() *Aggregator {
+					aggregator := newAggregator()
+					aggregator.memory = memory
+					aggregator.retainsValues = retainsValues
+					return aggregator
+				}
+			}
+			if err := conn.RegisterAggregator(v.Name, f, true); err != nil {
 				return fmt.Errorf("failed to register aggregate function %s: %w", v.Name, err)
 			}
 		}
 	}
 	for _, values := range windowFuncMap {
 		for _, v := range values {
-			if err := conn.RegisterAggregator(v.Name, v.Func, true); err != nil {
+			f := v.Func
+			if newAggregator, ok := v.Func.(func() *WindowAggregator); ok {
+				f = func() *WindowAggregator {
+					aggregator := newAggregator()
+					aggregator.memory = memory
+					return aggregator
+				}
+			}
+			if err := conn.RegisterAggregator(v.Name, f, true); err != nil {
 				return fmt.Errorf("failed to register window function %s: %w", v.Name, err)
 			}
 		}
@@ -496,26 +603,42 @@ func RegisterFunctions(conn *sqlite3.SQLiteConn) error {
 }
 
 func setupNormalFuncMap(info *FuncInfo) {
+	name := fmt.Sprintf("zetasqlite_%s", info.Name)
+	safeName := fmt.Sprintf("zetasqlite_safe_%s", info.Name)
 	normalFuncMap[info.Name] = append(normalFuncMap[info.Name], &NameAndFunc{
-		Name: fmt.Sprintf("zetasqlite_%s", info.Name),
-		Func: func(args ...interface{}) (interface{}, error) {
+		Name: name,
+		Func: func(args ...interface{}) (_ interface{}, e error) {
+			defer recoverFunctionPanic(name, &e)
 			values, err := convertArgs(args...)
 			if err != nil {
 				return nil, err
 			}
+			values, err = coerceArgs(info.Name, values)
+			if err != nil {
+				return nil, err
+			}
 			ret, err := info.BindFunc(values...)
 			if err != nil {
 				return nil, err
 			}
+			if err := validateTimeValueRange(ret); err != nil {
+				return nil, err
+			}
 			return EncodeValue(ret)
 		},
 	}, &NameAndFunc{
-		Name: fmt.Sprintf("zetasqlite_safe_%s", info.Name),
-		Func: func(args ...interface{}) (interface{}, error) {
+		Name: safeName,
+		// The panic is not suppressed by SAFE. prefix because it is not the error caused by the input data.
+		Func: func(args ...interface{}) (_ interface{}, e error) {
+			defer recoverFunctionPanic(safeName, &e)
 			values, err := convertArgs(args...)
 			if err != nil {
 				return nil, err
 			}
+			values, err = coerceArgs(info.Name, values)
+			if err != nil {
+				return nil, err
+			}
 			ret, err := info.BindFunc(values...)
 			if err != nil {
 				// Note, this should only suppress semantic errors based on the
@@ -523,21 +646,36 @@ func setupNormalFuncMap(info *FuncInfo) {
 				// https://github.com/google/zetasql/blob/master/docs/resolved_ast.md#resolvedfunctioncallbase
 				return nil, nil
 			}
+			if err := validateTimeValueRange(ret); err != nil {
+				return nil, nil
+			}
 			return EncodeValue(ret)
 		},
 	})
 }
 
 func setupAggregateFuncMap(info *AggregateFuncInfo) {
+	name := fmt.Sprintf("zetasqlite_%s", info.Name)
+	newAggregator := info.BindFunc()
 	aggregateFuncMap[info.Name] = append(aggregateFuncMap[info.Name], &NameAndFunc{
-		Name: fmt.Sprintf("zetasqlite_%s", info.Name),
-		Func: info.BindFunc(),
+		Name: name,
+		Func: func() *Aggregator {
+			aggregator := newAggregator()
+			aggregator.name = name
+			return aggregator
+		},
 	})
 }
 
 func setupWindowFuncMap(info *WindowFuncInfo) {
+	name := fmt.Sprintf("zetasqlite_window_%s", info.Name)
+	newAggregator := info.BindFunc()
 	windowFuncMap[info.Name] = append(windowFuncMap[info.Name], &NameAndFunc{
-		Name: fmt.Sprintf("zetasqlite_window_%s", info.Name),
-		Func: info.BindFunc(),
+		Name: name,
+		Func: func() *WindowAggregator {
+			aggregator := newAggregator()
+			aggregator.name = name
+			return aggregator
+		},
 	})
 }
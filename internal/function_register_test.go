@@ -0,0 +1,105 @@
+package internal
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestFunctionPanicRecovery(t *testing.T) {
+	errPanic := errors.New("broken function")
+	info := &FuncInfo{
+		Name: "panic_test",
+		BindFunc: func(...Value) (Value, error) {
+			panic(errPanic)
+		},
+	}
+	setupNormalFuncMap(info)
+	defer delete(normalFuncMap, info.Name)
+
+	for _, fn := range normalFuncMap[info.Name] {
+		f := fn.Func.(func(...interface{}) (interface{}, error))
+		ret, err := f(int64(1))
+		if ret != nil {
+			t.Fatalf("%s: unexpected result %v", fn.Name, ret)
+		}
+		var panicErr *FunctionPanicError
+		if !errors.As(err, &panicErr) {
+			t.Fatalf("%s: expected FunctionPanicError but got %v", fn.Name, err)
+		}
+		if panicErr.FuncName != fn.Name {
+			t.Fatalf("unexpected function name %s", panicErr.FuncName)
+		}
+		if !errors.Is(err, errPanic) {
+			t.Fatalf("%s: panic value is not wrapped: %v", fn.Name, err)
+		}
+		if !strings.Contains(string(panicErr.Stack), "TestFunctionPanicRecovery") {
+			t.Fatalf("%s: stack is not captured: %s", fn.Name, panicErr.Stack)
+		}
+	}
+}
+
+func TestAggregatorPanicRecovery(t *testing.T) {
+	info := &AggregateFuncInfo{
+		Name: "panic_test",
+		BindFunc: func() func() *Aggregator {
+			return func() *Aggregator {
+				return newAggregator(
+					func([]Value, *AggregatorOption) error {
+						var values []Value
+						_ = values[0]
+						return nil
+					},
+					func() (Value, error) {
+						var m map[string]Value
+						m["v"] = nil
+						return nil, nil
+					},
+				)
+			}
+		},
+	}
+	setupAggregateFuncMap(info)
+	defer delete(aggregateFuncMap, info.Name)
+
+	fn := aggregateFuncMap[info.Name][0]
+	aggregator := fn.Func.(func() *Aggregator)()
+	var panicErr *FunctionPanicError
+	if err := aggregator.Step(int64(1)); !errors.As(err, &panicErr) || panicErr.FuncName != fn.Name {
+		t.Fatalf("expected FunctionPanicError from step but got %v", err)
+	}
+	if _, err := aggregator.Done(); !errors.As(err, &panicErr) || panicErr.FuncName != fn.Name {
+		t.Fatalf("expected FunctionPanicError from done but got %v", err)
+	}
+}
+
+func TestCollateValues(t *testing.T) {
+	encode := func(v Value) string {
+		encoded, err := EncodeValue(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return encoded.(string)
+	}
+	a := encode(StringValue("a"))
+	b := encode(StringValue("b"))
+	for _, test := range []struct {
+		a, b     string
+		expected int
+	}{
+		{a: a, b: b, expected: -1},
+		{a: b, b: a, expected: 1},
+		{a: a, b: a, expected: 0},
+	} {
+		ret, err := collateValues(test.a, test.b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ret != test.expected {
+			t.Fatalf("expected %d but got %d", test.expected, ret)
+		}
+	}
+	if _, err := collateValues("invalid value", a); err == nil {
+		t.Fatal("expected error for invalid value")
+	}
+}
@@ -6,7 +6,6 @@ import (
 	"encoding/base64"
 	"encoding/hex"
 	"fmt"
-	"math"
 	"regexp"
 	"strings"
 	"unicode"
@@ -67,9 +66,43 @@ func CODE_POINTS_TO_STRING(v *ArrayValue) (Value, error) {
 }
 
 func COLLATE(v, spec string) (Value, error) {
+	collator, err := newCollator(spec)
+	if err != nil {
+		return nil, fmt.Errorf("COLLATE: %w", err)
+	}
+	if collator == nil {
+		return StringValue(v), nil
+	}
+	var buf collate.Buffer
+	key := collator.KeyFromString(&buf, v)
+	// TODO: need to add key to string as collate information.
+	_ = key
+	return StringValue(v), nil
+}
+
+// COLLATION_KEY returns the sort key of the string for the collation specification.
+// It is used to compare values of the column that has the collation specification.
+func COLLATION_KEY(v, spec string) (Value, error) {
+	collator, err := newCollator(spec)
+	if err != nil {
+		return nil, fmt.Errorf("COLLATION_KEY: %w", err)
+	}
+	if collator == nil {
+		return BytesValue(v), nil
+	}
+	var buf collate.Buffer
+	return BytesValue(collator.KeyFromString(&buf, v)), nil
+}
+
+// newCollator creates collator from the collation specification.
+// If spec is the binary collation, returns nil.
+func newCollator(spec string) (*collate.Collator, error) {
+	if spec == "" || spec == "binary" {
+		return nil, nil
+	}
 	splitted := strings.Split(spec, ":")
 	if len(splitted) != 2 {
-		return nil, fmt.Errorf("COLLATE: unexpected spec literal %s", spec)
+		return nil, fmt.Errorf("unexpected spec literal %s", spec)
 	}
 	tag := language.Make(splitted[0])
 	var opt collate.Option
@@ -77,13 +110,9 @@ func COLLATE(v, spec string) (Value, error) {
 	case "ci": // case insensitive
 		opt = collate.IgnoreCase
 	default:
-		return nil, fmt.Errorf("COLLATE: unsupported collation attribute %s", splitted[1])
+		return nil, fmt.Errorf("unsupported collation attribute %s", splitted[1])
 	}
-	var buf collate.Buffer
-	key := collate.New(tag, opt).KeyFromString(&buf, v)
-	// TODO: need to add key to string as collate information.
-	_ = key
-	return StringValue(v), nil
+	return collate.New(tag, opt), nil
 }
 
 func CONCAT(args ...Value) (Value, error) {
@@ -137,6 +166,79 @@ func ENDS_WITH(value, ends Value) (Value, error) {
 	return nil, fmt.Errorf("ENDS_WITH: argument type must be STRING or BYTES")
 }
 
+// EDIT_DISTANCE computes the Levenshtein distance between the values.
+// STRING values are compared by characters and BYTES values are compared by bytes.
+// If maxDistance is specified, the computation stops and returns maxDistance when the distance exceeds it.
+func EDIT_DISTANCE(v1, v2 Value, maxDistance *int64) (Value, error) {
+	var a, b []rune
+	switch v1.(type) {
+	case StringValue:
+		if _, ok := v2.(StringValue); !ok {
+			return nil, fmt.Errorf("EDIT_DISTANCE: arguments are must be same type")
+		}
+		s1, err := v1.ToString()
+		if err != nil {
+			return nil, err
+		}
+		s2, err := v2.ToString()
+		if err != nil {
+			return nil, err
+		}
+		a, b = []rune(s1), []rune(s2)
+	case BytesValue:
+		if _, ok := v2.(BytesValue); !ok {
+			return nil, fmt.Errorf("EDIT_DISTANCE: arguments are must be same type")
+		}
+		b1, err := v1.ToBytes()
+		if err != nil {
+			return nil, err
+		}
+		b2, err := v2.ToBytes()
+		if err != nil {
+			return nil, err
+		}
+		a, b = bytesToRunes(b1), bytesToRunes(b2)
+	default:
+		return nil, fmt.Errorf("EDIT_DISTANCE: argument type must be STRING or BYTES")
+	}
+	if maxDistance != nil && *maxDistance < 0 {
+		return nil, fmt.Errorf("EDIT_DISTANCE: max_distance must be non-negative but specified %d", *maxDistance)
+	}
+	prev := make([]int64, len(b)+1)
+	cur := make([]int64, len(b)+1)
+	for j := range prev {
+		prev[j] = int64(j)
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = int64(i)
+		rowMin := cur[0]
+		for j := 1; j <= len(b); j++ {
+			cost := int64(1)
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = minInt64(minInt64(prev[j]+1, cur[j-1]+1), prev[j-1]+cost)
+			rowMin = minInt64(rowMin, cur[j])
+		}
+		if maxDistance != nil && rowMin > *maxDistance {
+			return IntValue(*maxDistance), nil
+		}
+		prev, cur = cur, prev
+	}
+	distance := prev[len(b)]
+	if maxDistance != nil && distance > *maxDistance {
+		return IntValue(*maxDistance), nil
+	}
+	return IntValue(distance), nil
+}
+
+func minInt64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
 func FORMAT(format string, args ...Value) (Value, error) {
 	result, err := parseFormat(format, args...)
 	if err != nil {
@@ -228,87 +330,95 @@ func INITCAP(value string, delimiters []rune) (Value, error) {
 
 func INSTR(source, search Value, position, occurrence int64) (Value, error) {
 	if position == 0 {
-		return nil, fmt.Errorf("INSTR: invalid position number. position is must be large than zero value")
+		return nil, fmt.Errorf("INSTR: invalid position number. position is must be non zero value")
 	}
 	if occurrence <= 0 {
 		return nil, fmt.Errorf("INSTR: invalid occurrence number. occurrence is must be large than zero value. but specified %d", occurrence)
 	}
-	pos := int(math.Abs(float64(position)))
-	if _, ok := source.(StringValue); ok {
+	var src, substr []rune
+	switch source.(type) {
+	case StringValue:
 		if _, ok := search.(StringValue); !ok {
 			return nil, fmt.Errorf("INSTR: source and search are must be same type")
 		}
-		src, err := source.ToString()
+		s, err := source.ToString()
 		if err != nil {
 			return nil, err
 		}
-		search, err := search.ToString()
+		sub, err := search.ToString()
 		if err != nil {
 			return nil, err
 		}
-		if pos >= len(src) {
-			return nil, fmt.Errorf("INSTR: invalid position number. position %d is larger than source value length %d", pos, len(src))
-		}
-		length := len(src)
-		if position < 0 {
-			src = src[:len(src)-pos+1]
-		} else {
-			src = src[pos-1:]
-		}
-		var found int64
-		for i := 0; i < len(src); i++ {
-			idx := strings.Index(src[i:], search)
-			if idx >= 0 {
-				found++
-				i += idx
-			}
-			if found == occurrence {
-				if position < 0 {
-					return IntValue(length - i - 1), nil
-				}
-				return IntValue(pos + i), nil
-			}
-		}
-		return IntValue(0), nil
-	}
-	if _, ok := source.(BytesValue); ok {
+		src, substr = []rune(s), []rune(sub)
+	case BytesValue:
 		if _, ok := search.(BytesValue); !ok {
 			return nil, fmt.Errorf("INSTR: source and search are must be same type")
 		}
-		src, err := source.ToBytes()
+		b, err := source.ToBytes()
 		if err != nil {
 			return nil, err
 		}
-		search, err := search.ToBytes()
+		sub, err := search.ToBytes()
 		if err != nil {
 			return nil, err
 		}
-		if pos >= len(src) {
-			return nil, fmt.Errorf("INSTR: invalid position number. position %d is larger than source value length %d", pos, len(src))
-		}
-		length := len(src)
-		if position < 0 {
-			src = src[:len(src)-pos+1]
-		} else {
-			src = src[pos-1:]
+		src, substr = bytesToRunes(b), bytesToRunes(sub)
+	default:
+		return nil, fmt.Errorf("INSTR: source and search type are must be STRING or BYTES type")
+	}
+	return IntValue(instrIndex(src, substr, position, occurrence)), nil
+}
+
+// bytesToRunes converts each byte to a rune to search BYTES value by the same way as STRING value.
+func bytesToRunes(b []byte) []rune {
+	ret := make([]rune, len(b))
+	for i, c := range b {
+		ret[i] = rune(c)
+	}
+	return ret
+}
+
+// instrIndex returns the 1-based position of the occurrence-th substr in src, or 0 if it is not found.
+// If position is positive, the search starts at position and proceeds forward.
+// If position is negative, the search starts at position counted from the end and proceeds backward.
+// Overlapping occurrences are counted.
+func instrIndex(src, substr []rune, position, occurrence int64) int64 {
+	matchAt := func(i int) bool {
+		if i+len(substr) > len(src) {
+			return false
+		}
+		for j, r := range substr {
+			if src[i+j] != r {
+				return false
+			}
 		}
-		var found int64
-		for i := 0; i < len(src); i++ {
-			idx := bytes.Index(src[i:], search)
-			if idx >= 0 {
+		return true
+	}
+	var found int64
+	if position > 0 {
+		for i := int(position - 1); i < len(src); i++ {
+			if matchAt(i) {
 				found++
-				i += idx
+				if found == occurrence {
+					return int64(i + 1)
+				}
 			}
+		}
+		return 0
+	}
+	start := len(src) + int(position)
+	if start < 0 {
+		return 0
+	}
+	for i := start; i >= 0; i-- {
+		if matchAt(i) {
+			found++
 			if found == occurrence {
-				if position < 0 {
-					return IntValue(length - i - 1), nil
-				}
-				return IntValue(pos + i), nil
+				return int64(i + 1)
 			}
 		}
-		return IntValue(0), nil
 	}
-	return nil, fmt.Errorf("INSTR: source and search type are must be STRING or BYTES type")
+	return 0
 }
 
 func LEFT(v Value, length int64) (Value, error) {
@@ -987,6 +1097,35 @@ func SPLIT(value, delimValue Value) (Value, error) {
 	return nil, fmt.Errorf("SPLIT: value must be STRING or BYTES")
 }
 
+// SPLIT_SUBSTR splits value by delimiter and returns the substring made of count splits from startSplit.
+// startSplit is 1-based, and the negative number counts from the end.
+// If count is nil, all splits after startSplit are returned.
+func SPLIT_SUBSTR(value, delimiter string, startSplit int64, count *int64) (Value, error) {
+	if delimiter == "" {
+		return nil, fmt.Errorf("SPLIT_SUBSTR: delimiter must not be empty")
+	}
+	if count != nil && *count < 0 {
+		return nil, fmt.Errorf("SPLIT_SUBSTR: count must be non-negative but specified %d", *count)
+	}
+	splits := strings.Split(value, delimiter)
+	num := int64(len(splits))
+	start := startSplit
+	switch {
+	case start == 0 || start < -num:
+		start = 1
+	case start < 0:
+		start = num + start + 1
+	}
+	if start > num {
+		return StringValue(""), nil
+	}
+	end := num
+	if count != nil && start-1+*count < end {
+		end = start - 1 + *count
+	}
+	return StringValue(strings.Join(splits[start-1:end], delimiter)), nil
+}
+
 func STARTS_WITH(value, starts Value) (Value, error) {
 	switch value.(type) {
 	case StringValue:
@@ -1024,7 +1163,12 @@ func STRPOS(value, search Value) (Value, error) {
 		if err != nil {
 			return nil, err
 		}
-		return IntValue(strings.Index(v, s) + 1), nil
+		idx := strings.Index(v, s)
+		if idx < 0 {
+			return IntValue(0), nil
+		}
+		// STRPOS returns the position of the character, not the byte.
+		return IntValue(utf8.RuneCountInString(v[:idx]) + 1), nil
 	case BytesValue:
 		v, err := value.ToBytes()
 		if err != nil {
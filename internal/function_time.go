@@ -100,6 +100,18 @@ func TIME_SUB(t time.Time, v int64, part string) (Value, error) {
 	return nil, fmt.Errorf("TIME_SUB: unexpected part value %s", part)
 }
 
+// addTimeInterval adds the interval multiplied by sign to t for TIME +/- INTERVAL.
+// Like TIME_ADD, the result wraps around at midnight, so only the time parts of the interval can be added.
+func addTimeInterval(t time.Time, iv *IntervalValue, sign int64) (Value, error) {
+	if iv.Years != 0 || iv.Months != 0 || iv.Days != 0 {
+		return nil, fmt.Errorf("TIME +/- INTERVAL is not supported for intervals with non-zero YEAR, MONTH or DAY part")
+	}
+	const secondsPerDay = 24 * 60 * 60
+	seconds := (int64(iv.Hours)*60*60 + int64(iv.Minutes)*60 + int64(iv.Seconds)) % secondsPerDay
+	duration := time.Duration(seconds)*time.Second + time.Duration(iv.SubSecondNanos)
+	return TimeValue(t.Add(time.Duration(sign) * duration)), nil
+}
+
 func TIME_DIFF(a, b time.Time, part string) (Value, error) {
 	diff := a.Sub(b)
 	switch part {
@@ -56,39 +56,58 @@ func TIMESTAMP(v Value, zone string) (Value, error) {
 }
 
 func TIMESTAMP_ADD(t time.Time, v int64, part string) (Value, error) {
-	switch part {
-	case "MICROSECOND":
-		return TimestampValue(t.Add(time.Duration(v) * time.Microsecond)), nil
-	case "MILLISECOND":
-		return TimestampValue(t.Add(time.Duration(v) * time.Millisecond)), nil
-	case "SECOND":
-		return TimestampValue(t.Add(time.Duration(v) * time.Second)), nil
-	case "MINUTE":
-		return TimestampValue(t.Add(time.Duration(v) * time.Minute)), nil
-	case "HOUR":
-		return TimestampValue(t.Add(time.Duration(v) * time.Hour)), nil
-	case "DAY":
-		return TimestampValue(t.AddDate(0, 0, int(v))), nil
+	ret, ok, err := addTimestamp(t, v, part)
+	if err != nil {
+		return nil, fmt.Errorf("TIMESTAMP_ADD: %w", err)
+	}
+	if !ok {
+		return nil, fmt.Errorf("Adding %d %s to timestamp %s causes overflow", v, part, formatTimestampForError(t))
 	}
-	return nil, fmt.Errorf("TIMESTAMP_ADD: unexpected part value %s", part)
+	return TimestampValue(ret), nil
 }
 
 func TIMESTAMP_SUB(t time.Time, v int64, part string) (Value, error) {
-	switch part {
-	case "MICROSECOND":
-		return TimestampValue(t.Add(-time.Duration(v) * time.Microsecond)), nil
-	case "MILLISECOND":
-		return TimestampValue(t.Add(-time.Duration(v) * time.Millisecond)), nil
-	case "SECOND":
-		return TimestampValue(t.Add(-time.Duration(v) * time.Second)), nil
-	case "MINUTE":
-		return TimestampValue(t.Add(-time.Duration(v) * time.Minute)), nil
-	case "HOUR":
-		return TimestampValue(t.Add(-time.Duration(v) * time.Hour)), nil
-	case "DAY":
-		return TimestampValue(t.AddDate(0, 0, -int(v))), nil
+	ret, ok, err := addTimestamp(t, -v, part)
+	if err != nil {
+		return nil, fmt.Errorf("TIMESTAMP_SUB: %w", err)
 	}
-	return nil, fmt.Errorf("TIMESTAMP_SUB: unexpected part value %s", part)
+	if !ok {
+		return nil, fmt.Errorf("Subtracting %d %s from timestamp %s causes overflow", v, part, formatTimestampForError(t))
+	}
+	return TimestampValue(ret), nil
+}
+
+// addTimestamp adds v parts to t. false is returned if the result is out of the range of TIMESTAMP.
+func addTimestamp(t time.Time, v int64, part string) (time.Time, bool, error) {
+	if part == "DAY" {
+		if v > maxCivilDays || v < -maxCivilDays {
+			return time.Time{}, false, nil
+		}
+		ret := t.AddDate(0, 0, int(v))
+		return ret, isValidTimestamp(ret), nil
+	}
+	unit, exists := datetimeUnitMap[part]
+	if !exists {
+		return time.Time{}, false, fmt.Errorf("unexpected part value %s", part)
+	}
+	ret, ok := addTimeUnit(t, v, unit)
+	return ret, ok && isValidTimestamp(ret), nil
+}
+
+// addTimestampInterval adds the interval multiplied by sign to t for TIMESTAMP +/- INTERVAL.
+// TIMESTAMP has no time zone, so a day is always 24 hours, and the months can't be added like BigQuery.
+func addTimestampInterval(t time.Time, iv *IntervalValue, sign int64) (Value, error) {
+	if iv.Years != 0 || iv.Months != 0 {
+		return nil, fmt.Errorf("TIMESTAMP +/- INTERVAL is not supported for intervals with non-zero MONTH or YEAR part")
+	}
+	ret, ok := addInterval(t.UTC(), iv, sign)
+	if !ok || !isValidTimestamp(ret) {
+		if sign < 0 {
+			return nil, fmt.Errorf("Subtracting interval %s from timestamp %s causes overflow", iv.Format('s'), formatTimestampForError(t))
+		}
+		return nil, fmt.Errorf("Adding interval %s to timestamp %s causes overflow", iv.Format('s'), formatTimestampForError(t))
+	}
+	return TimestampValue(ret), nil
 }
 
 func TIMESTAMP_DIFF(a, b time.Time, part string) (Value, error) {
@@ -229,15 +248,23 @@ func PARSE_TIMESTAMP_WITH_TIMEZONE(format, date, zone string) (Value, error) {
 }
 
 func TIMESTAMP_SECONDS(sec int64) (Value, error) {
-	return TimestampValue(time.Unix(sec, 0)), nil
+	return timestampFromUnix(sec, time.Second)
 }
 
 func TIMESTAMP_MILLIS(sec int64) (Value, error) {
-	return TimestampValue(time.UnixMicro(sec * 1000)), nil
+	return timestampFromUnix(sec, time.Millisecond)
 }
 
 func TIMESTAMP_MICROS(sec int64) (Value, error) {
-	return TimestampValue(time.UnixMicro(sec)), nil
+	return timestampFromUnix(sec, time.Microsecond)
+}
+
+func timestampFromUnix(v int64, unit time.Duration) (Value, error) {
+	t, ok := addTimeUnit(time.Unix(0, 0).UTC(), v, unit)
+	if !ok || !isValidTimestamp(t) {
+		return nil, fmt.Errorf("Invalid timestamp value: %d", v)
+	}
+	return TimestampValue(t), nil
 }
 
 func UNIX_SECONDS(t time.Time) (Value, error) {
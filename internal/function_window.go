@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"math"
 	"sort"
-	"strings"
 	"sync"
 
 	"gonum.org/v1/gonum/stat"
@@ -20,10 +19,12 @@ func (f *WINDOW_ANY_VALUE) Step(v Value, opt *WindowFuncStatus, agg *WindowFuncA
 func (f *WINDOW_ANY_VALUE) Done(agg *WindowFuncAggregatedStatus) (Value, error) {
 	var value Value
 	if err := agg.Done(func(values []Value, start, end int) error {
-		if len(values) == 0 {
-			return nil
+		for _, v := range values[start : end+1] {
+			if v != nil {
+				value = v
+				break
+			}
 		}
-		value = values[start]
 		return nil
 	}); err != nil {
 		return nil, err
@@ -35,9 +36,6 @@ type WINDOW_ARRAY_AGG struct {
 }
 
 func (f *WINDOW_ARRAY_AGG) Step(v Value, opt *WindowFuncStatus, agg *WindowFuncAggregatedStatus) error {
-	if v == nil {
-		return fmt.Errorf("ARRAY_AGG: input value must be not null")
-	}
 	return agg.Step(v, opt)
 }
 
@@ -52,13 +50,14 @@ func (f *WINDOW_ARRAY_AGG) Done(agg *WindowFuncAggregatedStatus) (Value, error)
 			valueMap       = map[string]struct{}{}
 		)
 		for _, v := range values[start : end+1] {
-			if agg.IgnoreNulls() {
-				if v == nil {
+			if v == nil {
+				if agg.IgnoreNulls() {
 					continue
 				}
+				return fmt.Errorf("ARRAY_AGG: input value must be not null")
 			}
 			if agg.Distinct() {
-				key, err := v.ToString()
+				key, err := canonicalKey(v)
 				if err != nil {
 					return err
 				}
@@ -99,7 +98,7 @@ func (f *WINDOW_AVG) Done(agg *WindowFuncAggregatedStatus) (Value, error) {
 				continue
 			}
 			if agg.Distinct() {
-				key, err := value.ToString()
+				key, err := canonicalKey(value)
 				if err != nil {
 					return err
 				}
@@ -153,7 +152,7 @@ func (f *WINDOW_COUNT) Done(agg *WindowFuncAggregatedStatus) (Value, error) {
 				continue
 			}
 			if agg.Distinct() {
-				key, err := v.ToString()
+				key, err := canonicalKey(v)
 				if err != nil {
 					return err
 				}
@@ -289,30 +288,31 @@ func (f *WINDOW_MIN) Done(agg *WindowFuncAggregatedStatus) (Value, error) {
 }
 
 type WINDOW_STRING_AGG struct {
-	delim string
-	once  sync.Once
+	delim Value
 }
 
-func (f *WINDOW_STRING_AGG) Step(v Value, delim string, opt *WindowFuncStatus, agg *WindowFuncAggregatedStatus) error {
-	f.once.Do(func() {
-		if delim == "" {
-			delim = ","
+func (f *WINDOW_STRING_AGG) Step(v Value, delim Value, opt *WindowFuncStatus, agg *WindowFuncAggregatedStatus) error {
+	if v != nil && f.delim == nil {
+		d, err := stringAggDelimiter(v, delim)
+		if err != nil {
+			return err
 		}
-		f.delim = delim
-	})
+		f.delim = d
+	}
 	return agg.Step(v, opt)
 }
 
 func (f *WINDOW_STRING_AGG) Done(agg *WindowFuncAggregatedStatus) (Value, error) {
-	var strValues []string
+	var ret Value
 	if err := agg.Done(func(values []Value, start, end int) error {
+		var aggregated []Value
 		valueMap := map[string]struct{}{}
 		for _, value := range values[start : end+1] {
 			if value == nil {
 				continue
 			}
 			if agg.Distinct() {
-				key, err := value.ToString()
+				key, err := canonicalKey(value)
 				if err != nil {
 					return err
 				}
@@ -321,20 +321,18 @@ func (f *WINDOW_STRING_AGG) Done(agg *WindowFuncAggregatedStatus) (Value, error)
 				}
 				valueMap[key] = struct{}{}
 			}
-			text, err := value.ToString()
-			if err != nil {
-				return err
-			}
-			strValues = append(strValues, text)
+			aggregated = append(aggregated, value)
 		}
+		joined, err := joinStringAggValues(aggregated, f.delim)
+		if err != nil {
+			return err
+		}
+		ret = joined
 		return nil
 	}); err != nil {
 		return nil, err
 	}
-	if len(strValues) == 0 {
-		return nil, nil
-	}
-	return StringValue(strings.Join(strValues, f.delim)), nil
+	return ret, nil
 }
 
 type WINDOW_SUM struct {
@@ -345,7 +343,7 @@ func (f *WINDOW_SUM) Step(v Value, opt *WindowFuncStatus, agg *WindowFuncAggrega
 }
 
 func (f *WINDOW_SUM) Done(agg *WindowFuncAggregatedStatus) (Value, error) {
-	var sum Value
+	var sum sumAccumulator
 	if err := agg.Done(func(values []Value, start, end int) error {
 		valueMap := map[string]struct{}{}
 		for _, value := range values[start : end+1] {
@@ -353,7 +351,7 @@ func (f *WINDOW_SUM) Done(agg *WindowFuncAggregatedStatus) (Value, error) {
 				continue
 			}
 			if agg.Distinct() {
-				key, err := value.ToString()
+				key, err := canonicalKey(value)
 				if err != nil {
 					return err
 				}
@@ -362,21 +360,15 @@ func (f *WINDOW_SUM) Done(agg *WindowFuncAggregatedStatus) (Value, error) {
 				}
 				valueMap[key] = struct{}{}
 			}
-			if sum == nil {
-				sum = value
-			} else {
-				added, err := sum.Add(value)
-				if err != nil {
-					return err
-				}
-				sum = added
+			if err := sum.add(value); err != nil {
+				return err
 			}
 		}
 		return nil
 	}); err != nil {
 		return nil, err
 	}
-	return sum, nil
+	return sum.result()
 }
 
 type WINDOW_FIRST_VALUE struct {
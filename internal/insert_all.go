@@ -0,0 +1,251 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// DefaultInsertIDWindowSize is the default number of insertId kept for each table to deduplicate the rows of InsertAll.
+const DefaultInsertIDWindowSize = 1000
+
+const createInsertIDTableQuery = `
+CREATE TABLE IF NOT EXISTS zetasqlite_insert_ids(
+  seq INTEGER PRIMARY KEY AUTOINCREMENT,
+  tableName STRING NOT NULL,
+  insertId STRING NOT NULL
+)
+`
+
+// InsertAllRow is the row inserted by InsertAll like the rows of tabledata.insertAll of BigQuery.
+type InsertAllRow struct {
+	// InsertID is the identifier to deduplicate the row inserted by the retry.
+	// If InsertID is empty, the row is always inserted.
+	InsertID string
+	// Values is the column values of the row.
+	// The missing columns are inserted as the DEFAULT value of the column, or NULL if the column has no DEFAULT.
+	Values map[string]interface{}
+}
+
+// InsertAllResult is the result of InsertAll.
+type InsertAllResult struct {
+	// InsertedRows is the number of rows inserted to the table.
+	InsertedRows int64
+	// DeduplicatedRows is the number of rows skipped because the same insertId was found in the window.
+	DeduplicatedRows int64
+}
+
+// InsertAll inserts the rows to the table like the streaming insert of BigQuery.
+// The rows that have the insertId found in the recent insertIds of the table are skipped.
+// The window keeps the last windowSize insertIds for each table, so the deduplication is deterministic unlike BigQuery.
+// All rows are validated before inserting, and the rows are inserted in the savepoint,
+// so no rows are inserted if any row is invalid or fails to be inserted.
+func (a *Analyzer) InsertAll(ctx context.Context, conn *Conn, table string, rows []*InsertAllRow, windowSize int) (*InsertAllResult, error) {
+	if windowSize < 0 {
+		return nil, fmt.Errorf("invalid insertId window size %d: the window size must not be negative", windowSize)
+	}
+	if a.isReadOnlyMode {
+		return nil, fmt.Errorf("InsertAll is not allowed in read-only mode")
+	}
+	if err := a.catalog.Sync(ctx, conn); err != nil {
+		return nil, fmt.Errorf("failed to sync catalog: %w", err)
+	}
+	name := a.namePath.format(strings.Split(table, "."))
+	spec := a.catalog.tableSpec(name)
+	if spec == nil {
+		return nil, fmt.Errorf("Table not found: %s", table)
+	}
+	if spec.IsView {
+		return nil, fmt.Errorf("cannot insert rows to view %s", table)
+	}
+	encodedRows := make([]*encodedInsertAllRow, 0, len(rows))
+	for idx, row := range rows {
+		encoded, err := encodeInsertAllRow(spec, row)
+		if err != nil {
+			return nil, fmt.Errorf("failed to insert row %d: %w", idx, err)
+		}
+		encodedRows = append(encodedRows, encoded)
+	}
+	if _, err := conn.ExecContext(ctx, createInsertIDTableQuery); err != nil {
+		return nil, fmt.Errorf("failed to create insertId table: %w", err)
+	}
+
+	// the cached query results reading the table are stale after the rows are inserted.
+	defer a.catalog.InvalidateTableResultCache(spec.TableName())
+
+	if _, err := conn.ExecContext(ctx, "SAVEPOINT zetasqlite_insert_all"); err != nil {
+		return nil, fmt.Errorf("failed to begin inserting rows: %w", err)
+	}
+	result, err := a.insertAllInSavepoint(ctx, conn, spec, rows, encodedRows, windowSize)
+	if err != nil {
+		_, _ = conn.ExecContext(ctx, "ROLLBACK TO zetasqlite_insert_all")
+		_, _ = conn.ExecContext(ctx, "RELEASE zetasqlite_insert_all")
+		return nil, err
+	}
+	if _, err := conn.ExecContext(ctx, "RELEASE zetasqlite_insert_all"); err != nil {
+		return nil, fmt.Errorf("failed to finish inserting rows: %w", err)
+	}
+	return result, nil
+}
+
+func (a *Analyzer) insertAllInSavepoint(ctx context.Context, conn *Conn, spec *TableSpec, rows []*InsertAllRow, encodedRows []*encodedInsertAllRow, windowSize int) (*InsertAllResult, error) {
+	result := &InsertAllResult{}
+	for idx, row := range rows {
+		if row.InsertID != "" {
+			found, err := a.existsInsertID(ctx, conn, spec.TableName(), row.InsertID)
+			if err != nil {
+				return nil, err
+			}
+			if found {
+				result.DeduplicatedRows++
+				continue
+			}
+		}
+		if _, err := conn.ExecContext(ctx, encodedRows[idx].insertQuery(spec), encodedRows[idx].values...); err != nil {
+			return nil, fmt.Errorf("failed to insert row %d: %w", idx, err)
+		}
+		result.InsertedRows++
+		if row.InsertID == "" {
+			continue
+		}
+		if _, err := conn.ExecContext(
+			ctx,
+			"INSERT INTO zetasqlite_insert_ids (tableName, insertId) VALUES (?, ?)",
+			spec.TableName(), row.InsertID,
+		); err != nil {
+			return nil, fmt.Errorf("failed to save insertId: %w", err)
+		}
+	}
+	if _, err := conn.ExecContext(
+		ctx,
+		`DELETE FROM zetasqlite_insert_ids WHERE tableName = ? AND seq NOT IN (
+  SELECT seq FROM zetasqlite_insert_ids WHERE tableName = ? ORDER BY seq DESC LIMIT ?
+)`,
+		spec.TableName(), spec.TableName(), windowSize,
+	); err != nil {
+		return nil, fmt.Errorf("failed to trim insertId window: %w", err)
+	}
+	return result, nil
+}
+
+func (a *Analyzer) existsInsertID(ctx context.Context, conn *Conn, tableName, insertID string) (bool, error) {
+	rows, err := conn.QueryContext(
+		ctx,
+		"SELECT 1 FROM zetasqlite_insert_ids WHERE tableName = ? AND insertId = ? LIMIT 1",
+		tableName, insertID,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to find insertId: %w", err)
+	}
+	defer rows.Close()
+	found := rows.Next()
+	if err := rows.Err(); err != nil {
+		return false, fmt.Errorf("failed to find insertId: %w", err)
+	}
+	return found, nil
+}
+
+// deleteInsertIDs deletes the insertIds of the dropped table,
+// so that the rows inserted to the table created again with the same name aren't deduplicated by them.
+func deleteInsertIDs(ctx context.Context, conn *Conn, tableName string) error {
+	exists, err := existsInsertIDTable(ctx, conn)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return nil
+	}
+	if _, err := conn.ExecContext(ctx, "DELETE FROM zetasqlite_insert_ids WHERE tableName = ?", tableName); err != nil {
+		return fmt.Errorf("failed to delete insertIds of %s: %w", tableName, err)
+	}
+	return nil
+}
+
+func existsInsertIDTable(ctx context.Context, conn *Conn) (bool, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT 1 FROM sqlite_master WHERE type = 'table' AND name = 'zetasqlite_insert_ids'")
+	if err != nil {
+		return false, fmt.Errorf("failed to find insertId table: %w", err)
+	}
+	defer rows.Close()
+	exists := rows.Next()
+	if err := rows.Err(); err != nil {
+		return false, fmt.Errorf("failed to find insertId table: %w", err)
+	}
+	return exists, nil
+}
+
+// encodedInsertAllRow is the encoded values of the row and the columns to insert them.
+type encodedInsertAllRow struct {
+	columns []string
+	values  []interface{}
+}
+
+func (r *encodedInsertAllRow) insertQuery(spec *TableSpec) string {
+	columns := make([]string, 0, len(r.columns))
+	placeholders := make([]string, 0, len(r.columns))
+	for _, col := range r.columns {
+		columns = append(columns, fmt.Sprintf("`%s`", col))
+		placeholders = append(placeholders, "?")
+	}
+	if len(columns) == 0 {
+		return fmt.Sprintf("INSERT INTO `%s` DEFAULT VALUES", spec.TableName())
+	}
+	return fmt.Sprintf(
+		"INSERT INTO `%s` (%s) VALUES (%s)",
+		spec.TableName(), strings.Join(columns, ","), strings.Join(placeholders, ","),
+	)
+}
+
+// encodeInsertAllRow converts the values of the row to the column types and encodes them in the order of the columns.
+// The missing columns that have DEFAULT are omitted, so that SQLite evaluates DEFAULT for the row.
+func encodeInsertAllRow(spec *TableSpec, row *InsertAllRow) (*encodedInsertAllRow, error) {
+	for name := range row.Values {
+		if spec.Column(name) == nil {
+			return nil, fmt.Errorf("no such field: %s", name)
+		}
+	}
+	encoded := &encodedInsertAllRow{}
+	for _, col := range spec.Columns {
+		var (
+			goValue interface{}
+			exists  bool
+		)
+		for name, v := range row.Values {
+			if strings.EqualFold(name, col.Name) {
+				goValue = v
+				exists = true
+				break
+			}
+		}
+		value, err := ValueFromGoValue(goValue)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert value of %s: %w", col.Name, err)
+		}
+		if value == nil {
+			if !exists && col.DefaultValue != "" {
+				continue
+			}
+			if col.IsNotNull {
+				return nil, fmt.Errorf("missing required field: %s", col.Name)
+			}
+			encoded.columns = append(encoded.columns, col.Name)
+			encoded.values = append(encoded.values, nil)
+			continue
+		}
+		typ, err := col.Type.ToZetaSQLType()
+		if err != nil {
+			return nil, err
+		}
+		casted, err := CastValue(typ, value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert value of %s to %s: %w", col.Name, col.Type.FormatType(), err)
+		}
+		v, err := EncodeValue(casted)
+		if err != nil {
+			return nil, err
+		}
+		encoded.columns = append(encoded.columns, col.Name)
+		encoded.values = append(encoded.values, v)
+	}
+	return encoded, nil
+}
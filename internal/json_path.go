@@ -0,0 +1,249 @@
+package internal
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/goccy/go-json"
+)
+
+// jsonPathMode is the mode specified by the prefix of JSONPath like `lax $.a`.
+type jsonPathMode int
+
+const (
+	jsonPathModeStrict jsonPathMode = iota
+	// jsonPathModeLax applies the member access to each element of the array and wraps the results by the array.
+	jsonPathModeLax
+	// jsonPathModeLaxRecursive unwraps the nested arrays recursively to apply the member access.
+	jsonPathModeLaxRecursive
+)
+
+// jsonPathToken is the member access or the array subscript of JSONPath.
+type jsonPathToken struct {
+	member  string
+	index   int
+	isIndex bool
+}
+
+// splitJSONPathMode splits JSONPath into the mode and the path.
+// The mode keywords are case-insensitive ( e.g. `LAX RECURSIVE $.a` ).
+func splitJSONPathMode(path string) (jsonPathMode, string, error) {
+	fields := strings.Fields(path)
+	var keywords []string
+	for _, field := range fields {
+		if strings.HasPrefix(field, "$") {
+			break
+		}
+		keywords = append(keywords, strings.ToLower(field))
+	}
+	if len(keywords) == 0 {
+		return jsonPathModeStrict, path, nil
+	}
+	trimmed := strings.TrimSpace(path)
+	for _, keyword := range keywords {
+		trimmed = strings.TrimSpace(trimmed[len(keyword):])
+	}
+	switch strings.Join(keywords, " ") {
+	case "lax":
+		return jsonPathModeLax, trimmed, nil
+	case "lax recursive", "recursive lax":
+		return jsonPathModeLaxRecursive, trimmed, nil
+	case "recursive":
+		return 0, "", fmt.Errorf("JSONPath %q: recursive mode must be specified with lax mode ( e.g. `lax recursive $.a` )", path)
+	}
+	return 0, "", fmt.Errorf("JSONPath %q: unsupported mode %q. only lax and lax recursive are supported", path, strings.Join(keywords, " "))
+}
+
+// validateJSONPath returns an error if the path uses the syntax that isn't supported by BigQuery.
+// BigQuery doesn't support wildcards and recursive descent of JSONPath. Instead, lax recursive mode of JSON_QUERY can be used.
+func validateJSONPath(path string) error {
+	var quote rune
+	for i, c := range path {
+		if quote != 0 {
+			if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch c {
+		case '"', '\'':
+			quote = c
+		case '*':
+			return fmt.Errorf("JSONPath %q: wildcard `*` is not supported", path)
+		case '.':
+			if i+1 < len(path) && path[i+1] == '.' {
+				return fmt.Errorf("JSONPath %q: recursive descent `..` is not supported. use `lax recursive` mode of JSON_QUERY instead", path)
+			}
+		}
+	}
+	return nil
+}
+
+// createJSONPath creates the path after validating it.
+// The mode prefix is accepted only by JSON_QUERY, so the other functions report an error.
+func createJSONPath(funcName, path string) (*json.Path, error) {
+	mode, _, err := splitJSONPathMode(path)
+	if err != nil {
+		return nil, err
+	}
+	if mode != jsonPathModeStrict {
+		return nil, fmt.Errorf("%s: JSONPath mode is supported only by JSON_QUERY: %q", funcName, path)
+	}
+	if err := validateJSONPath(path); err != nil {
+		return nil, err
+	}
+	return json.CreatePath(path)
+}
+
+// parseJSONPath parses the path of lax mode into the tokens.
+// The member name can be quoted by double quotes like JSON_QUERY ( e.g. `$."a.b"` ).
+func parseJSONPath(path string) ([]*jsonPathToken, error) {
+	if err := validateJSONPath(path); err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("JSONPath %q: must start with `$`", path)
+	}
+	var tokens []*jsonPathToken
+	for i := 1; i < len(path); {
+		switch path[i] {
+		case '.':
+			i++
+			if i < len(path) && path[i] == '"' {
+				end := strings.IndexByte(path[i+1:], '"')
+				if end < 0 {
+					return nil, fmt.Errorf("JSONPath %q: unterminated quoted member", path)
+				}
+				tokens = append(tokens, &jsonPathToken{member: path[i+1 : i+1+end]})
+				i += end + 2
+				continue
+			}
+			start := i
+			for i < len(path) && path[i] != '.' && path[i] != '[' {
+				i++
+			}
+			if start == i {
+				return nil, fmt.Errorf("JSONPath %q: empty member name", path)
+			}
+			tokens = append(tokens, &jsonPathToken{member: path[start:i]})
+		case '[':
+			end := strings.IndexByte(path[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("JSONPath %q: unterminated subscript", path)
+			}
+			index, err := strconv.Atoi(strings.TrimSpace(path[i+1 : i+end]))
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("JSONPath %q: invalid array index %q", path, path[i+1:i+end])
+			}
+			tokens = append(tokens, &jsonPathToken{index: index, isIndex: true})
+			i += end + 1
+		default:
+			return nil, fmt.Errorf("JSONPath %q: unexpected character %q", path, path[i])
+		}
+	}
+	return tokens, nil
+}
+
+// extractJSONByLaxPath evaluates the path of lax mode and returns all matched values as JSON array.
+// In lax mode, the member access to the array is applied to the elements,
+// and the array subscript to the value other than array treats the value as the array of a single element.
+func extractJSONByLaxPath(v, path string, mode jsonPathMode) (string, error) {
+	tokens, err := parseJSONPath(path)
+	if err != nil {
+		return "", err
+	}
+	current := []json.RawMessage{json.RawMessage(v)}
+	for _, token := range tokens {
+		var next []json.RawMessage
+		for _, value := range current {
+			matched, err := applyLaxJSONPathToken(value, token, mode)
+			if err != nil {
+				return "", err
+			}
+			next = append(next, matched...)
+		}
+		current = next
+	}
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	for idx, value := range current {
+		if idx != 0 {
+			buf.WriteByte(',')
+		}
+		if err := json.Compact(&buf, value); err != nil {
+			return "", fmt.Errorf("failed to format json %q: %w", value, err)
+		}
+	}
+	buf.WriteByte(']')
+	return buf.String(), nil
+}
+
+func applyLaxJSONPathToken(value json.RawMessage, token *jsonPathToken, mode jsonPathMode) ([]json.RawMessage, error) {
+	elems, isArray := jsonArrayElements(value)
+	if token.isIndex {
+		if !isArray {
+			if token.index == 0 {
+				return []json.RawMessage{value}, nil
+			}
+			return nil, nil
+		}
+		if token.index < len(elems) {
+			return []json.RawMessage{elems[token.index]}, nil
+		}
+		return nil, nil
+	}
+	if !isArray {
+		return jsonObjectMember(value, token.member)
+	}
+	var matched []json.RawMessage
+	for _, elem := range elems {
+		if _, isNestedArray := jsonArrayElements(elem); isNestedArray {
+			// lax mode unwraps only one level of the array.
+			if mode != jsonPathModeLaxRecursive {
+				continue
+			}
+			values, err := applyLaxJSONPathToken(elem, token, mode)
+			if err != nil {
+				return nil, err
+			}
+			matched = append(matched, values...)
+			continue
+		}
+		values, err := jsonObjectMember(elem, token.member)
+		if err != nil {
+			return nil, err
+		}
+		matched = append(matched, values...)
+	}
+	return matched, nil
+}
+
+func jsonArrayElements(value json.RawMessage) ([]json.RawMessage, bool) {
+	trimmed := bytes.TrimSpace(value)
+	if len(trimmed) == 0 || trimmed[0] != '[' {
+		return nil, false
+	}
+	var elems []json.RawMessage
+	if err := json.Unmarshal(trimmed, &elems); err != nil {
+		return nil, false
+	}
+	return elems, true
+}
+
+func jsonObjectMember(value json.RawMessage, name string) ([]json.RawMessage, error) {
+	trimmed := bytes.TrimSpace(value)
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		return nil, nil
+	}
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(trimmed, &object); err != nil {
+		return nil, err
+	}
+	member, exists := object[name]
+	if !exists {
+		return nil, nil
+	}
+	return []json.RawMessage{member}, nil
+}
@@ -0,0 +1,189 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MacroFunc returns the SQL text that replaces the macro call.
+// args are the SQL texts of the arguments ( e.g. `{{ users_of('JP', 10) }}` is called with "'JP'" and "10" ).
+type MacroFunc func(args []string) (string, error)
+
+// maxMacroExpansionDepth is the maximum depth of the macro calls in the expanded SQL text.
+// It stops the expansion of the macros that call themselves.
+const maxMacroExpansionDepth = 32
+
+// RegisterMacro registers the macro called by `{{ name }}` or `{{ name(args...) }}` in the query.
+// The name of the macro is case-insensitive.
+func (a *Analyzer) RegisterMacro(name string, f MacroFunc) {
+	if a.macros == nil {
+		a.macros = map[string]MacroFunc{}
+	}
+	a.macros[strings.ToLower(name)] = f
+}
+
+// expandMacros replaces the macro calls in the query with the SQL texts returned by the macros before the query is parsed.
+// The macro calls in string literals, quoted identifiers and comments are not expanded.
+func (a *Analyzer) expandMacros(query string) (string, error) {
+	if len(a.macros) == 0 {
+		return query, nil
+	}
+	return expandMacros(query, a.macros, 0)
+}
+
+func expandMacros(query string, macros map[string]MacroFunc, depth int) (string, error) {
+	if depth > maxMacroExpansionDepth {
+		return "", fmt.Errorf("macro expansion exceeds the maximum depth %d", maxMacroExpansionDepth)
+	}
+	var b strings.Builder
+	for i := 0; i < len(query); {
+		if end := skipQuotedOrComment(query, i); end > i {
+			b.WriteString(query[i:end])
+			i = end
+			continue
+		}
+		if !strings.HasPrefix(query[i:], "{{") {
+			b.WriteByte(query[i])
+			i++
+			continue
+		}
+		end := findMacroCallEnd(query, i+2)
+		if end < 0 {
+			return "", fmt.Errorf("unterminated macro call: %s", query[i:])
+		}
+		name, args, err := parseMacroCall(query[i+2 : end])
+		if err != nil {
+			return "", err
+		}
+		macro, exists := macros[strings.ToLower(name)]
+		if !exists {
+			return "", fmt.Errorf("undefined macro: %s", name)
+		}
+		expanded, err := macro(args)
+		if err != nil {
+			return "", fmt.Errorf("failed to expand macro %s: %w", name, err)
+		}
+		expanded, err = expandMacros(expanded, macros, depth+1)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(expanded)
+		i = end + len("}}")
+	}
+	return b.String(), nil
+}
+
+// skipQuotedOrComment returns the end position of the string literal, quoted identifier or comment starting at pos.
+// If there is nothing to skip at pos, pos is returned.
+func skipQuotedOrComment(query string, pos int) int {
+	rest := query[pos:]
+	switch {
+	case strings.HasPrefix(rest, "--"), strings.HasPrefix(rest, "#"):
+		if idx := strings.IndexByte(rest, '\n'); idx >= 0 {
+			return pos + idx + 1
+		}
+		return len(query)
+	case strings.HasPrefix(rest, "/*"):
+		if idx := strings.Index(rest[2:], "*/"); idx >= 0 {
+			return pos + 2 + idx + 2
+		}
+		return len(query)
+	}
+	c := query[pos]
+	if c != '\'' && c != '"' && c != '`' {
+		return pos
+	}
+	quote := string(c)
+	if c != '`' && strings.HasPrefix(rest, strings.Repeat(quote, 3)) {
+		quote = strings.Repeat(quote, 3)
+	}
+	for i := pos + len(quote); i < len(query); i++ {
+		if query[i] == '\\' {
+			i++
+			continue
+		}
+		if strings.HasPrefix(query[i:], quote) {
+			return i + len(quote)
+		}
+	}
+	return len(query)
+}
+
+// findMacroCallEnd returns the position of `}}` that closes the macro call, or -1 if the call is not closed.
+func findMacroCallEnd(query string, pos int) int {
+	for i := pos; i < len(query); {
+		if end := skipQuotedOrComment(query, i); end > i {
+			i = end
+			continue
+		}
+		if strings.HasPrefix(query[i:], "}}") {
+			return i
+		}
+		i++
+	}
+	return -1
+}
+
+// parseMacroCall parses the content of the macro call ( e.g. `users_of('JP', 10)` ) into the name and the arguments.
+func parseMacroCall(call string) (string, []string, error) {
+	call = strings.TrimSpace(call)
+	nameEnd := strings.IndexByte(call, '(')
+	if nameEnd < 0 {
+		if !isMacroName(call) {
+			return "", nil, fmt.Errorf("invalid macro call: {{%s}}", call)
+		}
+		return call, nil, nil
+	}
+	name := strings.TrimSpace(call[:nameEnd])
+	if !isMacroName(name) || !strings.HasSuffix(call, ")") {
+		return "", nil, fmt.Errorf("invalid macro call: {{%s}}", call)
+	}
+	argsText := call[nameEnd+1 : len(call)-1]
+	if strings.TrimSpace(argsText) == "" {
+		return name, nil, nil
+	}
+	return name, splitCallArgs(argsText), nil
+}
+
+// splitCallArgs splits the SQL text of the arguments by the commas that are not nested in the parentheses or brackets.
+func splitCallArgs(argsText string) []string {
+	var (
+		args  []string
+		depth int
+		start int
+	)
+	for i := 0; i < len(argsText); {
+		if end := skipQuotedOrComment(argsText, i); end > i {
+			i = end
+			continue
+		}
+		switch argsText[i] {
+		case '(', '[':
+			depth++
+		case ')', ']':
+			depth--
+		case ',':
+			if depth == 0 {
+				args = append(args, strings.TrimSpace(argsText[start:i]))
+				start = i + 1
+			}
+		}
+		i++
+	}
+	return append(args, strings.TrimSpace(argsText[start:]))
+}
+
+func isMacroName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for idx, c := range name {
+		switch {
+		case c == '_', 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z':
+		case idx > 0 && ('0' <= c && c <= '9' || c == '.'):
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,95 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestExpandMacros(t *testing.T) {
+	macros := map[string]MacroFunc{
+		"active_users": func(args []string) (string, error) {
+			return "(SELECT * FROM users WHERE active)", nil
+		},
+		"users_of": func(args []string) (string, error) {
+			if len(args) != 2 {
+				return "", fmt.Errorf("users_of requires 2 arguments but got %d", len(args))
+			}
+			return fmt.Sprintf("(SELECT * FROM {{ active_users }} WHERE country = %s LIMIT %s)", args[0], args[1]), nil
+		},
+		"args": func(args []string) (string, error) {
+			return strings.Join(args, " | "), nil
+		},
+		"recursive": func(args []string) (string, error) {
+			return "{{ recursive }}", nil
+		},
+	}
+	for _, test := range []struct {
+		name        string
+		query       string
+		expected    string
+		expectedErr string
+	}{
+		{
+			name:     "no macro",
+			query:    "SELECT 1",
+			expected: "SELECT 1",
+		},
+		{
+			name:     "macro without arguments",
+			query:    "SELECT * FROM {{active_users}} AS u",
+			expected: "SELECT * FROM (SELECT * FROM users WHERE active) AS u",
+		},
+		{
+			name:     "nested macro with arguments",
+			query:    "SELECT * FROM {{ USERS_OF('JP', 10) }}",
+			expected: "SELECT * FROM (SELECT * FROM (SELECT * FROM users WHERE active) WHERE country = 'JP' LIMIT 10)",
+		},
+		{
+			name:     "arguments with nested commas and quotes",
+			query:    `SELECT {{ args(f(1, 2), [3, 4], 'a,b)', "}}") }}`,
+			expected: `SELECT f(1, 2) | [3, 4] | 'a,b)' | "}}"`,
+		},
+		{
+			name:     "macro in string literal and comment",
+			query:    "SELECT '{{ active_users }}', `{{x}}` -- {{ active_users }}\n/* {{ y }} */",
+			expected: "SELECT '{{ active_users }}', `{{x}}` -- {{ active_users }}\n/* {{ y }} */",
+		},
+		{
+			name:        "undefined macro",
+			query:       "SELECT * FROM {{ unknown }}",
+			expectedErr: "undefined macro: unknown",
+		},
+		{
+			name:        "unterminated macro",
+			query:       "SELECT * FROM {{ active_users",
+			expectedErr: "unterminated macro call: {{ active_users",
+		},
+		{
+			name:        "macro error",
+			query:       "SELECT * FROM {{ users_of('JP') }}",
+			expectedErr: "failed to expand macro users_of: users_of requires 2 arguments but got 1",
+		},
+		{
+			name:        "recursive macro",
+			query:       "SELECT {{ recursive }}",
+			expectedErr: "macro expansion exceeds the maximum depth 32",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			expanded, err := expandMacros(test.query, macros, 0)
+			if test.expectedErr != "" {
+				if err == nil || err.Error() != test.expectedErr {
+					t.Fatalf("expected error %q but got %v", test.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if expanded != test.expected {
+				t.Fatalf("expected %q but got %q", test.expected, expanded)
+			}
+		})
+	}
+}
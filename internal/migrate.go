@@ -0,0 +1,234 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/goccy/go-zetasql/types"
+)
+
+// valueEncodingUserVersion is set to PRAGMA user_version of the main database
+// after the values stored in the database are migrated to the binary value encoding.
+const valueEncodingUserVersion = 1
+
+// MigrateValueEncoding re-encodes the values stored by the legacy JSON based encoding with the binary value encoding.
+func (a *Analyzer) MigrateValueEncoding(ctx context.Context, conn *Conn) error {
+	if a.isReadOnlyMode {
+		return fmt.Errorf("MigrateValueEncoding is not allowed in read-only mode")
+	}
+	if err := a.catalog.Sync(ctx, conn); err != nil {
+		return fmt.Errorf("failed to sync catalog: %w", err)
+	}
+	return migrateValueEncoding(ctx, a.catalog, conn)
+}
+
+// MigrateValueEncodingOnOpen migrates the values stored by the legacy JSON based encoding when the database is opened.
+// The legacy values are decoded as they are, but they are not matched with the binary encoded values stored as TEXT
+// ( e.g. GROUP BY, DISTINCT, JOIN or IN subquery ), so the both encodings must not be mixed in a database.
+// The database is marked by PRAGMA user_version after the migration, so the tables are scanned only once.
+// The legacy values that can't be migrated ( read-only mode or the datasets attached as read-only ) are refused with an error.
+func MigrateValueEncodingOnOpen(ctx context.Context, catalog *Catalog, conn *Conn, readOnly bool) error {
+	rows, err := conn.QueryContext(ctx, "PRAGMA user_version")
+	if err != nil {
+		return fmt.Errorf("failed to get user_version: %w", err)
+	}
+	var version int64
+	if rows.Next() {
+		if err := rows.Scan(&version); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to get user_version: %w", err)
+		}
+	}
+	if err := rows.Close(); err != nil {
+		return err
+	}
+	if version >= valueEncodingUserVersion {
+		return nil
+	}
+	if err := catalog.Sync(ctx, conn); err != nil {
+		return fmt.Errorf("failed to sync catalog: %w", err)
+	}
+	if readOnly {
+		for _, spec := range catalog.tableSpecs() {
+			if err := checkTableValueEncoding(ctx, conn, spec); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return migrateValueEncoding(ctx, catalog, conn)
+}
+
+// migrateValueEncoding migrates the values of all tables in a savepoint,
+// so the interrupted migration doesn't leave the tables half-converted.
+func migrateValueEncoding(ctx context.Context, catalog *Catalog, conn *Conn) error {
+	if _, err := conn.ExecContext(ctx, "SAVEPOINT zetasqlite_migrate_value_encoding"); err != nil {
+		return fmt.Errorf("failed to begin migrating value encoding: %w", err)
+	}
+	if err := migrateValueEncodingInSavepoint(ctx, catalog, conn); err != nil {
+		_, _ = conn.ExecContext(ctx, "ROLLBACK TO zetasqlite_migrate_value_encoding")
+		_, _ = conn.ExecContext(ctx, "RELEASE zetasqlite_migrate_value_encoding")
+		return err
+	}
+	if _, err := conn.ExecContext(ctx, "RELEASE zetasqlite_migrate_value_encoding"); err != nil {
+		return fmt.Errorf("failed to finish migrating value encoding: %w", err)
+	}
+	return nil
+}
+
+func migrateValueEncodingInSavepoint(ctx context.Context, catalog *Catalog, conn *Conn) error {
+	for _, spec := range catalog.tableSpecs() {
+		if catalog.isReadOnlySchema(spec.Schema) {
+			if err := checkTableValueEncoding(ctx, conn, spec); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := migrateTableValueEncoding(ctx, conn, spec); err != nil {
+			return fmt.Errorf("failed to migrate value encoding of %s: %w", spec.TableName(), err)
+		}
+	}
+	if _, err := conn.ExecContext(ctx, fmt.Sprintf("PRAGMA user_version = %d", valueEncodingUserVersion)); err != nil {
+		return fmt.Errorf("failed to set user_version: %w", err)
+	}
+	return nil
+}
+
+// isEncodedColumnType returns whether the values of the type are stored by the value encoding.
+// INT64, BOOL and FLOAT64 values are stored as the SQLite's values as it is.
+func isEncodedColumnType(t *Type) bool {
+	switch types.TypeKind(t.Kind) {
+	case types.INT32, types.INT64, types.UINT32, types.UINT64, types.ENUM,
+		types.BOOL, types.FLOAT, types.DOUBLE:
+		return false
+	}
+	return true
+}
+
+// checkTableValueEncoding returns an error if the table has the values stored by the legacy JSON based encoding.
+func checkTableValueEncoding(ctx context.Context, conn *Conn, spec *TableSpec) error {
+	if spec.IsView || spec.IsTemp {
+		return nil
+	}
+	for _, col := range spec.Columns {
+		if !isEncodedColumnType(col.Type) {
+			continue
+		}
+		rows, err := conn.QueryContext(
+			ctx,
+			fmt.Sprintf(
+				"SELECT 1 FROM %s WHERE typeof(`%s`) = 'text' AND substr(`%s`, 1, %d) != ? LIMIT 1",
+				spec.QualifiedTableName(), col.Name, col.Name, len(binaryValuePrefix),
+			),
+			binaryValuePrefix,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to check value encoding of %s: %w", spec.TableName(), err)
+		}
+		exists := rows.Next()
+		if err := rows.Close(); err != nil {
+			return err
+		}
+		if exists {
+			return fmt.Errorf(
+				"%s has the values stored by the legacy value encoding of older zetasqlite versions. open the database without read-only mode once to migrate them",
+				strings.Join(spec.NamePath, "."),
+			)
+		}
+	}
+	return nil
+}
+
+func migrateTableValueEncoding(ctx context.Context, conn *Conn, spec *TableSpec) error {
+	if spec.IsView || spec.IsTemp {
+		return nil
+	}
+	var (
+		columns     []*ColumnSpec
+		columnTypes []types.Type
+		columnNames []string
+	)
+	for _, col := range spec.Columns {
+		if !isEncodedColumnType(col.Type) {
+			continue
+		}
+		typ, err := col.Type.ToZetaSQLType()
+		if err != nil {
+			return err
+		}
+		columns = append(columns, col)
+		columnTypes = append(columnTypes, typ)
+		columnNames = append(columnNames, fmt.Sprintf("`%s`", col.Name))
+	}
+	if len(columns) == 0 {
+		return nil
+	}
+	rows, err := conn.QueryContext(
+		ctx,
+		fmt.Sprintf("SELECT rowid, %s FROM %s", strings.Join(columnNames, ","), spec.QualifiedTableName()),
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	// collect all rows before updating them, because the connection is used by the rows until they are closed.
+	var updateArgs [][]interface{}
+	for rows.Next() {
+		var rowID int64
+		values := make([]interface{}, len(columns))
+		scanArgs := []interface{}{&rowID}
+		for i := range values {
+			scanArgs = append(scanArgs, &values[i])
+		}
+		if err := rows.Scan(scanArgs...); err != nil {
+			return err
+		}
+		var migrated bool
+		for i, v := range values {
+			encoded, ok := v.(string)
+			if !ok || strings.HasPrefix(encoded, binaryValuePrefix) {
+				continue
+			}
+			decoded, err := DecodeValue(encoded)
+			if err != nil {
+				return err
+			}
+			// values in ARRAY or STRUCT are decoded as float64 by the legacy encoding, so cast them to the column type.
+			casted, err := CastValue(columnTypes[i], decoded)
+			if err != nil {
+				return err
+			}
+			value, err := EncodeValue(casted)
+			if err != nil {
+				return err
+			}
+			values[i] = value
+			migrated = true
+		}
+		if migrated {
+			updateArgs = append(updateArgs, append(values, rowID))
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if err := rows.Close(); err != nil {
+		return err
+	}
+	if len(updateArgs) == 0 {
+		return nil
+	}
+	setColumns := make([]string, 0, len(columnNames))
+	for _, name := range columnNames {
+		setColumns = append(setColumns, fmt.Sprintf("%s = ?", name))
+	}
+	query := fmt.Sprintf("UPDATE %s SET %s WHERE rowid = ?", spec.QualifiedTableName(), strings.Join(setColumns, ","))
+	for _, args := range updateArgs {
+		if _, err := conn.ExecContext(ctx, query, args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -61,7 +61,7 @@ func (p *NamePath) mergePath(path []string) []string {
 	}
 	merged := []string{}
 	for _, basePath := range p.path {
-		if path[0] == basePath {
+		if strings.EqualFold(path[0], basePath) {
 			break
 		}
 		if maxNum > 0 && len(merged)+len(path) >= maxNum {
@@ -80,6 +80,14 @@ func formatPath(path []string) string {
 	return strings.Join(path, "_")
 }
 
+// nameKey returns the key to look up datasets, tables and functions by name.
+// Like BigQuery, the names of datasets and functions are resolved case-insensitively.
+// Table names are case-sensitive, but ZetaSQL catalog and SQLite can't hold the tables whose names differ only in case,
+// so the tables are stored by this key too and must be looked up with TableSpec.hasTableName.
+func nameKey(name string) string {
+	return strings.ToLower(name)
+}
+
 func (p *NamePath) setPath(path []string) error {
 	normalizedPath := p.normalizePath(path)
 	maxNum := p.getMaxNum(path)
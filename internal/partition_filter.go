@@ -0,0 +1,118 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+
+	ast "github.com/goccy/go-zetasql/resolved_ast"
+)
+
+// newPartitionColumns returns the names of the columns referenced by the expressions of PARTITION BY or CLUSTER BY.
+// The expressions of CREATE TABLE AS SELECT reference the columns of the query,
+// so the columns are named by the output columns of the query if outputColumns is specified.
+func newPartitionColumns(exprs []ast.ExprNode, outputColumns []*ast.OutputColumnNode) []string {
+	var (
+		columns       []string
+		columnMap     = map[string]struct{}{}
+		outputNameMap = map[int]string{}
+	)
+	for _, col := range outputColumns {
+		outputNameMap[col.Column().ColumnID()] = col.Name()
+	}
+	for _, expr := range exprs {
+		_ = ast.Walk(expr, func(n ast.Node) error {
+			ref, ok := n.(*ast.ColumnRefNode)
+			if !ok {
+				return nil
+			}
+			name := ref.Column().Name()
+			if outputName, exists := outputNameMap[ref.Column().ColumnID()]; exists {
+				name = outputName
+			}
+			if _, exists := columnMap[name]; !exists {
+				columns = append(columns, name)
+				columnMap[name] = struct{}{}
+			}
+			return nil
+		})
+	}
+	return columns
+}
+
+// partitionFilterRequiredTableSpec returns the table spec if the table requires a partition filter.
+// tableName is the name of the table registered to the ZetaSQL catalog.
+func (c *Catalog) partitionFilterRequiredTableSpec(tableName string) *TableSpec {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	spec := c.tableSpecByCatalogName(tableName)
+	if spec == nil || !spec.RequirePartitionFilter || len(spec.PartitionColumns) == 0 {
+		return nil
+	}
+	return spec
+}
+
+// checkPartitionFilter returns an error if the statement scans the table that requires a partition filter
+// without a filter ( e.g. WHERE clause ) that references the partitioning column.
+// The target tables of UPDATE and DELETE statements also require the filter in the WHERE clause like BigQuery.
+func (a *Analyzer) checkPartitionFilter(node ast.Node) error {
+	var (
+		tableScans        []*ast.TableScanNode
+		filteredColumnMap = map[int]struct{}{}
+	)
+	addFilteredColumns := func(expr ast.Node) {
+		if expr == nil {
+			return
+		}
+		_ = ast.Walk(expr, func(n ast.Node) error {
+			if ref, ok := n.(*ast.ColumnRefNode); ok {
+				filteredColumnMap[ref.Column().ColumnID()] = struct{}{}
+			}
+			return nil
+		})
+	}
+	_ = ast.Walk(node, func(n ast.Node) error {
+		switch nn := n.(type) {
+		case *ast.TableScanNode:
+			tableScans = append(tableScans, nn)
+		case *ast.FilterScanNode:
+			addFilteredColumns(nn.FilterExpr())
+		case *ast.UpdateStmtNode:
+			addFilteredColumns(nn.WhereExpr())
+		case *ast.DeleteStmtNode:
+			addFilteredColumns(nn.WhereExpr())
+		}
+		return nil
+	})
+	for _, scan := range tableScans {
+		spec := a.catalog.partitionFilterRequiredTableSpec(scan.Table().Name())
+		if spec == nil {
+			continue
+		}
+		if hasPartitionFilter(scan, spec, filteredColumnMap) {
+			continue
+		}
+		return fmt.Errorf(
+			"Cannot query over table '%s' without a filter over column(s) '%s' that can be used for partition elimination",
+			strings.Join(spec.NamePath, "."),
+			strings.Join(spec.PartitionColumns, ", "),
+		)
+	}
+	return nil
+}
+
+func hasPartitionFilter(scan *ast.TableScanNode, spec *TableSpec, filteredColumnMap map[int]struct{}) bool {
+	partitionColumnMap := map[string]struct{}{}
+	for _, name := range spec.PartitionColumns {
+		partitionColumnMap[name] = struct{}{}
+	}
+	for _, col := range scan.ColumnList() {
+		if _, exists := partitionColumnMap[col.Name()]; !exists {
+			continue
+		}
+		if _, exists := filteredColumnMap[col.ColumnID()]; exists {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+package internal
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+
+	parsed_ast "github.com/goccy/go-zetasql/ast"
+)
+
+const queryLabelSystemVariable = "query_label"
+
+// parseQueryLabel parses the value of @@query_label formatted as `key1:value1,key2:value2`.
+func parseQueryLabel(v string) (map[string]string, error) {
+	labels := map[string]string{}
+	if v == "" {
+		return labels, nil
+	}
+	for _, label := range strings.Split(v, ",") {
+		key, value, found := strings.Cut(label, ":")
+		if !found || key == "" {
+			return nil, fmt.Errorf("invalid query label %q: query label must be formatted as key:value", label)
+		}
+		labels[key] = value
+	}
+	return labels, nil
+}
+
+// mergedQueryLabels returns the labels of the query.
+// The labels specified by WithQueryLabels are merged with the labels specified by SET @@query_label,
+// and override the labels of the same keys.
+func (a *Analyzer) mergedQueryLabels(ctx context.Context) map[string]string {
+	ctxLabels := QueryLabels(ctx)
+	if len(a.queryLabels) == 0 && len(ctxLabels) == 0 {
+		return nil
+	}
+	labels := make(map[string]string, len(a.queryLabels)+len(ctxLabels))
+	for k, v := range a.queryLabels {
+		labels[k] = v
+	}
+	for k, v := range ctxLabels {
+		labels[k] = v
+	}
+	return labels
+}
+
+func (a *Analyzer) newSystemVariableAssignmentStmtAction(node *parsed_ast.SystemVariableAssignmentNode) (StmtAction, error) {
+	var names []string
+	for _, name := range node.SystemVariable().Path().Names() {
+		names = append(names, name.Name())
+	}
+	variable := strings.Join(names, ".")
+	if !strings.EqualFold(variable, queryLabelSystemVariable) {
+		return nil, fmt.Errorf("unsupported system variable @@%s", variable)
+	}
+	var value string
+	switch expr := node.Expression().(type) {
+	case *parsed_ast.StringLiteralNode:
+		value = expr.Value()
+	case *parsed_ast.NullLiteralNode:
+	default:
+		return nil, fmt.Errorf("@@%s must be assigned by a string literal", queryLabelSystemVariable)
+	}
+	labels, err := parseQueryLabel(value)
+	if err != nil {
+		return nil, err
+	}
+	return &SetQueryLabelStmtAction{analyzer: a, labels: labels}, nil
+}
+
+// SetQueryLabelStmtAction sets the labels attached to the following queries on the connection.
+type SetQueryLabelStmtAction struct {
+	analyzer *Analyzer
+	labels   map[string]string
+}
+
+func (a *SetQueryLabelStmtAction) Prepare(ctx context.Context, conn *Conn) (driver.Stmt, error) {
+	return nil, nil
+}
+
+func (a *SetQueryLabelStmtAction) ExecContext(ctx context.Context, conn *Conn) (driver.Result, error) {
+	a.analyzer.queryLabels = a.labels
+	return &Result{conn: conn}, nil
+}
+
+func (a *SetQueryLabelStmtAction) QueryContext(ctx context.Context, conn *Conn) (*Rows, error) {
+	a.analyzer.queryLabels = a.labels
+	return &Rows{conn: conn}, nil
+}
+
+func (a *SetQueryLabelStmtAction) Args() []interface{} {
+	return nil
+}
+
+func (a *SetQueryLabelStmtAction) Cleanup(ctx context.Context, conn *Conn) error {
+	return nil
+}
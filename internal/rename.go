@@ -0,0 +1,175 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// renamedNamePath returns the name path of the renamed table.
+// The new name without the dataset is resolved in the dataset of the current table like BigQuery ( e.g. ALTER TABLE dataset.t RENAME TO t2 ).
+func (a *Analyzer) renamedNamePath(oldPath, newPath []string) []string {
+	normalized := a.namePath.normalizePath(newPath)
+	if len(normalized) != 1 {
+		return a.namePath.mergePath(normalized)
+	}
+	merged := a.namePath.mergePath(oldPath)
+	return append(append([]string{}, merged[:len(merged)-1]...), normalized[0])
+}
+
+// tableReference returns the text that refers to the table in the formatted queries.
+// The table is referred to by the quoted name after FROM ( see TableScanNode.FormatSQL and WildcardTable.FormatSQL ).
+func tableReference(tableName string) string {
+	return fmt.Sprintf("FROM `%s`", tableName)
+}
+
+// renameTableReferences rewrites the references to the table in the formatted query.
+// The error is returned if the query still contains the quoted old name after rewriting,
+// because the query would refer to the old table that no longer exists.
+func renameTableReferences(query, oldName, newName string) (string, error) {
+	renamed := strings.ReplaceAll(query, tableReference(oldName), tableReference(newName))
+	if strings.Contains(renamed, fmt.Sprintf("`%s`", oldName)) {
+		return "", fmt.Errorf("failed to rewrite the references to %s", oldName)
+	}
+	return renamed, nil
+}
+
+// refersToTable reports whether the formatted query may refer to the table.
+func refersToTable(query, tableName string) bool {
+	return strings.Contains(query, fmt.Sprintf("`%s`", tableName))
+}
+
+// renameTable renames the SQLite table and the table spec.
+// The views and the functions that refer to the table are rewritten to refer to the new name.
+// All changes are made in the savepoint, so the table is never left renamed partially.
+func renameTable(ctx context.Context, conn *Conn, catalog *sessionCatalog, spec, renamed *TableSpec) error {
+	oldName := spec.TableName()
+	newName := renamed.TableName()
+	if catalog.existsTableSpec(newName) {
+		return fmt.Errorf("Already Exists: Table %s", strings.Join(renamed.NamePath, "."))
+	}
+	if err := catalog.setTableSchema(renamed); err != nil {
+		return err
+	}
+	if renamed.Schema != spec.Schema {
+		return fmt.Errorf("cannot rename table %s to %s: the table cannot be moved to the dataset stored in the other file", oldName, newName)
+	}
+	if _, err := conn.ExecContext(ctx, "SAVEPOINT zetasqlite_rename_table"); err != nil {
+		return fmt.Errorf("failed to begin renaming table: %w", err)
+	}
+	snapshot := catalog.snapshot()
+	if err := renameTableInSavepoint(ctx, conn, catalog, spec, renamed); err != nil {
+		_, _ = conn.ExecContext(ctx, "ROLLBACK TO zetasqlite_rename_table")
+		_, _ = conn.ExecContext(ctx, "RELEASE zetasqlite_rename_table")
+		if restoreErr := catalog.restore(snapshot); restoreErr != nil {
+			return fmt.Errorf("failed to restore catalog: %v: %w", restoreErr, err)
+		}
+		return err
+	}
+	if _, err := conn.ExecContext(ctx, "RELEASE zetasqlite_rename_table"); err != nil {
+		return fmt.Errorf("failed to finish renaming table: %w", err)
+	}
+	return nil
+}
+
+func renameTableInSavepoint(ctx context.Context, conn *Conn, catalog *sessionCatalog, spec, renamed *TableSpec) error {
+	oldName := spec.TableName()
+	newName := renamed.TableName()
+	if _, err := conn.ExecContext(
+		ctx,
+		fmt.Sprintf("ALTER TABLE %s RENAME TO `%s`", spec.QualifiedTableName(), newName),
+	); err != nil {
+		return fmt.Errorf("failed to rename table %s: %w", oldName, err)
+	}
+	if err := renameAutoIndexes(ctx, conn, spec, renamed); err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, view := range catalog.tableSpecs() {
+		if !view.IsView || !refersToTable(view.Query, oldName) {
+			continue
+		}
+		query, err := renameTableReferences(view.Query, oldName, newName)
+		if err != nil {
+			return fmt.Errorf("failed to update view %s: %w", view.TableName(), err)
+		}
+		// SQLite rewrites the table name in the view, but the view is recreated from the spec to keep them consistent.
+		updated := view.clone()
+		updated.Query = query
+		updated.UpdatedAt = now
+		if _, err := conn.ExecContext(ctx, fmt.Sprintf("DROP VIEW IF EXISTS %s", updated.QualifiedTableName())); err != nil {
+			return fmt.Errorf("failed to drop view %s: %w", updated.TableName(), err)
+		}
+		if _, err := conn.ExecContext(ctx, updated.SQLiteSchema()); err != nil {
+			return fmt.Errorf("failed to recreate view %s: %w", updated.TableName(), err)
+		}
+		if err := catalog.AddNewTableSpec(ctx, conn, updated); err != nil {
+			return fmt.Errorf("failed to update view spec %s: %w", updated.TableName(), err)
+		}
+		conn.updateTable(updated)
+	}
+	for _, fn := range catalog.functionSpecs() {
+		if !refersToTable(fn.Body, oldName) {
+			continue
+		}
+		body, err := renameTableReferences(fn.Body, oldName, newName)
+		if err != nil {
+			return fmt.Errorf("failed to update function %s: %w", fn.FuncName(), err)
+		}
+		updated := *fn
+		updated.Body = body
+		updated.UpdatedAt = now
+		if err := catalog.AddNewFunctionSpec(ctx, conn, &updated); err != nil {
+			return fmt.Errorf("failed to update function spec %s: %w", updated.FuncName(), err)
+		}
+	}
+	if err := catalog.DeleteTableSpec(ctx, conn, oldName); err != nil {
+		return fmt.Errorf("failed to delete table spec: %w", err)
+	}
+	if err := catalog.AddNewTableSpec(ctx, conn, renamed); err != nil {
+		return fmt.Errorf("failed to add renamed table spec: %w", err)
+	}
+	conn.deleteTable(spec)
+	conn.addTable(renamed)
+	return nil
+}
+
+// renameAutoIndexes renames the indexes created automatically for the table ( see CreateTableStmtAction.createIndexAutomatically ),
+// because the index names contain the table name and the old names would conflict with the indexes of a new table with the old name.
+// SQLite can't rename indexes, so they are dropped and recreated.
+func renameAutoIndexes(ctx context.Context, conn *Conn, spec, renamed *TableSpec) error {
+	rows, err := conn.QueryContext(
+		ctx,
+		fmt.Sprintf("SELECT name FROM %s WHERE type = 'index' AND tbl_name = ?", qualifiedName(spec.Schema, "sqlite_master")),
+		renamed.TableName(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to get indexes of table %s: %w", renamed.TableName(), err)
+	}
+	defer rows.Close()
+	indexMap := map[string]struct{}{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return err
+		}
+		indexMap[name] = struct{}{}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for _, col := range spec.Columns {
+		indexName := autoIndexName(spec, col.Name)
+		if _, exists := indexMap[indexName]; !exists {
+			continue
+		}
+		if _, err := conn.ExecContext(ctx, fmt.Sprintf("DROP INDEX %s", qualifiedName(spec.Schema, indexName))); err != nil {
+			return fmt.Errorf("failed to drop index %s: %w", indexName, err)
+		}
+		if err := createAutoIndex(ctx, conn, renamed, col.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
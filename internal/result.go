@@ -11,6 +11,11 @@ func (r *Result) ChangedCatalog() *ChangedCatalog {
 	return r.conn.cc
 }
 
+// QueryStatistics returns the statistics of the executed statements.
+func (r *Result) QueryStatistics() *QueryStatistics {
+	return newQueryStatistics(r.conn.stats)
+}
+
 func (r *Result) LastInsertId() (int64, error) {
 	if r.result == nil {
 		return 0, nil
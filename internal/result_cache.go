@@ -0,0 +1,239 @@
+package internal
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	ast "github.com/goccy/go-zetasql/resolved_ast"
+)
+
+// maxResultCacheEntries is the maximum number of the query results held by the result cache.
+// The results of the new queries are not cached after the limit is reached until the cache is invalidated.
+const maxResultCacheEntries = 1000
+
+// resultCache holds the results of the queries like the cached query results of BigQuery.
+// The results are shared by the connections of the same database.
+// Each table has the version of its rows keyed by the name of its spec ( TableSpec.TableName ), which is incremented when the rows of the table are modified,
+// and the cached result is discarded when the version of the table read by the query is changed.
+// The statements whose modified tables are unknown ( e.g. DDL or COMMIT ) discard all results.
+type resultCache struct {
+	mu sync.Mutex
+	// version is incremented by every modification.
+	// It's the version of the results that depend on the whole database ( e.g. the queries reading views ).
+	version uint64
+	// databaseVersion is incremented when the modified tables are unknown.
+	databaseVersion uint64
+	tableVersions   map[string]uint64
+	entries         map[string]*resultCacheEntry
+}
+
+type resultCacheEntry struct {
+	values   [][]interface{}
+	snapshot *resultCacheSnapshot
+}
+
+// resultCacheSnapshot is the versions of the data read by the query.
+type resultCacheSnapshot struct {
+	version         uint64
+	databaseVersion uint64
+	// tableVersions is the versions of the tables read by the query.
+	// If it's nil, the result depends on the whole database.
+	tableVersions map[string]uint64
+}
+
+func newResultCache() *resultCache {
+	return &resultCache{
+		tableVersions: map[string]uint64{},
+		entries:       map[string]*resultCacheEntry{},
+	}
+}
+
+// snapshot returns the current versions of the tables.
+// If tables is nil, the snapshot has the version of the whole database.
+func (c *resultCache) snapshot(tables []string) *resultCacheSnapshot {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	snapshot := &resultCacheSnapshot{version: c.version, databaseVersion: c.databaseVersion}
+	if tables == nil {
+		return snapshot
+	}
+	snapshot.tableVersions = make(map[string]uint64, len(tables))
+	for _, table := range tables {
+		snapshot.tableVersions[table] = c.tableVersions[table]
+	}
+	return snapshot
+}
+
+// isValid reports whether the data read at the snapshot is not modified. c.mu must be held.
+func (c *resultCache) isValid(snapshot *resultCacheSnapshot) bool {
+	if snapshot.databaseVersion != c.databaseVersion {
+		return false
+	}
+	if snapshot.tableVersions == nil {
+		return snapshot.version == c.version
+	}
+	for table, version := range snapshot.tableVersions {
+		if c.tableVersions[table] != version {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *resultCache) get(key string) ([][]interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, exists := c.entries[key]
+	if !exists {
+		return nil, false
+	}
+	if !c.isValid(entry.snapshot) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return entry.values, true
+}
+
+// add caches the values scanned at the snapshot.
+// If the data read by the query is modified while scanning, the values are not cached.
+func (c *resultCache) add(key string, snapshot *resultCacheSnapshot, values [][]interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.isValid(snapshot) || len(c.entries) >= maxResultCacheEntries {
+		return
+	}
+	c.entries[key] = &resultCacheEntry{values: values, snapshot: snapshot}
+}
+
+// invalidate discards all results, because the modified tables are unknown.
+func (c *resultCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.version++
+	c.databaseVersion++
+	c.entries = map[string]*resultCacheEntry{}
+}
+
+// invalidateTables discards the results reading the modified tables or depending on the whole database.
+func (c *resultCache) invalidateTables(tables []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.version++
+	for _, table := range tables {
+		c.tableVersions[table]++
+	}
+	for key, entry := range c.entries {
+		if !c.isValid(entry.snapshot) {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// formattedFunctionCallPattern matches the calls of the functions in the formatted query.
+var formattedFunctionCallPattern = regexp.MustCompile(`zetasqlite_(\w+)\(`)
+
+// resultCacheTables returns the tables read by the query and whether the result of the query can be cached.
+// Like BigQuery, the queries calling non-deterministic functions ( e.g. CURRENT_TIMESTAMP, RAND or NOT DETERMINISTIC user defined functions ) are not cached.
+// The tables read by views, wildcard tables, table functions and user defined functions are unknown,
+// so the returned tables are nil for the queries using them, and their results depend on the whole database.
+func resultCacheTables(ctx context.Context, catalog *Catalog, node ast.Node, formattedQuery string) ([]string, bool) {
+	// the bodies of user defined functions are expanded into the formatted query.
+	for _, match := range formattedFunctionCallPattern.FindAllStringSubmatch(formattedQuery, -1) {
+		if _, exists := nonDeterministicFuncMap[match[1]]; exists {
+			return nil, false
+		}
+	}
+	var (
+		tables            = []string{}
+		dependsOnDatabase bool
+		cacheable         = true
+	)
+	checkFunctionCall := func(call *ast.BaseFunctionCallNode) {
+		fn := call.Function()
+		if _, exists := nonDeterministicFuncMap[strings.ToLower(fn.Name())]; exists {
+			cacheable = false
+			return
+		}
+		if fn.IsZetaSQLBuiltin() {
+			return
+		}
+		name, err := getFuncName(ctx, call)
+		if err != nil {
+			dependsOnDatabase = true
+			return
+		}
+		spec, exists := funcMapFromContext(ctx)[nameKey(name)]
+		if !exists {
+			return
+		}
+		if spec.Determinism == DeterminismNotDeterministic {
+			cacheable = false
+		}
+		dependsOnDatabase = true
+	}
+	_ = ast.Walk(node, func(n ast.Node) error {
+		switch n := n.(type) {
+		case *ast.TableScanNode:
+			if _, ok := n.Table().(*WildcardTable); ok {
+				dependsOnDatabase = true
+				return nil
+			}
+			name, err := getTableName(ctx, n)
+			if err != nil {
+				dependsOnDatabase = true
+				return nil
+			}
+			spec := catalog.tableSpec(name)
+			if spec == nil || spec.IsView {
+				dependsOnDatabase = true
+				return nil
+			}
+			tables = append(tables, spec.TableName())
+		case *ast.TVFScanNode:
+			dependsOnDatabase = true
+		case *ast.FunctionCallNode:
+			checkFunctionCall(n.BaseFunctionCallNode)
+		case *ast.AggregateFunctionCallNode:
+			checkFunctionCall(n.BaseFunctionCallNode)
+		case *ast.AnalyticFunctionCallNode:
+			checkFunctionCall(n.BaseFunctionCallNode)
+		}
+		return nil
+	})
+	if !cacheable {
+		return nil, false
+	}
+	if dependsOnDatabase {
+		return nil, true
+	}
+	return tables, true
+}
+
+func resultCacheKey(formattedQuery string, args []interface{}) string {
+	return fmt.Sprintf("%s\x00%#v", formattedQuery, args)
+}
+
+// scanAllValues reads all rows as the values stored in SQLite.
+func scanAllValues(rows *sql.Rows, columnNum int) ([][]interface{}, error) {
+	defer rows.Close()
+	ret := [][]interface{}{}
+	for rows.Next() {
+		values := make([]interface{}, columnNum)
+		ptrs := make([]interface{}, columnNum)
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+		ret = append(ret, values)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
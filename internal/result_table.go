@@ -0,0 +1,155 @@
+package internal
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+var resultTableID uint64
+
+// ResultTable is the query result saved to the temporary table of SQLite to read it page by page.
+// The rows are saved with the encoded values and the rowid that follows the order of the query result,
+// so the pages are always read in the same order.
+type ResultTable struct {
+	name      string
+	columns   []*ColumnSpec
+	totalRows int64
+}
+
+// SaveResultTable reads all rows and saves them to the new temporary table.
+// The rows are closed after saving.
+func SaveResultTable(ctx context.Context, conn *Conn, rows *Rows) (_ *ResultTable, e error) {
+	defer func() {
+		if err := rows.Close(); err != nil && e == nil {
+			e = err
+		}
+	}()
+
+	table := &ResultTable{
+		name:    fmt.Sprintf("zetasqlite_result_%d", atomic.AddUint64(&resultTableID, 1)),
+		columns: rows.columns,
+	}
+	columns := make([]string, 0, len(table.columns))
+	placeholders := make([]string, 0, len(table.columns))
+	for idx := range table.columns {
+		columns = append(columns, table.columnName(idx))
+		placeholders = append(placeholders, "?")
+	}
+	if _, err := conn.ExecContext(
+		ctx,
+		fmt.Sprintf("CREATE TEMP TABLE `%s` (%s)", table.name, strings.Join(columns, ",")),
+	); err != nil {
+		return nil, fmt.Errorf("failed to create result table: %w", err)
+	}
+	if rows.rows == nil && rows.cachedValues == nil {
+		return table, nil
+	}
+	stmt, err := conn.PrepareContext(
+		ctx,
+		fmt.Sprintf("INSERT INTO `%s` (%s) VALUES (%s)", table.name, strings.Join(columns, ","), strings.Join(placeholders, ",")),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare result table: %w", err)
+	}
+	defer stmt.Close()
+
+	for {
+		values, err := rows.nextSources()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+		// the values are saved as they are encoded, so they are decoded the same way as the original rows.
+		if _, err := stmt.ExecContext(ctx, values...); err != nil {
+			return nil, fmt.Errorf("failed to save result row: %w", err)
+		}
+		table.totalRows++
+	}
+	return table, nil
+}
+
+// Columns returns the column specifications of the result.
+func (t *ResultTable) Columns() []*ColumnSpec {
+	return t.columns
+}
+
+// TotalRows returns the number of rows of the result.
+func (t *ResultTable) TotalRows() int64 {
+	return t.totalRows
+}
+
+// Page returns the rows of the page specified by pageToken and the token of the next page.
+// The empty token means the first page, and the next token is empty if the page is the last page.
+func (t *ResultTable) Page(ctx context.Context, conn *Conn, pageToken string, maxResults int) (*Rows, string, error) {
+	if maxResults <= 0 {
+		return nil, "", fmt.Errorf("maxResults must be positive but specified %d", maxResults)
+	}
+	offset, err := t.decodePageToken(pageToken)
+	if err != nil {
+		return nil, "", err
+	}
+	columns := make([]string, 0, len(t.columns))
+	for idx := range t.columns {
+		columns = append(columns, t.columnName(idx))
+	}
+	rows, err := conn.QueryContext(
+		ctx,
+		fmt.Sprintf(
+			"SELECT %s FROM `%s` WHERE rowid > ? ORDER BY rowid LIMIT ?",
+			strings.Join(columns, ","), t.name,
+		),
+		offset, maxResults,
+	)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read result page: %w", err)
+	}
+	var nextPageToken string
+	if next := offset + int64(maxResults); next < t.totalRows {
+		nextPageToken = t.encodePageToken(next)
+	}
+	return &Rows{conn: conn, rows: rows, columns: t.columns}, nextPageToken, nil
+}
+
+// Drop removes the temporary table.
+func (t *ResultTable) Drop(ctx context.Context, conn *Conn) error {
+	if _, err := conn.ExecContext(ctx, fmt.Sprintf("DROP TABLE IF EXISTS `%s`", t.name)); err != nil {
+		return fmt.Errorf("failed to drop result table: %w", err)
+	}
+	return nil
+}
+
+func (t *ResultTable) columnName(idx int) string {
+	return fmt.Sprintf("`c%d`", idx)
+}
+
+// encodePageToken encodes the table name and the number of rows read by the previous pages.
+// The table name is included to reject the token issued for the other results.
+func (t *ResultTable) encodePageToken(offset int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%d", t.name, offset)))
+}
+
+func (t *ResultTable) decodePageToken(pageToken string) (int64, error) {
+	if pageToken == "" {
+		return 0, nil
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(pageToken)
+	if err != nil {
+		return 0, fmt.Errorf("invalid page token %q", pageToken)
+	}
+	name, offset, found := strings.Cut(string(decoded), ":")
+	if !found || name != t.name {
+		return 0, fmt.Errorf("invalid page token %q", pageToken)
+	}
+	v, err := strconv.ParseInt(offset, 10, 64)
+	if err != nil || v < 0 {
+		return 0, fmt.Errorf("invalid page token %q", pageToken)
+	}
+	return v, nil
+}
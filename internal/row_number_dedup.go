@@ -0,0 +1,157 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	ast "github.com/goccy/go-zetasql/resolved_ast"
+	"github.com/goccy/go-zetasql/types"
+)
+
+// rowNumberDeduplication is the deduplication pattern QUALIFY ROW_NUMBER() OVER (PARTITION BY k ORDER BY v DESC) = 1.
+type rowNumberDeduplication struct {
+	rowNumberColumn *ast.Column
+	partitionBy     []*ast.ColumnRefNode
+	orderBy         *ast.OrderByItemNode
+}
+
+// newRowNumberDeduplication returns the deduplication pattern if the filter over the analytic scan keeps only the first row of each partition.
+// The window function runs the correlated subquery over the whole input for each row,
+// but the first row of each partition can be selected by the group-wise MAX or MIN in a single aggregation.
+// The pattern is rewritten only if the analytic scan has just ROW_NUMBER partitioned by the columns and ordered by a single column,
+// and the NULL values of the order column are sorted last, because MAX and MIN ignore NULL values.
+// If the filter cannot be rewritten, returns nil.
+func newRowNumberDeduplication(ctx context.Context, scan *ast.AnalyticScanNode, filter ast.ExprNode) *rowNumberDeduplication {
+	analyzer := analyzerFromContext(ctx)
+	if analyzer == nil || analyzer.isRowNumberDeduplicationDisabled {
+		return nil
+	}
+	groups := scan.FunctionGroupList()
+	if len(groups) != 1 {
+		return nil
+	}
+	group := groups[0]
+	if group.PartitionBy() == nil || len(group.PartitionBy().PartitionByList()) == 0 {
+		return nil
+	}
+	if group.OrderBy() == nil || len(group.OrderBy().OrderByItemList()) != 1 {
+		return nil
+	}
+	orderBy := group.OrderBy().OrderByItemList()[0]
+	if orderBy.CollationName() != nil {
+		return nil
+	}
+	switch orderBy.NullOrder() {
+	case ast.NullOrderModeNullsFirst:
+		return nil
+	case ast.NullOrderModeOrderUnspecified:
+		// NULL values are sorted first in ascending order.
+		if !orderBy.IsDescending() {
+			return nil
+		}
+	}
+	funcs := group.AnalyticFunctionList()
+	if len(funcs) != 1 {
+		return nil
+	}
+	call, ok := funcs[0].Expr().(*ast.AnalyticFunctionCallNode)
+	if !ok || call.Function().FullName(false) != "row_number" || call.WindowFrame() != nil {
+		return nil
+	}
+	rowNumberColumn := funcs[0].Column()
+	if !isFirstRowNumberFilter(rowNumberColumn, filter) {
+		return nil
+	}
+	return &rowNumberDeduplication{
+		rowNumberColumn: rowNumberColumn,
+		partitionBy:     group.PartitionBy().PartitionByList(),
+		orderBy:         orderBy,
+	}
+}
+
+// isFirstRowNumberFilter reports whether the filter is `rn = 1` or `rn <= 1` for the row number column.
+func isFirstRowNumberFilter(rowNumberColumn *ast.Column, filter ast.ExprNode) bool {
+	fn, ok := filter.(*ast.FunctionCallNode)
+	if !ok {
+		return false
+	}
+	args := fn.ArgumentList()
+	if len(args) != 2 {
+		return false
+	}
+	var ref, value ast.ExprNode
+	switch fn.Function().FullName(false) {
+	case "$equal":
+		ref, value = args[0], args[1]
+		if _, ok := value.(*ast.ColumnRefNode); ok {
+			ref, value = value, ref
+		}
+	case "$less_or_equal":
+		ref, value = args[0], args[1]
+	case "$greater_or_equal":
+		ref, value = args[1], args[0]
+	default:
+		return false
+	}
+	colRef, ok := ref.(*ast.ColumnRefNode)
+	if !ok || colRef.Column().ColumnID() != rowNumberColumn.ColumnID() {
+		return false
+	}
+	lit, ok := value.(*ast.LiteralNode)
+	if !ok || lit.Value().IsNull() || lit.Value().Type().Kind() != types.INT64 {
+		return false
+	}
+	return lit.Value().Int64Value() == 1
+}
+
+// formatRowNumberDeduplication formats the analytic scan filtered by the first row number as the group-wise MAX or MIN.
+// SQLite takes the values of the other columns from the row that has the maximum or minimum value,
+// so QUALIFY ROW_NUMBER() OVER (PARTITION BY k ORDER BY v DESC) = 1 is formatted to
+// SELECT ... FROM (SELECT *, MAX(v COLLATE zetasqlite_collate) FROM input GROUP BY zetasqlite_group_by(k)).
+// The row number column is always 1.
+func formatRowNumberDeduplication(ctx context.Context, scan *ast.AnalyticScanNode, dedup *rowNumberDeduplication) (string, error) {
+	input, err := newNode(scan.InputScan()).FormatSQL(ctx)
+	if err != nil {
+		return "", err
+	}
+	formattedInput, err := formatInput(input)
+	if err != nil {
+		return "", err
+	}
+	rowNumber, err := LiteralFromValue(IntValue(1))
+	if err != nil {
+		return "", err
+	}
+	columns := []string{}
+	columnMap := columnRefMap(ctx)
+	for _, col := range scan.ColumnList() {
+		colName := uniqueColumnName(ctx, col)
+		if col.ColumnID() == dedup.rowNumberColumn.ColumnID() {
+			columns = append(columns, fmt.Sprintf("%s AS `%s`", rowNumber, colName))
+			continue
+		}
+		if ref, exists := columnMap[colName]; exists {
+			columns = append(columns, ref)
+			delete(columnMap, colName)
+		} else {
+			columns = append(columns, fmt.Sprintf("`%s`", colName))
+		}
+	}
+	groupBy := make([]string, 0, len(dedup.partitionBy))
+	for _, ref := range dedup.partitionBy {
+		groupBy = append(groupBy, fmt.Sprintf("zetasqlite_group_by(`%s`)", uniqueColumnName(ctx, ref.Column())))
+	}
+	aggregate := "MIN"
+	if dedup.orderBy.IsDescending() {
+		aggregate = "MAX"
+	}
+	return fmt.Sprintf(
+		"SELECT %s FROM (SELECT *, %s(`%s` COLLATE zetasqlite_collate) AS `zetasqlite_dedup_order` %s GROUP BY %s)",
+		strings.Join(columns, ","),
+		aggregate,
+		uniqueColumnName(ctx, dedup.orderBy.ColumnRef().Column()),
+		formattedInput,
+		strings.Join(groupBy, ","),
+	), nil
+}
@@ -18,16 +18,31 @@ type Rows struct {
 	conn    *Conn
 	columns []*ColumnSpec
 	actions []StmtAction
+
+	// cachedValues is the values of all rows read in advance ( e.g. from the result cache ).
+	// If it isn't nil, rows is not used.
+	cachedValues [][]interface{}
+	cachedIdx    int
 }
 
 func (r *Rows) ChangedCatalog() *ChangedCatalog {
 	return r.conn.cc
 }
 
+// QueryStatistics returns the statistics of the executed statements.
+func (r *Rows) QueryStatistics() *QueryStatistics {
+	return newQueryStatistics(r.conn.stats)
+}
+
 func (r *Rows) SetActions(actions []StmtAction) {
 	r.actions = actions
 }
 
+// OutputColumns returns the column specifications of the result set.
+func (r *Rows) OutputColumns() []*ColumnSpec {
+	return r.columns
+}
+
 func (r *Rows) Columns() []string {
 	colNames := make([]string, 0, len(r.columns))
 	for _, col := range r.columns {
@@ -67,50 +82,67 @@ func (r *Rows) columnTypes() []*Type {
 }
 
 func (r *Rows) Next(dest []driver.Value) error {
-	if r.rows == nil {
-		return io.EOF
+	srcs, err := r.nextSources()
+	if err != nil {
+		return err
 	}
-	if !r.rows.Next() {
-		if err := r.rows.Err(); err != nil {
+	destV := reflect.ValueOf(dest)
+	for idx, colType := range r.columnTypes() {
+		if err := r.assignValue(srcs[idx], destV.Index(idx), colType); err != nil {
 			return err
 		}
-		return io.EOF
 	}
-	if err := r.rows.Err(); err != nil {
-		return err
+	return nil
+}
+
+// nextSources reads the values of the next row as they are stored in SQLite.
+func (r *Rows) nextSources() ([]interface{}, error) {
+	if r.cachedValues != nil {
+		if r.cachedIdx >= len(r.cachedValues) {
+			return nil, io.EOF
+		}
+		srcs := r.cachedValues[r.cachedIdx]
+		r.cachedIdx++
+		return srcs, nil
 	}
-	colTypes := r.columnTypes()
-	values := make([]interface{}, 0, len(dest))
-	for i := 0; i < len(dest); i++ {
-		var v interface{}
-		values = append(values, &v)
+	if r.rows == nil {
+		return nil, io.EOF
 	}
-	retErr := r.rows.Scan(values...)
-	destV := reflect.ValueOf(dest)
-	for idx, colType := range colTypes {
-		src := reflect.ValueOf(values[idx]).Elem().Interface()
-		dst := destV.Index(idx)
-		if err := r.assignValue(src, dst, colType); err != nil {
-			return err
+	if !r.rows.Next() {
+		if err := r.rows.Err(); err != nil {
+			return nil, err
 		}
+		return nil, io.EOF
+	}
+	srcs := make([]interface{}, len(r.columns))
+	ptrs := make([]interface{}, 0, len(srcs))
+	for idx := range srcs {
+		ptrs = append(ptrs, &srcs[idx])
 	}
-	return retErr
+	if err := r.rows.Scan(ptrs...); err != nil {
+		return nil, err
+	}
+	return srcs, nil
 }
 
-func (r *Rows) assignValue(src interface{}, dst reflect.Value, typ *Type) error {
-	if src == nil {
-		dst.Set(reflect.New(dst.Type()).Elem())
-		return nil
-	}
+func (r *Rows) decodeValue(src interface{}, typ *Type) (Value, error) {
 	decodedValue, err := DecodeValue(src)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	t, err := typ.ToZetaSQLType()
 	if err != nil {
-		return err
+		return nil, err
+	}
+	return CastValue(t, decodedValue)
+}
+
+func (r *Rows) assignValue(src interface{}, dst reflect.Value, typ *Type) error {
+	if src == nil {
+		dst.Set(reflect.New(dst.Type()).Elem())
+		return nil
 	}
-	value, err := CastValue(t, decodedValue)
+	value, err := r.decodeValue(src, typ)
 	if err != nil {
 		return err
 	}
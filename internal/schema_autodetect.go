@@ -0,0 +1,356 @@
+package internal
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/goccy/go-json"
+	"github.com/goccy/go-zetasql/types"
+)
+
+// DefaultSchemaAutodetectSampleRows is the default number of rows inspected to detect the schema.
+const DefaultSchemaAutodetectSampleRows = 500
+
+var (
+	autodetectDatePattern      = regexp.MustCompile(`^\d{4}-\d{1,2}-\d{1,2}$`)
+	autodetectTimePattern      = regexp.MustCompile(`^\d{1,2}:\d{1,2}:\d{1,2}(\.\d{1,6})?$`)
+	autodetectTimestampPattern = regexp.MustCompile(`^\d{4}-\d{1,2}-\d{1,2}[ T]\d{1,2}:\d{1,2}:\d{1,2}(\.\d{1,6})?( ?(UTC|Z|[+-]\d{1,2}(:\d{2})?))?$`)
+	autodetectInvalidNameChar  = regexp.MustCompile(`[^A-Za-z0-9_]`)
+)
+
+// autodetectKind is the type of the field detected from the values.
+// The kinds are ordered so that the wider kind can hold the narrower kind ( e.g. INT64 values are FLOAT64 values ).
+type autodetectKind int
+
+const (
+	autodetectUnknown autodetectKind = iota
+	autodetectBool
+	autodetectInt64
+	autodetectFloat64
+	autodetectDate
+	autodetectTime
+	autodetectTimestamp
+	autodetectString
+	autodetectRecord
+)
+
+// autodetectField is the field detected from the values, which keeps the order of the nested fields as they appear.
+type autodetectField struct {
+	name     string
+	kind     autodetectKind
+	repeated bool
+	fields   []*autodetectField
+}
+
+func (f *autodetectField) field(name string) *autodetectField {
+	for _, field := range f.fields {
+		if strings.EqualFold(field.name, name) {
+			return field
+		}
+	}
+	field := &autodetectField{name: name}
+	f.fields = append(f.fields, field)
+	return field
+}
+
+// mergeKind merges the kind detected from the value into the field.
+// INT64 and FLOAT64 are merged into FLOAT64, DATE and TIMESTAMP are merged into TIMESTAMP,
+// and other different scalar kinds are merged into STRING like BigQuery.
+func (f *autodetectField) mergeKind(kind autodetectKind) error {
+	switch {
+	case kind == autodetectUnknown || f.kind == kind:
+		return nil
+	case f.kind == autodetectUnknown:
+		f.kind = kind
+	case f.kind == autodetectRecord || kind == autodetectRecord:
+		return fmt.Errorf("field %s has both RECORD and non-RECORD values", f.name)
+	case isAutodetectKindPair(f.kind, kind, autodetectInt64, autodetectFloat64):
+		f.kind = autodetectFloat64
+	case isAutodetectKindPair(f.kind, kind, autodetectDate, autodetectTimestamp):
+		f.kind = autodetectTimestamp
+	default:
+		f.kind = autodetectString
+	}
+	return nil
+}
+
+func isAutodetectKindPair(a, b, x, y autodetectKind) bool {
+	return (a == x && b == y) || (a == y && b == x)
+}
+
+func (f *autodetectField) setRepeated(repeated bool, hasValue bool) error {
+	if f.kind == autodetectUnknown && !f.repeated {
+		f.repeated = repeated
+		return nil
+	}
+	if hasValue && f.repeated != repeated {
+		return fmt.Errorf("field %s has both REPEATED and non-REPEATED values", f.name)
+	}
+	return nil
+}
+
+// mergeJSONValue merges the type of the decoded JSON value into the field.
+func (f *autodetectField) mergeJSONValue(v interface{}) error {
+	switch vv := v.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		if err := f.setRepeated(true, true); err != nil {
+			return err
+		}
+		for _, elem := range vv {
+			if _, isArray := elem.([]interface{}); isArray {
+				return fmt.Errorf("field %s has the array of arrays that is not supported by BigQuery", f.name)
+			}
+			if err := f.mergeScalarOrRecord(elem); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := f.setRepeated(false, true); err != nil {
+		return err
+	}
+	return f.mergeScalarOrRecord(v)
+}
+
+func (f *autodetectField) mergeScalarOrRecord(v interface{}) error {
+	switch vv := v.(type) {
+	case nil:
+		return nil
+	case map[string]interface{}:
+		if err := f.mergeKind(autodetectRecord); err != nil {
+			return err
+		}
+		for _, key := range sortedJSONObjectKeys(vv) {
+			if err := f.field(key).mergeJSONValue(vv[key]); err != nil {
+				return err
+			}
+		}
+		return nil
+	case bool:
+		return f.mergeKind(autodetectBool)
+	case json.Number:
+		if _, err := vv.Int64(); err == nil {
+			return f.mergeKind(autodetectInt64)
+		}
+		return f.mergeKind(autodetectFloat64)
+	case string:
+		return f.mergeKind(detectStringKind(vv, false))
+	}
+	return fmt.Errorf("unexpected JSON value %T", v)
+}
+
+// detectStringKind detects the kind of the string value.
+// The boolean and the number are detected only for CSV values, because JSON has the literals of them.
+func detectStringKind(v string, csvValue bool) autodetectKind {
+	trimmed := strings.TrimSpace(v)
+	if csvValue {
+		if trimmed == "" {
+			return autodetectUnknown
+		}
+		if strings.EqualFold(trimmed, "true") || strings.EqualFold(trimmed, "false") {
+			return autodetectBool
+		}
+		if _, err := strconv.ParseInt(trimmed, 10, 64); err == nil {
+			return autodetectInt64
+		}
+		if _, err := strconv.ParseFloat(trimmed, 64); err == nil {
+			return autodetectFloat64
+		}
+	}
+	switch {
+	case autodetectDatePattern.MatchString(trimmed):
+		return autodetectDate
+	case autodetectTimePattern.MatchString(trimmed):
+		return autodetectTime
+	case autodetectTimestampPattern.MatchString(trimmed):
+		return autodetectTimestamp
+	}
+	return autodetectString
+}
+
+func (f *autodetectField) zetasqlType() (types.Type, error) {
+	var typ types.Type
+	switch f.kind {
+	case autodetectBool:
+		typ = types.BoolType()
+	case autodetectInt64:
+		typ = types.Int64Type()
+	case autodetectFloat64:
+		typ = types.DoubleType()
+	case autodetectDate:
+		typ = types.DateType()
+	case autodetectTime:
+		typ = types.TimeType()
+	case autodetectTimestamp:
+		typ = types.TimestampType()
+	case autodetectRecord:
+		fields := make([]*types.StructField, 0, len(f.fields))
+		for _, field := range f.fields {
+			fieldType, err := field.zetasqlType()
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, types.NewStructField(field.name, fieldType))
+		}
+		structType, err := types.NewStructType(fields)
+		if err != nil {
+			return nil, err
+		}
+		typ = structType
+	default:
+		// the field that has only NULL values is detected as STRING like BigQuery.
+		typ = types.StringType()
+	}
+	if !f.repeated {
+		return typ, nil
+	}
+	arrayType, err := types.NewArrayType(typ)
+	if err != nil {
+		return nil, err
+	}
+	return arrayType, nil
+}
+
+func (f *autodetectField) columnSpecs() ([]*ColumnSpec, error) {
+	columns := make([]*ColumnSpec, 0, len(f.fields))
+	for _, field := range f.fields {
+		typ, err := field.zetasqlType()
+		if err != nil {
+			return nil, fmt.Errorf("failed to detect type of %s: %w", field.name, err)
+		}
+		columns = append(columns, &ColumnSpec{Name: field.name, Type: newType(typ)})
+	}
+	return columns, nil
+}
+
+// sortedJSONObjectKeys returns the keys of the object.
+// The order of the keys in the JSON text is lost by decoding, so the keys are sorted to produce the stable schema.
+func sortedJSONObjectKeys(v map[string]interface{}) []string {
+	keys := make([]string, 0, len(v))
+	for key := range v {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// DetectJSONSchema detects the schema of newline delimited JSON from the first sampleRows rows like the schema auto-detection of BigQuery.
+// Objects are detected as RECORD and arrays are detected as REPEATED fields.
+// The fields are ordered by the first appearance, and the fields of the same object are sorted by name.
+func DetectJSONSchema(r io.Reader, sampleRows int) ([]*ColumnSpec, error) {
+	if sampleRows <= 0 {
+		sampleRows = DefaultSchemaAutodetectSampleRows
+	}
+	root := &autodetectField{kind: autodetectRecord}
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+	for i := 0; i < sampleRows; i++ {
+		var row map[string]interface{}
+		if err := decoder.Decode(&row); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("failed to decode JSON row %d: %w", i+1, err)
+		}
+		if err := root.mergeScalarOrRecord(row); err != nil {
+			return nil, fmt.Errorf("failed to detect schema from JSON row %d: %w", i+1, err)
+		}
+	}
+	return root.columnSpecs()
+}
+
+// DetectCSVSchema detects the schema of CSV from the first sampleRows rows like the schema auto-detection of BigQuery.
+// The first row is used as the header if all of its values are STRING and any other row has the non-STRING value in the same column.
+// Otherwise the columns are named by the type and the position ( e.g. int64_field_0 ).
+func DetectCSVSchema(r io.Reader, sampleRows int) ([]*ColumnSpec, error) {
+	if sampleRows <= 0 {
+		sampleRows = DefaultSchemaAutodetectSampleRows
+	}
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	var records [][]string
+	// read the header candidate in addition to the sample rows.
+	for i := 0; i <= sampleRows; i++ {
+		record, err := reader.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("failed to read CSV row %d: %w", i+1, err)
+		}
+		records = append(records, record)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("failed to detect schema from empty CSV")
+	}
+	numColumns := 0
+	for _, record := range records {
+		if len(record) > numColumns {
+			numColumns = len(record)
+		}
+	}
+	detect := func(records [][]string) []*autodetectField {
+		fields := make([]*autodetectField, numColumns)
+		for idx := range fields {
+			fields[idx] = &autodetectField{}
+		}
+		for _, record := range records {
+			for idx, v := range record {
+				// the kinds of the scalar values are always merged without error.
+				_ = fields[idx].mergeKind(detectStringKind(v, true))
+			}
+		}
+		return fields
+	}
+	header := records[0]
+	fields := detect(records[1:])
+	hasHeader := len(records) > 1 && isCSVHeader(header, fields)
+	if !hasHeader {
+		fields = detect(records)
+	}
+	columns := make([]*ColumnSpec, 0, numColumns)
+	for idx, field := range fields {
+		typ, err := field.zetasqlType()
+		if err != nil {
+			return nil, err
+		}
+		var name string
+		if hasHeader && idx < len(header) && strings.TrimSpace(header[idx]) != "" {
+			name = sanitizeColumnName(header[idx])
+		} else {
+			name = fmt.Sprintf("%s_field_%d", strings.ToLower(typ.TypeName(types.ProductInternal)), idx)
+		}
+		columns = append(columns, &ColumnSpec{Name: name, Type: newType(typ)})
+	}
+	return columns, nil
+}
+
+func isCSVHeader(header []string, fields []*autodetectField) bool {
+	var hasNonStringColumn bool
+	for idx, v := range header {
+		if detectStringKind(v, true) != autodetectString {
+			return false
+		}
+		if idx < len(fields) && fields[idx].kind != autodetectString && fields[idx].kind != autodetectUnknown {
+			hasNonStringColumn = true
+		}
+	}
+	return hasNonStringColumn
+}
+
+// sanitizeColumnName replaces the characters that can't be used for the column name by underscore.
+func sanitizeColumnName(name string) string {
+	sanitized := autodetectInvalidNameChar.ReplaceAllString(strings.TrimSpace(name), "_")
+	if sanitized != "" && sanitized[0] >= '0' && sanitized[0] <= '9' {
+		sanitized = "_" + sanitized
+	}
+	return sanitized
+}
@@ -0,0 +1,163 @@
+package internal
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/goccy/go-zetasql/types"
+)
+
+// sessionCatalog is the catalog used by the analyzer of a connection.
+// Catalog is shared by all connections opened by the same DSN,
+// so TEMP functions are registered to the session catalog to make them invisible from the other connections.
+// The TEMP functions are removed at the end of the script or when the connection is closed.
+type sessionCatalog struct {
+	*Catalog
+	mu            sync.Mutex
+	tempCatalog   *types.SimpleCatalog
+	tempFunctions []*FunctionSpec
+	tempFuncMap   map[string]*FunctionSpec
+}
+
+func newSessionCatalog(catalog *Catalog) *sessionCatalog {
+	return &sessionCatalog{
+		Catalog:     catalog,
+		tempCatalog: types.NewSimpleCatalog(catalogName),
+		tempFuncMap: map[string]*FunctionSpec{},
+	}
+}
+
+func (c *sessionCatalog) FindFunction(path []string) (*types.Function, error) {
+	c.mu.Lock()
+	fn, _ := c.tempCatalog.FindFunction(path)
+	c.mu.Unlock()
+	if fn != nil {
+		return fn, nil
+	}
+	return c.Catalog.FindFunction(path)
+}
+
+func (c *sessionCatalog) AddNewFunctionSpec(ctx context.Context, conn *Conn, spec *FunctionSpec) error {
+	if !spec.IsTemp {
+		return c.Catalog.AddNewFunctionSpec(ctx, conn, spec)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	functions := make([]*FunctionSpec, 0, len(c.tempFunctions)+1)
+	for _, fn := range c.tempFunctions {
+		if nameKey(fn.FuncName()) == nameKey(spec.FuncName()) {
+			continue
+		}
+		functions = append(functions, fn)
+	}
+	return c.resetTempFunctions(append(functions, spec))
+}
+
+func (c *sessionCatalog) DeleteFunctionSpec(ctx context.Context, conn *Conn, name string) error {
+	c.mu.Lock()
+	if _, exists := c.tempFuncMap[nameKey(name)]; !exists {
+		c.mu.Unlock()
+		return c.Catalog.DeleteFunctionSpec(ctx, conn, name)
+	}
+	defer c.mu.Unlock()
+
+	functions := make([]*FunctionSpec, 0, len(c.tempFunctions))
+	for _, fn := range c.tempFunctions {
+		if nameKey(fn.FuncName()) == nameKey(name) {
+			continue
+		}
+		functions = append(functions, fn)
+	}
+	return c.resetTempFunctions(functions)
+}
+
+func (c *sessionCatalog) existsFunctionSpec(name string) bool {
+	c.mu.Lock()
+	_, exists := c.tempFuncMap[nameKey(name)]
+	c.mu.Unlock()
+	if exists {
+		return true
+	}
+	return c.Catalog.existsFunctionSpec(name)
+}
+
+// getFunctions returns the functions that can be called from the session.
+// TEMP functions are placed at the end to take precedence over the functions of the same name.
+func (c *sessionCatalog) getFunctions(namePath *NamePath) []*FunctionSpec {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append(c.Catalog.getFunctions(namePath), c.tempFunctions...)
+}
+
+func (c *sessionCatalog) functionSpecs() []*FunctionSpec {
+	specs := c.Catalog.functionSpecs()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	specs = append(specs, c.tempFunctions...)
+	sort.SliceStable(specs, func(i, j int) bool {
+		return specs[i].FuncName() < specs[j].FuncName()
+	})
+	return specs
+}
+
+func (c *sessionCatalog) functionInfos() ([]*FunctionInfo, error) {
+	infos, err := c.Catalog.functionInfos()
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, spec := range c.tempFunctions {
+		infos = append(infos, newUserDefinedFunctionInfo(spec))
+	}
+	sort.SliceStable(infos, func(i, j int) bool {
+		return infos[i].Name < infos[j].Name
+	})
+	return infos, nil
+}
+
+func (c *sessionCatalog) snapshot() *catalogSnapshot {
+	snapshot := c.Catalog.snapshot()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	snapshot.tempFunctions = append([]*FunctionSpec{}, c.tempFunctions...)
+	return snapshot
+}
+
+func (c *sessionCatalog) restore(snapshot *catalogSnapshot) error {
+	if err := c.Catalog.restore(snapshot); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.resetTempFunctions(snapshot.tempFunctions)
+}
+
+// close removes all TEMP functions of the session.
+func (c *sessionCatalog) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_ = c.resetTempFunctions(nil)
+}
+
+func (c *sessionCatalog) resetTempFunctions(functions []*FunctionSpec) error {
+	c.tempCatalog = types.NewSimpleCatalog(catalogName)
+	c.tempFunctions = []*FunctionSpec{}
+	c.tempFuncMap = map[string]*FunctionSpec{}
+	for _, spec := range functions {
+		if err := c.Catalog.addFunctionSpecRecursive(c.tempCatalog, spec); err != nil {
+			return err
+		}
+		c.tempFunctions = append(c.tempFunctions, spec)
+		c.tempFuncMap[nameKey(spec.FuncName())] = spec
+	}
+	return nil
+}
@@ -18,31 +18,51 @@ type NameWithType struct {
 }
 
 func (t *NameWithType) FunctionArgumentType() (*types.FunctionArgumentType, error) {
+	// set the argument name to both fixed and templated arguments
+	// so that user defined functions can be called by named arguments ( e.g. `f(x => 1)` ).
+	opt := types.NewFunctionArgumentTypeOptions(types.RequiredArgumentCardinality)
+	opt.SetArgumentName(t.Name)
 	if t.Type.SignatureKind != types.ArgTypeFixed {
-		return types.NewTemplatedFunctionArgumentType(
-			t.Type.SignatureKind,
-			types.NewFunctionArgumentTypeOptions(types.RequiredArgumentCardinality),
-		), nil
+		return types.NewTemplatedFunctionArgumentType(t.Type.SignatureKind, opt), nil
 	}
 	typ, err := t.Type.ToZetaSQLType()
 	if err != nil {
 		return nil, err
 	}
-	opt := types.NewFunctionArgumentTypeOptions(types.RequiredArgumentCardinality)
-	opt.SetArgumentName(t.Name)
 	return types.NewFunctionArgumentType(typ, opt), nil
 }
 
 type FunctionSpec struct {
-	IsTemp    bool            `json:"isTemp"`
-	NamePath  []string        `json:"name"`
-	Language  string          `json:"language"`
-	Args      []*NameWithType `json:"args"`
-	Return    *Type           `json:"return"`
-	Body      string          `json:"body"`
-	Code      string          `json:"code"`
-	UpdatedAt time.Time       `json:"updatedAt"`
-	CreatedAt time.Time       `json:"createdAt"`
+	IsTemp      bool            `json:"isTemp"`
+	NamePath    []string        `json:"name"`
+	Language    string          `json:"language"`
+	Args        []*NameWithType `json:"args"`
+	Return      *Type           `json:"return"`
+	Body        string          `json:"body"`
+	Code        string          `json:"code"`
+	Description string          `json:"description"`
+	Determinism Determinism     `json:"determinism"`
+	UpdatedAt   time.Time       `json:"updatedAt"`
+	CreatedAt   time.Time       `json:"createdAt"`
+}
+
+// Determinism is the determinism specifier of the function ( e.g. CREATE FUNCTION ... NOT DETERMINISTIC LANGUAGE js ).
+// The empty value means that the function is defined without the specifier.
+type Determinism string
+
+const (
+	DeterminismDeterministic    Determinism = "DETERMINISTIC"
+	DeterminismNotDeterministic Determinism = "NOT_DETERMINISTIC"
+)
+
+func newDeterminism(level ast.DeterminismLevel) Determinism {
+	switch level {
+	case ast.DeterminismDeterministic, ast.DeterminismImmutable, ast.DeterminismStable:
+		return DeterminismDeterministic
+	case ast.DeterminismNotDeterministic, ast.DeterminismVolatile:
+		return DeterminismNotDeterministic
+	}
+	return ""
 }
 
 func (s *FunctionSpec) FuncName() string {
@@ -66,6 +86,11 @@ func (s *FunctionSpec) SQL() string {
 }
 
 func (s *FunctionSpec) CallSQL(ctx context.Context, callNode *ast.BaseFunctionCallNode, argValues []string) (string, error) {
+	call, err := newFunctionCall(ctx, s.FuncName())
+	if err != nil {
+		return "", err
+	}
+	ctx = withFunctionCall(ctx, call)
 	args := callNode.ArgumentList()
 	var body string
 	if s.Body == "" {
@@ -78,22 +103,16 @@ func (s *FunctionSpec) CallSQL(ctx context.Context, callNode *ast.BaseFunctionCa
 				fmt.Sprintf("%s %s", s.Args[idx].Name, typeName),
 			)
 		}
-		funcName := strings.Join(s.NamePath, ".")
-		runtimeDefinedFunc := fmt.Sprintf(
-			"CREATE FUNCTION `%s`(%s) as (%s)",
-			funcName,
-			strings.Join(definedArgs, ","),
-			s.Code,
-		)
 		analyzer := analyzerFromContext(ctx)
-		runtimeSpec, err := analyzer.analyzeTemplatedFunctionWithRuntimeArgument(ctx, runtimeDefinedFunc)
+		runtimeBody, err := analyzer.templatedFunctionBody(ctx, s, definedArgs)
 		if err != nil {
 			return "", err
 		}
-		body = runtimeSpec.Body
+		body = runtimeBody
 	} else {
 		body = s.Body
 	}
+	call.finish(s)
 	for i := 0; i < len(s.Args); i++ {
 		argRef := fmt.Sprintf("@%s", s.Args[i].Name)
 		value := argValues[i]
@@ -102,43 +121,103 @@ func (s *FunctionSpec) CallSQL(ctx context.Context, callNode *ast.BaseFunctionCa
 	return fmt.Sprintf("( %s )", body), nil
 }
 
+// DatasetSpec is the spec of the dataset created by CREATE SCHEMA.
+type DatasetSpec struct {
+	NamePath []string `json:"namePath"`
+	// DefaultCollation is the collation specification inherited by the tables created in the dataset without DEFAULT COLLATE.
+	DefaultCollation string    `json:"defaultCollation"`
+	UpdatedAt        time.Time `json:"updatedAt"`
+	CreatedAt        time.Time `json:"createdAt"`
+}
+
+func (s *DatasetSpec) DatasetName() string {
+	return formatPath(s.NamePath)
+}
+
 type TableSpec struct {
-	IsTemp     bool           `json:"isTemp"`
-	IsView     bool           `json:"isView"`
-	NamePath   []string       `json:"namePath"`
-	Columns    []*ColumnSpec  `json:"columns"`
-	PrimaryKey []string       `json:"primaryKey"`
-	CreateMode ast.CreateMode `json:"createMode"`
-	Query      string         `json:"query"`
-	UpdatedAt  time.Time      `json:"updatedAt"`
-	CreatedAt  time.Time      `json:"createdAt"`
+	IsTemp                 bool           `json:"isTemp"`
+	IsView                 bool           `json:"isView"`
+	NamePath               []string       `json:"namePath"`
+	Columns                []*ColumnSpec  `json:"columns"`
+	PrimaryKey             []string       `json:"primaryKey"`
+	CreateMode             ast.CreateMode `json:"createMode"`
+	Query                  string         `json:"query"`
+	DefaultCollation       string         `json:"defaultCollation"`
+	PartitionColumns       []string       `json:"partitionColumns"`
+	RequirePartitionFilter bool           `json:"requirePartitionFilter"`
+	// ClusterColumns is the columns specified by CLUSTER BY. The rows are not sorted by the columns.
+	ClusterColumns []string `json:"clusterColumns"`
+	// IsPrimaryKeyUnenforced reports whether the primary key is declared with NOT ENFORCED.
+	// Like BigQuery, the uniqueness of the unenforced primary key is not checked.
+	IsPrimaryKeyUnenforced bool `json:"isPrimaryKeyUnenforced"`
+	// ForeignKeys is the foreign keys declared by CREATE TABLE.
+	ForeignKeys []*ForeignKeySpec `json:"foreignKeys"`
+	// Description is the description specified by OPTIONS(description="...").
+	Description string `json:"description"`
+	// Labels is the labels specified by OPTIONS(labels=[("key", "value")]).
+	Labels map[string]string `json:"labels"`
+	// ExpirationTime is the time specified by OPTIONS(expiration_timestamp=...). nil if the expiration isn't specified.
+	// The table isn't deleted automatically when it expires.
+	ExpirationTime *time.Time `json:"expirationTime"`
+	UpdatedAt      time.Time  `json:"updatedAt"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	// Schema is the schema name of the attached database that stores the table.
+	// It's empty for the tables in the main database, and it isn't saved because it's determined by the database that has the catalog.
+	Schema string `json:"-"`
 }
 
 func (s *TableSpec) Column(name string) *ColumnSpec {
 	for _, col := range s.Columns {
-		if col.Name == name {
+		if strings.EqualFold(col.Name, name) {
 			return col
 		}
 	}
 	return nil
 }
 
+// ColumnDescription returns the description of the column.
+// If the column doesn't exist or doesn't have a description, returns an empty string.
+func (s *TableSpec) ColumnDescription(name string) string {
+	col := s.Column(name)
+	if col == nil {
+		return ""
+	}
+	return col.Description
+}
+
 func (s *TableSpec) TableName() string {
 	return formatPath(s.NamePath)
 }
 
+// hasTableName reports whether name refers to the table.
+// The dataset part of name is compared case-insensitively, but the table name must match exactly.
+func (s *TableSpec) hasTableName(name string) bool {
+	if len(s.NamePath) == 0 || !strings.EqualFold(s.TableName(), name) {
+		return false
+	}
+	return strings.HasSuffix(name, s.NamePath[len(s.NamePath)-1])
+}
+
+// QualifiedTableName returns the quoted table name qualified by the schema of the attached database if it's stored in it.
+func (s *TableSpec) QualifiedTableName() string {
+	return qualifiedName(s.Schema, s.TableName())
+}
+
 func (s *TableSpec) SQLiteSchema() string {
 	if s.IsView {
 		return viewSQLiteSchema(s)
 	}
 	if s.Query != "" {
-		return fmt.Sprintf("CREATE TABLE `%s` AS %s", s.TableName(), s.Query)
+		if s.CreateMode == ast.CreateIfNotExistsMode {
+			return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s AS %s", s.QualifiedTableName(), s.Query)
+		}
+		return fmt.Sprintf("CREATE TABLE %s AS %s", s.QualifiedTableName(), s.Query)
 	}
 	columns := []string{}
 	for _, c := range s.Columns {
 		columns = append(columns, c.SQLiteSchema())
 	}
-	if len(s.PrimaryKey) != 0 {
+	if len(s.PrimaryKey) != 0 && !s.IsPrimaryKeyUnenforced {
 		columns = append(
 			columns,
 			fmt.Sprintf("PRIMARY KEY (%s)", strings.Join(s.PrimaryKey, ",")),
@@ -153,7 +232,7 @@ func (s *TableSpec) SQLiteSchema() string {
 	case ast.CreateIfNotExistsMode:
 		stmt = "CREATE TABLE IF NOT EXISTS"
 	}
-	return fmt.Sprintf("%s `%s` (%s)", stmt, s.TableName(), strings.Join(columns, ","))
+	return fmt.Sprintf("%s %s (%s)", stmt, s.QualifiedTableName(), strings.Join(columns, ","))
 }
 
 func viewSQLiteSchema(s *TableSpec) string {
@@ -166,13 +245,31 @@ func viewSQLiteSchema(s *TableSpec) string {
 	case ast.CreateIfNotExistsMode:
 		stmt = "CREATE VIEW IF NOT EXISTS"
 	}
-	return fmt.Sprintf("%s `%s` AS %s", stmt, s.TableName(), s.Query)
+	return fmt.Sprintf("%s %s AS %s", stmt, s.QualifiedTableName(), s.Query)
 }
 
 type ColumnSpec struct {
 	Name      string `json:"name"`
 	Type      *Type  `json:"type"`
 	IsNotNull bool   `json:"isNotNull"`
+	// Collation is the collation specification of the STRING column ( e.g. `und:ci` ).
+	// If the column doesn't specify it, the default collation of the table is used.
+	Collation string `json:"collation"`
+	// TypeParams is the parameters of the parameterized type ( e.g. STRING(10) or NUMERIC(10, 2) ).
+	// If the column type isn't parameterized, TypeParams is nil.
+	TypeParams *TypeParameters `json:"typeParams"`
+	// Description is the description specified by the column OPTIONS ( e.g. `id INT64 OPTIONS(description="...")` ).
+	Description string `json:"description"`
+	// DefaultValue is the SQLite expression of the column DEFAULT ( e.g. `ts TIMESTAMP DEFAULT CURRENT_TIMESTAMP()` ).
+	// The expression is evaluated for each row when the row is inserted, so CURRENT_TIMESTAMP() isn't fixed at CREATE TABLE.
+	DefaultValue string `json:"defaultValue"`
+}
+
+// TypeParameters represents the parameters of STRING(L), BYTES(L), NUMERIC(P, S) and BIGNUMERIC(P, S).
+type TypeParameters struct {
+	MaxLength int64 `json:"maxLength"`
+	Precision int64 `json:"precision"`
+	Scale     int64 `json:"scale"`
 }
 
 type Type struct {
@@ -260,11 +357,17 @@ func (t *Type) ToZetaSQLType() (types.Type, error) {
 	return types.TypeFromKind(types.TypeKind(t.Kind)), nil
 }
 
+// FormatType returns the type in the syntax of ZetaSQL, so it can be embedded in the query to be analyzed.
+// The anonymous fields of STRUCT are formatted without the name ( e.g. STRUCT<INT64,`a` ARRAY<STRING>> ).
 func (t *Type) FormatType() string {
 	switch t.Kind {
 	case types.STRUCT:
 		formatTypes := make([]string, 0, len(t.FieldTypes))
 		for _, field := range t.FieldTypes {
+			if field.Name == "" {
+				formatTypes = append(formatTypes, field.Type.FormatType())
+				continue
+			}
 			formatTypes = append(formatTypes, fmt.Sprintf("`%s` %s", field.Name, field.Type.FormatType()))
 		}
 		return fmt.Sprintf("STRUCT<%s>", strings.Join(formatTypes, ","))
@@ -324,6 +427,9 @@ func (s *ColumnSpec) SQLiteSchema() string {
 	if s.IsNotNull {
 		schema += " NOT NULL"
 	}
+	if s.DefaultValue != "" {
+		schema += fmt.Sprintf(" DEFAULT (%s)", s.DefaultValue)
+	}
 	return schema
 }
 
@@ -366,16 +472,22 @@ func newFunctionSpec(ctx context.Context, namePath *NamePath, stmt *ast.CreateFu
 			argParams = append(argParams, fmt.Sprintf("@%s", arg.Name))
 			argNames = append(argNames, arg.Name)
 		}
+		// SQLite may reuse the result of the deterministic function for the same arguments,
+		// so NOT DETERMINISTIC function is evaluated by the function registered as non deterministic.
+		evalFuncName := "zetasqlite_eval_javascript"
+		if newDeterminism(stmt.DeterminismLevel()) == DeterminismNotDeterministic {
+			evalFuncName = "zetasqlite_eval_javascript_not_deterministic"
+		}
 		if len(argParams) == 0 {
-			body = fmt.Sprintf("zetasqlite_eval_javascript('%s', '%s')", code, retType)
+			body = fmt.Sprintf("%s('%s', '%s')", evalFuncName, code, retType)
 		} else {
 			arr, err := EncodeGoValue(types.StringArrayType(), argNames)
 			if err != nil {
 				return nil, err
 			}
 			body = fmt.Sprintf(
-				"zetasqlite_eval_javascript('%s', '%s', '%s', %s)",
-				code, retType, arr,
+				"%s('%s', '%s', '%s', %s)",
+				evalFuncName, code, retType, arr,
 				strings.Join(argParams, ","),
 			)
 		}
@@ -391,15 +503,17 @@ func newFunctionSpec(ctx context.Context, namePath *NamePath, stmt *ast.CreateFu
 	}
 	now := time.Now()
 	return &FunctionSpec{
-		IsTemp:    stmt.CreateScope() == ast.CreateScopeTemp,
-		NamePath:  namePath.mergePath(stmt.NamePath()),
-		Args:      args,
-		Return:    newType(stmt.ReturnType()),
-		Code:      stmt.Code(),
-		Body:      body,
-		Language:  language,
-		CreatedAt: now,
-		UpdatedAt: now,
+		IsTemp:      stmt.CreateScope() == ast.CreateScopeTemp,
+		NamePath:    namePath.mergePath(stmt.NamePath()),
+		Args:        args,
+		Return:      newType(stmt.ReturnType()),
+		Code:        stmt.Code(),
+		Body:        body,
+		Language:    language,
+		Description: descriptionFromOptions(stmt.OptionList()),
+		Determinism: newDeterminism(stmt.DeterminismLevel()),
+		CreatedAt:   now,
+		UpdatedAt:   now,
 	}, nil
 }
 
@@ -471,26 +585,81 @@ func newTemplatedFunctionSpec(ctx context.Context, namePath *NamePath, stmt *ast
 	}, nil
 }
 
-func newColumnsFromDef(def []*ast.ColumnDefinitionNode) []*ColumnSpec {
+func newColumnsFromDef(ctx context.Context, def []*ast.ColumnDefinitionNode, defaultCollation string) ([]*ColumnSpec, error) {
 	columns := []*ColumnSpec{}
 	for _, columnNode := range def {
 		annotation := columnNode.Annotations()
-		var isNotNull bool
+		var (
+			isNotNull    bool
+			hasCollation bool
+			collation    string
+			description  string
+			typeParams   *TypeParameters
+		)
 		if annotation != nil {
-			params := annotation.TypeParameters()
-			if params != nil {
-				//TODO: get type param from params
-				_ = params
+			params, err := newTypeParameters(columnNode.Type(), annotation.TypeParameters())
+			if err != nil {
+				return nil, err
 			}
+			typeParams = params
 			isNotNull = annotation.NotNull()
+			if collationName := annotation.CollationName(); collationName != nil {
+				hasCollation = true
+				collation = collationNameFromExpr(collationName)
+			}
+			description = descriptionFromOptions(annotation.OptionList())
+		}
+		typ := newType(columnNode.Type())
+		if !hasCollation && typ.Kind == types.STRING {
+			collation = defaultCollation
+		}
+		defaultValue, err := newColumnDefaultValue(ctx, columnNode.DefaultValue())
+		if err != nil {
+			return nil, fmt.Errorf("failed to format default value of %s: %w", columnNode.Name(), err)
 		}
 		columns = append(columns, &ColumnSpec{
-			Name:      columnNode.Name(),
-			Type:      newType(columnNode.Type()),
-			IsNotNull: isNotNull,
+			Name:         columnNode.Name(),
+			Type:         typ,
+			IsNotNull:    isNotNull,
+			Collation:    collation,
+			TypeParams:   typeParams,
+			Description:  description,
+			DefaultValue: defaultValue,
 		})
 	}
-	return columns
+	return columns, nil
+}
+
+// newColumnDefaultValue formats the DEFAULT expression of the column.
+// The current time of CREATE TABLE statement isn't passed to the current time functions,
+// so that they return the time of the DML statement inserting the row, or the time when the row is inserted by SQLite.
+func newColumnDefaultValue(ctx context.Context, node *ast.ColumnDefaultValueNode) (string, error) {
+	if node == nil || node.Expression() == nil {
+		return "", nil
+	}
+	return newNode(node.Expression()).FormatSQL(withColumnDefaultCurrentTime(ctx))
+}
+
+func descriptionFromOptions(options []*ast.OptionNode) string {
+	for _, option := range options {
+		if !strings.EqualFold(option.Name(), "description") {
+			continue
+		}
+		lit, ok := option.Value().(*ast.LiteralNode)
+		if !ok || lit == nil || lit.Value().IsNull() {
+			return ""
+		}
+		return lit.Value().StringValue()
+	}
+	return ""
+}
+
+func collationNameFromExpr(expr ast.ExprNode) string {
+	lit, ok := expr.(*ast.LiteralNode)
+	if !ok || lit == nil {
+		return ""
+	}
+	return lit.Value().StringValue()
 }
 
 func newColumnsFromOutputColumns(def []*ast.OutputColumnNode) []*ColumnSpec {
@@ -513,16 +682,48 @@ func newPrimaryKey(key *ast.PrimaryKeyNode) []string {
 	return key.ColumnNameList()
 }
 
-func newTableSpec(namePath *NamePath, stmt *ast.CreateTableStmtNode) *TableSpec {
+func newTableSpec(ctx context.Context, catalog *Catalog, namePath *NamePath, stmt *ast.CreateTableStmtNode) (*TableSpec, error) {
 	now := time.Now()
-	return &TableSpec{
-		IsTemp:     stmt.CreateScope() == ast.CreateScopeTemp,
-		NamePath:   namePath.mergePath(stmt.NamePath()),
-		Columns:    newColumnsFromDef(stmt.ColumnDefinitionList()),
-		PrimaryKey: newPrimaryKey(stmt.PrimaryKey()),
-		CreateMode: stmt.CreateMode(),
-		UpdatedAt:  now,
-		CreatedAt:  now,
+	tableNamePath := namePath.mergePath(stmt.NamePath())
+	defaultCollation := collationNameFromExpr(stmt.CollationName())
+	if defaultCollation == "" {
+		defaultCollation = catalog.datasetDefaultCollation(tableNamePath)
+	}
+	columns, err := newColumnsFromDef(ctx, stmt.ColumnDefinitionList(), defaultCollation)
+	if err != nil {
+		return nil, err
+	}
+	foreignKeys, err := catalog.newForeignKeys(namePath, stmt.ForeignKeyList())
+	if err != nil {
+		return nil, err
+	}
+	spec := &TableSpec{
+		IsTemp:                 stmt.CreateScope() == ast.CreateScopeTemp,
+		NamePath:               tableNamePath,
+		Columns:                columns,
+		PrimaryKey:             newPrimaryKey(stmt.PrimaryKey()),
+		IsPrimaryKeyUnenforced: isUnenforcedPrimaryKey(stmt.PrimaryKey()),
+		ForeignKeys:            foreignKeys,
+		CreateMode:             stmt.CreateMode(),
+		DefaultCollation:       defaultCollation,
+		PartitionColumns:       newPartitionColumns(stmt.PartitionByList(), nil),
+		ClusterColumns:         newPartitionColumns(stmt.ClusterByList(), nil),
+		UpdatedAt:              now,
+		CreatedAt:              now,
+	}
+	if err := spec.setLiteralOptions(stmt.OptionList()); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+func newDatasetSpec(namePath *NamePath, stmt *ast.CreateSchemaStmtNode) *DatasetSpec {
+	now := time.Now()
+	return &DatasetSpec{
+		NamePath:         namePath.mergePath(stmt.NamePath()),
+		DefaultCollation: collationNameFromExpr(stmt.CollationName()),
+		UpdatedAt:        now,
+		CreatedAt:        now,
 	}
 }
 
@@ -550,7 +751,7 @@ func newTableAsViewSpec(namePath *NamePath, query string, stmt *ast.CreateViewSt
 	}
 }
 
-func newTableAsSelectSpec(namePath *NamePath, query string, stmt *ast.CreateTableAsSelectStmtNode) *TableSpec {
+func newTableAsSelectSpec(ctx context.Context, catalog *Catalog, namePath *NamePath, query string, stmt *ast.CreateTableAsSelectStmtNode) (*TableSpec, error) {
 	var outputColumns []string
 	for _, column := range stmt.OutputColumnList() {
 		colName := column.Name()
@@ -562,16 +763,38 @@ func newTableAsSelectSpec(namePath *NamePath, query string, stmt *ast.CreateTabl
 		)
 	}
 	now := time.Now()
-	return &TableSpec{
-		IsTemp:     stmt.CreateScope() == ast.CreateScopeTemp,
-		NamePath:   namePath.mergePath(stmt.NamePath()),
-		Columns:    newColumnsFromDef(stmt.ColumnDefinitionList()),
-		PrimaryKey: newPrimaryKey(stmt.PrimaryKey()),
-		CreateMode: stmt.CreateMode(),
-		Query:      fmt.Sprintf("SELECT %s FROM (%s)", strings.Join(outputColumns, ","), query),
-		UpdatedAt:  now,
-		CreatedAt:  now,
+	tableNamePath := namePath.mergePath(stmt.NamePath())
+	defaultCollation := collationNameFromExpr(stmt.CollationName())
+	if defaultCollation == "" {
+		defaultCollation = catalog.datasetDefaultCollation(tableNamePath)
+	}
+	columns, err := newColumnsFromDef(ctx, stmt.ColumnDefinitionList(), defaultCollation)
+	if err != nil {
+		return nil, err
+	}
+	foreignKeys, err := catalog.newForeignKeys(namePath, stmt.ForeignKeyList())
+	if err != nil {
+		return nil, err
+	}
+	spec := &TableSpec{
+		IsTemp:                 stmt.CreateScope() == ast.CreateScopeTemp,
+		NamePath:               tableNamePath,
+		Columns:                columns,
+		PrimaryKey:             newPrimaryKey(stmt.PrimaryKey()),
+		IsPrimaryKeyUnenforced: isUnenforcedPrimaryKey(stmt.PrimaryKey()),
+		ForeignKeys:            foreignKeys,
+		CreateMode:             stmt.CreateMode(),
+		Query:                  fmt.Sprintf("SELECT %s FROM (%s)", strings.Join(outputColumns, ","), query),
+		DefaultCollation:       defaultCollation,
+		PartitionColumns:       newPartitionColumns(stmt.PartitionByList(), stmt.OutputColumnList()),
+		ClusterColumns:         newPartitionColumns(stmt.ClusterByList(), stmt.OutputColumnList()),
+		UpdatedAt:              now,
+		CreatedAt:              now,
+	}
+	if err := spec.setLiteralOptions(stmt.OptionList()); err != nil {
+		return nil, err
 	}
+	return spec, nil
 }
 
 func newType(t types.Type) *Type {
@@ -0,0 +1,106 @@
+package internal
+
+import (
+	ast "github.com/goccy/go-zetasql/resolved_ast"
+)
+
+// StatementType represents the type of the executed statement.
+// The values follow the statementType of BigQuery query job statistics.
+type StatementType string
+
+const (
+	StatementTypeSelect              StatementType = "SELECT"
+	StatementTypeInsert              StatementType = "INSERT"
+	StatementTypeUpdate              StatementType = "UPDATE"
+	StatementTypeDelete              StatementType = "DELETE"
+	StatementTypeMerge               StatementType = "MERGE"
+	StatementTypeTruncateTable       StatementType = "TRUNCATE_TABLE"
+	StatementTypeCreateTable         StatementType = "CREATE_TABLE"
+	StatementTypeCreateTableAsSelect StatementType = "CREATE_TABLE_AS_SELECT"
+	StatementTypeCreateView          StatementType = "CREATE_VIEW"
+	StatementTypeCreateFunction      StatementType = "CREATE_FUNCTION"
+	StatementTypeCreateSchema        StatementType = "CREATE_SCHEMA"
+	StatementTypeAlterTable          StatementType = "ALTER_TABLE"
+	StatementTypeDropTable           StatementType = "DROP_TABLE"
+	StatementTypeDropView            StatementType = "DROP_VIEW"
+	StatementTypeDropFunction        StatementType = "DROP_FUNCTION"
+	StatementTypeBeginTransaction    StatementType = "BEGIN_TRANSACTION"
+	StatementTypeCommitTransaction   StatementType = "COMMIT_TRANSACTION"
+	StatementTypeScript              StatementType = "SCRIPT"
+)
+
+const (
+	ddlOperationPerformedCreate  = "CREATE"
+	ddlOperationPerformedReplace = "REPLACE"
+	ddlOperationPerformedSkip    = "SKIP"
+	ddlOperationPerformedDrop    = "DROP"
+)
+
+// QueryStatistics mirrors the query statistics of BigQuery job.
+type QueryStatistics struct {
+	StatementType StatementType
+	// DDLOperationPerformed is one of CREATE, REPLACE, SKIP or DROP. Empty for non DDL statements.
+	DDLOperationPerformed string
+	// DDLTargetTable is the name path of the table or view targeted by the DDL statement.
+	DDLTargetTable []string
+	// DDLTargetRoutine is the name path of the function targeted by the DDL statement.
+	DDLTargetRoutine []string
+	// DMLStats is non-nil only for DML statements.
+	DMLStats *DMLStats
+	// CacheHit reports whether the result of the query is returned from the result cache.
+	CacheHit bool
+	// Labels is the labels attached to the query by WithQueryLabels or SET @@query_label.
+	Labels map[string]string
+	// Children holds the statistics of each statement when multiple statements are executed as a script.
+	Children []*QueryStatistics
+}
+
+type DMLStats struct {
+	InsertedRowCount int64
+	UpdatedRowCount  int64
+	DeletedRowCount  int64
+}
+
+func (s *DMLStats) add(v *DMLStats) {
+	s.InsertedRowCount += v.InsertedRowCount
+	s.UpdatedRowCount += v.UpdatedRowCount
+	s.DeletedRowCount += v.DeletedRowCount
+}
+
+func newQueryStatistics(stmts []*QueryStatistics) *QueryStatistics {
+	switch len(stmts) {
+	case 0:
+		return nil
+	case 1:
+		return stmts[0]
+	}
+	var dmlStats *DMLStats
+	for _, stmt := range stmts {
+		if stmt.DMLStats == nil {
+			continue
+		}
+		if dmlStats == nil {
+			dmlStats = &DMLStats{}
+		}
+		dmlStats.add(stmt.DMLStats)
+	}
+	return &QueryStatistics{
+		StatementType: StatementTypeScript,
+		DMLStats:      dmlStats,
+		Labels:        stmts[len(stmts)-1].Labels,
+		Children:      stmts,
+	}
+}
+
+func newDDLOperationPerformed(mode ast.CreateMode, exists bool) string {
+	if !exists {
+		return ddlOperationPerformedCreate
+	}
+	switch mode {
+	case ast.CreateOrReplaceMode:
+		return ddlOperationPerformedReplace
+	case ast.CreateIfNotExistsMode:
+		return ddlOperationPerformedSkip
+	}
+	return ddlOperationPerformedCreate
+}
@@ -21,6 +21,7 @@ type CreateTableStmt struct {
 	conn    *Conn
 	catalog *Catalog
 	spec    *TableSpec
+	args    []*ast.ParameterNode
 }
 
 type CreateViewStmt struct {
@@ -30,16 +31,28 @@ type CreateViewStmt struct {
 	spec    *TableSpec
 }
 
+func (s *CreateTableStmt) CheckNamedValue(value *driver.NamedValue) error {
+	return nil
+}
+
 func (s *CreateTableStmt) Close() error {
 	return s.stmt.Close()
 }
 
 func (s *CreateTableStmt) NumInput() int {
-	return 0
+	return len(s.args)
 }
 
 func (s *CreateTableStmt) Exec(args []driver.Value) (driver.Result, error) {
-	if _, err := s.stmt.Exec(args); err != nil {
+	values := make([]interface{}, 0, len(args))
+	for _, arg := range args {
+		values = append(values, arg)
+	}
+	newArgs, err := EncodeGoValues(values, s.args)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.stmt.Exec(newArgs...); err != nil {
 		return nil, err
 	}
 	if err := s.catalog.AddNewTableSpec(context.Background(), s.conn, s.spec); err != nil {
@@ -52,12 +65,13 @@ func (s *CreateTableStmt) Query(args []driver.Value) (driver.Rows, error) {
 	return nil, fmt.Errorf("failed to query for CreateTableStmt")
 }
 
-func newCreateTableStmt(stmt *sql.Stmt, conn *Conn, catalog *Catalog, spec *TableSpec) *CreateTableStmt {
+func newCreateTableStmt(stmt *sql.Stmt, conn *Conn, catalog *Catalog, spec *TableSpec, args []*ast.ParameterNode) *CreateTableStmt {
 	return &CreateTableStmt{
 		stmt:    stmt,
 		conn:    conn,
 		catalog: catalog,
 		spec:    spec,
+		args:    args,
 	}
 }
 
@@ -94,7 +108,7 @@ func (s *CreateViewStmt) Query(args []driver.Value) (driver.Rows, error) {
 
 type CreateFunctionStmt struct {
 	conn    *Conn
-	catalog *Catalog
+	catalog *sessionCatalog
 	spec    *FunctionSpec
 }
 
@@ -117,7 +131,7 @@ func (s *CreateFunctionStmt) Query(args []driver.Value) (driver.Rows, error) {
 	return nil, fmt.Errorf("failed to query for CreateFunctionStmt")
 }
 
-func newCreateFunctionStmt(conn *Conn, catalog *Catalog, spec *FunctionSpec) *CreateFunctionStmt {
+func newCreateFunctionStmt(conn *Conn, catalog *sessionCatalog, spec *FunctionSpec) *CreateFunctionStmt {
 	return &CreateFunctionStmt{
 		conn:    conn,
 		catalog: catalog,
@@ -18,27 +18,36 @@ type StmtAction interface {
 }
 
 type CreateTableStmtAction struct {
-	query           string
-	args            []interface{}
-	spec            *TableSpec
+	query  string
+	params []*ast.ParameterNode
+	args   []interface{}
+	spec   *TableSpec
+	// options is the options specified by the expression, which are evaluated at the execution.
+	options         []*tableOption
 	catalog         *Catalog
 	isAutoIndexMode bool
 }
 
 func (a *CreateTableStmtAction) Prepare(ctx context.Context, conn *Conn) (driver.Stmt, error) {
+	if err := setTableOptions(ctx, conn, a.spec, a.options); err != nil {
+		return nil, err
+	}
 	if a.spec.CreateMode == ast.CreateOrReplaceMode {
 		if _, err := conn.ExecContext(
 			ctx,
-			fmt.Sprintf("DROP TABLE IF EXISTS `%s`", a.spec.TableName()),
+			fmt.Sprintf("DROP TABLE IF EXISTS %s", a.spec.QualifiedTableName()),
 		); err != nil {
 			return nil, err
 		}
+		if err := deleteInsertIDs(ctx, conn, a.spec.TableName()); err != nil {
+			return nil, err
+		}
 	}
 	stmt, err := conn.PrepareContext(ctx, a.spec.SQLiteSchema())
 	if err != nil {
 		return nil, fmt.Errorf("failed to prepare %s: %w", a.query, err)
 	}
-	return newCreateTableStmt(stmt, conn, a.catalog, a.spec), nil
+	return newCreateTableStmt(stmt, conn, a.catalog, a.spec, a.params), nil
 }
 
 func (a *CreateTableStmtAction) createIndexAutomatically(ctx context.Context, conn *Conn) error {
@@ -46,31 +55,89 @@ func (a *CreateTableStmtAction) createIndexAutomatically(ctx context.Context, co
 		if !col.Type.AvailableAutoIndex() {
 			continue
 		}
-		indexName := fmt.Sprintf("zetasqlite_autoindex_%s_%s", col.Name, strings.Join(a.spec.NamePath, "_"))
-		createIndexQuery := fmt.Sprintf(
-			"CREATE INDEX IF NOT EXISTS %s ON `%s`(`%s`)",
-			indexName,
-			a.spec.TableName(),
-			col.Name,
-		)
-		if _, err := conn.ExecContext(ctx, createIndexQuery); err != nil {
-			return fmt.Errorf("failed to create index automatically %s: %w", createIndexQuery, err)
+		if err := createAutoIndex(ctx, conn, a.spec, col.Name); err != nil {
+			return err
 		}
 	}
 	return nil
 }
 
+// autoIndexName returns the name of the index created automatically for the column.
+func autoIndexName(spec *TableSpec, column string) string {
+	return fmt.Sprintf("zetasqlite_autoindex_%s_%s", column, strings.Join(spec.NamePath, "_"))
+}
+
+func createAutoIndex(ctx context.Context, conn *Conn, spec *TableSpec, column string) error {
+	createIndexQuery := fmt.Sprintf(
+		"CREATE INDEX IF NOT EXISTS %s ON `%s`(`%s`)",
+		qualifiedName(spec.Schema, autoIndexName(spec, column)),
+		spec.TableName(),
+		column,
+	)
+	if _, err := conn.ExecContext(ctx, createIndexQuery); err != nil {
+		return fmt.Errorf("failed to create index automatically %s: %w", createIndexQuery, err)
+	}
+	return nil
+}
+
+// replaceTableAsSelect creates the table from the query into the staging table before dropping the current table,
+// because the query may refer to the table to be replaced ( e.g. CREATE OR REPLACE TABLE t AS SELECT * FROM t ).
+func (a *CreateTableStmtAction) replaceTableAsSelect(ctx context.Context, conn *Conn) error {
+	// the staging table is created in the same database, because ALTER TABLE can't move the table to the other database.
+	tableName := a.spec.TableName()
+	stagingTableName := qualifiedName(a.spec.Schema, fmt.Sprintf("zetasqlite_staging_%s", tableName))
+	if _, err := conn.ExecContext(ctx, fmt.Sprintf("DROP TABLE IF EXISTS %s", stagingTableName)); err != nil {
+		return err
+	}
+	if _, err := conn.ExecContext(
+		ctx,
+		fmt.Sprintf("CREATE TABLE %s AS %s", stagingTableName, a.spec.Query),
+		a.args...,
+	); err != nil {
+		return fmt.Errorf("failed to exec %s: %w", a.query, err)
+	}
+	if _, err := conn.ExecContext(ctx, fmt.Sprintf("DROP TABLE IF EXISTS %s", a.spec.QualifiedTableName())); err != nil {
+		return err
+	}
+	if _, err := conn.ExecContext(
+		ctx,
+		fmt.Sprintf("ALTER TABLE %s RENAME TO `%s`", stagingTableName, tableName),
+	); err != nil {
+		return err
+	}
+	return nil
+}
+
 func (a *CreateTableStmtAction) exec(ctx context.Context, conn *Conn) error {
-	if a.spec.CreateMode == ast.CreateOrReplaceMode {
-		if _, err := conn.ExecContext(
-			ctx,
-			fmt.Sprintf("DROP TABLE IF EXISTS `%s`", a.spec.TableName()),
-		); err != nil {
+	exists := a.catalog.existsTableSpec(a.spec.TableName())
+	if a.spec.CreateMode == ast.CreateIfNotExistsMode && exists {
+		// keep the current table and its spec.
+		conn.addStatistics(&QueryStatistics{
+			StatementType:         StatementTypeCreateTable,
+			DDLOperationPerformed: newDDLOperationPerformed(a.spec.CreateMode, exists),
+			DDLTargetTable:        a.spec.NamePath,
+		})
+		return nil
+	}
+	if err := setTableOptions(ctx, conn, a.spec, a.options); err != nil {
+		return err
+	}
+	if a.spec.CreateMode == ast.CreateOrReplaceMode && a.spec.Query != "" {
+		if err := a.replaceTableAsSelect(ctx, conn); err != nil {
 			return err
 		}
-	}
-	if _, err := conn.ExecContext(ctx, a.spec.SQLiteSchema(), a.args...); err != nil {
-		return fmt.Errorf("failed to exec %s: %w", a.query, err)
+	} else {
+		if a.spec.CreateMode == ast.CreateOrReplaceMode {
+			if _, err := conn.ExecContext(
+				ctx,
+				fmt.Sprintf("DROP TABLE IF EXISTS %s", a.spec.QualifiedTableName()),
+			); err != nil {
+				return err
+			}
+		}
+		if _, err := conn.ExecContext(ctx, a.spec.SQLiteSchema(), a.args...); err != nil {
+			return fmt.Errorf("failed to exec %s: %w", a.query, err)
+		}
 	}
 	if a.isAutoIndexMode {
 		if err := a.createIndexAutomatically(ctx, conn); err != nil {
@@ -83,6 +150,15 @@ func (a *CreateTableStmtAction) exec(ctx context.Context, conn *Conn) error {
 	if !a.spec.IsTemp {
 		conn.addTable(a.spec)
 	}
+	stmtType := StatementTypeCreateTable
+	if a.spec.Query != "" {
+		stmtType = StatementTypeCreateTableAsSelect
+	}
+	conn.addStatistics(&QueryStatistics{
+		StatementType:         stmtType,
+		DDLOperationPerformed: newDDLOperationPerformed(a.spec.CreateMode, exists),
+		DDLTargetTable:        a.spec.NamePath,
+	})
 	return nil
 }
 
@@ -111,7 +187,7 @@ func (a *CreateTableStmtAction) Cleanup(ctx context.Context, conn *Conn) error {
 
 	if _, err := conn.ExecContext(
 		ctx,
-		fmt.Sprintf("DROP TABLE IF EXISTS `%s`", a.spec.TableName()),
+		fmt.Sprintf("DROP TABLE IF EXISTS %s", a.spec.QualifiedTableName()),
 	); err != nil {
 		return fmt.Errorf("failed to cleanup table %s: %w", a.spec.TableName(), err)
 	}
@@ -131,7 +207,7 @@ func (a *CreateViewStmtAction) Prepare(ctx context.Context, conn *Conn) (driver.
 	if a.spec.CreateMode == ast.CreateOrReplaceMode {
 		if _, err := conn.ExecContext(
 			ctx,
-			fmt.Sprintf("DROP VIEW IF EXISTS `%s`", a.spec.TableName()),
+			fmt.Sprintf("DROP VIEW IF EXISTS %s", a.spec.QualifiedTableName()),
 		); err != nil {
 			return nil, err
 		}
@@ -144,10 +220,11 @@ func (a *CreateViewStmtAction) Prepare(ctx context.Context, conn *Conn) (driver.
 }
 
 func (a *CreateViewStmtAction) exec(ctx context.Context, conn *Conn) error {
+	exists := a.catalog.existsTableSpec(a.spec.TableName())
 	if a.spec.CreateMode == ast.CreateOrReplaceMode {
 		if _, err := conn.ExecContext(
 			ctx,
-			fmt.Sprintf("DROP VIEW IF EXISTS `%s`", a.spec.TableName()),
+			fmt.Sprintf("DROP VIEW IF EXISTS %s", a.spec.QualifiedTableName()),
 		); err != nil {
 			return err
 		}
@@ -159,6 +236,11 @@ func (a *CreateViewStmtAction) exec(ctx context.Context, conn *Conn) error {
 	if err := a.catalog.AddNewTableSpec(ctx, conn, a.spec); err != nil {
 		return fmt.Errorf("failed to add new view spec: %w", err)
 	}
+	conn.addStatistics(&QueryStatistics{
+		StatementType:         StatementTypeCreateView,
+		DDLOperationPerformed: newDDLOperationPerformed(a.spec.CreateMode, exists),
+		DDLTargetTable:        a.spec.NamePath,
+	})
 	return nil
 }
 
@@ -183,7 +265,7 @@ func (a *CreateViewStmtAction) Cleanup(ctx context.Context, conn *Conn) error {
 	}
 	if _, err := conn.ExecContext(
 		ctx,
-		fmt.Sprintf("DROP VIEW IF EXISTS `%s`", a.spec.TableName()),
+		fmt.Sprintf("DROP VIEW IF EXISTS %s", a.spec.QualifiedTableName()),
 	); err != nil {
 		return fmt.Errorf("failed to cleanup view %s: %w", a.spec.TableName(), err)
 	}
@@ -198,9 +280,10 @@ func (a *CreateViewStmtAction) Args() []interface{} {
 }
 
 type CreateFunctionStmtAction struct {
-	spec    *FunctionSpec
-	catalog *Catalog
-	funcMap map[string]*FunctionSpec
+	spec       *FunctionSpec
+	createMode ast.CreateMode
+	catalog    *sessionCatalog
+	funcMap    map[string]*FunctionSpec
 }
 
 func (a *CreateFunctionStmtAction) Prepare(ctx context.Context, conn *Conn) (driver.Stmt, error) {
@@ -208,11 +291,19 @@ func (a *CreateFunctionStmtAction) Prepare(ctx context.Context, conn *Conn) (dri
 }
 
 func (a *CreateFunctionStmtAction) exec(ctx context.Context, conn *Conn) error {
+	exists := a.catalog.existsFunctionSpec(a.spec.FuncName())
 	if err := a.catalog.AddNewFunctionSpec(ctx, conn, a.spec); err != nil {
 		return fmt.Errorf("failed to add new function spec: %w", err)
 	}
-	a.funcMap[a.spec.FuncName()] = a.spec
-	conn.addFunction(a.spec)
+	a.funcMap[nameKey(a.spec.FuncName())] = a.spec
+	if !a.spec.IsTemp {
+		conn.addFunction(a.spec)
+	}
+	conn.addStatistics(&QueryStatistics{
+		StatementType:         StatementTypeCreateFunction,
+		DDLOperationPerformed: newDDLOperationPerformed(a.createMode, exists),
+		DDLTargetRoutine:      a.spec.NamePath,
+	})
 	return nil
 }
 
@@ -246,11 +337,61 @@ func (a *CreateFunctionStmtAction) Cleanup(ctx context.Context, conn *Conn) erro
 	return nil
 }
 
+type CreateSchemaStmtAction struct {
+	spec       *DatasetSpec
+	createMode ast.CreateMode
+	catalog    *Catalog
+}
+
+func (a *CreateSchemaStmtAction) Prepare(ctx context.Context, conn *Conn) (driver.Stmt, error) {
+	return nil, nil
+}
+
+func (a *CreateSchemaStmtAction) exec(ctx context.Context, conn *Conn) error {
+	exists := a.catalog.existsDatasetSpec(a.spec.DatasetName())
+	switch {
+	case exists && a.createMode == ast.CreateIfNotExistsMode:
+	case exists && a.createMode != ast.CreateOrReplaceMode:
+		return fmt.Errorf("Already Exists: Dataset %s", strings.Join(a.spec.NamePath, "."))
+	default:
+		if err := a.catalog.AddNewDatasetSpec(ctx, conn, a.spec); err != nil {
+			return fmt.Errorf("failed to add new dataset spec: %w", err)
+		}
+	}
+	conn.addStatistics(&QueryStatistics{
+		StatementType:         StatementTypeCreateSchema,
+		DDLOperationPerformed: newDDLOperationPerformed(a.createMode, exists),
+	})
+	return nil
+}
+
+func (a *CreateSchemaStmtAction) ExecContext(ctx context.Context, conn *Conn) (driver.Result, error) {
+	if err := a.exec(ctx, conn); err != nil {
+		return nil, err
+	}
+	return &Result{conn: conn}, nil
+}
+
+func (a *CreateSchemaStmtAction) QueryContext(ctx context.Context, conn *Conn) (*Rows, error) {
+	if err := a.exec(ctx, conn); err != nil {
+		return nil, err
+	}
+	return &Rows{conn: conn}, nil
+}
+
+func (a *CreateSchemaStmtAction) Args() []interface{} {
+	return nil
+}
+
+func (a *CreateSchemaStmtAction) Cleanup(ctx context.Context, conn *Conn) error {
+	return nil
+}
+
 type DropStmtAction struct {
 	name           string
 	objectType     string
 	funcMap        map[string]*FunctionSpec
-	catalog        *Catalog
+	catalog        *sessionCatalog
 	query          string
 	formattedQuery string
 	args           []interface{}
@@ -262,17 +403,37 @@ func (a *DropStmtAction) exec(ctx context.Context, conn *Conn) error {
 		if _, err := conn.ExecContext(ctx, a.formattedQuery, a.args...); err != nil {
 			return fmt.Errorf("failed to exec %s: %w", a.query, err)
 		}
-		spec := a.catalog.tableMap[a.name]
+		spec := a.catalog.tableSpec(a.name)
 		if err := a.catalog.DeleteTableSpec(ctx, conn, a.name); err != nil {
 			return fmt.Errorf("failed to delete table spec: %w", err)
 		}
+		if spec != nil && !spec.IsView {
+			if err := deleteInsertIDs(ctx, conn, spec.TableName()); err != nil {
+				return err
+			}
+		}
 		conn.deleteTable(spec)
+		stmtType := StatementTypeDropTable
+		if a.objectType == "VIEW" {
+			stmtType = StatementTypeDropView
+		}
+		conn.addStatistics(&QueryStatistics{
+			StatementType:         stmtType,
+			DDLOperationPerformed: ddlOperationPerformedDrop,
+			DDLTargetTable:        spec.NamePath,
+		})
 	case "FUNCTION":
 		if err := a.catalog.DeleteFunctionSpec(ctx, conn, a.name); err != nil {
 			return fmt.Errorf("failed to delete function spec: %w", err)
 		}
-		conn.deleteFunction(a.funcMap[a.name])
-		delete(a.funcMap, a.name)
+		spec := a.funcMap[nameKey(a.name)]
+		conn.deleteFunction(spec)
+		delete(a.funcMap, nameKey(a.name))
+		conn.addStatistics(&QueryStatistics{
+			StatementType:         StatementTypeDropFunction,
+			DDLOperationPerformed: ddlOperationPerformedDrop,
+			DDLTargetRoutine:      spec.NamePath,
+		})
 	default:
 		return fmt.Errorf("currently unsupported DROP %s statement", a.objectType)
 	}
@@ -305,11 +466,21 @@ func (a *DropStmtAction) Cleanup(ctx context.Context, conn *Conn) error {
 	return nil
 }
 
+// TableModifyingStmtAction is the action that modifies the rows of the tables returned by ModifiedTables only.
+// The cached query results not reading the tables are kept after the action is executed.
+// If ModifiedTables returns nil, the modified tables are unknown.
+type TableModifyingStmtAction interface {
+	StmtAction
+	ModifiedTables() []string
+}
+
 type DMLStmtAction struct {
+	kind           ast.Kind
 	query          string
 	params         []*ast.ParameterNode
 	args           []interface{}
 	formattedQuery string
+	table          string
 }
 
 func (a *DMLStmtAction) Prepare(ctx context.Context, conn *Conn) (driver.Stmt, error) {
@@ -325,6 +496,23 @@ func (a *DMLStmtAction) exec(ctx context.Context, conn *Conn) (driver.Result, er
 	if err != nil {
 		return nil, fmt.Errorf("failed to exec %s: %w", a.formattedQuery, err)
 	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	stats := &QueryStatistics{DMLStats: &DMLStats{}}
+	switch a.kind {
+	case ast.InsertStmt:
+		stats.StatementType = StatementTypeInsert
+		stats.DMLStats.InsertedRowCount = affected
+	case ast.UpdateStmt:
+		stats.StatementType = StatementTypeUpdate
+		stats.DMLStats.UpdatedRowCount = affected
+	case ast.DeleteStmt:
+		stats.StatementType = StatementTypeDelete
+		stats.DMLStats.DeletedRowCount = affected
+	}
+	conn.addStatistics(stats)
 	return result, nil
 }
 
@@ -344,7 +532,14 @@ func (a *DMLStmtAction) QueryContext(ctx context.Context, conn *Conn) (*Rows, er
 }
 
 func (a *DMLStmtAction) Args() []interface{} {
-	return nil
+	return a.args
+}
+
+func (a *DMLStmtAction) ModifiedTables() []string {
+	if a.table == "" {
+		return nil
+	}
+	return []string{a.table}
 }
 
 func (a *DMLStmtAction) Cleanup(ctx context.Context, conn *Conn) error {
@@ -358,6 +553,11 @@ type QueryStmtAction struct {
 	formattedQuery string
 	outputColumns  []*ColumnSpec
 	isExplainMode  bool
+
+	// resultCache is non-nil if the result of the query can be cached.
+	resultCache *resultCache
+	// resultCacheTables is the tables read by the query. It's nil if the result depends on the whole database.
+	resultCacheTables []string
 }
 
 func (a *QueryStmtAction) Prepare(ctx context.Context, conn *Conn) (driver.Stmt, error) {
@@ -372,6 +572,7 @@ func (a *QueryStmtAction) ExecContext(ctx context.Context, conn *Conn) (driver.R
 	if _, err := conn.ExecContext(ctx, a.formattedQuery, a.args...); err != nil {
 		return nil, fmt.Errorf("failed to query %s: %w", a.query, err)
 	}
+	conn.addStatistics(&QueryStatistics{StatementType: StatementTypeSelect})
 	return &Result{conn: conn}, nil
 }
 
@@ -406,6 +607,9 @@ func (a *QueryStmtAction) QueryContext(ctx context.Context, conn *Conn) (*Rows,
 		}
 		return &Rows{}, nil
 	}
+	if a.resultCache != nil && conn.tx == nil {
+		return a.queryWithResultCache(ctx, conn)
+	}
 	rows, err := conn.QueryContext(ctx, a.formattedQuery, a.args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query %s: %w", a.query, err)
@@ -413,9 +617,33 @@ func (a *QueryStmtAction) QueryContext(ctx context.Context, conn *Conn) (*Rows,
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("failed to query %s: %w", a.query, err)
 	}
+	conn.addStatistics(&QueryStatistics{StatementType: StatementTypeSelect})
 	return &Rows{conn: conn, rows: rows, columns: a.outputColumns}, nil
 }
 
+// queryWithResultCache returns the cached result of the query if exists.
+// Otherwise, it reads all rows of the result and caches them.
+// The queries in the transaction don't use the cache, because they may read the uncommitted changes.
+func (a *QueryStmtAction) queryWithResultCache(ctx context.Context, conn *Conn) (*Rows, error) {
+	key := resultCacheKey(a.formattedQuery, a.args)
+	if values, exists := a.resultCache.get(key); exists {
+		conn.addStatistics(&QueryStatistics{StatementType: StatementTypeSelect, CacheHit: true})
+		return &Rows{conn: conn, columns: a.outputColumns, cachedValues: values}, nil
+	}
+	snapshot := a.resultCache.snapshot(a.resultCacheTables)
+	rows, err := conn.QueryContext(ctx, a.formattedQuery, a.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s: %w", a.query, err)
+	}
+	values, err := scanAllValues(rows, len(a.outputColumns))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s: %w", a.query, err)
+	}
+	a.resultCache.add(key, snapshot, values)
+	conn.addStatistics(&QueryStatistics{StatementType: StatementTypeSelect})
+	return &Rows{conn: conn, columns: a.outputColumns, cachedValues: values}, nil
+}
+
 func (a *QueryStmtAction) Args() []interface{} {
 	return nil
 }
@@ -431,10 +659,12 @@ func (a *BeginStmtAction) Prepare(ctx context.Context, conn *Conn) (driver.Stmt,
 }
 
 func (a *BeginStmtAction) ExecContext(ctx context.Context, conn *Conn) (driver.Result, error) {
+	conn.addStatistics(&QueryStatistics{StatementType: StatementTypeBeginTransaction})
 	return &Result{conn: conn}, nil
 }
 
 func (a *BeginStmtAction) QueryContext(ctx context.Context, conn *Conn) (*Rows, error) {
+	conn.addStatistics(&QueryStatistics{StatementType: StatementTypeBeginTransaction})
 	return &Rows{conn: conn}, nil
 }
 
@@ -453,10 +683,12 @@ func (a *CommitStmtAction) Prepare(ctx context.Context, conn *Conn) (driver.Stmt
 }
 
 func (a *CommitStmtAction) ExecContext(ctx context.Context, conn *Conn) (driver.Result, error) {
+	conn.addStatistics(&QueryStatistics{StatementType: StatementTypeCommitTransaction})
 	return &Result{conn: conn}, nil
 }
 
 func (a *CommitStmtAction) QueryContext(ctx context.Context, conn *Conn) (*Rows, error) {
+	conn.addStatistics(&QueryStatistics{StatementType: StatementTypeCommitTransaction})
 	return &Rows{conn: conn}, nil
 }
 
@@ -477,9 +709,18 @@ func (a *TruncateStmtAction) Prepare(ctx context.Context, conn *Conn) (driver.St
 }
 
 func (a *TruncateStmtAction) exec(ctx context.Context, conn *Conn) error {
-	if _, err := conn.ExecContext(ctx, a.query); err != nil {
+	result, err := conn.ExecContext(ctx, a.query)
+	if err != nil {
 		return fmt.Errorf("failed to truncate %s: %w", a.query, err)
 	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	conn.addStatistics(&QueryStatistics{
+		StatementType: StatementTypeTruncateTable,
+		DMLStats:      &DMLStats{DeletedRowCount: deleted},
+	})
 	return nil
 }
 
@@ -505,6 +746,85 @@ func (a *TruncateStmtAction) Cleanup(ctx context.Context, conn *Conn) error {
 	return nil
 }
 
+type AlterTableStmtAction struct {
+	query         string
+	name          string
+	isIfExists    bool
+	options       []*tableOption
+	columnOptions []*columnOptions
+	columnTypes   []*columnTypeChange
+	renameTo      []string
+	catalog       *sessionCatalog
+}
+
+func (a *AlterTableStmtAction) Prepare(ctx context.Context, conn *Conn) (driver.Stmt, error) {
+	return nil, nil
+}
+
+func (a *AlterTableStmtAction) exec(ctx context.Context, conn *Conn) error {
+	spec := a.catalog.tableSpec(a.name)
+	if spec == nil {
+		if a.isIfExists {
+			return nil
+		}
+		return fmt.Errorf("Table not found: %s", a.name)
+	}
+	if spec.IsView {
+		return fmt.Errorf("failed to exec %s: %s is a view", a.query, a.name)
+	}
+	updated, err := updateTableOptions(ctx, conn, spec, a.options, a.columnOptions)
+	if err != nil {
+		return fmt.Errorf("failed to exec %s: %w", a.query, err)
+	}
+	changedTypeMap, err := applyColumnTypeChanges(updated, a.columnTypes)
+	if err != nil {
+		return fmt.Errorf("failed to exec %s: %w", a.query, err)
+	}
+	if len(changedTypeMap) != 0 {
+		if err := convertColumnTypes(ctx, conn, spec, updated, changedTypeMap); err != nil {
+			return fmt.Errorf("failed to exec %s: %w", a.query, err)
+		}
+	}
+	if a.renameTo != nil {
+		updated.NamePath = a.renameTo
+		if err := renameTable(ctx, conn, a.catalog, spec, updated); err != nil {
+			return fmt.Errorf("failed to exec %s: %w", a.query, err)
+		}
+	} else {
+		if err := a.catalog.AddNewTableSpec(ctx, conn, updated); err != nil {
+			return fmt.Errorf("failed to update table spec: %w", err)
+		}
+		conn.updateTable(updated)
+	}
+	conn.addStatistics(&QueryStatistics{
+		StatementType:  StatementTypeAlterTable,
+		DDLTargetTable: updated.NamePath,
+	})
+	return nil
+}
+
+func (a *AlterTableStmtAction) ExecContext(ctx context.Context, conn *Conn) (driver.Result, error) {
+	if err := a.exec(ctx, conn); err != nil {
+		return nil, err
+	}
+	return &Result{conn: conn}, nil
+}
+
+func (a *AlterTableStmtAction) QueryContext(ctx context.Context, conn *Conn) (*Rows, error) {
+	if err := a.exec(ctx, conn); err != nil {
+		return nil, err
+	}
+	return &Rows{conn: conn}, nil
+}
+
+func (a *AlterTableStmtAction) Args() []interface{} {
+	return nil
+}
+
+func (a *AlterTableStmtAction) Cleanup(ctx context.Context, conn *Conn) error {
+	return nil
+}
+
 type MergeStmtAction struct {
 	stmts []string
 }
@@ -514,11 +834,29 @@ func (a *MergeStmtAction) Prepare(ctx context.Context, conn *Conn) (driver.Stmt,
 }
 
 func (a *MergeStmtAction) exec(ctx context.Context, conn *Conn) error {
+	dmlStats := &DMLStats{}
 	for _, stmt := range a.stmts {
-		if _, err := conn.ExecContext(ctx, stmt); err != nil {
+		result, err := conn.ExecContext(ctx, stmt)
+		if err != nil {
 			return fmt.Errorf("failed to exec merge statement %s: %w", stmt, err)
 		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get affected rows: %w", err)
+		}
+		switch {
+		case strings.HasPrefix(stmt, "INSERT"):
+			dmlStats.InsertedRowCount += affected
+		case strings.HasPrefix(stmt, "UPDATE"):
+			dmlStats.UpdatedRowCount += affected
+		case strings.HasPrefix(stmt, "DELETE"):
+			dmlStats.DeletedRowCount += affected
+		}
 	}
+	conn.addStatistics(&QueryStatistics{
+		StatementType: StatementTypeMerge,
+		DMLStats:      dmlStats,
+	})
 	return nil
 }
 
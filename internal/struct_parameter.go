@@ -0,0 +1,110 @@
+package internal
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"math/big"
+	"reflect"
+	"time"
+
+	"cloud.google.com/go/civil"
+	"github.com/goccy/go-json"
+	"github.com/goccy/go-zetasql/types"
+)
+
+var (
+	valuerType = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+
+	// scalarGoTypeMap is the Go types converted to the scalar values by ValueFromGoValue.
+	// The struct types in the map are not declared as STRUCT.
+	scalarGoTypeMap = map[reflect.Type]func() types.Type{
+		reflect.TypeOf(json.RawMessage{}): types.JsonType,
+		reflect.TypeOf(time.Time{}):       types.TimestampType,
+		reflect.TypeOf(civil.Date{}):      types.DateType,
+		reflect.TypeOf(civil.DateTime{}):  types.DatetimeType,
+		reflect.TypeOf(civil.Time{}):      types.TimeType,
+		reflect.TypeOf(big.Rat{}):         types.NumericType,
+		reflect.TypeOf(sql.NullBool{}):    types.BoolType,
+		reflect.TypeOf(sql.NullByte{}):    types.Int64Type,
+		reflect.TypeOf(sql.NullInt16{}):   types.Int64Type,
+		reflect.TypeOf(sql.NullInt32{}):   types.Int64Type,
+		reflect.TypeOf(sql.NullInt64{}):   types.Int64Type,
+		reflect.TypeOf(sql.NullFloat64{}): types.DoubleType,
+		reflect.TypeOf(sql.NullString{}):  types.StringType,
+		reflect.TypeOf(sql.NullTime{}):    types.TimestampType,
+	}
+)
+
+// isStructParameterValue reports whether the parameter value is the struct or the slice of structs.
+// The type of these values cannot be inferred by the analyzer, so they must be declared by the Go type.
+func isStructParameterValue(v interface{}) bool {
+	if v == nil {
+		return false
+	}
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+	}
+	return t.Kind() == reflect.Struct && !isScalarGoType(t)
+}
+
+func isScalarGoType(t reflect.Type) bool {
+	if _, exists := scalarGoTypeMap[t]; exists {
+		return true
+	}
+	return t.Implements(valuerType) || reflect.PtrTo(t).Implements(valuerType)
+}
+
+// goValueType returns the type of the value converted from the Go type by ValueFromGoValue.
+// The fields of the struct are named by the names of the Go struct fields.
+func goValueType(t reflect.Type) (types.Type, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if typ, exists := scalarGoTypeMap[t]; exists {
+		return typ(), nil
+	}
+	if isScalarGoType(t) {
+		// e.g. zetasqlite.NullDate. The type depends on the value.
+		return nil, fmt.Errorf("cannot determine the type of %s", t)
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return types.Int64Type(), nil
+	case reflect.Float32, reflect.Float64:
+		return types.DoubleType(), nil
+	case reflect.Bool:
+		return types.BoolType(), nil
+	case reflect.String:
+		return types.StringType(), nil
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return types.BytesType(), nil
+		}
+		elem, err := goValueType(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return types.NewArrayType(elem)
+	case reflect.Struct:
+		fields := make([]*types.StructField, 0, t.NumField())
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			typ, err := goValueType(field.Type)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get type of field %s: %w", field.Name, err)
+			}
+			fields = append(fields, types.NewStructField(field.Name, typ))
+		}
+		return types.NewStructType(fields)
+	}
+	return nil, fmt.Errorf("unsupported parameter type %s", t)
+}
@@ -0,0 +1,243 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	ast "github.com/goccy/go-zetasql/resolved_ast"
+)
+
+// tableOption is the option of OPTIONS clause formatted as SQLite expression.
+// The value may be the expression like TIMESTAMP_ADD(CURRENT_TIMESTAMP(), INTERVAL 1 DAY), so it's evaluated at the execution.
+type tableOption struct {
+	name  string
+	value string
+}
+
+func newTableOptions(ctx context.Context, options []*ast.OptionNode) ([]*tableOption, error) {
+	ret := make([]*tableOption, 0, len(options))
+	for _, option := range options {
+		value, err := newNode(option.Value()).FormatSQL(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to format option %s: %w", option.Name(), err)
+		}
+		ret = append(ret, &tableOption{name: option.Name(), value: value})
+	}
+	return ret, nil
+}
+
+func (o *tableOption) eval(ctx context.Context, conn *Conn) (Value, error) {
+	rows, err := conn.QueryContext(ctx, fmt.Sprintf("SELECT %s", o.value))
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate option %s: %w", o.name, err)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to evaluate option %s: %w", o.name, err)
+		}
+		return nil, fmt.Errorf("failed to evaluate option %s", o.name)
+	}
+	var v interface{}
+	if err := rows.Scan(&v); err != nil {
+		return nil, fmt.Errorf("failed to evaluate option %s: %w", o.name, err)
+	}
+	return DecodeValue(v)
+}
+
+// newExprTableOptions returns the options of CREATE statement specified by the expression.
+// They are evaluated at the execution like ALTER TABLE SET OPTIONS, and the literal options are set by setLiteralOptions.
+func newExprTableOptions(ctx context.Context, options []*ast.OptionNode) ([]*tableOption, error) {
+	var exprOptions []*ast.OptionNode
+	for _, option := range options {
+		if lit, ok := option.Value().(*ast.LiteralNode); ok && lit != nil {
+			continue
+		}
+		exprOptions = append(exprOptions, option)
+	}
+	return newTableOptions(ctx, exprOptions)
+}
+
+// setLiteralOptions sets the literal options of CREATE statement to the spec at the analysis.
+// The options specified by the expression are set by setTableOptions at the execution.
+func (s *TableSpec) setLiteralOptions(options []*ast.OptionNode) error {
+	for _, option := range options {
+		lit, ok := option.Value().(*ast.LiteralNode)
+		if !ok || lit == nil {
+			continue
+		}
+		value, err := ValueFromZetaSQLValue(lit.Value())
+		if err != nil {
+			return fmt.Errorf("failed to get value of option %s: %w", option.Name(), err)
+		}
+		if err := s.setOption(option.Name(), value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setOption sets the table option stored in the spec. The other options are ignored.
+// The option set to NULL is cleared like BigQuery.
+func (s *TableSpec) setOption(name string, value Value) error {
+	switch strings.ToLower(name) {
+	case "description":
+		description, err := stringOptionValue(name, value)
+		if err != nil {
+			return err
+		}
+		s.Description = description
+	case "labels":
+		labels, err := labelsFromValue(value)
+		if err != nil {
+			return err
+		}
+		s.Labels = labels
+	case "expiration_timestamp":
+		if value == nil {
+			s.ExpirationTime = nil
+			return nil
+		}
+		t, err := value.ToTime()
+		if err != nil {
+			return fmt.Errorf("option %s must be TIMESTAMP: %w", name, err)
+		}
+		s.ExpirationTime = &t
+	case "require_partition_filter":
+		if value == nil {
+			s.RequirePartitionFilter = false
+			return nil
+		}
+		b, err := value.ToBool()
+		if err != nil {
+			return fmt.Errorf("option %s must be BOOL: %w", name, err)
+		}
+		s.RequirePartitionFilter = b
+	}
+	return nil
+}
+
+// setOption sets the column option stored in the spec. The other options are ignored.
+func (s *ColumnSpec) setOption(name string, value Value) error {
+	if strings.EqualFold(name, "description") {
+		description, err := stringOptionValue(name, value)
+		if err != nil {
+			return err
+		}
+		s.Description = description
+	}
+	return nil
+}
+
+func stringOptionValue(name string, value Value) (string, error) {
+	if value == nil {
+		return "", nil
+	}
+	s, err := value.ToString()
+	if err != nil {
+		return "", fmt.Errorf("option %s must be STRING: %w", name, err)
+	}
+	return s, nil
+}
+
+// labelsFromValue converts the value of labels option ( e.g. [("env", "prod")] ) to the map.
+func labelsFromValue(value Value) (map[string]string, error) {
+	if value == nil {
+		return nil, nil
+	}
+	array, err := value.ToArray()
+	if err != nil {
+		return nil, fmt.Errorf("option labels must be ARRAY<STRUCT<STRING, STRING>>: %w", err)
+	}
+	labels := make(map[string]string, len(array.values))
+	for _, elem := range array.values {
+		if elem == nil {
+			return nil, fmt.Errorf("option labels must not contain NULL")
+		}
+		label, err := elem.ToStruct()
+		if err != nil {
+			return nil, fmt.Errorf("option labels must be ARRAY<STRUCT<STRING, STRING>>: %w", err)
+		}
+		if len(label.values) != 2 || label.values[0] == nil || label.values[1] == nil {
+			return nil, fmt.Errorf("option labels must be the pairs of the key and the value")
+		}
+		k, err := label.values[0].ToString()
+		if err != nil {
+			return nil, err
+		}
+		v, err := label.values[1].ToString()
+		if err != nil {
+			return nil, err
+		}
+		labels[k] = v
+	}
+	return labels, nil
+}
+
+// clone returns the copy of the spec to update it without changing the spec shared with the catalog.
+func (s *TableSpec) clone() *TableSpec {
+	cloned := *s
+	cloned.Columns = make([]*ColumnSpec, 0, len(s.Columns))
+	for _, col := range s.Columns {
+		c := *col
+		cloned.Columns = append(cloned.Columns, &c)
+	}
+	if s.Labels != nil {
+		cloned.Labels = make(map[string]string, len(s.Labels))
+		for k, v := range s.Labels {
+			cloned.Labels[k] = v
+		}
+	}
+	return &cloned
+}
+
+// columnOptions is the options of ALTER COLUMN SET OPTIONS.
+type columnOptions struct {
+	column     string
+	isIfExists bool
+	options    []*tableOption
+}
+
+// setTableOptions evaluates the options and sets them to the spec.
+func setTableOptions(ctx context.Context, conn *Conn, spec *TableSpec, options []*tableOption) error {
+	for _, option := range options {
+		value, err := option.eval(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if err := spec.setOption(option.name, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateTableOptions applies the options to the copy of the table spec and returns it.
+func updateTableOptions(ctx context.Context, conn *Conn, spec *TableSpec, options []*tableOption, colOptions []*columnOptions) (*TableSpec, error) {
+	updated := spec.clone()
+	if err := setTableOptions(ctx, conn, updated, options); err != nil {
+		return nil, err
+	}
+	for _, colOption := range colOptions {
+		col := updated.Column(colOption.column)
+		if col == nil {
+			if colOption.isIfExists {
+				continue
+			}
+			return nil, fmt.Errorf("Column not found: %s", colOption.column)
+		}
+		for _, option := range colOption.options {
+			value, err := option.eval(ctx, conn)
+			if err != nil {
+				return nil, err
+			}
+			if err := col.setOption(option.name, value); err != nil {
+				return nil, err
+			}
+		}
+	}
+	updated.UpdatedAt = time.Now()
+	return updated, nil
+}
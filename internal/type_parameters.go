@@ -0,0 +1,149 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/goccy/go-zetasql/types"
+)
+
+var typeParametersPattern = regexp.MustCompile(`\((\d+)(?:,\s*(\d+))?\)$`)
+
+// newTypeParameters converts type parameters of the column definition.
+// ZetaSQL doesn't expose the values of type parameters directly,
+// so they are parsed from the type name that includes parameters ( e.g. `NUMERIC(10, 2)` ).
+func newTypeParameters(typ types.Type, params *types.TypeParameters) (*TypeParameters, error) {
+	if params == nil {
+		return nil, nil
+	}
+	switch typ.Kind() {
+	case types.STRING, types.BYTES, types.NUMERIC, types.BIG_NUMERIC:
+	default:
+		return nil, nil
+	}
+	name, err := typ.TypeNameWithParameters(params, types.ProductExternal)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get type parameters: %w", err)
+	}
+	matched := typeParametersPattern.FindStringSubmatch(name)
+	if len(matched) != 3 {
+		return nil, nil
+	}
+	first, err := strconv.ParseInt(matched[1], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse type parameter %s: %w", name, err)
+	}
+	switch typ.Kind() {
+	case types.STRING, types.BYTES:
+		return &TypeParameters{MaxLength: first}, nil
+	}
+	var scale int64
+	if matched[2] != "" {
+		s, err := strconv.ParseInt(matched[2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse type parameter %s: %w", name, err)
+		}
+		scale = s
+	}
+	return &TypeParameters{Precision: first, Scale: scale}, nil
+}
+
+// typeParametersColumnMap returns the columns that have type parameters.
+// Type parameters are enforced only in strict mode, so nil is returned if strict mode is disabled.
+func typeParametersColumnMap(ctx context.Context, table string) map[string]*ColumnSpec {
+	analyzer := analyzerFromContext(ctx)
+	if analyzer == nil || !analyzer.isStrictMode {
+		return nil
+	}
+	spec := analyzer.catalog.tableSpec(table)
+	if spec == nil {
+		return nil
+	}
+	columnMap := map[string]*ColumnSpec{}
+	for _, col := range spec.Columns {
+		if col.TypeParams != nil {
+			columnMap[col.Name] = col
+		}
+	}
+	return columnMap
+}
+
+// applyTypeParameters wraps the value expression to enforce type parameters of the column.
+func applyTypeParameters(col *ColumnSpec, value string) (string, error) {
+	name, err := LiteralFromValue(StringValue(col.Name))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(
+		"zetasqlite_apply_type_parameters(%s, %s, %d, %d, %d)",
+		value, name, col.TypeParams.MaxLength, col.TypeParams.Precision, col.TypeParams.Scale,
+	), nil
+}
+
+// APPLY_TYPE_PARAMETERS validates that the value fits the parameterized type of the column.
+// NUMERIC and BIGNUMERIC values are rounded to the scale before validation as BigQuery does.
+func APPLY_TYPE_PARAMETERS(v Value, column string, maxLength, precision, scale int64) (Value, error) {
+	switch vv := v.(type) {
+	case StringValue:
+		if maxLength <= 0 {
+			return v, nil
+		}
+		if length := int64(utf8.RuneCountInString(string(vv))); length > maxLength {
+			return nil, fmt.Errorf(
+				"Value of type STRING has length %d, which exceeds the maximum length %d of column %s with type STRING(%d)",
+				length, maxLength, column, maxLength,
+			)
+		}
+	case BytesValue:
+		if maxLength <= 0 {
+			return v, nil
+		}
+		if length := int64(len(vv)); length > maxLength {
+			return nil, fmt.Errorf(
+				"Value of type BYTES has length %d, which exceeds the maximum length %d of column %s with type BYTES(%d)",
+				length, maxLength, column, maxLength,
+			)
+		}
+	case *NumericValue:
+		if precision <= 0 {
+			return v, nil
+		}
+		rounded := roundRat(vv.Rat, scale)
+		limit := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(precision-scale), nil))
+		if new(big.Rat).Abs(rounded).Cmp(limit) >= 0 {
+			typeName := "NUMERIC"
+			if vv.isBigNumeric {
+				typeName = "BIGNUMERIC"
+			}
+			text, err := vv.ToString()
+			if err != nil {
+				return nil, err
+			}
+			return nil, fmt.Errorf(
+				"Value %s exceeds the precision of column %s with type %s(%d, %d)",
+				text, column, typeName, precision, scale,
+			)
+		}
+		return &NumericValue{Rat: rounded, isBigNumeric: vv.isBigNumeric}, nil
+	}
+	return v, nil
+}
+
+// roundRat rounds r to the specified number of decimal places, rounding half away from zero.
+func roundRat(r *big.Rat, scale int64) *big.Rat {
+	pow := new(big.Int).Exp(big.NewInt(10), big.NewInt(scale), nil)
+	scaled := new(big.Rat).Mul(r, new(big.Rat).SetInt(pow))
+	num := new(big.Int).Abs(scaled.Num())
+	quo, rem := new(big.Int).QuoRem(num, scaled.Denom(), new(big.Int))
+	if new(big.Int).Mul(rem, big.NewInt(2)).Cmp(scaled.Denom()) >= 0 {
+		quo.Add(quo, big.NewInt(1))
+	}
+	if scaled.Sign() < 0 {
+		quo.Neg(quo)
+	}
+	return new(big.Rat).SetFrac(quo, pow)
+}
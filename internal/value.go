@@ -47,7 +47,11 @@ func (iv IntValue) Add(v Value) (Value, error) {
 	if err != nil {
 		return nil, err
 	}
-	return IntValue(int64(iv) + v2), nil
+	ret := int64(iv) + v2
+	if (v2 > 0 && ret < int64(iv)) || (v2 < 0 && ret > int64(iv)) {
+		return nil, fmt.Errorf("int64 overflow: %d + %d", iv, v2)
+	}
+	return IntValue(ret), nil
 }
 
 func (iv IntValue) Sub(v Value) (Value, error) {
@@ -55,7 +59,11 @@ func (iv IntValue) Sub(v Value) (Value, error) {
 	if err != nil {
 		return nil, err
 	}
-	return IntValue(int64(iv) - v2), nil
+	ret := int64(iv) - v2
+	if (v2 > 0 && ret > int64(iv)) || (v2 < 0 && ret < int64(iv)) {
+		return nil, fmt.Errorf("int64 overflow: %d - %d", iv, v2)
+	}
+	return IntValue(ret), nil
 }
 
 func (iv IntValue) Mul(v Value) (Value, error) {
@@ -63,7 +71,11 @@ func (iv IntValue) Mul(v Value) (Value, error) {
 	if err != nil {
 		return nil, err
 	}
-	return IntValue(int64(iv) * v2), nil
+	ret := int64(iv) * v2
+	if iv != 0 && (ret/int64(iv) != v2 || (iv == -1 && v2 == math.MinInt64)) {
+		return nil, fmt.Errorf("int64 overflow: %d * %d", iv, v2)
+	}
+	return IntValue(ret), nil
 }
 
 func (iv IntValue) Div(v Value) (Value, error) {
@@ -74,6 +86,9 @@ func (iv IntValue) Div(v Value) (Value, error) {
 	if v2 == 0 {
 		return nil, fmt.Errorf("zero divided error ( %d / 0 )", iv)
 	}
+	if iv == math.MinInt64 && v2 == -1 {
+		return nil, fmt.Errorf("int64 overflow: %d / %d", iv, v2)
+	}
 	return IntValue(int64(iv) / v2), nil
 }
 
@@ -308,8 +323,13 @@ func (sv StringValue) ToTime() (time.Time, error) {
 }
 
 func (sv StringValue) ToRat() (*big.Rat, error) {
-	r := new(big.Rat)
-	r.SetString(string(sv))
+	if sv == "" {
+		return new(big.Rat), nil
+	}
+	r, ok := new(big.Rat).SetString(string(sv))
+	if !ok {
+		return nil, fmt.Errorf("failed to convert %q to numeric value", string(sv))
+	}
 	return r, nil
 }
 
@@ -1018,6 +1038,9 @@ func (jv JsonValue) Type() string {
 		return "null"
 	}
 	rv := reflect.ValueOf(jv.Interface())
+	if !rv.IsValid() {
+		return "null"
+	}
 	return jv.reflectTypeToJsonType(rv.Type())
 }
 
@@ -1062,16 +1085,11 @@ func (av *ArrayValue) EQ(v Value) (bool, error) {
 	if len(arr.values) != len(av.values) {
 		return false, nil
 	}
-	for idx, value := range av.values {
-		cond, err := arr.values[idx].EQ(value)
-		if err != nil {
-			return false, err
-		}
-		if !cond {
-			return false, nil
-		}
+	cmp, err := compareArrayValue(av, arr)
+	if err != nil {
+		return false, err
 	}
-	return true, nil
+	return cmp == 0, nil
 }
 
 func (av *ArrayValue) GT(v Value) (bool, error) {
@@ -1079,19 +1097,11 @@ func (av *ArrayValue) GT(v Value) (bool, error) {
 	if err != nil {
 		return false, err
 	}
-	if len(arr.values) != len(av.values) {
-		return false, nil
-	}
-	for idx, value := range av.values {
-		cond, err := arr.values[idx].GT(value)
-		if err != nil {
-			return false, err
-		}
-		if !cond {
-			return false, nil
-		}
+	cmp, err := compareArrayValue(av, arr)
+	if err != nil {
+		return false, err
 	}
-	return true, nil
+	return cmp > 0, nil
 }
 
 func (av *ArrayValue) GTE(v Value) (bool, error) {
@@ -1099,19 +1109,11 @@ func (av *ArrayValue) GTE(v Value) (bool, error) {
 	if err != nil {
 		return false, err
 	}
-	if len(arr.values) != len(av.values) {
-		return false, nil
-	}
-	for idx, value := range av.values {
-		cond, err := arr.values[idx].GTE(value)
-		if err != nil {
-			return false, err
-		}
-		if !cond {
-			return false, nil
-		}
+	cmp, err := compareArrayValue(av, arr)
+	if err != nil {
+		return false, err
 	}
-	return true, nil
+	return cmp >= 0, nil
 }
 
 func (av *ArrayValue) LT(v Value) (bool, error) {
@@ -1119,19 +1121,11 @@ func (av *ArrayValue) LT(v Value) (bool, error) {
 	if err != nil {
 		return false, err
 	}
-	if len(arr.values) != len(av.values) {
-		return false, nil
-	}
-	for idx, value := range av.values {
-		cond, err := arr.values[idx].LT(value)
-		if err != nil {
-			return false, err
-		}
-		if !cond {
-			return false, nil
-		}
+	cmp, err := compareArrayValue(av, arr)
+	if err != nil {
+		return false, err
 	}
-	return true, nil
+	return cmp < 0, nil
 }
 
 func (av *ArrayValue) LTE(v Value) (bool, error) {
@@ -1139,19 +1133,33 @@ func (av *ArrayValue) LTE(v Value) (bool, error) {
 	if err != nil {
 		return false, err
 	}
-	if len(arr.values) != len(av.values) {
-		return false, nil
+	cmp, err := compareArrayValue(av, arr)
+	if err != nil {
+		return false, err
 	}
-	for idx, value := range av.values {
-		cond, err := arr.values[idx].LTE(value)
+	return cmp <= 0, nil
+}
+
+// compareArrayValue compares arrays lexicographically.
+// NULL elements are ordered before non NULL elements,
+// and an array that is a prefix of another array is ordered first.
+func compareArrayValue(a, b *ArrayValue) (int, error) {
+	for idx := 0; idx < len(a.values) && idx < len(b.values); idx++ {
+		cmp, err := compareValue(a.values[idx], b.values[idx])
 		if err != nil {
-			return false, err
+			return 0, err
 		}
-		if !cond {
-			return false, nil
+		if cmp != 0 {
+			return cmp, nil
 		}
 	}
-	return true, nil
+	switch {
+	case len(a.values) < len(b.values):
+		return -1, nil
+	case len(a.values) > len(b.values):
+		return 1, nil
+	}
+	return 0, nil
 }
 
 func (av *ArrayValue) ToInt64() (int64, error) {
@@ -1223,7 +1231,8 @@ func (av *ArrayValue) Format(verb rune) string {
 }
 
 func (av *ArrayValue) Interface() interface{} {
-	var arr []interface{}
+	// the empty array is returned as the empty slice ( not nil ) like the top-level array values.
+	arr := make([]interface{}, 0, len(av.values))
 	for _, v := range av.values {
 		if v == nil {
 			arr = append(arr, nil)
@@ -1261,19 +1270,14 @@ func (sv *StructValue) EQ(v Value) (bool, error) {
 	if err != nil {
 		return false, err
 	}
-	if len(st.m) != len(sv.m) {
+	if len(st.values) != len(sv.values) {
 		return false, nil
 	}
-	for key := range sv.m {
-		cond, err := st.m[key].EQ(sv.m[key])
-		if err != nil {
-			return false, err
-		}
-		if !cond {
-			return false, nil
-		}
+	cmp, err := compareStructValue(sv, st)
+	if err != nil {
+		return false, err
 	}
-	return true, nil
+	return cmp == 0, nil
 }
 
 func (sv *StructValue) GT(v Value) (bool, error) {
@@ -1281,19 +1285,11 @@ func (sv *StructValue) GT(v Value) (bool, error) {
 	if err != nil {
 		return false, err
 	}
-	if len(st.m) != len(sv.m) {
-		return false, nil
-	}
-	for key := range sv.m {
-		cond, err := st.m[key].GT(sv.m[key])
-		if err != nil {
-			return false, err
-		}
-		if !cond {
-			return false, nil
-		}
+	cmp, err := compareStructValue(sv, st)
+	if err != nil {
+		return false, err
 	}
-	return true, nil
+	return cmp > 0, nil
 }
 
 func (sv *StructValue) GTE(v Value) (bool, error) {
@@ -1301,19 +1297,11 @@ func (sv *StructValue) GTE(v Value) (bool, error) {
 	if err != nil {
 		return false, err
 	}
-	if len(st.m) != len(sv.m) {
-		return false, nil
-	}
-	for key := range sv.m {
-		cond, err := st.m[key].GTE(sv.m[key])
-		if err != nil {
-			return false, err
-		}
-		if !cond {
-			return false, nil
-		}
+	cmp, err := compareStructValue(sv, st)
+	if err != nil {
+		return false, err
 	}
-	return true, nil
+	return cmp >= 0, nil
 }
 
 func (sv *StructValue) LT(v Value) (bool, error) {
@@ -1321,39 +1309,184 @@ func (sv *StructValue) LT(v Value) (bool, error) {
 	if err != nil {
 		return false, err
 	}
-	if len(st.m) != len(sv.m) {
-		return false, nil
+	cmp, err := compareStructValue(sv, st)
+	if err != nil {
+		return false, err
+	}
+	return cmp < 0, nil
+}
+
+func (sv *StructValue) LTE(v Value) (bool, error) {
+	st, err := v.ToStruct()
+	if err != nil {
+		return false, err
+	}
+	cmp, err := compareStructValue(sv, st)
+	if err != nil {
+		return false, err
 	}
-	for key := range sv.m {
-		cond, err := st.m[key].LT(sv.m[key])
+	return cmp <= 0, nil
+}
+
+// compareStructValue compares struct fields in order of their position.
+func compareStructValue(a, b *StructValue) (int, error) {
+	if len(a.values) != len(b.values) {
+		return 0, fmt.Errorf("failed to compare structs with different number of fields: %d and %d", len(a.values), len(b.values))
+	}
+	for idx := range a.values {
+		cmp, err := compareValue(a.values[idx], b.values[idx])
 		if err != nil {
-			return false, err
+			return 0, err
 		}
-		if !cond {
-			return false, nil
+		if cmp != 0 {
+			return cmp, nil
 		}
 	}
-	return true, nil
+	return 0, nil
 }
 
-func (sv *StructValue) LTE(v Value) (bool, error) {
-	st, err := v.ToStruct()
+// canonicalKey returns the key that identifies a value for DISTINCT.
+// Values that are equal in terms of SQL semantics have the same key
+// even if their encoded representations are different ( e.g. 0.0 and -0.0, or NUMERIC 1 and 1.00 ).
+// STRUCT values are compared by position of fields, so field names are not included in the key.
+// NULL is represented by the bare `null` token, and the other values that are formatted as arbitrary text are quoted,
+// so a NULL element never has the same key as a non-NULL element ( e.g. JSON 'null' or BYTES whose base64 text is "null" ),
+// and an element formatted as the empty text doesn't disappear from the key of the ARRAY value.
+func canonicalKey(v Value) (string, error) {
+	switch vv := v.(type) {
+	case nil:
+		return "null", nil
+	case *ArrayValue:
+		elems := make([]string, 0, len(vv.values))
+		for _, elem := range vv.values {
+			key, err := canonicalKey(elem)
+			if err != nil {
+				return "", err
+			}
+			elems = append(elems, key)
+		}
+		return fmt.Sprintf("[%s]", strings.Join(elems, ",")), nil
+	case *StructValue:
+		fields := make([]string, 0, len(vv.values))
+		for _, field := range vv.values {
+			key, err := canonicalKey(field)
+			if err != nil {
+				return "", err
+			}
+			fields = append(fields, key)
+		}
+		return fmt.Sprintf("{%s}", strings.Join(fields, ",")), nil
+	case FloatValue:
+		f := float64(vv)
+		switch {
+		case math.IsNaN(f):
+			return "NaN", nil
+		case f == 0:
+			return "0", nil
+		}
+		return strconv.FormatFloat(f, 'g', -1, 64), nil
+	case *NumericValue:
+		return vv.Rat.RatString(), nil
+	case StringValue:
+		return strconv.Quote(string(vv)), nil
+	case TimestampValue:
+		return time.Time(vv).UTC().Format(time.RFC3339Nano), nil
+	case IntValue, BoolValue:
+		return v.ToString()
+	}
+	s, err := v.ToString()
 	if err != nil {
-		return false, err
+		return "", err
 	}
-	if len(st.m) != len(sv.m) {
-		return false, nil
+	return strconv.Quote(s), nil
+}
+
+// compareValue returns -1, 0 or 1 depending on whether a is less than, equal to, or greater than b.
+// NULL is ordered before any other values.
+func compareValue(a, b Value) (int, error) {
+	switch {
+	case a == nil && b == nil:
+		return 0, nil
+	case a == nil:
+		return -1, nil
+	case b == nil:
+		return 1, nil
+	}
+	eq, err := a.EQ(b)
+	if err != nil {
+		return 0, err
+	}
+	if eq {
+		return 0, nil
 	}
-	for key := range sv.m {
-		cond, err := st.m[key].LTE(sv.m[key])
+	lt, err := a.LT(b)
+	if err != nil {
+		return 0, err
+	}
+	if lt {
+		return -1, nil
+	}
+	return 1, nil
+}
+
+// equalValue returns the result of the `=` operator in SQL semantics.
+// Unlike compareValue, NULL is returned if either value is NULL,
+// or if the elements of ARRAY ( or the fields of STRUCT ) are not different except for the NULL ones ( e.g. STRUCT(1, NULL) = STRUCT(1, NULL) ).
+// compareValue keeps the total order for MIN, MAX and ORDER BY.
+func equalValue(a, b Value) (Value, error) {
+	if a == nil || b == nil {
+		return nil, nil
+	}
+	switch av := a.(type) {
+	case *ArrayValue:
+		bv, err := b.ToArray()
 		if err != nil {
-			return false, err
+			return nil, err
+		}
+		if len(av.values) != len(bv.values) {
+			return BoolValue(false), nil
+		}
+		return equalValues(av.values, bv.values)
+	case *StructValue:
+		bv, err := b.ToStruct()
+		if err != nil {
+			return nil, err
+		}
+		if len(av.values) != len(bv.values) {
+			return BoolValue(false), nil
+		}
+		return equalValues(av.values, bv.values)
+	}
+	eq, err := a.EQ(b)
+	if err != nil {
+		return nil, err
+	}
+	return BoolValue(eq), nil
+}
+
+func equalValues(a, b []Value) (Value, error) {
+	var unknown bool
+	for idx := range a {
+		eq, err := equalValue(a[idx], b[idx])
+		if err != nil {
+			return nil, err
+		}
+		if eq == nil {
+			unknown = true
+			continue
+		}
+		cond, err := eq.ToBool()
+		if err != nil {
+			return nil, err
 		}
 		if !cond {
-			return false, nil
+			return BoolValue(false), nil
 		}
 	}
-	return true, nil
+	if unknown {
+		return nil, nil
+	}
+	return BoolValue(true), nil
 }
 
 func (sv *StructValue) ToInt64() (int64, error) {
@@ -1466,19 +1599,13 @@ func (d DateValue) AddDateWithInterval(v int, interval string) (Value, error) {
 }
 
 func (d DateValue) Add(v Value) (Value, error) {
-	src := time.Time(d)
 	switch vv := v.(type) {
 	case *IntervalValue:
-		return DatetimeValue(time.Date(
-			src.Year()+int(vv.Years),
-			time.Month(int(src.Month())+int(vv.Months)),
-			src.Day()+int(vv.Days),
-			src.Hour()+int(vv.Hours),
-			src.Minute()+int(vv.Minutes),
-			src.Second()+int(vv.Seconds),
-			src.Nanosecond()+int(vv.SubSecondNanos),
-			src.Location(),
-		)), nil
+		ret, ok := addInterval(time.Time(d), vv, 1)
+		if !ok {
+			return nil, fmt.Errorf("Adding interval %s to date %s causes overflow", vv.Format('s'), time.Time(d).Format("2006-01-02"))
+		}
+		return DatetimeValue(ret), nil
 	case IntValue:
 		return DateValue(time.Time(d).AddDate(0, 0, int(vv))), nil
 	}
@@ -1486,19 +1613,13 @@ func (d DateValue) Add(v Value) (Value, error) {
 }
 
 func (d DateValue) Sub(v Value) (Value, error) {
-	src := time.Time(d)
 	switch vv := v.(type) {
 	case *IntervalValue:
-		return DatetimeValue(time.Date(
-			src.Year()-int(vv.Years),
-			time.Month(int(src.Month())-int(vv.Months)),
-			src.Day()-int(vv.Days),
-			src.Hour()-int(vv.Hours),
-			src.Minute()-int(vv.Minutes),
-			src.Second()-int(vv.Seconds),
-			src.Nanosecond()-int(vv.SubSecondNanos),
-			src.Location(),
-		)), nil
+		ret, ok := addInterval(time.Time(d), vv, -1)
+		if !ok {
+			return nil, fmt.Errorf("Subtracting interval %s from date %s causes overflow", vv.Format('s'), time.Time(d).Format("2006-01-02"))
+		}
+		return DatetimeValue(ret), nil
 	case IntValue:
 		return DateValue(time.Time(d).AddDate(0, 0, -int(vv))), nil
 	}
@@ -1631,16 +1752,11 @@ type DatetimeValue time.Time
 func (d DatetimeValue) Add(v Value) (Value, error) {
 	src := time.Time(d)
 	if vv, ok := v.(*IntervalValue); ok {
-		return DatetimeValue(time.Date(
-			src.Year()+int(vv.Years),
-			time.Month(int(src.Month())+int(vv.Months)),
-			src.Day()+int(vv.Days),
-			src.Hour()+int(vv.Hours),
-			src.Minute()+int(vv.Minutes),
-			src.Second()+int(vv.Seconds),
-			src.Nanosecond()+int(vv.SubSecondNanos),
-			src.Location(),
-		)), nil
+		ret, ok := addInterval(src, vv, 1)
+		if !ok {
+			return nil, fmt.Errorf("Adding interval %s to datetime %s causes overflow", vv.Format('s'), formatDatetimeForError(src))
+		}
+		return DatetimeValue(ret), nil
 	}
 	return nil, fmt.Errorf("failed to use add operator for datetime and %T type", v)
 }
@@ -1648,16 +1764,11 @@ func (d DatetimeValue) Add(v Value) (Value, error) {
 func (d DatetimeValue) Sub(v Value) (Value, error) {
 	src := time.Time(d)
 	if vv, ok := v.(*IntervalValue); ok {
-		return DatetimeValue(time.Date(
-			src.Year()-int(vv.Years),
-			time.Month(int(src.Month())-int(vv.Months)),
-			src.Day()-int(vv.Days),
-			src.Hour()-int(vv.Hours),
-			src.Minute()-int(vv.Minutes),
-			src.Second()-int(vv.Seconds),
-			src.Nanosecond()-int(vv.SubSecondNanos),
-			src.Location(),
-		)), nil
+		ret, ok := addInterval(src, vv, -1)
+		if !ok {
+			return nil, fmt.Errorf("Subtracting interval %s from datetime %s causes overflow", vv.Format('s'), formatDatetimeForError(src))
+		}
+		return DatetimeValue(ret), nil
 	}
 	dst, err := v.ToTime()
 	if err != nil {
@@ -1777,10 +1888,16 @@ func (d DatetimeValue) Interface() interface{} {
 type TimeValue time.Time
 
 func (t TimeValue) Add(v Value) (Value, error) {
+	if vv, ok := v.(*IntervalValue); ok {
+		return addTimeInterval(time.Time(t), vv, 1)
+	}
 	return nil, fmt.Errorf("add operation is unsupported for time %v", t)
 }
 
 func (t TimeValue) Sub(v Value) (Value, error) {
+	if vv, ok := v.(*IntervalValue); ok {
+		return addTimeInterval(time.Time(t), vv, -1)
+	}
 	return nil, fmt.Errorf("sub operation is unsupported for time %v", t)
 }
 
@@ -1915,16 +2032,7 @@ func (t TimestampValue) AddValueWithPart(v int64, part string) (Value, error) {
 func (t TimestampValue) Add(v Value) (Value, error) {
 	src := time.Time(t)
 	if vv, ok := v.(*IntervalValue); ok {
-		return TimestampValue(time.Date(
-			src.Year()+int(vv.Years),
-			time.Month(int(src.Month())+int(vv.Months)),
-			src.Day()+int(vv.Days),
-			src.Hour()+int(vv.Hours),
-			src.Minute()+int(vv.Minutes),
-			src.Second()+int(vv.Seconds),
-			src.Nanosecond()+int(vv.SubSecondNanos),
-			src.Location(),
-		)), nil
+		return addTimestampInterval(src, vv, 1)
 	}
 	return nil, fmt.Errorf("failed to use add operator for timestamp and %T type", v)
 }
@@ -1932,16 +2040,7 @@ func (t TimestampValue) Add(v Value) (Value, error) {
 func (t TimestampValue) Sub(v Value) (Value, error) {
 	src := time.Time(t)
 	if vv, ok := v.(*IntervalValue); ok {
-		return TimestampValue(time.Date(
-			src.Year()-int(vv.Years),
-			time.Month(int(src.Month())-int(vv.Months)),
-			src.Day()-int(vv.Days),
-			src.Hour()-int(vv.Hours),
-			src.Minute()-int(vv.Minutes),
-			src.Second()-int(vv.Seconds),
-			src.Nanosecond()-int(vv.SubSecondNanos),
-			src.Location(),
-		)), nil
+		return addTimestampInterval(src, vv, -1)
 	}
 	dst, err := v.ToTime()
 	if err != nil {
@@ -2103,11 +2202,38 @@ func (iv *IntervalValue) ToInt64() (int64, error) {
 	return 0, fmt.Errorf("unsupported int64 cast for interval value")
 }
 
+// ToString returns the interval in canonical format ( [sign]Y-M [sign]D [sign]H:M:S[.F] ).
+// String() of bigquery.IntervalValue isn't used because it loses the sign of the months
+// and the time part when the upper part is zero ( e.g. -0-1 0 -0:0:1 ).
 func (iv *IntervalValue) ToString() (string, error) {
-	if iv.Years == 0 && iv.Months < 0 {
-		return "-" + iv.String(), nil
+	src := iv.IntervalValue
+	if !src.IsCanonical() {
+		src = src.Canonicalize()
+	}
+	var ymSign, timeSign string
+	if src.Years < 0 || src.Months < 0 {
+		ymSign = "-"
+	}
+	if src.Hours < 0 || src.Minutes < 0 || src.Seconds < 0 || src.SubSecondNanos < 0 {
+		timeSign = "-"
 	}
-	return iv.String(), nil
+	out := fmt.Sprintf(
+		"%s%d-%d %d %s%d:%d:%d",
+		ymSign, absInt32(src.Years), absInt32(src.Months),
+		src.Days,
+		timeSign, absInt32(src.Hours), absInt32(src.Minutes), absInt32(src.Seconds),
+	)
+	if src.SubSecondNanos != 0 {
+		out += "." + strings.TrimRight(fmt.Sprintf("%09d", absInt32(src.SubSecondNanos)), "0")
+	}
+	return out, nil
+}
+
+func absInt32(v int32) int32 {
+	if v < 0 {
+		return -v
+	}
+	return v
 }
 
 func (iv *IntervalValue) ToBytes() ([]byte, error) {
@@ -2359,6 +2485,52 @@ func isTimestamp(timestamp string) bool {
 	return true
 }
 
+const (
+	// maxCivilDays is the number of days from 0001-01-01 to 9999-12-31.
+	// Adding more days than this to any date always overflows.
+	maxCivilDays = 3652058
+	// maxCivilSeconds is the number of seconds from 0001-01-01 00:00:00 to 9999-12-31 23:59:59.
+	maxCivilSeconds = (maxCivilDays+1)*24*60*60 - 1
+)
+
+// isValidCivilTime reports whether the wall clock of t is in the range of DATE and DATETIME ( 0001-01-01 to 9999-12-31 ).
+func isValidCivilTime(t time.Time) bool {
+	year := t.Year()
+	return 1 <= year && year <= 9999
+}
+
+// isValidTimestamp reports whether t is in the range of TIMESTAMP ( 0001-01-01 00:00:00 to 9999-12-31 23:59:59.999999 UTC ).
+func isValidTimestamp(t time.Time) bool {
+	return isValidCivilTime(t.UTC())
+}
+
+// validateTimeValueRange returns an error if the DATE, DATETIME or TIMESTAMP value is out of the range supported by BigQuery.
+func validateTimeValueRange(v Value) error {
+	switch vv := v.(type) {
+	case DateValue:
+		if !isValidCivilTime(time.Time(vv)) {
+			return fmt.Errorf("DATE value is out of range: %s", time.Time(vv).Format("2006-01-02"))
+		}
+	case DatetimeValue:
+		if !isValidCivilTime(time.Time(vv)) {
+			return fmt.Errorf("DATETIME value is out of range: %s", formatDatetimeForError(time.Time(vv)))
+		}
+	case TimestampValue:
+		if !isValidTimestamp(time.Time(vv)) {
+			return fmt.Errorf("TIMESTAMP value is out of range: %s", formatTimestampForError(time.Time(vv)))
+		}
+	}
+	return nil
+}
+
+func formatDatetimeForError(t time.Time) string {
+	return t.Format("2006-01-02 15:04:05.999999")
+}
+
+func formatTimestampForError(t time.Time) string {
+	return t.UTC().Format("2006-01-02 15:04:05.999999-07")
+}
+
 func parseDate(date string) (time.Time, error) {
 	return time.Parse("2006-01-02", date)
 }
@@ -2412,7 +2584,14 @@ func parseTimestamp(timestamp string, loc *time.Location) (time.Time, error) {
 }
 
 func DateFromInt64Value(v int64) (time.Time, error) {
-	return time.Unix(0, 0).Add(time.Duration(v) * 24 * time.Hour), nil
+	return dateFromUnixDays(v), nil
+}
+
+// dateFromUnixDays returns the date of the number of days since 1970-01-01 in UTC.
+// The dates must not depend on the local time zone of the process, and
+// time.Duration can't hold the days after 2262, so the date is calculated by the calendar.
+func dateFromUnixDays(days int64) time.Time {
+	return time.Date(1970, time.January, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, int(days))
 }
 
 func TimestampFromFloatValue(f float64) (time.Time, error) {
@@ -2431,6 +2610,9 @@ func parseInterval(v string) (*IntervalValue, error) {
 	if v == "" {
 		return nil, fmt.Errorf("interval value is empty")
 	}
+	if v[0] == 'P' {
+		return parseISO8601Interval(v)
+	}
 	isNegative := v[0] == '-'
 	interval, err := bigquery.ParseInterval(v)
 	if err != nil {
@@ -2439,9 +2621,141 @@ func parseInterval(v string) (*IntervalValue, error) {
 	if isNegative && interval.Months > 0 {
 		interval.Months *= -1
 	}
+	// bigquery.ParseInterval takes the sign of the time part from the hours,
+	// so the sign is lost if the hours is zero ( e.g. -0:0:1 ).
+	if parts := strings.Fields(v); len(parts) == 3 && strings.HasPrefix(parts[2], "-") && interval.Hours == 0 {
+		interval.Minutes *= -1
+		interval.Seconds *= -1
+		interval.SubSecondNanos *= -1
+	}
 	return &IntervalValue{IntervalValue: interval}, nil
 }
 
+const (
+	// the maximum absolute values of the interval parts supported by BigQuery ( 10000-0 3660000 87840000:0:0 ).
+	maxIntervalMonths  = 10000 * 12
+	maxIntervalDays    = 3660000
+	maxIntervalSeconds = 87840000 * 3600
+)
+
+// parseISO8601Interval parses the interval in ISO 8601 duration format ( e.g. P1Y2M3DT4H5M6.5S ).
+// Each part can be negative ( e.g. P-1Y2M ) and the fractional value is only allowed for the seconds part.
+func parseISO8601Interval(v string) (*IntervalValue, error) {
+	src := v
+	v = strings.TrimPrefix(v, "P")
+	if v == "" {
+		return nil, fmt.Errorf("invalid interval %q: at least one datetime part must be defined", src)
+	}
+	var (
+		months      int64
+		days        int64
+		seconds     int64
+		nanos       int64
+		inTimePart  bool
+		hasTimePart bool
+	)
+	// add adds the part to the total, and returns an error if the total exceeds the range of the interval.
+	add := func(total *int64, n, unit, max int64) error {
+		if n > max/unit || n < -max/unit {
+			return fmt.Errorf("invalid interval %q: interval field is out of range", src)
+		}
+		*total += n * unit
+		if *total > max || *total < -max {
+			return fmt.Errorf("invalid interval %q: interval field is out of range", src)
+		}
+		return nil
+	}
+	for v != "" {
+		if v[0] == 'T' {
+			if inTimePart {
+				return nil, fmt.Errorf("invalid interval %q: unexpected duplicate time separator 'T'", src)
+			}
+			inTimePart = true
+			v = v[1:]
+			continue
+		}
+		isNegative := v[0] == '-'
+		if isNegative {
+			v = v[1:]
+		}
+		end := strings.IndexFunc(v, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+		if end <= 0 {
+			return nil, fmt.Errorf("invalid interval %q: expected number", src)
+		}
+		number, part := v[:end], v[end]
+		v = v[end+1:]
+		integer, fraction, hasFraction := strings.Cut(number, ".")
+		if hasFraction && (!inTimePart || part != 'S') {
+			return nil, fmt.Errorf("invalid interval %q: fractional values are only allowed for seconds part 'S'", src)
+		}
+		n, err := strconv.ParseInt(integer, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid interval %q: %w", src, err)
+		}
+		if isNegative {
+			n = -n
+		}
+		hasTimePart = inTimePart
+		switch {
+		case !inTimePart && part == 'Y':
+			err = add(&months, n, 12, maxIntervalMonths)
+		case !inTimePart && part == 'M':
+			err = add(&months, n, 1, maxIntervalMonths)
+		case !inTimePart && part == 'W':
+			err = add(&days, n, 7, maxIntervalDays)
+		case !inTimePart && part == 'D':
+			err = add(&days, n, 1, maxIntervalDays)
+		case inTimePart && part == 'H':
+			err = add(&seconds, n, 3600, maxIntervalSeconds)
+		case inTimePart && part == 'M':
+			err = add(&seconds, n, 60, maxIntervalSeconds)
+		case inTimePart && part == 'S':
+			err = add(&seconds, n, 1, maxIntervalSeconds)
+			if fraction != "" {
+				if len(fraction) > 9 {
+					return nil, fmt.Errorf("invalid interval %q: too many fractional digits", src)
+				}
+				f, err := strconv.ParseInt(fraction+strings.Repeat("0", 9-len(fraction)), 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("invalid interval %q: %w", src, err)
+				}
+				if isNegative {
+					f = -f
+				}
+				nanos += f
+			}
+		default:
+			return nil, fmt.Errorf("invalid interval %q: unexpected '%c'", src, part)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if inTimePart && !hasTimePart {
+		return nil, fmt.Errorf("invalid interval %q: time separator 'T' must be followed by the time part", src)
+	}
+	seconds += nanos / int64(time.Second)
+	nanos %= int64(time.Second)
+	if seconds > 0 && nanos < 0 {
+		seconds--
+		nanos += int64(time.Second)
+	} else if seconds < 0 && nanos > 0 {
+		seconds++
+		nanos -= int64(time.Second)
+	}
+	return &IntervalValue{
+		IntervalValue: &bigquery.IntervalValue{
+			Years:          int32(months / 12),
+			Months:         int32(months % 12),
+			Days:           int32(days),
+			Hours:          int32(seconds / 3600),
+			Minutes:        int32(seconds % 3600 / 60),
+			Seconds:        int32(seconds % 60),
+			SubSecondNanos: int32(nanos),
+		},
+	}, nil
+}
+
 func isNullValue(v interface{}) bool {
 	if v == nil {
 		return true
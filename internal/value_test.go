@@ -29,3 +29,77 @@ func TestTimestampValue(t *testing.T) {
 		t.Fatalf("failed to format timestamp")
 	}
 }
+
+func TestBytesValueFromLiteral(t *testing.T) {
+	for _, test := range []struct {
+		literal  string
+		expected string
+	}{
+		{literal: `b""`, expected: ""},
+		{literal: `b"abc"`, expected: "abc"},
+		{literal: `b'it\'s'`, expected: "it's"},
+		{literal: `B"\x00\xFF"`, expected: "\x00\xff"},
+		{literal: `b"\101\n\t\\"`, expected: "A\n\t\\"},
+		{literal: `b"""a"b"""`, expected: `a"b`},
+		{literal: `b'''a'b'''`, expected: `a'b`},
+	} {
+		v, err := bytesValueFromLiteral(test.literal)
+		if err != nil {
+			t.Fatalf("%s: %v", test.literal, err)
+		}
+		if string(v) != test.expected {
+			t.Fatalf("%s: expected %q but got %q", test.literal, test.expected, string(v))
+		}
+	}
+	if _, err := bytesValueFromLiteral(`b"\q"`); err == nil {
+		t.Fatal("expected error for invalid escape sequence")
+	}
+}
+
+func TestTimeValueAddInterval(t *testing.T) {
+	src := TimeValue(time.Date(1970, 1, 1, 23, 30, 0, 0, time.UTC))
+	for _, test := range []struct {
+		name     string
+		interval *IntervalValue
+		isSub    bool
+		expected string
+	}{
+		{name: "wrap around midnight", interval: mustInterval(t, 45, "MINUTE"), expected: "00:15:00"},
+		{name: "sub", interval: mustInterval(t, 1500, "MILLISECOND"), isSub: true, expected: "23:29:58.5"},
+		{name: "more than a day", interval: mustInterval(t, 25, "HOUR"), expected: "00:30:00"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				v   Value
+				err error
+			)
+			if test.isSub {
+				v, err = src.Sub(test.interval)
+			} else {
+				v, err = src.Add(test.interval)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := v.ToString()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != test.expected {
+				t.Fatalf("expected %s but got %s", test.expected, got)
+			}
+		})
+	}
+	if _, err := src.Add(mustInterval(t, 1, "DAY")); err == nil {
+		t.Fatal("expected error for interval with day part")
+	}
+}
+
+func mustInterval(t *testing.T, value int64, part string) *IntervalValue {
+	t.Helper()
+	v, err := INTERVAL(value, part)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v.(*IntervalValue)
+}
@@ -155,8 +155,13 @@ func (c *Catalog) createWildcardTable(path []string) (types.Table, error) {
 			matchedSpecs = append(matchedSpecs, spec)
 		}
 	}
+	// The tables created at the same time are sorted by name,
+	// so that the same query is always formatted to the same SQL regardless of the order of the map.
 	sort.Slice(matchedSpecs, func(i, j int) bool {
-		return matchedSpecs[i].CreatedAt.UnixNano() > matchedSpecs[j].CreatedAt.UnixNano()
+		if !matchedSpecs[i].CreatedAt.Equal(matchedSpecs[j].CreatedAt) {
+			return matchedSpecs[i].CreatedAt.After(matchedSpecs[j].CreatedAt)
+		}
+		return matchedSpecs[i].TableName() < matchedSpecs[j].TableName()
 	})
 	if len(matchedSpecs) == 0 {
 		return nil, fmt.Errorf("failed to find matched tables by wildcard")
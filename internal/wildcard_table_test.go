@@ -0,0 +1,42 @@
+package internal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/goccy/go-zetasql/types"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWildcardTableOrder(t *testing.T) {
+	createdAt := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	specs := []*TableSpec{
+		{NamePath: []string{"project", "dataset", "events_20230102"}, CreatedAt: createdAt},
+		{NamePath: []string{"project", "dataset", "events_20230101"}, CreatedAt: createdAt},
+		{NamePath: []string{"project", "dataset", "events_20230103"}, CreatedAt: createdAt.Add(time.Second)},
+	}
+	tableMap := map[string]*TableSpec{}
+	for _, spec := range specs {
+		spec.Columns = []*ColumnSpec{{Name: "id", Type: &Type{Kind: types.INT64}}}
+		tableMap[nameKey(spec.TableName())] = spec
+	}
+	catalog := &Catalog{tableMap: tableMap}
+	expected := []string{
+		"project_dataset_events_20230103",
+		"project_dataset_events_20230101",
+		"project_dataset_events_20230102",
+	}
+	for i := 0; i < 10; i++ {
+		table, err := catalog.createWildcardTable([]string{"project.dataset.events_*"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, spec := range table.(*WildcardTable).tables {
+			names = append(names, spec.TableName())
+		}
+		if diff := cmp.Diff(expected, names); diff != "" {
+			t.Fatalf("(-want +got):\n%s", diff)
+		}
+	}
+}
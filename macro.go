@@ -0,0 +1,18 @@
+package zetasqlite
+
+import (
+	internal "github.com/goccy/go-zetasqlite/internal"
+)
+
+// MacroFunc returns the SQL text that replaces the macro call.
+// args are the SQL texts of the arguments ( e.g. `{{ users_of('JP', 10) }}` is called with "'JP'" and "10" ).
+type MacroFunc = internal.MacroFunc
+
+// RegisterMacro registers the macro that is expanded to the SQL text before the query is analyzed ( like the macros of dbt ).
+// The macro is called by `{{ name }}` or `{{ name(args...) }}` in the query, and f receives the SQL texts of the arguments.
+// The macro calls in string literals, quoted identifiers and comments are not expanded,
+// and the SQL text returned by f can also call the other macros.
+// To register the macros for all connections of *sql.DB, use ConnectHook of ZetaSQLiteDriver.
+func (c *ZetaSQLiteConn) RegisterMacro(name string, f MacroFunc) {
+	c.analyzer.RegisterMacro(name, f)
+}
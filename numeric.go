@@ -0,0 +1,51 @@
+package zetasqlite
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math/big"
+)
+
+// NullNumeric represents a NUMERIC or BIGNUMERIC value that may be NULL.
+// It can be used as a scan target and a query parameter to interoperate with the types of cloud.google.com/go/bigquery.
+// *big.Rat can also be passed as a query parameter directly, and it is declared as NUMERIC.
+type NullNumeric struct {
+	Numeric *big.Rat
+	Valid   bool // Valid is true if Numeric is not NULL.
+}
+
+// Scan implements the sql.Scanner interface.
+func (n *NullNumeric) Scan(src interface{}) error {
+	if src == nil {
+		n.Numeric, n.Valid = nil, false
+		return nil
+	}
+	switch v := src.(type) {
+	case string:
+		r, ok := new(big.Rat).SetString(v)
+		if !ok {
+			return fmt.Errorf("failed to scan NUMERIC value %q", v)
+		}
+		n.Numeric = r
+	case []byte:
+		r, ok := new(big.Rat).SetString(string(v))
+		if !ok {
+			return fmt.Errorf("failed to scan NUMERIC value %q", v)
+		}
+		n.Numeric = r
+	case int64:
+		n.Numeric = new(big.Rat).SetInt64(v)
+	default:
+		return fmt.Errorf("unexpected NUMERIC value type %T", src)
+	}
+	n.Valid = true
+	return nil
+}
+
+// Value implements the driver.Valuer interface.
+func (n NullNumeric) Value() (driver.Value, error) {
+	if !n.Valid || n.Numeric == nil {
+		return nil, nil
+	}
+	return n.Numeric, nil
+}
@@ -0,0 +1,87 @@
+package zetasqlite
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+
+	internal "github.com/goccy/go-zetasqlite/internal"
+)
+
+// ResultPages is the query result saved to the temporary table to be read page by page,
+// like the results of BigQuery query jobs read by pageToken.
+// The temporary table belongs to the connection, so the pages must be read from the connection that executed the query.
+type ResultPages struct {
+	conn  *ZetaSQLiteConn
+	table *internal.ResultTable
+}
+
+// ResultPage is a page of the query result.
+// Values are converted the same way as scanning into interface{} destinations via database/sql.
+type ResultPage struct {
+	Rows [][]interface{}
+	// NextPageToken is the token to read the next page. It is empty if the page is the last page.
+	NextPageToken string
+}
+
+// QueryPages executes a query once and saves the result to the temporary table.
+// Use Page to read the result and Close to remove the temporary table.
+func (c *ZetaSQLiteConn) QueryPages(ctx context.Context, query string, args ...interface{}) (*ResultPages, error) {
+	driverRows, err := c.QueryContext(ctx, query, namedValuesFromArgs(args))
+	if err != nil {
+		return nil, err
+	}
+	rows, _ := driverRows.(*internal.Rows)
+	if rows == nil {
+		return nil, fmt.Errorf("zetasqlite: query doesn't return rows")
+	}
+	table, err := internal.SaveResultTable(ctx, internal.NewConn(c.conn, c.tx), rows)
+	if err != nil {
+		return nil, fmt.Errorf("zetasqlite: failed to save query result: %w", err)
+	}
+	return &ResultPages{conn: c, table: table}, nil
+}
+
+// Columns returns the names and types of the result columns.
+func (p *ResultPages) Columns() []*ColumnSpec {
+	return p.table.Columns()
+}
+
+// TotalRows returns the number of rows of the result.
+func (p *ResultPages) TotalRows() int64 {
+	return p.table.TotalRows()
+}
+
+// Page reads up to maxResults rows starting from the position specified by pageToken.
+// Specify the empty token to read the first page.
+func (p *ResultPages) Page(ctx context.Context, pageToken string, maxResults int) (*ResultPage, error) {
+	rows, nextPageToken, err := p.table.Page(ctx, internal.NewConn(p.conn.conn, p.conn.tx), pageToken, maxResults)
+	if err != nil {
+		return nil, fmt.Errorf("zetasqlite: %w", err)
+	}
+	defer rows.Close()
+
+	page := &ResultPage{NextPageToken: nextPageToken}
+	for {
+		dest := make([]driver.Value, len(p.table.Columns()))
+		if err := rows.Next(dest); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("zetasqlite: failed to read row: %w", err)
+		}
+		row := make([]interface{}, 0, len(dest))
+		for _, v := range dest {
+			row = append(row, v)
+		}
+		page.Rows = append(page.Rows, row)
+	}
+	return page, nil
+}
+
+// Close removes the temporary table that holds the result.
+func (p *ResultPages) Close(ctx context.Context) error {
+	return p.table.Drop(ctx, internal.NewConn(p.conn.conn, p.conn.tx))
+}
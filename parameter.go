@@ -0,0 +1,18 @@
+package zetasqlite
+
+import (
+	"time"
+
+	"cloud.google.com/go/civil"
+)
+
+// ScheduledQueryParameters returns the parameters provided to the scheduled queries of BigQuery.
+// @run_time is the TIMESTAMP of runTime, and @run_date is the DATE of runTime in UTC.
+// Pass the result to ZetaSQLiteConn.SetDefaultParameters to execute the SQL of scheduled queries as it is.
+func ScheduledQueryParameters(runTime time.Time) map[string]interface{} {
+	runTime = runTime.UTC()
+	return map[string]interface{}{
+		"run_time": runTime,
+		"run_date": civil.DateOf(runTime),
+	}
+}
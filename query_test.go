@@ -61,6 +61,18 @@ UNION ALL
 (WITH toks2 AS (SELECT 2 AS x) SELECT COUNT(x) AS total_rows FROM toks2 WHERE x > 0 HAVING total_rows >= 0)`,
 			expectedRows: [][]interface{}{{int64(1)}, {int64(1)}},
 		},
+		{
+			name: "nested with scan",
+			query: `WITH a AS (
+  WITH b AS (
+    WITH c AS (SELECT 1 AS x)
+    SELECT x + 1 AS x FROM c
+  )
+  SELECT x + 1 AS x FROM b
+)
+SELECT x, (WITH d AS (SELECT x * 10 AS y FROM a) SELECT y FROM d) FROM (WITH e AS (SELECT x FROM a) SELECT x FROM e)`,
+			expectedRows: [][]interface{}{{int64(3), int64(30)}},
+		},
 		// priority 2 operator
 		{
 			name:         "unary plus operator",
@@ -110,6 +122,11 @@ UNION ALL
 			query:        "SELECT 1 - 2",
 			expectedRows: [][]interface{}{{int64(-1)}},
 		},
+		{
+			name:         "unary minus operator with column",
+			query:        "SELECT -x, -CAST(x AS FLOAT64), -CAST(x AS NUMERIC) FROM UNNEST([1, -2]) AS x",
+			expectedRows: [][]interface{}{{int64(-1), float64(-1), "-1"}, {int64(2), float64(2), "2"}},
+		},
 		// priority 5 operator
 		{
 			name:         "left shift operator",
@@ -227,6 +244,18 @@ UNION ALL
 			// When left-hand side is null, null is always returned
 			expectedRows: [][]interface{}{{true, nil, nil}},
 		},
+		{
+			name:         "in operator with struct",
+			query:        `SELECT (1, 2) IN ((1, 2), (3, 4)), (1, 3) IN ((1, 2), (3, 4)), STRUCT(1 AS a, 2 AS b) IN (STRUCT(1 AS x, 2 AS y))`,
+			expectedRows: [][]interface{}{{true, false, true}},
+		},
+		{
+			name: "in operator with struct columns",
+			query: `
+SELECT a, b FROM UNNEST([STRUCT(1 AS a, 2 AS b), STRUCT(3 AS a, 5 AS b)])
+WHERE (a, b) IN ((1, 2), (3, 4))`,
+			expectedRows: [][]interface{}{{int64(1), int64(2)}},
+		},
 		{
 			name:         "is null operator",
 			query:        `SELECT NULL IS NULL`,
@@ -454,6 +483,19 @@ FROM UNNEST([1, 2, 3, 4]) AS val`,
 				{int64(4), "four"},
 			},
 		},
+		{
+			name: "case-when with int64 and float64 branches",
+			query: `
+SELECT
+  CASE WHEN val > 1 THEN val ELSE 0.5 END,
+  CASE val WHEN 1 THEN NULL ELSE val END,
+  IF(val > 1, val, 1.5)
+FROM UNNEST([1, 2]) AS val`,
+			expectedRows: [][]interface{}{
+				{float64(0.5), nil, float64(1.5)},
+				{float64(2), int64(2), float64(2)},
+			},
+		},
 		{
 			name:         "coalesce",
 			query:        `SELECT COALESCE('A', 'B', 'C')`,
@@ -469,6 +511,34 @@ FROM UNNEST([1, 2, 3, 4]) AS val`,
 			query:        `SELECT COALESCE(NULL, NULL, NULL)`,
 			expectedRows: [][]interface{}{{nil}},
 		},
+		{
+			name: "conditional functions with date",
+			query: `
+SELECT
+  DATE_ADD(COALESCE(d, DATE '2020-01-01'), INTERVAL 1 DAY),
+  COALESCE(d, '2021-01-01'),
+  FORMAT_DATE('%Y/%m/%d', IFNULL(d, '2021-02-03')),
+  NULLIF(d, DATE '2022-01-01')
+FROM UNNEST([DATE '2022-01-01', NULL]) AS d`,
+			expectedRows: [][]interface{}{
+				{"2022-01-02", "2022-01-01", "2022/01/01", nil},
+				{"2020-01-02", "2021-01-01", "2021/02/03", nil},
+			},
+		},
+		{
+			name: "conditional functions with timestamp",
+			query: `
+SELECT
+  TIMESTAMP_ADD(COALESCE(ts, TIMESTAMP '2020-01-01 00:00:00+00'), INTERVAL 1 HOUR),
+  IF(ts IS NULL, TIMESTAMP '2021-01-01 00:00:00+00', ts)
+FROM UNNEST([CAST(NULL AS TIMESTAMP)]) AS ts`,
+			expectedRows: [][]interface{}{
+				{
+					createTimestampFormatFromString("2020-01-01 01:00:00+00"),
+					createTimestampFormatFromString("2021-01-01 00:00:00+00"),
+				},
+			},
+		},
 		{
 			name:         "if return int64",
 			query:        `SELECT IF("a" = "b", 1, 2)`,
@@ -542,6 +612,51 @@ SELECT * FROM sub2`,
 				{[]interface{}{"c", "d"}},
 			},
 		},
+		{
+			name: "with recursive",
+			query: `
+WITH RECURSIVE numbers AS (
+  SELECT 1 AS n, 'a' AS s
+  UNION ALL
+  SELECT n + 1, CONCAT(s, 'a') FROM numbers WHERE n < 4
+)
+SELECT n, s FROM numbers ORDER BY n`,
+			expectedRows: [][]interface{}{
+				{int64(1), "a"},
+				{int64(2), "aa"},
+				{int64(3), "aaa"},
+				{int64(4), "aaaa"},
+			},
+		},
+		{
+			name: "with recursive join and union distinct",
+			query: `
+WITH RECURSIVE
+  edges AS (
+    SELECT 1 AS src, 2 AS dst UNION ALL
+    SELECT 2, 3 UNION ALL
+    SELECT 3, 1 UNION ALL
+    SELECT 3, 4
+  ),
+  reachable AS (
+    SELECT 1 AS node
+    UNION DISTINCT
+    SELECT edges.dst FROM reachable JOIN edges ON reachable.node = edges.src
+  )
+SELECT node FROM reachable ORDER BY node`,
+			expectedRows: [][]interface{}{{int64(1)}, {int64(2)}, {int64(3)}, {int64(4)}},
+		},
+		{
+			name: "with recursive exceeds maximum iterations",
+			query: `
+WITH RECURSIVE numbers AS (
+  SELECT 1 AS n
+  UNION ALL
+  SELECT n + 1 FROM numbers
+)
+SELECT MAX(n) FROM numbers`,
+			expectedErr: "recursive query exceeded the maximum number of iterations (500). add the termination condition to the recursive term or increase the limit",
+		},
 		{
 			name: "field access operator",
 			query: `
@@ -556,6 +671,56 @@ SELECT t.customer.address.country FROM orders AS t`,
 			query:        `SELECT CURRENT_TIMESTAMP() AS ts, STRUCT(NULL AS a, FALSE AS b).b AS b`,
 			expectedRows: [][]interface{}{{createTimestampFormatFromTime(now.UTC()), false}},
 		},
+		{
+			name:  "typed struct constructor with array field",
+			query: `SELECT STRUCT<a ARRAY<INT64>>([1, 2]) AS s, STRUCT<a ARRAY<INT64>>([]) AS empty`,
+			expectedRows: [][]interface{}{
+				{
+					[]map[string]interface{}{{"a": []interface{}{int64(1), int64(2)}}},
+					[]map[string]interface{}{{"a": []interface{}{}}},
+				},
+			},
+		},
+		{
+			name:  "typed struct constructor with array of struct field",
+			query: `SELECT STRUCT<id INT64, tags ARRAY<STRUCT<k STRING, v FLOAT64>>>(1, [('x', 1), ('y', 2.5)]) AS s`,
+			expectedRows: [][]interface{}{
+				{
+					[]map[string]interface{}{
+						{"id": int64(1)},
+						{"tags": []interface{}{
+							[]map[string]interface{}{{"k": "x"}, {"v": float64(1)}},
+							[]map[string]interface{}{{"k": "y"}, {"v": 2.5}},
+						}},
+					},
+				},
+			},
+		},
+		{
+			name:  "typed struct constructor with nested non literal fields",
+			query: `SELECT STRUCT<a ARRAY<INT64>, b STRUCT<c ARRAY<STRING>>>([x, x + 1], STRUCT([CAST(x AS STRING)])) AS s FROM UNNEST([1]) AS x`,
+			expectedRows: [][]interface{}{
+				{
+					[]map[string]interface{}{
+						{"a": []interface{}{int64(1), int64(2)}},
+						{"b": []map[string]interface{}{{"c": []interface{}{"1"}}}},
+					},
+				},
+			},
+		},
+		{
+			name: "typed struct constructor with anonymous fields",
+			query: `
+CREATE TEMP FUNCTION Identity(s ANY TYPE) AS (s);
+SELECT STRUCT<INT64, ARRAY<STRING>>(1, ['a']), Identity(STRUCT<INT64, ARRAY<STRING>>(2, ['b', 'c']));
+`,
+			expectedRows: [][]interface{}{
+				{
+					[]map[string]interface{}{{"": int64(1)}, {"": []interface{}{"a"}}},
+					[]map[string]interface{}{{"": int64(2)}, {"": []interface{}{"b", "c"}}},
+				},
+			},
+		},
 		{
 			name: "array index access operator",
 			query: `
@@ -648,6 +813,144 @@ FROM Items`,
 				{"banana", "apple"},
 			},
 		},
+		{
+			name:         "count distinct numeric",
+			query:        `SELECT COUNT(DISTINCT n) FROM UNNEST([NUMERIC '1', NUMERIC '1.00', NUMERIC '2']) AS n`,
+			expectedRows: [][]interface{}{{int64(2)}},
+		},
+		{
+			name:         "count distinct signed zero",
+			query:        `SELECT COUNT(DISTINCT f) FROM UNNEST([0.0, -0.0, 1.0]) AS f`,
+			expectedRows: [][]interface{}{{int64(2)}},
+		},
+		{
+			name:         "count distinct struct",
+			query:        `SELECT COUNT(DISTINCT s) FROM UNNEST([STRUCT(NUMERIC '1' AS n, 0.0 AS f), (NUMERIC '1.00', -0.0), (NUMERIC '2', 0.0)]) AS s`,
+			expectedRows: [][]interface{}{{int64(2)}},
+		},
+		{
+			name:         "count distinct array",
+			query:        `SELECT COUNT(DISTINCT a) FROM (SELECT [0.0, 1.0] AS a UNION ALL SELECT [-0.0, 1.0] UNION ALL SELECT [1.0, 0.0])`,
+			expectedRows: [][]interface{}{{int64(2)}},
+		},
+		{
+			name:  "select distinct array",
+			query: `SELECT DISTINCT a FROM (SELECT [0.0, 1.0] AS a UNION ALL SELECT [-0.0, 1.0] UNION ALL SELECT [1.0, 0.0]) ORDER BY ARRAY_LENGTH(a), a[OFFSET(0)]`,
+			expectedRows: [][]interface{}{
+				{[]interface{}{float64(0), float64(1)}},
+				{[]interface{}{float64(1), float64(0)}},
+			},
+		},
+		{
+			name:         "order by expression of alias",
+			query:        `SELECT UPPER(x) AS name FROM UNNEST(["b", "C", "a"]) AS x ORDER BY LOWER(name) DESC`,
+			expectedRows: [][]interface{}{{"C"}, {"B"}, {"A"}},
+		},
+		{
+			name:         "order by expression of alias shadowing column",
+			query:        `SELECT -x AS x FROM UNNEST([2, 3, 1]) AS x ORDER BY ABS(x)`,
+			expectedRows: [][]interface{}{{int64(-1)}, {int64(-2)}, {int64(-3)}},
+		},
+		{
+			name:         "order by expression of aggregate alias",
+			query:        `SELECT x, COUNT(*) AS cnt FROM UNNEST(["a", "b", "b", "c", "c", "c"]) AS x GROUP BY x ORDER BY -cnt, LOWER(x)`,
+			expectedRows: [][]interface{}{{"c", int64(3)}, {"b", int64(2)}, {"a", int64(1)}},
+		},
+		{
+			name:         "order by expression of alias with distinct",
+			query:        `SELECT DISTINCT UPPER(x) AS name FROM UNNEST(["b", "a", "b"]) AS x ORDER BY CONCAT(name, "!") DESC`,
+			expectedRows: [][]interface{}{{"B"}, {"A"}},
+		},
+		{
+			name:         "order by expression of alias after union all",
+			query:        `SELECT "B" AS name UNION ALL SELECT "a" UNION ALL SELECT "C" ORDER BY LOWER(name)`,
+			expectedRows: [][]interface{}{{"a"}, {"B"}, {"C"}},
+		},
+		{
+			name:         "group by array",
+			query:        `SELECT ARRAY_LENGTH(a), COUNT(*) FROM (SELECT [NUMERIC '1', 2] AS a UNION ALL SELECT [NUMERIC '1.00', 2] UNION ALL SELECT [NUMERIC '2']) GROUP BY a ORDER BY 1`,
+			expectedRows: [][]interface{}{{int64(1), int64(1)}, {int64(2), int64(2)}},
+		},
+		{
+			name: "group by array with null elements",
+			query: `
+SELECT ARRAY_LENGTH(a), COUNT(*) FROM (
+  SELECT [b''] AS a
+  UNION ALL SELECT CAST([] AS ARRAY<BYTES>)
+  UNION ALL SELECT [b'']
+  UNION ALL SELECT [CAST(NULL AS BYTES)]
+  UNION ALL SELECT [b'\x9e\xe9\x65']
+) GROUP BY a ORDER BY 1, 2`,
+			expectedRows: [][]interface{}{
+				{int64(0), int64(1)},
+				{int64(1), int64(1)},
+				{int64(1), int64(1)},
+				{int64(1), int64(2)},
+			},
+		},
+		{
+			name: "group by struct with null fields",
+			query: `
+SELECT s IS NULL, s.a, TO_HEX(s.b), COUNT(*) FROM (
+  SELECT STRUCT(1 AS a, b'\x9e\xe9\x65' AS b) AS s
+  UNION ALL SELECT STRUCT(1, NULL)
+  UNION ALL SELECT STRUCT(1, CAST(NULL AS BYTES))
+  UNION ALL SELECT STRUCT(NULL, NULL)
+  UNION ALL SELECT NULL
+) GROUP BY s ORDER BY 1, 2, 3`,
+			expectedRows: [][]interface{}{
+				{false, nil, nil, int64(1)},
+				{false, int64(1), nil, int64(2)},
+				{false, int64(1), "9ee965", int64(1)},
+				{true, nil, nil, int64(1)},
+			},
+		},
+		{
+			name:         "in unnest with array",
+			query:        `SELECT 1 IN UNNEST([1, 2]), 3 IN UNNEST([1, 2])`,
+			expectedRows: [][]interface{}{{true, false}},
+		},
+		{
+			name:        "array equality is not supported",
+			query:       `SELECT [1, 2] = [1, 2]`,
+			expectedErr: "No matching signature for operator = for argument types: ARRAY<INT64>, ARRAY<INT64>",
+		},
+		{
+			name:        "array in list is not supported",
+			query:       `SELECT [1] IN ([1], [2])`,
+			expectedErr: "No matching signature for operator IN",
+		},
+		{
+			name:        "struct equality with array field is not supported",
+			query:       `SELECT STRUCT([1] AS a) = STRUCT([1] AS a)`,
+			expectedErr: "No matching signature for operator =",
+		},
+		{
+			name:  "any_value with struct",
+			query: `SELECT ANY_VALUE(v) FROM UNNEST([STRUCT(NULL AS id, "" AS name), (1, "alice"), (2, "bob")]) AS v WHERE v.id IS NOT NULL`,
+			expectedRows: [][]interface{}{
+				{
+					[]map[string]interface{}{
+						{"id": int64(1)},
+						{"name": "alice"},
+					},
+				},
+			},
+		},
+		{
+			name:         "any_value with array",
+			query:        `SELECT ANY_VALUE(v) FROM (SELECT [1, 2] AS v UNION ALL SELECT [3])`,
+			expectedRows: [][]interface{}{{[]interface{}{int64(1), int64(2)}}},
+		},
+		{
+			name:  "any_value with window ignores null",
+			query: `SELECT x, ANY_VALUE(y) OVER (ORDER BY x ROWS BETWEEN CURRENT ROW AND 1 FOLLOWING) FROM UNNEST([STRUCT(1 AS x, CAST(NULL AS STRING) AS y), (2, "b"), (3, NULL)])`,
+			expectedRows: [][]interface{}{
+				{int64(1), "b"},
+				{int64(2), "b"},
+				{int64(3), nil},
+			},
+		},
 		{
 			name:  "array_agg",
 			query: `SELECT ARRAY_AGG(x) AS array_agg FROM UNNEST([2, 1,-2, 3, -2, 1, 2]) AS x`,
@@ -718,6 +1021,67 @@ FROM Items`,
 				{int64(3), []interface{}{int64(1), int64(1), int64(2), int64(-2), int64(-2), int64(2), int64(3)}},
 			},
 		},
+		{
+			name:  "array_agg with window and ignore nulls",
+			query: `SELECT id, ARRAY_AGG(x IGNORE NULLS) OVER (ORDER BY id) FROM UNNEST([10, NULL, 30]) AS x WITH OFFSET AS id`,
+			expectedRows: [][]interface{}{
+				{int64(0), []interface{}{int64(10)}},
+				{int64(1), []interface{}{int64(10)}},
+				{int64(2), []interface{}{int64(10), int64(30)}},
+			},
+		},
+		{
+			name:        "array_agg with window and nulls",
+			query:       `SELECT id, ARRAY_AGG(x) OVER (ORDER BY id) FROM UNNEST([10, NULL, 30]) AS x WITH OFFSET AS id`,
+			expectedErr: "ARRAY_AGG: input value must be not null",
+		},
+		{
+			name:        "array_agg with window and order by in arguments",
+			query:       `SELECT ARRAY_AGG(x ORDER BY x) OVER () FROM UNNEST([2, 1, 3]) AS x`,
+			expectedErr: "ORDER BY in arguments is not supported on analytic functions",
+		},
+		{
+			name:        "window frame with exclusion",
+			query:       `SELECT x, SUM(x) OVER (ORDER BY x ROWS BETWEEN 1 PRECEDING AND 1 FOLLOWING EXCLUDE CURRENT ROW) FROM UNNEST([1, 2, 3]) AS x`,
+			expectedErr: "window frame exclusion ( EXCLUDE CURRENT ROW / GROUP / TIES / NO OTHERS ) is not supported",
+		},
+		{
+			name:        "count with filter clause",
+			query:       `SELECT COUNT(*) FILTER (WHERE x > 1) FROM UNNEST([1, 2, 3]) AS x`,
+			expectedErr: "aggregate FILTER (WHERE ...) clause is not supported. use COUNTIF or IF expression in the aggregate arguments instead",
+		},
+		{
+			name:        "array_agg with window and limit in arguments",
+			query:       `SELECT ARRAY_AGG(x LIMIT 2) OVER () FROM UNNEST([2, 1, 3]) AS x`,
+			expectedErr: "LIMIT in arguments is not supported on analytic functions",
+		},
+		{
+			name:  "array_agg with window order by and frame",
+			query: `SELECT x, ARRAY_AGG(x) OVER (ORDER BY x DESC ROWS BETWEEN 1 PRECEDING AND CURRENT ROW) FROM UNNEST([2, 1, 3]) AS x`,
+			expectedRows: [][]interface{}{
+				{int64(3), []interface{}{int64(3)}},
+				{int64(2), []interface{}{int64(3), int64(2)}},
+				{int64(1), []interface{}{int64(2), int64(1)}},
+			},
+		},
+		{
+			name:  "window frame without order by follows array order",
+			query: `SELECT x, SUM(x) OVER (ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW) FROM UNNEST([3, 1, 2]) AS x`,
+			expectedRows: [][]interface{}{
+				{int64(3), int64(3)},
+				{int64(1), int64(4)},
+				{int64(2), int64(6)},
+			},
+		},
+		{
+			name:  "window function without order by follows json array order",
+			query: `SELECT v, STRING_AGG(v) OVER () FROM UNNEST(JSON_VALUE_ARRAY(JSON '["c", "a", "b"]')) AS v WHERE v != "x"`,
+			expectedRows: [][]interface{}{
+				{"c", "c,a,b"},
+				{"a", "c,a,b"},
+				{"b", "c,a,b"},
+			},
+		},
 		{
 			name: "array_concat_agg",
 			query: `
@@ -946,6 +1310,31 @@ SELECT LOGICAL_OR(x) AS logical_or FROM toks`,
 			query:        `SELECT STRING_AGG(DISTINCT fruit, " & " ORDER BY fruit DESC LIMIT 2) AS string_agg FROM UNNEST(["apple", "pear", "banana", "pear"]) AS fruit`,
 			expectedRows: [][]interface{}{{"pear & banana"}},
 		},
+		{
+			name:         "string_agg with empty delimiter",
+			query:        `SELECT STRING_AGG(fruit, "") AS string_agg FROM UNNEST(["apple", "pear"]) AS fruit`,
+			expectedRows: [][]interface{}{{"applepear"}},
+		},
+		{
+			name:         "string_agg with bytes",
+			query:        `SELECT STRING_AGG(b, b"|") AS string_agg FROM UNNEST([b"a", NULL, b"b", b"c"]) AS b`,
+			expectedRows: [][]interface{}{{"YXxifGM="}},
+		},
+		{
+			name:         "string_agg with bytes and default delimiter",
+			query:        `SELECT STRING_AGG(b) AS string_agg FROM UNNEST([b"a", b"b"]) AS b`,
+			expectedRows: [][]interface{}{{"YSxi"}},
+		},
+		{
+			name:        "string_agg with mismatched delimiter",
+			query:       `SELECT STRING_AGG(b, "|") FROM UNNEST([b"a", b"b"]) AS b`,
+			expectedErr: "No matching signature for aggregate function STRING_AGG",
+		},
+		{
+			name:        "string_agg with window and order by in arguments",
+			query:       `SELECT STRING_AGG(fruit, " & " ORDER BY fruit) OVER () FROM UNNEST(["apple", "pear"]) AS fruit`,
+			expectedErr: "ORDER BY in arguments is not supported on analytic functions",
+		},
 		{
 			// TODO: add NULL back to the unnest once ORDER BY does not crash on NULL
 			name:  "string_agg with window",
@@ -1002,6 +1391,21 @@ SELECT LOGICAL_OR(x) AS logical_or FROM toks`,
 			query:        `SELECT SUM(x) AS sum FROM UNNEST([]) AS x`,
 			expectedRows: [][]interface{}{{nil}},
 		},
+		{
+			name:         "sum with int64 intermediate overflow",
+			query:        `SELECT SUM(x), AVG(x) FROM UNNEST([9223372036854775807, 1, -2]) AS x`,
+			expectedRows: [][]interface{}{{int64(9223372036854775806), float64(3074457345618258602)}},
+		},
+		{
+			name:        "sum with int64 overflow",
+			query:       `SELECT SUM(x) FROM UNNEST([9223372036854775807, 1]) AS x`,
+			expectedErr: "int64 overflow",
+		},
+		{
+			name:        "window sum with int64 overflow",
+			query:       `SELECT SUM(x) OVER () FROM UNNEST([9223372036854775807, 1]) AS x`,
+			expectedErr: "int64 overflow",
+		},
 		{
 			name:        "safe sum",
 			query:       `SELECT SAFE.SUM(x) AS sum FROM UNNEST([1, 2, 3, 4, 5, 4, 3, 2, 1]) AS x`,
@@ -1742,6 +2146,24 @@ SELECT dt, letter, ROW_NUMBER() OVER (ORDER BY dt, letter) AS rn FROM toks
 				{"2024-02-01", nil, int64(2)},
 			},
 		},
+		{
+			name: "window function over ordered subquery with limit and offset",
+			query: `
+SELECT x, ROW_NUMBER() OVER (ORDER BY x DESC) AS rn
+FROM (SELECT x FROM UNNEST([5, 3, 1, 4, 2]) AS x ORDER BY x LIMIT 3 OFFSET 1)`,
+			expectedRows: [][]interface{}{
+				{int64(4), int64(1)},
+				{int64(3), int64(2)},
+				{int64(2), int64(3)},
+			},
+		},
+		{
+			name: "window function without order over ordered subquery with limit",
+			query: `
+SELECT SUM(x) OVER () AS total
+FROM (SELECT x FROM UNNEST([5, 3, 1, 4, 2]) AS x ORDER BY x DESC LIMIT 2)`,
+			expectedRows: [][]interface{}{{int64(9)}, {int64(9)}},
+		},
 		{
 			name: `percentile_cont`,
 			query: `
@@ -2271,6 +2693,43 @@ FROM finishers`,
 				{int64(1)}, {int64(0)}, {int64(-1)},
 			},
 		},
+		{
+			name:         "safe math functions",
+			query:        `SELECT SAFE_ADD(1, 2), SAFE_SUBTRACT(1, 2), SAFE_MULTIPLY(3, 4), SAFE_NEGATE(5), SAFE_DIVIDE(6, 3), SAFE_DIVIDE(1, 0), SAFE_ADD(1.5, 2.5)`,
+			expectedRows: [][]interface{}{{int64(3), int64(-1), int64(12), int64(-5), float64(2), nil, float64(4)}},
+		},
+		{
+			name: "safe math functions with int64 overflow",
+			query: `
+SELECT SAFE_ADD(x, 1), SAFE_SUBTRACT(-x, 2), SAFE_MULTIPLY(x, 2), SAFE_NEGATE(-x - 1), SAFE_ADD(x, -1)
+FROM UNNEST([9223372036854775807]) AS x`,
+			expectedRows: [][]interface{}{{nil, nil, nil, nil, int64(9223372036854775806)}},
+		},
+		{
+			name:        "add operator with int64 overflow",
+			query:       `SELECT x + 1 FROM UNNEST([9223372036854775807]) AS x`,
+			expectedErr: "int64 overflow: 9223372036854775807 + 1",
+		},
+		{
+			name:        "sub operator with int64 overflow",
+			query:       `SELECT x - 2 FROM UNNEST([-9223372036854775807]) AS x`,
+			expectedErr: "int64 overflow: -9223372036854775807 - 2",
+		},
+		{
+			name:        "mul operator with int64 overflow",
+			query:       `SELECT x * -2 FROM UNNEST([-9223372036854775807]) AS x`,
+			expectedErr: "int64 overflow: -9223372036854775807 * -2",
+		},
+		{
+			name:        "unary minus operator with int64 overflow",
+			query:       `SELECT -(x - 1) FROM UNNEST([-9223372036854775807]) AS x`,
+			expectedErr: "int64 overflow: --9223372036854775808",
+		},
+		{
+			name:         "safe div with int64 overflow",
+			query:        `SELECT SAFE.DIV(x - 1, -1), SAFE.DIV(x, -1) FROM UNNEST([-9223372036854775807]) AS x`,
+			expectedRows: [][]interface{}{{nil, int64(9223372036854775807)}},
+		},
 
 		{
 			name: "bit_count",
@@ -2316,6 +2775,100 @@ ORDER BY offset DESC;`,
 				{[]interface{}{int64(1), int64(2), int64(3)}},
 			},
 		},
+		{
+			name: "correlated array subquery",
+			query: `
+WITH t1 AS (SELECT 1 AS id UNION ALL SELECT 2 UNION ALL SELECT 3),
+t2 AS (SELECT 1 AS id, 'a' AS x UNION ALL SELECT 1, 'b' UNION ALL SELECT 2, 'c')
+SELECT id, ARRAY(SELECT x FROM t2 WHERE t2.id = t1.id ORDER BY x) FROM t1 ORDER BY id`,
+			expectedRows: [][]interface{}{
+				{int64(1), []interface{}{"a", "b"}},
+				{int64(2), []interface{}{"c"}},
+				{int64(3), []interface{}{}},
+			},
+		},
+		{
+			name: "correlated array subquery with computed column",
+			query: `
+WITH t1 AS (SELECT 1 AS id UNION ALL SELECT 2),
+t2 AS (SELECT 2 AS id, 10 AS x UNION ALL SELECT 3, 20 UNION ALL SELECT 3, 30)
+SELECT k, ARRAY(SELECT x * k FROM t2 WHERE t2.id = k ORDER BY x) AS arr
+FROM (SELECT id + 1 AS k FROM t1) ORDER BY k`,
+			expectedRows: [][]interface{}{
+				{int64(2), []interface{}{int64(20)}},
+				{int64(3), []interface{}{int64(60), int64(90)}},
+			},
+		},
+		{
+			name: "correlated array subquery with unnest",
+			query: `
+WITH t AS (SELECT 1 AS id, [3, 1, 2] AS arr UNION ALL SELECT 2, [5, 4])
+SELECT id, ARRAY(SELECT v FROM UNNEST(t.arr) AS v WHERE v > id ORDER BY v) FROM t ORDER BY id`,
+			expectedRows: [][]interface{}{
+				{int64(1), []interface{}{int64(2), int64(3)}},
+				{int64(2), []interface{}{int64(4), int64(5)}},
+			},
+		},
+		{
+			name:  "unnest with element filter",
+			query: `SELECT v FROM UNNEST([5, 1, NULL, 3, 8]) AS v WHERE v > 2 AND 6 >= v ORDER BY v`,
+			expectedRows: [][]interface{}{
+				{int64(3)},
+				{int64(5)},
+			},
+		},
+		{
+			name: "unnest with element filter and join condition",
+			query: `
+WITH t AS (SELECT 1 AS id, [1, 2, 3] AS arr UNION ALL SELECT 2, [4, 5])
+SELECT id, v FROM t, UNNEST(arr) AS v WHERE v != 2 AND v < id + 3 ORDER BY id, v`,
+			expectedRows: [][]interface{}{
+				{int64(1), int64(1)},
+				{int64(1), int64(3)},
+				{int64(2), int64(4)},
+			},
+		},
+		{
+			name:  "unnest with non-deterministic element filter",
+			query: `SELECT COUNT(*) BETWEEN 1 AND 399 FROM UNNEST(ARRAY(SELECT 0 FROM UNNEST(GENERATE_ARRAY(1, 400)))) AS v WHERE v = CAST(FLOOR(RAND() * 2) AS INT64)`,
+			expectedRows: [][]interface{}{
+				{true},
+			},
+		},
+		{
+			name: "correlated exists subquery",
+			query: `
+WITH t1 AS (SELECT 1 AS id UNION ALL SELECT 2 UNION ALL SELECT 3 UNION ALL SELECT NULL),
+t2 AS (SELECT 1 AS id, 10 AS v UNION ALL SELECT 2, -1 UNION ALL SELECT NULL, 10)
+SELECT
+  id,
+  EXISTS (SELECT 1 FROM t2 WHERE t2.id = t1.id AND v > 0),
+  NOT EXISTS (SELECT 1 FROM t2 WHERE t1.id = t2.id)
+FROM t1 ORDER BY id`,
+			expectedRows: [][]interface{}{
+				{nil, false, true},
+				{int64(1), true, false},
+				{int64(2), false, false},
+				{int64(3), false, true},
+			},
+		},
+		{
+			name: "correlated in subquery",
+			query: `
+WITH t1 AS (SELECT 1 AS id, 'a' AS x UNION ALL SELECT 2, 'b' UNION ALL SELECT 3, CAST(NULL AS STRING) UNION ALL SELECT 4, 'e'),
+t2 AS (SELECT 1 AS id, 'a' AS x UNION ALL SELECT 2, 'c' UNION ALL SELECT 3, 'd' UNION ALL SELECT 3, NULL)
+SELECT
+  id,
+  x IN (SELECT x FROM t2 WHERE t2.id = t1.id),
+  x NOT IN (SELECT x FROM t2 WHERE t2.id = t1.id)
+FROM t1 ORDER BY id`,
+			expectedRows: [][]interface{}{
+				{int64(1), true, false},
+				{int64(2), false, true},
+				{int64(3), nil, nil},
+				{int64(4), false, true},
+			},
+		},
 		// Regression tests for goccy/go-zetasqlite#176
 		{
 			name: "array scan left outer join",
@@ -2336,6 +2889,76 @@ INNER JOIN unnest(['lettuce']) in_stock_items ON in_stock_items = item;`,
 				{"lettuce", true},
 			},
 		},
+		{
+			name: "join on in unnest",
+			query: `
+WITH t1 AS (SELECT 1 AS id, [10, 20] AS ids UNION ALL SELECT 2, [30]),
+t2 AS (SELECT 10 AS id, 'a' AS name UNION ALL SELECT 20, 'b' UNION ALL SELECT 30, 'c' UNION ALL SELECT 40, 'd')
+SELECT t1.id, t2.name FROM t1 JOIN t2 ON t2.id IN UNNEST(t1.ids) ORDER BY t2.name`,
+			expectedRows: [][]interface{}{
+				{int64(1), "a"},
+				{int64(1), "b"},
+				{int64(2), "c"},
+			},
+		},
+		{
+			name: "left join unnest with offset in join condition",
+			query: `
+WITH t AS (SELECT 1 AS id, [1, 2, 3] AS arr)
+SELECT id, x, off FROM t LEFT JOIN UNNEST(arr) AS x WITH OFFSET AS off ON x > 1 AND off < 2`,
+			expectedRows: [][]interface{}{
+				{int64(1), int64(2), int64(1)},
+			},
+		},
+		{
+			name:  "join unnest with unnest",
+			query: `SELECT a, b FROM UNNEST([1, 2, 3]) AS a JOIN UNNEST([2, 3, 4]) AS b ON a = b ORDER BY a`,
+			expectedRows: [][]interface{}{
+				{int64(2), int64(2)},
+				{int64(3), int64(3)},
+			},
+		},
+		{
+			name: "cross join unnest with nested arrays",
+			query: `
+WITH t AS (
+  SELECT 1 AS id, [STRUCT('a' AS name, [STRUCT([1, 2] AS vals), STRUCT([3] AS vals)] AS items)] AS groups
+  UNION ALL
+  SELECT 2, [STRUCT('b' AS name, [STRUCT([4] AS vals)] AS items), STRUCT('c' AS name, [STRUCT(CAST([] AS ARRAY<INT64>) AS vals)] AS items)]
+)
+SELECT id, g.name, item_offset, v
+FROM t CROSS JOIN UNNEST(t.groups) AS g
+CROSS JOIN UNNEST(g.items) AS item WITH OFFSET AS item_offset
+CROSS JOIN UNNEST(item.vals) AS v
+ORDER BY id, g.name, item_offset, v`,
+			expectedRows: [][]interface{}{
+				{int64(1), "a", int64(0), int64(1)},
+				{int64(1), "a", int64(0), int64(2)},
+				{int64(1), "a", int64(1), int64(3)},
+				{int64(2), "b", int64(0), int64(4)},
+			},
+		},
+		{
+			name: "left join unnest with nested arrays",
+			query: `
+WITH t AS (
+  SELECT 1 AS id, [STRUCT('a' AS name, [1, 2] AS vals)] AS groups
+  UNION ALL
+  SELECT 2, [STRUCT('b' AS name, CAST([] AS ARRAY<INT64>) AS vals)]
+  UNION ALL
+  SELECT 3, CAST([] AS ARRAY<STRUCT<name STRING, vals ARRAY<INT64>>>)
+)
+SELECT id, g.name, v
+FROM t LEFT JOIN UNNEST(t.groups) AS g
+LEFT JOIN UNNEST(g.vals) AS v
+ORDER BY id, v`,
+			expectedRows: [][]interface{}{
+				{int64(1), "a", int64(1)},
+				{int64(1), "a", int64(2)},
+				{int64(2), "b", nil},
+				{int64(3), nil, nil},
+			},
+		},
 		{
 			name:  "array function with struct",
 			query: `SELECT ARRAY (SELECT AS STRUCT 1, 2, 3 UNION ALL SELECT AS STRUCT 4, 5, 6) AS new_array`,
@@ -2344,30 +2967,65 @@ INNER JOIN unnest(['lettuce']) in_stock_items ON in_stock_items = item;`,
 					[]interface{}{
 						[]map[string]interface{}{
 							{
-								"": float64(1),
+								"": int64(1),
 							},
 							{
-								"": float64(2),
+								"": int64(2),
 							},
 							{
-								"": float64(3),
+								"": int64(3),
 							},
 						},
 						[]map[string]interface{}{
 							{
-								"": float64(4),
+								"": int64(4),
 							},
 							{
-								"": float64(5),
+								"": int64(5),
 							},
 							{
-								"": float64(6),
+								"": int64(6),
 							},
 						},
 					},
 				},
 			},
 		},
+		{
+			name:  "array function with named struct",
+			query: `SELECT ARRAY (SELECT AS STRUCT 1 AS a, 'x' AS b UNION ALL SELECT AS STRUCT 2 AS c, 'y' AS d) AS new_array`,
+			expectedRows: [][]interface{}{
+				{
+					[]interface{}{
+						[]map[string]interface{}{
+							{"a": int64(1)},
+							{"b": "x"},
+						},
+						[]map[string]interface{}{
+							{"a": int64(2)},
+							{"b": "y"},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:         "select as value subquery",
+			query:        `SELECT (SELECT AS VALUE STRUCT(1 AS a, 'x' AS b)).b`,
+			expectedRows: [][]interface{}{{"x"}},
+		},
+		{
+			name:  "array function with select as value",
+			query: `SELECT ARRAY (SELECT AS VALUE STRUCT(x AS v) FROM UNNEST([1, 2]) AS x ORDER BY x) AS new_array`,
+			expectedRows: [][]interface{}{
+				{
+					[]interface{}{
+						[]map[string]interface{}{{"v": int64(1)}},
+						[]map[string]interface{}{{"v": int64(2)}},
+					},
+				},
+			},
+		},
 		{
 			name:  "array function with multiple array",
 			query: `SELECT ARRAY (SELECT AS STRUCT [1, 2, 3] UNION ALL SELECT AS STRUCT [4, 5, 6]) AS new_array`,
@@ -2377,18 +3035,18 @@ INNER JOIN unnest(['lettuce']) in_stock_items ON in_stock_items = item;`,
 						[]map[string]interface{}{
 							{
 								"": []interface{}{
-									float64(1),
-									float64(2),
-									float64(3),
+									int64(1),
+									int64(2),
+									int64(3),
 								},
 							},
 						},
 						[]map[string]interface{}{
 							{
 								"": []interface{}{
-									float64(4),
-									float64(5),
-									float64(6),
+									int64(4),
+									int64(5),
+									int64(6),
 								},
 							},
 						},
@@ -2693,6 +3351,60 @@ WITH example AS (
 				{[]interface{}{}},
 			},
 		},
+		{
+			name:  "array_zip function",
+			query: `SELECT ARRAY_ZIP([1, 2] AS a, ['x', 'y'] AS b)`,
+			expectedRows: [][]interface{}{
+				{[]interface{}{
+					[]map[string]interface{}{{"a": int64(1)}, {"b": "x"}},
+					[]map[string]interface{}{{"a": int64(2)}, {"b": "y"}},
+				}},
+			},
+		},
+		{
+			name: "array_zip function with path expressions",
+			query: `
+WITH example AS (SELECT [1, 2] AS nums, ['x', 'y'] AS strs)
+SELECT ARRAY_ZIP(t.nums, strs) FROM example AS t`,
+			expectedRows: [][]interface{}{
+				{[]interface{}{
+					[]map[string]interface{}{{"nums": int64(1)}, {"strs": "x"}},
+					[]map[string]interface{}{{"nums": int64(2)}, {"strs": "y"}},
+				}},
+			},
+		},
+		{
+			name:  "array_zip function with truncate mode",
+			query: `SELECT ARRAY_ZIP([1, 2, 3] AS a, ['x', 'y'] AS b, mode => 'TRUNCATE')`,
+			expectedRows: [][]interface{}{
+				{[]interface{}{
+					[]map[string]interface{}{{"a": int64(1)}, {"b": "x"}},
+					[]map[string]interface{}{{"a": int64(2)}, {"b": "y"}},
+				}},
+			},
+		},
+		{
+			name:  "array_zip function with pad mode",
+			query: `SELECT ARRAY_ZIP([1, 2, 3] AS a, ['x', 'y'] AS b, mode => 'PAD')`,
+			expectedRows: [][]interface{}{
+				{[]interface{}{
+					[]map[string]interface{}{{"a": int64(1)}, {"b": "x"}},
+					[]map[string]interface{}{{"a": int64(2)}, {"b": "y"}},
+					[]map[string]interface{}{{"a": int64(3)}, {"b": nil}},
+				}},
+			},
+		},
+		{
+			name:         "array_zip function with null array",
+			query:        `SELECT ARRAY_ZIP([1, 2] AS a, CAST(NULL AS ARRAY<STRING>) AS b)`,
+			expectedRows: [][]interface{}{{nil}},
+		},
+		{
+			name:         "array_zip function with unequal array length in strict mode",
+			query:        `SELECT ARRAY_ZIP([1, 2, 3] AS a, ['x', 'y'] AS b)`,
+			expectedRows: [][]interface{}{},
+			expectedErr:  "Unequal array length in ARRAY_ZIP using STRICT mode",
+		},
 		{
 			name: "group by",
 			query: `
@@ -2925,6 +3637,92 @@ SELECT Roster.LastName, TeamMascot.Mascot FROM Roster FULL JOIN TeamMascot ON Ro
 				{nil, "Mustangs"},
 			},
 		},
+		{
+			name: "full join with using",
+			query: `
+WITH Roster AS
+ (SELECT 'Adams' as LastName, 50 as SchoolID, 1 as Year UNION ALL
+  SELECT 'Buchanan', 52, 1 UNION ALL
+  SELECT 'Coolidge', 52, 2 UNION ALL
+  SELECT 'Davis', 51, 1 UNION ALL
+  SELECT 'Eisenhower', 77, 1),
+ TeamMascot AS
+ (SELECT 50 as SchoolID, 1 as Year, 'Jaguars' as Mascot UNION ALL
+  SELECT 51, 1, 'Knights' UNION ALL
+  SELECT 52, 1, 'Lakers' UNION ALL
+  SELECT 53, 1, 'Mustangs')
+SELECT * FROM Roster FULL JOIN TeamMascot USING (SchoolID, Year) ORDER BY SchoolID, Year
+`,
+			expectedRows: [][]interface{}{
+				{int64(50), int64(1), "Adams", "Jaguars"},
+				{int64(51), int64(1), "Davis", "Knights"},
+				{int64(52), int64(1), "Buchanan", "Lakers"},
+				{int64(52), int64(2), "Coolidge", nil},
+				{int64(53), int64(1), nil, "Mustangs"},
+				{int64(77), int64(1), "Eisenhower", nil},
+			},
+		},
+		{
+			name: "left join with using",
+			query: `
+WITH Roster AS
+ (SELECT 'Adams' as LastName, 50 as SchoolID, 1 as Year UNION ALL
+  SELECT 'Buchanan', 52, 1 UNION ALL
+  SELECT 'Coolidge', 52, 2 UNION ALL
+  SELECT 'Davis', 51, 1 UNION ALL
+  SELECT 'Eisenhower', 77, 1),
+ TeamMascot AS
+ (SELECT 50 as SchoolID, 1 as Year, 'Jaguars' as Mascot UNION ALL
+  SELECT 51, 1, 'Knights' UNION ALL
+  SELECT 52, 1, 'Lakers' UNION ALL
+  SELECT 53, 1, 'Mustangs')
+SELECT * FROM Roster LEFT JOIN TeamMascot USING (SchoolID, Year) ORDER BY SchoolID, Year
+`,
+			expectedRows: [][]interface{}{
+				{int64(50), int64(1), "Adams", "Jaguars"},
+				{int64(51), int64(1), "Davis", "Knights"},
+				{int64(52), int64(1), "Buchanan", "Lakers"},
+				{int64(52), int64(2), "Coolidge", nil},
+				{int64(77), int64(1), "Eisenhower", nil},
+			},
+		},
+		{
+			name: "right join with using",
+			query: `
+WITH Roster AS
+ (SELECT 'Adams' as LastName, 50 as SchoolID, 1 as Year UNION ALL
+  SELECT 'Buchanan', 52, 1 UNION ALL
+  SELECT 'Coolidge', 52, 2 UNION ALL
+  SELECT 'Davis', 51, 1 UNION ALL
+  SELECT 'Eisenhower', 77, 1),
+ TeamMascot AS
+ (SELECT 50 as SchoolID, 1 as Year, 'Jaguars' as Mascot UNION ALL
+  SELECT 51, 1, 'Knights' UNION ALL
+  SELECT 52, 1, 'Lakers' UNION ALL
+  SELECT 53, 1, 'Mustangs')
+SELECT * FROM Roster RIGHT JOIN TeamMascot USING (SchoolID, Year) ORDER BY SchoolID, Year
+`,
+			expectedRows: [][]interface{}{
+				{int64(50), int64(1), "Adams", "Jaguars"},
+				{int64(51), int64(1), "Davis", "Knights"},
+				{int64(52), int64(1), "Buchanan", "Lakers"},
+				{int64(53), int64(1), nil, "Mustangs"},
+			},
+		},
+		{
+			name: "full join with using and nested join",
+			query: `
+WITH a AS (SELECT 1 AS k, 'a1' AS x UNION ALL SELECT 2, 'a2'),
+b AS (SELECT 2 AS k, 'b2' AS y UNION ALL SELECT 3, 'b3'),
+c AS (SELECT 2 AS k, 'c2' AS z UNION ALL SELECT 3, 'c3')
+SELECT k, x, y, z FROM a FULL JOIN (b JOIN c USING (k)) USING (k) ORDER BY k
+`,
+			expectedRows: [][]interface{}{
+				{int64(1), "a1", nil, nil},
+				{int64(2), "a2", "b2", "c2"},
+				{int64(3), nil, "b3", "c3"},
+			},
+		},
 		{
 			name: "qualify",
 			query: `
@@ -2995,9 +3793,43 @@ WITH Produce AS
   UNION ALL SELECT 'lettuce', 10, 'vegetable')
 SELECT item FROM Produce WHERE Produce.category = 'vegetable' QUALIFY RANK() OVER (PARTITION BY category ORDER BY purchases DESC) <= 3`,
 			expectedRows: [][]interface{}{
-				{"kale"},
-				{"lettuce"},
-				{"cabbage"},
+				{"kale"},
+				{"lettuce"},
+				{"cabbage"},
+			},
+		},
+		{
+			name: "qualify row_number deduplication",
+			query: `
+WITH events AS (
+  SELECT 'a' AS k, 1 AS v, TIMESTAMP '2023-01-01 00:00:00+00' AS ts
+  UNION ALL SELECT 'a', 2, TIMESTAMP '2023-01-03 00:00:00+00'
+  UNION ALL SELECT 'a', 3, TIMESTAMP '2023-01-02 00:00:00+00'
+  UNION ALL SELECT 'b', 4, NULL
+  UNION ALL SELECT 'b', 5, TIMESTAMP '2022-12-31 00:00:00+00'
+  UNION ALL SELECT NULL, 6, TIMESTAMP '2023-01-01 00:00:00+00'
+  UNION ALL SELECT NULL, 7, TIMESTAMP '2023-01-05 00:00:00+00'
+)
+SELECT k, v FROM events QUALIFY ROW_NUMBER() OVER (PARTITION BY k ORDER BY ts DESC) = 1 ORDER BY v`,
+			expectedRows: [][]interface{}{
+				{"a", int64(2)},
+				{"b", int64(5)},
+				{nil, int64(7)},
+			},
+		},
+		{
+			name: "qualify row_number deduplication with row number column",
+			query: `
+WITH events AS (
+  SELECT 'a' AS k, 1 AS v, TIMESTAMP '2023-01-01 00:00:00+00' AS ts
+  UNION ALL SELECT 'a', 2, TIMESTAMP '2023-01-03 00:00:00+00'
+  UNION ALL SELECT 'b', 4, NULL
+  UNION ALL SELECT 'b', 5, TIMESTAMP '2022-12-31 00:00:00+00'
+)
+SELECT k, v, ROW_NUMBER() OVER (PARTITION BY k ORDER BY ts ASC NULLS LAST) AS rn FROM events QUALIFY rn <= 1 ORDER BY k`,
+			expectedRows: [][]interface{}{
+				{"a", int64(1), int64(1)},
+				{"b", int64(5), int64(1)},
 			},
 		},
 		{
@@ -3038,6 +3870,35 @@ SELECT item FROM Produce WHERE Produce.category = 'vegetable' QUALIFY RANK() OVE
 			SELECT ARRAY_AGG(CAST(x AS INT64)) FROM toks`,
 			expectedRows: [][]interface{}{{[]any{int64(800), int64(-900), int64(100), int64(0), int64(0)}}},
 		},
+		{
+			name:         "cast array element type",
+			query:        `SELECT CAST(arr AS ARRAY<FLOAT64>), CAST(arr AS ARRAY<STRING>) FROM (SELECT [1, NULL, 3] AS arr)`,
+			expectedRows: [][]interface{}{{[]interface{}{float64(1), nil, float64(3)}, []interface{}{"1", nil, "3"}}},
+		},
+		{
+			name:  "cast struct field-wise",
+			query: `SELECT CAST(s AS STRUCT<x FLOAT64, y INT64>) FROM (SELECT STRUCT(1 AS a, "2" AS b) AS s)`,
+			expectedRows: [][]interface{}{
+				{
+					[]map[string]interface{}{
+						{"x": float64(1)},
+						{"y": int64(2)},
+					},
+				},
+			},
+		},
+		{
+			name:  "cast array of struct",
+			query: `SELECT CAST(arr AS ARRAY<STRUCT<id STRING>>) FROM (SELECT [STRUCT(1 AS v), STRUCT(2 AS v)] AS arr)`,
+			expectedRows: [][]interface{}{
+				{
+					[]interface{}{
+						[]map[string]interface{}{{"id": "1"}},
+						[]map[string]interface{}{{"id": "2"}},
+					},
+				},
+			},
+		},
 
 		// hash functions
 		{
@@ -3288,6 +4149,31 @@ SELECT characters, CHARACTER_LENGTH(characters) FROM example`,
 			query:        `SELECT FROM_BASE64('/+A='), FROM_BASE64(NULL)`,
 			expectedRows: [][]interface{}{{"/+A=", nil}},
 		},
+		{
+			name:         "from_base64 composes with bytes functions",
+			query:        `SELECT TO_HEX(FROM_BASE64('/+A=')), CAST(FROM_BASE64('YWJj') AS STRING), LENGTH(FROM_BASE64('YWJj')), TO_BASE64(FROM_BASE64('/+A='))`,
+			expectedRows: [][]interface{}{{"ffe0", "abc", int64(3), "/+A="}},
+		},
+		{
+			name:         "bytes literals with escapes and prefixes",
+			query:        `SELECT TO_HEX(b'it\'s'), TO_HEX(rb'\d+'), TO_HEX(B"\x00\xff"), TO_HEX(b"""a"b"""), TO_HEX(b'\101\n'), TO_HEX(b'')`,
+			expectedRows: [][]interface{}{{"69742773", "5c642b", "00ff", "612262", "410a", ""}},
+		},
+		{
+			name:         "raw string literals",
+			query:        `SELECT r'\d+', R"a\nb", REGEXP_CONTAINS('abc123', r'\d+'), LENGTH(r'\n')`,
+			expectedRows: [][]interface{}{{`\d+`, `a\nb`, true, int64(2)}},
+		},
+		{
+			name:        "cast invalid utf-8 bytes to string",
+			query:       `SELECT CAST(FROM_BASE64('/+A=') AS STRING)`,
+			expectedErr: `failed to cast BYTES to STRING: invalid UTF-8 sequence "\xff\xe0"`,
+		},
+		{
+			name:         "safe cast invalid utf-8 bytes to string",
+			query:        `SELECT SAFE_CAST(FROM_BASE64('/+A=') AS STRING)`,
+			expectedRows: [][]interface{}{{nil}},
+		},
 		{
 			name:         "from_hex",
 			query:        `SELECT FROM_HEX('00010203aaeeefff'), FROM_HEX('0AF'), FROM_HEX('666f6f626172'), FROM_HEX(NULL)`,
@@ -3370,6 +4256,11 @@ WITH example AS
 				{"helloooo", "oo", int64(1), nil, nil},
 			},
 		},
+		{
+			name:         "instr with default position and backward search",
+			query:        `SELECT INSTR('hello', 'l'), INSTR('hello', 'l', -1), INSTR('hello', 'l', -1, 2), INSTR('abc', 'c', 10), INSTR('αβγβ', 'β', 1, 2), INSTR(b'\x01\x02\x01', b'\x01', -1)`,
+			expectedRows: [][]interface{}{{int64(3), int64(4), int64(3), int64(0), int64(4), int64(3)}},
+		},
 		{
 			name:         "left with string value",
 			query:        `SELECT LEFT('apple', 3), LEFT('banana', 3), LEFT('абвгд', 3), LEFT(NULL, 3), LEFT('apple', NULL)`,
@@ -3845,6 +4736,11 @@ WITH items AS (
 			query:        `SELECT SAFE_CONVERT_BYTES_TO_STRING(b'\xc2'), SAFE_CONVERT_BYTES_TO_STRING(NULL)`,
 			expectedRows: [][]interface{}{{"�", nil}},
 		},
+		{
+			name:         "edit_distance",
+			query:        `SELECT EDIT_DISTANCE('kitten', 'sitting'), EDIT_DISTANCE('kitten', 'sitting', 2), EDIT_DISTANCE(b'abc', b'acb'), EDIT_DISTANCE('a', NULL)`,
+			expectedRows: [][]interface{}{{int64(3), int64(2), int64(2), nil}},
+		},
 		{
 			name: "soundex",
 			query: `
@@ -3889,6 +4785,11 @@ WITH letters AS (
 			query:        `SELECT SPLIT('abc', NULL), SPLIT(b'\xab\xcd\xef\xaa\xbb', NULL)`,
 			expectedRows: [][]interface{}{{[]interface{}{}, []interface{}{}}},
 		},
+		{
+			name:         "split_substr",
+			query:        `SELECT SPLIT_SUBSTR('www.abc.xyz.com', '.', 1, 2), SPLIT_SUBSTR('www.abc.xyz.com', '.', 2), SPLIT_SUBSTR('www.abc.xyz.com', '.', -2), SPLIT_SUBSTR(NULL, '.', 1)`,
+			expectedRows: [][]interface{}{{"www.abc", "abc.xyz.com", "xyz.com", nil}},
+		},
 		{
 			name:         "starts_with",
 			query:        `SELECT STARTS_WITH('foo', 'b'), STARTS_WITH('bar', 'b'), STARTS_WITH('baz', 'b'), STARTS_WITH(NULL, 'a'), STARTS_WITH('a', NULL)`,
@@ -3899,6 +4800,11 @@ WITH letters AS (
 			query:        `SELECT STRPOS('foo@example.com', '@'), STRPOS('foobar@example.com', '@'), STRPOS('foobarbaz@example.com', '@'), STRPOS('quxexample.com', '@'), STRPOS(NULL, 'a'), STRPOS('a', NULL)`,
 			expectedRows: [][]interface{}{{int64(4), int64(7), int64(10), int64(0), nil, nil}},
 		},
+		{
+			name:         "strpos with multibyte characters",
+			query:        `SELECT STRPOS('αβγ', 'γ'), STRPOS('αβγ', 'x')`,
+			expectedRows: [][]interface{}{{int64(3), int64(0)}},
+		},
 		{
 			name:         "substr",
 			query:        `SELECT SUBSTR('apple', 2), SUBSTR('apple', 2, 2), SUBSTR('apple', -2), SUBSTR('apple', 1, 123), SUBSTR('apple', 123), SUBSTR(NULL, 1, 1), SUBSTR('foo', NULL, 1), SUBSTR('foo', 1, NULL)`,
@@ -4010,6 +4916,13 @@ WITH example AS (
 				{now.Format("2006-01-02")},
 			},
 		},
+		{
+			name:  "current_date with time zone",
+			query: `SELECT CURRENT_DATE('UTC')`,
+			expectedRows: [][]interface{}{
+				{now.UTC().Format("2006-01-02")},
+			},
+		},
 		{
 			name:         "date_add",
 			query:        `SELECT DATE_ADD('2023-01-29', INTERVAL 1 MONTH)`,
@@ -4106,6 +5019,74 @@ UNPIVOT(sales FOR quarter IN (Q1, Q2, Q3, Q4))
 			query:        `SELECT DATE_SUB('2023-03-31', INTERVAL 1 MONTH)`,
 			expectedRows: [][]interface{}{{"2023-02-28"}},
 		},
+		{
+			name:         "date_add and date_sub at the boundary",
+			query:        `SELECT DATE_ADD(DATE '9999-12-30', INTERVAL 1 DAY), DATE_SUB(DATE '0001-01-02', INTERVAL 1 DAY), DATE_SUB(DATE '2023-05-31', INTERVAL 1 QUARTER), SAFE.DATE_ADD(DATE '9999-12-31', INTERVAL 1 DAY)`,
+			expectedRows: [][]interface{}{{"9999-12-31", "0001-01-01", "2023-02-28", nil}},
+		},
+		{
+			name:        "date_add overflow",
+			query:       `SELECT DATE_ADD(DATE '9999-12-31', INTERVAL 1 DAY)`,
+			expectedErr: "Adding 1 DAY to date 9999-12-31 causes overflow",
+		},
+		{
+			name:        "date_sub overflow",
+			query:       `SELECT DATE_SUB(DATE '0001-01-01', INTERVAL 1 YEAR)`,
+			expectedErr: "Subtracting 1 YEAR from date 0001-01-01 causes overflow",
+		},
+		{
+			name:        "date_add overflow with large value",
+			query:       `SELECT DATE_ADD(DATE '2023-01-01', INTERVAL 9223372036854775807 DAY)`,
+			expectedErr: "Adding 9223372036854775807 DAY to date 2023-01-01 causes overflow",
+		},
+		{
+			name:        "date constructor with invalid date",
+			query:       `SELECT DATE(10000, 1, 1)`,
+			expectedErr: "Input calculates to invalid date: 10000-01-01",
+		},
+		{
+			name:        "date plus interval overflow",
+			query:       `SELECT DATE '9999-12-31' + INTERVAL 1 DAY`,
+			expectedErr: "DATETIME value is out of range: 10000-01-01 00:00:00",
+		},
+		{
+			name:         "date plus interval with time parts",
+			query:        `SELECT DATE '2024-02-29' + INTERVAL '1-0 0 12:00:00' YEAR TO SECOND`,
+			expectedRows: [][]interface{}{{"2025-02-28T12:00:00"}},
+		},
+		{
+			name:         "datetime plus and minus interval at the end of month",
+			query:        `SELECT DATETIME '2024-01-31 10:30:00' + INTERVAL 1 MONTH, DATETIME '2024-03-31 10:30:00' - INTERVAL 1 MONTH`,
+			expectedRows: [][]interface{}{{"2024-02-29T10:30:00", "2024-02-29T10:30:00"}},
+		},
+		{
+			name: "datetime plus interval of column value",
+			query: `
+SELECT
+  DATETIME '2023-01-31 08:00:00' + INTERVAL n MONTH,
+  DATETIME '2023-01-31 08:00:00' + INTERVAL n MONTH = DATETIME_ADD(DATETIME '2023-01-31 08:00:00', INTERVAL n MONTH)
+FROM UNNEST([1, 2, 13]) AS n`,
+			expectedRows: [][]interface{}{
+				{"2023-02-28T08:00:00", true},
+				{"2023-03-31T08:00:00", true},
+				{"2024-02-29T08:00:00", true},
+			},
+		},
+		{
+			name:         "datetime plus interval across daylight saving time",
+			query:        `SELECT DATETIME '2024-03-09 02:30:00' + INTERVAL 1 DAY`,
+			expectedRows: [][]interface{}{{"2024-03-10T02:30:00"}},
+		},
+		{
+			name:         "timestamp plus interval across daylight saving time",
+			query:        `SELECT TIMESTAMP("2024-03-09 12:00:00", "America/Los_Angeles") + INTERVAL 1 DAY`,
+			expectedRows: [][]interface{}{{createTimestampFormatFromString("2024-03-10 20:00:00+00")}},
+		},
+		{
+			name:        "timestamp plus interval with month part",
+			query:       `SELECT TIMESTAMP '2024-01-31 00:00:00+00' + INTERVAL 1 MONTH`,
+			expectedErr: "TIMESTAMP +/- INTERVAL is not supported for intervals with non-zero MONTH or YEAR part",
+		},
 		{
 			name:  "current_date",
 			query: `SELECT CURRENT_DATE()`,
@@ -4306,6 +5287,11 @@ SELECT date, EXTRACT(ISOYEAR FROM date), EXTRACT(YEAR FROM date), EXTRACT(MONTH
 			query:        `SELECT DATE_FROM_UNIX_DATE(14238) AS date_from_epoch`,
 			expectedRows: [][]interface{}{{"2008-12-25"}},
 		},
+		{
+			name:        "date_from_unix_date out of range",
+			query:       `SELECT DATE_FROM_UNIX_DATE(2932897)`,
+			expectedErr: "DATE_FROM_UNIX_DATE range is -719162 to 2932896 but saw 2932897",
+		},
 		{
 			name:         "date_trunc with day",
 			query:        `SELECT DATE_TRUNC(DATE "2008-12-25", DAY)`,
@@ -4511,6 +5497,16 @@ SELECT date, EXTRACT(ISOYEAR FROM date), EXTRACT(YEAR FROM date), EXTRACT(MONTH
 			query:        `SELECT DATETIME_ADD(DATETIME '2023-01-29 00:00:00', INTERVAL 1 MONTH)`,
 			expectedRows: [][]interface{}{{"2023-02-28T00:00:00"}},
 		},
+		{
+			name:        "datetime_add overflow",
+			query:       `SELECT DATETIME_ADD(DATETIME '9999-12-31 23:59:59', INTERVAL 1 SECOND)`,
+			expectedErr: "Adding 1 SECOND to datetime 9999-12-31 23:59:59 causes overflow",
+		},
+		{
+			name:         "datetime_add with more than 292 years",
+			query:        `SELECT DATETIME_ADD(DATETIME '2000-01-01 00:00:00', INTERVAL 43800000 HOUR)`,
+			expectedRows: [][]interface{}{{"6996-09-05T00:00:00"}},
+		},
 		{
 			name:  "datetime_sub",
 			query: `SELECT DATETIME "2008-12-25 15:30:00", DATETIME_SUB(DATETIME "2008-12-25 15:30:00", INTERVAL 10 MINUTE)`,
@@ -4779,6 +5775,11 @@ SELECT date, EXTRACT(ISOYEAR FROM date), EXTRACT(YEAR FROM date), EXTRACT(MONTH
 			query:        `SELECT TIMESTAMP_SUB(TIMESTAMP "2008-12-25 15:30:00+00", INTERVAL 10 MINUTE)`,
 			expectedRows: [][]interface{}{{createTimestampFormatFromString("2008-12-25 15:20:00+00")}},
 		},
+		{
+			name:        "timestamp_sub overflow",
+			query:       `SELECT TIMESTAMP_SUB(TIMESTAMP "0001-01-01 00:00:00+00", INTERVAL 1 MICROSECOND)`,
+			expectedErr: "Subtracting 1 MICROSECOND from timestamp 0001-01-01 00:00:00+00 causes overflow",
+		},
 		{
 			name:         "timestamp_diff",
 			query:        `SELECT TIMESTAMP_DIFF(TIMESTAMP "2010-07-07 10:20:00+00", TIMESTAMP "2008-12-25 15:30:00+00", HOUR)`,
@@ -4926,6 +5927,11 @@ SELECT date, EXTRACT(ISOYEAR FROM date), EXTRACT(YEAR FROM date), EXTRACT(MONTH
 			query:        `SELECT TIMESTAMP_MICROS(1230219000000000)`,
 			expectedRows: [][]interface{}{{createTimestampFormatFromString("2008-12-25 15:30:00+00")}},
 		},
+		{
+			name:        "timestamp_seconds out of range",
+			query:       `SELECT TIMESTAMP_SECONDS(253402300800)`,
+			expectedErr: "Invalid timestamp value: 253402300800",
+		},
 		{
 			name:         "unix_seconds",
 			query:        `SELECT UNIX_SECONDS(TIMESTAMP "2008-12-25 15:30:00+00")`,
@@ -4954,6 +5960,66 @@ FROM Input`,
 				{int64(25), int64(24), "2008-12-25"},
 			},
 		},
+		{
+			name: "extract quarter and week parts from date",
+			query: `
+SELECT
+  d,
+  EXTRACT(QUARTER FROM d), EXTRACT(WEEK FROM d), EXTRACT(WEEK(MONDAY) FROM d),
+  EXTRACT(WEEK(SATURDAY) FROM d), EXTRACT(ISOWEEK FROM d), EXTRACT(ISOYEAR FROM d), EXTRACT(DAYOFWEEK FROM d)
+FROM UNNEST([DATE '2017-01-01', DATE '2017-01-02', DATE '2017-11-05', DATE '2023-04-01', DATE '2024-03-31', DATE '2024-12-31']) AS d
+ORDER BY d`,
+			expectedRows: [][]interface{}{
+				{"2017-01-01", int64(1), int64(1), int64(0), int64(0), int64(52), int64(2016), int64(1)},
+				{"2017-01-02", int64(1), int64(1), int64(1), int64(0), int64(1), int64(2017), int64(2)},
+				{"2017-11-05", int64(4), int64(45), int64(44), int64(44), int64(44), int64(2017), int64(1)},
+				{"2023-04-01", int64(2), int64(13), int64(13), int64(13), int64(13), int64(2023), int64(7)},
+				{"2024-03-31", int64(1), int64(13), int64(13), int64(13), int64(13), int64(2024), int64(1)},
+				{"2024-12-31", int64(4), int64(52), int64(53), int64(52), int64(1), int64(2025), int64(3)},
+			},
+		},
+		{
+			name: "extract quarter and week parts from datetime",
+			query: `
+SELECT
+  EXTRACT(QUARTER FROM dt), EXTRACT(WEEK FROM dt), EXTRACT(WEEK(MONDAY) FROM dt),
+  EXTRACT(ISOWEEK FROM dt), EXTRACT(ISOYEAR FROM dt), EXTRACT(DAYOFWEEK FROM dt), EXTRACT(DAYOFYEAR FROM dt)
+FROM UNNEST([DATETIME '2020-12-31 23:59:59']) AS dt`,
+			expectedRows: [][]interface{}{
+				{int64(4), int64(52), int64(52), int64(53), int64(2020), int64(5), int64(366)},
+			},
+		},
+		{
+			name: "extract parts from timestamp at time zone",
+			query: `
+SELECT
+  EXTRACT(YEAR FROM ts AT TIME ZONE tz), EXTRACT(QUARTER FROM ts AT TIME ZONE tz), EXTRACT(WEEK FROM ts AT TIME ZONE tz),
+  EXTRACT(ISOWEEK FROM ts AT TIME ZONE tz), EXTRACT(ISOYEAR FROM ts AT TIME ZONE tz), EXTRACT(DAYOFWEEK FROM ts AT TIME ZONE tz),
+  EXTRACT(DAYOFYEAR FROM ts AT TIME ZONE tz), EXTRACT(HOUR FROM ts AT TIME ZONE tz), EXTRACT(DATE FROM ts AT TIME ZONE tz)
+FROM UNNEST([
+  STRUCT(TIMESTAMP '2021-01-01 03:00:00+00' AS ts, 'UTC' AS tz),
+  (TIMESTAMP '2021-01-01 03:00:00+00', 'America/Los_Angeles'),
+  (TIMESTAMP '2020-12-31 18:30:00+00', 'Asia/Kolkata'),
+  (TIMESTAMP '2021-03-14 09:59:59+00', 'America/Los_Angeles'),
+  (TIMESTAMP '2021-03-14 10:00:00+00', 'America/Los_Angeles'),
+  (TIMESTAMP '2021-11-07 08:30:00+00', 'America/Los_Angeles'),
+  (TIMESTAMP '2021-11-07 09:30:00+00', 'America/Los_Angeles')
+])`,
+			expectedRows: [][]interface{}{
+				{int64(2021), int64(1), int64(0), int64(53), int64(2020), int64(6), int64(1), int64(3), "2021-01-01"},
+				{int64(2020), int64(4), int64(52), int64(53), int64(2020), int64(5), int64(366), int64(19), "2020-12-31"},
+				{int64(2021), int64(1), int64(0), int64(53), int64(2020), int64(6), int64(1), int64(0), "2021-01-01"},
+				{int64(2021), int64(1), int64(11), int64(10), int64(2021), int64(1), int64(73), int64(1), "2021-03-14"},
+				{int64(2021), int64(1), int64(11), int64(10), int64(2021), int64(1), int64(73), int64(3), "2021-03-14"},
+				{int64(2021), int64(4), int64(45), int64(44), int64(2021), int64(1), int64(311), int64(1), "2021-11-07"},
+				{int64(2021), int64(4), int64(45), int64(44), int64(2021), int64(1), int64(311), int64(1), "2021-11-07"},
+			},
+		},
+		{
+			name:         "date_from_unix_date with the maximum date",
+			query:        `SELECT DATE_FROM_UNIX_DATE(2932896), DATE_FROM_UNIX_DATE(-719162)`,
+			expectedRows: [][]interface{}{{"9999-12-31", "0001-01-01"}},
+		},
 
 		// interval functions
 		{
@@ -4972,6 +6038,16 @@ SELECT
 				{"0-0 396 0:0:0", "0-0 0 36:34:56.789", "0-0 0 36:34:56.789"},
 			},
 		},
+		{
+			name:         "interval larger than int32 in minutes",
+			query:        `SELECT INTERVAL m MINUTE, INTERVAL m * 60000 MILLISECOND FROM UNNEST([5000000000]) AS m`,
+			expectedRows: [][]interface{}{{"0-0 0 83333333:20:0", "0-0 0 83333333:20:0"}},
+		},
+		{
+			name:        "interval out of range",
+			query:       `SELECT INTERVAL y QUARTER FROM UNNEST([99999999999]) AS y`,
+			expectedErr: "interval value is out of range",
+		},
 		{
 			name:         "make interval",
 			query:        `SELECT MAKE_INTERVAL(1, 6, 15), MAKE_INTERVAL(hour => 10, second => 20), MAKE_INTERVAL(1, minute => 5, day => 2)`,
@@ -5003,6 +6079,41 @@ SELECT
 			query:        `SELECT JUSTIFY_INTERVAL(INTERVAL '29 49:00:00' DAY TO SECOND)`,
 			expectedRows: [][]interface{}{{"0-1 1 1:0:0"}},
 		},
+		{
+			name:  "cast iso 8601 duration to interval",
+			query: `SELECT CAST('P1Y2M3DT4H5M6S' AS INTERVAL), CAST(s AS INTERVAL) FROM UNNEST(['P1Y2M3DT4H5M6S', 'P-1Y2M', 'P1W', 'PT1.5S', 'PT-90M']) AS s`,
+			expectedRows: [][]interface{}{
+				{"1-2 3 4:5:6", "1-2 3 4:5:6"},
+				{"1-2 3 4:5:6", "-0-10 0 0:0:0"},
+				{"1-2 3 4:5:6", "0-0 7 0:0:0"},
+				{"1-2 3 4:5:6", "0-0 0 0:0:1.5"},
+				{"1-2 3 4:5:6", "0-0 0 -1:30:0"},
+			},
+		},
+		{
+			name:  "cast interval to string round trip",
+			query: `SELECT CAST(i AS STRING), CAST(CAST(CAST(i AS STRING) AS INTERVAL) AS STRING) FROM UNNEST([INTERVAL -1 MONTH, INTERVAL -1 SECOND, INTERVAL '1-2 3 4:5:6.5' YEAR TO SECOND]) AS i`,
+			expectedRows: [][]interface{}{
+				{"-0-1 0 0:0:0", "-0-1 0 0:0:0"},
+				{"0-0 0 -0:0:1", "0-0 0 -0:0:1"},
+				{"1-2 3 4:5:6.5", "1-2 3 4:5:6.5"},
+			},
+		},
+		{
+			name:        "cast invalid iso 8601 duration to interval",
+			query:       `SELECT CAST(s AS INTERVAL) FROM UNNEST(['P1.5D']) AS s`,
+			expectedErr: `invalid interval "P1.5D": fractional values are only allowed for seconds part 'S'`,
+		},
+		{
+			name:        "cast out of range iso 8601 duration to interval",
+			query:       `SELECT CAST(s AS INTERVAL) FROM UNNEST(['P99999999999Y']) AS s`,
+			expectedErr: `invalid interval "P99999999999Y": interval field is out of range`,
+		},
+		{
+			name:        "cast iso 8601 duration without time part to interval",
+			query:       `SELECT CAST(s AS INTERVAL) FROM UNNEST(['PT']) AS s`,
+			expectedErr: `invalid interval "PT": time separator 'T' must be followed by the time part`,
+		},
 
 		// numeric/bignumeric
 		{
@@ -5017,8 +6128,43 @@ SELECT
 		},
 		{
 			name:         "parse_bignumeric",
-			query:        `SELECT PARSE_BIGNUMERIC("123.45"), PARSE_BIGNUMERIC("123.456E37"), PARSE_BIGNUMERIC("1.123456789012345678901234567890123456789")`,
-			expectedRows: [][]interface{}{{"123.45", "1234560000000000000000000000000000000000", "1.12345678901234567890123456789012345679"}},
+			query:        `SELECT PARSE_BIGNUMERIC("123.45"), PARSE_BIGNUMERIC("123.456E35"), PARSE_BIGNUMERIC("1.123456789012345678901234567890123456789")`,
+			expectedRows: [][]interface{}{{"123.45", "12345600000000000000000000000000000000", "1.12345678901234567890123456789012345679"}},
+		},
+		{
+			name:         "parse_numeric with sign and rounding",
+			query:        `SELECT PARSE_NUMERIC("  -  12.34 "), PARSE_NUMERIC("12.34e-1-"), PARSE_NUMERIC("0.0000000005"), PARSE_NUMERIC("-0.0000000005"), PARSE_NUMERIC(".1"), PARSE_NUMERIC(NULL)`,
+			expectedRows: [][]interface{}{{"-12.34", "-1.234", "0.000000001", "-0.000000001", "0.1", nil}},
+		},
+		{
+			name:         "parse_numeric boundary",
+			query:        `SELECT PARSE_NUMERIC("99999999999999999999999999999.999999999"), PARSE_NUMERIC("-99999999999999999999999999999.999999999")`,
+			expectedRows: [][]interface{}{{"99999999999999999999999999999.999999999", "-99999999999999999999999999999.999999999"}},
+		},
+		{
+			name:        "parse_numeric out of range by rounding",
+			query:       `SELECT PARSE_NUMERIC("99999999999999999999999999999.9999999995")`,
+			expectedErr: "Invalid NUMERIC value: 99999999999999999999999999999.9999999995",
+		},
+		{
+			name:        "parse_numeric out of range",
+			query:       `SELECT PARSE_NUMERIC("1e29")`,
+			expectedErr: "Invalid NUMERIC value: 1e29",
+		},
+		{
+			name:        "parse_numeric invalid value",
+			query:       `SELECT PARSE_NUMERIC("1,2")`,
+			expectedErr: "Invalid NUMERIC value: 1,2",
+		},
+		{
+			name:         "parse_bignumeric boundary",
+			query:        `SELECT PARSE_BIGNUMERIC("578960446186580977117854925043439539266.34992332820282019728792003956564819967"), PARSE_BIGNUMERIC("-578960446186580977117854925043439539266.34992332820282019728792003956564819968")`,
+			expectedRows: [][]interface{}{{"578960446186580977117854925043439539266.34992332820282019728792003956564819967", "-578960446186580977117854925043439539266.34992332820282019728792003956564819968"}},
+		},
+		{
+			name:        "parse_bignumeric out of range",
+			query:       `SELECT PARSE_BIGNUMERIC("578960446186580977117854925043439539266.34992332820282019728792003956564819968")`,
+			expectedErr: "Invalid BIGNUMERIC value: 578960446186580977117854925043439539266.34992332820282019728792003956564819968",
 		},
 		{
 			name:         "cast numeric and bignumeric to string",
@@ -5039,6 +6185,16 @@ SELECT
 			query:        `SELECT LENGTH(GENERATE_UUID())`,
 			expectedRows: [][]interface{}{{int64(36)}},
 		},
+		{
+			name:         "generate_uuid for each row",
+			query:        `SELECT COUNT(DISTINCT GENERATE_UUID()) FROM UNNEST(GENERATE_ARRAY(1, 100))`,
+			expectedRows: [][]interface{}{{int64(100)}},
+		},
+		{
+			name:         "rand for each row",
+			query:        `SELECT COUNT(DISTINCT RAND()) > 1, LOGICAL_AND(RAND() >= 0 AND RAND() < 1) FROM UNNEST(GENERATE_ARRAY(1, 100))`,
+			expectedRows: [][]interface{}{{true, true}},
+		},
 
 		// debugging functions
 		{
@@ -5077,6 +6233,35 @@ SELECT Add(3, 4);
 `,
 			expectedRows: [][]interface{}{{int64(7)}},
 		},
+		{
+			name: "call temp function with named arguments",
+			query: `
+CREATE TEMP FUNCTION Sub(x INT64, y INT64) AS (x - y);
+SELECT Sub(y => 3, x => 10), Sub(10, y => 4);
+`,
+			expectedRows: [][]interface{}{{int64(7), int64(6)}},
+		},
+		{
+			name: "call templated temp function with named arguments",
+			query: `
+CREATE TEMP FUNCTION Concat3(a ANY TYPE, b ANY TYPE, c ANY TYPE) AS (CONCAT(a, b, c));
+SELECT Concat3(c => "z", a => "x", b => "y");
+`,
+			expectedRows: [][]interface{}{{"xyz"}},
+		},
+		{
+			name: "call javascript temp function with named arguments",
+			query: `
+CREATE TEMP FUNCTION JsSub(x FLOAT64, y FLOAT64) RETURNS FLOAT64 LANGUAGE js AS "return x - y;";
+SELECT JsSub(y => 3, x => 10);
+`,
+			expectedRows: [][]interface{}{{float64(7)}},
+		},
+		{
+			name:         "call edit_distance with named argument",
+			query:        `SELECT EDIT_DISTANCE('kitten', 'sitting', max_distance => 2)`,
+			expectedRows: [][]interface{}{{int64(2)}},
+		},
 
 		// except
 		{
@@ -5244,6 +6429,31 @@ SELECT
   JSON_QUERY(JSON '{"a":null}', "$.b")`,
 			expectedRows: [][]interface{}{{nil, nil, nil, nil}},
 		},
+		{
+			name: "json_query with lax mode",
+			query: `
+SELECT
+  JSON_QUERY(JSON '{"a":[{"b":1},{"b":2},[{"b":3}]]}', 'lax $.a.b'),
+  JSON_QUERY(JSON '{"a":[{"b":1},{"b":2},[{"b":3}]]}', 'lax recursive $.a.b'),
+  JSON_QUERY(JSON '{"a":{"b":1}}', 'lax $.a[0].b'),
+  JSON_QUERY(JSON '{"a":{"b":1}}', 'LAX $.a[1].b')`,
+			expectedRows: [][]interface{}{{"[1,2]", "[1,2,3]", "[1]", "[]"}},
+		},
+		{
+			name:        "json_query with wildcard",
+			query:       `SELECT JSON_QUERY(JSON '{"a":[1,2]}', '$.a[*]')`,
+			expectedErr: "JSONPath \"$.a[*]\": wildcard `*` is not supported",
+		},
+		{
+			name:        "json_value with recursive descent",
+			query:       `SELECT JSON_VALUE(JSON '{"a":{"b":1}}', '$..b')`,
+			expectedErr: "JSONPath \"$..b\": recursive descent `..` is not supported. use `lax recursive` mode of JSON_QUERY instead",
+		},
+		{
+			name:        "json_value with lax mode",
+			query:       `SELECT JSON_VALUE(JSON '{"a":{"b":1}}', 'lax $.a.b')`,
+			expectedErr: "JSON_VALUE: JSONPath mode is supported only by JSON_QUERY: \"lax $.a.b\"",
+		},
 		{
 			name:         "json_extract_scalar with number",
 			query:        `SELECT JSON_EXTRACT_SCALAR(JSON '{ "name" : "Jakob", "age" : "6" }', '$.age')`,
@@ -5567,6 +6777,52 @@ FROM
 				{"false", "boolean"},
 			},
 		},
+		{
+			name: "json functions with sql null",
+			query: `
+SELECT
+  BOOL(CAST(NULL AS JSON)),
+  INT64(CAST(NULL AS JSON)),
+  FLOAT64(CAST(NULL AS JSON)),
+  STRING(CAST(NULL AS JSON)),
+  JSON_TYPE(CAST(NULL AS JSON)),
+  JSON_EXTRACT_SCALAR(CAST(NULL AS JSON)),
+  TO_JSON_STRING(CAST(NULL AS INT64))`,
+			expectedRows: [][]interface{}{{nil, nil, nil, nil, nil, nil, "null"}},
+		},
+		{
+			name: "json functions with json null",
+			query: `
+SELECT
+  JSON_EXTRACT_SCALAR(JSON 'null'),
+  JSON_EXTRACT_SCALAR('{"a":null}', '$.a'),
+  JSON_VALUE('{"a":null}', '$.a'),
+  JSON_VALUE_ARRAY('["a",null]'),
+  JSON_EXTRACT_STRING_ARRAY('["a",null]'),
+  JSON_TYPE(JSON '{"a":null}'.a),
+  JSON 'null'.a`,
+			expectedRows: [][]interface{}{{nil, nil, nil, []interface{}{"a", nil}, []interface{}{"a", nil}, "null", nil}},
+		},
+		{
+			name:         "json conversion functions",
+			query:        `SELECT INT64(JSON '1e2'), FLOAT64(JSON '9.8', wide_number_mode=>'exact'), STRING(JSON '"a"'), BOOL(JSON 'false')`,
+			expectedRows: [][]interface{}{{int64(100), float64(9.8), "a", false}},
+		},
+		{
+			name:        "bool with json null",
+			query:       `SELECT BOOL(JSON 'null')`,
+			expectedErr: "BOOL: the JSON value is not a boolean: null",
+		},
+		{
+			name:        "int64 with json fraction",
+			query:       `SELECT INT64(JSON '1.5')`,
+			expectedErr: "INT64: the JSON number cannot be converted to INT64 without loss of precision: 1.5",
+		},
+		{
+			name:        "string with json number",
+			query:       `SELECT STRING(JSON '123')`,
+			expectedErr: "STRING: the JSON value is not a string: 123",
+		},
 
 		// subquery expr
 		{
@@ -5599,6 +6855,65 @@ FROM
 			query:        "SELECT * FROM UNNEST([1, 2, 3]) AS val WHERE val IN (SELECT 1)",
 			expectedRows: [][]interface{}{{int64(1)}},
 		},
+		{
+			name: "subquery expr with in type and struct",
+			query: `
+SELECT a, b FROM UNNEST([STRUCT(1 AS a, 'x' AS b), STRUCT(2 AS a, 'y' AS b)])
+WHERE (a, b) IN (SELECT AS STRUCT x, y FROM UNNEST([STRUCT(1 AS x, 'x' AS y), STRUCT(2 AS x, 'z' AS y)]))`,
+			expectedRows: [][]interface{}{{int64(1), "x"}},
+		},
+		{
+			name: "subquery expr with in type and struct containing null",
+			query: `
+SELECT
+  (3, 4) IN (SELECT s FROM UNNEST([STRUCT(3 AS x, 4 AS y), NULL]) AS s),
+  (1, 2) IN (SELECT s FROM UNNEST([STRUCT(3 AS x, 4 AS y), NULL]) AS s),
+  (1, 2) IN (SELECT s FROM UNNEST([STRUCT(3 AS x, 4 AS y)]) AS s)`,
+			expectedRows: [][]interface{}{{true, nil, false}},
+		},
+		{
+			name: "subquery expr with not in type and struct",
+			query: `
+SELECT
+  (3, 4) NOT IN (SELECT s FROM UNNEST([STRUCT(3 AS x, 4 AS y), NULL]) AS s),
+  (1, 2) NOT IN (SELECT s FROM UNNEST([STRUCT(3 AS x, 4 AS y), NULL]) AS s),
+  (1, 2) NOT IN (SELECT s FROM UNNEST([STRUCT(3 AS x, 4 AS y)]) AS s)`,
+			expectedRows: [][]interface{}{{false, nil, true}},
+		},
+		{
+			name: "subquery expr with in type and null struct",
+			query: `
+SELECT
+  CAST(NULL AS STRUCT<x INT64, y INT64>) IN (SELECT s FROM UNNEST([STRUCT(3 AS x, 4 AS y)]) AS s),
+  CAST(NULL AS STRUCT<x INT64, y INT64>) IN (SELECT s FROM UNNEST(ARRAY<STRUCT<x INT64, y INT64>>[]) AS s),
+  CAST(NULL AS STRUCT<x INT64, y INT64>) NOT IN (SELECT s FROM UNNEST([STRUCT(3 AS x, 4 AS y)]) AS s)`,
+			expectedRows: [][]interface{}{{nil, false, nil}},
+		},
+		{
+			name: "subquery expr with in type and struct containing null field",
+			query: `
+SELECT
+  (1, CAST(NULL AS INT64)) IN (SELECT s FROM UNNEST([STRUCT(1 AS x, CAST(NULL AS INT64) AS y)]) AS s),
+  (1, CAST(NULL AS INT64)) IN (SELECT s FROM UNNEST([STRUCT(2 AS x, 3 AS y)]) AS s),
+  (1, CAST(NULL AS INT64)) IN (SELECT s FROM UNNEST([STRUCT(2 AS x, 3 AS y), STRUCT(1 AS x, 3 AS y)]) AS s)`,
+			expectedRows: [][]interface{}{{nil, false, nil}},
+		},
+		{
+			name: "struct equality with null field",
+			query: `
+SELECT
+  STRUCT(1, CAST(NULL AS INT64)) = STRUCT(1, CAST(NULL AS INT64)),
+  STRUCT(1, CAST(NULL AS INT64)) = STRUCT(2, CAST(NULL AS INT64)),
+  STRUCT(1, CAST(NULL AS INT64)) != STRUCT(1, CAST(NULL AS INT64)),
+  STRUCT(1, CAST(NULL AS INT64)) != STRUCT(2, CAST(NULL AS INT64)),
+  STRUCT(1, 2) = STRUCT(1, 2)`,
+			expectedRows: [][]interface{}{{nil, false, nil, true, true}},
+		},
+		{
+			name:         "in with null value",
+			query:        `SELECT 1 IN (NULL, 2), 1 IN (NULL, 1), CAST(NULL AS INT64) IN (1, 2)`,
+			expectedRows: [][]interface{}{{nil, true, nil}},
+		},
 		{
 			name:         "subquery expr with exists type",
 			query:        `SELECT EXISTS ( SELECT val FROM UNNEST([1, 2, 3]) AS val WHERE val = 1 )`,
@@ -5898,6 +7213,102 @@ SELECT * FROM table2;
 `,
 			expectedRows: [][]interface{}{{"test"}},
 		},
+		{
+			name:        "unnest json",
+			query:       `SELECT v FROM UNNEST(JSON '[1, 2]') AS v`,
+			expectedErr: "UNNEST of JSON value is not supported. use UNNEST(JSON_QUERY_ARRAY(json_expr)) instead",
+		},
+		{
+			name:         "unnest json query array",
+			query:        `SELECT INT64(v) FROM UNNEST(JSON_QUERY_ARRAY(JSON '[1, 2]')) AS v`,
+			expectedRows: [][]interface{}{{int64(1)}, {int64(2)}},
+		},
+		{
+			name:        "multi-column in subquery",
+			query:       `SELECT (1, 2) IN (SELECT x, y FROM UNNEST([STRUCT(1 AS x, 2 AS y)]))`,
+			expectedErr: "multi-column IN subquery is not supported. use (a, b) IN (SELECT AS STRUCT x, y FROM ...) instead",
+		},
+		{
+			name: "tablesample all rows",
+			query: `
+CREATE TEMP TABLE sample_all_table (id INT64);
+INSERT INTO sample_all_table (id) VALUES (1), (2), (3);
+SELECT id FROM sample_all_table TABLESAMPLE SYSTEM (100 PERCENT) ORDER BY id;
+`,
+			expectedRows: [][]interface{}{{int64(1)}, {int64(2)}, {int64(3)}},
+		},
+		{
+			name: "tablesample no rows",
+			query: `
+CREATE TEMP TABLE sample_none_table (id INT64);
+INSERT INTO sample_none_table (id) VALUES (1), (2), (3);
+SELECT id FROM sample_none_table TABLESAMPLE SYSTEM (0 PERCENT);
+`,
+			expectedRows: [][]interface{}{},
+		},
+		{
+			name: "tablesample rows with repeatable",
+			query: `
+CREATE TEMP TABLE sample_rows_table (id INT64);
+INSERT INTO sample_rows_table (id) VALUES (1), (2), (3), (4);
+SELECT COUNT(*) FROM sample_rows_table TABLESAMPLE RESERVOIR (2 ROWS) REPEATABLE (10);
+`,
+			expectedRows: [][]interface{}{{int64(2)}},
+		},
+		{
+			name: "tablesample by fingerprint of primary key",
+			query: `
+CREATE TEMP TABLE sample_key_table (id INT64, name STRING, PRIMARY KEY (id) NOT ENFORCED);
+INSERT INTO sample_key_table (id, name) SELECT id, 'same' FROM UNNEST(GENERATE_ARRAY(1, 1000)) AS id;
+SELECT
+  (SELECT COUNT(*) FROM sample_key_table TABLESAMPLE SYSTEM (10 PERCENT)) = (SELECT COUNTIF(MOD(ABS(FARM_FINGERPRINT(CAST(id AS STRING))), 100) < 10) FROM sample_key_table),
+  (SELECT COUNT(*) FROM sample_key_table TABLESAMPLE SYSTEM (10 PERCENT) WHERE MOD(ABS(FARM_FINGERPRINT(CAST(id AS STRING))), 100) >= 10),
+  (SELECT COUNT(*) FROM sample_key_table TABLESAMPLE SYSTEM (10 PERCENT)) BETWEEN 50 AND 150,
+  (SELECT COUNT(*) FROM sample_key_table TABLESAMPLE SYSTEM (50 PERCENT)) BETWEEN 400 AND 600;
+`,
+			expectedRows: [][]interface{}{{true, int64(0), true, true}},
+		},
+		{
+			name: "count rows of table without referenced columns",
+			query: `
+CREATE TEMP TABLE pruned_columns_table (id INT64, name STRING, payload STRING);
+INSERT INTO pruned_columns_table (id, name, payload) VALUES (1, 'alice', 'x'), (2, 'bob', 'y'), (3, 'carol', 'z');
+SELECT COUNT(*), (SELECT name FROM pruned_columns_table WHERE id = 2) FROM pruned_columns_table;
+`,
+			expectedRows: [][]interface{}{{int64(3), "bob"}},
+		},
+		{
+			name: "table default collation",
+			query: `
+CREATE TEMP TABLE collation_table (name STRING, code STRING COLLATE '') DEFAULT COLLATE 'und:ci';
+INSERT INTO collation_table (name, code) VALUES ('Alice', 'A'), ('BOB', 'b');
+SELECT name FROM collation_table WHERE name = 'alice' OR code = 'B';
+`,
+			expectedRows: [][]interface{}{{"Alice"}},
+		},
+		{
+			name: "dataset default collation",
+			query: `
+CREATE SCHEMA collation_dataset DEFAULT COLLATE 'und:ci';
+CREATE TABLE collation_dataset.users (name STRING, code STRING COLLATE '');
+INSERT INTO collation_dataset.users (name, code) VALUES ('Alice', 'A'), ('alice', 'a'), ('BOB', 'b');
+SELECT code FROM collation_dataset.users WHERE name = 'ALICE' AND code = 'a';
+`,
+			expectedRows: [][]interface{}{{"a"}},
+		},
+		{
+			name:         "group by collated column",
+			query:        `SELECT COUNT(*) AS cnt FROM collation_dataset.users GROUP BY name ORDER BY cnt`,
+			expectedRows: [][]interface{}{{int64(1)}, {int64(2)}},
+		},
+		{
+			name: "distinct collated column",
+			query: `
+SELECT
+  (SELECT COUNT(*) FROM (SELECT DISTINCT name FROM collation_dataset.users)),
+  (SELECT COUNT(*) FROM (SELECT DISTINCT code FROM collation_dataset.users))`,
+			expectedRows: [][]interface{}{{int64(2), int64(3)}},
+		},
 	} {
 		test := test
 		t.Run(test.name, func(t *testing.T) {
@@ -0,0 +1,22 @@
+package zetasqlite
+
+import (
+	"io"
+
+	internal "github.com/goccy/go-zetasqlite/internal"
+)
+
+// DetectJSONSchema detects the column specifications from the newline delimited JSON like the schema auto-detection of BigQuery.
+// sampleRows is the number of rows inspected to detect the types. If it is zero, the first 500 rows are inspected.
+// JSON objects are detected as STRUCT ( RECORD ) and JSON arrays are detected as ARRAY ( REPEATED ).
+// The result can be used to create the table or converted to the schema of cloud.google.com/go/bigquery by BigQuerySchema.
+func DetectJSONSchema(r io.Reader, sampleRows int) ([]*ColumnSpec, error) {
+	return internal.DetectJSONSchema(r, sampleRows)
+}
+
+// DetectCSVSchema detects the column specifications from CSV like the schema auto-detection of BigQuery.
+// sampleRows is the number of rows inspected to detect the types. If it is zero, the first 500 rows are inspected.
+// The first row is used as the header only if it looks like the header ( all values are strings while the other rows are not ).
+func DetectCSVSchema(r io.Reader, sampleRows int) ([]*ColumnSpec, error) {
+	return internal.DetectCSVSchema(r, sampleRows)
+}
@@ -0,0 +1,55 @@
+package zetasqlite
+
+import (
+	"database/sql"
+
+	internal "github.com/goccy/go-zetasqlite/internal"
+)
+
+type (
+	QueryStatistics = internal.QueryStatistics
+	DMLStats        = internal.DMLStats
+	StatementType   = internal.StatementType
+)
+
+const (
+	StatementTypeSelect              = internal.StatementTypeSelect
+	StatementTypeInsert              = internal.StatementTypeInsert
+	StatementTypeUpdate              = internal.StatementTypeUpdate
+	StatementTypeDelete              = internal.StatementTypeDelete
+	StatementTypeMerge               = internal.StatementTypeMerge
+	StatementTypeTruncateTable       = internal.StatementTypeTruncateTable
+	StatementTypeCreateTable         = internal.StatementTypeCreateTable
+	StatementTypeCreateTableAsSelect = internal.StatementTypeCreateTableAsSelect
+	StatementTypeCreateView          = internal.StatementTypeCreateView
+	StatementTypeCreateFunction      = internal.StatementTypeCreateFunction
+	StatementTypeCreateSchema        = internal.StatementTypeCreateSchema
+	StatementTypeDropTable           = internal.StatementTypeDropTable
+	StatementTypeDropView            = internal.StatementTypeDropView
+	StatementTypeDropFunction        = internal.StatementTypeDropFunction
+	StatementTypeBeginTransaction    = internal.StatementTypeBeginTransaction
+	StatementTypeCommitTransaction   = internal.StatementTypeCommitTransaction
+	StatementTypeScript              = internal.StatementTypeScript
+)
+
+// QueryStatisticsFromRows retrieve BigQuery compatible job statistics from sql.Rows.
+// If multiple statements are executed, the statement type is SCRIPT and the statistics of each statement are stored in Children.
+// NOTE: This API relies on the internal structure of sql.Rows, so not will work for all Go versions.
+func QueryStatisticsFromRows(rows *sql.Rows) (*QueryStatistics, error) {
+	zetasqliteRows, err := zetasqliteRowsFromRows(rows)
+	if err != nil {
+		return nil, err
+	}
+	return zetasqliteRows.QueryStatistics(), nil
+}
+
+// QueryStatisticsFromResult retrieve BigQuery compatible job statistics from sql.Result.
+// If multiple statements are executed, the statement type is SCRIPT and the statistics of each statement are stored in Children.
+// NOTE: This API relies on the internal structure of sql.Result, so not will work for all Go versions.
+func QueryStatisticsFromResult(result sql.Result) (*QueryStatistics, error) {
+	zetasqliteResult, err := zetasqliteResultFromResult(result)
+	if err != nil {
+		return nil, err
+	}
+	return zetasqliteResult.QueryStatistics(), nil
+}